@@ -675,7 +675,12 @@ static bool ensurePollingUnit() {
         "\"total_voters\":1000" +
         "}";
     String resp; int code = 0;
-    if (!httpPostJson(ENSURE_PU_ENDPOINT, json, resp, code, /*withAuth*/false)) return false;
+    // Terminal routes require a terminal JWT
+    if (g_jwt.length() == 0 && !fetchTerminalToken()) return false;
+    if (!httpPostJson(ENSURE_PU_ENDPOINT, json, resp, code, /*withAuth*/true)) return false;
+    if (code == 401 && fetchTerminalToken()) {
+        if (!httpPostJson(ENSURE_PU_ENDPOINT, json, resp, code, /*withAuth*/true)) return false;
+    }
     return code >= 200 && code < 300;
 }
 
@@ -0,0 +1,145 @@
+// Command migrate applies, reverts and inspects database schema migrations.
+//
+//	migrate [-config configs/server.yaml] up
+//	migrate down [steps]
+//	migrate to <version>
+//	migrate status
+//	migrate verify
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"voting-system/internal/database"
+	"voting-system/pkg/config"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	configPath := flag.String("config", "configs/server.yaml", "path to the server config file")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("Warning: .env file not found, using system environment variables")
+	}
+
+	dbConfig, err := config.LoadDatabaseConfig(*configPath)
+	if err != nil {
+		log.Fatal("Failed to load config: ", err)
+	}
+
+	dialect, err := database.DialectFor(dbConfig.Type)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := database.NewConnection(dbConfig)
+	if err != nil {
+		log.Fatal("Failed to connect to database: ", err)
+	}
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db, dialect)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch flag.Arg(0) {
+	case "up":
+		count, err := migrator.Up()
+		report("Applied", count, err, migrator)
+
+	case "down":
+		steps := 1
+		if flag.NArg() > 1 {
+			if steps, err = strconv.Atoi(flag.Arg(1)); err != nil || steps < 1 {
+				log.Fatalf("Invalid step count %q", flag.Arg(1))
+			}
+		}
+		count, err := migrator.Down(steps)
+		report("Reverted", count, err, migrator)
+
+	case "to":
+		if flag.NArg() < 2 {
+			log.Fatal("Usage: migrate to <version>")
+		}
+		version, err := strconv.Atoi(flag.Arg(1))
+		if err != nil || version < 0 {
+			log.Fatalf("Invalid version %q", flag.Arg(1))
+		}
+		count, err := migrator.To(version)
+		report("Migrated", count, err, migrator)
+
+	case "status":
+		printStatus(migrator)
+
+	case "verify":
+		if err := migrator.Verify(); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("All applied migrations match their checksums")
+
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func report(verb string, count int, err error, migrator *database.Migrator) {
+	if err != nil {
+		log.Fatalf("%s %d migration(s) before failing: %v", verb, count, err)
+	}
+
+	version, err := migrator.Version()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%s %d migration(s); schema is at version %d of %d\n", verb, count, version, migrator.Latest())
+}
+
+func printStatus(migrator *database.Migrator) {
+	statuses, err := migrator.Status()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		state, appliedAt := "pending", ""
+		if status.Applied {
+			state = "applied"
+			if status.Modified {
+				state = "applied (checksum mismatch)"
+			}
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+	w.Flush()
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: migrate [-config path] <command>
+
+Commands:
+  up              apply all pending migrations
+  down [steps]    revert the last applied migration(s) (default 1)
+  to <version>    migrate up or down to a version (0 reverts everything)
+  status          list migrations and whether they are applied
+  verify          check applied migrations against their checksums
+`)
+	flag.PrintDefaults()
+}
@@ -14,6 +14,9 @@ import (
 	"voting-system/internal/api/middlewares"
 	"voting-system/internal/blockchain"
 	"voting-system/internal/database"
+	"voting-system/internal/database/repositories"
+	"voting-system/internal/fleet"
+	"voting-system/internal/votesig"
 	"voting-system/pkg/config"
 	"voting-system/pkg/logger"
 
@@ -66,10 +69,20 @@ func main() {
 	if err := verifyBlockchainConnection(blockchainClient, logger); err != nil {
 		logger.Fatal("Blockchain connection verification failed: %v", err)
 	}
+	verifyVoteDomain(blockchainClient, cfg, logger)
 
 	// Initialize sync manager
-	syncManager := blockchain.NewSyncManager(blockchainClient, 30*time.Second)
-	setupSyncCallbacks(syncManager, logger)
+	syncManager := blockchain.NewSyncManager(blockchainClient, cfg.Blockchain.SyncInterval)
+	syncManager.Configure(blockchain.SyncOptions{
+		RetryInterval:  cfg.Blockchain.RetryInterval,
+		MaxBackoff:     cfg.Blockchain.MaxBackoff,
+		MaxRetries:     &cfg.Blockchain.MaxRetries,
+		Workers:        cfg.Blockchain.SyncWorkers,
+		BatchSize:      cfg.Blockchain.SyncBatchSize,
+		ReceiptTimeout: cfg.Blockchain.ReceiptTimeout,
+	})
+	syncManager.SetVoteQueue(repositories.NewPendingVoteRepository(db))
+	setupSyncCallbacks(syncManager, repositories.NewVoteRepository(db), logger)
 
 	// Initialize event monitor
 	eventMonitor := blockchain.NewEventMonitor(blockchainClient)
@@ -90,6 +103,25 @@ func main() {
 		cfg,
 	)
 
+	// Create the first admin account on a fresh database
+	if err := api.BootstrapAdmin(services); err != nil {
+		logger.Fatal("Failed to bootstrap admin user: %v", err)
+	}
+
+	// Encrypt fingerprint templates stored before the template vault
+	if err := api.SealVoterTemplates(services); err != nil {
+		logger.Fatal("Failed to seal voter templates: %v", err)
+	}
+
+	// Voter imports run in the server process and do not survive a restart
+	if err := api.FailInterruptedImports(services); err != nil {
+		logger.Error("%v", err)
+	}
+
+	// Watch terminal heartbeats
+	fleetMonitor := fleet.NewMonitor(services.TerminalRepository(), services.TerminalAlertRepository(),
+		services.AuditLogRepository(), cfg.Fleet, logger)
+
 	// Initialize Gin router
 	if cfg.Server.Mode == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -114,13 +146,16 @@ func main() {
 	// Start background services
 	logger.Info("Starting background services...")
 	if err := syncManager.Start(); err != nil {
-		logger.Error("Failed to start sync manager:", err)
+		logger.Error("Failed to start sync manager: %v", err)
 	}
 	if err := eventMonitor.Start(); err != nil {
-		logger.Error("Failed to start event monitor:", err)
+		logger.Error("Failed to start event monitor: %v", err)
 	}
 	if err := connManager.Start(); err != nil {
-		logger.Error("Failed to start connection manager:", err)
+		logger.Error("Failed to start connection manager: %v", err)
+	}
+	if err := fleetMonitor.Start(); err != nil {
+		logger.Error("Failed to start fleet monitor: %v", err)
 	}
 
 	// Start server in a goroutine
@@ -142,6 +177,7 @@ func main() {
 	syncManager.Stop()
 	eventMonitor.Stop()
 	connManager.Stop()
+	fleetMonitor.Stop()
 
 	// Shutdown server with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -179,12 +215,30 @@ func verifyBlockchainConnection(client *blockchain.BlockchainClient, logger *log
 	return nil
 }
 
-func setupSyncCallbacks(syncManager *blockchain.SyncManager, logger *logger.Logger) {
+// verifyVoteDomain warns when the chain ID and contract address terminals sign
+// votes for do not match the deployed contract, since the contract would then
+// reject every relayed vote
+func verifyVoteDomain(client *blockchain.BlockchainClient, cfg *config.Config, logger *logger.Logger) {
+	separator, err := client.DomainSeparator()
+	if err != nil {
+		logger.Warning("Failed to read the contract's vote signing domain: %v", err)
+		return
+	}
+	if separator != votesig.NewDomain(cfg.Blockchain.ChainID, cfg.Blockchain.ContractAddress).Separator() {
+		logger.Warning("Vote signing domain does not match the contract: check blockchain.chain_id and contract_address")
+	}
+}
+
+func setupSyncCallbacks(syncManager *blockchain.SyncManager, votes *repositories.VoteRepository, logger *logger.Logger) {
 	syncManager.SetCallbacks(
 		// On vote success
 		func(voteData blockchain.VoteData, txHash string) {
 			logger.Info("Vote synced successfully - hash: %s, tx: %s",
 				voteData.VerificationHash, txHash)
+			// Mark the stored vote synced so it counts in results and tallies
+			if err := votes.UpdateVoteSync(voteData.VerificationHash, txHash, 0); err != nil {
+				logger.Error("Failed to update vote sync status - hash: %s: %v", voteData.VerificationHash, err)
+			}
 		},
 		// On vote failed
 		func(voteData blockchain.VoteData, err error) {
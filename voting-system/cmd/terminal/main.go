@@ -1,29 +1,125 @@
 package main
 
 import (
-    "fmt"
-    "log"
-    "net/http"
-    
-    "voting-system/pkg/config"
-    "github.com/gin-gonic/gin"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"voting-system/internal/database"
+	"voting-system/internal/terminal"
+	"voting-system/internal/votesig"
+	"voting-system/pkg/config"
+	"voting-system/pkg/logger"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
 )
 
 func main() {
-    cfg, err := config.LoadConfig("configs/terminal.yaml")
-    if err != nil {
-        log.Fatal("Failed to load config:", err)
-    }
-    
-    r := gin.Default()
-    r.GET("/", func(c *gin.Context) {
-        c.JSON(200, gin.H{
-            "message": "Voting Terminal is running",
-            "status":  "active",
-        })
-    })
-    
-    addr := fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port)
-    fmt.Printf("Starting terminal server on %s\n", addr)
-    log.Fatal(http.ListenAndServe(addr, r))
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("Warning: .env file not found, using system environment variables")
+	}
+
+	// Load configuration
+	cfg, err := config.LoadTerminalConfig("configs/terminal.yaml")
+	if err != nil {
+		log.Fatal("Failed to load config:", err)
+	}
+
+	// Initialize logger
+	logger := logger.NewLogger(cfg.Logging.Level, cfg.Logging.File)
+	logger.Info("Starting Voting Terminal %s for polling unit %s...", cfg.Terminal.DeviceID, cfg.Terminal.PollingUnitID)
+
+	// The terminal signs votes with its blockchain key, for the configured contract
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(cfg.Blockchain.PrivateKey, "0x"))
+	if err != nil {
+		logger.Fatal("Failed to parse terminal private key: %v", err)
+	}
+	signer := votesig.NewSigner(key, votesig.NewDomain(cfg.Blockchain.ChainID, cfg.Blockchain.ContractAddress))
+	logger.Info("Signing votes as %s for contract %s on chain %d",
+		signer.Address().Hex(), cfg.Blockchain.ContractAddress, cfg.Blockchain.ChainID)
+
+	// Initialize local store
+	db, err := database.NewConnection(&cfg.Database)
+	if err != nil {
+		logger.Fatal("Failed to open terminal database: %v", err)
+	}
+	defer db.Close()
+
+	store, err := terminal.NewStore(db, cfg.Terminal.DeviceID, cfg.Terminal.SharedSecret, cfg.Terminal.PreviousSecrets...)
+	if err != nil {
+		logger.Fatal("Failed to initialize terminal store: %v", err)
+	}
+	logger.Info("Terminal store initialized at %s", cfg.Database.Path)
+
+	// Initialize central server client and vote forwarder
+	client := terminal.NewClient(
+		cfg.Terminal.ServerURL,
+		cfg.Terminal.DeviceID,
+		cfg.Terminal.SharedSecret,
+		cfg.Terminal.RequestTimeout,
+	)
+	forwarder := terminal.NewForwarder(store, client, logger, terminal.ForwarderOptions{
+		Interval:      cfg.Terminal.ForwardInterval,
+		RetryInterval: cfg.Terminal.RetryInterval,
+		MaxBackoff:    cfg.Terminal.MaxBackoff,
+	})
+	daemon := terminal.New(&cfg.Terminal, signer, store, client, forwarder, logger)
+
+	// Initialize Gin router
+	if cfg.Server.Mode == "production" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(logger.GinLogger())
+	daemon.SetupRoutes(router)
+
+	// Create HTTP server
+	server := &http.Server{
+		Addr:         fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port),
+		Handler:      router,
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 60 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	// Start background services
+	if err := daemon.Start(); err != nil {
+		logger.Fatal("Failed to start terminal: %v", err)
+	}
+
+	// Start server in a goroutine
+	go func() {
+		logger.Info("Starting terminal API on %s", server.Addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Fatal("Failed to start server: %v", err)
+		}
+	}()
+
+	// Wait for interrupt signal to gracefully shutdown
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	logger.Info("Shutting down terminal...")
+
+	// Shutdown server with timeout, then stop forwarding
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		logger.Error("Server forced to shutdown: %v", err)
+	}
+	daemon.Stop()
+
+	logger.Info("Terminal shutdown completed")
 }
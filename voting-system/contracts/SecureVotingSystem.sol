@@ -4,21 +4,30 @@ pragma solidity ^0.8.19;
 import "@openzeppelin/contracts/access/Ownable.sol";
 import "@openzeppelin/contracts/security/ReentrancyGuard.sol";
 import "@openzeppelin/contracts/utils/Counters.sol";
+import "@openzeppelin/contracts/utils/cryptography/ECDSA.sol";
+import "@openzeppelin/contracts/utils/cryptography/EIP712.sol";
+import "@openzeppelin/contracts/utils/cryptography/MerkleProof.sol";
 
 /**
  * @title SecureVotingSystem
  * @dev A secure blockchain-based voting system with biometric verification
  * @author Olawale Olatunji Bright
  */
-contract SecureVotingSystem is Ownable, ReentrancyGuard {
+contract SecureVotingSystem is Ownable, ReentrancyGuard, EIP712 {
     using Counters for Counters.Counter;
     
+    // EIP-712 type of a vote signed by a terminal and relayed by the server.
+    // Encrypted ballots put the ballot hash in encryptedVote and leave candidateId empty.
+    bytes32 public constant VOTE_TYPEHASH = keccak256(
+        "Vote(bytes32 verificationHash,bytes32 encryptedVote,string candidateId,string pollingUnitId,uint256 electionId,bytes32 nonce,uint256 timestamp)"
+    );
+    
     // State variables
     Counters.Counter private _voteCounter;
     Counters.Counter private _electionCounter;
     
     struct Vote {
-        bytes32 verificationHash;      // Hash of NIN + BVN + Fingerprint
+        bytes32 verificationHash;      // HMAC of the NIN under the election's secret salt
         bytes32 encryptedVote;         // Encrypted vote data
         uint256 timestamp;             // When vote was cast
         string pollingUnitId;          // Where vote was cast
@@ -55,13 +64,17 @@ contract SecureVotingSystem is Ownable, ReentrancyGuard {
     }
     
     // Mappings
-    mapping(bytes32 => bool) public hasVoted;                    // verificationHash -> voted status
+    mapping(uint256 => mapping(bytes32 => bool)) public hasVoted;                    // electionId -> verificationHash -> voted status
     mapping(uint256 => Vote) public votes;                      // voteId -> Vote
-    mapping(bytes32 => uint256) public verificationHashToVoteId; // verificationHash -> voteId
+    mapping(uint256 => mapping(bytes32 => uint256)) public verificationHashToVoteId; // electionId -> verificationHash -> voteId
     mapping(uint256 => Election) public elections;              // electionId -> Election
     mapping(address => bool) public authorizedTerminals;        // terminal addresses
     mapping(string => PollingUnit) public pollingUnits;         // pollingUnitId -> PollingUnit
     mapping(uint256 => mapping(string => uint256)) public electionResults; // electionId -> candidateId -> votes
+    mapping(uint256 => address) public voteTerminals;           // voteId -> terminal that cast the vote
+    mapping(address => mapping(bytes32 => bool)) public usedVoteNonces; // terminal -> nonce -> used
+    mapping(uint256 => bytes32) public voterRollRoots;          // electionId -> Merkle root of the voter roll
+    mapping(uint256 => uint256) public voterRollSizes;          // electionId -> voters on the roll
     
     // Current active election
     uint256 public currentElectionId;
@@ -89,6 +102,8 @@ contract SecureVotingSystem is Ownable, ReentrancyGuard {
     event PollingUnitRegistered(string indexed pollingUnitId, string name);
     event VoteInvalidated(uint256 indexed voteId, string reason);
     event CandidateRegistered(uint256 indexed electionId, string indexed candidateId);
+    event VoteRelayed(uint256 indexed voteId, address indexed terminal, address indexed relayer);
+    event VoterRollPublished(uint256 indexed electionId, bytes32 root, uint256 voterCount);
     
     // Modifiers
     modifier onlyAuthorizedTerminal() {
@@ -129,7 +144,7 @@ contract SecureVotingSystem is Ownable, ReentrancyGuard {
         _;
     }
     
-    constructor() {
+    constructor() EIP712("SecureVotingSystem", "1") {
         // Initialize with deployer as first authorized terminal
         authorizedTerminals[msg.sender] = true;
         emit TerminalAuthorized(msg.sender, true);
@@ -238,6 +253,44 @@ contract SecureVotingSystem is Ownable, ReentrancyGuard {
         }
     }
     
+    /**
+     * @dev Publish the Merkle root of an election's voter roll, snapshotted
+     *      before it starts. Leaves are keccak256(abi.encodePacked(verificationHash))
+     *      and pairs are hashed sorted, so no personal data is published.
+     * @param _electionId Election ID
+     * @param _root Merkle root of the roll
+     * @param _voterCount Number of voters on the roll
+     */
+    function publishVoterRoll(uint256 _electionId, bytes32 _root, uint256 _voterCount) external onlyOwner {
+        require(_electionId > 0 && _electionId <= _electionCounter.current(), "VotingSystem: Invalid election ID");
+        require(voterRollRoots[_electionId] == bytes32(0), "VotingSystem: Voter roll already published");
+        require(_root != bytes32(0) && _voterCount > 0, "VotingSystem: Empty voter roll");
+        
+        voterRollRoots[_electionId] = _root;
+        voterRollSizes[_electionId] = _voterCount;
+        
+        emit VoterRollPublished(_electionId, _root, _voterCount);
+    }
+    
+    /**
+     * @dev Check a voter's inclusion proof against an election's published roll
+     * @param _electionId Election ID
+     * @param _verificationHash Voter's verification hash for the election
+     * @param _proof Sibling hashes from the voter's leaf to the root
+     * @return bool Whether the voter is on the roll
+     */
+    function isOnVoterRoll(
+        uint256 _electionId,
+        bytes32 _verificationHash,
+        bytes32[] calldata _proof
+    ) external view returns (bool) {
+        bytes32 root = voterRollRoots[_electionId];
+        if (root == bytes32(0)) {
+            return false;
+        }
+        return MerkleProof.verify(_proof, root, keccak256(abi.encodePacked(_verificationHash)));
+    }
+    
     /**
      * @dev Start an election
      * @param _electionId Election ID to start
@@ -251,6 +304,7 @@ contract SecureVotingSystem is Ownable, ReentrancyGuard {
         require(block.timestamp >= election.startTime, "VotingSystem: Election start time not reached");
         require(block.timestamp < election.endTime, "VotingSystem: Election has expired");
         require(election.candidates.length > 0, "VotingSystem: No candidates configured");
+        require(voterRollRoots[_electionId] != bytes32(0), "VotingSystem: Voter roll not published");
         
         election.isActive = true;
         currentElectionId = _electionId;
@@ -278,7 +332,7 @@ contract SecureVotingSystem is Ownable, ReentrancyGuard {
     
     /**
      * @dev Cast a vote in the current election
-     * @param _verificationHash Hash combining NIN, BVN, and biometric data
+     * @param _verificationHash HMAC of the voter's NIN under the election's secret salt
      * @param _encryptedVote Encrypted vote data
      * @param _pollingUnitId Polling unit where vote is cast
      * @param _candidateId ID of the candidate being voted for
@@ -295,8 +349,154 @@ contract SecureVotingSystem is Ownable, ReentrancyGuard {
         nonReentrant 
         returns (uint256) {
         
+        return _recordVote(_verificationHash, _encryptedVote, _pollingUnitId, _candidateId, msg.sender);
+    }
+    
+    /**
+     * @dev Cast a vote signed by an authorized terminal. Anyone may relay it;
+     * the vote is recorded as cast by the terminal that signed it.
+     * @param _verificationHash HMAC of the voter's NIN under the election's secret salt
+     * @param _encryptedVote Encrypted vote data
+     * @param _pollingUnitId Polling unit where vote is cast
+     * @param _candidateId ID of the candidate being voted for
+     * @param _electionId Election the terminal signed the vote for
+     * @param _nonce Terminal-chosen nonce, usable once per terminal
+     * @param _timestamp When the terminal signed the vote
+     * @param _signature Terminal's EIP-712 signature over the vote
+     * @return uint256 Vote ID
+     */
+    function castVoteBySig(
+        bytes32 _verificationHash,
+        bytes32 _encryptedVote,
+        string memory _pollingUnitId,
+        string memory _candidateId,
+        uint256 _electionId,
+        bytes32 _nonce,
+        uint256 _timestamp,
+        bytes memory _signature
+    ) external 
+        onlyDuringElection 
+        validPollingUnit(_pollingUnitId)
+        nonReentrant 
+        returns (uint256) {
+        
+        address terminal = _useTerminalSignature(
+            _verificationHash, _encryptedVote, _candidateId, _pollingUnitId, _electionId, _nonce, _timestamp, _signature
+        );
+        uint256 voteId = _recordVote(_verificationHash, _encryptedVote, _pollingUnitId, _candidateId, terminal);
+        emit VoteRelayed(voteId, terminal, msg.sender);
+        return voteId;
+    }
+    
+    /**
+     * @dev Cast an encrypted ballot. The candidate stays secret: only a hash of the
+     * ballot is recorded, and per-candidate counts come from the off-chain
+     * homomorphic tally after the election ends.
+     * @param _verificationHash Voter's verification hash
+     * @param _ballotHash Hash of the encrypted ballot
+     * @param _electionId Election the ballot was encrypted for
+     * @param _pollingUnitId Polling unit where vote is cast
+     * @return uint256 Vote ID
+     */
+    function castEncryptedVote(
+        bytes32 _verificationHash,
+        bytes32 _ballotHash,
+        uint256 _electionId,
+        string memory _pollingUnitId
+    ) external 
+        onlyAuthorizedTerminal 
+        onlyDuringElection 
+        validPollingUnit(_pollingUnitId)
+        nonReentrant 
+        returns (uint256) {
+        
+        return _recordEncryptedVote(_verificationHash, _ballotHash, _electionId, _pollingUnitId, msg.sender);
+    }
+    
+    /**
+     * @dev Cast an encrypted ballot signed by an authorized terminal
+     * @param _verificationHash Voter's verification hash
+     * @param _ballotHash Hash of the encrypted ballot
+     * @param _electionId Election the ballot was encrypted for
+     * @param _pollingUnitId Polling unit where vote is cast
+     * @param _nonce Terminal-chosen nonce, usable once per terminal
+     * @param _timestamp When the terminal signed the vote
+     * @param _signature Terminal's EIP-712 signature over the vote
+     * @return uint256 Vote ID
+     */
+    function castEncryptedVoteBySig(
+        bytes32 _verificationHash,
+        bytes32 _ballotHash,
+        uint256 _electionId,
+        string memory _pollingUnitId,
+        bytes32 _nonce,
+        uint256 _timestamp,
+        bytes memory _signature
+    ) external 
+        onlyDuringElection 
+        validPollingUnit(_pollingUnitId)
+        nonReentrant 
+        returns (uint256) {
+        
+        address terminal = _useTerminalSignature(
+            _verificationHash, _ballotHash, "", _pollingUnitId, _electionId, _nonce, _timestamp, _signature
+        );
+        uint256 voteId = _recordEncryptedVote(_verificationHash, _ballotHash, _electionId, _pollingUnitId, terminal);
+        emit VoteRelayed(voteId, terminal, msg.sender);
+        return voteId;
+    }
+    
+    /**
+     * @dev Recover the terminal that signed a vote, check it is authorized and
+     * spend its nonce. The signed election must be the current one, so a
+     * signature cannot be replayed into a later election.
+     * @return address Signing terminal
+     */
+    function _useTerminalSignature(
+        bytes32 _verificationHash,
+        bytes32 _encryptedVote,
+        string memory _candidateId,
+        string memory _pollingUnitId,
+        uint256 _electionId,
+        bytes32 _nonce,
+        uint256 _timestamp,
+        bytes memory _signature
+    ) internal returns (address) {
+        require(_electionId == currentElectionId, "VotingSystem: Vote is for another election");
+        
+        bytes32 structHash = keccak256(abi.encode(
+            VOTE_TYPEHASH,
+            _verificationHash,
+            _encryptedVote,
+            keccak256(bytes(_candidateId)),
+            keccak256(bytes(_pollingUnitId)),
+            _electionId,
+            _nonce,
+            _timestamp
+        ));
+        address terminal = ECDSA.recover(_hashTypedDataV4(structHash), _signature);
+        
+        require(authorizedTerminals[terminal], "VotingSystem: Unauthorized terminal");
+        require(!usedVoteNonces[terminal][_nonce], "VotingSystem: Nonce already used");
+        usedVoteNonces[terminal][_nonce] = true;
+        
+        return terminal;
+    }
+    
+    /**
+     * @dev Record a candidate vote cast by a terminal
+     * @return uint256 Vote ID
+     */
+    function _recordVote(
+        bytes32 _verificationHash,
+        bytes32 _encryptedVote,
+        string memory _pollingUnitId,
+        string memory _candidateId,
+        address _terminal
+    ) internal returns (uint256) {
+        
         // Check if voter has already voted in this election
-        require(!hasVoted[_verificationHash], "VotingSystem: Voter has already cast a vote");
+        require(!hasVoted[currentElectionId][_verificationHash], "VotingSystem: Voter has already cast a vote");
         
         // Validate candidate
         Election storage election = elections[currentElectionId];
@@ -309,8 +509,8 @@ contract SecureVotingSystem is Ownable, ReentrancyGuard {
         }
         require(validCandidate, "VotingSystem: Invalid candidate");
         
-        // Mark as voted
-        hasVoted[_verificationHash] = true;
+        // Mark as voted in this election
+        hasVoted[currentElectionId][_verificationHash] = true;
         
         // Increment vote counter
         _voteCounter.increment();
@@ -326,9 +526,10 @@ contract SecureVotingSystem is Ownable, ReentrancyGuard {
             candidateId: _candidateId,
             isValid: true
         });
+        voteTerminals[voteId] = _terminal;
         
         // Map verification hash to vote ID
-        verificationHashToVoteId[_verificationHash] = voteId;
+        verificationHashToVoteId[currentElectionId][_verificationHash] = voteId;
         
         // Update election tallies
         election.candidateVotes[_candidateId]++;
@@ -345,12 +546,54 @@ contract SecureVotingSystem is Ownable, ReentrancyGuard {
     }
     
     /**
-     * @dev Check if a voter has already voted
-     * @param _verificationHash Voter's verification hash
+     * @dev Record an encrypted ballot cast by a terminal
+     * @return uint256 Vote ID
+     */
+    function _recordEncryptedVote(
+        bytes32 _verificationHash,
+        bytes32 _ballotHash,
+        uint256 _electionId,
+        string memory _pollingUnitId,
+        address _terminal
+    ) internal returns (uint256) {
+        
+        require(_electionId == currentElectionId, "VotingSystem: Ballot is for another election");
+        require(!hasVoted[currentElectionId][_verificationHash], "VotingSystem: Voter has already cast a vote");
+        
+        hasVoted[currentElectionId][_verificationHash] = true;
+        
+        _voteCounter.increment();
+        uint256 voteId = _voteCounter.current();
+        
+        votes[voteId] = Vote({
+            verificationHash: _verificationHash,
+            encryptedVote: _ballotHash,
+            timestamp: block.timestamp,
+            pollingUnitId: _pollingUnitId,
+            electionId: currentElectionId,
+            candidateId: "",
+            isValid: true
+        });
+        voteTerminals[voteId] = _terminal;
+        
+        verificationHashToVoteId[currentElectionId][_verificationHash] = voteId;
+        
+        elections[currentElectionId].totalVotes++;
+        pollingUnits[_pollingUnitId].votesRecorded++;
+        
+        emit VoteCast(_verificationHash, _pollingUnitId, currentElectionId, block.timestamp, voteId);
+        
+        return voteId;
+    }
+    
+    /**
+     * @dev Check if a voter has already voted in an election
+     * @param _electionId Election ID
+     * @param _verificationHash Voter's verification hash for the election
      * @return bool Whether the voter has voted
      */
-    function hasVoterVoted(bytes32 _verificationHash) external view returns (bool) {
-        return hasVoted[_verificationHash];
+    function hasVoterVoted(uint256 _electionId, bytes32 _verificationHash) external view returns (bool) {
+        return hasVoted[_electionId][_verificationHash];
     }
     
     // Polling Unit Management
@@ -396,6 +639,14 @@ contract SecureVotingSystem is Ownable, ReentrancyGuard {
         emit TerminalAuthorized(_terminal, _status);
     }
     
+    /**
+     * @dev Get the EIP-712 domain separator terminals sign votes under
+     * @return bytes32 Domain separator
+     */
+    function domainSeparator() external view returns (bytes32) {
+        return _domainSeparatorV4();
+    }
+    
     /**
      * @dev Check if a terminal is authorized
      * @param _terminal Terminal address to check
@@ -437,6 +688,16 @@ contract SecureVotingSystem is Ownable, ReentrancyGuard {
         );
     }
     
+    /**
+     * @dev Get the terminal that cast a vote
+     * @param _voteId Vote ID
+     * @return address Terminal address, or the sender for votes cast directly
+     */
+    function getVoteTerminal(uint256 _voteId) external view returns (address) {
+        require(_voteId > 0 && _voteId <= _voteCounter.current(), "VotingSystem: Invalid vote ID");
+        return voteTerminals[_voteId];
+    }
+    
     /**
      * @dev Get election details
      * @param _electionId Election ID
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"voting-system/internal/api/models"
+	"voting-system/internal/api/types"
+	"voting-system/internal/auth"
+	"voting-system/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPassword = "correct horse battery staple"
+
+// doJSON sends a JSON body with an optional token
+func (env *testEnv) doJSON(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	env.request++
+
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, path, strings.NewReader(string(payload)))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = fmt.Sprintf("10.%d.%d.%d:40000", env.request/65536%256, env.request/256%256, env.request%256)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+	return w
+}
+
+func (env *testEnv) createLoginUser(t *testing.T, username, role string) int64 {
+	t.Helper()
+
+	hash, err := auth.HashPassword(testPassword)
+	require.NoError(t, err)
+
+	user := &database.User{
+		Username:     username,
+		Email:        username + "@example.com",
+		PasswordHash: hash,
+		Role:         role,
+	}
+	require.NoError(t, env.users.Create(user))
+	return user.ID
+}
+
+func decodeTokens(t *testing.T, w *httptest.ResponseRecorder) types.AuthTokens {
+	t.Helper()
+
+	var resp struct {
+		Data types.AuthTokens `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp.Data
+}
+
+func login(t *testing.T, env *testEnv, username, password string) *httptest.ResponseRecorder {
+	t.Helper()
+	return env.doJSON("POST", "/api/v1/public/auth/login", "", types.LoginRequest{Username: username, Password: password})
+}
+
+func TestLoginIssuesSessionTokens(t *testing.T) {
+	env := newTestEnv(t)
+	env.createLoginUser(t, "alice", models.RoleAuditor)
+
+	w := login(t, env, "alice", testPassword)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	tokens := decodeTokens(t, w)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	assert.Equal(t, int64(15*60), tokens.ExpiresIn)
+
+	// The access token works and the session is listed as current
+	w = env.do("GET", "/api/v1/auth/sessions", tokens.AccessToken)
+	require.Equal(t, http.StatusOK, w.Code)
+	var sessions struct {
+		Data []types.SessionInfo `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sessions))
+	require.Len(t, sessions.Data, 1)
+	assert.Equal(t, tokens.SessionID, sessions.Data[0].ID)
+	assert.True(t, sessions.Data[0].Current)
+
+	// Wrong passwords and unknown users get the same answer
+	assert.Equal(t, http.StatusUnauthorized, login(t, env, "alice", "wrong").Code)
+	assert.Equal(t, http.StatusUnauthorized, login(t, env, "nobody", testPassword).Code)
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	env := newTestEnv(t)
+	env.createLoginUser(t, "bob", models.RoleOperator)
+
+	first := decodeTokens(t, login(t, env, "bob", testPassword))
+
+	w := env.doJSON("POST", "/api/v1/public/auth/refresh", "", types.RefreshRequest{RefreshToken: first.RefreshToken})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	second := decodeTokens(t, w)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+	assert.Equal(t, first.SessionID, second.SessionID)
+
+	// Replaying the rotated token revokes the session
+	w = env.doJSON("POST", "/api/v1/public/auth/refresh", "", types.RefreshRequest{RefreshToken: first.RefreshToken})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = env.doJSON("POST", "/api/v1/public/auth/refresh", "", types.RefreshRequest{RefreshToken: second.RefreshToken})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, http.StatusUnauthorized, env.do("GET", "/api/v1/auth/sessions", second.AccessToken).Code)
+}
+
+func TestLogoutRevokesSessions(t *testing.T) {
+	env := newTestEnv(t)
+	env.createLoginUser(t, "carol", models.RoleAdmin)
+
+	one := decodeTokens(t, login(t, env, "carol", testPassword))
+	two := decodeTokens(t, login(t, env, "carol", testPassword))
+	three := decodeTokens(t, login(t, env, "carol", testPassword))
+
+	// Logout only ends the current session
+	require.Equal(t, http.StatusOK, env.do("POST", "/api/v1/auth/logout", one.AccessToken).Code)
+	assert.Equal(t, http.StatusUnauthorized, env.do("GET", "/api/v1/auth/sessions", one.AccessToken).Code)
+	assert.Equal(t, http.StatusOK, env.do("GET", "/api/v1/auth/sessions", two.AccessToken).Code)
+
+	// Sessions can be revoked individually
+	require.Equal(t, http.StatusOK, env.do("DELETE", "/api/v1/auth/sessions/"+three.SessionID, two.AccessToken).Code)
+	assert.Equal(t, http.StatusUnauthorized, env.do("GET", "/api/v1/auth/sessions", three.AccessToken).Code)
+
+	// Logout-all ends everything, including refresh
+	four := decodeTokens(t, login(t, env, "carol", testPassword))
+	require.Equal(t, http.StatusOK, env.do("POST", "/api/v1/auth/logout-all", two.AccessToken).Code)
+	assert.Equal(t, http.StatusUnauthorized, env.do("GET", "/api/v1/auth/sessions", four.AccessToken).Code)
+	w := env.doJSON("POST", "/api/v1/public/auth/refresh", "", types.RefreshRequest{RefreshToken: four.RefreshToken})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRevokeSessionOfAnotherUserIsNotFound(t *testing.T) {
+	env := newTestEnv(t)
+	env.createLoginUser(t, "dave", models.RoleAuditor)
+	env.createLoginUser(t, "erin", models.RoleAuditor)
+
+	dave := decodeTokens(t, login(t, env, "dave", testPassword))
+	erin := decodeTokens(t, login(t, env, "erin", testPassword))
+
+	w := env.do("DELETE", "/api/v1/auth/sessions/"+dave.SessionID, erin.AccessToken)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, http.StatusOK, env.do("GET", "/api/v1/auth/sessions", dave.AccessToken).Code)
+}
+
+func TestLoginLockout(t *testing.T) {
+	env := newTestEnv(t)
+	env.createLoginUser(t, "mallory", models.RoleOperator)
+
+	// newTestEnv allows three attempts
+	assert.Equal(t, http.StatusUnauthorized, login(t, env, "mallory", "guess-1").Code)
+	assert.Equal(t, http.StatusUnauthorized, login(t, env, "mallory", "guess-2").Code)
+	assert.Equal(t, http.StatusLocked, login(t, env, "mallory", "guess-3").Code)
+
+	// The correct password is refused while locked
+	assert.Equal(t, http.StatusLocked, login(t, env, "mallory", testPassword).Code)
+}
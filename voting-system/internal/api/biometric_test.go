@@ -0,0 +1,144 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"voting-system/internal/api/models"
+	"voting-system/internal/api/types"
+	"voting-system/internal/biometric"
+	"voting-system/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// minutiaeCapture is a capture of finger seed with n minutiae, shifted by
+// (dx, dy) on the sensor
+func minutiaeCapture(t *testing.T, seed, n int, dx, dy float64) string {
+	t.Helper()
+
+	minutiae := make([]biometric.Minutia, n)
+	for i := range minutiae {
+		k := float64(i*7 + seed*13)
+		minutiae[i] = biometric.Minutia{
+			X:       float64((i*37+seed*11)%250) + dx,
+			Y:       float64((i*53+seed*29)%350) + dy,
+			Angle:   float64(int(k*29) % 360),
+			Type:    []string{biometric.RidgeEnding, biometric.RidgeBifurcation}[(i+seed)%2],
+			Quality: 0.9,
+		}
+	}
+	data, err := json.Marshal(biometric.MinutiaeSample{Minutiae: minutiae})
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestMinutiaeRegistrationAndVerification(t *testing.T) {
+	env := newTestEnv(t)
+	env.startElection(t, "1")
+	env.services.GetConfig().Biometric.Matcher = biometric.FormatMinutiae
+	env.services.GetConfig().Biometric.LockoutDuration = time.Minute
+	key := env.registerTerminal(t, "TERM-001", true)
+	terminal := env.tokenFor(t, models.RoleTerminal)
+
+	register := func(nin, capture string) (int, string) {
+		w := env.doJSON("POST", "/api/v1/public/voter/register", terminal, types.VoterRegistrationRequest{
+			NIN: nin, FirstName: "Ada", LastName: "Obi", DateOfBirth: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+			Gender: "F", PollingUnitID: "PU-1", FingerprintData: capture,
+		})
+		var failure types.ErrorResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &failure)
+		return w.Code, failure.Error
+	}
+
+	// Captures below the quality threshold are refused
+	code, reason := register("12345678901", minutiaeCapture(t, 1, 5, 0, 0))
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	assert.Equal(t, "low_quality_fingerprint", reason)
+
+	enrolled := minutiaeCapture(t, 1, 30, 0, 0)
+	code, _ = register("12345678901", enrolled)
+	require.Equal(t, http.StatusCreated, code)
+	hash := voteHash("12345678901")
+	template, err := env.services.VoterTemplateRepository().Get("12345678901")
+	require.NoError(t, err)
+	assert.Equal(t, biometric.FormatMinutiae, template.Format)
+	assert.True(t, template.Sealed)
+	assert.NotContains(t, template.Template, "minutiae", "templates are encrypted at rest")
+
+	// Another capture of a registered finger is found under another NIN
+	code, reason = register("10987654321", minutiaeCapture(t, 1, 30, 4, -3))
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, "fingerprint_exists", reason)
+	code, _ = register("10987654321", minutiaeCapture(t, 2, 30, 0, 0))
+	assert.Equal(t, http.StatusCreated, code)
+	env.snapshotRoll(t, "1")
+
+	// Votes with the wrong finger count towards the lockout
+	for i := 0; i < 3; i++ {
+		vote := types.VoteRequest{NIN: "12345678901", FingerprintData: minutiaeCapture(t, 3, 30, 0, 0), CandidateID: "APC", PollingUnitID: "PU-1"}
+		signVoteAs(t, testVoteDomain, key, "TERM-001", hash, &vote)
+		code, reason = castError(t, env, vote)
+		if i < 2 {
+			assert.Equal(t, http.StatusUnauthorized, code)
+			assert.Equal(t, "invalid_fingerprint", reason)
+		} else {
+			assert.Equal(t, http.StatusLocked, code)
+			assert.Equal(t, "biometric_locked", reason)
+		}
+	}
+
+	// and a locked out voter is refused even with the right finger
+	vote := types.VoteRequest{NIN: "12345678901", FingerprintData: minutiaeCapture(t, 1, 30, 2, 2), CandidateID: "APC", PollingUnitID: "PU-1"}
+	signVoteAs(t, testVoteDomain, key, "TERM-001", hash, &vote)
+	code, reason = castError(t, env, vote)
+	assert.Equal(t, http.StatusLocked, code)
+	assert.Equal(t, "biometric_locked", reason)
+
+	logs, err := env.services.AuditLogRepository().GetAuditLogsByAction("biometric_locked", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, logs, 1)
+}
+
+func TestTemplatesEnrolledBeforeTheVault(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerTerminal(t, "TERM-001", true)
+	terminal := env.tokenFor(t, models.RoleTerminal)
+
+	// A voter registered with an unkeyed hash and template kept in the clear
+	legacy := biometric.Hash(nil, "slot-3")
+	require.NoError(t, env.services.VoterRepository().RegisterVoter(&database.Voter{
+		NIN: "12345678901", FirstName: "Ada", LastName: "Obi", DateOfBirth: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+		Gender: "F", PollingUnitID: "PU-1", FingerprintHash: legacy,
+	}, &database.VoterTemplate{Format: biometric.FormatSHA256, Template: legacy, Quality: 1}))
+
+	require.NoError(t, SealVoterTemplates(env.services))
+	template, err := env.services.VoterTemplateRepository().Get("12345678901")
+	require.NoError(t, err)
+	assert.True(t, template.Sealed)
+	assert.NotEqual(t, legacy, template.Template)
+	unsealed, err := env.services.VoterTemplateRepository().ListUnsealed()
+	require.NoError(t, err)
+	assert.Empty(t, unsealed)
+
+	// The same finger is still found under its unkeyed hash
+	w := env.doJSON("POST", "/api/v1/public/voter/register", terminal, types.VoterRegistrationRequest{
+		NIN: "10987654321", FirstName: "Bola", LastName: "Ade", DateOfBirth: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+		Gender: "M", PollingUnitID: "PU-1", FingerprintData: "slot-3",
+	})
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+
+	// and new voters are registered under a keyed hash
+	w = env.doJSON("POST", "/api/v1/public/voter/register", terminal, types.VoterRegistrationRequest{
+		NIN: "10987654321", FirstName: "Bola", LastName: "Ade", DateOfBirth: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+		Gender: "M", PollingUnitID: "PU-1", FingerprintData: "slot-4",
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	voter, err := env.services.VoterRepository().GetVoterByNIN("10987654321")
+	require.NoError(t, err)
+	assert.NotEqual(t, biometric.Hash(nil, "slot-4"), voter.FingerprintHash)
+	assert.Len(t, voter.FingerprintHash, 64)
+}
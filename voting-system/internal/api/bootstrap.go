@@ -0,0 +1,112 @@
+package api
+
+import (
+	"fmt"
+	"time"
+	"voting-system/internal/api/interfaces"
+	"voting-system/internal/api/models"
+	"voting-system/internal/auth"
+	"voting-system/internal/biometric"
+	"voting-system/internal/database"
+)
+
+// BootstrapAdmin creates the first admin account from the admin config
+// (ADMIN_USERNAME / ADMIN_PASSWORD) when the users table is empty. It does
+// nothing once any user exists, so the credentials can stay in the environment.
+func BootstrapAdmin(services interfaces.Services) error {
+	admin := services.GetConfig().Admin
+	if admin.Username == "" || admin.Password == "" {
+		return nil
+	}
+
+	count, err := services.UserRepository().CountUsers()
+	if err != nil {
+		return fmt.Errorf("failed to count users: %v", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	security := services.GetConfig().Security
+	if err := auth.ValidatePassword(admin.Password, security.PasswordMinLength, security.RequireStrongPasswd); err != nil {
+		return fmt.Errorf("ADMIN_PASSWORD rejected: %v", err)
+	}
+
+	hash, err := auth.HashPassword(admin.Password)
+	if err != nil {
+		return fmt.Errorf("failed to hash admin password: %v", err)
+	}
+
+	email := admin.Email
+	if email == "" {
+		email = admin.Username + "@localhost"
+	}
+
+	user := &database.User{
+		Username:     admin.Username,
+		Email:        email,
+		PasswordHash: hash,
+		Role:         models.RoleAdmin,
+		Permissions:  "[]",
+	}
+	if err := services.UserRepository().Create(user); err != nil {
+		return fmt.Errorf("failed to create admin user: %v", err)
+	}
+
+	err = services.AuditLogRepository().InsertAuditLog(&database.AuditLog{
+		Action:    "admin_bootstrapped",
+		UserID:    fmt.Sprintf("%d", user.ID),
+		Details:   fmt.Sprintf("Created initial admin %s from configuration", user.Username),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		services.GetLogger().Error("Failed to create audit log: %v", err)
+	}
+
+	services.GetLogger().Info("Created initial admin user %s", user.Username)
+	return nil
+}
+
+// SealVoterTemplates encrypts the fingerprint templates enrolled before the
+// template vault, so no template is kept in the clear
+func SealVoterTemplates(services interfaces.Services) error {
+	templates, err := services.VoterTemplateRepository().ListUnsealed()
+	if err != nil {
+		return fmt.Errorf("failed to list unsealed templates: %v", err)
+	}
+	if len(templates) == 0 {
+		return nil
+	}
+
+	vault, err := biometric.NewVault(services.GetConfig().SealingKey())
+	if err != nil {
+		return err
+	}
+	for i := range templates {
+		template := &templates[i]
+		sealed, err := vault.Seal(template.Template)
+		if err != nil {
+			return fmt.Errorf("failed to seal template of voter %s: %v", template.NIN, err)
+		}
+		template.Template, template.Sealed = sealed, true
+		if err := services.VoterTemplateRepository().Save(template); err != nil {
+			return fmt.Errorf("failed to store sealed template of voter %s: %v", template.NIN, err)
+		}
+	}
+
+	services.GetLogger().Info("Sealed %d fingerprint templates enrolled before the template vault", len(templates))
+	return nil
+}
+
+// FailInterruptedImports marks the voter imports left running by the last
+// server process as failed; they are not resumed
+func FailInterruptedImports(services interfaces.Services) error {
+	failed, err := services.VoterImportRepository().FailInterrupted()
+	if err != nil {
+		return fmt.Errorf("failed to close interrupted voter imports: %v", err)
+	}
+	if failed > 0 {
+		services.GetLogger().Warning("Marked %d voter import(s) interrupted by a restart as failed", failed)
+	}
+	return nil
+}
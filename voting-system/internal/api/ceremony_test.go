@@ -0,0 +1,226 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"voting-system/internal/api/models"
+	"voting-system/internal/api/types"
+	"voting-system/internal/database"
+	"voting-system/internal/tally"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyCeremony(t *testing.T) {
+	env := newTestEnv(t)
+	admin := env.tokenFor(t, models.RoleAdmin)
+	candidates := []string{"APC", "PDP", "LP"}
+	env.createCachedElection(t, "7", candidates...)
+
+	trustees := []int64{
+		env.createUser(t, models.RoleTrustee, ""),
+		env.createUser(t, models.RoleTrustee, ""),
+		env.createUser(t, models.RoleAuditor, `["tally:decrypt"]`),
+	}
+	tokens := make([]string, len(trustees))
+	for i, id := range trustees {
+		role := models.RoleTrustee
+		if i == 2 {
+			role = models.RoleAuditor
+		}
+		tokens[i] = env.userToken(t, id, role)
+	}
+
+	// Trustees must hold the tally:decrypt permission
+	w := env.doJSON("POST", "/api/v1/admin/elections/7/key-ceremony", admin, types.KeyCeremonyRequest{
+		Threshold: 2,
+		Trustees:  []int64{trustees[0], env.userIDs[models.RoleAdmin]},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	w = env.doJSON("POST", "/api/v1/admin/elections/7/key-ceremony", admin, types.KeyCeremonyRequest{
+		Threshold: 3,
+		Trustees:  trustees[:2],
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	w = env.doJSON("POST", "/api/v1/admin/elections/7/key-ceremony", admin, types.KeyCeremonyRequest{
+		Threshold: 2,
+		Trustees:  trustees,
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var ceremony types.KeyCeremony
+	decodeData(t, w.Body.Bytes(), &ceremony)
+	assert.Len(t, ceremony.Commitments, 2)
+	assert.Equal(t, ceremony.PublicKey, ceremony.Commitments[0])
+	assert.Nil(t, ceremony.CompletedAt)
+
+	// The ceremony's key can only be replaced by another ceremony
+	w = env.doJSON("PUT", "/api/v1/admin/elections/7/ballot-key", admin, types.BallotKeyRequest{
+		PublicKey: ceremony.PublicKey, Threshold: 1, VerificationKeys: []string{ceremony.PublicKey},
+	})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Each trustee collects their share once and checks it against the commitments
+	commitments := make([]tally.Point, len(ceremony.Commitments))
+	for i, s := range ceremony.Commitments {
+		var err error
+		commitments[i], err = tally.DecodePoint(s)
+		require.NoError(t, err)
+	}
+	shares := make([]tally.KeyShare, len(trustees))
+	for i, token := range tokens {
+		w = env.do("POST", "/api/v1/trustee/elections/7/key-share", token)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var collected types.TrusteeKeyShare
+		decodeData(t, w.Body.Bytes(), &collected)
+		assert.Equal(t, i+1, collected.Share.Index)
+		require.NoError(t, collected.Share.Verify(commitments))
+		shares[i] = collected.Share
+
+		assert.Equal(t, http.StatusGone, env.do("POST", "/api/v1/trustee/elections/7/key-share", token).Code)
+	}
+	assert.Equal(t, http.StatusNotFound, env.do("POST", "/api/v1/trustee/elections/8/key-share", tokens[0]).Code)
+
+	w = env.do("GET", "/api/v1/admin/elections/7/key-ceremony", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	decodeData(t, w.Body.Bytes(), &ceremony)
+	assert.NotNil(t, ceremony.CompletedAt)
+	for _, trustee := range ceremony.Trustees {
+		assert.True(t, trustee.Collected)
+	}
+
+	w = env.do("GET", "/api/v1/trustee/elections", tokens[1])
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var seats []types.TrusteeSeat
+	decodeData(t, w.Body.Bytes(), &seats)
+	require.Len(t, seats, 1)
+	assert.Equal(t, 2, seats[0].Index)
+
+	// Voting with the ceremony's key
+	w = env.do("GET", "/api/v1/public/election/7/ballot-key", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var key types.BallotKey
+	decodeData(t, w.Body.Bytes(), &key)
+	pk, err := tally.ParsePublicKey(key.PublicKey)
+	require.NoError(t, err)
+	for i, choice := range []int{1, 1, 0, 2} {
+		ballot, err := tally.EncryptBallot(pk, []byte(key.Context), len(candidates), choice, nil)
+		require.NoError(t, err)
+		env.storeSyncedBallot(t, 7, fmt.Sprintf("voter-%d", i), ballot)
+	}
+
+	// Decryption shares are refused until the election ends
+	assert.Equal(t, http.StatusConflict, env.do("GET", "/api/v1/trustee/elections/7/tally", tokens[0]).Code)
+	require.NoError(t, env.services.ElectionKeyRepository().Close("7"))
+
+	w = env.do("GET", "/api/v1/trustee/elections/7/tally", tokens[0])
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var published types.EncryptedTally
+	decodeData(t, w.Body.Bytes(), &published)
+	result, err := tally.ParseEncryptedTally(published.Tally.String(), []byte(published.Context))
+	require.NoError(t, err)
+
+	partials := make([]*tally.DecryptionShare, len(shares))
+	for i, share := range shares {
+		partials[i], err = share.PartialDecrypt(result, nil)
+		require.NoError(t, err)
+	}
+
+	// A trustee can only submit for their own share
+	w = env.doJSON("POST", "/api/v1/trustee/elections/7/decryption-share", tokens[0],
+		types.DecryptionShareRequest{Share: partials[1]})
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	// A share that fails its proof is rejected and audited
+	forged := *partials[0]
+	forged.Factors = partials[1].Factors
+	w = env.doJSON("POST", "/api/v1/trustee/elections/7/decryption-share", tokens[0],
+		types.DecryptionShareRequest{Share: &forged})
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	logs, err := env.services.AuditLogRepository().GetAuditLogsByAction("decryption_share_rejected", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, logs, 1)
+
+	w = env.doJSON("POST", "/api/v1/trustee/elections/7/decryption-share", tokens[2],
+		types.DecryptionShareRequest{Share: partials[2]})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var progress types.DecryptionProgress
+	decodeData(t, w.Body.Bytes(), &progress)
+	assert.Equal(t, 1, progress.Submitted)
+	assert.Nil(t, progress.Results)
+	assert.Equal(t, http.StatusConflict, env.do("GET", "/api/v1/public/election/7/results", "").Code)
+
+	// The threshold-th share publishes the results
+	w = env.doJSON("POST", "/api/v1/trustee/elections/7/decryption-share", tokens[0],
+		types.DecryptionShareRequest{Share: partials[0]})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	decodeData(t, w.Body.Bytes(), &progress)
+	want := map[string]int64{"APC": 1, "PDP": 2, "LP": 1}
+	require.NotNil(t, progress.Results)
+	assert.Equal(t, want, progress.Results.Results)
+
+	w = env.do("GET", "/api/v1/public/election/7/results", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var results types.TallyResults
+	decodeData(t, w.Body.Bytes(), &results)
+	assert.Equal(t, want, results.Results)
+
+	w = env.doJSON("POST", "/api/v1/trustee/elections/7/decryption-share", tokens[1],
+		types.DecryptionShareRequest{Share: partials[1]})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	for _, action := range []string{"key_ceremony_created", "key_ceremony_completed", "tally_decrypted"} {
+		logs, err := env.services.AuditLogRepository().GetAuditLogsByAction(action, 10, 0)
+		require.NoError(t, err)
+		assert.Len(t, logs, 1, action)
+	}
+}
+
+func TestKeyCeremonyReuse(t *testing.T) {
+	env := newTestEnv(t)
+	admin := env.tokenFor(t, models.RoleAdmin)
+	env.createCachedElection(t, "7", "APC", "PDP")
+	env.createCachedElection(t, "8", "APC", "LP")
+	trustee := env.createUser(t, models.RoleTrustee, "")
+	token := env.userToken(t, trustee, models.RoleTrustee)
+
+	w := env.doJSON("POST", "/api/v1/admin/elections/7/key-ceremony", admin, types.KeyCeremonyRequest{
+		Threshold: 1,
+		Trustees:  []int64{trustee},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	// The key is only carried over once every trustee holds their share
+	reuse := types.KeyCeremonyRequest{ReuseElectionID: "7"}
+	w = env.doJSON("POST", "/api/v1/admin/elections/8/key-ceremony", admin, reuse)
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	require.Equal(t, http.StatusOK, env.do("POST", "/api/v1/trustee/elections/7/key-share", token).Code)
+
+	// Key rotation forces a fresh key per election
+	env.services.GetConfig().Encryption.KeyRotation = true
+	w = env.doJSON("POST", "/api/v1/admin/elections/8/key-ceremony", admin, reuse)
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+
+	env.services.GetConfig().Encryption.KeyRotation = false
+	w = env.doJSON("POST", "/api/v1/admin/elections/8/key-ceremony", admin, reuse)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var ceremony types.KeyCeremony
+	decodeData(t, w.Body.Bytes(), &ceremony)
+	assert.Equal(t, "7", ceremony.ReusedFrom)
+	assert.NotNil(t, ceremony.CompletedAt)
+	require.Len(t, ceremony.Trustees, 1)
+	assert.True(t, ceremony.Trustees[0].Collected)
+
+	first, err := env.services.ElectionKeyRepository().Get("7")
+	require.NoError(t, err)
+	second, err := env.services.ElectionKeyRepository().Get("8")
+	require.NoError(t, err)
+	assert.Equal(t, first.PublicKey, second.PublicKey)
+	assert.Equal(t, database.ElectionKeyOpen, second.Status)
+
+	// Nothing is left to collect for the carried-over key
+	assert.Equal(t, http.StatusGone, env.do("POST", "/api/v1/trustee/elections/8/key-share", token).Code)
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"voting-system/internal/api/models"
+	"voting-system/internal/api/types"
+	"voting-system/internal/database"
+	"voting-system/internal/votesig"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// issueEnrolmentCode issues a code for PU-1, bound to terminalID if it is set
+func issueEnrolmentCode(t *testing.T, env *testEnv, terminalID string) string {
+	t.Helper()
+
+	w := env.doJSON("POST", "/api/v1/admin/terminals/enrolment-codes", env.tokenFor(t, models.RoleAdmin),
+		types.EnrolmentCodeRequest{PollingUnitID: "PU-1", TerminalID: terminalID})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var resp types.EnrolmentCodeResponse
+	decodeData(t, w.Body.Bytes(), &resp)
+	require.NotEmpty(t, resp.Code)
+	return resp.Code
+}
+
+// enrolmentRequest builds the enrolment request terminalID's terminal would
+// send, signed with key
+func enrolmentRequest(t *testing.T, key *ecdsa.PrivateKey, terminalID, pollingUnitID, code string) types.TerminalEnrolmentRequest {
+	t.Helper()
+
+	signer := votesig.NewSigner(key, testVoteDomain)
+	enrolment := &votesig.Enrolment{TerminalID: terminalID, PollingUnitID: pollingUnitID, Code: code, Timestamp: time.Now().Unix()}
+	signature, err := signer.SignEnrolment(enrolment)
+	require.NoError(t, err)
+	return types.TerminalEnrolmentRequest{
+		TerminalID:    terminalID,
+		Name:          terminalID,
+		Location:      "Test",
+		PollingUnitID: pollingUnitID,
+		Address:       signer.Address().Hex(),
+		PublicKey:     signer.PublicKey(),
+		Code:          code,
+		Timestamp:     enrolment.Timestamp,
+		Signature:     signature,
+	}
+}
+
+func enrol(t *testing.T, env *testEnv, req types.TerminalEnrolmentRequest) (int, types.TerminalEnrolmentResponse, string) {
+	t.Helper()
+
+	w := env.doJSON("POST", "/api/v1/public/terminal/enrol", "", req)
+	var resp types.TerminalEnrolmentResponse
+	var failure types.ErrorResponse
+	if w.Code < 300 {
+		decodeData(t, w.Body.Bytes(), &resp)
+	} else {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &failure), w.Body.String())
+	}
+	return w.Code, resp, failure.Error
+}
+
+// requestTerminalToken asks for terminal deviceID's token, signing the request
+// with secret unless it is empty
+func requestTerminalToken(t *testing.T, env *testEnv, deviceID, secret string) (int, string) {
+	t.Helper()
+
+	req := types.TerminalTokenRequest{DeviceID: deviceID}
+	if secret != "" {
+		req = terminalTokenRequest(t, deviceID, secret)
+	}
+	w := env.doJSON("POST", "/api/v1/public/token/terminal", "", req)
+	var failure types.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &failure), w.Body.String())
+	return w.Code, failure.Error
+}
+
+func TestTerminalEnrolment(t *testing.T) {
+	env := newTestEnv(t)
+	t.Setenv("JWT_SECRET", testJWTSecret)
+	admin := env.tokenFor(t, models.RoleAdmin)
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	code, reason := requestTerminalToken(t, env, "TERM-010", "")
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "terminal_not_enrolled", reason)
+
+	enrolment := issueEnrolmentCode(t, env, "TERM-010")
+
+	// The request must be signed by the key being enrolled
+	forged := enrolmentRequest(t, key, "TERM-010", "PU-1", enrolment)
+	other, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	forged.Signature = enrolmentRequest(t, other, "TERM-010", "PU-1", enrolment).Signature
+	status, _, reason := enrol(t, env, forged)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, "invalid_signature", reason)
+
+	// Codes are bound to their polling unit and terminal, and refusals do not use them up
+	status, _, reason = enrol(t, env, enrolmentRequest(t, key, "TERM-010", "PU-2", enrolment))
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, "invalid_enrolment_code", reason)
+	status, _, _ = enrol(t, env, enrolmentRequest(t, key, "TERM-011", "PU-1", enrolment))
+	assert.Equal(t, http.StatusForbidden, status)
+
+	status, resp, _ := enrol(t, env, enrolmentRequest(t, key, "TERM-010", "PU-1", enrolment))
+	require.Equal(t, http.StatusAccepted, status)
+	assert.Equal(t, database.TerminalPending, resp.Status)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey).Hex(), resp.Address)
+
+	// Resubmitting with the same key reports the status; the code is spent
+	status, resp, _ = enrol(t, env, enrolmentRequest(t, key, "TERM-010", "PU-1", enrolment))
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, database.TerminalPending, resp.Status)
+	status, _, reason = enrol(t, env, enrolmentRequest(t, other, "TERM-010", "PU-1", issueEnrolmentCode(t, env, "")))
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Equal(t, "terminal_exists", reason)
+	status, _, reason = enrol(t, env, enrolmentRequest(t, other, "TERM-012", "PU-1", enrolment))
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, "invalid_enrolment_code", reason)
+
+	// Pending terminals can neither sign in nor be authorized
+	code, reason = requestTerminalToken(t, env, "TERM-010", "")
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "terminal_not_approved", reason)
+	address := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	w := env.doJSON("POST", "/api/v1/admin/terminals/TERM-010/authorize", admin,
+		map[string]interface{}{"address": address, "authorize": true})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Approval without a blockchain leaves the terminal approved, not active
+	w = env.do("POST", "/api/v1/admin/terminals/TERM-010/approve", admin)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	var approved struct {
+		Secret string `json:"secret"`
+	}
+	decodeData(t, w.Body.Bytes(), &approved)
+	require.NotEmpty(t, approved.Secret)
+	terminal, err := env.services.TerminalRepository().GetTerminal("TERM-010")
+	require.NoError(t, err)
+	assert.Equal(t, database.TerminalApproved, terminal.Status)
+	assert.False(t, terminal.Authorized)
+	assert.NotNil(t, terminal.ApprovedAt)
+	assert.Equal(t, address, terminal.EthAddress)
+	assert.NotEmpty(t, terminal.PublicKey)
+
+	code, _ = requestTerminalToken(t, env, "TERM-010", approved.Secret)
+	assert.Equal(t, http.StatusOK, code)
+
+	// Once active it cannot be approved again, and a revoked terminal enrols anew
+	require.NoError(t, env.services.TerminalRepository().SetAuthorization("TERM-010", true))
+	w = env.do("POST", "/api/v1/admin/terminals/TERM-010/approve", admin)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = env.do("POST", "/api/v1/admin/terminals/TERM-010/deauthorize", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	code, reason = requestTerminalToken(t, env, "TERM-010", approved.Secret)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "terminal_revoked", reason)
+
+	status, resp, _ = enrol(t, env, enrolmentRequest(t, other, "TERM-010", "PU-1", issueEnrolmentCode(t, env, "TERM-010")))
+	require.Equal(t, http.StatusAccepted, status)
+	assert.Equal(t, database.TerminalPending, resp.Status)
+	terminal, err = env.services.TerminalRepository().GetTerminal("TERM-010")
+	require.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(other.PublicKey).Hex(), terminal.EthAddress)
+	assert.Empty(t, terminal.ApprovedBy)
+
+	// Issued codes are listed without the codes themselves
+	w = env.do("GET", "/api/v1/admin/terminals/enrolment-codes?polling_unit_id=PU-1", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NotContains(t, w.Body.String(), enrolment)
+	var listed struct {
+		Codes []database.TerminalEnrolmentCode `json:"codes"`
+	}
+	decodeData(t, w.Body.Bytes(), &listed)
+	require.Len(t, listed.Codes, 3)
+	used := 0
+	for _, c := range listed.Codes {
+		if c.UsedAt != nil {
+			used++
+			assert.Equal(t, "TERM-010", c.UsedBy)
+		}
+	}
+	assert.Equal(t, 2, used)
+}
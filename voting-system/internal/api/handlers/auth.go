@@ -0,0 +1,554 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"voting-system/internal/api/interfaces"
+	"voting-system/internal/api/models"
+	"voting-system/internal/api/types"
+	"voting-system/internal/auth"
+	"voting-system/internal/database"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Login authenticates a user with username and password and starts a session
+func Login(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.LoginRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_request",
+				Code:    400,
+				Message: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		clientIP := getClientIP(c)
+		security := services.GetConfig().Security
+
+		// Refuse locked accounts before checking the password
+		attempt, err := services.LoginAttemptRepository().Get(req.Username)
+		if err != nil {
+			services.GetLogger().Error("Failed to load login attempts: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to process login",
+			})
+			return
+		}
+		if attempt.LockedUntil != nil && attempt.LockedUntil.After(time.Now()) {
+			respondAccountLocked(c, *attempt.LockedUntil)
+			return
+		}
+
+		user, err := services.UserRepository().GetByUsername(req.Username)
+		passwordHash := ""
+		if err == nil {
+			passwordHash = user.PasswordHash
+		}
+
+		if !auth.CheckPassword(passwordHash, req.Password) {
+			failed, recordErr := services.LoginAttemptRepository().RecordFailure(
+				req.Username, security.MaxLoginAttempts, security.LockoutDuration)
+			if recordErr != nil {
+				services.GetLogger().Error("Failed to record login failure: %v", recordErr)
+			}
+
+			createAuditLog(services, "login_failed", req.Username, "", "Invalid username or password", clientIP)
+
+			if failed != nil && failed.LockedUntil != nil {
+				services.GetLogger().SecurityLogger("account_locked", req.Username,
+					fmt.Sprintf("Locked after %d failed logins from %s", failed.FailedCount, clientIP))
+				createAuditLog(services, "account_locked", req.Username, "",
+					fmt.Sprintf("Locked until %s", failed.LockedUntil.Format(time.RFC3339)), clientIP)
+				respondAccountLocked(c, *failed.LockedUntil)
+				return
+			}
+
+			c.JSON(http.StatusUnauthorized, types.ErrorResponse{
+				Error:   "invalid_credentials",
+				Code:    401,
+				Message: "Invalid username or password",
+			})
+			return
+		}
+
+		// Accounts with 2FA enabled must also present a TOTP or recovery code
+		twoFactorEnabled, err := services.TwoFactorRepository().IsEnabled(user.ID)
+		if err != nil {
+			services.GetLogger().Error("Failed to load two-factor settings: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to process login",
+			})
+			return
+		}
+		if twoFactorEnabled {
+			if req.OTP == "" && req.RecoveryCode == "" {
+				c.JSON(http.StatusUnauthorized, types.ErrorResponse{
+					Error:   "two_factor_required",
+					Code:    401,
+					Message: "A one-time code or recovery code is required",
+				})
+				return
+			}
+
+			method, ok := verifySecondFactor(services, user.ID, req.OTP, req.RecoveryCode)
+			if !ok {
+				failed, recordErr := services.LoginAttemptRepository().RecordFailure(
+					req.Username, security.MaxLoginAttempts, security.LockoutDuration)
+				if recordErr != nil {
+					services.GetLogger().Error("Failed to record login failure: %v", recordErr)
+				}
+
+				createAuditLog(services, "login_failed", req.Username, "", "Invalid two-factor code", clientIP)
+
+				if failed != nil && failed.LockedUntil != nil {
+					respondAccountLocked(c, *failed.LockedUntil)
+					return
+				}
+
+				c.JSON(http.StatusUnauthorized, types.ErrorResponse{
+					Error:   "invalid_two_factor_code",
+					Code:    401,
+					Message: "Invalid one-time code or recovery code",
+				})
+				return
+			}
+
+			if method == "recovery_code" {
+				createAuditLog(services, "recovery_code_used", strconv.FormatInt(user.ID, 10), "",
+					"Logged in with a recovery code", clientIP)
+			}
+		}
+
+		if err := services.LoginAttemptRepository().Reset(req.Username); err != nil {
+			services.GetLogger().Error("Failed to reset login attempts: %v", err)
+		}
+		if err := services.UserRepository().UpdateLastLogin(user.ID); err != nil {
+			services.GetLogger().Error("Failed to update last login: %v", err)
+		}
+		if _, err := services.SessionRepository().DeleteExpired(); err != nil {
+			services.GetLogger().Error("Failed to clean up expired sessions: %v", err)
+		}
+
+		// Accounts that must use 2FA but have not enrolled get a session limited to enrolment
+		setupRequired := !twoFactorEnabled && twoFactorRequired(services, user.Role)
+
+		tokens, err := startSession(services, user, clientIP, c.Request.UserAgent(), setupRequired)
+		if err != nil {
+			services.GetLogger().Error("Failed to start session: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "session_error",
+				Code:    500,
+				Message: "Failed to create session",
+			})
+			return
+		}
+
+		createAuditLog(services, "user_login", strconv.FormatInt(user.ID, 10), "",
+			fmt.Sprintf("User %s logged in (session %s)", user.Username, tokens.SessionID), clientIP)
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data:    tokens,
+			Message: "Login successful",
+		})
+	}
+}
+
+// RefreshToken exchanges a refresh token for a new access token and a rotated refresh token.
+// Presenting a refresh token that was already rotated revokes the whole session.
+func RefreshToken(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.RefreshRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_request",
+				Code:    400,
+				Message: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		clientIP := getClientIP(c)
+		invalid := types.ErrorResponse{
+			Error:   "invalid_refresh_token",
+			Code:    401,
+			Message: "Refresh token is invalid or expired",
+		}
+
+		sessionID, secret, err := auth.SplitRefreshToken(req.RefreshToken)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, invalid)
+			return
+		}
+
+		session, err := services.SessionRepository().GetByID(sessionID)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, invalid)
+			return
+		}
+
+		var data database.SessionData
+		if err := json.Unmarshal([]byte(session.Data), &data); err != nil {
+			c.JSON(http.StatusUnauthorized, invalid)
+			return
+		}
+
+		if !auth.TokenMatches(secret, data.RefreshHash) {
+			if data.PreviousHash != "" && auth.TokenMatches(secret, data.PreviousHash) {
+				// A rotated token was replayed: assume it was stolen and end the session
+				if err := services.SessionRepository().Delete(session.ID); err != nil {
+					services.GetLogger().Error("Failed to revoke session: %v", err)
+				}
+				userID := strconv.FormatInt(session.UserID, 10)
+				services.GetLogger().SecurityLogger("refresh_token_reuse", userID,
+					fmt.Sprintf("Session %s revoked after refresh token reuse from %s", session.ID, clientIP))
+				createAuditLog(services, "refresh_token_reuse", userID, "",
+					fmt.Sprintf("Session %s revoked", session.ID), clientIP)
+			}
+			c.JSON(http.StatusUnauthorized, invalid)
+			return
+		}
+
+		user, err := services.UserRepository().GetByID(session.UserID)
+		if err != nil || !user.IsActive {
+			if err := services.SessionRepository().Delete(session.ID); err != nil {
+				services.GetLogger().Error("Failed to revoke session: %v", err)
+			}
+			c.JSON(http.StatusUnauthorized, invalid)
+			return
+		}
+
+		refreshToken, newSecret, err := auth.NewRefreshToken(session.ID)
+		if err != nil {
+			services.GetLogger().Error("Failed to generate refresh token: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "session_error",
+				Code:    500,
+				Message: "Failed to refresh session",
+			})
+			return
+		}
+
+		data.PreviousHash = data.RefreshHash
+		data.RefreshHash = auth.HashToken(newSecret)
+		data.IPAddress = clientIP
+		data.LastUsedAt = time.Now().Unix()
+		encoded, _ := json.Marshal(data)
+
+		expiresAt := time.Now().Add(sessionTTL(services))
+		if err := services.SessionRepository().Update(session.ID, string(encoded), expiresAt); err != nil {
+			services.GetLogger().Error("Failed to update session: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "session_error",
+				Code:    500,
+				Message: "Failed to refresh session",
+			})
+			return
+		}
+
+		accessToken, expiresIn, err := signAccessToken(services, user, session.ID)
+		if err != nil {
+			services.GetLogger().Error("Failed to sign access token: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "session_error",
+				Code:    500,
+				Message: "Failed to refresh session",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data: types.AuthTokens{
+				AccessToken:  accessToken,
+				RefreshToken: refreshToken,
+				TokenType:    "Bearer",
+				ExpiresIn:    expiresIn,
+				SessionID:    session.ID,
+
+				TwoFactorSetupRequired: data.TwoFactorPending,
+			},
+		})
+	}
+}
+
+// Logout revokes the caller's current session
+func Logout(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sessionID := c.GetString("session_id")
+		if sessionID == "" {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "no_session",
+				Code:    400,
+				Message: "Token is not bound to a session",
+			})
+			return
+		}
+
+		if err := services.SessionRepository().Delete(sessionID); err != nil {
+			services.GetLogger().Error("Failed to revoke session: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to log out",
+			})
+			return
+		}
+
+		createAuditLog(services, "user_logout", c.GetString("user_id"), "",
+			fmt.Sprintf("Session %s revoked", sessionID), getClientIP(c))
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Message: "Logged out",
+		})
+	}
+}
+
+// LogoutAll revokes every session belonging to the caller
+func LogoutAll(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := strconv.ParseInt(c.GetString("user_id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "no_session",
+				Code:    400,
+				Message: "Only user accounts have sessions",
+			})
+			return
+		}
+
+		revoked, err := services.SessionRepository().DeleteByUser(userID)
+		if err != nil {
+			services.GetLogger().Error("Failed to revoke sessions: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to revoke sessions",
+			})
+			return
+		}
+
+		createAuditLog(services, "user_logout_all", c.GetString("user_id"), "",
+			fmt.Sprintf("%d sessions revoked", revoked), getClientIP(c))
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data:    map[string]int64{"revoked": revoked},
+			Message: "All sessions revoked",
+		})
+	}
+}
+
+// ListSessions returns the caller's active sessions
+func ListSessions(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := strconv.ParseInt(c.GetString("user_id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "no_session",
+				Code:    400,
+				Message: "Only user accounts have sessions",
+			})
+			return
+		}
+
+		sessions, err := services.SessionRepository().ListByUser(userID)
+		if err != nil {
+			services.GetLogger().Error("Failed to list sessions: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to list sessions",
+			})
+			return
+		}
+
+		current := c.GetString("session_id")
+		result := make([]types.SessionInfo, 0, len(sessions))
+		for _, session := range sessions {
+			var data database.SessionData
+			_ = json.Unmarshal([]byte(session.Data), &data)
+			result = append(result, types.SessionInfo{
+				ID:         session.ID,
+				IPAddress:  data.IPAddress,
+				UserAgent:  data.UserAgent,
+				CreatedAt:  session.CreatedAt.Unix(),
+				LastUsedAt: data.LastUsedAt,
+				ExpiresAt:  session.ExpiresAt.Unix(),
+				Current:    session.ID == current,
+			})
+		}
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data:    result,
+		})
+	}
+}
+
+// RevokeSession revokes one of the caller's sessions by ID
+func RevokeSession(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sessionID := c.Param("id")
+		userID := c.GetString("user_id")
+
+		session, err := services.SessionRepository().GetByID(sessionID)
+		if err != nil || strconv.FormatInt(session.UserID, 10) != userID {
+			c.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error:   "session_not_found",
+				Code:    404,
+				Message: "Session not found",
+			})
+			return
+		}
+
+		if err := services.SessionRepository().Delete(sessionID); err != nil {
+			services.GetLogger().Error("Failed to revoke session: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to revoke session",
+			})
+			return
+		}
+
+		createAuditLog(services, "session_revoked", userID, "",
+			fmt.Sprintf("Session %s revoked", sessionID), getClientIP(c))
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Message: "Session revoked",
+		})
+	}
+}
+
+// startSession creates a session for the user and returns its first token pair
+func startSession(services interfaces.Services, user *database.User, clientIP, userAgent string, twoFactorPending bool) (*types.AuthTokens, error) {
+	sessionID, err := auth.RandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, secret, err := auth.NewRefreshToken(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	data, err := json.Marshal(database.SessionData{
+		RefreshHash:      auth.HashToken(secret),
+		IPAddress:        clientIP,
+		UserAgent:        userAgent,
+		LastUsedAt:       now.Unix(),
+		TwoFactorPending: twoFactorPending,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	session := &database.Session{
+		ID:        sessionID,
+		UserID:    user.ID,
+		Data:      string(data),
+		ExpiresAt: now.Add(sessionTTL(services)),
+		CreatedAt: now,
+	}
+	if err := services.SessionRepository().Create(session); err != nil {
+		return nil, err
+	}
+
+	accessToken, expiresIn, err := signAccessToken(services, user, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    expiresIn,
+		SessionID:    sessionID,
+		User:         toUserInfo(user),
+
+		TwoFactorSetupRequired: twoFactorPending,
+	}, nil
+}
+
+// signAccessToken mints a short-lived access JWT bound to a session
+func signAccessToken(services interfaces.Services, user *database.User, sessionID string) (string, int64, error) {
+	security := services.GetConfig().Security
+	if security.JWTSecret == "" {
+		return "", 0, fmt.Errorf("JWT secret not configured")
+	}
+
+	ttl := security.JWTExpiration
+	if ttl <= 0 {
+		ttl = 15 * time.Minute
+	}
+
+	extra, _ := models.ParsePermissions(user.Permissions)
+	claims := jwt.MapClaims{
+		"user_id":     strconv.FormatInt(user.ID, 10),
+		"role":        user.Role,
+		"permissions": models.PermissionsForRole(user.Role, extra),
+		"session_id":  sessionID,
+		"iat":         time.Now().Unix(),
+		"exp":         time.Now().Add(ttl).Unix(),
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(security.JWTSecret))
+	if err != nil {
+		return "", 0, err
+	}
+	return signed, int64(ttl.Seconds()), nil
+}
+
+// sessionTTL is how long a session lives after login or its last refresh
+func sessionTTL(services interfaces.Services) time.Duration {
+	if ttl := services.GetConfig().Security.SessionTimeout; ttl > 0 {
+		return ttl
+	}
+	return 2 * time.Hour
+}
+
+// toUserInfo converts a user record to its public representation
+func toUserInfo(user *database.User) *types.UserInfo {
+	extra, _ := models.ParsePermissions(user.Permissions)
+	info := &types.UserInfo{
+		ID:          user.ID,
+		Username:    user.Username,
+		Email:       user.Email,
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		Role:        user.Role,
+		Permissions: models.PermissionsForRole(user.Role, extra),
+		IsActive:    user.IsActive,
+		CreatedAt:   user.CreatedAt.Unix(),
+	}
+	if user.LastLogin != nil {
+		lastLogin := user.LastLogin.Unix()
+		info.LastLogin = &lastLogin
+	}
+	return info
+}
+
+// respondAccountLocked writes the lockout error
+func respondAccountLocked(c *gin.Context, lockedUntil time.Time) {
+	c.JSON(http.StatusLocked, types.ErrorResponse{
+		Error:   "account_locked",
+		Code:    423,
+		Message: fmt.Sprintf("Too many failed logins. Try again after %s", lockedUntil.UTC().Format(time.RFC3339)),
+	})
+}
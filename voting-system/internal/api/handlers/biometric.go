@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"voting-system/internal/api/interfaces"
+	"voting-system/internal/api/types"
+	"voting-system/internal/biometric"
+	"voting-system/internal/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// templateVault returns the vault voters' templates are sealed in
+func templateVault(services interfaces.Services) (*biometric.Vault, error) {
+	return biometric.NewVault(sealingKey(services))
+}
+
+// newMatcher returns the fingerprint matching service for the server's
+// biometric configuration
+func newMatcher(services interfaces.Services) (*biometric.Service, error) {
+	vault, err := templateVault(services)
+	if err != nil {
+		return nil, err
+	}
+	return biometric.NewService(services.GetConfig().Biometric, vault.FingerprintKey())
+}
+
+// biometrics returns the fingerprint matching service for the server's
+// biometric configuration, writing a 500 if it is invalid
+func biometrics(c *gin.Context, services interfaces.Services) (*biometric.Service, bool) {
+	service, err := newMatcher(services)
+	if err != nil {
+		services.GetLogger().Error("Invalid biometric configuration: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "biometric_error",
+			Code:    500,
+			Message: "Fingerprint matching is not configured",
+		})
+		return nil, false
+	}
+	return service, true
+}
+
+// sealTemplate encrypts an enrolled template for storage
+func sealTemplate(services interfaces.Services, template *biometric.Template) (*database.VoterTemplate, error) {
+	vault, err := templateVault(services)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := vault.Seal(template.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &database.VoterTemplate{
+		Format:   template.Format,
+		Template: sealed,
+		Quality:  template.Quality,
+		Sealed:   true,
+	}, nil
+}
+
+// openTemplate decrypts a stored template. Templates enrolled before the
+// vault and not sealed yet are read as they are.
+func openTemplate(services interfaces.Services, stored *database.VoterTemplate) (*biometric.Template, error) {
+	data := stored.Template
+	if stored.Sealed {
+		vault, err := templateVault(services)
+		if err != nil {
+			return nil, err
+		}
+		if data, err = vault.Open(stored.Template); err != nil {
+			return nil, fmt.Errorf("failed to open template of voter %s: %v", stored.NIN, err)
+		}
+	}
+	return &biometric.Template{Format: stored.Format, Data: data, Quality: stored.Quality}, nil
+}
+
+// voterTemplate returns a voter's enrolled template. Voters registered before
+// templates were kept are matched against their unkeyed fingerprint hash.
+func voterTemplate(services interfaces.Services, voter *database.Voter) (*biometric.Template, error) {
+	stored, err := services.VoterTemplateRepository().Get(voter.NIN)
+	if err == sql.ErrNoRows {
+		return &biometric.Template{Format: biometric.FormatSHA256, Data: voter.FingerprintHash, Quality: 1}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return openTemplate(services, stored)
+}
+
+// templateGallery opens every template enrolled in a format, to search for a
+// finger among the registered voters
+func templateGallery(services interfaces.Services, format string) ([]biometric.Candidate, error) {
+	stored, err := services.VoterTemplateRepository().ListByFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	gallery := make([]biometric.Candidate, 0, len(stored))
+	for i := range stored {
+		opened, err := openTemplate(services, &stored[i])
+		if err != nil {
+			return nil, err
+		}
+		gallery = append(gallery, biometric.Candidate{ID: stored[i].NIN, Template: opened})
+	}
+	return gallery, nil
+}
+
+// enrolFingerprint extracts the template a voter registers with and searches
+// the enrolled templates of other voters for the same finger, so a voter may
+// re-enrol their own. It writes the response and returns nil if the capture
+// is unusable or already registered.
+func enrolFingerprint(c *gin.Context, services interfaces.Services, nin, pollingUnitID, sample, clientIP string) *biometric.Template {
+	matcher, ok := biometrics(c, services)
+	if !ok {
+		return nil
+	}
+
+	template, err := matcher.Enrol(sample)
+	switch {
+	case errors.Is(err, biometric.ErrLowQuality):
+		services.GetLogger().Warning("Low quality fingerprint at registration - nin: %s, quality: %.2f", nin, template.Quality)
+		c.JSON(http.StatusUnprocessableEntity, types.ErrorResponse{
+			Error:   "low_quality_fingerprint",
+			Code:    422,
+			Message: fmt.Sprintf("Fingerprint quality %.2f is below the required %.2f; capture it again", template.Quality, services.GetConfig().Biometric.QualityThreshold),
+		})
+		return nil
+	case errors.Is(err, biometric.ErrInvalidSample):
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "invalid_fingerprint",
+			Code:    400,
+			Message: err.Error(),
+		})
+		return nil
+	case err != nil:
+		services.GetLogger().Error("Failed to enrol fingerprint: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "biometric_error",
+			Code:    500,
+			Message: "Failed to enrol fingerprint",
+		})
+		return nil
+	}
+
+	gallery, err := templateGallery(services, template.Format)
+	if err != nil {
+		services.GetLogger().Error("Failed to load fingerprint templates: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "biometric_error",
+			Code:    500,
+			Message: "Failed to check for duplicate fingerprints",
+		})
+		return nil
+	}
+
+	others := gallery[:0]
+	for _, candidate := range gallery {
+		if candidate.ID != nin {
+			others = append(others, candidate)
+		}
+	}
+	matches, err := matcher.Identify(sample, others)
+	if err != nil {
+		services.GetLogger().Error("Failed to search fingerprint templates: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "biometric_error",
+			Code:    500,
+			Message: "Failed to check for duplicate fingerprints",
+		})
+		return nil
+	}
+	if len(matches) > 0 {
+		services.GetLogger().SecurityLogger("duplicate_fingerprint", nin,
+			fmt.Sprintf("Fingerprint matches voter %s with score %.2f", matches[0].ID, matches[0].Score))
+		createAuditLog(services, "voter_rejected_duplicate_fingerprint", nin, pollingUnitID,
+			fmt.Sprintf("Fingerprint matches %d registered voter(s), best score %.2f", len(matches), matches[0].Score), clientIP)
+		c.JSON(http.StatusConflict, types.ErrorResponse{
+			Error:   "fingerprint_exists",
+			Code:    409,
+			Message: "Fingerprint is already registered",
+		})
+		return nil
+	}
+
+	return template
+}
+
+// verifyFingerprint matches a capture against the voter's enrolled template,
+// 1:1. Failed matches count towards the biometric lockout. It writes the
+// response and returns false unless the fingerprint matches.
+func verifyFingerprint(c *gin.Context, services interfaces.Services, voter *database.Voter, sample, action, pollingUnitID, clientIP string) bool {
+	cfg := services.GetConfig().Biometric
+	matcher, ok := biometrics(c, services)
+	if !ok {
+		return false
+	}
+
+	// Refuse locked out voters before matching
+	attempt, err := services.BiometricAttemptRepository().Get(voter.NIN)
+	if err != nil {
+		services.GetLogger().Error("Failed to load biometric attempts: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "database_error",
+			Code:    500,
+			Message: "Failed to verify fingerprint",
+		})
+		return false
+	}
+	if attempt.LockedUntil != nil && attempt.LockedUntil.After(time.Now()) {
+		respondBiometricLocked(c, *attempt.LockedUntil)
+		return false
+	}
+
+	template, err := voterTemplate(services, voter)
+	if err != nil {
+		services.GetLogger().Error("Failed to load fingerprint template: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "database_error",
+			Code:    500,
+			Message: "Failed to verify fingerprint",
+		})
+		return false
+	}
+
+	score, matched, err := matcher.Verify(sample, template)
+	if err != nil && !errors.Is(err, biometric.ErrInvalidSample) {
+		services.GetLogger().Error("Failed to match fingerprint: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "biometric_error",
+			Code:    500,
+			Message: "Failed to verify fingerprint",
+		})
+		return false
+	}
+
+	if !matched {
+		services.GetLogger().Warning("Invalid fingerprint - nin: %s, score: %.2f", voter.NIN, score)
+		createAuditLog(services, action+"_rejected_invalid_fingerprint", voter.NIN, pollingUnitID,
+			fmt.Sprintf("Fingerprint did not match (score %.2f, threshold %.2f)", score, cfg.MatchThreshold), clientIP)
+
+		failed, recordErr := services.BiometricAttemptRepository().RecordFailure(voter.NIN, matcher.MaxAttempts(), cfg.LockoutDuration)
+		if recordErr != nil {
+			services.GetLogger().Error("Failed to record biometric failure: %v", recordErr)
+		}
+		if failed != nil && failed.LockedUntil != nil {
+			services.GetLogger().SecurityLogger("biometric_locked", voter.NIN,
+				fmt.Sprintf("Locked after %d failed fingerprint matches from %s", failed.FailedCount, clientIP))
+			createAuditLog(services, "biometric_locked", voter.NIN, pollingUnitID,
+				fmt.Sprintf("Locked until %s", failed.LockedUntil.Format(time.RFC3339)), clientIP)
+			respondBiometricLocked(c, *failed.LockedUntil)
+			return false
+		}
+
+		c.JSON(http.StatusUnauthorized, types.ErrorResponse{
+			Error:   "invalid_fingerprint",
+			Code:    401,
+			Message: "Invalid fingerprint",
+		})
+		return false
+	}
+
+	if attempt.FailedCount > 0 {
+		if err := services.BiometricAttemptRepository().Reset(voter.NIN); err != nil {
+			services.GetLogger().Error("Failed to reset biometric attempts: %v", err)
+		}
+	}
+	if template.Format == biometric.FormatSHA256 {
+		upgradeFingerprint(services, voter, matcher, sample)
+	}
+	return true
+}
+
+// upgradeFingerprint replaces the unkeyed fingerprint hash of a voter enrolled
+// before the vault with a keyed one, sealed, once a capture has matched it.
+// Failing to is logged; the voter still verifies against the old hash.
+func upgradeFingerprint(services interfaces.Services, voter *database.Voter, matcher *biometric.Service, sample string) {
+	hash := matcher.Hash(sample)
+	template, err := sealTemplate(services, &biometric.Template{Format: biometric.FormatExact, Data: hash, Quality: 1})
+	if err == nil {
+		err = services.VoterRepository().UpdateFingerprint(voter.NIN, hash, template)
+	}
+	if err != nil {
+		services.GetLogger().Error("Failed to upgrade fingerprint hash of voter %s: %v", voter.NIN, err)
+		return
+	}
+	voter.FingerprintHash = hash
+	services.GetLogger().Info("Upgraded fingerprint hash of voter %s to a keyed hash", voter.NIN)
+}
+
+func respondBiometricLocked(c *gin.Context, lockedUntil time.Time) {
+	c.JSON(http.StatusLocked, types.ErrorResponse{
+		Error:   "biometric_locked",
+		Code:    423,
+		Message: fmt.Sprintf("Too many failed fingerprint matches. Try again after %s", lockedUntil.UTC().Format(time.RFC3339)),
+	})
+}
@@ -0,0 +1,632 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"voting-system/internal/api/interfaces"
+	"voting-system/internal/api/models"
+	"voting-system/internal/api/types"
+	"voting-system/internal/auth"
+	"voting-system/internal/database"
+	"voting-system/internal/tally"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StartKeyCeremony deals an election's ballot key to its trustees (Admin only).
+// The server generates the key, splits it threshold-of-n with Feldman
+// commitments and keeps only each trustee's share, sealed, until the trustee
+// collects it. With key rotation disabled, an earlier election's key and
+// trustees can be carried over instead.
+func StartKeyCeremony(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		electionID, id, ok := parseElectionID(c)
+		if !ok {
+			return
+		}
+
+		var req types.KeyCeremonyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_request",
+				Code:    400,
+				Message: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		if !checkKeyReplaceable(c, services, electionID, id) {
+			return
+		}
+
+		var (
+			ceremony *database.KeyCeremony
+			trustees []database.CeremonyTrustee
+			key      *electionKey
+		)
+		if req.ReuseElectionID != "" {
+			ceremony, trustees, key, ok = reuseCeremony(c, services, electionID, req.ReuseElectionID)
+		} else {
+			ceremony, trustees, key, ok = dealCeremony(c, services, electionID, &req)
+		}
+		if !ok {
+			return
+		}
+
+		if err := services.CeremonyRepository().Create(ceremony, trustees); err != nil {
+			services.GetLogger().Error("Failed to save key ceremony: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "database_error", Code: 500, Message: "Failed to save key ceremony"})
+			return
+		}
+
+		// A carried-over key has nothing left to collect
+		if _, err := services.CeremonyRepository().MarkCompleted(electionID); err != nil {
+			services.GetLogger().Error("Failed to complete key ceremony: %v", err)
+		}
+
+		details := fmt.Sprintf("Key ceremony for election %s: %d-of-%d trustees", electionID, ceremony.Threshold, ceremony.Trustees)
+		if ceremony.ReusedFrom != nil {
+			details += ", key carried over from election " + *ceremony.ReusedFrom
+		}
+		createAuditLog(services, "key_ceremony_created", c.GetString("user_id"), "", details, getClientIP(c))
+
+		stored, err := services.CeremonyRepository().Get(electionID)
+		var info *types.KeyCeremony
+		if err == nil {
+			info, err = ceremonyInfo(services, stored, key)
+		}
+		if err != nil {
+			services.GetLogger().Error("Failed to load key ceremony: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "database_error", Code: 500, Message: "Failed to load key ceremony"})
+			return
+		}
+		c.JSON(http.StatusCreated, types.SuccessResponse{Success: true, Data: info, Message: "Key ceremony started"})
+	}
+}
+
+// dealCeremony generates and splits a fresh key for the requested trustees
+func dealCeremony(c *gin.Context, services interfaces.Services, electionID string, req *types.KeyCeremonyRequest) (*database.KeyCeremony, []database.CeremonyTrustee, *electionKey, bool) {
+	if len(req.Trustees) == 0 || req.Threshold < 1 || req.Threshold > len(req.Trustees) {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "invalid_threshold",
+			Code:    400,
+			Message: fmt.Sprintf("Threshold must be between 1 and the number of trustees (%d)", len(req.Trustees)),
+		})
+		return nil, nil, nil, false
+	}
+
+	seen := make(map[int64]bool, len(req.Trustees))
+	for _, userID := range req.Trustees {
+		if seen[userID] {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_trustee",
+				Code:    400,
+				Message: fmt.Sprintf("User %d is listed more than once", userID),
+			})
+			return nil, nil, nil, false
+		}
+		seen[userID] = true
+
+		if !canHoldKeyShare(services, userID) {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_trustee",
+				Code:    400,
+				Message: fmt.Sprintf("User %d is not an active user with the %s permission", userID, models.PermTallyDecrypt),
+			})
+			return nil, nil, nil, false
+		}
+	}
+
+	dealing, err := tally.Deal(req.Threshold, len(req.Trustees), nil)
+	if err != nil {
+		services.GetLogger().Error("Failed to deal election key: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "ceremony_error", Code: 500, Message: "Failed to generate election key"})
+		return nil, nil, nil, false
+	}
+
+	commitments := make([]string, len(dealing.Commitments))
+	for i, commitment := range dealing.Commitments {
+		commitments[i] = tally.EncodePoint(commitment)
+	}
+	verificationKeys := make([]string, len(dealing.Shares))
+	trustees := make([]database.CeremonyTrustee, len(dealing.Shares))
+	for i, share := range dealing.Shares {
+		verificationKeys[i] = tally.EncodePoint(tally.CommitmentVerificationKey(dealing.Commitments, share.Index))
+
+		encoded, _ := json.Marshal(share)
+		sealed, err := auth.Seal(sealingKey(services), string(encoded))
+		if err != nil {
+			services.GetLogger().Error("Failed to seal key share: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "ceremony_error", Code: 500, Message: "Failed to seal key shares"})
+			return nil, nil, nil, false
+		}
+		trustees[i] = database.CeremonyTrustee{
+			ElectionID:   electionID,
+			TrusteeIndex: share.Index,
+			UserID:       req.Trustees[i],
+			SealedShare:  &sealed,
+		}
+	}
+
+	key, ok := saveBallotKey(c, services, electionID, dealing.PublicKey, req.Threshold, verificationKeys)
+	if !ok {
+		return nil, nil, nil, false
+	}
+
+	commitmentsJSON, _ := json.Marshal(commitments)
+	ceremony := &database.KeyCeremony{
+		ElectionID:  electionID,
+		Threshold:   req.Threshold,
+		Trustees:    len(trustees),
+		Commitments: string(commitmentsJSON),
+		CreatedBy:   c.GetString("user_id"),
+	}
+	return ceremony, trustees, key, true
+}
+
+// reuseCeremony carries over the key and trustees of an earlier election
+// whose trustees have all collected their shares
+func reuseCeremony(c *gin.Context, services interfaces.Services, electionID, sourceID string) (*database.KeyCeremony, []database.CeremonyTrustee, *electionKey, bool) {
+	if services.GetConfig().Encryption.KeyRotation {
+		c.JSON(http.StatusConflict, types.ErrorResponse{
+			Error:   "key_rotation_enabled",
+			Code:    409,
+			Message: "Key rotation is enabled; every election needs a fresh key ceremony",
+		})
+		return nil, nil, nil, false
+	}
+	if sourceID == electionID {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    400,
+			Message: "An election cannot reuse its own key",
+		})
+		return nil, nil, nil, false
+	}
+
+	source, err := services.CeremonyRepository().Get(sourceID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, types.ErrorResponse{
+			Error:   "key_ceremony_not_found",
+			Code:    404,
+			Message: "Election " + sourceID + " has no key ceremony",
+		})
+		return nil, nil, nil, false
+	}
+	if err != nil {
+		services.GetLogger().Error("Failed to load key ceremony: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "database_error", Code: 500, Message: "Failed to load key ceremony"})
+		return nil, nil, nil, false
+	}
+	if source.CompletedAt == nil {
+		c.JSON(http.StatusConflict, types.ErrorResponse{
+			Error:   "key_ceremony_incomplete",
+			Code:    409,
+			Message: "Every trustee of election " + sourceID + " must collect their share before its key is reused",
+		})
+		return nil, nil, nil, false
+	}
+
+	sourceKey, err := loadElectionKey(services, sourceID)
+	if err != nil {
+		services.GetLogger().Error("Failed to load ballot key: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "database_error", Code: 500, Message: "Failed to load ballot key"})
+		return nil, nil, nil, false
+	}
+	sourceTrustees, err := services.CeremonyRepository().ListTrustees(sourceID)
+	if err != nil {
+		services.GetLogger().Error("Failed to load trustees: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "database_error", Code: 500, Message: "Failed to load trustees"})
+		return nil, nil, nil, false
+	}
+
+	// Trustees already hold their shares
+	now := time.Now().UTC()
+	trustees := make([]database.CeremonyTrustee, len(sourceTrustees))
+	for i, trustee := range sourceTrustees {
+		trustees[i] = database.CeremonyTrustee{
+			ElectionID:   electionID,
+			TrusteeIndex: trustee.TrusteeIndex,
+			UserID:       trustee.UserID,
+			CollectedAt:  &now,
+		}
+	}
+
+	key, ok := saveBallotKey(c, services, electionID, sourceKey.publicKey, source.Threshold, sourceKey.verificationKeys)
+	if !ok {
+		return nil, nil, nil, false
+	}
+
+	ceremony := &database.KeyCeremony{
+		ElectionID:  electionID,
+		Threshold:   source.Threshold,
+		Trustees:    source.Trustees,
+		Commitments: source.Commitments,
+		ReusedFrom:  &sourceID,
+		CreatedBy:   c.GetString("user_id"),
+	}
+	return ceremony, trustees, key, true
+}
+
+// canHoldKeyShare reports whether a user is an active account allowed to decrypt tallies
+func canHoldKeyShare(services interfaces.Services, userID int64) bool {
+	user, err := services.UserRepository().GetByID(userID)
+	if err != nil || !user.IsActive {
+		return false
+	}
+	extra, _ := models.ParsePermissions(user.Permissions)
+	for _, perm := range models.PermissionsForRole(user.Role, extra) {
+		if perm == models.PermTallyDecrypt {
+			return true
+		}
+	}
+	return false
+}
+
+// ceremonyInfo describes a ceremony with each trustee's progress
+func ceremonyInfo(services interfaces.Services, ceremony *database.KeyCeremony, key *electionKey) (*types.KeyCeremony, error) {
+	trustees, err := services.CeremonyRepository().ListTrustees(ceremony.ElectionID)
+	if err != nil {
+		return nil, err
+	}
+	shares, err := services.CeremonyRepository().ListDecryptionShares(ceremony.ElectionID)
+	if err != nil {
+		return nil, err
+	}
+	submitted := make(map[int]bool, len(shares))
+	for _, share := range shares {
+		submitted[share.TrusteeIndex] = true
+	}
+
+	info := &types.KeyCeremony{
+		ElectionID: ceremony.ElectionID,
+		Threshold:  ceremony.Threshold,
+		PublicKey:  key.record.PublicKey,
+		Trustees:   make([]types.CeremonyTrustee, len(trustees)),
+		CreatedAt:  ceremony.CreatedAt.Unix(),
+		KeyStatus:  key.record.Status,
+	}
+	if err := json.Unmarshal([]byte(ceremony.Commitments), &info.Commitments); err != nil {
+		return nil, err
+	}
+	if ceremony.ReusedFrom != nil {
+		info.ReusedFrom = *ceremony.ReusedFrom
+	}
+	if ceremony.CompletedAt != nil {
+		completedAt := ceremony.CompletedAt.Unix()
+		info.CompletedAt = &completedAt
+	}
+	for i, trustee := range trustees {
+		info.Trustees[i] = types.CeremonyTrustee{
+			Index:     trustee.TrusteeIndex,
+			UserID:    trustee.UserID,
+			Collected: trustee.CollectedAt != nil,
+			Submitted: submitted[trustee.TrusteeIndex],
+		}
+		if trustee.CollectedAt != nil {
+			collectedAt := trustee.CollectedAt.Unix()
+			info.Trustees[i].CollectedAt = &collectedAt
+		}
+	}
+	return info, nil
+}
+
+// GetKeyCeremony returns an election's key ceremony and trustee progress (Admin only)
+func GetKeyCeremony(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		electionID, _, ok := parseElectionID(c)
+		if !ok {
+			return
+		}
+
+		ceremony, err := services.CeremonyRepository().Get(electionID)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error:   "key_ceremony_not_found",
+				Code:    404,
+				Message: "Election has no key ceremony",
+			})
+			return
+		}
+		var key *electionKey
+		if err == nil {
+			key, err = loadElectionKey(services, electionID)
+		}
+		var info *types.KeyCeremony
+		if err == nil {
+			info, err = ceremonyInfo(services, ceremony, key)
+		}
+		if err != nil {
+			services.GetLogger().Error("Failed to load key ceremony: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "database_error", Code: 500, Message: "Failed to load key ceremony"})
+			return
+		}
+
+		c.JSON(http.StatusOK, types.SuccessResponse{Success: true, Data: info})
+	}
+}
+
+// ListTrusteeSeats lists the elections the caller is a trustee of (Trustee only)
+func ListTrusteeSeats(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := strconv.ParseInt(c.GetString("user_id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusNotFound, types.ErrorResponse{Error: "not_a_trustee", Code: 404, Message: "Only user accounts can be trustees"})
+			return
+		}
+
+		seats, err := services.CeremonyRepository().ListByUser(userID)
+		if err != nil {
+			services.GetLogger().Error("Failed to list trustee seats: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "database_error", Code: 500, Message: "Failed to list elections"})
+			return
+		}
+
+		response := make([]types.TrusteeSeat, 0, len(seats))
+		for _, seat := range seats {
+			item := types.TrusteeSeat{
+				ElectionID: seat.ElectionID,
+				Index:      seat.TrusteeIndex,
+				Collected:  seat.CollectedAt != nil,
+			}
+			if key, err := services.ElectionKeyRepository().Get(seat.ElectionID); err == nil {
+				item.KeyStatus = key.Status
+			}
+			if shares, err := services.CeremonyRepository().ListDecryptionShares(seat.ElectionID); err == nil {
+				for _, share := range shares {
+					if share.TrusteeIndex == seat.TrusteeIndex {
+						item.Submitted = true
+					}
+				}
+			}
+			response = append(response, item)
+		}
+
+		c.JSON(http.StatusOK, types.SuccessResponse{Success: true, Data: response})
+	}
+}
+
+// trusteeSeat loads the caller's seat in the :id election's ceremony,
+// writing an error response if they are not one of its trustees
+func trusteeSeat(c *gin.Context, services interfaces.Services) (string, int64, *database.CeremonyTrustee, bool) {
+	electionID, id, ok := parseElectionID(c)
+	if !ok {
+		return "", 0, nil, false
+	}
+
+	notTrustee := types.ErrorResponse{
+		Error:   "not_a_trustee",
+		Code:    404,
+		Message: "You are not a trustee of this election",
+	}
+	userID, err := strconv.ParseInt(c.GetString("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusNotFound, notTrustee)
+		return "", 0, nil, false
+	}
+	seat, err := services.CeremonyRepository().GetTrustee(electionID, userID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, notTrustee)
+		return "", 0, nil, false
+	}
+	if err != nil {
+		services.GetLogger().Error("Failed to load trustee seat: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "database_error", Code: 500, Message: "Failed to load trustee"})
+		return "", 0, nil, false
+	}
+	return electionID, id, seat, true
+}
+
+// CollectKeyShare hands the caller their key share for an election, exactly
+// once. The share is erased from the server as it is collected; the trustee
+// checks it against the published commitments and stores it offline.
+func CollectKeyShare(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		electionID, _, seat, ok := trusteeSeat(c, services)
+		if !ok {
+			return
+		}
+		if seat.SealedShare == nil {
+			c.JSON(http.StatusGone, types.ErrorResponse{
+				Error:   "share_already_collected",
+				Code:    410,
+				Message: "Your key share has already been collected",
+			})
+			return
+		}
+
+		ceremony, err := services.CeremonyRepository().Get(electionID)
+		if err != nil {
+			services.GetLogger().Error("Failed to load key ceremony: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "database_error", Code: 500, Message: "Failed to load key ceremony"})
+			return
+		}
+		key, err := loadElectionKey(services, electionID)
+		if err != nil {
+			services.GetLogger().Error("Failed to load ballot key: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "database_error", Code: 500, Message: "Failed to load ballot key"})
+			return
+		}
+
+		// Open and check the share before it is erased
+		var response types.TrusteeKeyShare
+		if err := json.Unmarshal([]byte(ceremony.Commitments), &response.Commitments); err != nil {
+			services.GetLogger().Error("Stored commitments for election %s are invalid: %v", electionID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "ceremony_error", Code: 500, Message: "Invalid key ceremony"})
+			return
+		}
+		commitments := make([]tally.Point, len(response.Commitments))
+		for i, s := range response.Commitments {
+			if commitments[i], err = tally.DecodePoint(s); err != nil {
+				break
+			}
+		}
+		var plaintext string
+		if err == nil {
+			plaintext, err = auth.Open(sealingKey(services), *seat.SealedShare)
+		}
+		if err == nil {
+			err = json.Unmarshal([]byte(plaintext), &response.Share)
+		}
+		if err == nil {
+			err = response.Share.Verify(commitments)
+		}
+		if err != nil {
+			services.GetLogger().Error("Key share %d of election %s cannot be opened: %v", seat.TrusteeIndex, electionID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "ceremony_error", Code: 500, Message: "Failed to open key share"})
+			return
+		}
+
+		if _, err := services.CeremonyRepository().CollectShare(electionID, seat.TrusteeIndex); err == sql.ErrNoRows {
+			c.JSON(http.StatusGone, types.ErrorResponse{
+				Error:   "share_already_collected",
+				Code:    410,
+				Message: "Your key share has already been collected",
+			})
+			return
+		} else if err != nil {
+			services.GetLogger().Error("Failed to collect key share: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "database_error", Code: 500, Message: "Failed to collect key share"})
+			return
+		}
+
+		createAuditLog(services, "key_share_collected", c.GetString("user_id"), "",
+			fmt.Sprintf("Trustee %d collected their key share for election %s", seat.TrusteeIndex, electionID), getClientIP(c))
+
+		if completed, err := services.CeremonyRepository().MarkCompleted(electionID); err != nil {
+			services.GetLogger().Error("Failed to complete key ceremony: %v", err)
+		} else if completed {
+			createAuditLog(services, "key_ceremony_completed", "system", "",
+				fmt.Sprintf("All %d trustees of election %s hold their key shares", ceremony.Trustees, electionID), "")
+		}
+
+		response.ElectionID = electionID
+		response.Threshold = ceremony.Threshold
+		response.PublicKey = key.record.PublicKey
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data:    response,
+			Message: "Store this key share offline; it will not be shown again",
+		})
+	}
+}
+
+// SubmitDecryptionShare records the caller's partial decryption of an ended
+// election's tally. Each share is checked against the trustee's verification
+// key; once threshold trustees have submitted, the results are published.
+func SubmitDecryptionShare(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		electionID, id, seat, ok := trusteeSeat(c, services)
+		if !ok {
+			return
+		}
+
+		var req types.DecryptionShareRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_request",
+				Code:    400,
+				Message: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+		if req.Share.Index != seat.TrusteeIndex {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "share_index_mismatch",
+				Code:    400,
+				Message: fmt.Sprintf("You hold key share %d", seat.TrusteeIndex),
+			})
+			return
+		}
+
+		key, ok := loadClosedElectionKey(c, services, electionID)
+		if !ok {
+			return
+		}
+		if key.record.Status == database.ElectionKeyDecrypted {
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "already_decrypted",
+				Code:    409,
+				Message: "The tally has already been decrypted",
+			})
+			return
+		}
+
+		result, err := encryptedTally(services, key, id)
+		if err != nil {
+			services.GetLogger().Error("Failed to compute tally for election %s: %v", electionID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "tally_error", Code: 500, Message: "Failed to compute tally"})
+			return
+		}
+		verificationKeys, err := key.trusteeKeys()
+		if err != nil {
+			services.GetLogger().Error("Stored verification keys are invalid: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "tally_error", Code: 500, Message: "Invalid stored verification key"})
+			return
+		}
+
+		if err := tally.VerifyShare(result, req.Share, verificationKeys[seat.TrusteeIndex]); err != nil {
+			services.GetLogger().Warning("Rejected decryption share %d for election %s: %v", seat.TrusteeIndex, electionID, err)
+			createAuditLog(services, "decryption_share_rejected", c.GetString("user_id"), "",
+				fmt.Sprintf("Election %s, trustee %d: %v", electionID, seat.TrusteeIndex, err), getClientIP(c))
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "invalid_decryption_share", Code: 400, Message: err.Error()})
+			return
+		}
+
+		encoded, _ := json.Marshal(req.Share)
+		if err := services.CeremonyRepository().SaveDecryptionShare(&database.DecryptionShareRecord{
+			ElectionID:   electionID,
+			TrusteeIndex: seat.TrusteeIndex,
+			UserID:       seat.UserID,
+			Share:        string(encoded),
+		}); err != nil {
+			services.GetLogger().Error("Failed to save decryption share: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "database_error", Code: 500, Message: "Failed to save decryption share"})
+			return
+		}
+		createAuditLog(services, "decryption_share_submitted", c.GetString("user_id"), "",
+			fmt.Sprintf("Trustee %d submitted a decryption share for election %s", seat.TrusteeIndex, electionID), getClientIP(c))
+
+		stored, err := services.CeremonyRepository().ListDecryptionShares(electionID)
+		if err != nil {
+			services.GetLogger().Error("Failed to list decryption shares: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "database_error", Code: 500, Message: "Failed to list decryption shares"})
+			return
+		}
+		progress := types.DecryptionProgress{ElectionID: electionID, Submitted: len(stored), Threshold: key.record.Threshold}
+		if len(stored) < key.record.Threshold {
+			c.JSON(http.StatusOK, types.SuccessResponse{Success: true, Data: progress, Message: "Decryption share accepted"})
+			return
+		}
+
+		shares := make([]*tally.DecryptionShare, 0, len(stored))
+		indices := make([]string, 0, len(stored))
+		for _, record := range stored {
+			var share tally.DecryptionShare
+			if err := json.Unmarshal([]byte(record.Share), &share); err != nil {
+				services.GetLogger().Error("Stored decryption share %d is invalid: %v", record.TrusteeIndex, err)
+				continue
+			}
+			shares = append(shares, &share)
+			indices = append(indices, strconv.Itoa(record.TrusteeIndex))
+		}
+
+		counts, err := tally.Combine(result, shares, verificationKeys, key.record.Threshold)
+		if err == nil {
+			progress.Results, err = saveTallyResults(services, key, result, counts,
+				strings.Join(indices, ","), c.GetString("user_id"), getClientIP(c))
+		}
+		if err != nil {
+			services.GetLogger().Error("Failed to decrypt tally for election %s: %v", electionID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "tally_error", Code: 500, Message: "Failed to decrypt tally"})
+			return
+		}
+
+		c.JSON(http.StatusOK, types.SuccessResponse{Success: true, Data: progress, Message: "Tally decrypted"})
+	}
+}
@@ -130,6 +130,12 @@ func GetElectionResults(services interfaces.Services) gin.HandlerFunc {
 			return
 		}
 
+		// Encrypted ballots are only counted by decrypting the tally
+		if _, err := services.ElectionKeyRepository().Get(electionID); err == nil {
+			writeTallyResults(c, services, electionID)
+			return
+		}
+
 		// Prefer on-chain results if connected
 		if services.GetConnManager().IsConnected() {
 			bcID := new(big.Int).SetInt64(id)
@@ -380,6 +386,18 @@ func StartElection(services interfaces.Services) gin.HandlerFunc {
 			return
 		}
 
+		// Freeze the roll and publish its root, which the contract requires
+		// before the election can start
+		roll, ok := takeRollSnapshot(c, services, electionID.String())
+		if !ok {
+			return
+		}
+		if err := publishRoll(services, electionID, roll); err != nil {
+			services.GetLogger().Error("Publishing voter roll root failed: %v", err)
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "blockchain_error", Code: 400, Message: err.Error()})
+			return
+		}
+
 		tx, err := services.GetBlockchainClient().StartElection(electionID)
 		if err != nil {
 			services.GetLogger().Error("StartElection on-chain failed: %v", err)
@@ -404,6 +422,8 @@ func StartElection(services interfaces.Services) gin.HandlerFunc {
 			Data: map[string]interface{}{
 				"election_id": electionID.String(),
 				"tx_hash":     receipt.TxHash.Hex(),
+				"voter_count": roll.VoterCount,
+				"roll_root":   roll.MerkleRoot,
 			},
 		})
 	}
@@ -446,6 +466,11 @@ func EndElection(services interfaces.Services) gin.HandlerFunc {
 			_ = services.ElectionRepository().UpdateElectionStatus(e.ID, false)
 		}
 
+		// Stop accepting encrypted ballots so the tally can be decrypted
+		if err := services.ElectionKeyRepository().Close(electionID.String()); err != nil {
+			services.GetLogger().Error("Failed to close ballot key: %v", err)
+		}
+
 		services.GetLogger().Info("Election ended - election_id: %s, tx: %s", electionID.String(), receipt.TxHash.Hex())
 
 		c.JSON(http.StatusOK, types.SuccessResponse{
@@ -477,6 +502,16 @@ func RegisterCandidates(services interfaces.Services) gin.HandlerFunc {
 			return
 		}
 
+		// Encrypted ballots are laid out for the candidates known when the key was registered
+		if _, err := services.ElectionKeyRepository().Get(electionID.String()); err == nil {
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "ballot_key_registered",
+				Code:    409,
+				Message: "Candidates cannot be added after the ballot key is registered",
+			})
+			return
+		}
+
 		if !services.GetConnManager().IsConnected() {
 			c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{Error: "blockchain_offline", Code: 503, Message: "Blockchain is offline"})
 			return
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"voting-system/internal/api/interfaces"
+	"voting-system/internal/api/types"
+	"voting-system/internal/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// chainElectionID parses the blockchain ID of a cached election
+func chainElectionID(election *database.Election) (*big.Int, error) {
+	id, ok := new(big.Int).SetString(election.BlockchainID, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid blockchain ID %q of election %d", election.BlockchainID, election.ID)
+	}
+	return id, nil
+}
+
+// checkEligibility checks that a voter is on the election's roll by proving
+// their verification hash against the roll snapshot's Merkle root. Elections
+// started before snapshots were taken check the linked roll instead. It
+// writes the response and returns false if the voter is not on the roll.
+func checkEligibility(c *gin.Context, services interfaces.Services, election *database.Election, voter *database.Voter, verificationHash, pollingUnitID, clientIP string) bool {
+	var eligible bool
+	roll, err := services.ElectionRollRepository().Get(election.BlockchainID)
+	switch {
+	case err == nil:
+		eligible, err = onRoll(services, roll, verificationHash)
+	case errors.Is(err, sql.ErrNoRows):
+		eligible, err = services.ElectionVoterRepository().IsEligible(election.BlockchainID, voter.NIN)
+	}
+	if err != nil {
+		services.GetLogger().Error("Failed to check voter eligibility: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "database_error",
+			Code:    500,
+			Message: "Failed to check voter eligibility",
+		})
+		return false
+	}
+	if !eligible {
+		services.GetLogger().Warning("Voter not on the roll - nin: %s, election: %s", voter.NIN, election.BlockchainID)
+		createAuditLog(services, "vote_rejected_not_eligible", voter.NIN, pollingUnitID,
+			fmt.Sprintf("Voter is not on the roll of election %s", election.BlockchainID), clientIP)
+		c.JSON(http.StatusForbidden, types.ErrorResponse{
+			Error:   "not_eligible",
+			Code:    403,
+			Message: "Voter is not on the roll of this election",
+		})
+		return false
+	}
+	return true
+}
+
+// statusElection returns the election a voter status request is for: the
+// election_id query parameter, or the active election. It writes the
+// response and returns nil if there is neither.
+func statusElection(c *gin.Context, services interfaces.Services) *big.Int {
+	if param := c.Query("election_id"); param != "" {
+		id, ok := new(big.Int).SetString(param, 10)
+		if !ok || id.Sign() <= 0 {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_election_id",
+				Code:    400,
+				Message: "Invalid election ID format",
+			})
+			return nil
+		}
+		return id
+	}
+
+	election, err := activeElection(services)
+	if errors.Is(err, errNoActiveElection) {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "no_active_election",
+			Code:    400,
+			Message: "No active election found; pass election_id",
+		})
+		return nil
+	}
+	var id *big.Int
+	if err == nil {
+		id, err = chainElectionID(election)
+	}
+	if err != nil {
+		services.GetLogger().Error("Failed to load the active election: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "database_error",
+			Code:    500,
+			Message: "Failed to load the active election",
+		})
+		return nil
+	}
+	return id
+}
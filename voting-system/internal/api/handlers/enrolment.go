@@ -0,0 +1,427 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"voting-system/internal/api/interfaces"
+	"voting-system/internal/api/types"
+	"voting-system/internal/database"
+	"voting-system/internal/votesig"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gin-gonic/gin"
+)
+
+// enrolmentMaxAge is how far an enrolment request's timestamp may be from the
+// server's clock
+const enrolmentMaxAge = 5 * time.Minute
+
+// newEnrolmentCode returns a random code formatted for reading out, such as
+// "ABCD-EFGH-IJKL-MNOP"
+func newEnrolmentCode() (string, error) {
+	raw := make([]byte, 10)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	code := base32.StdEncoding.EncodeToString(raw)
+	return code[0:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:16], nil
+}
+
+// enrolmentCodeHash hashes a code the way it is stored, ignoring case,
+// dashes and spaces
+func enrolmentCodeHash(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToUpper(code))
+	hash := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(hash[:])
+}
+
+// IssueEnrolmentCode issues a one-time code for enrolling a terminal at a
+// polling unit. The code is returned once and only its hash is stored. (admin)
+func IssueEnrolmentCode(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.EnrolmentCodeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_request",
+				Code:    400,
+				Message: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		validFor := time.Duration(req.ValidFor) * time.Second
+		if validFor == 0 {
+			validFor = services.GetConfig().Fleet.EnrolmentCodeTTL
+		}
+		if validFor <= 0 {
+			validFor = 24 * time.Hour
+		}
+
+		code, err := newEnrolmentCode()
+		if err != nil {
+			services.GetLogger().Error("Failed to generate enrolment code: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "server_error",
+				Code:    500,
+				Message: "Failed to generate enrolment code",
+			})
+			return
+		}
+
+		issuedBy := c.GetString("user_id")
+		record := &database.TerminalEnrolmentCode{
+			CodeHash:      enrolmentCodeHash(code),
+			PollingUnitID: req.PollingUnitID,
+			TerminalID:    req.TerminalID,
+			IssuedBy:      issuedBy,
+			ExpiresAt:     time.Now().Add(validFor).UTC(),
+		}
+		if err := services.TerminalEnrolmentRepository().IssueCode(record); err != nil {
+			services.GetLogger().Error("Failed to store enrolment code: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to store enrolment code",
+			})
+			return
+		}
+
+		details := "Terminal enrolment code issued, valid until " + record.ExpiresAt.Format(time.RFC3339)
+		if req.TerminalID != "" {
+			details += " for terminal " + req.TerminalID
+		}
+		createAuditLog(services, "terminal_enrolment_code_issued", issuedBy, req.PollingUnitID, details, getClientIP(c))
+
+		c.JSON(http.StatusCreated, types.SuccessResponse{
+			Success: true,
+			Message: "Enrolment code issued",
+			Data: types.EnrolmentCodeResponse{
+				Code:          code,
+				PollingUnitID: record.PollingUnitID,
+				TerminalID:    record.TerminalID,
+				ExpiresAt:     record.ExpiresAt,
+			},
+		})
+	}
+}
+
+// ListEnrolmentCodes lists issued enrolment codes, optionally for one polling
+// unit, without the codes themselves (admin)
+func ListEnrolmentCodes(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := 50
+		offset := 0
+		if limitStr := c.Query("limit"); limitStr != "" {
+			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 1000 {
+				limit = l
+			}
+		}
+		if offsetStr := c.Query("offset"); offsetStr != "" {
+			if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+				offset = o
+			}
+		}
+
+		codes, err := services.TerminalEnrolmentRepository().ListCodes(c.Query("polling_unit_id"), limit, offset)
+		if err != nil {
+			services.GetLogger().Error("Failed to list enrolment codes: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to list enrolment codes",
+			})
+			return
+		}
+		if codes == nil {
+			codes = []database.TerminalEnrolmentCode{}
+		}
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"codes":  codes,
+				"limit":  limit,
+				"offset": offset,
+			},
+		})
+	}
+}
+
+// EnrolTerminal takes a terminal's enrolment request. The request must be
+// signed with the key being enrolled and carry an unused enrolment code for
+// the polling unit. The terminal is stored as pending until an admin approves
+// it. Resubmitting with the same key reports the terminal's status.
+func EnrolTerminal(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.TerminalEnrolmentRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_request",
+				Code:    400,
+				Message: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+		if !common.IsHexAddress(req.Address) {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_address",
+				Code:    400,
+				Message: "Invalid terminal Ethereum address",
+			})
+			return
+		}
+		address := common.HexToAddress(req.Address)
+
+		rawKey, err := hexutil.Decode(req.PublicKey)
+		if err != nil {
+			rawKey, err = hex.DecodeString(req.PublicKey)
+		}
+		publicKey, keyErr := crypto.UnmarshalPubkey(rawKey)
+		if err != nil || keyErr != nil || crypto.PubkeyToAddress(*publicKey) != address {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_public_key",
+				Code:    400,
+				Message: "Public key must be an uncompressed secp256k1 key for the terminal's address",
+			})
+			return
+		}
+
+		clientIP := getClientIP(c)
+		enrolment := &votesig.Enrolment{
+			TerminalID:    req.TerminalID,
+			PollingUnitID: req.PollingUnitID,
+			Address:       address,
+			Code:          req.Code,
+			Timestamp:     req.Timestamp,
+		}
+		signer, err := votesig.RecoverEnrolment(voteDomain(services), enrolment, req.Signature)
+		if err != nil || signer != address {
+			services.GetLogger().SecurityLogger("invalid_enrolment_signature", req.TerminalID,
+				fmt.Sprintf("enrolment request for %s from %s is not signed by its key", req.Address, clientIP))
+			c.JSON(http.StatusUnauthorized, types.ErrorResponse{
+				Error:   "invalid_signature",
+				Code:    401,
+				Message: "Enrolment request must be signed with the terminal's key",
+			})
+			return
+		}
+		if now := time.Now(); enrolment.SignedAt().After(now.Add(enrolmentMaxAge)) || enrolment.SignedAt().Before(now.Add(-enrolmentMaxAge)) {
+			c.JSON(http.StatusUnauthorized, types.ErrorResponse{
+				Error:   "stale_request",
+				Code:    401,
+				Message: "Timestamp outside allowed window",
+			})
+			return
+		}
+
+		existing, err := services.TerminalRepository().GetTerminal(req.TerminalID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			services.GetLogger().Error("Failed to load terminal %s: %v", req.TerminalID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to load terminal",
+			})
+			return
+		}
+		if existing != nil {
+			switch {
+			case existing.Status == database.TerminalDecommissioned:
+				c.JSON(http.StatusConflict, types.ErrorResponse{
+					Error:   "terminal_decommissioned",
+					Code:    409,
+					Message: "Terminal has been decommissioned",
+				})
+				return
+			case existing.Status != database.TerminalRevoked && common.HexToAddress(existing.EthAddress) == address:
+				c.JSON(http.StatusOK, types.SuccessResponse{
+					Success: true,
+					Message: "Terminal is already enrolled",
+					Data:    types.TerminalEnrolmentResponse{TerminalID: existing.ID, Status: existing.Status, Address: address.Hex()},
+				})
+				return
+			case existing.Status != database.TerminalRevoked:
+				services.GetLogger().SecurityLogger("terminal_enrolment_conflict", req.TerminalID,
+					fmt.Sprintf("enrolment with key %s from %s for a terminal enrolled with another key", address.Hex(), clientIP))
+				c.JSON(http.StatusConflict, types.ErrorResponse{
+					Error:   "terminal_exists",
+					Code:    409,
+					Message: "Terminal is already enrolled with another key",
+				})
+				return
+			}
+		}
+
+		terminal := &database.Terminal{
+			ID:            req.TerminalID,
+			Name:          req.Name,
+			Location:      req.Location,
+			PollingUnitID: req.PollingUnitID,
+			EthAddress:    address.Hex(),
+			PublicKey:     hexutil.Encode(crypto.FromECDSAPub(publicKey)),
+		}
+		if terminal.Name == "" {
+			terminal.Name = req.TerminalID
+		}
+		if err := services.TerminalEnrolmentRepository().Enrol(enrolmentCodeHash(req.Code), terminal); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				services.GetLogger().SecurityLogger("invalid_enrolment_code", req.TerminalID,
+					fmt.Sprintf("enrolment for polling unit %s from %s with an unusable code", req.PollingUnitID, clientIP))
+				c.JSON(http.StatusForbidden, types.ErrorResponse{
+					Error:   "invalid_enrolment_code",
+					Code:    403,
+					Message: "Enrolment code is unknown, expired, used or issued for another terminal",
+				})
+				return
+			}
+			services.GetLogger().Error("Failed to enrol terminal %s: %v", req.TerminalID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to store terminal",
+			})
+			return
+		}
+
+		createAuditLog(services, "terminal_enrolment_requested", terminal.ID, terminal.PollingUnitID,
+			fmt.Sprintf("Terminal enrolment requested with key %s from %s", terminal.EthAddress, terminal.Location), clientIP)
+		services.GetLogger().Info("Terminal enrolment requested - id: %s, address: %s, polling_unit: %s",
+			terminal.ID, terminal.EthAddress, terminal.PollingUnitID)
+
+		c.JSON(http.StatusAccepted, types.SuccessResponse{
+			Success: true,
+			Message: "Enrolment request received; awaiting approval",
+			Data:    types.TerminalEnrolmentResponse{TerminalID: terminal.ID, Status: terminal.Status, Address: terminal.EthAddress},
+		})
+	}
+}
+
+// activateTerminal authorizes an approved terminal's address on chain and
+// marks it active. While the blockchain is unreachable the terminal stays
+// approved and "" is returned.
+func activateTerminal(services interfaces.Services, terminal *database.Terminal) (string, error) {
+	txHash, err := setChainAuthorization(services, terminal.EthAddress, true)
+	if err != nil || txHash == "" {
+		return "", err
+	}
+	if err := services.TerminalRepository().SetAuthorization(terminal.ID, true); err != nil {
+		return txHash, err
+	}
+	terminal.Status = database.TerminalActive
+	terminal.Authorized = true
+	return txHash, nil
+}
+
+// ApproveTerminal approves a pending terminal, issues its secret and
+// authorizes it on chain, making it active. The secret is returned once.
+// Approving an approved terminal retries the on-chain authorization. (admin)
+func ApproveTerminal(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reason, ok := bindReason(c)
+		if !ok {
+			return
+		}
+		terminal := loadTerminal(c, services, c.Param("id"))
+		if terminal == nil {
+			return
+		}
+
+		secret := ""
+		switch terminal.Status {
+		case database.TerminalPending:
+			approved, err := services.TerminalRepository().ApproveTerminal(terminal.ID, c.GetString("user_id"))
+			if err != nil {
+				services.GetLogger().Error("Failed to approve terminal %s: %v", terminal.ID, err)
+				c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+					Error:   "database_error",
+					Code:    500,
+					Message: "Failed to approve terminal",
+				})
+				return
+			}
+			if !approved {
+				c.JSON(http.StatusConflict, types.ErrorResponse{
+					Error:   "invalid_terminal_state",
+					Code:    409,
+					Message: "Terminal is no longer pending",
+				})
+				return
+			}
+			terminal.Status = database.TerminalApproved
+			createAuditLog(services, "terminal_approved", terminal.ID, terminal.PollingUnitID,
+				terminalActionDetails(c, "Terminal enrolment approved", "", reason), getClientIP(c))
+
+			// The terminal signs in with a secret of its own, shown only now
+			issued, err := issueTerminalSecret(services, terminal.ID, c.GetString("user_id"), 0)
+			if err != nil {
+				services.GetLogger().Error("Failed to issue secret for terminal %s: %v", terminal.ID, err)
+				c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+					Error:   "database_error",
+					Code:    500,
+					Message: "Terminal approved, but issuing its secret failed; rotate the secret to retry",
+				})
+				return
+			}
+			secret = issued.Secret
+			createAuditLog(services, "terminal_secret_issued", terminal.ID, terminal.PollingUnitID,
+				"Terminal secret issued on approval by user "+c.GetString("user_id"), getClientIP(c))
+		case database.TerminalApproved:
+		default:
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "invalid_terminal_state",
+				Code:    409,
+				Message: "Only pending or approved terminals can be approved; the terminal is " + terminal.Status,
+			})
+			return
+		}
+
+		txHash, err := activateTerminal(services, terminal)
+		if err != nil {
+			services.GetLogger().Error("Failed to authorize terminal %s on chain: %v", terminal.ID, err)
+			c.JSON(http.StatusBadGateway, types.ErrorResponse{
+				Error:   "blockchain_error",
+				Code:    502,
+				Message: "Terminal approved, but authorizing it on-chain failed: " + err.Error(),
+			})
+			return
+		}
+
+		status, message := http.StatusOK, "Terminal approved and authorized"
+		if txHash == "" {
+			status, message = http.StatusAccepted, "Terminal approved; approve again to authorize it once the blockchain is reachable"
+		} else {
+			createAuditLog(services, "terminal_authorized", terminal.ID, terminal.PollingUnitID,
+				terminalActionDetails(c, "Terminal authorized", txHash, reason), getClientIP(c))
+		}
+		services.GetLogger().Info("Terminal approved - terminal_id: %s, status: %s, on_chain: %t", terminal.ID, terminal.Status, txHash != "")
+
+		data := map[string]interface{}{
+			"terminal_id": terminal.ID,
+			"status":      terminal.Status,
+			"authorized":  terminal.Authorized,
+			"on_chain":    txHash != "",
+			"transaction": txHash,
+			"updated_at":  time.Now().Unix(),
+		}
+		if secret != "" {
+			data["secret"] = secret
+		}
+		c.JSON(status, types.SuccessResponse{
+			Success: true,
+			Message: message,
+			Data:    data,
+		})
+	}
+}
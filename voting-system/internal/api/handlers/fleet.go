@@ -0,0 +1,399 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"voting-system/internal/api/interfaces"
+	"voting-system/internal/api/types"
+	"voting-system/internal/database"
+	"voting-system/internal/database/repositories"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+)
+
+// ListTerminals lists terminals, filtered by status, polling_unit_id,
+// authorized, online and a search term q (admin)
+func ListTerminals(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := 50
+		offset := 0
+		if limitStr := c.Query("limit"); limitStr != "" {
+			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 1000 {
+				limit = l
+			}
+		}
+		if offsetStr := c.Query("offset"); offsetStr != "" {
+			if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+				offset = o
+			}
+		}
+
+		filter := repositories.TerminalFilter{
+			Status:        c.Query("status"),
+			PollingUnitID: c.Query("polling_unit_id"),
+			Search:        c.Query("q"),
+		}
+		for name, target := range map[string]**bool{"authorized": &filter.Authorized, "online": &filter.Online} {
+			value := c.Query(name)
+			if value == "" {
+				continue
+			}
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, types.ErrorResponse{
+					Error:   "invalid_parameter",
+					Code:    400,
+					Message: name + " must be true or false",
+				})
+				return
+			}
+			*target = &parsed
+		}
+
+		terminals, total, err := services.TerminalRepository().FindTerminals(filter, limit, offset)
+		if err != nil {
+			services.GetLogger().Error("Failed to list terminals: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to list terminals",
+			})
+			return
+		}
+		if terminals == nil {
+			terminals = []database.Terminal{}
+		}
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"terminals": terminals,
+				"limit":     limit,
+				"offset":    offset,
+				"total":     total,
+			},
+		})
+	}
+}
+
+// setChainAuthorization changes a terminal's authorization on chain and waits
+// for the transaction. It returns "" without a transaction when the
+// blockchain is unreachable.
+func setChainAuthorization(services interfaces.Services, address string, authorize bool) (string, error) {
+	if !services.GetConnManager().IsConnected() {
+		return "", nil
+	}
+	if !common.IsHexAddress(address) {
+		return "", fmt.Errorf("terminal has no valid address")
+	}
+
+	tx, err := services.GetBlockchainClient().AuthorizeTerminal(address, authorize)
+	if err != nil {
+		return "", err
+	}
+	receipt, err := services.GetBlockchainClient().WaitForTransaction(tx)
+	if err != nil {
+		return "", err
+	}
+	return receipt.TxHash.Hex(), nil
+}
+
+// bindReason reads the optional reason of an admin action.
+// On failure it writes an error response and returns false.
+func bindReason(c *gin.Context) (string, bool) {
+	var req types.TerminalActionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_request",
+				Code:    400,
+				Message: "Invalid request format: " + err.Error(),
+			})
+			return "", false
+		}
+	}
+	if req.Reason == "" {
+		req.Reason = c.Query("reason")
+	}
+	return req.Reason, true
+}
+
+// DeauthorizeTerminal revokes a terminal's authorization on chain and in the
+// database, leaving it revoked. While the chain is unreachable only the
+// database is updated; the server refuses the terminal's votes either way. A
+// revoked terminal has to enrol again. (admin)
+func DeauthorizeTerminal(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reason, ok := bindReason(c)
+		if !ok {
+			return
+		}
+		terminal := loadTerminal(c, services, c.Param("id"))
+		if terminal == nil {
+			return
+		}
+		if terminal.Status == database.TerminalDecommissioned {
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "terminal_decommissioned",
+				Code:    409,
+				Message: "Terminal has already been decommissioned",
+			})
+			return
+		}
+
+		txHash, err := setChainAuthorization(services, terminal.EthAddress, false)
+		if err != nil {
+			services.GetLogger().Error("Failed to deauthorize terminal %s on chain: %v", terminal.ID, err)
+			c.JSON(http.StatusBadGateway, types.ErrorResponse{
+				Error:   "blockchain_error",
+				Code:    502,
+				Message: "Failed to deauthorize terminal on-chain: " + err.Error(),
+			})
+			return
+		}
+
+		if err := services.TerminalRepository().SetAuthorization(terminal.ID, false); err != nil {
+			services.GetLogger().Error("Failed to deauthorize terminal %s: %v", terminal.ID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to deauthorize terminal",
+			})
+			return
+		}
+
+		revokeTerminalSecrets(services, terminal.ID)
+
+		createAuditLog(services, "terminal_deauthorized", terminal.ID, terminal.PollingUnitID,
+			terminalActionDetails(c, "Terminal deauthorized", txHash, reason), getClientIP(c))
+		services.GetLogger().Info("Terminal deauthorized - terminal_id: %s, on_chain: %t, reason: %s", terminal.ID, txHash != "", reason)
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Message: "Terminal deauthorized",
+			Data: map[string]interface{}{
+				"terminal_id": terminal.ID,
+				"status":      database.TerminalRevoked,
+				"authorized":  false,
+				"on_chain":    txHash != "",
+				"transaction": txHash,
+				"updated_at":  time.Now().Unix(),
+			},
+		})
+	}
+}
+
+// RemoveTerminal decommissions a terminal: it is deauthorized on chain, can no
+// longer sign in, and its open alerts are closed. The record is kept for the
+// audit trail. (admin)
+func RemoveTerminal(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reason, ok := bindReason(c)
+		if !ok {
+			return
+		}
+		terminal := loadTerminal(c, services, c.Param("id"))
+		if terminal == nil {
+			return
+		}
+		if terminal.Status == database.TerminalDecommissioned {
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "terminal_decommissioned",
+				Code:    409,
+				Message: "Terminal has already been decommissioned",
+			})
+			return
+		}
+
+		txHash, err := setChainAuthorization(services, terminal.EthAddress, false)
+		if err != nil {
+			services.GetLogger().Error("Failed to deauthorize terminal %s on chain: %v", terminal.ID, err)
+			c.JSON(http.StatusBadGateway, types.ErrorResponse{
+				Error:   "blockchain_error",
+				Code:    502,
+				Message: "Failed to deauthorize terminal on-chain: " + err.Error(),
+			})
+			return
+		}
+
+		if err := services.TerminalRepository().DecommissionTerminal(terminal.ID); err != nil {
+			services.GetLogger().Error("Failed to decommission terminal %s: %v", terminal.ID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to decommission terminal",
+			})
+			return
+		}
+		if _, err := services.TerminalAlertRepository().ResolveAll(terminal.ID); err != nil {
+			services.GetLogger().Error("Failed to close alerts of terminal %s: %v", terminal.ID, err)
+		}
+		revokeTerminalSecrets(services, terminal.ID)
+
+		createAuditLog(services, "terminal_decommissioned", terminal.ID, terminal.PollingUnitID,
+			terminalActionDetails(c, "Terminal decommissioned", txHash, reason), getClientIP(c))
+		services.GetLogger().Info("Terminal decommissioned - terminal_id: %s, on_chain: %t, reason: %s", terminal.ID, txHash != "", reason)
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Message: "Terminal decommissioned",
+			Data: map[string]interface{}{
+				"terminal_id": terminal.ID,
+				"status":      database.TerminalDecommissioned,
+				"on_chain":    txHash != "",
+				"transaction": txHash,
+				"updated_at":  time.Now().Unix(),
+			},
+		})
+	}
+}
+
+// BulkAuthorizeTerminals approves every pending terminal of a polling unit and
+// authorizes every approved one on chain, reporting the outcome for each
+// terminal (admin)
+func BulkAuthorizeTerminals(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.BulkAuthorizeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_request",
+				Code:    400,
+				Message: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		terminals, err := services.TerminalRepository().GetTerminalsByPollingUnit(req.PollingUnitID)
+		if err != nil {
+			services.GetLogger().Error("Failed to load terminals of polling unit %s: %v", req.PollingUnitID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to load terminals",
+			})
+			return
+		}
+		if len(terminals) == 0 {
+			c.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error:   "no_terminals",
+				Code:    404,
+				Message: "Polling unit has no registered terminals",
+			})
+			return
+		}
+
+		resp := types.BulkOperationResponse{
+			PollingUnitID: req.PollingUnitID,
+			Summary:       map[string]int{types.TerminalOpDone: 0, types.TerminalOpSkipped: 0, types.TerminalOpFailed: 0},
+			Results:       make([]types.TerminalOperationResult, 0, len(terminals)),
+		}
+		for _, terminal := range terminals {
+			result := types.TerminalOperationResult{TerminalID: terminal.ID}
+			switch terminal.Status {
+			case database.TerminalActive:
+				result.Status, result.Error = types.TerminalOpSkipped, "terminal is already active"
+			case database.TerminalPending, database.TerminalApproved:
+				if terminal.Status == database.TerminalPending {
+					if _, err := services.TerminalRepository().ApproveTerminal(terminal.ID, c.GetString("user_id")); err != nil {
+						services.GetLogger().Error("Failed to approve terminal %s: %v", terminal.ID, err)
+						result.Status, result.Error = types.TerminalOpFailed, err.Error()
+						break
+					}
+					terminal.Status = database.TerminalApproved
+					createAuditLog(services, "terminal_approved", terminal.ID, terminal.PollingUnitID,
+						terminalActionDetails(c, "Terminal enrolment approved with its polling unit", "", req.Reason), getClientIP(c))
+				}
+				result.Transaction, err = activateTerminal(services, &terminal)
+				if err != nil {
+					services.GetLogger().Error("Failed to authorize terminal %s: %v", terminal.ID, err)
+					result.Status, result.Error = types.TerminalOpFailed, err.Error()
+					break
+				}
+				result.Status = types.TerminalOpDone
+				if result.Transaction != "" {
+					createAuditLog(services, "terminal_authorized", terminal.ID, terminal.PollingUnitID,
+						terminalActionDetails(c, "Terminal authorized with its polling unit", result.Transaction, req.Reason), getClientIP(c))
+				}
+			default:
+				result.Status, result.Error = types.TerminalOpSkipped, "terminal is "+terminal.Status
+			}
+			result.TerminalStatus = terminal.Status
+			resp.Summary[result.Status]++
+			resp.Results = append(resp.Results, result)
+		}
+
+		services.GetLogger().Info("Bulk terminal authorization - polling_unit: %s, authorized: %d, skipped: %d, failed: %d",
+			req.PollingUnitID, resp.Summary[types.TerminalOpDone], resp.Summary[types.TerminalOpSkipped], resp.Summary[types.TerminalOpFailed])
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data:    resp,
+			Message: fmt.Sprintf("%d of %d terminals approved", resp.Summary[types.TerminalOpDone], len(terminals)),
+		})
+	}
+}
+
+// terminalActionDetails describes an admin action on a terminal for its audit trail
+func terminalActionDetails(c *gin.Context, action, txHash, reason string) string {
+	details := fmt.Sprintf("%s by user %s", action, c.GetString("user_id"))
+	if txHash != "" {
+		details += " in transaction " + txHash
+	} else {
+		details += " (blockchain not updated)"
+	}
+	if reason != "" {
+		details += ": " + reason
+	}
+	return details
+}
+
+// GetTerminalLogs returns a terminal's audit trail, newest first (admin)
+func GetTerminalLogs(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		terminal := loadTerminal(c, services, c.Param("id"))
+		if terminal == nil {
+			return
+		}
+
+		limit := 100
+		offset := 0
+		if limitStr := c.Query("limit"); limitStr != "" {
+			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 1000 {
+				limit = l
+			}
+		}
+		if offsetStr := c.Query("offset"); offsetStr != "" {
+			if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+				offset = o
+			}
+		}
+
+		logs, err := services.AuditLogRepository().GetAuditLogsByUser(terminal.ID, limit, offset)
+		if err != nil {
+			services.GetLogger().Error("Failed to load audit trail of terminal %s: %v", terminal.ID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "audit_error",
+				Code:    500,
+				Message: "Failed to retrieve terminal logs",
+			})
+			return
+		}
+		if logs == nil {
+			logs = []database.AuditLog{}
+		}
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"terminal_id": terminal.ID,
+				"logs":        logs,
+				"limit":       limit,
+				"offset":      offset,
+			},
+		})
+	}
+}
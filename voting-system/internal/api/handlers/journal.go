@@ -0,0 +1,414 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"voting-system/internal/api/interfaces"
+	"voting-system/internal/api/types"
+	"voting-system/internal/database"
+	"voting-system/internal/journal"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadJournal accepts a batch of a terminal's journaled votes. Entries must
+// continue the terminal's hash chain; each one is replayed through CastVote
+// and its outcome stored, so uploading the same entry again returns the
+// stored outcome instead of casting twice. Processing stops at the first entry
+// that breaks the chain or that the server cannot decide on right now.
+func UploadJournal(services interfaces.Services) gin.HandlerFunc {
+	castVote := CastVote(services)
+
+	return func(c *gin.Context) {
+		// Each vote is also checked against the terminal's polling unit as it is replayed
+		if _, ok := terminalPollingUnit(c, services, "", "journal upload"); !ok {
+			return
+		}
+
+		var req types.JournalUpload
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_request",
+				Code:    400,
+				Message: "Invalid request: " + err.Error(),
+			})
+			return
+		}
+
+		deviceID := c.GetString("user_id")
+		last, err := services.TerminalJournalRepository().Last(deviceID)
+		if err != nil && err != sql.ErrNoRows {
+			services.GetLogger().Error("Failed to load journal of terminal %s: %v", deviceID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to load terminal journal",
+			})
+			return
+		}
+
+		// Entries signed before a rotation verify with the older secrets
+		stored, err := services.TerminalSecretRepository().Unrevoked(deviceID)
+		if err != nil {
+			services.GetLogger().Error("Failed to load secrets of terminal %s: %v", deviceID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to load terminal secrets",
+			})
+			return
+		}
+		secrets, err := openTerminalSecrets(services, stored)
+		if err != nil {
+			services.GetLogger().Error("Failed to unseal secrets of terminal %s: %v", deviceID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "server_error",
+				Code:    500,
+				Message: "Failed to load terminal secrets",
+			})
+			return
+		}
+		if len(secrets) == 0 {
+			c.JSON(http.StatusForbidden, types.ErrorResponse{
+				Error:   "terminal_secret_missing",
+				Code:    403,
+				Message: "Terminal has no valid secret to verify its journal with",
+			})
+			return
+		}
+
+		sort.Slice(req.Entries, func(i, j int) bool { return req.Entries[i].Seq < req.Entries[j].Seq })
+
+		results := make([]types.JournalEntryResult, 0, len(req.Entries))
+		counts := make(map[string]int)
+		stopped := false
+		for i := range req.Entries {
+			entry := &req.Entries[i]
+			result := types.JournalEntryResult{Seq: entry.Seq, EntryHash: entry.EntryHash, Status: types.JournalNotProcessed}
+			if !stopped {
+				result = processJournalEntry(c, services, castVote, deviceID, secrets, entry, &last)
+			}
+			switch result.Status {
+			case types.JournalInvalid, types.JournalOutOfOrder, types.JournalRetry:
+				stopped = true
+			}
+			results = append(results, result)
+			counts[result.Status]++
+		}
+
+		services.GetLogger().Info("Journal upload from terminal %s: %d entries, %v", deviceID, len(results), counts)
+		createAuditLog(services, "journal_uploaded", deviceID, "",
+			fmt.Sprintf("Received %d journal entries: %v", len(results), counts), getClientIP(c))
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data:    types.JournalUploadResponse{Results: results},
+		})
+	}
+}
+
+// processJournalEntry checks one entry against the terminal's chain, replays
+// its vote and records the outcome. last is advanced when the entry is stored.
+func processJournalEntry(c *gin.Context, services interfaces.Services, castVote gin.HandlerFunc,
+	deviceID string, secrets []string, entry *types.JournalUploadEntry, last **database.TerminalJournalEntry) types.JournalEntryResult {
+
+	result := types.JournalEntryResult{Seq: entry.Seq, EntryHash: entry.EntryHash}
+	repo := services.TerminalJournalRepository()
+
+	// An entry received before: report what happened then
+	if existing, err := repo.Get(deviceID, entry.Seq); err == nil {
+		if existing.EntryHash != entry.EntryHash {
+			services.GetLogger().Warning("Terminal %s re-sent journal entry %d with a different hash", deviceID, entry.Seq)
+			createAuditLog(services, "journal_fork_detected", deviceID, existing.PollingUnitID,
+				fmt.Sprintf("Entry %d re-sent with hash %s, already received %s", entry.Seq, entry.EntryHash, existing.EntryHash),
+				getClientIP(c))
+			result.Status = types.JournalOutOfOrder
+			result.Error = "conflicts with the entry already received at this sequence number"
+			return result
+		}
+		result.Status = existing.Status
+		result.Code = existing.ResponseCode
+		result.Error = existing.Error
+		result.TransactionHash = existing.TransactionHash
+		result.Replayed = true
+		return result
+	} else if err != sql.ErrNoRows {
+		services.GetLogger().Error("Failed to look up journal entry %d of terminal %s: %v", entry.Seq, deviceID, err)
+		result.Status = types.JournalRetry
+		return result
+	}
+
+	link := journal.Entry{
+		DeviceID:         deviceID,
+		Seq:              entry.Seq,
+		PrevHash:         entry.PrevHash,
+		VerificationHash: entry.VerificationHash,
+		PayloadHash:      journal.PayloadHash(entry.Vote),
+		CreatedAt:        journal.Timestamp(entry.CreatedAt),
+	}
+	if err := link.VerifyAny(entry.EntryHash, entry.Signature, secrets); err != nil {
+		services.GetLogger().Warning("Invalid journal entry %d from terminal %s: %v", entry.Seq, deviceID, err)
+		createAuditLog(services, "journal_entry_invalid", deviceID, "",
+			fmt.Sprintf("Entry %d: %v", entry.Seq, err), getClientIP(c))
+		result.Status = types.JournalInvalid
+		result.Error = err.Error()
+		return result
+	}
+
+	expectedSeq, expectedPrev := int64(1), journal.GenesisHash
+	if *last != nil {
+		expectedSeq, expectedPrev = (*last).Seq+1, (*last).EntryHash
+	}
+	if entry.Seq != expectedSeq || entry.PrevHash != expectedPrev {
+		result.Status = types.JournalOutOfOrder
+		result.Error = fmt.Sprintf("expected entry %d following %s", expectedSeq, expectedPrev)
+		return result
+	}
+
+	// The chained verification hash must be the vote's own, derived with the
+	// salt of the election it was cast in, which may have ended by the time
+	// the journal is uploaded
+	var vote types.VoteRequest
+	if err := json.Unmarshal(entry.Vote, &vote); err != nil {
+		result.Status = types.JournalInvalid
+		result.Error = "vote is not a valid vote request"
+		return result
+	}
+	var hash string
+	var err error
+	if vote.ElectionID != "" {
+		hash, err = electionVerificationHash(services, vote.ElectionID, vote.NIN)
+	} else {
+		_, hash, err = voteVerificationHash(services, vote.NIN)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		result.Status = types.JournalInvalid
+		result.Error = "vote is for unknown election " + vote.ElectionID
+		return result
+	}
+	if err != nil {
+		result.Status = types.JournalRetry
+		result.Error = "failed to derive verification hash: " + err.Error()
+		return result
+	}
+	if hash != entry.VerificationHash {
+		result.Status = types.JournalInvalid
+		result.Error = "verification hash does not match the vote"
+		return result
+	}
+
+	code, body := replayVote(c, castVote, entry.Vote)
+	result.Code = code
+	switch {
+	case code >= 200 && code < 300:
+		var resp types.VoteResponse
+		_ = json.Unmarshal(body, &resp)
+		result.TransactionHash = resp.TransactionHash
+		result.Status = types.JournalAccepted
+		if code == http.StatusAccepted {
+			result.Status = types.JournalQueued
+		}
+	case code >= 500 || code == http.StatusRequestTimeout || code == http.StatusTooManyRequests:
+		result.Status = types.JournalRetry
+		result.Error = responseError(body)
+		return result
+	case code == http.StatusConflict:
+		result.Status = types.JournalDuplicate
+		result.Error = responseError(body)
+	default:
+		result.Status = types.JournalRejected
+		result.Error = responseError(body)
+	}
+
+	record := &database.TerminalJournalEntry{
+		DeviceID:         deviceID,
+		Seq:              entry.Seq,
+		PrevHash:         entry.PrevHash,
+		EntryHash:        entry.EntryHash,
+		Signature:        entry.Signature,
+		VerificationHash: entry.VerificationHash,
+		PollingUnitID:    vote.PollingUnitID,
+		Status:           result.Status,
+		ResponseCode:     result.Code,
+		Error:            result.Error,
+		TransactionHash:  result.TransactionHash,
+		RecordedAt:       link.CreatedAt,
+	}
+	if err := repo.Record(record); err != nil {
+		// The vote went through CastVote; uploading again reports it as a duplicate
+		services.GetLogger().Error("Failed to record journal entry %d of terminal %s: %v", entry.Seq, deviceID, err)
+		result.Status = types.JournalRetry
+		return result
+	}
+	*last = record
+
+	return result
+}
+
+// replayVote runs a journaled vote through CastVote as the uploading terminal
+// and captures the response instead of writing it to the client
+func replayVote(c *gin.Context, castVote gin.HandlerFunc, vote json.RawMessage) (int, []byte) {
+	w := &capturedResponse{header: make(http.Header)}
+	replay, _ := gin.CreateTestContext(w)
+
+	replay.Request = c.Request.Clone(c.Request.Context())
+	replay.Request.Body = io.NopCloser(bytes.NewReader(vote))
+	replay.Request.ContentLength = int64(len(vote))
+	replay.Request.Header.Set("Content-Type", "application/json")
+	for key, value := range c.Keys {
+		replay.Set(key, value)
+	}
+
+	castVote(replay)
+	return w.status, w.body.Bytes()
+}
+
+// capturedResponse is an http.ResponseWriter that keeps the response in memory
+type capturedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *capturedResponse) Header() http.Header { return w.header }
+
+func (w *capturedResponse) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(data)
+}
+
+func (w *capturedResponse) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// responseError returns the error code and message of an ErrorResponse body
+func responseError(body []byte) string {
+	var e types.ErrorResponse
+	if err := json.Unmarshal(body, &e); err != nil || e.Error == "" {
+		return ""
+	}
+	if e.Message != "" {
+		return e.Error + ": " + e.Message
+	}
+	return e.Error
+}
+
+// GetVotedSet returns the verification hashes of everyone who has voted at a
+// polling unit in the active election, so terminals can refuse repeat voters
+// while offline. Terminals only get the voted set of their own polling unit.
+func GetVotedSet(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		pollingUnitID, ok := terminalPollingUnit(c, services, c.Query("polling_unit_id"), "voted set download")
+		if !ok {
+			return
+		}
+		if pollingUnitID == "" {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "missing_parameter",
+				Code:    400,
+				Message: "polling_unit_id is required",
+			})
+			return
+		}
+
+		hashes := []string{}
+		election, err := services.ElectionRepository().GetActiveElection()
+		if err == nil {
+			electionID, _ := strconv.ParseInt(election.BlockchainID, 10, 64)
+			hashes, err = services.TerminalJournalRepository().VotedHashes(pollingUnitID, electionID)
+			if err != nil {
+				services.GetLogger().Error("Failed to load voted set for polling unit %s: %v", pollingUnitID, err)
+				c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+					Error:   "database_error",
+					Code:    500,
+					Message: "Failed to load voted set",
+				})
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, types.SuccessResponse{Success: true, Data: hashes})
+	}
+}
+
+// GetJournalReconciliation reports where the calling terminal's uploaded journal entries ended up
+func GetJournalReconciliation(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := terminalPollingUnit(c, services, "", "journal reconciliation"); !ok {
+			return
+		}
+		reconcileJournal(c, services, c.GetString("user_id"))
+	}
+}
+
+// GetTerminalReconciliation reports where a terminal's uploaded journal entries ended up (admin)
+func GetTerminalReconciliation(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reconcileJournal(c, services, c.Param("id"))
+	}
+}
+
+func reconcileJournal(c *gin.Context, services interfaces.Services, deviceID string) {
+	rows, err := services.TerminalJournalRepository().Reconcile(deviceID)
+	if err != nil {
+		services.GetLogger().Error("Failed to reconcile journal of terminal %s: %v", deviceID, err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "database_error",
+			Code:    500,
+			Message: "Failed to reconcile terminal journal",
+		})
+		return
+	}
+
+	report := types.ReconciliationReport{
+		DeviceID: deviceID,
+		Summary:  map[string]int{"received": len(rows), "on_chain": 0, "awaiting_chain": 0},
+		Entries:  make([]types.ReconciliationEntry, 0, len(rows)),
+	}
+	for _, row := range rows {
+		entry := types.ReconciliationEntry{
+			Seq:              row.Seq,
+			EntryHash:        row.EntryHash,
+			VerificationHash: row.VerificationHash,
+			Status:           row.Status,
+			RecordedAt:       row.RecordedAt,
+			ReceivedAt:       row.ReceivedAt,
+			SyncedAt:         row.SyncedAt,
+		}
+		// A duplicate's vote row belongs to the earlier ballot, not this entry
+		if row.Status == types.JournalAccepted || row.Status == types.JournalQueued {
+			if row.VoteStatus != nil {
+				entry.VoteStatus = *row.VoteStatus
+			}
+			if row.VoteTxHash != nil {
+				entry.TransactionHash = *row.VoteTxHash
+			}
+			if row.BlockNumber != nil {
+				entry.BlockNumber = *row.BlockNumber
+			}
+			entry.OnChain = entry.VoteStatus == "synced" && entry.TransactionHash != ""
+			if entry.OnChain {
+				report.Summary["on_chain"]++
+			} else {
+				report.Summary["awaiting_chain"]++
+			}
+		}
+		report.Summary[row.Status]++
+		report.LastSeq = row.Seq
+		report.Entries = append(report.Entries, entry)
+	}
+
+	c.JSON(http.StatusOK, types.SuccessResponse{Success: true, Data: report})
+}
@@ -0,0 +1,350 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+
+	"voting-system/internal/api/interfaces"
+	"voting-system/internal/api/types"
+	"voting-system/internal/database"
+	"voting-system/internal/merkle"
+	"voting-system/internal/votesig"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errEmptyRoll is returned when an election's roll has no voters to snapshot
+var errEmptyRoll = errors.New("no active voters to put on the roll")
+
+// snapshotRoll freezes an election's voter roll: the active voters are linked
+// to it and the Merkle tree of their leaves is stored. An election is
+// snapshotted once; later calls return the same snapshot.
+func snapshotRoll(services interfaces.Services, electionID string) (*database.ElectionRoll, bool, error) {
+	roll, err := services.ElectionRollRepository().Get(electionID)
+	if err == nil {
+		return roll, false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, false, err
+	}
+
+	if _, err := services.ElectionVoterRepository().LinkActiveVoters(electionID); err != nil {
+		return nil, false, fmt.Errorf("failed to link voters: %v", err)
+	}
+	nins, err := services.ElectionVoterRepository().ListNINs(electionID)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(nins) == 0 {
+		return nil, false, errEmptyRoll
+	}
+
+	salt, err := electionSalt(services, electionID)
+	if err != nil {
+		return nil, false, err
+	}
+	leaves := make([]merkle.Hash, len(nins))
+	for i, nin := range nins {
+		leaves[i] = merkle.Leaf(votesig.VerificationHash(salt, nin))
+	}
+	tree, err := merkle.New(leaves)
+	if err != nil {
+		return nil, false, err
+	}
+
+	levels := make([][]string, len(tree.Levels()))
+	for i, level := range tree.Levels() {
+		levels[i] = make([]string, len(level))
+		for j, node := range level {
+			levels[i][j] = node.Hex()
+		}
+	}
+	roll = &database.ElectionRoll{ElectionID: electionID, MerkleRoot: tree.Root().Hex(), VoterCount: tree.Size()}
+	if err := services.ElectionRollRepository().Create(roll, levels); err != nil {
+		// Another request may have taken the snapshot first
+		if existing, getErr := services.ElectionRollRepository().Get(electionID); getErr == nil {
+			return existing, false, nil
+		}
+		return nil, false, err
+	}
+	return roll, true, nil
+}
+
+// publishRoll publishes a roll's root on chain unless it already is. A
+// different root already published for the election is an error.
+func publishRoll(services interfaces.Services, electionID *big.Int, roll *database.ElectionRoll) error {
+	if roll.PublishedAt != nil {
+		return nil
+	}
+	root, err := merkle.ParseHash(roll.MerkleRoot)
+	if err != nil {
+		return err
+	}
+
+	client := services.GetBlockchainClient()
+	published, err := client.GetVoterRollRoot(electionID)
+	if err != nil {
+		return err
+	}
+	txHash := ""
+	switch {
+	case published == [32]byte{}:
+		tx, err := client.PublishVoterRoll(electionID, root, roll.VoterCount)
+		if err != nil {
+			return err
+		}
+		receipt, err := client.WaitForTransaction(tx)
+		if err != nil {
+			return err
+		}
+		txHash = receipt.TxHash.Hex()
+	case !bytes.Equal(published[:], root[:]):
+		return fmt.Errorf("election %s already has voter roll root %s on chain, not %s",
+			electionID.String(), merkle.Hash(published).Hex(), roll.MerkleRoot)
+	}
+
+	if err := services.ElectionRollRepository().MarkPublished(roll.ElectionID, txHash); err != nil {
+		return err
+	}
+	roll.TxHash = txHash
+	return nil
+}
+
+// onRoll reports whether the vote's verification hash is a leaf of the
+// election's roll, checking its proof against the snapshot's root
+func onRoll(services interfaces.Services, roll *database.ElectionRoll, verificationHash string) (bool, error) {
+	leaf := merkle.Leaf(verificationHash)
+	stored, err := services.ElectionRollRepository().Proof(roll.ElectionID, leaf.Hex())
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	root, err := merkle.ParseHash(roll.MerkleRoot)
+	if err != nil {
+		return false, err
+	}
+	proof := make([]merkle.Hash, len(stored))
+	for i, node := range stored {
+		if proof[i], err = merkle.ParseHash(node); err != nil {
+			return false, err
+		}
+	}
+	if !merkle.Verify(root, leaf, proof) {
+		return false, fmt.Errorf("proof of leaf %s does not lead to the root of election %s", leaf.Hex(), roll.ElectionID)
+	}
+	return true, nil
+}
+
+// takeRollSnapshot snapshots an election's roll for a request, writing the
+// response and returning false if it cannot
+func takeRollSnapshot(c *gin.Context, services interfaces.Services, electionID string) (*database.ElectionRoll, bool) {
+	roll, created, err := snapshotRoll(services, electionID)
+	if errors.Is(err, errEmptyRoll) {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "empty_roll",
+			Code:    400,
+			Message: "There are no active voters to put on the roll",
+		})
+		return nil, false
+	}
+	if err != nil {
+		services.GetLogger().Error("Failed to snapshot voter roll of election %s: %v", electionID, err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "database_error",
+			Code:    500,
+			Message: "Failed to snapshot the voter roll",
+		})
+		return nil, false
+	}
+	if created {
+		createAuditLog(services, "election_roll_snapshot", c.GetString("user_id"), "",
+			fmt.Sprintf("Snapshotted %d voter(s) on the roll of election %s, root %s", roll.VoterCount, electionID, roll.MerkleRoot),
+			getClientIP(c))
+	}
+	return roll, true
+}
+
+// SnapshotVoterRoll freezes an election's voter roll ahead of its start and
+// returns the Merkle root that starting it publishes (Admin only)
+func SnapshotVoterRoll(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		electionID, _, ok := parseElectionID(c)
+		if !ok {
+			return
+		}
+
+		roll, ok := takeRollSnapshot(c, services, electionID)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data:    roll,
+		})
+	}
+}
+
+// GetVoterRoll returns an election's roll snapshot: its Merkle root, voter
+// count and publishing transaction
+func GetVoterRoll(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		electionID, _, ok := parseElectionID(c)
+		if !ok {
+			return
+		}
+
+		roll, ok := loadRoll(c, services, electionID)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data:    roll,
+		})
+	}
+}
+
+// GetVoterRollLeaves returns a page of an election's roll leaves, so
+// observers can rebuild the tree and check its root against the chain
+func GetVoterRollLeaves(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		electionID, _, ok := parseElectionID(c)
+		if !ok {
+			return
+		}
+
+		limit := 1000
+		offset := 0
+		if limitStr := c.Query("limit"); limitStr != "" {
+			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 10000 {
+				limit = l
+			}
+		}
+		if offsetStr := c.Query("offset"); offsetStr != "" {
+			if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+				offset = o
+			}
+		}
+
+		roll, ok := loadRoll(c, services, electionID)
+		if !ok {
+			return
+		}
+		leaves, err := services.ElectionRollRepository().ListLeaves(electionID, limit, offset)
+		if err != nil {
+			services.GetLogger().Error("Failed to list voter roll leaves: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to list voter roll leaves",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data: types.VoterRollLeaves{
+				ElectionID: electionID,
+				Root:       roll.MerkleRoot,
+				Total:      roll.VoterCount,
+				Limit:      limit,
+				Offset:     offset,
+				Leaves:     leaves,
+			},
+		})
+	}
+}
+
+// GetVoterRollProof returns the inclusion proof of the voter with the nin
+// query parameter in an election's roll
+func GetVoterRollProof(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		electionID, _, ok := parseElectionID(c)
+		if !ok {
+			return
+		}
+		nin := c.Query("nin")
+		if nin == "" {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "missing_parameter",
+				Code:    400,
+				Message: "nin is required",
+			})
+			return
+		}
+
+		roll, ok := loadRoll(c, services, electionID)
+		if !ok {
+			return
+		}
+		salt, err := electionSalt(services, electionID)
+		var proof []string
+		hash := ""
+		if err == nil {
+			hash = votesig.VerificationHash(salt, nin)
+			proof, err = services.ElectionRollRepository().Proof(electionID, merkle.Leaf(hash).Hex())
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error:   "not_on_roll",
+				Code:    404,
+				Message: "Voter is not on the roll of this election",
+			})
+			return
+		}
+		if err != nil {
+			services.GetLogger().Error("Failed to build voter roll proof: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to build the proof",
+			})
+			return
+		}
+
+		createAuditLog(services, "election_roll_proof", c.GetString("user_id"), "",
+			fmt.Sprintf("Inclusion proof of voter %s in election %s", nin, electionID), getClientIP(c))
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data: types.VoterRollProof{
+				ElectionID:       electionID,
+				VerificationHash: merkle.ChainHash(hash).Hex(),
+				Leaf:             merkle.Leaf(hash).Hex(),
+				Proof:            proof,
+				Root:             roll.MerkleRoot,
+			},
+		})
+	}
+}
+
+// loadRoll returns an election's roll snapshot, writing a 404 if it has none
+func loadRoll(c *gin.Context, services interfaces.Services, electionID string) (*database.ElectionRoll, bool) {
+	roll, err := services.ElectionRollRepository().Get(electionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, types.ErrorResponse{
+			Error:   "roll_not_found",
+			Code:    404,
+			Message: "The election's voter roll has not been snapshotted",
+		})
+		return nil, false
+	}
+	if err != nil {
+		services.GetLogger().Error("Failed to load voter roll: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "database_error",
+			Code:    500,
+			Message: "Failed to load the voter roll",
+		})
+		return nil, false
+	}
+	return roll, true
+}
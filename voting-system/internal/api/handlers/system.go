@@ -1,9 +1,8 @@
 package handlers
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
+	"database/sql"
+	"errors"
 	"math/big"
 	"net/http"
 	"os"
@@ -13,6 +12,7 @@ import (
 	"time"
 	"voting-system/internal/api/interfaces"
 	"voting-system/internal/api/types"
+	"voting-system/internal/auth"
 	"voting-system/internal/database"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -153,140 +153,6 @@ func TriggerSync(services interfaces.Services) gin.HandlerFunc {
 	}
 }
 
-// RegisterTerminal registers a new voting terminal
-func RegisterTerminal(services interfaces.Services) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		var req struct {
-			TerminalID    string `json:"terminal_id" binding:"required"`
-			Name          string `json:"name"`
-			Location      string `json:"location" binding:"required"`
-			PollingUnitID string `json:"polling_unit_id" binding:"required"`
-			Address       string `json:"address" binding:"required"`
-			Authorize     bool   `json:"authorize"`
-		}
-
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, types.ErrorResponse{
-				Error:   "invalid_request",
-				Code:    400,
-				Message: "Invalid request format: " + err.Error(),
-			})
-			return
-		}
-
-		// Basic address validation
-		if !common.IsHexAddress(req.Address) {
-			c.JSON(http.StatusBadRequest, types.ErrorResponse{
-				Error:   "invalid_address",
-				Code:    400,
-				Message: "Invalid terminal Ethereum address",
-			})
-			return
-		}
-
-		// Log terminal registration attempt
-		clientIP := getClientIP(c)
-		createAuditLog(services, "terminal_registration", req.TerminalID, req.PollingUnitID,
-			"Terminal registration attempt from "+req.Location, clientIP)
-
-		// Persist terminal in DB (cache)
-		term := &database.Terminal{
-			ID:            req.TerminalID,
-			Name:          req.Name,
-			Location:      req.Location,
-			PollingUnitID: req.PollingUnitID,
-			EthAddress:    req.Address,
-			PublicKey:     "",
-			Status:        "registered",
-			Authorized:    false,
-			CreatedAt:     time.Now(),
-			UpdatedAt:     time.Now(),
-		}
-		if err := services.TerminalRepository().RegisterTerminal(term); err != nil {
-			services.GetLogger().Error("Failed to store terminal: %v", err)
-			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
-				Error:   "database_error",
-				Code:    500,
-				Message: "Failed to store terminal",
-			})
-			return
-		}
-
-		// Optionally authorize on-chain immediately
-		var txHash string
-		if req.Authorize {
-			if services.GetConnManager().IsConnected() {
-				tx, err := services.GetBlockchainClient().AuthorizeTerminal(req.Address, true)
-				if err != nil {
-					services.GetLogger().Error("Blockchain authorizeTerminal failed: %v", err)
-					c.JSON(http.StatusBadRequest, types.ErrorResponse{
-						Error:   "blockchain_error",
-						Code:    400,
-						Message: "Failed to authorize terminal on-chain: " + err.Error(),
-					})
-					return
-				}
-				receipt, err := services.GetBlockchainClient().WaitForTransaction(tx)
-				if err != nil {
-					services.GetLogger().Error("AuthorizeTerminal tx failed: %v", err)
-				} else {
-					txHash = receipt.TxHash.Hex()
-				}
-				// Update DB flag
-				_ = services.TerminalRepository().AuthorizeTerminal(req.TerminalID)
-				term.Authorized = true
-				term.Status = "authorized"
-			}
-		}
-
-		services.GetLogger().Info("Terminal registered - id: %s, address: %s, authorized: %t", req.TerminalID, req.Address, term.Authorized)
-
-		c.JSON(http.StatusOK, types.SuccessResponse{
-			Success: true,
-			Message: "Terminal registration completed",
-			Data: map[string]interface{}{
-				"terminal_id":   req.TerminalID,
-				"status":        term.Status,
-				"authorized":    term.Authorized,
-				"transaction":   txHash,
-				"registered_at": time.Now().Unix(),
-			},
-		})
-	}
-}
-
-// GetTerminalStatus returns the status of a specific terminal
-func GetTerminalStatus(services interfaces.Services) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		terminalID := c.Param("id")
-		if terminalID == "" {
-			c.JSON(http.StatusBadRequest, types.ErrorResponse{
-				Error:   "missing_parameter",
-				Code:    400,
-				Message: "Terminal ID is required",
-			})
-			return
-		}
-
-		// Here you would query your database for terminal information
-		// For now, returning a mock response
-		terminalStatus := map[string]interface{}{
-			"terminal_id":     terminalID,
-			"status":          "online",
-			"last_heartbeat":  time.Now().Unix() - 30, // 30 seconds ago
-			"location":        "Polling Unit A",
-			"polling_unit_id": "PU001",
-			"votes_today":     25,
-			"uptime":          "2h 15m",
-		}
-
-		c.JSON(http.StatusOK, types.SuccessResponse{
-			Success: true,
-			Data:    terminalStatus,
-		})
-	}
-}
-
 // AuthorizeTerminal authorizes a terminal to participate in voting
 func AuthorizeTerminal(services interfaces.Services) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -324,37 +190,65 @@ func AuthorizeTerminal(services interfaces.Services) gin.HandlerFunc {
 			return
 		}
 
+		terminal := loadTerminal(c, services, terminalID)
+		if terminal == nil {
+			return
+		}
+		if !strings.EqualFold(terminal.EthAddress, common.HexToAddress(req.Address).Hex()) {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "address_mismatch",
+				Code:    400,
+				Message: "Address does not match the terminal's enrolled key",
+			})
+			return
+		}
+		if req.Authorize && terminal.Status == database.TerminalDecommissioned {
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "terminal_decommissioned",
+				Code:    409,
+				Message: "Decommissioned terminals cannot be authorized again",
+			})
+			return
+		}
+		// Only approved enrolments are authorized; revoked terminals enrol again
+		if req.Authorize && terminal.Status != database.TerminalApproved && terminal.Status != database.TerminalActive {
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "terminal_not_approved",
+				Code:    409,
+				Message: "Terminal is " + terminal.Status + "; only approved terminals can be authorized",
+			})
+			return
+		}
+
 		clientIP := getClientIP(c)
 		action := "terminal_deauthorized"
 		if req.Authorize {
 			action = "terminal_authorized"
 		}
-		createAuditLog(services, action, terminalID, "",
+		createAuditLog(services, action, terminalID, terminal.PollingUnitID,
 			"Terminal authorization change: "+req.Reason, clientIP)
 
 		var txHash string
-		if services.GetConnManager().IsConnected() {
-			tx, err := services.GetBlockchainClient().AuthorizeTerminal(req.Address, req.Authorize)
-			if err != nil {
-				services.GetLogger().Error("AuthorizeTerminal failed: %v", err)
-				c.JSON(http.StatusBadRequest, types.ErrorResponse{
-					Error:   "blockchain_error",
-					Code:    400,
-					Message: "Failed to authorize terminal on-chain: " + err.Error(),
-				})
-				return
-			}
-			receipt, err := services.GetBlockchainClient().WaitForTransaction(tx)
+		var err error
+		if req.Authorize {
+			txHash, err = activateTerminal(services, terminal)
+		} else {
+			txHash, err = setChainAuthorization(services, terminal.EthAddress, false)
 			if err == nil {
-				txHash = receipt.TxHash.Hex()
+				// Revocations hold in the database even while the chain is unreachable
+				err = services.TerminalRepository().SetAuthorization(terminalID, false)
+				terminal.Status, terminal.Authorized = database.TerminalRevoked, false
+				revokeTerminalSecrets(services, terminalID)
 			}
 		}
-
-		// Update DB cache
-		if req.Authorize {
-			_ = services.TerminalRepository().AuthorizeTerminal(terminalID)
-		} else {
-			_ = services.TerminalRepository().DeauthorizeTerminal(terminalID)
+		if err != nil {
+			services.GetLogger().Error("AuthorizeTerminal failed: %v", err)
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "blockchain_error",
+				Code:    400,
+				Message: "Failed to authorize terminal on-chain: " + err.Error(),
+			})
+			return
 		}
 
 		services.GetLogger().Info("Terminal authorization change - terminal_id: %s, authorize: %t, reason: %s",
@@ -365,7 +259,8 @@ func AuthorizeTerminal(services interfaces.Services) gin.HandlerFunc {
 			Message: "Terminal authorization updated",
 			Data: map[string]interface{}{
 				"terminal_id": terminalID,
-				"authorized":  req.Authorize,
+				"status":      terminal.Status,
+				"authorized":  terminal.Authorized,
 				"transaction": txHash,
 				"updated_at":  time.Now().Unix(),
 			},
@@ -498,42 +393,97 @@ func bToMb(b uint64) uint64 {
 	return b / 1024 / 1024
 }
 
-// IssueTerminalToken issues a short-lived JWT for a terminal using optional HMAC verification
+// IssueTerminalToken issues a short-lived JWT for an approved terminal. The
+// request is signed with the terminal's own secret over its timestamp, a
+// nonce and the device ID; each nonce is accepted once. The token carries
+// the terminal's polling unit.
 func IssueTerminalToken(services interfaces.Services) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var req struct {
-			DeviceID  string `json:"device_id" binding:"required"`
-			Timestamp string `json:"ts"`
-			Signature string `json:"signature"`
-		}
+		var req types.TerminalTokenRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "invalid_request", Code: 400, Message: "Invalid request: " + err.Error()})
 			return
 		}
 
-		shared := os.Getenv("TERMINAL_SHARED_SECRET")
-		if shared != "" {
-			// Verify HMAC over ts|device_id
-			if req.Timestamp == "" || req.Signature == "" {
-				c.JSON(http.StatusUnauthorized, types.ErrorResponse{Error: "auth_required", Code: 401, Message: "Missing timestamp or signature"})
-				return
-			}
-			// replay window 5 minutes
-			if ts, err := strconv.ParseInt(req.Timestamp, 10, 64); err == nil {
-				now := time.Now().Unix()
-				if ts > now+300 || ts < now-300 {
-					c.JSON(http.StatusUnauthorized, types.ErrorResponse{Error: "stale_request", Code: 401, Message: "Timestamp outside allowed window"})
-					return
-				}
-			}
-			mac := hmac.New(sha256.New, []byte(shared))
-			mac.Write([]byte(req.Timestamp + "|" + req.DeviceID))
-			expected := hex.EncodeToString(mac.Sum(nil))
-			if !hmac.Equal([]byte(strings.ToLower(expected)), []byte(strings.ToLower(req.Signature))) {
-				c.JSON(http.StatusUnauthorized, types.ErrorResponse{Error: "invalid_signature", Code: 401, Message: "Signature verification failed"})
-				return
+		// Only enrolled terminals an admin has approved can sign in
+		terminal, err := services.TerminalRepository().GetTerminal(req.DeviceID)
+		if errors.Is(err, sql.ErrNoRows) {
+			services.GetLogger().SecurityLogger("unknown_terminal_login", req.DeviceID, "token requested by a terminal that is not enrolled")
+			c.JSON(http.StatusForbidden, types.ErrorResponse{Error: "terminal_not_enrolled", Code: 403, Message: "Terminal is not enrolled"})
+			return
+		}
+		if err != nil {
+			services.GetLogger().Error("Failed to load terminal %s: %v", req.DeviceID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "database_error", Code: 500, Message: "Failed to load terminal"})
+			return
+		}
+		switch terminal.Status {
+		case database.TerminalApproved, database.TerminalActive:
+		case database.TerminalPending:
+			c.JSON(http.StatusForbidden, types.ErrorResponse{Error: "terminal_not_approved", Code: 403, Message: "Terminal enrolment is awaiting approval"})
+			return
+		default:
+			services.GetLogger().SecurityLogger(terminal.Status+"_terminal_login", req.DeviceID, "token requested by a "+terminal.Status+" terminal")
+			c.JSON(http.StatusForbidden, types.ErrorResponse{Error: "terminal_" + terminal.Status, Code: 403, Message: "Terminal has been " + terminal.Status})
+			return
+		}
+
+		stored, err := services.TerminalSecretRepository().Active(terminal.ID)
+		if err != nil {
+			services.GetLogger().Error("Failed to load secrets of terminal %s: %v", terminal.ID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "database_error", Code: 500, Message: "Failed to load terminal secrets"})
+			return
+		}
+		secrets, err := openTerminalSecrets(services, stored)
+		if err != nil {
+			services.GetLogger().Error("Failed to unseal secrets of terminal %s: %v", terminal.ID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "server_error", Code: 500, Message: "Failed to load terminal secrets"})
+			return
+		}
+		if len(secrets) == 0 {
+			c.JSON(http.StatusForbidden, types.ErrorResponse{Error: "terminal_secret_missing", Code: 403, Message: "Terminal has no valid secret; an admin must issue one"})
+			return
+		}
+
+		// Verify the HMAC over ts|nonce|device_id
+		if req.Timestamp == "" || req.Nonce == "" || req.Signature == "" {
+			c.JSON(http.StatusUnauthorized, types.ErrorResponse{Error: "auth_required", Code: 401, Message: "Missing timestamp, nonce or signature"})
+			return
+		}
+		if len(req.Nonce) > 64 {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "invalid_request", Code: 400, Message: "Nonce is too long"})
+			return
+		}
+		now := time.Now()
+		ts, err := strconv.ParseInt(req.Timestamp, 10, 64)
+		if err != nil || ts > now.Add(terminalTokenMaxAge).Unix() || ts < now.Add(-terminalTokenMaxAge).Unix() {
+			c.JSON(http.StatusUnauthorized, types.ErrorResponse{Error: "stale_request", Code: 401, Message: "Timestamp outside allowed window"})
+			return
+		}
+		matched := false
+		for _, secret := range secrets {
+			if auth.TerminalTokenMACMatches(secret, req.Timestamp, req.Nonce, req.DeviceID, req.Signature) {
+				matched = true
+				break
 			}
 		}
+		if !matched {
+			services.GetLogger().SecurityLogger("terminal_login_invalid_signature", req.DeviceID, "token request signature did not match any of the terminal's secrets")
+			c.JSON(http.StatusUnauthorized, types.ErrorResponse{Error: "invalid_signature", Code: 401, Message: "Signature verification failed"})
+			return
+		}
+
+		fresh, err := services.TerminalSecretRepository().UseNonce(terminal.ID, req.Nonce, time.Unix(ts, 0), now.Add(-terminalTokenMaxAge))
+		if err != nil {
+			services.GetLogger().Error("Failed to record token nonce of terminal %s: %v", terminal.ID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "database_error", Code: 500, Message: "Failed to record request nonce"})
+			return
+		}
+		if !fresh {
+			services.GetLogger().SecurityLogger("terminal_login_replayed", req.DeviceID, "token request nonce "+req.Nonce+" was already used")
+			c.JSON(http.StatusUnauthorized, types.ErrorResponse{Error: "replayed_request", Code: 401, Message: "Request nonce has already been used"})
+			return
+		}
 
 		// Mint JWT
 		secret := os.Getenv("JWT_SECRET")
@@ -542,10 +492,11 @@ func IssueTerminalToken(services interfaces.Services) gin.HandlerFunc {
 			return
 		}
 		claims := jwt.MapClaims{
-			"user_id":     req.DeviceID,
-			"role":        "terminal",
-			"permissions": []string{"voting", "terminal"},
-			"exp":         time.Now().Add(24 * time.Hour).Unix(),
+			"user_id":         req.DeviceID,
+			"role":            "terminal",
+			"permissions":     []string{"voting", "terminal"},
+			"polling_unit_id": terminal.PollingUnitID,
+			"exp":             time.Now().Add(24 * time.Hour).Unix(),
 		}
 		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 		signed, err := token.SignedString([]byte(secret))
@@ -558,7 +509,7 @@ func IssueTerminalToken(services interfaces.Services) gin.HandlerFunc {
 	}
 }
 
-// EnsurePollingUnitTerminal allows a terminal to ensure its polling unit exists on-chain (idempotent)
+// EnsurePollingUnitTerminal allows a terminal to ensure its own polling unit exists on-chain (idempotent)
 func EnsurePollingUnitTerminal(services interfaces.Services) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req struct {
@@ -571,12 +522,12 @@ func EnsurePollingUnitTerminal(services interfaces.Services) gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "invalid_request", Code: 400, Message: "Invalid request: " + err.Error()})
 			return
 		}
-		// default ID from token if not provided
-		if req.ID == "" {
-			if uid, ok := c.Get("user_id"); ok {
-				req.ID, _ = uid.(string)
-			}
+		// The polling unit is the terminal's own, from its terminal record
+		id, ok := terminalPollingUnit(c, services, req.ID, "polling unit registration")
+		if !ok {
+			return
 		}
+		req.ID = id
 		if req.ID == "" {
 			c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "missing_parameter", Code: 400, Message: "Polling unit ID is required"})
 			return
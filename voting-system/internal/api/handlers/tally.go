@@ -0,0 +1,548 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"voting-system/internal/api/interfaces"
+	"voting-system/internal/api/types"
+	"voting-system/internal/database"
+	"voting-system/internal/tally"
+
+	"github.com/gin-gonic/gin"
+)
+
+// electionKey is a parsed ballot key together with the candidate order it was registered for
+type electionKey struct {
+	record           *database.ElectionKey
+	publicKey        tally.PublicKey
+	candidates       []string
+	verificationKeys []string
+}
+
+// context returns the proof context ballots of this election are bound to
+func (k *electionKey) context() []byte {
+	return tally.BallotContext(k.record.ElectionID, k.candidates)
+}
+
+// loadElectionKey returns the ballot key of an election, or sql.ErrNoRows if
+// the election does not use encrypted ballots
+func loadElectionKey(services interfaces.Services, electionID string) (*electionKey, error) {
+	record, err := services.ElectionKeyRepository().Get(electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &electionKey{record: record}
+	if key.publicKey, err = tally.ParsePublicKey(record.PublicKey); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(record.Candidates), &key.candidates); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(record.VerificationKeys), &key.verificationKeys); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// electionCandidates returns an election's candidate IDs from the DB cache,
+// falling back to the blockchain
+func electionCandidates(services interfaces.Services, electionID string) ([]string, error) {
+	if e, err := services.ElectionRepository().GetElectionByBlockchainID(electionID); err == nil && e != nil {
+		if list, err := services.CandidateRepository().ListByElection(e.ID); err == nil && len(list) > 0 {
+			candidates := make([]string, 0, len(list))
+			for _, x := range list {
+				candidates = append(candidates, x.CandidateID)
+			}
+			return candidates, nil
+		}
+	}
+
+	client := services.GetBlockchainClient()
+	if client == nil {
+		return nil, errors.New("no candidates cached and blockchain unavailable")
+	}
+	bid, ok := new(big.Int).SetString(electionID, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid election ID %q", electionID)
+	}
+	details, err := client.GetElectionDetails(bid)
+	if err != nil {
+		return nil, err
+	}
+	return details.Candidates, nil
+}
+
+// parseElectionID reads the :id parameter as a blockchain election ID
+func parseElectionID(c *gin.Context) (string, int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "invalid_election_id",
+			Code:    400,
+			Message: "Invalid election ID format",
+		})
+		return "", 0, false
+	}
+	return strconv.FormatInt(id, 10), id, true
+}
+
+// SetBallotKey registers the ballot encryption key of an election, switching
+// it to encrypted ballots. The key can be replaced until the first ballot is cast.
+func SetBallotKey(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		electionID, id, ok := parseElectionID(c)
+		if !ok {
+			return
+		}
+
+		var req types.BallotKeyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_request",
+				Code:    400,
+				Message: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		pk, err := tally.ParsePublicKey(req.PublicKey)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "invalid_public_key", Code: 400, Message: err.Error()})
+			return
+		}
+		verificationKeys := make([]tally.Point, len(req.VerificationKeys))
+		for i, s := range req.VerificationKeys {
+			if verificationKeys[i], err = tally.DecodePoint(s); err != nil {
+				c.JSON(http.StatusBadRequest, types.ErrorResponse{
+					Error:   "invalid_verification_key",
+					Code:    400,
+					Message: fmt.Sprintf("Verification key %d: %v", i+1, err),
+				})
+				return
+			}
+		}
+		if err := tally.CheckVerificationKeys(pk, verificationKeys, req.Threshold); err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "invalid_verification_key", Code: 400, Message: err.Error()})
+			return
+		}
+
+		// Keys dealt by a ceremony are replaced by running the ceremony again
+		if _, err := services.CeremonyRepository().Get(electionID); err == nil {
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "key_ceremony_exists",
+				Code:    409,
+				Message: "The election's key comes from a key ceremony; run the ceremony again to replace it",
+			})
+			return
+		}
+		if !checkKeyReplaceable(c, services, electionID, id) {
+			return
+		}
+
+		key, ok := saveBallotKey(c, services, electionID, pk, req.Threshold, req.VerificationKeys)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, types.SuccessResponse{Success: true, Data: ballotKeyInfo(key), Message: "Ballot key registered"})
+	}
+}
+
+// checkKeyReplaceable reports whether an election's ballot key may still be
+// set: the election has not ended and no ballots have been cast.
+// Otherwise it writes an error response.
+func checkKeyReplaceable(c *gin.Context, services interfaces.Services, electionID string, id int64) bool {
+	if existing, err := services.ElectionKeyRepository().Get(electionID); err == nil && existing.Status != database.ElectionKeyOpen {
+		c.JSON(http.StatusConflict, types.ErrorResponse{
+			Error:   "ballot_key_closed",
+			Code:    409,
+			Message: "The election has ended; its ballot key can no longer be changed",
+		})
+		return false
+	}
+	count, err := services.VoteRepository().CountBallots(id)
+	if err != nil {
+		services.GetLogger().Error("Failed to count ballots: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "database_error", Code: 500, Message: "Failed to check ballots"})
+		return false
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, types.ErrorResponse{
+			Error:   "ballots_cast",
+			Code:    409,
+			Message: "Ballots have already been cast with the current key",
+		})
+		return false
+	}
+	return true
+}
+
+// saveBallotKey stores an election's ballot key for its current candidates.
+// On failure it writes an error response and returns false.
+func saveBallotKey(c *gin.Context, services interfaces.Services, electionID string, pk tally.PublicKey, threshold int, verificationKeys []string) (*electionKey, bool) {
+	candidates, err := electionCandidates(services, electionID)
+	if err != nil || len(candidates) == 0 {
+		services.GetLogger().Error("Failed to load candidates for election %s: %v", electionID, err)
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "no_candidates",
+			Code:    400,
+			Message: "Register the election's candidates before its ballot key",
+		})
+		return nil, false
+	}
+
+	candidatesJSON, _ := json.Marshal(candidates)
+	verificationKeysJSON, _ := json.Marshal(verificationKeys)
+	record := &database.ElectionKey{
+		ElectionID:       electionID,
+		PublicKey:        pk.String(),
+		Threshold:        threshold,
+		Candidates:       string(candidatesJSON),
+		VerificationKeys: string(verificationKeysJSON),
+		CreatedBy:        c.GetString("user_id"),
+	}
+	if err := services.ElectionKeyRepository().Save(record); err != nil {
+		services.GetLogger().Error("Failed to save ballot key: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "database_error", Code: 500, Message: "Failed to save ballot key"})
+		return nil, false
+	}
+
+	createAuditLog(services, "ballot_key_registered", c.GetString("user_id"), "",
+		fmt.Sprintf("Ballot key for election %s: %d-of-%d trustees, %d candidates",
+			electionID, threshold, len(verificationKeys), len(candidates)), getClientIP(c))
+
+	key, err := loadElectionKey(services, electionID)
+	if err != nil {
+		services.GetLogger().Error("Failed to load ballot key: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "database_error", Code: 500, Message: "Failed to load ballot key"})
+		return nil, false
+	}
+	return key, true
+}
+
+// GetBallotKey returns the key and candidate order terminals encrypt ballots with (public)
+func GetBallotKey(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		electionID, _, ok := parseElectionID(c)
+		if !ok {
+			return
+		}
+
+		key, err := loadElectionKey(services, electionID)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error:   "ballot_key_not_found",
+				Code:    404,
+				Message: "Election does not use encrypted ballots",
+			})
+			return
+		}
+		if err != nil {
+			services.GetLogger().Error("Failed to load ballot key: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "database_error", Code: 500, Message: "Failed to load ballot key"})
+			return
+		}
+
+		c.JSON(http.StatusOK, types.SuccessResponse{Success: true, Data: ballotKeyInfo(key)})
+	}
+}
+
+func ballotKeyInfo(key *electionKey) types.BallotKey {
+	return types.BallotKey{
+		ElectionID:       key.record.ElectionID,
+		PublicKey:        key.record.PublicKey,
+		Threshold:        key.record.Threshold,
+		VerificationKeys: key.verificationKeys,
+		Candidates:       key.candidates,
+		Context:          string(key.context()),
+		Status:           key.record.Status,
+	}
+}
+
+// encryptedTally returns the tally trustees decrypt, summing the election's
+// synced ballots the first time it is requested after the election has ended
+func encryptedTally(services interfaces.Services, key *electionKey, id int64) (*tally.EncryptedTally, error) {
+	stored, err := services.ElectionKeyRepository().GetTally(key.record.ElectionID)
+	if err == sql.ErrNoRows {
+		ballots, err := services.VoteRepository().ListBallots(id)
+		if err != nil {
+			return nil, err
+		}
+
+		result := tally.NewEncryptedTally(key.context(), len(key.candidates))
+		rejected := 0
+		for _, s := range ballots {
+			ballot, err := tally.ParseBallot(s)
+			if err == nil {
+				err = result.Add(key.publicKey, ballot)
+			}
+			if err != nil {
+				// Ballots are verified when cast, so this indicates tampering with the votes table
+				services.GetLogger().Error("Skipping invalid ballot in election %s: %v", key.record.ElectionID, err)
+				rejected++
+			}
+		}
+
+		stored, err = services.ElectionKeyRepository().SaveTally(&database.ElectionTally{
+			ElectionID:     key.record.ElectionID,
+			Ballots:        result.Ballots,
+			EncryptedTally: result.String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		createAuditLog(services, "tally_computed", "system", "",
+			fmt.Sprintf("Encrypted tally for election %s: %d ballots, %d rejected",
+				key.record.ElectionID, stored.Ballots, rejected), "")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return tally.ParseEncryptedTally(stored.EncryptedTally, key.context())
+}
+
+// loadClosedElectionKey loads the ballot key of an ended election, writing an
+// error response if there is none
+func loadClosedElectionKey(c *gin.Context, services interfaces.Services, electionID string) (*electionKey, bool) {
+	key, err := loadElectionKey(services, electionID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, types.ErrorResponse{
+			Error:   "ballot_key_not_found",
+			Code:    404,
+			Message: "Election does not use encrypted ballots",
+		})
+		return nil, false
+	}
+	if err != nil {
+		services.GetLogger().Error("Failed to load ballot key: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "database_error", Code: 500, Message: "Failed to load ballot key"})
+		return nil, false
+	}
+	if key.record.Status == database.ElectionKeyOpen {
+		c.JSON(http.StatusConflict, types.ErrorResponse{
+			Error:   "election_not_ended",
+			Code:    409,
+			Message: "The tally is available once the election has ended",
+		})
+		return nil, false
+	}
+	return key, true
+}
+
+// GetEncryptedTally returns the homomorphic sum of an ended election's ballots
+// for trustees to compute their decryption shares (Admin only)
+func GetEncryptedTally(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		electionID, id, ok := parseElectionID(c)
+		if !ok {
+			return
+		}
+		key, ok := loadClosedElectionKey(c, services, electionID)
+		if !ok {
+			return
+		}
+
+		result, err := encryptedTally(services, key, id)
+		if err != nil {
+			services.GetLogger().Error("Failed to compute tally for election %s: %v", electionID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "tally_error", Code: 500, Message: "Failed to compute tally"})
+			return
+		}
+
+		c.JSON(http.StatusOK, types.SuccessResponse{Success: true, Data: types.EncryptedTally{
+			ElectionID: electionID,
+			Candidates: key.candidates,
+			Context:    string(key.context()),
+			Tally:      result,
+			Decrypted:  key.record.Status == database.ElectionKeyDecrypted,
+		}})
+	}
+}
+
+// DecryptTally combines the trustees' decryption shares and publishes the
+// election results (Admin only)
+func DecryptTally(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		electionID, id, ok := parseElectionID(c)
+		if !ok {
+			return
+		}
+
+		var req types.DecryptTallyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_request",
+				Code:    400,
+				Message: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		key, ok := loadClosedElectionKey(c, services, electionID)
+		if !ok {
+			return
+		}
+		if key.record.Status == database.ElectionKeyDecrypted {
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "already_decrypted",
+				Code:    409,
+				Message: "The tally has already been decrypted",
+			})
+			return
+		}
+
+		result, err := encryptedTally(services, key, id)
+		if err != nil {
+			services.GetLogger().Error("Failed to compute tally for election %s: %v", electionID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "tally_error", Code: 500, Message: "Failed to compute tally"})
+			return
+		}
+
+		verificationKeys, err := key.trusteeKeys()
+		if err != nil {
+			services.GetLogger().Error("Stored verification keys are invalid: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "tally_error", Code: 500, Message: "Invalid stored verification key"})
+			return
+		}
+
+		trustees := make([]string, 0, len(req.Shares))
+		for _, share := range req.Shares {
+			if share == nil {
+				c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "invalid_request", Code: 400, Message: "Empty decryption share"})
+				return
+			}
+			trustees = append(trustees, strconv.Itoa(share.Index))
+		}
+
+		counts, err := tally.Combine(result, req.Shares, verificationKeys, key.record.Threshold)
+		if err != nil {
+			services.GetLogger().Warning("Rejected decryption shares for election %s: %v", electionID, err)
+			createAuditLog(services, "tally_decryption_rejected", c.GetString("user_id"), "",
+				fmt.Sprintf("Election %s, trustees %s: %v", electionID, strings.Join(trustees, ","), err), getClientIP(c))
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "invalid_decryption_shares", Code: 400, Message: err.Error()})
+			return
+		}
+
+		results, err := saveTallyResults(services, key, result, counts, strings.Join(trustees, ","), c.GetString("user_id"), getClientIP(c))
+		if err != nil {
+			services.GetLogger().Error("Failed to save results: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "database_error", Code: 500, Message: "Failed to save results"})
+			return
+		}
+
+		c.JSON(http.StatusOK, types.SuccessResponse{Success: true, Data: results, Message: "Tally decrypted"})
+	}
+}
+
+// trusteeKeys returns the trustees' verification keys by share index
+func (k *electionKey) trusteeKeys() (map[int]tally.Point, error) {
+	keys := make(map[int]tally.Point, len(k.verificationKeys))
+	for i, s := range k.verificationKeys {
+		point, err := tally.DecodePoint(s)
+		if err != nil {
+			return nil, fmt.Errorf("verification key %d: %v", i+1, err)
+		}
+		keys[i+1] = point
+	}
+	return keys, nil
+}
+
+// saveTallyResults publishes the decrypted counts of an election and marks its key decrypted
+func saveTallyResults(services interfaces.Services, key *electionKey, result *tally.EncryptedTally, counts []int64, trustees, userID, clientIP string) (*types.TallyResults, error) {
+	results := make(map[string]int64, len(counts))
+	for i, count := range counts {
+		results[key.candidates[i]] = count
+	}
+	resultsJSON, _ := json.Marshal(results)
+	if err := services.ElectionKeyRepository().SaveResults(key.record.ElectionID, string(resultsJSON)); err != nil {
+		return nil, err
+	}
+
+	createAuditLog(services, "tally_decrypted", userID, "",
+		fmt.Sprintf("Election %s decrypted by trustees %s: %d ballots, results %s",
+			key.record.ElectionID, trustees, result.Ballots, resultsJSON), clientIP)
+
+	return &types.TallyResults{ElectionID: key.record.ElectionID, Ballots: result.Ballots, Results: results}, nil
+}
+
+// writeTallyResults answers a results request for an election with encrypted
+// ballots. Results stay hidden until the trustees have decrypted the tally.
+func writeTallyResults(c *gin.Context, services interfaces.Services, electionID string) {
+	stored, err := services.ElectionKeyRepository().GetTally(electionID)
+	if err == nil && stored.Results != nil {
+		response := types.TallyResults{ElectionID: electionID, Ballots: stored.Ballots}
+		if err := json.Unmarshal([]byte(*stored.Results), &response.Results); err != nil {
+			services.GetLogger().Error("Stored results for election %s are invalid: %v", electionID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "results_error", Code: 500, Message: "Failed to get election results"})
+			return
+		}
+		if stored.DecryptedAt != nil {
+			decryptedAt := stored.DecryptedAt.Unix()
+			response.DecryptedAt = &decryptedAt
+		}
+		c.JSON(http.StatusOK, types.SuccessResponse{Success: true, Data: response, Message: "Election results retrieved successfully"})
+		return
+	}
+	if err != nil && err != sql.ErrNoRows {
+		services.GetLogger().Error("Error getting tally: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "results_error", Code: 500, Message: "Failed to get election results"})
+		return
+	}
+
+	c.JSON(http.StatusConflict, types.ErrorResponse{
+		Error:   "results_pending",
+		Code:    409,
+		Message: "Ballots are encrypted; results are published once the trustees decrypt the tally",
+	})
+}
+
+// checkBallot validates the vote choice against the election's ballot mode
+// and returns the encoded ballot, or "" for elections without a ballot key.
+// On failure it writes an error response and returns false.
+func checkBallot(c *gin.Context, services interfaces.Services, req *types.VoteRequest, electionID, verificationHash, clientIP string) (string, bool) {
+	key, err := loadElectionKey(services, electionID)
+	if err == sql.ErrNoRows {
+		if req.Ballot != nil || req.CandidateID == "" {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_request",
+				Code:    400,
+				Message: "This election takes a candidate_id, not an encrypted ballot",
+			})
+			return "", false
+		}
+		return "", true
+	}
+	if err != nil {
+		services.GetLogger().Error("Failed to load ballot key: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "database_error", Code: 500, Message: "Failed to load ballot key"})
+		return "", false
+	}
+
+	if req.Ballot == nil || req.CandidateID != "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "ballot_required",
+			Code:    400,
+			Message: "This election takes an encrypted ballot, not a candidate_id",
+		})
+		return "", false
+	}
+	if key.record.Status != database.ElectionKeyOpen {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "election_ended", Code: 400, Message: "The election has ended"})
+		return "", false
+	}
+	if err := req.Ballot.Verify(key.publicKey, key.context(), len(key.candidates)); err != nil {
+		services.GetLogger().Warning("Invalid ballot - hash: %s: %v", verificationHash, err)
+		createAuditLog(services, "vote_rejected_invalid_ballot", verificationHash, req.PollingUnitID,
+			fmt.Sprintf("Invalid ballot: %v", err), clientIP)
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "invalid_ballot", Code: 400, Message: "Invalid ballot"})
+		return "", false
+	}
+
+	return req.Ballot.String(), true
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"voting-system/internal/api/interfaces"
+	"voting-system/internal/api/types"
+	"voting-system/internal/auth"
+	"voting-system/internal/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// terminalTokenMaxAge is how far a token request's timestamp may be from the
+// server's clock. Nonces are remembered for as long.
+const terminalTokenMaxAge = 5 * time.Minute
+
+// issueTerminalSecret generates a new secret for a terminal and stores it
+// sealed. The terminal's current secrets stay valid for grace.
+func issueTerminalSecret(services interfaces.Services, terminalID, createdBy string, grace time.Duration) (*types.TerminalSecretResponse, error) {
+	secret, err := auth.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := auth.Seal(sealingKey(services), secret)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, previous, err := services.TerminalSecretRepository().Rotate(terminalID, sealed, createdBy, grace)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &types.TerminalSecretResponse{
+		TerminalID: terminalID,
+		Secret:     secret,
+		CreatedAt:  stored.CreatedAt,
+		Previous:   previous,
+	}
+	if previous > 0 && grace > 0 {
+		until := stored.CreatedAt.Add(grace)
+		resp.GraceUntil = &until
+	}
+	return resp, nil
+}
+
+// revokeTerminalSecrets revokes the secrets of a terminal that is being
+// revoked or decommissioned. Failures are logged; the terminal's status
+// already keeps it from signing in.
+func revokeTerminalSecrets(services interfaces.Services, terminalID string) {
+	if _, err := services.TerminalSecretRepository().Revoke(terminalID); err != nil {
+		services.GetLogger().Error("Failed to revoke secrets of terminal %s: %v", terminalID, err)
+	}
+}
+
+// openTerminalSecrets unseals stored terminal secrets
+func openTerminalSecrets(services interfaces.Services, stored []database.TerminalSecret) ([]string, error) {
+	secrets := make([]string, 0, len(stored))
+	for _, s := range stored {
+		secret, err := auth.Open(sealingKey(services), s.SecretSealed)
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, secret)
+	}
+	return secrets, nil
+}
+
+// RotateTerminalSecret issues a new secret for a terminal. Its previous
+// secrets keep working for fleet.secret_grace so the terminal can be
+// reconfigured without downtime. The secret is returned once. (admin)
+func RotateTerminalSecret(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reason, ok := bindReason(c)
+		if !ok {
+			return
+		}
+		terminal := loadTerminal(c, services, c.Param("id"))
+		if terminal == nil {
+			return
+		}
+		switch terminal.Status {
+		case database.TerminalApproved, database.TerminalActive:
+		default:
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "invalid_terminal_state",
+				Code:    409,
+				Message: "Only approved or active terminals have secrets; the terminal is " + terminal.Status,
+			})
+			return
+		}
+
+		resp, err := issueTerminalSecret(services, terminal.ID, c.GetString("user_id"), services.GetConfig().Fleet.SecretGrace)
+		if err != nil {
+			services.GetLogger().Error("Failed to rotate secret of terminal %s: %v", terminal.ID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to issue terminal secret",
+			})
+			return
+		}
+
+		details := "Terminal secret rotated by user " + c.GetString("user_id")
+		if reason != "" {
+			details += ": " + reason
+		}
+		createAuditLog(services, "terminal_secret_rotated", terminal.ID, terminal.PollingUnitID, details, getClientIP(c))
+		services.GetLogger().Info("Terminal secret rotated - terminal_id: %s, previous: %d", terminal.ID, resp.Previous)
+
+		c.JSON(http.StatusCreated, types.SuccessResponse{
+			Success: true,
+			Message: "Terminal secret issued; it will not be shown again",
+			Data:    resp,
+		})
+	}
+}
+
+// RevokeTerminalSecrets revokes all of a terminal's secrets at once. The
+// terminal cannot sign in again until a new secret is issued. (admin)
+func RevokeTerminalSecrets(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reason, ok := bindReason(c)
+		if !ok {
+			return
+		}
+		terminal := loadTerminal(c, services, c.Param("id"))
+		if terminal == nil {
+			return
+		}
+
+		revoked, err := services.TerminalSecretRepository().Revoke(terminal.ID)
+		if err != nil {
+			services.GetLogger().Error("Failed to revoke secrets of terminal %s: %v", terminal.ID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to revoke terminal secrets",
+			})
+			return
+		}
+
+		details := "Terminal secrets revoked by user " + c.GetString("user_id")
+		if reason != "" {
+			details += ": " + reason
+		}
+		createAuditLog(services, "terminal_secret_revoked", terminal.ID, terminal.PollingUnitID, details, getClientIP(c))
+		services.GetLogger().SecurityLogger("terminal_secret_revoked", terminal.ID, details)
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Message: "Terminal secrets revoked",
+			Data: map[string]interface{}{
+				"terminal_id": terminal.ID,
+				"revoked":     revoked,
+			},
+		})
+	}
+}
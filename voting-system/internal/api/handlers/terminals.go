@@ -0,0 +1,516 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"voting-system/internal/api/interfaces"
+	"voting-system/internal/api/models"
+	"voting-system/internal/api/types"
+	"voting-system/internal/database"
+	"voting-system/internal/fleet"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fleetMonitor returns the monitor that raises and resolves terminal alerts
+func fleetMonitor(services interfaces.Services) *fleet.Monitor {
+	return fleet.NewMonitor(services.TerminalRepository(), services.TerminalAlertRepository(),
+		services.AuditLogRepository(), services.GetConfig().Fleet, services.GetLogger())
+}
+
+// ownTerminal refuses a terminal acting as another terminal.
+// On refusal it writes an error response and returns false.
+func ownTerminal(c *gin.Context, services interfaces.Services, deviceID string) bool {
+	caller := c.GetString("user_id")
+	if caller == deviceID {
+		return true
+	}
+	services.GetLogger().SecurityLogger("terminal_impersonation", caller, "terminal attempted to act as "+deviceID)
+	c.JSON(http.StatusForbidden, types.ErrorResponse{
+		Error:   "forbidden",
+		Code:    403,
+		Message: "Terminals may only act for themselves",
+	})
+	return false
+}
+
+// loadTerminal fetches the terminal named in the path.
+// On failure it writes an error response and returns nil.
+func loadTerminal(c *gin.Context, services interfaces.Services, deviceID string) *database.Terminal {
+	terminal, err := services.TerminalRepository().GetTerminal(deviceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, types.ErrorResponse{
+			Error:   "terminal_not_found",
+			Code:    404,
+			Message: "Terminal is not registered",
+		})
+		return nil
+	}
+	if err != nil {
+		services.GetLogger().Error("Failed to load terminal %s: %v", deviceID, err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "database_error",
+			Code:    500,
+			Message: "Failed to load terminal",
+		})
+		return nil
+	}
+	return terminal
+}
+
+// terminalPollingUnit checks that a terminal acts for the polling unit it is
+// enrolled at, which is taken from its terminal record rather than from the
+// request. An empty requested polling unit means the terminal's own. Callers
+// that are not terminals get requested back unchanged. Mismatches are logged
+// as security events; on refusal it writes an error response and returns
+// false.
+func terminalPollingUnit(c *gin.Context, services interfaces.Services, requested, action string) (string, bool) {
+	if c.GetString("user_role") != models.RoleTerminal {
+		return requested, true
+	}
+
+	deviceID := c.GetString("user_id")
+	terminal, err := services.TerminalRepository().GetTerminal(deviceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		services.GetLogger().SecurityLogger("unknown_terminal", deviceID, action+" attempted with the token of a terminal that is not enrolled")
+		c.JSON(http.StatusForbidden, types.ErrorResponse{
+			Error:   "terminal_not_enrolled",
+			Code:    403,
+			Message: "Terminal is not enrolled",
+		})
+		return "", false
+	}
+	if err != nil {
+		services.GetLogger().Error("Failed to load terminal %s: %v", deviceID, err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "database_error",
+			Code:    500,
+			Message: "Failed to load terminal",
+		})
+		return "", false
+	}
+
+	// A token issued before the terminal moved is no longer good for either unit
+	if claimed := c.GetString("polling_unit_id"); claimed != "" && claimed != terminal.PollingUnitID {
+		services.GetLogger().SecurityLogger("terminal_token_polling_unit_stale", deviceID,
+			fmt.Sprintf("%s attempted with a token for polling unit %s; the terminal is enrolled at %s", action, claimed, terminal.PollingUnitID))
+		c.JSON(http.StatusUnauthorized, types.ErrorResponse{
+			Error:   "stale_token",
+			Code:    401,
+			Message: "Token was issued for another polling unit; request a new one",
+		})
+		return "", false
+	}
+
+	if requested != "" && requested != terminal.PollingUnitID {
+		details := fmt.Sprintf("%s for polling unit %s by a terminal enrolled at %s", action, requested, terminal.PollingUnitID)
+		services.GetLogger().SecurityLogger("polling_unit_mismatch", deviceID, details)
+		createAuditLog(services, "terminal_polling_unit_mismatch", deviceID, terminal.PollingUnitID, details, getClientIP(c))
+		c.JSON(http.StatusForbidden, types.ErrorResponse{
+			Error:   "polling_unit_mismatch",
+			Code:    403,
+			Message: "Terminal is enrolled at polling unit " + terminal.PollingUnitID,
+		})
+		return "", false
+	}
+	return terminal.PollingUnitID, true
+}
+
+// refuseDecommissioned turns away a retired terminal.
+// On refusal it writes an error response and returns true.
+func refuseDecommissioned(c *gin.Context, terminal *database.Terminal) bool {
+	if terminal.Status != database.TerminalDecommissioned {
+		return false
+	}
+	c.JSON(http.StatusForbidden, types.ErrorResponse{
+		Error:   "terminal_decommissioned",
+		Code:    403,
+		Message: "Terminal has been decommissioned",
+	})
+	return true
+}
+
+// TerminalHeartbeat records a terminal's health report. The server works out
+// the terminal's clock skew from the reported time, raises alerts for
+// readings outside the fleet limits and resolves those that recovered.
+func TerminalHeartbeat(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deviceID := c.Param("id")
+		if !ownTerminal(c, services, deviceID) {
+			return
+		}
+		if _, ok := terminalPollingUnit(c, services, "", "heartbeat"); !ok {
+			return
+		}
+
+		var req types.TerminalHeartbeat
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_request",
+				Code:    400,
+				Message: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		terminal := loadTerminal(c, services, deviceID)
+		if terminal == nil || refuseDecommissioned(c, terminal) {
+			return
+		}
+
+		now := time.Now()
+		health := database.TerminalHealth{
+			Online:          true,
+			BatteryLevel:    req.BatteryLevel,
+			Charging:        req.Charging,
+			FirmwareVersion: req.FirmwareVersion,
+			QueueDepth:      req.QueueDepth,
+			ClockSkewMs:     req.Timestamp - now.UnixMilli(),
+		}
+		if err := services.TerminalRepository().RecordHeartbeat(deviceID, health); err != nil {
+			services.GetLogger().Error("Failed to record heartbeat of terminal %s: %v", deviceID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to record heartbeat",
+			})
+			return
+		}
+
+		// Alerts are secondary to recording the heartbeat; a failure is only logged
+		alerts := []string{}
+		conditions, err := fleetMonitor(services).Heartbeat(terminal, health)
+		if err != nil {
+			services.GetLogger().Error("Failed to update alerts of terminal %s: %v", deviceID, err)
+		}
+		for _, condition := range conditions {
+			alerts = append(alerts, condition.Kind)
+		}
+
+		var version string
+		if remote, err := terminalConfig(services, terminal); err != nil {
+			services.GetLogger().Error("Failed to build config of terminal %s: %v", deviceID, err)
+		} else {
+			version = remote.Version
+		}
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data: types.HeartbeatResponse{
+				ServerTime:    now.UnixMilli(),
+				ClockSkewMs:   health.ClockSkewMs,
+				ConfigVersion: version,
+				Alerts:        alerts,
+			},
+		})
+	}
+}
+
+// GetTerminalConfig returns the calling terminal's remote config
+func GetTerminalConfig(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deviceID := c.Param("id")
+		if !ownTerminal(c, services, deviceID) {
+			return
+		}
+		if _, ok := terminalPollingUnit(c, services, "", "config download"); !ok {
+			return
+		}
+		terminal := loadTerminal(c, services, deviceID)
+		if terminal == nil || refuseDecommissioned(c, terminal) {
+			return
+		}
+
+		remote, err := terminalConfig(services, terminal)
+		if err != nil {
+			services.GetLogger().Error("Failed to build config of terminal %s: %v", deviceID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "config_error",
+				Code:    500,
+				Message: "Failed to build terminal config",
+			})
+			return
+		}
+
+		// The terminal hashes fingerprints and derives verification hashes
+		// itself while it is offline
+		if err := addTerminalSecrets(services, remote); err != nil {
+			services.GetLogger().Error("Failed to add secrets to config of terminal %s: %v", deviceID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "config_error",
+				Code:    500,
+				Message: "Failed to build terminal config",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, types.SuccessResponse{Success: true, Data: remote})
+	}
+}
+
+// UpdateTerminalConfig replaces a terminal's config overrides (admin)
+func UpdateTerminalConfig(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.TerminalConfigOverrides
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_request",
+				Code:    400,
+				Message: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		terminal := loadTerminal(c, services, c.Param("id"))
+		if terminal == nil {
+			return
+		}
+
+		settings, _ := json.Marshal(req)
+		if err := services.TerminalConfigRepository().Save(terminal.ID, string(settings), c.GetString("user_id")); err != nil {
+			services.GetLogger().Error("Failed to save config of terminal %s: %v", terminal.ID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to save terminal config",
+			})
+			return
+		}
+		createAuditLog(services, "terminal_config_updated", c.GetString("user_id"), terminal.PollingUnitID,
+			"Config overrides of terminal "+terminal.ID+" set to "+string(settings), getClientIP(c))
+
+		remote, err := terminalConfig(services, terminal)
+		if err != nil {
+			services.GetLogger().Error("Failed to build config of terminal %s: %v", terminal.ID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "config_error",
+				Code:    500,
+				Message: "Failed to build terminal config",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data:    remote,
+			Message: "Terminal config updated",
+		})
+	}
+}
+
+// addTerminalSecrets adds the fingerprint key and the active election's
+// verification salt to a terminal's own config. They are left out of the
+// config admins see. The terminal needs both to match fingerprints and hash
+// votes while offline; "Terminal config secrets" in the README sets out what
+// a compromised terminal exposes.
+func addTerminalSecrets(services interfaces.Services, remote *types.TerminalRemoteConfig) error {
+	vault, err := templateVault(services)
+	if err != nil {
+		return err
+	}
+	remote.FingerprintKey = hex.EncodeToString(vault.FingerprintKey())
+
+	if remote.ElectionID != "" {
+		salt, err := electionSalt(services, remote.ElectionID)
+		if err != nil {
+			return err
+		}
+		remote.VerificationSalt = salt
+	}
+	return nil
+}
+
+// terminalConfig assembles a terminal's remote config from the active
+// election, the server's biometric and fleet settings and the terminal's overrides
+func terminalConfig(services interfaces.Services, terminal *database.Terminal) (*types.TerminalRemoteConfig, error) {
+	cfg := services.GetConfig()
+	remote := &types.TerminalRemoteConfig{
+		DeviceID:          terminal.ID,
+		PollingUnitID:     terminal.PollingUnitID,
+		Candidates:        []string{},
+		QualityThreshold:  cfg.Biometric.QualityThreshold,
+		MatchThreshold:    cfg.Biometric.MatchThreshold,
+		MaxAttempts:       cfg.Biometric.MaxAttempts,
+		LockoutDuration:   int(cfg.Biometric.LockoutDuration / time.Second),
+		Matcher:           cfg.Biometric.Matcher,
+		HeartbeatInterval: int(cfg.Fleet.HeartbeatInterval / time.Second),
+	}
+
+	election, err := services.ElectionRepository().GetActiveElection()
+	switch {
+	case err == nil:
+		remote.ElectionID = election.BlockchainID
+		candidates, err := electionCandidates(services, election.BlockchainID)
+		if err != nil {
+			services.GetLogger().Warning("Terminal %s config has no candidates: %v", terminal.ID, err)
+		} else {
+			remote.Candidates = candidates
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	}
+
+	settings, err := services.TerminalConfigRepository().Get(terminal.ID)
+	switch {
+	case err == nil:
+		var overrides types.TerminalConfigOverrides
+		if err := json.Unmarshal([]byte(settings.Settings), &overrides); err != nil {
+			return nil, err
+		}
+		if overrides.QualityThreshold != nil {
+			remote.QualityThreshold = *overrides.QualityThreshold
+		}
+		if overrides.MatchThreshold != nil {
+			remote.MatchThreshold = *overrides.MatchThreshold
+		}
+		if overrides.MaxAttempts != nil {
+			remote.MaxAttempts = *overrides.MaxAttempts
+		}
+		if overrides.HeartbeatInterval != nil {
+			remote.HeartbeatInterval = *overrides.HeartbeatInterval
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	}
+
+	// The version lets a terminal notice from its heartbeat that the config changed
+	encoded, _ := json.Marshal(remote)
+	sum := sha256.Sum256(encoded)
+	remote.Version = hex.EncodeToString(sum[:8])
+	return remote, nil
+}
+
+// GetTerminalStatus returns the calling terminal's status
+func GetTerminalStatus(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deviceID := c.Param("id")
+		if !ownTerminal(c, services, deviceID) {
+			return
+		}
+		if _, ok := terminalPollingUnit(c, services, "", "status check"); !ok {
+			return
+		}
+		terminalStatus(c, services, deviceID)
+	}
+}
+
+// GetTerminalStatusAdmin returns any terminal's status (admin)
+func GetTerminalStatusAdmin(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		terminalStatus(c, services, c.Param("id"))
+	}
+}
+
+func terminalStatus(c *gin.Context, services interfaces.Services, deviceID string) {
+	terminal := loadTerminal(c, services, deviceID)
+	if terminal == nil {
+		return
+	}
+
+	startOfDay := time.Now().UTC().Truncate(24 * time.Hour)
+	votesToday, err := services.VoteRepository().CountTerminalVotes(deviceID, startOfDay)
+	if err != nil {
+		services.GetLogger().Error("Failed to count votes of terminal %s: %v", deviceID, err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "database_error",
+			Code:    500,
+			Message: "Failed to load terminal status",
+		})
+		return
+	}
+	alerts, err := services.TerminalAlertRepository().List(deviceID, true, 100, 0)
+	if err != nil {
+		services.GetLogger().Error("Failed to load alerts of terminal %s: %v", deviceID, err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "database_error",
+			Code:    500,
+			Message: "Failed to load terminal status",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, types.SuccessResponse{
+		Success: true,
+		Data: types.TerminalStatus{
+			TerminalID:      terminal.ID,
+			Name:            terminal.Name,
+			Location:        terminal.Location,
+			PollingUnitID:   terminal.PollingUnitID,
+			Address:         terminal.EthAddress,
+			Status:          terminal.Status,
+			Authorized:      terminal.Authorized,
+			Online:          terminal.Online,
+			LastHeartbeat:   terminal.LastHeartbeat,
+			BatteryLevel:    terminal.BatteryLevel,
+			Charging:        terminal.Charging,
+			FirmwareVersion: terminal.FirmwareVersion,
+			QueueDepth:      terminal.QueueDepth,
+			ClockSkewMs:     terminal.ClockSkewMs,
+			VotesToday:      votesToday,
+			Alerts:          terminalAlerts(alerts),
+		},
+	})
+}
+
+// ListTerminalAlerts lists fleet alerts, open ones only unless all=true (admin)
+func ListTerminalAlerts(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := 100
+		offset := 0
+		if limitStr := c.Query("limit"); limitStr != "" {
+			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 1000 {
+				limit = l
+			}
+		}
+		if offsetStr := c.Query("offset"); offsetStr != "" {
+			if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+				offset = o
+			}
+		}
+		openOnly := c.Query("all") != "true"
+
+		alerts, err := services.TerminalAlertRepository().List(c.Query("terminal_id"), openOnly, limit, offset)
+		if err != nil {
+			services.GetLogger().Error("Failed to list terminal alerts: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to list terminal alerts",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"alerts": terminalAlerts(alerts),
+				"limit":  limit,
+				"offset": offset,
+				"total":  len(alerts),
+			},
+		})
+	}
+}
+
+func terminalAlerts(alerts []database.TerminalAlert) []types.TerminalAlert {
+	out := make([]types.TerminalAlert, 0, len(alerts))
+	for _, a := range alerts {
+		out = append(out, types.TerminalAlert{
+			ID:         a.ID,
+			DeviceID:   a.DeviceID,
+			Kind:       a.Kind,
+			Message:    a.Message,
+			RaisedAt:   a.RaisedAt,
+			ResolvedAt: a.ResolvedAt,
+		})
+	}
+	return out
+}
@@ -0,0 +1,473 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"voting-system/internal/api/interfaces"
+	"voting-system/internal/api/models"
+	"voting-system/internal/api/types"
+	"voting-system/internal/auth"
+	"voting-system/internal/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recoveryCodeCount is how many recovery codes are issued at a time
+const recoveryCodeCount = 10
+
+// GetTwoFactorStatus returns the caller's 2FA enrolment status
+func GetTwoFactorStatus(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := currentUser(c, services)
+		if !ok {
+			return
+		}
+
+		status := types.TwoFactorStatus{Required: twoFactorRequired(services, user.Role)}
+
+		tf, err := services.TwoFactorRepository().Get(user.ID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			services.GetLogger().Error("Failed to load two-factor settings: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to load two-factor settings",
+			})
+			return
+		}
+		if tf != nil && tf.Enabled {
+			status.Enabled = true
+			if tf.EnabledAt != nil {
+				enabledAt := tf.EnabledAt.Unix()
+				status.EnabledAt = &enabledAt
+			}
+			status.RecoveryCodesRemaining, _ = services.TwoFactorRepository().CountRecoveryCodes(user.ID)
+		}
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data:    status,
+		})
+	}
+}
+
+// EnrollTwoFactor starts TOTP enrolment and returns the secret and otpauth URI.
+// 2FA is not active until the first code is confirmed.
+func EnrollTwoFactor(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := currentUser(c, services)
+		if !ok {
+			return
+		}
+
+		enabled, err := services.TwoFactorRepository().IsEnabled(user.ID)
+		if err != nil {
+			services.GetLogger().Error("Failed to load two-factor settings: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to start enrolment",
+			})
+			return
+		}
+		if enabled {
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "two_factor_enabled",
+				Code:    409,
+				Message: "Two-factor authentication is already enabled",
+			})
+			return
+		}
+
+		secret, err := auth.NewTOTPSecret()
+		if err != nil {
+			services.GetLogger().Error("Failed to generate TOTP secret: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "internal_error",
+				Code:    500,
+				Message: "Failed to start enrolment",
+			})
+			return
+		}
+
+		sealed, err := auth.Seal(sealingKey(services), secret)
+		if err == nil {
+			err = services.TwoFactorRepository().SavePending(user.ID, sealed)
+		}
+		if err != nil {
+			services.GetLogger().Error("Failed to store TOTP secret: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "internal_error",
+				Code:    500,
+				Message: "Failed to start enrolment",
+			})
+			return
+		}
+
+		createAuditLog(services, "two_factor_enrollment_started", c.GetString("user_id"), "",
+			fmt.Sprintf("User %s started TOTP enrolment", user.Username), getClientIP(c))
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data: types.TwoFactorEnrollment{
+				Secret: secret,
+				URI:    auth.TOTPURI(twoFactorIssuer(services), user.Username, secret),
+			},
+			Message: "Scan the URI in an authenticator app and confirm with a code",
+		})
+	}
+}
+
+// ConfirmTwoFactor enables 2FA once the user proves they hold the secret and
+// returns the initial recovery codes
+func ConfirmTwoFactor(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := currentUser(c, services)
+		if !ok {
+			return
+		}
+
+		var req types.TwoFactorCodeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_request",
+				Code:    400,
+				Message: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		tf, err := services.TwoFactorRepository().Get(user.ID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "two_factor_not_started",
+				Code:    400,
+				Message: "Start enrolment before confirming a code",
+			})
+			return
+		}
+		if tf.Enabled {
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "two_factor_enabled",
+				Code:    409,
+				Message: "Two-factor authentication is already enabled",
+			})
+			return
+		}
+
+		secret, err := auth.Open(sealingKey(services), tf.Secret)
+		if err != nil {
+			services.GetLogger().Error("Failed to open TOTP secret: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "internal_error",
+				Code:    500,
+				Message: "Failed to confirm enrolment",
+			})
+			return
+		}
+
+		step, valid := auth.ValidateTOTP(secret, req.Code, time.Now())
+		if !valid {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_two_factor_code",
+				Code:    400,
+				Message: "Invalid one-time code",
+			})
+			return
+		}
+
+		codes, err := issueRecoveryCodes(services, user.ID)
+		if err == nil {
+			err = services.TwoFactorRepository().Enable(user.ID, step)
+		}
+		if err != nil {
+			services.GetLogger().Error("Failed to enable two-factor authentication: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to confirm enrolment",
+			})
+			return
+		}
+
+		// Lift the enrolment-only restriction from the current session
+		if err := clearTwoFactorPending(services, c.GetString("session_id")); err != nil {
+			services.GetLogger().Error("Failed to update session: %v", err)
+		}
+
+		createAuditLog(services, "two_factor_enabled", c.GetString("user_id"), "",
+			fmt.Sprintf("User %s enabled TOTP two-factor authentication", user.Username), getClientIP(c))
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data:    types.RecoveryCodes{Codes: codes},
+			Message: "Two-factor authentication enabled. Store the recovery codes safely; they are shown only once",
+		})
+	}
+}
+
+// RegenerateRecoveryCodes replaces the caller's recovery codes. A current TOTP code is required.
+func RegenerateRecoveryCodes(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := currentUser(c, services)
+		if !ok {
+			return
+		}
+
+		var req types.TwoFactorCodeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_request",
+				Code:    400,
+				Message: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		if _, valid := verifySecondFactor(services, user.ID, req.Code, ""); !valid {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_two_factor_code",
+				Code:    400,
+				Message: "Invalid one-time code",
+			})
+			return
+		}
+
+		codes, err := issueRecoveryCodes(services, user.ID)
+		if err != nil {
+			services.GetLogger().Error("Failed to issue recovery codes: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to issue recovery codes",
+			})
+			return
+		}
+
+		createAuditLog(services, "recovery_codes_regenerated", c.GetString("user_id"), "",
+			fmt.Sprintf("User %s regenerated recovery codes", user.Username), getClientIP(c))
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data:    types.RecoveryCodes{Codes: codes},
+		})
+	}
+}
+
+// DisableTwoFactor turns off 2FA for the caller. Roles that require 2FA cannot disable it.
+func DisableTwoFactor(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := currentUser(c, services)
+		if !ok {
+			return
+		}
+
+		if twoFactorRequired(services, user.Role) {
+			c.JSON(http.StatusForbidden, types.ErrorResponse{
+				Error:   "two_factor_required",
+				Code:    403,
+				Message: fmt.Sprintf("Two-factor authentication is mandatory for the %s role", user.Role),
+			})
+			return
+		}
+
+		var req types.TwoFactorCodeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_request",
+				Code:    400,
+				Message: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		if _, valid := verifySecondFactor(services, user.ID, req.Code, ""); !valid {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_two_factor_code",
+				Code:    400,
+				Message: "Invalid one-time code",
+			})
+			return
+		}
+
+		if err := services.TwoFactorRepository().Delete(user.ID); err != nil {
+			services.GetLogger().Error("Failed to disable two-factor authentication: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to disable two-factor authentication",
+			})
+			return
+		}
+
+		createAuditLog(services, "two_factor_disabled", c.GetString("user_id"), "",
+			fmt.Sprintf("User %s disabled two-factor authentication", user.Username), getClientIP(c))
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Message: "Two-factor authentication disabled",
+		})
+	}
+}
+
+// ResetUserTwoFactor removes a user's 2FA enrolment (e.g. after a lost device)
+// and revokes their sessions. They must enrol again at next login if their role requires it.
+func ResetUserTwoFactor(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := loadUserParam(c, services)
+		if !ok {
+			return
+		}
+
+		if err := services.TwoFactorRepository().Delete(user.ID); err != nil {
+			services.GetLogger().Error("Failed to reset two-factor authentication: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to reset two-factor authentication",
+			})
+			return
+		}
+		if _, err := services.SessionRepository().DeleteByUser(user.ID); err != nil {
+			services.GetLogger().Error("Failed to revoke sessions: %v", err)
+		}
+
+		createAuditLog(services, "two_factor_reset", c.GetString("user_id"), "",
+			fmt.Sprintf("Reset two-factor authentication for user %d (%s)", user.ID, user.Username), getClientIP(c))
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Message: "Two-factor authentication reset",
+		})
+	}
+}
+
+// verifySecondFactor checks a TOTP code or, failing that, a recovery code.
+// Each TOTP code and recovery code is accepted only once.
+func verifySecondFactor(services interfaces.Services, userID int64, otp, recoveryCode string) (string, bool) {
+	if otp != "" {
+		tf, err := services.TwoFactorRepository().Get(userID)
+		if err != nil || !tf.Enabled {
+			return "", false
+		}
+
+		secret, err := auth.Open(sealingKey(services), tf.Secret)
+		if err != nil {
+			services.GetLogger().Error("Failed to open TOTP secret: %v", err)
+			return "", false
+		}
+
+		step, valid := auth.ValidateTOTP(secret, otp, time.Now())
+		if !valid {
+			return "", false
+		}
+
+		fresh, err := services.TwoFactorRepository().UseStep(userID, step)
+		if err != nil {
+			services.GetLogger().Error("Failed to record TOTP use: %v", err)
+			return "", false
+		}
+		return "totp", fresh
+	}
+
+	if recoveryCode != "" {
+		used, err := services.TwoFactorRepository().UseRecoveryCode(userID,
+			auth.HashToken(auth.NormalizeRecoveryCode(recoveryCode)))
+		if err != nil {
+			services.GetLogger().Error("Failed to use recovery code: %v", err)
+			return "", false
+		}
+		return "recovery_code", used
+	}
+
+	return "", false
+}
+
+// issueRecoveryCodes generates a new set of recovery codes and stores their digests
+func issueRecoveryCodes(services interfaces.Services, userID int64) ([]string, error) {
+	codes, err := auth.NewRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = auth.HashToken(auth.NormalizeRecoveryCode(code))
+	}
+
+	if err := services.TwoFactorRepository().ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// clearTwoFactorPending lifts the enrolment-only restriction from a session
+func clearTwoFactorPending(services interfaces.Services, sessionID string) error {
+	session, err := services.SessionRepository().GetByID(sessionID)
+	if err != nil {
+		return err
+	}
+
+	var data database.SessionData
+	if err := json.Unmarshal([]byte(session.Data), &data); err != nil {
+		return err
+	}
+	if !data.TwoFactorPending {
+		return nil
+	}
+
+	data.TwoFactorPending = false
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return services.SessionRepository().Update(session.ID, string(encoded), session.ExpiresAt)
+}
+
+// twoFactorRequired reports whether the policy forces 2FA for role
+func twoFactorRequired(services interfaces.Services, role string) bool {
+	return services.GetConfig().Security.EnableTwoFA && models.RequiresTwoFactor(role)
+}
+
+// sealingKey is the key server-held secrets are sealed with: TOTP secrets,
+// trustee key shares awaiting collection and voters' fingerprint templates
+func sealingKey(services interfaces.Services) string {
+	return services.GetConfig().SealingKey()
+}
+
+// twoFactorIssuer is the issuer shown in authenticator apps
+func twoFactorIssuer(services interfaces.Services) string {
+	if issuer := services.GetConfig().Security.TwoFAIssuer; issuer != "" {
+		return issuer
+	}
+	return "Voting System"
+}
+
+// currentUser loads the authenticated user, writing a 400 for non-user callers
+func currentUser(c *gin.Context, services interfaces.Services) (*database.User, bool) {
+	userID, err := strconv.ParseInt(c.GetString("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    400,
+			Message: "Only user accounts can use two-factor authentication",
+		})
+		return nil, false
+	}
+
+	user, err := services.UserRepository().GetByID(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, types.ErrorResponse{
+			Error:   "user_not_found",
+			Code:    404,
+			Message: "User not found",
+		})
+		return nil, false
+	}
+	return user, true
+}
@@ -0,0 +1,571 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"voting-system/internal/api/interfaces"
+	"voting-system/internal/api/models"
+	"voting-system/internal/api/types"
+	"voting-system/internal/auth"
+	"voting-system/internal/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListUsers returns staff accounts, optionally filtered by role and status
+func ListUsers(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := 50
+		offset := 0
+		if limitStr := c.Query("limit"); limitStr != "" {
+			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 1000 {
+				limit = l
+			}
+		}
+		if offsetStr := c.Query("offset"); offsetStr != "" {
+			if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+				offset = o
+			}
+		}
+
+		var isActive *bool
+		if activeStr := c.Query("is_active"); activeStr != "" {
+			if active, err := strconv.ParseBool(activeStr); err == nil {
+				isActive = &active
+			}
+		}
+
+		users, err := services.UserRepository().ListUsers(c.Query("role"), isActive, limit, offset)
+		if err != nil {
+			services.GetLogger().Error("Failed to list users: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to list users",
+			})
+			return
+		}
+
+		result := make([]*types.UserInfo, 0, len(users))
+		for i := range users {
+			result = append(result, toUserInfo(&users[i]))
+		}
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"users":  result,
+				"limit":  limit,
+				"offset": offset,
+			},
+		})
+	}
+}
+
+// GetUser returns a single staff account
+func GetUser(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := loadUserParam(c, services)
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data:    toUserInfo(user),
+		})
+	}
+}
+
+// CreateUser creates a staff account with a role and optional extra permissions
+func CreateUser(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.CreateUserRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_request",
+				Code:    400,
+				Message: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		permissions, err := encodePermissions(req.Role, req.Permissions)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_role",
+				Code:    400,
+				Message: err.Error(),
+			})
+			return
+		}
+
+		security := services.GetConfig().Security
+		if err := auth.ValidatePassword(req.Password, security.PasswordMinLength, security.RequireStrongPasswd); err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "weak_password",
+				Code:    400,
+				Message: err.Error(),
+			})
+			return
+		}
+
+		hash, err := auth.HashPassword(req.Password)
+		if err != nil {
+			services.GetLogger().Error("Failed to hash password: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "internal_error",
+				Code:    500,
+				Message: "Failed to create user",
+			})
+			return
+		}
+
+		user := &database.User{
+			Username:     strings.TrimSpace(req.Username),
+			Email:        strings.TrimSpace(req.Email),
+			PasswordHash: hash,
+			FirstName:    req.FirstName,
+			LastName:     req.LastName,
+			Role:         req.Role,
+			Permissions:  permissions,
+		}
+		if err := services.UserRepository().Create(user); err != nil {
+			if isUniqueViolation(err) {
+				c.JSON(http.StatusConflict, types.ErrorResponse{
+					Error:   "user_exists",
+					Code:    409,
+					Message: "Username or email is already in use",
+				})
+				return
+			}
+			services.GetLogger().Error("Failed to create user: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to create user",
+			})
+			return
+		}
+
+		createAuditLog(services, "user_created", c.GetString("user_id"), "",
+			fmt.Sprintf("Created user %d (%s) with role %s and permissions %s", user.ID, user.Username, user.Role, permissions),
+			getClientIP(c))
+
+		created, err := services.UserRepository().GetByID(user.ID)
+		if err != nil {
+			created = user
+		}
+
+		c.JSON(http.StatusCreated, types.SuccessResponse{
+			Success: true,
+			Data:    toUserInfo(created),
+			Message: "User created",
+		})
+	}
+}
+
+// UpdateUser changes a staff account's profile, role, permissions or status
+func UpdateUser(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := loadUserParam(c, services)
+		if !ok {
+			return
+		}
+
+		var req types.UpdateUserRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_request",
+				Code:    400,
+				Message: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		var changes []string
+		if req.Email != nil && *req.Email != user.Email {
+			changes = append(changes, fmt.Sprintf("email %s -> %s", user.Email, *req.Email))
+			user.Email = strings.TrimSpace(*req.Email)
+		}
+		if req.FirstName != nil && *req.FirstName != user.FirstName {
+			changes = append(changes, "first_name")
+			user.FirstName = *req.FirstName
+		}
+		if req.LastName != nil && *req.LastName != user.LastName {
+			changes = append(changes, "last_name")
+			user.LastName = *req.LastName
+		}
+
+		role := user.Role
+		if req.Role != nil {
+			role = *req.Role
+		}
+		extra, _ := models.ParsePermissions(user.Permissions)
+		if req.Permissions != nil {
+			extra = *req.Permissions
+		}
+		permissions, err := encodePermissions(role, extra)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_role",
+				Code:    400,
+				Message: err.Error(),
+			})
+			return
+		}
+		if role != user.Role {
+			changes = append(changes, fmt.Sprintf("role %s -> %s", user.Role, role))
+		}
+		if permissions != user.Permissions {
+			changes = append(changes, fmt.Sprintf("permissions %s -> %s", user.Permissions, permissions))
+		}
+
+		isActive := user.IsActive
+		if req.IsActive != nil {
+			isActive = *req.IsActive
+		}
+		if isActive != user.IsActive {
+			changes = append(changes, fmt.Sprintf("is_active %t -> %t", user.IsActive, isActive))
+		}
+
+		// Never remove the last active admin
+		losesAdmin := user.Role == models.RoleAdmin && user.IsActive && (role != models.RoleAdmin || !isActive)
+		if losesAdmin && !guardLastAdmin(c, services) {
+			return
+		}
+
+		if len(changes) == 0 {
+			c.JSON(http.StatusOK, types.SuccessResponse{
+				Success: true,
+				Data:    toUserInfo(user),
+				Message: "No changes",
+			})
+			return
+		}
+
+		revokeSessions := role != user.Role || !isActive
+		user.Role = role
+		user.Permissions = permissions
+		user.IsActive = isActive
+
+		if err := services.UserRepository().UpdateUser(user); err != nil {
+			if isUniqueViolation(err) {
+				c.JSON(http.StatusConflict, types.ErrorResponse{
+					Error:   "user_exists",
+					Code:    409,
+					Message: "Email is already in use",
+				})
+				return
+			}
+			services.GetLogger().Error("Failed to update user: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to update user",
+			})
+			return
+		}
+
+		// Role changes and deactivation end existing sessions
+		if revokeSessions {
+			if _, err := services.SessionRepository().DeleteByUser(user.ID); err != nil {
+				services.GetLogger().Error("Failed to revoke sessions: %v", err)
+			}
+		}
+
+		createAuditLog(services, "user_updated", c.GetString("user_id"), "",
+			fmt.Sprintf("Updated user %d (%s): %s", user.ID, user.Username, strings.Join(changes, ", ")),
+			getClientIP(c))
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data:    toUserInfo(user),
+			Message: "User updated",
+		})
+	}
+}
+
+// DeleteUser deactivates a staff account and revokes its sessions.
+// Accounts are kept so that audit log entries still resolve.
+func DeleteUser(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := loadUserParam(c, services)
+		if !ok {
+			return
+		}
+
+		if strconv.FormatInt(user.ID, 10) == c.GetString("user_id") {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_request",
+				Code:    400,
+				Message: "You cannot delete your own account",
+			})
+			return
+		}
+
+		if user.Role == models.RoleAdmin && user.IsActive && !guardLastAdmin(c, services) {
+			return
+		}
+
+		if err := services.UserRepository().DeactivateUser(user.ID); err != nil {
+			services.GetLogger().Error("Failed to deactivate user: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to delete user",
+			})
+			return
+		}
+		if _, err := services.SessionRepository().DeleteByUser(user.ID); err != nil {
+			services.GetLogger().Error("Failed to revoke sessions: %v", err)
+		}
+		if err := services.PasswordResetRepository().DeleteByUser(user.ID); err != nil {
+			services.GetLogger().Error("Failed to remove password reset tokens: %v", err)
+		}
+
+		createAuditLog(services, "user_deactivated", c.GetString("user_id"), "",
+			fmt.Sprintf("Deactivated user %d (%s)", user.ID, user.Username), getClientIP(c))
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Message: "User deactivated",
+		})
+	}
+}
+
+// ResetUserPassword issues a one-time password reset token for a staff account.
+// The token is only returned in this response.
+func ResetUserPassword(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := loadUserParam(c, services)
+		if !ok {
+			return
+		}
+
+		if !user.IsActive {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "user_inactive",
+				Code:    400,
+				Message: "Cannot reset the password of an inactive user",
+			})
+			return
+		}
+
+		token, err := auth.RandomToken(32)
+		if err != nil {
+			services.GetLogger().Error("Failed to generate reset token: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "internal_error",
+				Code:    500,
+				Message: "Failed to issue reset token",
+			})
+			return
+		}
+
+		ttl := services.GetConfig().Security.PasswordResetTTL
+		if ttl <= 0 {
+			ttl = time.Hour
+		}
+		expiresAt := time.Now().Add(ttl)
+
+		err = services.PasswordResetRepository().Create(&database.PasswordResetToken{
+			TokenHash: auth.HashToken(token),
+			UserID:    user.ID,
+			CreatedBy: c.GetString("user_id"),
+			ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			services.GetLogger().Error("Failed to store reset token: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to issue reset token",
+			})
+			return
+		}
+
+		createAuditLog(services, "password_reset_issued", c.GetString("user_id"), "",
+			fmt.Sprintf("Issued password reset for user %d (%s), expires %s", user.ID, user.Username, expiresAt.UTC().Format(time.RFC3339)),
+			getClientIP(c))
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data: types.PasswordResetToken{
+				UserID:    user.ID,
+				Token:     token,
+				ExpiresAt: expiresAt.Unix(),
+			},
+			Message: "Password reset token issued",
+		})
+	}
+}
+
+// CompletePasswordReset sets a new password using a one-time reset token
+func CompletePasswordReset(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.CompletePasswordResetRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_request",
+				Code:    400,
+				Message: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		security := services.GetConfig().Security
+		if err := auth.ValidatePassword(req.NewPassword, security.PasswordMinLength, security.RequireStrongPasswd); err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "weak_password",
+				Code:    400,
+				Message: err.Error(),
+			})
+			return
+		}
+
+		clientIP := getClientIP(c)
+		userID, err := services.PasswordResetRepository().Consume(auth.HashToken(req.Token))
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				services.GetLogger().Error("Failed to consume reset token: %v", err)
+			}
+			createAuditLog(services, "password_reset_rejected", "", "", "Invalid or expired reset token", clientIP)
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_reset_token",
+				Code:    400,
+				Message: "Reset token is invalid, expired or already used",
+			})
+			return
+		}
+
+		user, err := services.UserRepository().GetByID(userID)
+		if err != nil || !user.IsActive {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_reset_token",
+				Code:    400,
+				Message: "Reset token is invalid, expired or already used",
+			})
+			return
+		}
+
+		hash, err := auth.HashPassword(req.NewPassword)
+		if err == nil {
+			err = services.UserRepository().UpdatePassword(user.ID, hash)
+		}
+		if err != nil {
+			services.GetLogger().Error("Failed to update password: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to reset password",
+			})
+			return
+		}
+
+		// A new password ends every existing session and clears any lockout
+		if _, err := services.SessionRepository().DeleteByUser(user.ID); err != nil {
+			services.GetLogger().Error("Failed to revoke sessions: %v", err)
+		}
+		if err := services.LoginAttemptRepository().Reset(user.Username); err != nil {
+			services.GetLogger().Error("Failed to reset login attempts: %v", err)
+		}
+
+		createAuditLog(services, "password_reset_completed", strconv.FormatInt(user.ID, 10), "",
+			fmt.Sprintf("Password reset for user %s", user.Username), clientIP)
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Message: "Password updated",
+		})
+	}
+}
+
+// loadUserParam loads the user named by the :id path parameter, writing a 400 or 404 on failure
+func loadUserParam(c *gin.Context, services interfaces.Services) (*database.User, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    400,
+			Message: "Invalid user ID",
+		})
+		return nil, false
+	}
+
+	user, err := services.UserRepository().GetByID(id)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			services.GetLogger().Error("Failed to load user: %v", err)
+		}
+		c.JSON(http.StatusNotFound, types.ErrorResponse{
+			Error:   "user_not_found",
+			Code:    404,
+			Message: "User not found",
+		})
+		return nil, false
+	}
+
+	return user, true
+}
+
+// guardLastAdmin writes a 409 and returns false if only one active admin remains
+func guardLastAdmin(c *gin.Context, services interfaces.Services) bool {
+	admins, err := services.UserRepository().CountActiveByRole(models.RoleAdmin)
+	if err != nil {
+		services.GetLogger().Error("Failed to count admins: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "database_error",
+			Code:    500,
+			Message: "Failed to update user",
+		})
+		return false
+	}
+	if admins <= 1 {
+		c.JSON(http.StatusConflict, types.ErrorResponse{
+			Error:   "last_admin",
+			Code:    409,
+			Message: "At least one active admin is required",
+		})
+		return false
+	}
+	return true
+}
+
+// encodePermissions validates a staff role and its extra permissions and
+// returns the JSON stored on the user record
+func encodePermissions(role string, permissions []string) (string, error) {
+	if !models.IsStaffRole(role) {
+		return "", fmt.Errorf("invalid role %q", role)
+	}
+
+	extra := make([]string, 0, len(permissions))
+	seen := make(map[string]bool)
+	for _, perm := range permissions {
+		if !models.IsAssignablePermission(perm) {
+			return "", fmt.Errorf("invalid permission %q", perm)
+		}
+		if !seen[perm] {
+			seen[perm] = true
+			extra = append(extra, perm)
+		}
+	}
+
+	encoded, err := json.Marshal(extra)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// isUniqueViolation reports whether err is a unique constraint failure on sqlite or postgres
+func isUniqueViolation(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "UNIQUE constraint failed") || strings.Contains(msg, "duplicate key value")
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+
+	"voting-system/internal/api/interfaces"
+	"voting-system/internal/auth"
+	"voting-system/internal/database"
+	"voting-system/internal/votesig"
+)
+
+// errNoActiveElection is returned for a verification hash outside an election
+var errNoActiveElection = errors.New("no active election")
+
+// electionSalt returns the secret salt of an election's verification hashes,
+// creating it the first time it is needed
+func electionSalt(services interfaces.Services, electionID string) (string, error) {
+	stored, err := services.ElectionSaltRepository().Get(electionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		stored, err = createElectionSalt(services, electionID)
+	}
+	if err != nil {
+		return "", err
+	}
+	return auth.Open(sealingKey(services), stored.SaltSealed)
+}
+
+// createElectionSalt stores a new random salt for an election. If another
+// request created one first, that one is kept and returned.
+func createElectionSalt(services interfaces.Services, electionID string) (*database.ElectionSalt, error) {
+	salt, err := auth.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := auth.Seal(sealingKey(services), salt)
+	if err != nil {
+		return nil, err
+	}
+	return services.ElectionSaltRepository().Create(electionID, sealed)
+}
+
+// activeElection returns the election active in the database cache, or
+// errNoActiveElection
+func activeElection(services interfaces.Services) (*database.Election, error) {
+	election, err := services.ElectionRepository().GetActiveElection()
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errNoActiveElection
+	}
+	return election, err
+}
+
+// voteVerificationHash returns the active election and the verification hash
+// of a vote by the voter with nin in it
+func voteVerificationHash(services interfaces.Services, nin string) (*database.Election, string, error) {
+	election, err := activeElection(services)
+	if err != nil {
+		return nil, "", err
+	}
+
+	salt, err := electionSalt(services, election.BlockchainID)
+	if err != nil {
+		return nil, "", err
+	}
+	return election, votesig.VerificationHash(salt, nin), nil
+}
+
+// electionVerificationHash returns the verification hash of the voter with nin
+// in an election that already has a salt. It returns sql.ErrNoRows for an
+// election without one rather than creating it.
+func electionVerificationHash(services interfaces.Services, electionID, nin string) (string, error) {
+	stored, err := services.ElectionSaltRepository().Get(electionID)
+	if err != nil {
+		return "", err
+	}
+	salt, err := auth.Open(sealingKey(services), stored.SaltSealed)
+	if err != nil {
+		return "", err
+	}
+	return votesig.VerificationHash(salt, nin), nil
+}
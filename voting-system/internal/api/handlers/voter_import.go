@@ -0,0 +1,390 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"voting-system/internal/api/interfaces"
+	"voting-system/internal/api/types"
+	"voting-system/internal/auth"
+	"voting-system/internal/biometric"
+	"voting-system/internal/database"
+	"voting-system/internal/voterimport"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxImportSize bounds an uploaded voter import file
+	maxImportSize = 64 << 20
+	// importProgressEvery is how many rows are checked between progress updates
+	importProgressEvery = 100
+)
+
+// importFormat returns the format of an import upload: the format query
+// parameter, or the one its content type names
+func importFormat(c *gin.Context) string {
+	if format := c.Query("format"); format != "" {
+		return strings.ToLower(format)
+	}
+	switch c.ContentType() {
+	case "text/csv":
+		return voterimport.FormatCSV
+	case "application/x-ndjson", "application/jsonl":
+		return voterimport.FormatJSONL
+	}
+	return ""
+}
+
+// ImportVoters starts a bulk voter import from a CSV or JSON Lines file sent
+// as the request body. Rows are checked and registered in the background;
+// the response carries the job to poll.
+func ImportVoters(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		dryRun := false
+		if param := c.Query("dry_run"); param != "" {
+			parsed, err := strconv.ParseBool(param)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, types.ErrorResponse{
+					Error:   "invalid_request",
+					Code:    400,
+					Message: "dry_run must be true or false",
+				})
+				return
+			}
+			dryRun = parsed
+		}
+
+		format := importFormat(c)
+		body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+		rows, rowErrors, err := voterimport.Parse(format, body, time.Now())
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, types.ErrorResponse{
+				Error:   "file_too_large",
+				Code:    413,
+				Message: fmt.Sprintf("Import files are limited to %d MB", maxImportSize>>20),
+			})
+			return
+		}
+		if err == nil && len(rows)+len(rowErrors) == 0 {
+			err = errors.New("file has no rows")
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_file",
+				Code:    400,
+				Message: err.Error(),
+			})
+			return
+		}
+
+		matcher, ok := biometrics(c, services)
+		if !ok {
+			return
+		}
+
+		id, err := auth.RandomToken(16)
+		var job *database.VoterImportJob
+		if err == nil {
+			job = &database.VoterImportJob{
+				ID:         id,
+				Format:     format,
+				DryRun:     dryRun,
+				Status:     database.ImportRunning,
+				TotalRows:  len(rows) + len(rowErrors),
+				FailedRows: len(rowErrors),
+				CreatedBy:  c.GetString("user_id"),
+			}
+			err = services.VoterImportRepository().Create(job)
+		}
+		if err == nil {
+			err = services.VoterImportRepository().AddErrors(job.ID, importErrors(rowErrors))
+		}
+		if err == nil {
+			err = services.VoterImportRepository().UpdateProgress(job.ID, len(rowErrors), 0, len(rowErrors))
+			job.ProcessedRows = len(rowErrors)
+		}
+		if err != nil {
+			services.GetLogger().Error("Failed to create voter import job: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to start the import",
+			})
+			return
+		}
+
+		clientIP := getClientIP(c)
+		createAuditLog(services, "voter_import_started", job.CreatedBy, "",
+			fmt.Sprintf("Voter import %s: %d row(s) of %s, dry run %t", job.ID, job.TotalRows, format, dryRun), clientIP)
+
+		go runVoterImport(services, matcher, *job, rows, clientIP)
+
+		c.JSON(http.StatusAccepted, types.SuccessResponse{
+			Success: true,
+			Message: "Import started",
+			Data:    job,
+		})
+	}
+}
+
+// GetVoterImport returns an import job and its progress
+func GetVoterImport(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, ok := loadVoterImport(c, services)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data:    job,
+		})
+	}
+}
+
+// ListVoterImportErrors returns a page of the rows an import could not take
+func ListVoterImportErrors(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := 100
+		offset := 0
+		if limitStr := c.Query("limit"); limitStr != "" {
+			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 1000 {
+				limit = l
+			}
+		}
+		if offsetStr := c.Query("offset"); offsetStr != "" {
+			if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+				offset = o
+			}
+		}
+
+		job, ok := loadVoterImport(c, services)
+		if !ok {
+			return
+		}
+		rowErrors, err := services.VoterImportRepository().ListErrors(job.ID, limit, offset)
+		if err != nil {
+			services.GetLogger().Error("Failed to list voter import errors: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to list import errors",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data: types.VoterImportErrors{
+				JobID:  job.ID,
+				Total:  job.FailedRows,
+				Limit:  limit,
+				Offset: offset,
+				Errors: rowErrors,
+			},
+		})
+	}
+}
+
+// loadVoterImport returns the import job named in the path, writing a 404 if
+// there is none
+func loadVoterImport(c *gin.Context, services interfaces.Services) (*database.VoterImportJob, bool) {
+	job, err := services.VoterImportRepository().Get(c.Param("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, types.ErrorResponse{
+			Error:   "import_not_found",
+			Code:    404,
+			Message: "Import job not found",
+		})
+		return nil, false
+	}
+	if err != nil {
+		services.GetLogger().Error("Failed to load voter import: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "database_error",
+			Code:    500,
+			Message: "Failed to load the import",
+		})
+		return nil, false
+	}
+	return job, true
+}
+
+// importErrors converts row errors for storage
+func importErrors(rowErrors []voterimport.RowError) []database.VoterImportError {
+	out := make([]database.VoterImportError, len(rowErrors))
+	for i, e := range rowErrors {
+		out[i] = database.VoterImportError{Line: e.Line, NIN: e.NIN, Code: e.Code, Message: e.Message}
+	}
+	return out
+}
+
+// voterImport checks the rows of an import against the registered voters
+// and against each other
+type voterImport struct {
+	services interfaces.Services
+	matcher  *biometric.Service
+	gallery  []biometric.Candidate // enrolled templates, then those of accepted rows
+	nins     map[string]int        // accepted rows' line by NIN
+	hashes   map[string]int        // accepted rows' line by fingerprint hash
+	voters   []*database.Voter
+	sealed   []*database.VoterTemplate
+}
+
+// runVoterImport checks every row of an import and, unless it is a dry run,
+// registers the rows that pass in one transaction. Progress and row errors
+// are stored as it goes.
+func runVoterImport(services interfaces.Services, matcher *biometric.Service, job database.VoterImportJob, rows []voterimport.Row, clientIP string) {
+	repo := services.VoterImportRepository()
+	finish := func(err error) {
+		job.Status = database.ImportCompleted
+		action := "voter_import_completed"
+		if err != nil {
+			services.GetLogger().Error("Voter import %s failed: %v", job.ID, err)
+			job.Status, job.Error = database.ImportFailed, err.Error()
+			action = "voter_import_failed"
+		}
+		createAuditLog(services, action, job.CreatedBy, "",
+			fmt.Sprintf("Voter import %s: %d imported, %d failed of %d row(s), dry run %t",
+				job.ID, job.ImportedRows, job.FailedRows, job.TotalRows, job.DryRun), clientIP)
+		if err := repo.Finish(&job); err != nil {
+			services.GetLogger().Error("Failed to record the outcome of voter import %s: %v", job.ID, err)
+		}
+	}
+
+	gallery, err := templateGallery(services, matcher.Format())
+	if err != nil {
+		finish(fmt.Errorf("failed to load fingerprint templates: %v", err))
+		return
+	}
+	run := &voterImport{
+		services: services,
+		matcher:  matcher,
+		gallery:  gallery,
+		nins:     make(map[string]int),
+		hashes:   make(map[string]int),
+	}
+
+	var pending []database.VoterImportError
+	for i := range rows {
+		rowErr, err := run.check(&rows[i])
+		if err != nil {
+			finish(fmt.Errorf("line %d: %v", rows[i].Line, err))
+			return
+		}
+		job.ProcessedRows++
+		if rowErr != nil {
+			job.FailedRows++
+			pending = append(pending, *rowErr)
+		} else {
+			job.ImportedRows++
+		}
+
+		if job.ProcessedRows%importProgressEvery == 0 || i == len(rows)-1 {
+			err := repo.AddErrors(job.ID, pending)
+			if err == nil {
+				err = repo.UpdateProgress(job.ID, job.ProcessedRows, job.ImportedRows, job.FailedRows)
+			}
+			if err != nil {
+				finish(fmt.Errorf("failed to record progress: %v", err))
+				return
+			}
+			pending = nil
+		}
+	}
+
+	if !job.DryRun && len(run.voters) > 0 {
+		if err := services.VoterRepository().RegisterVoters(run.voters, run.sealed); err != nil {
+			job.ImportedRows = 0
+			finish(fmt.Errorf("failed to register voters, none were imported: %v", err))
+			return
+		}
+		for _, voter := range run.voters {
+			createAuditLog(services, "voter_registered", voter.NIN, voter.PollingUnitID,
+				fmt.Sprintf("Voter registered: %s %s (import %s)", voter.FirstName, voter.LastName, job.ID), clientIP)
+		}
+	}
+	finish(nil)
+}
+
+// check checks a row, keeping its voter for registration if it passes. It
+// returns the row's error, or an error if the row could not be checked.
+func (run *voterImport) check(row *voterimport.Row) (*database.VoterImportError, error) {
+	reject := func(code, format string, args ...interface{}) (*database.VoterImportError, error) {
+		return &database.VoterImportError{Line: row.Line, NIN: row.NIN, Code: code, Message: fmt.Sprintf(format, args...)}, nil
+	}
+
+	if line, ok := run.nins[row.NIN]; ok {
+		return reject(voterimport.CodeDuplicateNIN, "NIN is already on line %d", line)
+	}
+	_, err := run.services.VoterRepository().GetVoterByNIN(row.NIN)
+	if err == nil {
+		return reject(voterimport.CodeDuplicateNIN, "Voter with this NIN is already registered")
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	template, err := run.matcher.Enrol(row.FingerprintData)
+	switch {
+	case errors.Is(err, biometric.ErrLowQuality):
+		return reject(voterimport.CodeLowQuality, "Fingerprint quality %.2f is below the required %.2f",
+			template.Quality, run.services.GetConfig().Biometric.QualityThreshold)
+	case errors.Is(err, biometric.ErrInvalidSample):
+		return reject(voterimport.CodeInvalidFingerprint, "%v", err)
+	case err != nil:
+		return nil, err
+	}
+
+	// The same finger, by its keyed or unkeyed hash or by matching
+	hash := run.matcher.Hash(row.FingerprintData)
+	if line, ok := run.hashes[hash]; ok {
+		return reject(voterimport.CodeDuplicateFingerprint, "Fingerprint is already on line %d", line)
+	}
+	for _, h := range []string{hash, biometric.Hash(nil, row.FingerprintData)} {
+		_, err := run.services.VoterRepository().GetVoterByFingerprint(h)
+		if err == nil {
+			return reject(voterimport.CodeDuplicateFingerprint, "Fingerprint is already registered")
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+	}
+	matches, err := run.matcher.Identify(row.FingerprintData, run.gallery)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) > 0 {
+		if line, ok := run.nins[matches[0].ID]; ok {
+			return reject(voterimport.CodeDuplicateFingerprint, "Fingerprint matches line %d with score %.2f", line, matches[0].Score)
+		}
+		return reject(voterimport.CodeDuplicateFingerprint, "Fingerprint matches a registered voter with score %.2f", matches[0].Score)
+	}
+
+	sealed, err := sealTemplate(run.services, template)
+	if err != nil {
+		return nil, err
+	}
+	run.nins[row.NIN] = row.Line
+	run.hashes[hash] = row.Line
+	run.gallery = append(run.gallery, biometric.Candidate{ID: row.NIN, Template: template})
+	run.voters = append(run.voters, &database.Voter{
+		NIN:             row.NIN,
+		FirstName:       row.FirstName,
+		LastName:        row.LastName,
+		DateOfBirth:     row.DateOfBirth,
+		Gender:          row.Gender,
+		PollingUnitID:   row.PollingUnitID,
+		FingerprintHash: hash,
+		RegisteredAt:    time.Now(),
+		IsActive:        true,
+	})
+	run.sealed = append(run.sealed, sealed)
+	return nil, nil
+}
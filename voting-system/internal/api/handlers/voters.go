@@ -0,0 +1,466 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"voting-system/internal/api/interfaces"
+	"voting-system/internal/api/types"
+	"voting-system/internal/biometric"
+	"voting-system/internal/database"
+	"voting-system/internal/database/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Reason codes accepted for each voter lifecycle operation. Deactivation
+// reasons are stored on the voter; every reason is recorded in the audit log.
+var (
+	transferReasons     = []string{"relocation", "correction", "polling_unit_closed"}
+	deactivationReasons = []string{database.VoterDeceased, database.VoterDuplicate, database.VoterIneligible}
+	reactivationReasons = []string{"deactivated_in_error", "appeal_upheld"}
+	reenrolmentReasons  = []string{"injury", "poor_quality", "enrolment_error"}
+)
+
+// checkVoterActive refuses voters that have been deactivated. It writes the
+// response and returns false if the voter is not active.
+func checkVoterActive(c *gin.Context, services interfaces.Services, voter *database.Voter, action, pollingUnitID, clientIP string) bool {
+	if voter.IsActive {
+		return true
+	}
+	services.GetLogger().Warning("Deactivated voter refused - nin: %s, reason: %s", voter.NIN, voter.DeactivationReason)
+	createAuditLog(services, action+"_rejected_voter_deactivated", voter.NIN, pollingUnitID,
+		fmt.Sprintf("Voter was deactivated (%s)", voter.DeactivationReason), clientIP)
+	c.JSON(http.StatusForbidden, types.ErrorResponse{
+		Error:   "voter_deactivated",
+		Code:    403,
+		Message: "Voter has been deactivated",
+	})
+	return false
+}
+
+// checkReasonCode checks that code is one of the operation's reasons. It
+// writes the response and returns false if it is not.
+func checkReasonCode(c *gin.Context, reasons []string, code string) bool {
+	if slices.Contains(reasons, code) {
+		return true
+	}
+	c.JSON(http.StatusBadRequest, types.ErrorResponse{
+		Error:   "invalid_reason_code",
+		Code:    400,
+		Message: "reason_code must be one of " + strings.Join(reasons, ", "),
+	})
+	return false
+}
+
+// loadVoter returns the voter with a NIN, active or not. It writes the
+// response and returns nil if there is none.
+func loadVoter(c *gin.Context, services interfaces.Services, nin string) *database.Voter {
+	voter, err := services.VoterRepository().GetVoterByNIN(nin)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, types.ErrorResponse{
+			Error:   "voter_not_found",
+			Code:    404,
+			Message: "Voter not found or not registered",
+		})
+		return nil
+	}
+	if err != nil {
+		services.GetLogger().Error("Failed to load voter %s: %v", nin, err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "database_error",
+			Code:    500,
+			Message: "Failed to load voter",
+		})
+		return nil
+	}
+	return voter
+}
+
+// voterInfo is what the admin API shows of a voter; the fingerprint hash is
+// left out
+func voterInfo(voter *database.Voter) types.VoterInfo {
+	info := types.VoterInfo{
+		ID:                 voter.ID,
+		NIN:                voter.NIN,
+		FirstName:          voter.FirstName,
+		LastName:           voter.LastName,
+		DateOfBirth:        voter.DateOfBirth.Format("2006-01-02"),
+		Gender:             voter.Gender,
+		PollingUnitID:      voter.PollingUnitID,
+		IsActive:           voter.IsActive,
+		RegisteredAt:       voter.RegisteredAt.UTC().Format(time.RFC3339),
+		DeactivationReason: voter.DeactivationReason,
+	}
+	if voter.DeactivatedAt != nil {
+		info.DeactivatedAt = voter.DeactivatedAt.UTC().Format(time.RFC3339)
+	}
+	return info
+}
+
+// voterActionDetails describes an admin's action on a voter for the audit log
+func voterActionDetails(c *gin.Context, action, reasonCode, notes string) string {
+	details := fmt.Sprintf("%s by user %s (reason: %s)", action, c.GetString("user_id"), reasonCode)
+	if notes != "" {
+		details += ": " + notes
+	}
+	return details
+}
+
+// bindVoterAction binds an admin's request on a voter and loads the voter
+// named in the path. On failure it writes the response and returns nil.
+func bindVoterAction(c *gin.Context, services interfaces.Services, req interface{}) *database.Voter {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    400,
+			Message: "Invalid request format: " + err.Error(),
+		})
+		return nil
+	}
+	return loadVoter(c, services, c.Param("nin"))
+}
+
+// SearchVoters lists voters, filtered by nin, a name search term q,
+// polling_unit_id and status, active or deactivated (admin, operator)
+func SearchVoters(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := 50
+		offset := 0
+		if limitStr := c.Query("limit"); limitStr != "" {
+			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 1000 {
+				limit = l
+			}
+		}
+		if offsetStr := c.Query("offset"); offsetStr != "" {
+			if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+				offset = o
+			}
+		}
+
+		filter := repositories.VoterFilter{
+			NIN:           c.Query("nin"),
+			Name:          c.Query("q"),
+			PollingUnitID: c.Query("polling_unit_id"),
+		}
+		switch status := c.Query("status"); status {
+		case "":
+		case "active", "deactivated":
+			active := status == "active"
+			filter.Active = &active
+		default:
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "invalid_parameter",
+				Code:    400,
+				Message: "status must be active or deactivated",
+			})
+			return
+		}
+
+		voters, total, err := services.VoterRepository().SearchVoters(filter, limit, offset)
+		if err != nil {
+			services.GetLogger().Error("Failed to search voters: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to search voters",
+			})
+			return
+		}
+		infos := make([]types.VoterInfo, len(voters))
+		for i, voter := range voters {
+			infos[i] = voterInfo(voter)
+		}
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"voters": infos,
+				"limit":  limit,
+				"offset": offset,
+				"total":  total,
+			},
+		})
+	}
+}
+
+// GetVoter returns a voter by NIN, active or not (admin, operator)
+func GetVoter(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		voter := loadVoter(c, services, c.Param("nin"))
+		if voter == nil {
+			return
+		}
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Data:    voterInfo(voter),
+		})
+	}
+}
+
+// TransferVoter moves an active voter to another polling unit. Terminals of
+// the old unit drop the voter at their next roster sync. (admin, operator)
+func TransferVoter(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.VoterTransferRequest
+		voter := bindVoterAction(c, services, &req)
+		if voter == nil || !checkReasonCode(c, transferReasons, req.ReasonCode) {
+			return
+		}
+		if !voter.IsActive {
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "voter_deactivated",
+				Code:    409,
+				Message: "Deactivated voters cannot be transferred",
+			})
+			return
+		}
+		if voter.PollingUnitID == req.PollingUnitID {
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "same_polling_unit",
+				Code:    409,
+				Message: "Voter is already registered at this polling unit",
+			})
+			return
+		}
+
+		err := services.VoterRepository().TransferVoter(voter.ID, req.PollingUnitID)
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "voter_deactivated",
+				Code:    409,
+				Message: "Deactivated voters cannot be transferred",
+			})
+			return
+		}
+		if err != nil {
+			services.GetLogger().Error("Failed to transfer voter %s: %v", voter.NIN, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to transfer voter",
+			})
+			return
+		}
+
+		createAuditLog(services, "voter_transferred", voter.NIN, req.PollingUnitID,
+			voterActionDetails(c, fmt.Sprintf("Transferred from %s to %s", voter.PollingUnitID, req.PollingUnitID),
+				req.ReasonCode, req.Notes), getClientIP(c))
+		services.GetLogger().Info("Voter transferred - nin: %s, from: %s, to: %s, reason: %s",
+			voter.NIN, voter.PollingUnitID, req.PollingUnitID, req.ReasonCode)
+
+		voter.PollingUnitID = req.PollingUnitID
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Message: "Voter transferred",
+			Data:    voterInfo(voter),
+		})
+	}
+}
+
+// DeactivateVoter deactivates a voter: they can no longer vote or be
+// verified, and leave the terminal rosters. Their record, NIN and
+// fingerprint are kept, so they cannot register again. (admin, operator)
+func DeactivateVoter(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.VoterDeactivationRequest
+		voter := bindVoterAction(c, services, &req)
+		if voter == nil || !checkReasonCode(c, deactivationReasons, req.ReasonCode) {
+			return
+		}
+		if !voter.IsActive {
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "voter_deactivated",
+				Code:    409,
+				Message: "Voter has already been deactivated",
+			})
+			return
+		}
+
+		// A duplicate names the registration that is kept, which must be
+		// another active voter
+		notes := req.Notes
+		if req.ReasonCode == database.VoterDuplicate {
+			kept, err := services.VoterRepository().GetVoterByNIN(req.DuplicateOf)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				services.GetLogger().Error("Failed to load voter %s: %v", req.DuplicateOf, err)
+				c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+					Error:   "database_error",
+					Code:    500,
+					Message: "Failed to load voter",
+				})
+				return
+			}
+			if err != nil || kept.NIN == voter.NIN || !kept.IsActive {
+				c.JSON(http.StatusBadRequest, types.ErrorResponse{
+					Error:   "invalid_duplicate",
+					Code:    400,
+					Message: "duplicate_of must be the NIN of another active voter",
+				})
+				return
+			}
+			notes = "duplicate of " + kept.NIN
+			if req.Notes != "" {
+				notes += "; " + req.Notes
+			}
+		}
+
+		err := services.VoterRepository().DeactivateVoter(voter.ID, req.ReasonCode)
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "voter_deactivated",
+				Code:    409,
+				Message: "Voter has already been deactivated",
+			})
+			return
+		}
+		if err != nil {
+			services.GetLogger().Error("Failed to deactivate voter %s: %v", voter.NIN, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to deactivate voter",
+			})
+			return
+		}
+
+		createAuditLog(services, "voter_deactivated", voter.NIN, voter.PollingUnitID,
+			voterActionDetails(c, "Voter deactivated", req.ReasonCode, notes), getClientIP(c))
+		services.GetLogger().Info("Voter deactivated - nin: %s, reason: %s", voter.NIN, req.ReasonCode)
+
+		now := time.Now().UTC()
+		voter.IsActive, voter.DeactivationReason, voter.DeactivatedAt = false, req.ReasonCode, &now
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Message: "Voter deactivated",
+			Data:    voterInfo(voter),
+		})
+	}
+}
+
+// ReactivateVoter restores a deactivated voter. Snapshotted election rolls
+// stay as they were taken; the voter is on the rolls of elections
+// snapshotted from now on. (admin, operator)
+func ReactivateVoter(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.VoterReactivationRequest
+		voter := bindVoterAction(c, services, &req)
+		if voter == nil || !checkReasonCode(c, reactivationReasons, req.ReasonCode) {
+			return
+		}
+
+		err := services.VoterRepository().ReactivateVoter(voter.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "voter_active",
+				Code:    409,
+				Message: "Voter is active",
+			})
+			return
+		}
+		if err != nil {
+			services.GetLogger().Error("Failed to reactivate voter %s: %v", voter.NIN, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to reactivate voter",
+			})
+			return
+		}
+
+		createAuditLog(services, "voter_reactivated", voter.NIN, voter.PollingUnitID,
+			voterActionDetails(c, fmt.Sprintf("Voter reactivated, deactivated as %s", voter.DeactivationReason),
+				req.ReasonCode, req.Notes), getClientIP(c))
+		services.GetLogger().Info("Voter reactivated - nin: %s, reason: %s", voter.NIN, req.ReasonCode)
+
+		voter.IsActive, voter.DeactivationReason, voter.DeactivatedAt = true, "", nil
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Message: "Voter reactivated",
+			Data:    voterInfo(voter),
+		})
+	}
+}
+
+// ReenrolVoterFingerprint replaces an active voter's enrolled fingerprint,
+// e.g. after an injury. The new capture must not match another voter. The
+// voter's biometric lockout is cleared. (admin, operator)
+func ReenrolVoterFingerprint(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.VoterReenrolmentRequest
+		voter := bindVoterAction(c, services, &req)
+		if voter == nil || !checkReasonCode(c, reenrolmentReasons, req.ReasonCode) {
+			return
+		}
+		if !voter.IsActive {
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "voter_deactivated",
+				Code:    409,
+				Message: "Deactivated voters cannot be re-enrolled",
+			})
+			return
+		}
+		clientIP := getClientIP(c)
+
+		matcher, ok := biometrics(c, services)
+		if !ok {
+			return
+		}
+		fingerprintHash := matcher.Hash(req.FingerprintData)
+		for _, hash := range []string{fingerprintHash, biometric.Hash(nil, req.FingerprintData)} {
+			existing, err := services.VoterRepository().GetVoterByFingerprint(hash)
+			if err == nil && existing.NIN != voter.NIN {
+				services.GetLogger().Warning("Duplicate fingerprint re-enrolment attempt - nin: %s", voter.NIN)
+				c.JSON(http.StatusConflict, types.ErrorResponse{
+					Error:   "fingerprint_exists",
+					Code:    409,
+					Message: "Fingerprint is already registered",
+				})
+				return
+			}
+		}
+
+		template := enrolFingerprint(c, services, voter.NIN, voter.PollingUnitID, req.FingerprintData, clientIP)
+		if template == nil {
+			return
+		}
+		sealed, err := sealTemplate(services, template)
+		if err == nil {
+			err = services.VoterRepository().UpdateFingerprint(voter.NIN, fingerprintHash, sealed)
+		}
+		if err != nil {
+			services.GetLogger().Error("Failed to re-enrol voter %s: %v", voter.NIN, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to re-enrol fingerprint",
+			})
+			return
+		}
+		if err := services.BiometricAttemptRepository().Reset(voter.NIN); err != nil {
+			services.GetLogger().Error("Failed to reset biometric attempts of %s: %v", voter.NIN, err)
+		}
+
+		createAuditLog(services, "voter_fingerprint_reenrolled", voter.NIN, voter.PollingUnitID,
+			voterActionDetails(c, fmt.Sprintf("Fingerprint re-enrolled (%s, quality %.2f)", template.Format, template.Quality),
+				req.ReasonCode, req.Notes), clientIP)
+		services.GetLogger().Info("Voter fingerprint re-enrolled - nin: %s, reason: %s", voter.NIN, req.ReasonCode)
+
+		c.JSON(http.StatusOK, types.SuccessResponse{
+			Success: true,
+			Message: "Fingerprint re-enrolled",
+			Data: map[string]interface{}{
+				"nin":        voter.NIN,
+				"format":     template.Format,
+				"quality":    template.Quality,
+				"updated_at": time.Now().Unix(),
+			},
+		})
+	}
+}
@@ -1,8 +1,8 @@
 package handlers
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
+	"database/sql"
+	"errors"
 	"fmt"
 	"math/big"
 	"net/http"
@@ -10,9 +10,12 @@ import (
 
 	"voting-system/internal/api/interfaces"
 	"voting-system/internal/api/types"
+	"voting-system/internal/biometric"
 	"voting-system/internal/blockchain"
 	"voting-system/internal/database"
+	"voting-system/internal/votesig"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/gin-gonic/gin"
 )
 
@@ -64,6 +67,11 @@ func RegisterVoter(services interfaces.Services) gin.HandlerFunc {
 		services.GetLogger().Info("Voter registration attempt - nin: %s, polling_unit: %s, ip: %s",
 			req.NIN, req.PollingUnitID, clientIP)
 
+		// Terminals register voters at their own polling unit only
+		if _, ok := terminalPollingUnit(c, services, req.PollingUnitID, "voter registration"); !ok {
+			return
+		}
+
 		// Check if voter already exists
 		existingVoter, err := services.VoterRepository().GetVoterByNIN(req.NIN)
 		if err == nil && existingVoter != nil {
@@ -76,19 +84,32 @@ func RegisterVoter(services interfaces.Services) gin.HandlerFunc {
 			return
 		}
 
-		// Create fingerprint hash
-		fingerprintHash := sha256.Sum256([]byte(req.FingerprintData))
-		fingerprintHashStr := hex.EncodeToString(fingerprintHash[:])
+		// Create the keyed fingerprint hash
+		matcher, ok := biometrics(c, services)
+		if !ok {
+			return
+		}
+		fingerprintHashStr := matcher.Hash(req.FingerprintData)
+
+		// Check if fingerprint is already registered, under its keyed hash or
+		// the unkeyed one voters were registered with before the vault
+		for _, hash := range []string{fingerprintHashStr, biometric.Hash(nil, req.FingerprintData)} {
+			existingByFingerprint, err := services.VoterRepository().GetVoterByFingerprint(hash)
+			if err == nil && existingByFingerprint != nil {
+				services.GetLogger().Warning("Duplicate fingerprint registration attempt - nin: %s", req.NIN)
+				c.JSON(http.StatusConflict, types.ErrorResponse{
+					Error:   "fingerprint_exists",
+					Code:    409,
+					Message: "Fingerprint is already registered",
+				})
+				return
+			}
+		}
 
-		// Check if fingerprint is already registered
-		existingByFingerprint, err := services.VoterRepository().GetVoterByFingerprint(fingerprintHashStr)
-		if err == nil && existingByFingerprint != nil {
-			services.GetLogger().Warning("Duplicate fingerprint registration attempt - nin: %s", req.NIN)
-			c.JSON(http.StatusConflict, types.ErrorResponse{
-				Error:   "fingerprint_exists",
-				Code:    409,
-				Message: "Fingerprint is already registered",
-			})
+		// Enrol the fingerprint's template and search for the same finger
+		// among the voters already registered
+		template := enrolFingerprint(c, services, req.NIN, req.PollingUnitID, req.FingerprintData, clientIP)
+		if template == nil {
 			return
 		}
 
@@ -105,8 +126,11 @@ func RegisterVoter(services interfaces.Services) gin.HandlerFunc {
 			IsActive:        true,
 		}
 
-		// Register voter in database
-		err = services.VoterRepository().RegisterVoter(voter)
+		// Register voter in database, with their template sealed
+		sealed, err := sealTemplate(services, template)
+		if err == nil {
+			err = services.VoterRepository().RegisterVoter(voter, sealed)
+		}
 		if err != nil {
 			services.GetLogger().Error("Failed to register voter: %v", err)
 			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
@@ -151,6 +175,66 @@ func CastVote(services interfaces.Services) gin.HandlerFunc {
 		services.GetLogger().Info("Vote submission attempt - polling_unit: %s, candidate: %s, ip: %s",
 			req.PollingUnitID, req.CandidateID, clientIP)
 
+		// Create verification hash from the NIN and the election's salt
+		election, verificationHash, err := voteVerificationHash(services, req.NIN)
+		var electionID *big.Int
+		if err == nil {
+			electionID, err = chainElectionID(election)
+		}
+		if errors.Is(err, errNoActiveElection) {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "no_active_election",
+				Code:    400,
+				Message: "No active election found",
+			})
+			return
+		}
+		if err != nil {
+			services.GetLogger().Error("Failed to derive verification hash: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to process vote",
+			})
+			return
+		}
+
+		// The terminal signs the election a vote is for; a vote taken for an
+		// election that has since ended is not moved to the next one
+		if req.ElectionID != election.BlockchainID {
+			message := "Vote is for election " + req.ElectionID + ", which is not the active election"
+			if req.ElectionID == "" {
+				message = "Vote does not name the election it is for"
+			}
+			createAuditLog(services, "vote_rejected_election_not_active", c.GetString("user_id"), req.PollingUnitID,
+				fmt.Sprintf("Vote for election %q while election %s is active", req.ElectionID, election.BlockchainID), clientIP)
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "election_not_active",
+				Code:    400,
+				Message: message,
+			})
+			return
+		}
+
+		// Only votes signed by an authorized terminal are accepted
+		vote, ok := checkVoteSignature(c, services, &req, verificationHash, clientIP)
+		if !ok {
+			return
+		}
+		// A vote that failed on the server's side may be resubmitted with the same nonce
+		defer func() {
+			if c.Writer.Status() >= http.StatusInternalServerError {
+				if err := services.VoteNonceRepository().Release(vote.DeviceID, vote.Nonce); err != nil {
+					services.GetLogger().Error("Failed to release vote nonce: %v", err)
+				}
+			}
+		}()
+
+		// Terminals take votes for their own polling unit only
+		if _, ok := terminalPollingUnit(c, services, req.PollingUnitID, "vote"); !ok {
+			return
+		}
+
 		// Verify voter exists in database
 		voter, err := services.VoterRepository().GetVoterByNIN(req.NIN)
 		if err != nil {
@@ -163,18 +247,18 @@ func CastVote(services interfaces.Services) gin.HandlerFunc {
 			return
 		}
 
+		// Deactivated voters stay on rolls snapshotted before they were deactivated
+		if !checkVoterActive(c, services, voter, "vote", req.PollingUnitID, clientIP) {
+			return
+		}
+
+		// Only voters on the election's roll may vote in it
+		if !checkEligibility(c, services, election, voter, verificationHash, req.PollingUnitID, clientIP) {
+			return
+		}
+
 		// Verify fingerprint
-		fingerprintHash := sha256.Sum256([]byte(req.FingerprintData))
-		fingerprintHashStr := hex.EncodeToString(fingerprintHash[:])
-		if fingerprintHashStr != voter.FingerprintHash {
-			services.GetLogger().Warning("Invalid fingerprint - nin: %s", req.NIN)
-			createAuditLog(services, "vote_rejected_invalid_fingerprint", req.NIN, req.PollingUnitID,
-				"Invalid fingerprint provided", clientIP)
-			c.JSON(http.StatusUnauthorized, types.ErrorResponse{
-				Error:   "invalid_fingerprint",
-				Code:    401,
-				Message: "Invalid fingerprint",
-			})
+		if !verifyFingerprint(c, services, voter, req.FingerprintData, "vote", req.PollingUnitID, clientIP) {
 			return
 		}
 
@@ -192,31 +276,37 @@ func CastVote(services interfaces.Services) gin.HandlerFunc {
 			return
 		}
 
-		// Create verification hash from NIN + Fingerprint
-		verificationData := req.NIN + req.FingerprintData
-		hash := sha256.Sum256([]byte(verificationData))
-		verificationHash := hex.EncodeToString(hash[:])
-
-		// Check if voter has already voted
-		hasVoted, err := services.GetBlockchainClient().HasVoterVoted(verificationHash)
+		// Check if voter has already voted in this election
+		hasVoted, err := services.GetBlockchainClient().HasVoterVoted(electionID, verificationHash)
 		if err != nil {
 			services.GetLogger().Error("Error checking voter status: %v", err)
 
 			// If blockchain is unavailable, add to sync queue
 			if !services.GetConnManager().IsConnected() {
-				voteData := blockchain.VoteData{
-					VerificationHash: verificationHash,
-					EncryptedVote:    req.EncryptedVote,
-					PollingUnitID:    req.PollingUnitID,
-					CandidateID:      req.CandidateID,
+				voteData := vote.voteData(&req)
+
+				// Check the ballot mode against the election cached in the database
+				ballot, ok := checkBallot(c, services, &req, election.BlockchainID, verificationHash, clientIP)
+				if !ok {
+					return
 				}
+				if ballot != "" {
+					voteData.Ballot = ballot
 
-				services.GetSyncManager().AddPendingVote(voteData)
-				queuePosition := services.GetSyncManager().GetPendingVoteCount()
+					// The tally reads ballots from the votes table
+					if !storeVote(c, services, &req, verificationHash, electionID.Int64(), ballot) {
+						return
+					}
+				}
+
+				queuePosition, ok := queuePendingVote(c, services, voteData)
+				if !ok {
+					return
+				}
 
 				// Create audit log
 				createAuditLog(services, "vote_queued", verificationHash, req.PollingUnitID,
-					fmt.Sprintf("Vote queued for candidate %s", req.CandidateID), clientIP)
+					"Vote queued for "+voteChoice(&req), clientIP)
 
 				c.JSON(http.StatusAccepted, types.VoteResponse{
 					Success:       true,
@@ -268,6 +358,19 @@ func CastVote(services interfaces.Services) gin.HandlerFunc {
 			return
 		}
 
+		// The verification hash and roll are the cached election's; a vote
+		// taken while the cache lags the chain is retried once it catches up
+		if currentElectionID.Cmp(electionID) != 0 {
+			services.GetLogger().Error("Active election on chain %s differs from the cached election %s",
+				currentElectionID.String(), election.BlockchainID)
+			c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
+				Error:   "election_out_of_sync",
+				Code:    503,
+				Message: "The active election is changing; try again",
+			})
+			return
+		}
+
 		// Get election details to validate candidate
 		electionData, err := services.GetBlockchainClient().GetElectionDetails(currentElectionID)
 		if err != nil {
@@ -280,8 +383,14 @@ func CastVote(services interfaces.Services) gin.HandlerFunc {
 			return
 		}
 
+		// Encrypted ballots carry their own proof of validity
+		ballot, ok := checkBallot(c, services, &req, currentElectionID.String(), verificationHash, clientIP)
+		if !ok {
+			return
+		}
+
 		// Validate candidate ID
-		validCandidate := false
+		validCandidate := ballot != ""
 		for _, candidate := range electionData.Candidates {
 			if candidate == req.CandidateID {
 				validCandidate = true
@@ -303,11 +412,9 @@ func CastVote(services interfaces.Services) gin.HandlerFunc {
 		}
 
 		// Prepare vote data
-		voteData := blockchain.VoteData{
-			VerificationHash: verificationHash,
-			EncryptedVote:    req.EncryptedVote,
-			PollingUnitID:    req.PollingUnitID,
-			CandidateID:      req.CandidateID,
+		voteData := vote.voteData(&req)
+		if ballot != "" {
+			voteData.Ballot = ballot
 		}
 
 		// // Get current election from database
@@ -323,24 +430,7 @@ func CastVote(services interfaces.Services) gin.HandlerFunc {
 		// }
 
 		// Store vote in database
-		dbVote := &database.Vote{
-			VerificationHash: verificationHash,
-			ElectionID:       currentElectionID.Int64(),
-			PollingUnitID:    req.PollingUnitID,
-			CandidateID:      req.CandidateID,
-			EncryptedVote:    req.EncryptedVote,
-			Status:           "pending",
-			CreatedAt:        time.Now(),
-		}
-
-		err = services.VoteRepository().InsertVote(dbVote)
-		if err != nil {
-			services.GetLogger().Error("Failed to store vote in database: %v", err)
-			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
-				Error:   "database_error",
-				Code:    500,
-				Message: "Failed to store vote",
-			})
+		if !storeVote(c, services, &req, verificationHash, currentElectionID.Int64(), ballot) {
 			return
 		}
 
@@ -351,8 +441,10 @@ func CastVote(services interfaces.Services) gin.HandlerFunc {
 				services.GetLogger().Error("Error casting vote: %v", err)
 
 				// Add to sync queue as fallback
-				services.GetSyncManager().AddPendingVote(voteData)
-				queuePosition := services.GetSyncManager().GetPendingVoteCount()
+				queuePosition, ok := queuePendingVote(c, services, voteData)
+				if !ok {
+					return
+				}
 
 				createAuditLog(services, "vote_queued_error", verificationHash, req.PollingUnitID,
 					fmt.Sprintf("Vote queued due to error: %s", err.Error()), clientIP)
@@ -371,8 +463,10 @@ func CastVote(services interfaces.Services) gin.HandlerFunc {
 				services.GetLogger().Error("Transaction failed: %v", err)
 
 				// Add to sync queue for retry
-				services.GetSyncManager().AddPendingVote(voteData)
-				queuePosition := services.GetSyncManager().GetPendingVoteCount()
+				queuePosition, ok := queuePendingVote(c, services, voteData)
+				if !ok {
+					return
+				}
 
 				c.JSON(http.StatusAccepted, types.VoteResponse{
 					Success:       true,
@@ -393,7 +487,7 @@ func CastVote(services interfaces.Services) gin.HandlerFunc {
 				receipt.TxHash.Hex(), receipt.GasUsed, req.PollingUnitID)
 
 			createAuditLog(services, "vote_cast_success", verificationHash, req.PollingUnitID,
-				fmt.Sprintf("Vote cast for candidate %s, TX: %s", req.CandidateID, receipt.TxHash.Hex()), clientIP)
+				fmt.Sprintf("Vote cast for %s, TX: %s", voteChoice(&req), receipt.TxHash.Hex()), clientIP)
 
 			c.JSON(http.StatusOK, types.VoteResponse{
 				Success:         true,
@@ -402,11 +496,13 @@ func CastVote(services interfaces.Services) gin.HandlerFunc {
 			})
 		} else {
 			// Blockchain offline - add to sync queue
-			services.GetSyncManager().AddPendingVote(voteData)
-			queuePosition := services.GetSyncManager().GetPendingVoteCount()
+			queuePosition, ok := queuePendingVote(c, services, voteData)
+			if !ok {
+				return
+			}
 
 			createAuditLog(services, "vote_queued_offline", verificationHash, req.PollingUnitID,
-				fmt.Sprintf("Vote queued (blockchain offline) for candidate %s", req.CandidateID), clientIP)
+				"Vote queued (blockchain offline) for "+voteChoice(&req), clientIP)
 
 			c.JSON(http.StatusAccepted, types.VoteResponse{
 				Success:       true,
@@ -417,6 +513,184 @@ func CastVote(services interfaces.Services) gin.HandlerFunc {
 	}
 }
 
+// checkVoteSignature recovers the signer of a vote and checks it is the
+// submitting terminal's registered address, that the address is authorized
+// on chain, and that the vote is not a replay. The chain is asked while it is
+// reachable; otherwise the authorization cached in the terminals table is used.
+// On failure it writes an error response and returns false.
+func checkVoteSignature(c *gin.Context, services interfaces.Services, req *types.VoteRequest, verificationHash, clientIP string) (*signedVote, bool) {
+	deviceID := c.GetString("user_id")
+	reject := func(status int, code, message string) (*signedVote, bool) {
+		services.GetLogger().Warning("Vote signature rejected - terminal: %s, reason: %s", deviceID, message)
+		createAuditLog(services, "vote_rejected_signature", deviceID, req.PollingUnitID,
+			fmt.Sprintf("%s (verification hash %s)", message, verificationHash), clientIP)
+		c.JSON(status, types.ErrorResponse{Error: code, Code: status, Message: message})
+		return nil, false
+	}
+
+	vote, err := votesig.FromRequest(deviceID, verificationHash, req)
+	if err != nil {
+		return reject(http.StatusBadRequest, "invalid_request", err.Error())
+	}
+	signer, err := votesig.Recover(voteDomain(services), vote, req.Signature)
+	if errors.Is(err, votesig.ErrUnsigned) {
+		return reject(http.StatusUnauthorized, "signature_required", "Vote must carry the terminal's signature, nonce and timestamp")
+	}
+	if err != nil {
+		return reject(http.StatusUnauthorized, "invalid_signature", "Vote signature is invalid")
+	}
+
+	maxAge := services.GetConfig().Security.VoteSignatureMaxAge
+	if maxAge <= 0 {
+		maxAge = 24 * time.Hour
+	}
+	now := time.Now().UTC()
+	if vote.SignedAt().After(now.Add(voteClockSkew)) || vote.SignedAt().Before(now.Add(-maxAge)) {
+		return reject(http.StatusUnauthorized, "stale_signature", "Vote timestamp is outside the accepted window")
+	}
+
+	terminal, err := services.TerminalRepository().GetTerminal(deviceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return reject(http.StatusForbidden, "unknown_terminal", "Terminal is not registered")
+	}
+	if err != nil {
+		services.GetLogger().Error("Failed to load terminal %s: %v", deviceID, err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "database_error",
+			Code:    500,
+			Message: "Failed to load terminal",
+		})
+		return nil, false
+	}
+	if !common.IsHexAddress(terminal.EthAddress) || common.HexToAddress(terminal.EthAddress) != signer {
+		return reject(http.StatusForbidden, "signer_mismatch",
+			fmt.Sprintf("Vote was signed by %s, not the terminal's registered address", signer.Hex()))
+	}
+
+	// Only active terminals vote. Revocations recorded by the server hold even
+	// if the chain was not updated.
+	if terminal.Status != database.TerminalActive {
+		return reject(http.StatusForbidden, "terminal_not_authorized", "Terminal is "+terminal.Status)
+	}
+
+	authorized := terminal.Authorized
+	if services.GetConnManager().IsConnected() {
+		onChain, err := services.GetBlockchainClient().IsTerminalAuthorized(signer)
+		if err != nil {
+			services.GetLogger().Warning("Falling back to cached authorization for terminal %s: %v", deviceID, err)
+		} else {
+			authorized = onChain
+		}
+	}
+	if !authorized {
+		return reject(http.StatusForbidden, "terminal_not_authorized", "Terminal is not authorized to submit votes")
+	}
+
+	fresh, err := services.VoteNonceRepository().Use(deviceID, vote.Nonce, vote.SignedAt(), now.Add(-maxAge))
+	if err != nil {
+		services.GetLogger().Error("Failed to record vote nonce: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "database_error",
+			Code:    500,
+			Message: "Failed to record vote nonce",
+		})
+		return nil, false
+	}
+	if !fresh {
+		return reject(http.StatusUnauthorized, "replayed_vote", "Vote nonce has already been used")
+	}
+
+	return &signedVote{Vote: vote, Signer: signer, Signature: req.Signature}, true
+}
+
+// voteDomain returns the EIP-712 domain terminals sign votes for: the
+// configured chain and contract
+func voteDomain(services interfaces.Services) votesig.Domain {
+	cfg := services.GetConfig().Blockchain
+	return votesig.NewDomain(cfg.ChainID, cfg.ContractAddress)
+}
+
+// signedVote is a vote whose terminal signature has been checked
+type signedVote struct {
+	*votesig.Vote
+	Signer    common.Address
+	Signature string
+}
+
+// voteData returns the vote to cast on chain. It carries the terminal's
+// signature so the contract records the terminal as the vote's origin, and
+// the election the terminal signed it for.
+func (v *signedVote) voteData(req *types.VoteRequest) blockchain.VoteData {
+	return blockchain.VoteData{
+		VerificationHash: v.VerificationHash,
+		EncryptedVote:    req.EncryptedVote,
+		PollingUnitID:    req.PollingUnitID,
+		CandidateID:      req.CandidateID,
+		ElectionID:       v.ElectionID,
+		Terminal:         v.Signer.Hex(),
+		Nonce:            v.Nonce,
+		SignedAt:         v.Timestamp,
+		Signature:        v.Signature,
+	}
+}
+
+// voteClockSkew is how far ahead of the server's clock a terminal's vote timestamp may be
+const voteClockSkew = 5 * time.Minute
+
+// storeVote records a pending vote in the database. ballot is the encoded
+// encrypted ballot, or "" for a plain candidate vote.
+// On failure it writes an error response and returns false.
+func storeVote(c *gin.Context, services interfaces.Services, req *types.VoteRequest, verificationHash string, electionID int64, ballot string) bool {
+	dbVote := &database.Vote{
+		VerificationHash: verificationHash,
+		ElectionID:       electionID,
+		PollingUnitID:    req.PollingUnitID,
+		CandidateID:      req.CandidateID,
+		EncryptedVote:    req.EncryptedVote,
+		TerminalID:       c.GetString("user_id"),
+		Status:           "pending",
+		CreatedAt:        time.Now(),
+	}
+	if ballot != "" {
+		dbVote.Ballot = &ballot
+	}
+
+	if err := services.VoteRepository().InsertVote(dbVote); err != nil {
+		services.GetLogger().Error("Failed to store vote in database: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "database_error",
+			Code:    500,
+			Message: "Failed to store vote",
+		})
+		return false
+	}
+	return true
+}
+
+// voteChoice describes a vote for audit logs without revealing an encrypted choice
+func voteChoice(req *types.VoteRequest) string {
+	if req.Ballot != nil {
+		return "encrypted ballot"
+	}
+	return "candidate " + req.CandidateID
+}
+
+// queuePendingVote adds a vote to the sync queue and returns its queue position.
+// On failure it writes an error response and returns false.
+func queuePendingVote(c *gin.Context, services interfaces.Services, voteData blockchain.VoteData) (int, bool) {
+	if err := services.GetSyncManager().AddPendingVote(voteData); err != nil {
+		services.GetLogger().Error("Failed to queue vote: %v", err)
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "queue_error",
+			Code:    500,
+			Message: "Failed to queue vote for blockchain sync",
+		})
+		return 0, false
+	}
+
+	return services.GetSyncManager().GetPendingVoteCount(), true
+}
+
 // GetVoterStatus checks if a voter has already voted
 func GetVoterStatus(services interfaces.Services) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -430,8 +704,13 @@ func GetVoterStatus(services interfaces.Services) gin.HandlerFunc {
 			return
 		}
 
+		electionID := statusElection(c, services)
+		if electionID == nil {
+			return
+		}
+
 		// Check voter status on blockchain
-		hasVoted, err := services.GetBlockchainClient().HasVoterVoted(voterHash)
+		hasVoted, err := services.GetBlockchainClient().HasVoterVoted(electionID, voterHash)
 		if err != nil {
 			services.GetLogger().Error("Error checking voter status: %v", err)
 			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
@@ -443,7 +722,8 @@ func GetVoterStatus(services interfaces.Services) gin.HandlerFunc {
 		}
 
 		status := types.VoterStatus{
-			HasVoted: hasVoted,
+			ElectionID: electionID.String(),
+			HasVoted:   hasVoted,
 		}
 
 		// If voter has voted, try to get additional details
@@ -460,29 +740,73 @@ func GetVoterStatus(services interfaces.Services) gin.HandlerFunc {
 	}
 }
 
-// VerifyVoter handles voter verification requests
+// VerifyVoter checks a voter before their ballot is taken: they are active
+// and on the active election's roll, their fingerprint matches and whether
+// they may vote at this polling unit now (Terminal only)
 func VerifyVoter(services interfaces.Services) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		voterHash := c.Param("voter_hash")
-		if voterHash == "" {
+		var req types.VoterVerificationRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, types.ErrorResponse{
-				Error:   "missing_parameter",
+				Error:   "invalid_request",
 				Code:    400,
-				Message: "Voter hash is required",
+				Message: "Invalid request format: " + err.Error(),
 			})
 			return
 		}
 
 		// Log verification attempt
 		clientIP := getClientIP(c)
-		createAuditLog(services, "voter_verification", voterHash, "",
+		createAuditLog(services, "voter_verification", req.NIN, req.PollingUnitID,
 			"Voter verification request", clientIP)
 
-		// Check if voter exists in system (implement based on your voter database)
-		// This would involve checking against your voter registration database
+		election, verificationHash, err := voteVerificationHash(services, req.NIN)
+		var electionID *big.Int
+		if err == nil {
+			electionID, err = chainElectionID(election)
+		}
+		if errors.Is(err, errNoActiveElection) {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "no_active_election",
+				Code:    400,
+				Message: "No active election found",
+			})
+			return
+		}
+		if err != nil {
+			services.GetLogger().Error("Failed to derive verification hash: %v", err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to verify voter",
+			})
+			return
+		}
+
+		if _, ok := terminalPollingUnit(c, services, req.PollingUnitID, "voter verification"); !ok {
+			return
+		}
 
-		// For now, we'll check blockchain status
-		hasVoted, err := services.GetBlockchainClient().HasVoterVoted(voterHash)
+		voter, err := services.VoterRepository().GetVoterByNIN(req.NIN)
+		if err != nil {
+			c.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error:   "voter_not_found",
+				Code:    404,
+				Message: "Voter not found or not registered",
+			})
+			return
+		}
+		if !checkVoterActive(c, services, voter, "verification", req.PollingUnitID, clientIP) {
+			return
+		}
+		if !checkEligibility(c, services, election, voter, verificationHash, req.PollingUnitID, clientIP) {
+			return
+		}
+		if !verifyFingerprint(c, services, voter, req.FingerprintData, "verification", req.PollingUnitID, clientIP) {
+			return
+		}
+
+		hasVoted, err := services.GetBlockchainClient().HasVoterVoted(electionID, verificationHash)
 		if err != nil {
 			services.GetLogger().Error("Error verifying voter: %v", err)
 			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
@@ -494,10 +818,12 @@ func VerifyVoter(services interfaces.Services) gin.HandlerFunc {
 		}
 
 		verification := map[string]interface{}{
-			"voter_hash":  voterHash,
-			"is_eligible": true, // This should be checked against voter registration DB
-			"has_voted":   hasVoted,
-			"verified_at": time.Now().Unix(),
+			"voter_hash":      verificationHash,
+			"election_id":     electionID.String(),
+			"polling_unit_id": voter.PollingUnitID,
+			"is_eligible":     voter.PollingUnitID == req.PollingUnitID && !hasVoted,
+			"has_voted":       hasVoted,
+			"verified_at":     time.Now().Unix(),
 		}
 
 		c.JSON(http.StatusOK, types.SuccessResponse{
@@ -507,3 +833,77 @@ func VerifyVoter(services interfaces.Services) gin.HandlerFunc {
 		})
 	}
 }
+
+// GetPollingUnitRoster returns the active voters of a polling unit for a
+// terminal's local voter store (Terminal only). Terminals only get the roster
+// of the polling unit they are enrolled at.
+func GetPollingUnitRoster(services interfaces.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		pollingUnitID, ok := terminalPollingUnit(c, services, c.Query("polling_unit_id"), "roster download")
+		if !ok {
+			return
+		}
+		if pollingUnitID == "" {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "missing_parameter",
+				Code:    400,
+				Message: "polling_unit_id is required",
+			})
+			return
+		}
+
+		voters, err := services.VoterRepository().GetVotersByPollingUnit(pollingUnitID)
+		if err != nil {
+			services.GetLogger().Error("Failed to load voters for polling unit %s: %v", pollingUnitID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to load voters",
+			})
+			return
+		}
+
+		// Terminals match fingerprints offline against the enrolled templates
+		stored, err := services.VoterTemplateRepository().ListByPollingUnit(pollingUnitID)
+		if err != nil {
+			services.GetLogger().Error("Failed to load templates for polling unit %s: %v", pollingUnitID, err)
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   "database_error",
+				Code:    500,
+				Message: "Failed to load voters",
+			})
+			return
+		}
+		templates := make(map[string]*biometric.Template, len(stored))
+		for i := range stored {
+			opened, err := openTemplate(services, &stored[i])
+			if err != nil {
+				services.GetLogger().Error("Failed to open fingerprint template: %v", err)
+				c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+					Error:   "biometric_error",
+					Code:    500,
+					Message: "Failed to load voters",
+				})
+				return
+			}
+			templates[stored[i].NIN] = opened
+		}
+
+		roster := make([]types.RosterVoter, 0, len(voters))
+		for _, voter := range voters {
+			roster = append(roster, types.RosterVoter{
+				NIN:             voter.NIN,
+				FirstName:       voter.FirstName,
+				LastName:        voter.LastName,
+				PollingUnitID:   voter.PollingUnitID,
+				FingerprintHash: voter.FingerprintHash,
+				Template:        templates[voter.NIN],
+			})
+		}
+
+		createAuditLog(services, "roster_downloaded", c.GetString("user_id"), pollingUnitID,
+			fmt.Sprintf("Terminal downloaded %d voters", len(roster)), getClientIP(c))
+
+		c.JSON(http.StatusOK, types.SuccessResponse{Success: true, Data: roster})
+	}
+}
@@ -2,11 +2,12 @@ package interfaces
 
 // Claims represents JWT token claims
 type Claims struct {
-	UserID      string   `json:"user_id"`
-	Role        string   `json:"role"`
-	Permissions []string `json:"permissions"`
-	SessionID   string   `json:"session_id"`
-	ExpiresAt   int64    `json:"expires_at"`
+	UserID        string   `json:"user_id"`
+	Role          string   `json:"role"`
+	Permissions   []string `json:"permissions"`
+	SessionID     string   `json:"session_id"`
+	PollingUnitID string   `json:"polling_unit_id"` // set on terminal tokens
+	ExpiresAt     int64    `json:"expires_at"`
 }
 
 type AuthServiceInterface interface {
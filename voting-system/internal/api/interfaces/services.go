@@ -3,12 +3,14 @@ package interfaces
 import (
 	"voting-system/internal/blockchain"
 	"voting-system/internal/database/repositories"
+	"voting-system/pkg/config"
 	"voting-system/pkg/logger"
 )
 
 // Services defines the interface for API services
 type Services interface {
 	GetLogger() *logger.Logger
+	GetConfig() *config.Config
 	GetBlockchainClient() *blockchain.BlockchainClient
 	GetSyncManager() *blockchain.SyncManager
 	GetConnManager() *blockchain.ConnectionManager
@@ -20,4 +22,22 @@ type Services interface {
 	TerminalRepository() *repositories.TerminalRepository
 	UserRepository() *repositories.UserRepository
 	CandidateRepository() *repositories.CandidateRepository
+	SessionRepository() *repositories.SessionRepository
+	LoginAttemptRepository() *repositories.LoginAttemptRepository
+	PasswordResetRepository() *repositories.PasswordResetRepository
+	TwoFactorRepository() *repositories.TwoFactorRepository
+	ElectionKeyRepository() *repositories.ElectionKeyRepository
+	CeremonyRepository() *repositories.CeremonyRepository
+	TerminalJournalRepository() *repositories.TerminalJournalRepository
+	VoteNonceRepository() *repositories.VoteNonceRepository
+	TerminalAlertRepository() *repositories.TerminalAlertRepository
+	TerminalConfigRepository() *repositories.TerminalConfigRepository
+	TerminalEnrolmentRepository() *repositories.TerminalEnrolmentRepository
+	TerminalSecretRepository() *repositories.TerminalSecretRepository
+	VoterTemplateRepository() *repositories.VoterTemplateRepository
+	BiometricAttemptRepository() *repositories.BiometricAttemptRepository
+	ElectionSaltRepository() *repositories.ElectionSaltRepository
+	ElectionVoterRepository() *repositories.ElectionVoterRepository
+	ElectionRollRepository() *repositories.ElectionRollRepository
+	VoterImportRepository() *repositories.VoterImportRepository
 }
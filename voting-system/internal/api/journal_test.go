@@ -0,0 +1,219 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"voting-system/internal/api/models"
+	"voting-system/internal/api/types"
+	"voting-system/internal/auth"
+	"voting-system/internal/database"
+	"voting-system/internal/journal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chainEntry builds the journal entry a terminal would upload for a vote,
+// signing the vote with key when one is given
+func chainEntry(t *testing.T, seq int64, prevHash, secret string, key *ecdsa.PrivateKey, vote types.VoteRequest) types.JournalUploadEntry {
+	t.Helper()
+
+	if key != nil {
+		signVote(t, key, "TERM-001", &vote)
+	}
+	payload, err := json.Marshal(vote)
+	require.NoError(t, err)
+
+	link := journal.Entry{
+		DeviceID:         "TERM-001",
+		Seq:              seq,
+		PrevHash:         prevHash,
+		VerificationHash: voteHash(vote.NIN),
+		PayloadHash:      journal.PayloadHash(payload),
+		CreatedAt:        journal.Timestamp(time.Now()),
+	}
+	return types.JournalUploadEntry{
+		Seq:              seq,
+		PrevHash:         prevHash,
+		EntryHash:        link.Hash(),
+		Signature:        journal.Sign(secret, link.Hash()),
+		VerificationHash: link.VerificationHash,
+		CreatedAt:        link.CreatedAt,
+		Vote:             payload,
+	}
+}
+
+func uploadJournal(t *testing.T, env *testEnv, entries ...types.JournalUploadEntry) []types.JournalEntryResult {
+	t.Helper()
+
+	w := env.doJSON("POST", "/api/v1/terminal/journal", env.tokenFor(t, models.RoleTerminal),
+		types.JournalUpload{Entries: entries})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp types.JournalUploadResponse
+	decodeData(t, w.Body.Bytes(), &resp)
+	require.Len(t, resp.Results, len(entries))
+	return resp.Results
+}
+
+func TestJournalUploadIsIdempotent(t *testing.T) {
+	env := newTestEnv(t)
+	env.startElection(t, "1")
+	key := env.registerTerminal(t, "TERM-001", true)
+	secret := rotateTerminalSecret(t, env, "TERM-001")
+
+	// Votes the server refuses still become links of the chain
+	vote := func(nin string) types.VoteRequest {
+		return types.VoteRequest{NIN: nin, FingerprintData: "fp-" + nin, CandidateID: "APC", PollingUnitID: "PU-1"}
+	}
+	first := chainEntry(t, 1, journal.GenesisHash, secret, key, vote("11111111111"))
+	second := chainEntry(t, 2, first.EntryHash, secret, key, vote("22222222222"))
+
+	results := uploadJournal(t, env, second, first)
+	for i, result := range results {
+		assert.Equal(t, int64(i+1), result.Seq)
+		assert.Equal(t, types.JournalRejected, result.Status)
+		assert.Equal(t, http.StatusNotFound, result.Code)
+		assert.Contains(t, result.Error, "voter_not_found")
+		assert.False(t, result.Replayed)
+	}
+
+	// Uploading again returns the stored outcomes and continues the chain
+	third := chainEntry(t, 3, second.EntryHash, secret, key, vote("33333333333"))
+	results = uploadJournal(t, env, first, second, third)
+	assert.True(t, results[0].Replayed)
+	assert.True(t, results[1].Replayed)
+	assert.Equal(t, types.JournalRejected, results[1].Status)
+	assert.False(t, results[2].Replayed)
+	assert.Equal(t, types.JournalRejected, results[2].Status)
+
+	// A different entry at a received seq is a fork
+	fork := chainEntry(t, 2, first.EntryHash, secret, key, vote("44444444444"))
+	assert.Equal(t, types.JournalOutOfOrder, uploadJournal(t, env, fork)[0].Status)
+
+	// Gaps, tampering and unsigned entries stop the batch
+	gap := chainEntry(t, 5, third.EntryHash, secret, key, vote("55555555555"))
+	assert.Equal(t, types.JournalOutOfOrder, uploadJournal(t, env, gap)[0].Status)
+
+	tampered := chainEntry(t, 4, third.EntryHash, secret, key, vote("66666666666"))
+	tampered.Vote = json.RawMessage(`{"nin":"66666666666","fingerprint_data":"fp-66666666666","candidate_id":"PDP","polling_unit_id":"PU-1"}`)
+	next := chainEntry(t, 5, tampered.EntryHash, secret, key, vote("77777777777"))
+	results = uploadJournal(t, env, tampered, next)
+	assert.Equal(t, types.JournalInvalid, results[0].Status)
+	assert.Equal(t, types.JournalNotProcessed, results[1].Status)
+
+	unsigned := chainEntry(t, 4, third.EntryHash, "", key, vote("66666666666"))
+	assert.Equal(t, types.JournalInvalid, uploadJournal(t, env, unsigned)[0].Status)
+
+	// The reconciliation report covers the received chain
+	w := env.do("GET", "/api/v1/admin/terminals/TERM-001/reconciliation", env.tokenFor(t, models.RoleAdmin))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var report types.ReconciliationReport
+	decodeData(t, w.Body.Bytes(), &report)
+	assert.Equal(t, int64(3), report.LastSeq)
+	assert.Equal(t, 3, report.Summary["received"])
+	assert.Equal(t, 3, report.Summary[types.JournalRejected])
+	assert.Equal(t, 0, report.Summary["on_chain"])
+	require.Len(t, report.Entries, 3)
+	assert.Equal(t, third.EntryHash, report.Entries[2].EntryHash)
+}
+
+func TestJournalEntriesKeepTheirElection(t *testing.T) {
+	env := newTestEnv(t)
+	first := env.startElection(t, "1")
+	key := env.registerTerminal(t, "TERM-001", true)
+	secret := rotateTerminalSecret(t, env, "TERM-001")
+	env.registerVoter(t, "12345678901", "slot-1")
+
+	// A vote journaled offline in election 1 is uploaded after election 2 started
+	entry := chainEntry(t, 1, journal.GenesisHash, secret, key, types.VoteRequest{
+		NIN: "12345678901", FingerprintData: "slot-1", CandidateID: "APC", PollingUnitID: "PU-1", ElectionID: "1",
+	})
+	require.NoError(t, env.services.ElectionRepository().UpdateElectionStatus(first.ID, false))
+	second := &database.Election{BlockchainID: "2", Name: "Election 2", StartTime: time.Now(), EndTime: time.Now().Add(time.Hour)}
+	require.NoError(t, env.services.ElectionRepository().CreateElection(second))
+	require.NoError(t, env.services.ElectionRepository().UpdateElectionStatus(second.ID, true))
+	sealed, err := auth.Seal(testJWTSecret, "second-election-salt")
+	require.NoError(t, err)
+	_, err = env.services.ElectionSaltRepository().Create("2", sealed)
+	require.NoError(t, err)
+
+	// Its hash is checked against election 1's salt, and it is not cast in election 2
+	results := uploadJournal(t, env, entry)
+	assert.Equal(t, types.JournalRejected, results[0].Status)
+	assert.Equal(t, http.StatusBadRequest, results[0].Code)
+	assert.Contains(t, results[0].Error, "election_not_active")
+
+	// Votes for elections the server has no salt for are invalid
+	unknown := chainEntry(t, 2, entry.EntryHash, secret, key, types.VoteRequest{
+		NIN: "12345678901", FingerprintData: "slot-1", CandidateID: "APC", PollingUnitID: "PU-1", ElectionID: "9",
+	})
+	results = uploadJournal(t, env, unknown)
+	assert.Equal(t, types.JournalInvalid, results[0].Status)
+	assert.Contains(t, results[0].Error, "unknown election")
+}
+
+func TestJournalReconciliationAndVotedSet(t *testing.T) {
+	env := newTestEnv(t)
+	terminal := env.tokenFor(t, models.RoleTerminal)
+	env.registerTerminal(t, "TERM-001", true)
+	rotateTerminalSecret(t, env, "TERM-001")
+
+	election := &database.Election{BlockchainID: "3", Name: "Election 3", StartTime: time.Now(), EndTime: time.Now().Add(time.Hour)}
+	require.NoError(t, env.services.ElectionRepository().CreateElection(election))
+	require.NoError(t, env.services.ElectionRepository().UpdateElectionStatus(election.ID, true))
+
+	entry := chainEntry(t, 1, journal.GenesisHash, "", nil, types.VoteRequest{
+		NIN: "12345678901", FingerprintData: "fp", CandidateID: "APC", PollingUnitID: "PU-1",
+	})
+	// Record the entry as if its vote had been accepted, then synced
+	require.NoError(t, env.services.TerminalJournalRepository().Record(&database.TerminalJournalEntry{
+		DeviceID: "TERM-001", Seq: 1, PrevHash: entry.PrevHash, EntryHash: entry.EntryHash,
+		VerificationHash: entry.VerificationHash, PollingUnitID: "PU-1", Status: types.JournalAccepted,
+		ResponseCode: http.StatusCreated, RecordedAt: entry.CreatedAt,
+	}))
+	require.NoError(t, env.services.VoteRepository().InsertVote(&database.Vote{
+		VerificationHash: entry.VerificationHash, ElectionID: 3, PollingUnitID: "PU-1", CandidateID: "APC", Status: "pending",
+	}))
+
+	w := env.do("GET", "/api/v1/terminal/journal/reconciliation", terminal)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var report types.ReconciliationReport
+	decodeData(t, w.Body.Bytes(), &report)
+	require.Len(t, report.Entries, 1)
+	assert.False(t, report.Entries[0].OnChain)
+	assert.Equal(t, 1, report.Summary["awaiting_chain"])
+
+	require.NoError(t, env.services.VoteRepository().UpdateVoteSync(entry.VerificationHash, "0xabc", 42))
+	w = env.do("GET", "/api/v1/terminal/journal/reconciliation", terminal)
+	decodeData(t, w.Body.Bytes(), &report)
+	assert.True(t, report.Entries[0].OnChain)
+	assert.Equal(t, "0xabc", report.Entries[0].TransactionHash)
+	assert.Equal(t, int64(42), report.Entries[0].BlockNumber)
+
+	// Resending the recorded entry returns its outcome without casting again
+	results := uploadJournal(t, env, entry)
+	assert.True(t, results[0].Replayed)
+	assert.Equal(t, types.JournalAccepted, results[0].Status)
+
+	w = env.do("GET", "/api/v1/terminal/voted?polling_unit_id=PU-1", terminal)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var hashes []string
+	decodeData(t, w.Body.Bytes(), &hashes)
+	assert.Equal(t, []string{entry.VerificationHash}, hashes)
+
+	w = env.do("GET", "/api/v1/terminal/voted", terminal)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	decodeData(t, w.Body.Bytes(), &hashes)
+	assert.Equal(t, []string{entry.VerificationHash}, hashes)
+
+	// Another polling unit's voted set is refused
+	w = env.do("GET", "/api/v1/terminal/voted?polling_unit_id=PU-2", terminal)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	var refused types.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &refused))
+	assert.Equal(t, "polling_unit_mismatch", refused.Error)
+}
@@ -1,11 +1,15 @@
 package middlewares
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 	"voting-system/internal/api/interfaces"
 	"voting-system/internal/api/models"
+	"voting-system/internal/database"
 
 	"github.com/gin-gonic/gin"
 )
@@ -42,21 +46,134 @@ func AuthRequired(services interfaces.Services) gin.HandlerFunc {
 			return
 		}
 
+		// Resolve the role and permissions from the users table
+		principal, err := resolvePrincipal(services, claims)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.BaseResponse{
+				Success: false,
+				Error: &models.ErrorInfo{
+					Code:    models.ErrCodeUnauthorized,
+					Message: err.Error(),
+				},
+				Timestamp: time.Now().Unix(),
+			})
+			c.Abort()
+			return
+		}
+
+		// Sessions of users who still have to enrol in 2FA may only reach the enrolment routes
+		if principal.twoFactorPending && !c.GetBool(twoFactorSetupKey) {
+			c.JSON(http.StatusForbidden, models.BaseResponse{
+				Success: false,
+				Error: &models.ErrorInfo{
+					Code:    models.ErrCodeTwoFactorRequired,
+					Message: "Two-factor authentication must be enabled for this account",
+				},
+				Timestamp: time.Now().Unix(),
+			})
+			c.Abort()
+			return
+		}
+
 		// Set user context from validated claims
 		c.Set("user_id", claims.UserID)
-		c.Set("user_role", claims.Role)
-		c.Set("user_permissions", claims.Permissions)
+		c.Set("user_role", principal.role)
+		c.Set("user_permissions", principal.permissions)
 		c.Set("session_id", claims.SessionID)
+		c.Set("polling_unit_id", claims.PollingUnitID)
 
 		c.Next()
 	}
 }
 
+// OptionalAuth authenticates requests that carry a token, as AuthRequired
+// does, and lets requests without one through
+func OptionalAuth(services interfaces.Services) gin.HandlerFunc {
+	authRequired := AuthRequired(services)
+	return func(c *gin.Context) {
+		if extractToken(c) == "" {
+			c.Next()
+			return
+		}
+		authRequired(c)
+	}
+}
+
+// twoFactorSetupKey marks routes that sessions pending 2FA enrolment may use
+const twoFactorSetupKey = "allow_two_factor_setup"
+
+// AllowTwoFactorSetup lets sessions that still have to enrol in 2FA through
+// AuthRequired. It must be registered before AuthRequired.
+func AllowTwoFactorSetup() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(twoFactorSetupKey, true)
+		c.Next()
+	}
+}
+
+// principal is the caller resolved from a validated token
+type principal struct {
+	role             string
+	permissions      []string
+	twoFactorPending bool
+}
+
+// resolvePrincipal returns the effective role and permissions for validated claims.
+// Terminal tokens get the terminal role's permissions; user tokens are checked
+// against the users table so role changes and deactivation take effect immediately.
+func resolvePrincipal(services interfaces.Services, claims *interfaces.Claims) (*principal, error) {
+	if claims.Role == models.RoleTerminal {
+		return &principal{
+			role:        models.RoleTerminal,
+			permissions: models.PermissionsForRole(models.RoleTerminal, nil),
+		}, nil
+	}
+
+	userID, err := strconv.ParseInt(claims.UserID, 10, 64)
+	if err != nil {
+		return nil, errors.New("invalid user in token")
+	}
+
+	user, err := services.UserRepository().GetByID(userID)
+	if err != nil || !user.IsActive {
+		return nil, errors.New("user not found or inactive")
+	}
+
+	// User tokens are bound to a login session; logout revokes them immediately
+	if claims.SessionID == "" {
+		return nil, errors.New("token is not bound to a session")
+	}
+	session, err := services.SessionRepository().GetByID(claims.SessionID)
+	if err != nil || session.UserID != user.ID {
+		return nil, errors.New("session expired or revoked")
+	}
+
+	if !models.IsStaffRole(user.Role) {
+		return nil, errors.New("user has no valid role")
+	}
+
+	extra, err := models.ParsePermissions(user.Permissions)
+	if err != nil {
+		return nil, errors.New("invalid user permissions")
+	}
+
+	var data database.SessionData
+	if session.Data != "" {
+		_ = json.Unmarshal([]byte(session.Data), &data)
+	}
+
+	return &principal{
+		role:             user.Role,
+		permissions:      models.PermissionsForRole(user.Role, extra),
+		twoFactorPending: data.TwoFactorPending,
+	}, nil
+}
+
 // AdminRequired middleware ensures user has admin role
 func AdminRequired(services interfaces.Services) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("user_role")
-		if !exists || userRole != "admin" {
+		if !exists || userRole != models.RoleAdmin {
 			c.JSON(http.StatusForbidden, models.BaseResponse{
 				Success: false,
 				Error: &models.ErrorInfo{
@@ -169,8 +286,22 @@ func WSAuthRequired(services interfaces.Services) gin.HandlerFunc {
 			return
 		}
 
+		principal, err := resolvePrincipal(services, claims)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+		if principal.twoFactorPending {
+			c.JSON(http.StatusForbidden, gin.H{"error": "two-factor authentication must be enabled for this account"})
+			c.Abort()
+			return
+		}
+
 		c.Set("user_id", claims.UserID)
-		c.Set("user_role", claims.Role)
+		c.Set("user_role", principal.role)
+		c.Set("user_permissions", principal.permissions)
+		c.Set("polling_unit_id", claims.PollingUnitID)
 		c.Next()
 	}
 }
@@ -29,6 +29,7 @@ const (
 	ErrCodeTokenExpired       = "TOKEN_EXPIRED"
 	ErrCodeInvalidToken       = "INVALID_TOKEN"
 	ErrCodeAccountLocked      = "ACCOUNT_LOCKED"
+	ErrCodeTwoFactorRequired  = "TWO_FACTOR_REQUIRED"
 
 	// Terminal errors
 	ErrCodeTerminalNotFound     = "TERMINAL_NOT_FOUND"
@@ -0,0 +1,115 @@
+package models
+
+import "encoding/json"
+
+// User and device roles
+const (
+	RoleAdmin    = "admin"
+	RoleOperator = "operator"
+	RoleAuditor  = "auditor"
+	RoleTerminal = "terminal"
+	RoleTrustee  = "trustee"
+)
+
+// Permissions checked by PermissionRequired
+const (
+	PermVoting          = "voting"           // cast votes and verify voters
+	PermTerminal        = "terminal"         // terminal self-service endpoints
+	PermDashboardRead   = "dashboard:read"   // admin dashboard and contract info
+	PermElectionsRead   = "elections:read"   // election statistics and live updates
+	PermElectionsManage = "elections:manage" // create, start, end and delete elections
+	PermTerminalsManage = "terminals:manage" // authorize terminals
+	PermVotesManage     = "votes:manage"     // invalidate votes
+	PermSystemManage    = "system:manage"    // sync and polling unit registration
+	PermAuditRead       = "audit:read"       // audit logs and reports
+	PermUsersManage     = "users:manage"     // create, update and deactivate staff accounts
+	PermTallyDecrypt    = "tally:decrypt"    // hold key shares and submit partial decryptions
+	PermVotersManage    = "voters:manage"    // bulk imports and voter search, transfers and deactivation
+)
+
+// RolePermissions is the permission matrix granted to each role.
+// Permissions stored on a user record are added on top of their role's set.
+var RolePermissions = map[string][]string{
+	RoleAdmin: {
+		PermDashboardRead, PermElectionsRead, PermElectionsManage, PermTerminalsManage,
+		PermVotesManage, PermSystemManage, PermAuditRead, PermUsersManage, PermVotersManage,
+	},
+	RoleOperator: {
+		PermDashboardRead, PermElectionsRead, PermTerminalsManage, PermSystemManage, PermVotersManage,
+	},
+	RoleAuditor: {
+		PermDashboardRead, PermElectionsRead, PermAuditRead,
+	},
+	RoleTerminal: {
+		PermVoting, PermTerminal,
+	},
+	RoleTrustee: {
+		PermTallyDecrypt,
+	},
+}
+
+// TwoFactorRoles are the roles that must enrol in TOTP two-factor
+// authentication when SecurityConfig.EnableTwoFA is set
+var TwoFactorRoles = map[string]bool{
+	RoleAdmin:   true,
+	RoleTrustee: true,
+}
+
+// RequiresTwoFactor reports whether accounts with role must use 2FA
+func RequiresTwoFactor(role string) bool {
+	return TwoFactorRoles[role]
+}
+
+// IsValidRole reports whether role is a known role
+func IsValidRole(role string) bool {
+	_, ok := RolePermissions[role]
+	return ok
+}
+
+// IsStaffRole reports whether role can be assigned to a user account
+func IsStaffRole(role string) bool {
+	return role != RoleTerminal && IsValidRole(role)
+}
+
+// IsAssignablePermission reports whether a permission may be granted to a user
+// account on top of its role. Device permissions are reserved for terminals.
+func IsAssignablePermission(perm string) bool {
+	for role, permissions := range RolePermissions {
+		if role == RoleTerminal {
+			continue
+		}
+		for _, p := range permissions {
+			if p == perm {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PermissionsForRole returns the role's permissions merged with any extra grants
+func PermissionsForRole(role string, extra []string) []string {
+	seen := make(map[string]bool)
+	permissions := make([]string, 0, len(RolePermissions[role])+len(extra))
+	for _, perm := range append(append([]string{}, RolePermissions[role]...), extra...) {
+		if perm == "" || seen[perm] {
+			continue
+		}
+		seen[perm] = true
+		permissions = append(permissions, perm)
+	}
+	return permissions
+}
+
+// ParsePermissions decodes the JSON permission list stored on a user record
+func ParsePermissions(raw string) ([]string, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var permissions []string
+	if err := json.Unmarshal([]byte(raw), &permissions); err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
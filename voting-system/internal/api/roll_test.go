@@ -0,0 +1,111 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"voting-system/internal/api/models"
+	"voting-system/internal/api/types"
+	"voting-system/internal/database"
+	"voting-system/internal/merkle"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// snapshotRoll freezes the roll of an election started by startElection
+func (env *testEnv) snapshotRoll(t *testing.T, blockchainID string) database.ElectionRoll {
+	t.Helper()
+
+	w := env.do("POST", "/api/v1/admin/elections/"+blockchainID+"/roll/snapshot", env.tokenFor(t, models.RoleAdmin))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var roll database.ElectionRoll
+	decodeData(t, w.Body.Bytes(), &roll)
+	return roll
+}
+
+// registerVoter registers a voter through a terminal of PU-1
+func (env *testEnv) registerVoter(t *testing.T, nin, fingerprint string) {
+	t.Helper()
+
+	w := env.doJSON("POST", "/api/v1/public/voter/register", env.tokenFor(t, models.RoleTerminal), types.VoterRegistrationRequest{
+		NIN: nin, FirstName: "Ada", LastName: "Obi", DateOfBirth: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+		Gender: "F", PollingUnitID: "PU-1", FingerprintData: fingerprint,
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+}
+
+func TestVoterRollSnapshotAndProofs(t *testing.T) {
+	env := newTestEnv(t)
+	key := env.registerTerminal(t, "TERM-001", true)
+	env.registerVoter(t, "12345678901", "slot-1")
+	env.registerVoter(t, "10987654321", "slot-2")
+	env.startElection(t, "1")
+
+	w := env.do("GET", "/api/v1/public/election/1/roll", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	roll := env.snapshotRoll(t, "1")
+	assert.Equal(t, 2, roll.VoterCount)
+	assert.Nil(t, roll.PublishedAt, "the root is published when the election starts")
+
+	// Snapshots are taken once
+	env.registerVoter(t, "11122233344", "slot-3")
+	assert.Equal(t, roll.MerkleRoot, env.snapshotRoll(t, "1").MerkleRoot)
+	logs, err := env.services.AuditLogRepository().GetAuditLogsByAction("election_roll_snapshot", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, logs, 1)
+
+	// Observers see the root and leaves, and rebuild the same tree
+	w = env.do("GET", "/api/v1/public/election/1/roll/leaves", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var page types.VoterRollLeaves
+	decodeData(t, w.Body.Bytes(), &page)
+	assert.Equal(t, 2, page.Total)
+	require.Len(t, page.Leaves, 2)
+	leaves := make([]merkle.Hash, len(page.Leaves))
+	for i, leaf := range page.Leaves {
+		leaves[i], err = merkle.ParseHash(leaf)
+		require.NoError(t, err)
+	}
+	tree, err := merkle.New(leaves)
+	require.NoError(t, err)
+	assert.Equal(t, roll.MerkleRoot, tree.Root().Hex())
+	assert.NotContains(t, w.Body.String(), "12345678901")
+
+	// A voter's proof leads from their leaf to the root
+	auditor := env.tokenFor(t, models.RoleAuditor)
+	w = env.do("GET", "/api/v1/election/1/roll/proof?nin=12345678901", auditor)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var proof types.VoterRollProof
+	decodeData(t, w.Body.Bytes(), &proof)
+	assert.Equal(t, merkle.ChainHash(voteHash("12345678901")).Hex(), proof.VerificationHash)
+	assert.Equal(t, merkle.Leaf(voteHash("12345678901")).Hex(), proof.Leaf)
+	siblings := make([]merkle.Hash, len(proof.Proof))
+	for i, node := range proof.Proof {
+		siblings[i], err = merkle.ParseHash(node)
+		require.NoError(t, err)
+	}
+	leaf, err := merkle.ParseHash(proof.Leaf)
+	require.NoError(t, err)
+	root, err := merkle.ParseHash(proof.Root)
+	require.NoError(t, err)
+	assert.True(t, merkle.Verify(root, leaf, siblings))
+
+	// Voters registered after the snapshot are not on the roll
+	w = env.do("GET", "/api/v1/election/1/roll/proof?nin=11122233344", auditor)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	vote := types.VoteRequest{NIN: "11122233344", FingerprintData: "slot-3", CandidateID: "APC", PollingUnitID: "PU-1"}
+	signVote(t, key, "TERM-001", &vote)
+	code, reason := castError(t, env, vote)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "not_eligible", reason)
+
+	// and voters on it go on to the fingerprint check
+	vote = types.VoteRequest{NIN: "12345678901", FingerprintData: "slot-9", CandidateID: "APC", PollingUnitID: "PU-1"}
+	signVote(t, key, "TERM-001", &vote)
+	code, reason = castError(t, env, vote)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Equal(t, "invalid_fingerprint", reason)
+}
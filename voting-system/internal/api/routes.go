@@ -4,6 +4,7 @@ import (
 	"voting-system/internal/api/handlers"
 	"voting-system/internal/api/interfaces"
 	"voting-system/internal/api/middlewares"
+	"voting-system/internal/api/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -49,77 +50,127 @@ func setupPublicRoutes(rg *gin.RouterGroup, services interfaces.Services) {
 		public.GET("/election/:id", handlers.GetElectionDetails(services))
 		public.GET("/election/:id/results", handlers.GetElectionResults(services))
 		public.GET("/election/:id/candidates", handlers.GetElectionCandidates(services))
+		public.GET("/election/:id/ballot-key", handlers.GetBallotKey(services))
+		public.GET("/election/:id/roll", handlers.GetVoterRoll(services))
+		public.GET("/election/:id/roll/leaves", handlers.GetVoterRollLeaves(services))
 
 		// Polling Unit
 		public.GET("/polling-unit/:id", handlers.GetPollingUnitInfo(services))
 
-		// Voter registration (public endpoint)
-		public.POST("/voter/register", handlers.RegisterVoter(services))
+		// Voter registration (public endpoint; terminals register at their own polling unit)
+		public.POST("/voter/register", middlewares.OptionalAuth(services), handlers.RegisterVoter(services))
 
-		// Terminal token issuance (HMAC optional)
+		// Terminal enrolment and token issuance (signed with the terminal's secret)
+		public.POST("/terminal/enrol", handlers.EnrolTerminal(services))
 		public.POST("/token/terminal", handlers.IssueTerminalToken(services))
 
 		// Authentication
-		// auth := public.Group("/auth")
-		// {
-		// 	auth.POST("/login", handlers.Login(services))
-		// 	auth.POST("/refresh", handlers.RefreshToken(services))
-		// }
+		auth := public.Group("/auth")
+		{
+			auth.POST("/login", handlers.Login(services))
+			auth.POST("/refresh", handlers.RefreshToken(services))
+			auth.POST("/reset-password", handlers.CompletePasswordReset(services))
+		}
 	}
 }
 
 // setupAuthenticatedRoutes configures routes that require authentication
 func setupAuthenticatedRoutes(rg *gin.RouterGroup, services interfaces.Services) {
-	// Voting endpoints (no auth)
+	// Session management and 2FA enrolment (any authenticated user, including
+	// sessions that must enrol in 2FA before using anything else)
+	auth := rg.Group("/auth")
+	auth.Use(middlewares.AllowTwoFactorSetup(), middlewares.AuthRequired(services))
+	{
+		auth.POST("/logout", handlers.Logout(services))
+		auth.POST("/logout-all", handlers.LogoutAll(services))
+		auth.GET("/sessions", handlers.ListSessions(services))
+		auth.DELETE("/sessions/:id", handlers.RevokeSession(services))
+
+		auth.GET("/2fa", handlers.GetTwoFactorStatus(services))
+		auth.POST("/2fa/enroll", handlers.EnrollTwoFactor(services))
+		auth.POST("/2fa/confirm", handlers.ConfirmTwoFactor(services))
+		auth.POST("/2fa/recovery-codes", handlers.RegenerateRecoveryCodes(services))
+		auth.POST("/2fa/disable", handlers.DisableTwoFactor(services))
+	}
+
+	// Voting endpoints (terminals)
 	voting := rg.Group("/voting")
+	voting.Use(middlewares.AuthRequired(services), middlewares.PermissionRequired(models.PermVoting))
 	{
 		voting.POST("/cast", handlers.CastVote(services))
 		voting.GET("/status/:voter_hash", handlers.GetVoterStatus(services))
 		voting.POST("/verify", handlers.VerifyVoter(services))
 	}
 
-	// Election endpoints (no auth)
+	// Election endpoints (admin, operator, auditor)
 	election := rg.Group("/election")
+	election.Use(middlewares.AuthRequired(services), middlewares.PermissionRequired(models.PermElectionsRead))
 	{
 		election.GET("/:id/statistics", handlers.GetElectionStatistics(services))
+		election.GET("/:id/roll/proof", handlers.GetVoterRollProof(services))
 		// election.GET("/:id/audit", handlers.GetElectionAudit(services))
 	}
 
-	// Terminal endpoints (no auth)
+	// Terminal endpoints (terminals)
 	terminal := rg.Group("/terminal")
+	terminal.Use(middlewares.AuthRequired(services), middlewares.PermissionRequired(models.PermTerminal))
 	{
-		terminal.POST("/register", handlers.RegisterTerminal(services))
 		terminal.GET("/:id/status", handlers.GetTerminalStatus(services))
 		terminal.POST("/polling-unit/ensure", handlers.EnsurePollingUnitTerminal(services))
-		// terminal.POST("/:id/heartbeat", handlers.TerminalHeartbeat(services))
-		// terminal.GET("/:id/config", handlers.GetTerminalConfig(services))
+		terminal.GET("/voters", handlers.GetPollingUnitRoster(services))
+		terminal.GET("/voted", handlers.GetVotedSet(services))
+		terminal.POST("/journal", handlers.UploadJournal(services))
+		terminal.GET("/journal/reconciliation", handlers.GetJournalReconciliation(services))
+		terminal.POST("/:id/heartbeat", handlers.TerminalHeartbeat(services))
+		terminal.GET("/:id/config", handlers.GetTerminalConfig(services))
 	}
 
-	// Audit endpoints (no auth)
+	// Audit endpoints (admin, auditor)
 	audit := rg.Group("/audit")
+	audit.Use(middlewares.AuthRequired(services), middlewares.PermissionRequired(models.PermAuditRead))
 	{
 		audit.GET("/logs", handlers.GetAuditLogs(services))
 		audit.GET("/votes/:time_range", handlers.GetVotesByTimeRange(services))
 	}
+
+	// Trustee endpoints: key share collection and partial decryption
+	trustee := rg.Group("/trustee")
+	trustee.Use(middlewares.AuthRequired(services), middlewares.PermissionRequired(models.PermTallyDecrypt))
+	{
+		trustee.GET("/elections", handlers.ListTrusteeSeats(services))
+		trustee.POST("/elections/:id/key-share", handlers.CollectKeyShare(services))
+		trustee.GET("/elections/:id/tally", handlers.GetEncryptedTally(services))
+		trustee.POST("/elections/:id/decryption-share", handlers.SubmitDecryptionShare(services))
+	}
 }
 
 // setupAdminRoutes configures admin-only routes
 func setupAdminRoutes(rg *gin.RouterGroup, services interfaces.Services) {
-	// Admin routes (no auth)
+	// Admin routes (authenticated staff; each group narrows by permission)
+	admin := rg.Group("/admin")
+	admin.Use(middlewares.AuthRequired(services))
 	{
 		// Dashboard
-		rg.GET("/admin/dashboard", handlers.AdminDashboard(services))
+		admin.GET("/dashboard", middlewares.PermissionRequired(models.PermDashboardRead), handlers.AdminDashboard(services))
 		// rg.GET("/admin/stats", handlers.GetAdminStats(services))
 
 		// Election management
-		elections := rg.Group("/admin/elections")
+		elections := admin.Group("/elections")
+		elections.Use(middlewares.AdminRequired(services), middlewares.PermissionRequired(models.PermElectionsManage))
 		{
 			elections.POST("/", handlers.CreateElection(services))
 			// elections.PUT("/:id", handlers.UpdateElection(services))
+			elections.POST("/:id/roll/snapshot", handlers.SnapshotVoterRoll(services))
 			elections.POST("/:id/start", handlers.StartElection(services))
 			elections.POST("/:id/end", handlers.EndElection(services))
 			// New: register candidates
 			elections.POST("/:id/candidates", handlers.RegisterCandidates(services))
+			// Encrypted ballots: key registration, tally and threshold decryption
+			elections.PUT("/:id/ballot-key", handlers.SetBallotKey(services))
+			elections.GET("/:id/tally", handlers.GetEncryptedTally(services))
+			elections.POST("/:id/tally/decrypt", handlers.DecryptTally(services))
+			elections.POST("/:id/key-ceremony", handlers.StartKeyCeremony(services))
+			elections.GET("/:id/key-ceremony", handlers.GetKeyCeremony(services))
 			// New: list and delete (DB only)
 			elections.GET("/", handlers.ListElections(services))
 			elections.DELETE("/", handlers.DeleteElection(services))
@@ -128,17 +179,45 @@ func setupAdminRoutes(rg *gin.RouterGroup, services interfaces.Services) {
 		}
 
 		// Terminal management
-		terminals := rg.Group("/admin/terminals")
+		terminals := admin.Group("/terminals")
+		terminals.Use(middlewares.PermissionRequired(models.PermTerminalsManage))
 		{
-			// terminals.GET("/", handlers.ListTerminals(services))
+			terminals.GET("/", handlers.ListTerminals(services))
+			terminals.POST("/enrolment-codes", handlers.IssueEnrolmentCode(services))
+			terminals.GET("/enrolment-codes", handlers.ListEnrolmentCodes(services))
+			terminals.POST("/:id/approve", handlers.ApproveTerminal(services))
 			terminals.POST("/:id/authorize", handlers.AuthorizeTerminal(services))
-			// terminals.POST("/:id/deauthorize", handlers.DeauthorizeTerminal(services))
-			// terminals.DELETE("/:id", handlers.RemoveTerminal(services))
-			// terminals.GET("/:id/logs", handlers.GetTerminalLogs(services))
+			terminals.POST("/:id/deauthorize", handlers.DeauthorizeTerminal(services))
+			terminals.POST("/:id/secret", handlers.RotateTerminalSecret(services))
+			terminals.DELETE("/:id/secret", handlers.RevokeTerminalSecrets(services))
+			terminals.DELETE("/:id", handlers.RemoveTerminal(services))
+			terminals.GET("/:id/logs", handlers.GetTerminalLogs(services))
+			terminals.GET("/:id/reconciliation", handlers.GetTerminalReconciliation(services))
+			terminals.GET("/:id/status", handlers.GetTerminalStatusAdmin(services))
+			terminals.PUT("/:id/config", handlers.UpdateTerminalConfig(services))
+			terminals.GET("/alerts", handlers.ListTerminalAlerts(services))
+			terminals.POST("/bulk-authorize", handlers.BulkAuthorizeTerminals(services))
+		}
+
+		// Voter management
+		voters := admin.Group("/voters")
+		voters.Use(middlewares.PermissionRequired(models.PermVotersManage))
+		{
+			voters.POST("/import", handlers.ImportVoters(services))
+			voters.GET("/import/:id", handlers.GetVoterImport(services))
+			voters.GET("/import/:id/errors", handlers.ListVoterImportErrors(services))
+
+			voters.GET("", handlers.SearchVoters(services))
+			voters.GET("/:nin", handlers.GetVoter(services))
+			voters.POST("/:nin/transfer", handlers.TransferVoter(services))
+			voters.POST("/:nin/deactivate", handlers.DeactivateVoter(services))
+			voters.POST("/:nin/reactivate", handlers.ReactivateVoter(services))
+			voters.POST("/:nin/fingerprint", handlers.ReenrolVoterFingerprint(services))
 		}
 
 		// Vote management
-		votes := rg.Group("/admin/votes")
+		votes := admin.Group("/votes")
+		votes.Use(middlewares.AdminRequired(services), middlewares.PermissionRequired(models.PermVotesManage))
 		{
 			// votes.GET("/", handlers.ListVotes(services))
 			// votes.GET("/:id", handlers.GetVoteDetails(services))
@@ -147,7 +226,8 @@ func setupAdminRoutes(rg *gin.RouterGroup, services interfaces.Services) {
 		}
 
 		// System management
-		system := rg.Group("/admin/system")
+		system := admin.Group("/system")
+		system.Use(middlewares.PermissionRequired(models.PermSystemManage))
 		{
 			system.POST("/sync", handlers.TriggerSync(services))
 			// Register polling unit on-chain
@@ -159,7 +239,8 @@ func setupAdminRoutes(rg *gin.RouterGroup, services interfaces.Services) {
 		}
 
 		// Blockchain management
-		blockchain := rg.Group("/admin/blockchain")
+		blockchain := admin.Group("/blockchain")
+		blockchain.Use(middlewares.PermissionRequired(models.PermDashboardRead))
 		{
 			// blockchain.GET("/status", handlers.GetBlockchainStatus(services))
 			// blockchain.GET("/transactions", handlers.ListTransactions(services))
@@ -167,18 +248,22 @@ func setupAdminRoutes(rg *gin.RouterGroup, services interfaces.Services) {
 			// blockchain.POST("/redeploy", handlers.RedeployContract(services))
 		}
 
-		// // User management
-		// users := rg.Group("/admin/users")
-		// {
-		// 	users.GET("/", handlers.ListUsers(services))
-		// 	users.POST("/", handlers.CreateUser(services))
-		// 	users.PUT("/:id", handlers.UpdateUser(services))
-		// 	users.DELETE("/:id", handlers.DeleteUser(services))
-		// 	users.POST("/:id/reset-password", handlers.ResetUserPassword(services))
-		// }
+		// User management
+		users := admin.Group("/users")
+		users.Use(middlewares.PermissionRequired(models.PermUsersManage))
+		{
+			users.GET("/", handlers.ListUsers(services))
+			users.POST("/", handlers.CreateUser(services))
+			users.GET("/:id", handlers.GetUser(services))
+			users.PUT("/:id", handlers.UpdateUser(services))
+			users.DELETE("/:id", handlers.DeleteUser(services))
+			users.POST("/:id/reset-password", handlers.ResetUserPassword(services))
+			users.DELETE("/:id/2fa", handlers.ResetUserTwoFactor(services))
+		}
 
 		// Audit and reporting
-		reports := rg.Group("/admin/reports")
+		reports := admin.Group("/reports")
+		reports.Use(middlewares.PermissionRequired(models.PermAuditRead))
 		{
 			reports.GET("/audit/full", handlers.GetFullAuditLogs(services))
 			// reports.GET("/election/:id/report", handlers.GetElectionReport(services))
@@ -197,7 +282,8 @@ func setupWebSocketRoutes(rg *gin.RouterGroup, services interfaces.Services) {
 		// ws.GET("/election/:id/results", handlers.ElectionResultsWebSocket(services))
 
 		// Authenticated WebSocket endpoints
-		ws.GET("/votes", handlers.VoteUpdatesWebSocket(services))
+		ws.GET("/votes", middlewares.WSAuthRequired(services), middlewares.PermissionRequired(models.PermElectionsRead),
+			handlers.VoteUpdatesWebSocket(services))
 
 		// Admin WebSocket endpoints
 		// admin := ws.Group("/admin")
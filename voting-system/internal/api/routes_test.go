@@ -0,0 +1,394 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"voting-system/internal/api/models"
+	"voting-system/internal/database"
+	"voting-system/internal/database/repositories"
+	"voting-system/pkg/config"
+	"voting-system/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testJWTSecret = "test-jwt-secret"
+
+// noRole is used for requests sent without a token
+const noRole = "none"
+
+// routePermission describes which roles may call a protected route
+type routePermission struct {
+	method  string
+	path    string
+	allowed []string
+}
+
+// protectedRoutes is the permission matrix enforced by SetupRoutes
+var protectedRoutes = []routePermission{
+	// Voting (terminals only)
+	{"POST", "/api/v1/voting/cast", []string{models.RoleTerminal}},
+	{"GET", "/api/v1/voting/status/abc123", []string{models.RoleTerminal}},
+	{"POST", "/api/v1/voting/verify", []string{models.RoleTerminal}},
+	{"GET", "/api/v1/terminal/voters?polling_unit_id=PU-1", []string{models.RoleTerminal}},
+	{"GET", "/api/v1/terminal/voted?polling_unit_id=PU-1", []string{models.RoleTerminal}},
+	{"POST", "/api/v1/terminal/journal", []string{models.RoleTerminal}},
+	{"GET", "/api/v1/terminal/journal/reconciliation", []string{models.RoleTerminal}},
+
+	// Election statistics
+	{"GET", "/api/v1/election/1/statistics", []string{models.RoleAdmin, models.RoleOperator, models.RoleAuditor}},
+	{"GET", "/api/v1/election/1/roll/proof?nin=12345678901", []string{models.RoleAdmin, models.RoleOperator, models.RoleAuditor}},
+
+	// Terminal self-service
+	{"GET", "/api/v1/terminal/TERM-001/status", []string{models.RoleTerminal}},
+	{"POST", "/api/v1/terminal/TERM-001/heartbeat", []string{models.RoleTerminal}},
+	{"GET", "/api/v1/terminal/TERM-001/config", []string{models.RoleTerminal}},
+	{"POST", "/api/v1/terminal/polling-unit/ensure", []string{models.RoleTerminal}},
+
+	// Audit
+	{"GET", "/api/v1/audit/logs", []string{models.RoleAdmin, models.RoleAuditor}},
+	{"GET", "/api/v1/audit/votes/24h", []string{models.RoleAdmin, models.RoleAuditor}},
+
+	// Admin
+	{"GET", "/api/v1/admin/dashboard", []string{models.RoleAdmin, models.RoleOperator, models.RoleAuditor}},
+	{"POST", "/api/v1/admin/elections/", []string{models.RoleAdmin}},
+	{"GET", "/api/v1/admin/elections/", []string{models.RoleAdmin}},
+	{"DELETE", "/api/v1/admin/elections/", []string{models.RoleAdmin}},
+	{"POST", "/api/v1/admin/elections/1/roll/snapshot", []string{models.RoleAdmin}},
+	{"POST", "/api/v1/admin/elections/1/start", []string{models.RoleAdmin}},
+	{"POST", "/api/v1/admin/elections/1/end", []string{models.RoleAdmin}},
+	{"POST", "/api/v1/admin/elections/1/candidates", []string{models.RoleAdmin}},
+	{"PUT", "/api/v1/admin/elections/1/ballot-key", []string{models.RoleAdmin}},
+	{"GET", "/api/v1/admin/elections/1/tally", []string{models.RoleAdmin}},
+	{"POST", "/api/v1/admin/elections/1/tally/decrypt", []string{models.RoleAdmin}},
+	{"POST", "/api/v1/admin/elections/1/key-ceremony", []string{models.RoleAdmin}},
+	{"GET", "/api/v1/admin/elections/1/key-ceremony", []string{models.RoleAdmin}},
+	{"POST", "/api/v1/admin/terminals/T1/authorize", []string{models.RoleAdmin, models.RoleOperator}},
+	{"GET", "/api/v1/admin/terminals/T1/reconciliation", []string{models.RoleAdmin, models.RoleOperator}},
+	{"GET", "/api/v1/admin/terminals/T1/status", []string{models.RoleAdmin, models.RoleOperator}},
+	{"PUT", "/api/v1/admin/terminals/T1/config", []string{models.RoleAdmin, models.RoleOperator}},
+	{"GET", "/api/v1/admin/terminals/alerts", []string{models.RoleAdmin, models.RoleOperator}},
+	{"GET", "/api/v1/admin/terminals/", []string{models.RoleAdmin, models.RoleOperator}},
+	{"POST", "/api/v1/admin/terminals/T1/deauthorize", []string{models.RoleAdmin, models.RoleOperator}},
+	{"DELETE", "/api/v1/admin/terminals/T1", []string{models.RoleAdmin, models.RoleOperator}},
+	{"GET", "/api/v1/admin/terminals/T1/logs", []string{models.RoleAdmin, models.RoleOperator}},
+	{"POST", "/api/v1/admin/terminals/bulk-authorize", []string{models.RoleAdmin, models.RoleOperator}},
+	{"POST", "/api/v1/admin/terminals/enrolment-codes", []string{models.RoleAdmin, models.RoleOperator}},
+	{"GET", "/api/v1/admin/terminals/enrolment-codes", []string{models.RoleAdmin, models.RoleOperator}},
+	{"POST", "/api/v1/admin/terminals/T1/approve", []string{models.RoleAdmin, models.RoleOperator}},
+	{"POST", "/api/v1/admin/terminals/T1/secret", []string{models.RoleAdmin, models.RoleOperator}},
+	{"DELETE", "/api/v1/admin/terminals/T1/secret", []string{models.RoleAdmin, models.RoleOperator}},
+	{"POST", "/api/v1/admin/voters/import", []string{models.RoleAdmin, models.RoleOperator}},
+	{"GET", "/api/v1/admin/voters/import/job-1", []string{models.RoleAdmin, models.RoleOperator}},
+	{"GET", "/api/v1/admin/voters/import/job-1/errors", []string{models.RoleAdmin, models.RoleOperator}},
+	{"GET", "/api/v1/admin/voters?q=ada", []string{models.RoleAdmin, models.RoleOperator}},
+	{"GET", "/api/v1/admin/voters/12345678901", []string{models.RoleAdmin, models.RoleOperator}},
+	{"POST", "/api/v1/admin/voters/12345678901/transfer", []string{models.RoleAdmin, models.RoleOperator}},
+	{"POST", "/api/v1/admin/voters/12345678901/deactivate", []string{models.RoleAdmin, models.RoleOperator}},
+	{"POST", "/api/v1/admin/voters/12345678901/reactivate", []string{models.RoleAdmin, models.RoleOperator}},
+	{"POST", "/api/v1/admin/voters/12345678901/fingerprint", []string{models.RoleAdmin, models.RoleOperator}},
+	{"POST", "/api/v1/admin/votes/1/invalidate", []string{models.RoleAdmin}},
+	{"POST", "/api/v1/admin/system/sync", []string{models.RoleAdmin, models.RoleOperator}},
+	{"POST", "/api/v1/admin/system/polling-unit", []string{models.RoleAdmin, models.RoleOperator}},
+	{"GET", "/api/v1/admin/blockchain/contracts", []string{models.RoleAdmin, models.RoleOperator, models.RoleAuditor}},
+	{"GET", "/api/v1/admin/reports/audit/full", []string{models.RoleAdmin, models.RoleAuditor}},
+	{"GET", "/api/v1/admin/users/", []string{models.RoleAdmin}},
+	{"POST", "/api/v1/admin/users/", []string{models.RoleAdmin}},
+	{"GET", "/api/v1/admin/users/1", []string{models.RoleAdmin}},
+	{"PUT", "/api/v1/admin/users/1", []string{models.RoleAdmin}},
+	{"DELETE", "/api/v1/admin/users/999", []string{models.RoleAdmin}},
+	{"POST", "/api/v1/admin/users/1/reset-password", []string{models.RoleAdmin}},
+	{"DELETE", "/api/v1/admin/users/999/2fa", []string{models.RoleAdmin}},
+
+	// Trustees (key shares and partial decryptions)
+	{"GET", "/api/v1/trustee/elections", []string{models.RoleTrustee}},
+	{"POST", "/api/v1/trustee/elections/1/key-share", []string{models.RoleTrustee}},
+	{"GET", "/api/v1/trustee/elections/1/tally", []string{models.RoleTrustee}},
+	{"POST", "/api/v1/trustee/elections/1/decryption-share", []string{models.RoleTrustee}},
+
+	// WebSocket (token passed as query parameter)
+	{"GET", "/api/v1/ws/votes", []string{models.RoleAdmin, models.RoleOperator, models.RoleAuditor}},
+}
+
+// publicRoutes must stay reachable without a token
+var publicRoutes = []routePermission{
+	{"GET", "/api/v1/public/status", nil},
+	{"GET", "/api/v1/public/election/current", nil},
+	{"GET", "/api/v1/public/election/1/ballot-key", nil},
+	{"GET", "/api/v1/public/election/1/roll", nil},
+	{"GET", "/api/v1/public/election/1/roll/leaves", nil},
+	{"POST", "/api/v1/public/voter/register", nil},
+	{"POST", "/api/v1/public/token/terminal", nil},
+	{"POST", "/api/v1/public/terminal/enrol", nil},
+	{"POST", "/api/v1/public/auth/login", nil},
+	{"POST", "/api/v1/public/auth/refresh", nil},
+	{"POST", "/api/v1/public/auth/reset-password", nil},
+}
+
+// testEnv holds a router wired to a throwaway database
+type testEnv struct {
+	router   *gin.Engine
+	services *Services
+	users    *repositories.UserRepository
+	sessions *repositories.SessionRepository
+	userIDs  map[string]int64
+	request  int
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	gin.DefaultErrorWriter = io.Discard
+
+	db, err := database.NewConnection(&config.DatabaseConfig{
+		Type: "sqlite",
+		Path: filepath.Join(t.TempDir(), "test.db"),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, database.RunMigrations(db))
+
+	cfg := &config.Config{
+		Blockchain: config.BlockchainConfig{
+			ChainID:         testVoteDomain.ChainID.Int64(),
+			ContractAddress: testVoteDomain.Contract.Hex(),
+		},
+		Security: config.SecurityConfig{
+			JWTSecret:        testJWTSecret,
+			JWTExpiration:    15 * time.Minute,
+			SessionTimeout:   time.Hour,
+			MaxLoginAttempts: 3,
+			LockoutDuration:  15 * time.Minute,
+		},
+		Biometric: config.BiometricConfig{QualityThreshold: 0.8, MatchThreshold: 0.85, MaxAttempts: 3},
+		Fleet: config.FleetConfig{
+			HeartbeatInterval: time.Minute,
+			OfflineAfter:      5 * time.Minute,
+			LowBattery:        20,
+			MaxClockSkew:      time.Minute,
+			MaxQueueDepth:     100,
+			SecretGrace:       time.Hour,
+		},
+	}
+	services := NewServices(db, nil, nil, nil, nil, logger.NewLogger("panic", ""), cfg)
+
+	router := gin.New()
+	SetupRoutes(router, services)
+
+	env := &testEnv{
+		router:   router,
+		services: services,
+		users:    repositories.NewUserRepository(db),
+		sessions: repositories.NewSessionRepository(db),
+		userIDs:  make(map[string]int64),
+	}
+	for _, role := range []string{models.RoleAdmin, models.RoleOperator, models.RoleAuditor, models.RoleTrustee} {
+		env.userIDs[role] = env.createUser(t, role, "")
+	}
+	return env
+}
+
+func (env *testEnv) createUser(t *testing.T, role, permissions string) int64 {
+	t.Helper()
+
+	env.request++
+	user := &database.User{
+		Username:     fmt.Sprintf("%s-%d", role, env.request),
+		Email:        fmt.Sprintf("%s-%d@example.com", role, env.request),
+		PasswordHash: "x",
+		Role:         role,
+		Permissions:  permissions,
+	}
+	require.NoError(t, env.users.Create(user))
+	return user.ID
+}
+
+// tokenFor issues a token for the seeded user of the given role, or a device token for terminals
+func (env *testEnv) tokenFor(t *testing.T, role string) string {
+	t.Helper()
+
+	if role == models.RoleTerminal {
+		return signToken(t, "TERM-001", models.RoleTerminal, "")
+	}
+	return env.userToken(t, env.userIDs[role], role)
+}
+
+// userToken opens a session for the user and issues a token bound to it
+func (env *testEnv) userToken(t *testing.T, userID int64, role string) string {
+	t.Helper()
+
+	sessionID := fmt.Sprintf("session-%d-%d", userID, env.request)
+	env.request++
+	require.NoError(t, env.sessions.Create(&database.Session{
+		ID:        sessionID,
+		UserID:    userID,
+		Data:      "{}",
+		ExpiresAt: time.Now().Add(time.Hour),
+		CreatedAt: time.Now(),
+	}))
+	return signToken(t, strconv.FormatInt(userID, 10), role, sessionID)
+}
+
+func signToken(t *testing.T, userID, role, sessionID string) string {
+	t.Helper()
+
+	claims := jwt.MapClaims{
+		"user_id": userID,
+		"role":    role,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}
+	if sessionID != "" {
+		claims["session_id"] = sessionID
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString([]byte(testJWTSecret))
+	require.NoError(t, err)
+	return signed
+}
+
+// terminalToken issues a terminal token that claims the given polling unit,
+// as the token endpoint does
+func terminalToken(t *testing.T, deviceID, pollingUnitID string) string {
+	t.Helper()
+
+	claims := jwt.MapClaims{
+		"user_id":         deviceID,
+		"role":            models.RoleTerminal,
+		"polling_unit_id": pollingUnitID,
+		"exp":             time.Now().Add(time.Hour).Unix(),
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testJWTSecret))
+	require.NoError(t, err)
+	return signed
+}
+
+// do sends a request with the given token. Each request uses its own client
+// address so the rate limiter does not interfere.
+func (env *testEnv) do(method, path, token string) *httptest.ResponseRecorder {
+	env.request++
+
+	if token != "" && strings.HasPrefix(path, "/api/v1/ws/") {
+		path += "?token=" + token
+	}
+	req := httptest.NewRequest(method, path, strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = fmt.Sprintf("10.%d.%d.%d:40000", env.request/65536%256, env.request/256%256, env.request%256)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+	return w
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestProtectedRoutesEnforceRoles(t *testing.T) {
+	env := newTestEnv(t)
+	// Terminal routes act on the calling terminal's record
+	env.registerTerminal(t, "TERM-001", true)
+	roles := []string{noRole, models.RoleAdmin, models.RoleOperator, models.RoleAuditor, models.RoleTerminal, models.RoleTrustee}
+
+	for _, route := range protectedRoutes {
+		for _, role := range roles {
+			route, role := route, role
+			t.Run(fmt.Sprintf("%s %s as %s", route.method, route.path, role), func(t *testing.T) {
+				token := ""
+				if role != noRole {
+					token = env.tokenFor(t, role)
+				}
+
+				w := env.do(route.method, route.path, token)
+
+				switch {
+				case role == noRole:
+					assert.Equal(t, http.StatusUnauthorized, w.Code)
+				case contains(route.allowed, role):
+					assert.NotEqual(t, http.StatusUnauthorized, w.Code)
+					assert.NotEqual(t, http.StatusForbidden, w.Code)
+				default:
+					assert.Equal(t, http.StatusForbidden, w.Code)
+				}
+			})
+		}
+	}
+}
+
+func TestPublicRoutesDoNotRequireAuth(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, route := range publicRoutes {
+		w := env.do(route.method, route.path, "")
+		assert.NotEqual(t, http.StatusUnauthorized, w.Code, "%s %s", route.method, route.path)
+		assert.NotEqual(t, http.StatusForbidden, w.Code, "%s %s", route.method, route.path)
+	}
+}
+
+func TestRoleIsTakenFromUsersTable(t *testing.T) {
+	env := newTestEnv(t)
+
+	// An operator token claiming the admin role is still treated as an operator
+	forged := env.userToken(t, env.userIDs[models.RoleOperator], models.RoleAdmin)
+	w := env.do("POST", "/api/v1/admin/votes/1/invalidate", forged)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Deactivated users are rejected even with a valid token
+	token := env.tokenFor(t, models.RoleAuditor)
+	require.NoError(t, env.users.DeactivateUser(env.userIDs[models.RoleAuditor]))
+	w = env.do("GET", "/api/v1/audit/logs", token)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Unknown users are rejected
+	w = env.do("GET", "/api/v1/audit/logs", signToken(t, "9999", models.RoleAdmin, "unknown"))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// User tokens without a live session are rejected
+	adminID := strconv.FormatInt(env.userIDs[models.RoleAdmin], 10)
+	w = env.do("GET", "/api/v1/audit/logs", signToken(t, adminID, models.RoleAdmin, ""))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = env.do("GET", "/api/v1/audit/logs", signToken(t, adminID, models.RoleAdmin, "revoked"))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Tokens signed with another secret are rejected
+	other := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": "1", "role": models.RoleAdmin, "exp": time.Now().Add(time.Hour).Unix(),
+	})
+	signed, err := other.SignedString([]byte("wrong-secret"))
+	require.NoError(t, err)
+	w = env.do("GET", "/api/v1/audit/logs", signed)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestExtraPermissionsExtendRole(t *testing.T) {
+	env := newTestEnv(t)
+
+	// An operator granted audit:read can read audit logs but still cannot manage elections
+	id := env.createUser(t, models.RoleOperator, `["`+models.PermAuditRead+`"]`)
+	token := env.userToken(t, id, models.RoleOperator)
+
+	w := env.do("GET", "/api/v1/audit/logs", token)
+	assert.NotEqual(t, http.StatusForbidden, w.Code)
+
+	w = env.do("POST", "/api/v1/admin/elections/1/start", token)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
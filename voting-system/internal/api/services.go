@@ -53,6 +53,24 @@ type Services struct {
 	auditLogRepository  *repositories.AuditLogRepository
 	terminalRepository  *repositories.TerminalRepository
 	userRepository      *repositories.UserRepository
+	sessionRepository   *repositories.SessionRepository
+	loginAttemptRepo    *repositories.LoginAttemptRepository
+	passwordResetRepo   *repositories.PasswordResetRepository
+	twoFactorRepository *repositories.TwoFactorRepository
+	electionKeyRepo     *repositories.ElectionKeyRepository
+	ceremonyRepository  *repositories.CeremonyRepository
+	journalRepository   *repositories.TerminalJournalRepository
+	voteNonceRepository *repositories.VoteNonceRepository
+	terminalAlertRepo   *repositories.TerminalAlertRepository
+	terminalConfigRepo  *repositories.TerminalConfigRepository
+	enrolmentRepo       *repositories.TerminalEnrolmentRepository
+	terminalSecretRepo  *repositories.TerminalSecretRepository
+	voterTemplateRepo   *repositories.VoterTemplateRepository
+	biometricAttempts   *repositories.BiometricAttemptRepository
+	electionSaltRepo    *repositories.ElectionSaltRepository
+	electionVoterRepo   *repositories.ElectionVoterRepository
+	electionRollRepo    *repositories.ElectionRollRepository
+	voterImportRepo     *repositories.VoterImportRepository
 }
 
 // CandidateRepository returns the candidate repository instance
@@ -136,6 +154,24 @@ func NewServices(
 	services.auditLogRepository = repositories.NewAuditLogRepository(db)
 	services.terminalRepository = repositories.NewTerminalRepository(db)
 	services.userRepository = repositories.NewUserRepository(db)
+	services.sessionRepository = repositories.NewSessionRepository(db)
+	services.loginAttemptRepo = repositories.NewLoginAttemptRepository(db)
+	services.passwordResetRepo = repositories.NewPasswordResetRepository(db)
+	services.twoFactorRepository = repositories.NewTwoFactorRepository(db)
+	services.electionKeyRepo = repositories.NewElectionKeyRepository(db)
+	services.ceremonyRepository = repositories.NewCeremonyRepository(db)
+	services.journalRepository = repositories.NewTerminalJournalRepository(db)
+	services.voteNonceRepository = repositories.NewVoteNonceRepository(db)
+	services.terminalAlertRepo = repositories.NewTerminalAlertRepository(db)
+	services.terminalConfigRepo = repositories.NewTerminalConfigRepository(db)
+	services.enrolmentRepo = repositories.NewTerminalEnrolmentRepository(db)
+	services.terminalSecretRepo = repositories.NewTerminalSecretRepository(db)
+	services.voterTemplateRepo = repositories.NewVoterTemplateRepository(db)
+	services.biometricAttempts = repositories.NewBiometricAttemptRepository(db)
+	services.electionSaltRepo = repositories.NewElectionSaltRepository(db)
+	services.electionVoterRepo = repositories.NewElectionVoterRepository(db)
+	services.electionRollRepo = repositories.NewElectionRollRepository(db)
+	services.voterImportRepo = repositories.NewVoterImportRepository(db)
 
 	return services
 }
@@ -244,6 +280,9 @@ func (s *Services) setupEventCallbacks() {
 			// Vote sync success - broadcast to WebSocket clients
 			// s.WSHub.BroadcastVoteSync(voteData, txHash)
 			s.Logger.Info("Vote synced successfully: %s", txHash)
+			if err := s.voteRepository.UpdateVoteSync(voteData.VerificationHash, txHash, 0); err != nil {
+				s.Logger.Error("Failed to update vote sync status: %v", err)
+			}
 		},
 		func(voteData blockchain.VoteData, err error) {
 			// Vote sync failed
@@ -359,6 +398,78 @@ func (s *Services) UserRepository() *repositories.UserRepository {
 	return s.userRepository
 }
 
+func (s *Services) SessionRepository() *repositories.SessionRepository {
+	return s.sessionRepository
+}
+
+func (s *Services) LoginAttemptRepository() *repositories.LoginAttemptRepository {
+	return s.loginAttemptRepo
+}
+
+func (s *Services) PasswordResetRepository() *repositories.PasswordResetRepository {
+	return s.passwordResetRepo
+}
+
+func (s *Services) TwoFactorRepository() *repositories.TwoFactorRepository {
+	return s.twoFactorRepository
+}
+
+func (s *Services) ElectionKeyRepository() *repositories.ElectionKeyRepository {
+	return s.electionKeyRepo
+}
+
+func (s *Services) CeremonyRepository() *repositories.CeremonyRepository {
+	return s.ceremonyRepository
+}
+
+func (s *Services) TerminalJournalRepository() *repositories.TerminalJournalRepository {
+	return s.journalRepository
+}
+
+func (s *Services) VoteNonceRepository() *repositories.VoteNonceRepository {
+	return s.voteNonceRepository
+}
+
+func (s *Services) TerminalAlertRepository() *repositories.TerminalAlertRepository {
+	return s.terminalAlertRepo
+}
+
+func (s *Services) TerminalConfigRepository() *repositories.TerminalConfigRepository {
+	return s.terminalConfigRepo
+}
+
+func (s *Services) TerminalEnrolmentRepository() *repositories.TerminalEnrolmentRepository {
+	return s.enrolmentRepo
+}
+
+func (s *Services) TerminalSecretRepository() *repositories.TerminalSecretRepository {
+	return s.terminalSecretRepo
+}
+
+func (s *Services) VoterTemplateRepository() *repositories.VoterTemplateRepository {
+	return s.voterTemplateRepo
+}
+
+func (s *Services) BiometricAttemptRepository() *repositories.BiometricAttemptRepository {
+	return s.biometricAttempts
+}
+
+func (s *Services) ElectionSaltRepository() *repositories.ElectionSaltRepository {
+	return s.electionSaltRepo
+}
+
+func (s *Services) ElectionVoterRepository() *repositories.ElectionVoterRepository {
+	return s.electionVoterRepo
+}
+
+func (s *Services) ElectionRollRepository() *repositories.ElectionRollRepository {
+	return s.electionRollRepo
+}
+
+func (s *Services) VoterImportRepository() *repositories.VoterImportRepository {
+	return s.voterImportRepo
+}
+
 // GetConfig returns the loaded configuration
 func (s *Services) GetConfig() *config.Config {
 	return s.Config
@@ -489,13 +600,17 @@ func (s *Services) ValidateToken(token string) (*interfaces.Claims, error) {
 		sessionID = sid
 	}
 
+	// Extract the terminal's polling unit (terminal tokens only)
+	pollingUnitID, _ := claims["polling_unit_id"].(string)
+
 	// Create and return claims
 	result := &interfaces.Claims{
-		UserID:      userID,
-		Role:        role,
-		Permissions: permissions,
-		SessionID:   sessionID,
-		ExpiresAt:   int64(exp),
+		UserID:        userID,
+		Role:          role,
+		Permissions:   permissions,
+		SessionID:     sessionID,
+		PollingUnitID: pollingUnitID,
+		ExpiresAt:     int64(exp),
 	}
 
 	s.Logger.Info("Token validated successfully for user: %s, role: %s", userID, role)
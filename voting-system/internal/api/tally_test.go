@@ -0,0 +1,198 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"voting-system/internal/api/models"
+	"voting-system/internal/api/types"
+	"voting-system/internal/database"
+	"voting-system/internal/tally"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createCachedElection stores an election and its candidates in the DB cache
+func (env *testEnv) createCachedElection(t *testing.T, blockchainID string, candidates ...string) {
+	t.Helper()
+
+	election := &database.Election{
+		BlockchainID: blockchainID,
+		Name:         "Election " + blockchainID,
+		StartTime:    time.Now(),
+		EndTime:      time.Now().Add(time.Hour),
+	}
+	require.NoError(t, env.services.ElectionRepository().CreateElection(election))
+	for _, candidate := range candidates {
+		require.NoError(t, env.services.CandidateRepository().Insert(election.ID, candidate, "", ""))
+	}
+}
+
+// storeSyncedBallot records an encrypted ballot as if it had been cast and synced
+func (env *testEnv) storeSyncedBallot(t *testing.T, electionID int64, hash string, ballot *tally.Ballot) {
+	t.Helper()
+
+	encoded := ballot.String()
+	require.NoError(t, env.services.VoteRepository().InsertVote(&database.Vote{
+		VerificationHash: hash,
+		ElectionID:       electionID,
+		PollingUnitID:    "PU-1",
+		Ballot:           &encoded,
+		Status:           "pending",
+	}))
+	require.NoError(t, env.services.VoteRepository().UpdateVoteSync(hash, "0xtx"+hash, 1))
+}
+
+func decodeData(t *testing.T, body []byte, data interface{}) {
+	t.Helper()
+
+	resp := struct {
+		Data interface{} `json:"data"`
+	}{Data: data}
+	require.NoError(t, json.Unmarshal(body, &resp))
+}
+
+func TestEncryptedBallotTally(t *testing.T) {
+	env := newTestEnv(t)
+	admin := env.tokenFor(t, models.RoleAdmin)
+	candidates := []string{"APC", "PDP", "LP"}
+	env.createCachedElection(t, "7", candidates...)
+
+	sk, err := tally.GenerateKey(nil)
+	require.NoError(t, err)
+	shares, err := tally.SplitKey(sk, 2, 3, nil)
+	require.NoError(t, err)
+	verificationKeys := make([]string, len(shares))
+	for i, share := range shares {
+		verificationKeys[i] = tally.EncodePoint(share.VerificationKey())
+	}
+
+	// Verification keys must match the public key
+	w := env.doJSON("PUT", "/api/v1/admin/elections/7/ballot-key", admin, types.BallotKeyRequest{
+		PublicKey:        sk.PublicKey.String(),
+		Threshold:        2,
+		VerificationKeys: []string{verificationKeys[1], verificationKeys[0], verificationKeys[2]},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	w = env.doJSON("PUT", "/api/v1/admin/elections/7/ballot-key", admin, types.BallotKeyRequest{
+		PublicKey:        sk.PublicKey.String(),
+		Threshold:        2,
+		VerificationKeys: verificationKeys,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// Terminals fetch the key and candidate order without a token
+	w = env.do("GET", "/api/v1/public/election/7/ballot-key", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var key types.BallotKey
+	decodeData(t, w.Body.Bytes(), &key)
+	assert.Equal(t, candidates, key.Candidates)
+	assert.Equal(t, database.ElectionKeyOpen, key.Status)
+	pk, err := tally.ParsePublicKey(key.PublicKey)
+	require.NoError(t, err)
+	ctx := []byte(key.Context)
+	assert.Equal(t, tally.BallotContext("7", candidates), ctx)
+
+	// Neither the tally nor the results are available while voting is open
+	assert.Equal(t, http.StatusConflict, env.do("GET", "/api/v1/admin/elections/7/tally", admin).Code)
+	assert.Equal(t, http.StatusConflict, env.do("GET", "/api/v1/public/election/7/results", "").Code)
+
+	for i, choice := range []int{0, 2, 2, 1, 2} {
+		ballot, err := tally.EncryptBallot(pk, ctx, len(candidates), choice, nil)
+		require.NoError(t, err)
+		env.storeSyncedBallot(t, 7, fmt.Sprintf("voter-%d", i), ballot)
+	}
+
+	// The key is frozen once ballots exist
+	w = env.doJSON("PUT", "/api/v1/admin/elections/7/ballot-key", admin, types.BallotKeyRequest{
+		PublicKey: sk.PublicKey.String(), Threshold: 2, VerificationKeys: verificationKeys,
+	})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Ending the election closes the key
+	require.NoError(t, env.services.ElectionKeyRepository().Close("7"))
+
+	w = env.do("GET", "/api/v1/admin/elections/7/tally", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var published types.EncryptedTally
+	decodeData(t, w.Body.Bytes(), &published)
+	require.NotNil(t, published.Tally)
+	assert.Equal(t, 5, published.Tally.Ballots)
+	assert.False(t, published.Decrypted)
+	assert.Equal(t, http.StatusConflict, env.do("GET", "/api/v1/public/election/7/results", "").Code)
+
+	// Trustees decrypt the published tally offline
+	result, err := tally.ParseEncryptedTally(published.Tally.String(), []byte(published.Context))
+	require.NoError(t, err)
+	partials := make([]*tally.DecryptionShare, len(shares))
+	for i, share := range shares {
+		partials[i], err = share.PartialDecrypt(result, nil)
+		require.NoError(t, err)
+	}
+
+	// One trustee is not enough
+	w = env.doJSON("POST", "/api/v1/admin/elections/7/tally/decrypt", admin, types.DecryptTallyRequest{
+		Shares: partials[:1],
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	// A share presented under another trustee's index is rejected
+	forged := *partials[0]
+	forged.Index = 2
+	w = env.doJSON("POST", "/api/v1/admin/elections/7/tally/decrypt", admin, types.DecryptTallyRequest{
+		Shares: []*tally.DecryptionShare{&forged, partials[2]},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	w = env.doJSON("POST", "/api/v1/admin/elections/7/tally/decrypt", admin, types.DecryptTallyRequest{
+		Shares: []*tally.DecryptionShare{partials[2], partials[0]},
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	want := map[string]int64{"APC": 1, "PDP": 1, "LP": 3}
+	var decrypted types.TallyResults
+	decodeData(t, w.Body.Bytes(), &decrypted)
+	assert.Equal(t, want, decrypted.Results)
+
+	// Results are public once decrypted, and decryption happens once
+	w = env.do("GET", "/api/v1/public/election/7/results", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var results types.TallyResults
+	decodeData(t, w.Body.Bytes(), &results)
+	assert.Equal(t, want, results.Results)
+	assert.Equal(t, 5, results.Ballots)
+
+	w = env.doJSON("POST", "/api/v1/admin/elections/7/tally/decrypt", admin, types.DecryptTallyRequest{
+		Shares: partials[1:],
+	})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	logs, err := env.services.AuditLogRepository().GetAuditLogsByAction("tally_decrypted", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Contains(t, logs[0].Details, "trustees 3,1")
+}
+
+func TestBallotKeyRequiresCandidates(t *testing.T) {
+	env := newTestEnv(t)
+	admin := env.tokenFor(t, models.RoleAdmin)
+
+	sk, err := tally.GenerateKey(nil)
+	require.NoError(t, err)
+	shares, err := tally.SplitKey(sk, 1, 1, nil)
+	require.NoError(t, err)
+
+	// Election 9 has no cached candidates and there is no blockchain in tests
+	w := env.doJSON("PUT", "/api/v1/admin/elections/9/ballot-key", admin, types.BallotKeyRequest{
+		PublicKey:        sk.PublicKey.String(),
+		Threshold:        1,
+		VerificationKeys: []string{tally.EncodePoint(shares[0].VerificationKey())},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	assert.Equal(t, http.StatusNotFound, env.do("GET", "/api/v1/public/election/9/ballot-key", "").Code)
+}
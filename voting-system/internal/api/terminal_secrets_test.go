@@ -0,0 +1,135 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"voting-system/internal/api/models"
+	"voting-system/internal/api/types"
+	"voting-system/internal/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rotateTerminalSecret issues a new secret for a terminal and returns it
+func rotateTerminalSecret(t *testing.T, env *testEnv, terminalID string) string {
+	t.Helper()
+
+	w := env.do("POST", "/api/v1/admin/terminals/"+terminalID+"/secret", env.tokenFor(t, models.RoleAdmin))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var resp types.TerminalSecretResponse
+	decodeData(t, w.Body.Bytes(), &resp)
+	require.NotEmpty(t, resp.Secret)
+	return resp.Secret
+}
+
+// terminalTokenRequest builds a token request signed with secret
+func terminalTokenRequest(t *testing.T, deviceID, secret string) types.TerminalTokenRequest {
+	t.Helper()
+
+	nonce, err := auth.RandomToken(16)
+	require.NoError(t, err)
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	return types.TerminalTokenRequest{
+		DeviceID:  deviceID,
+		Timestamp: ts,
+		Nonce:     nonce,
+		Signature: auth.TerminalTokenMAC(secret, ts, nonce, deviceID),
+	}
+}
+
+func TestTerminalTokenRequiresTerminalSecret(t *testing.T) {
+	env := newTestEnv(t)
+	t.Setenv("JWT_SECRET", testJWTSecret)
+	env.registerTerminal(t, "TERM-001", true)
+
+	request := func(req types.TerminalTokenRequest) (int, string) {
+		w := env.doJSON("POST", "/api/v1/public/token/terminal", "", req)
+		if w.Code == http.StatusOK {
+			var resp struct {
+				Token string `json:"token"`
+			}
+			decodeData(t, w.Body.Bytes(), &resp)
+			return w.Code, resp.Token
+		}
+		var failure types.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &failure), w.Body.String())
+		return w.Code, failure.Error
+	}
+
+	// Without a secret of its own the terminal cannot sign in at all
+	code, reason := request(types.TerminalTokenRequest{DeviceID: "TERM-001"})
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "terminal_secret_missing", reason)
+
+	first := rotateTerminalSecret(t, env, "TERM-001")
+	stored, err := env.services.TerminalSecretRepository().Active("TERM-001")
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.NotContains(t, stored[0].SecretSealed, first)
+
+	code, reason = request(types.TerminalTokenRequest{DeviceID: "TERM-001"})
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Equal(t, "auth_required", reason)
+
+	forged := terminalTokenRequest(t, "TERM-001", "some-other-secret")
+	code, reason = request(forged)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Equal(t, "invalid_signature", reason)
+
+	stale := terminalTokenRequest(t, "TERM-001", first)
+	stale.Timestamp = strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	stale.Signature = auth.TerminalTokenMAC(first, stale.Timestamp, stale.Nonce, "TERM-001")
+	code, reason = request(stale)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Equal(t, "stale_request", reason)
+
+	// A signed request works once and the token carries the polling unit
+	signed := terminalTokenRequest(t, "TERM-001", first)
+	code, token := request(signed)
+	require.Equal(t, http.StatusOK, code, token)
+	claims, err := env.services.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "TERM-001", claims.UserID)
+	assert.Equal(t, "PU-1", claims.PollingUnitID)
+
+	code, reason = request(signed)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Equal(t, "replayed_request", reason)
+
+	// After a rotation the old secret keeps working for the grace period
+	second := rotateTerminalSecret(t, env, "TERM-001")
+	code, _ = request(terminalTokenRequest(t, "TERM-001", first))
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = request(terminalTokenRequest(t, "TERM-001", second))
+	assert.Equal(t, http.StatusOK, code)
+
+	// Without a grace period it stops at once
+	env.services.GetConfig().Fleet.SecretGrace = 0
+	third := rotateTerminalSecret(t, env, "TERM-001")
+	code, reason = request(terminalTokenRequest(t, "TERM-001", second))
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Equal(t, "invalid_signature", reason)
+	code, _ = request(terminalTokenRequest(t, "TERM-001", third))
+	assert.Equal(t, http.StatusOK, code)
+
+	// Revocation takes every secret away
+	w := env.do("DELETE", "/api/v1/admin/terminals/TERM-001/secret", env.tokenFor(t, models.RoleAdmin))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	code, reason = request(terminalTokenRequest(t, "TERM-001", third))
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "terminal_secret_missing", reason)
+
+	var actions []string
+	logs, err := env.services.AuditLogRepository().GetAuditLogsByUser("TERM-001", 10, 0)
+	require.NoError(t, err)
+	for _, log := range logs {
+		actions = append(actions, log.Action)
+	}
+	assert.Contains(t, actions, "terminal_secret_rotated")
+	assert.Contains(t, actions, "terminal_secret_revoked")
+}
@@ -0,0 +1,345 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"voting-system/internal/api/models"
+	"voting-system/internal/api/types"
+	"voting-system/internal/database"
+	"voting-system/internal/fleet"
+	"voting-system/internal/votesig"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func heartbeat(t *testing.T, env *testEnv, req types.TerminalHeartbeat) types.HeartbeatResponse {
+	t.Helper()
+
+	w := env.doJSON("POST", "/api/v1/terminal/TERM-001/heartbeat", env.tokenFor(t, models.RoleTerminal), req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp types.HeartbeatResponse
+	decodeData(t, w.Body.Bytes(), &resp)
+	return resp
+}
+
+func terminalStatus(t *testing.T, env *testEnv) types.TerminalStatus {
+	t.Helper()
+
+	w := env.do("GET", "/api/v1/admin/terminals/TERM-001/status", env.tokenFor(t, models.RoleAdmin))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var status types.TerminalStatus
+	decodeData(t, w.Body.Bytes(), &status)
+	return status
+}
+
+func TestTerminalHeartbeatRaisesAndResolvesAlerts(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerTerminal(t, "TERM-001", true)
+	terminal := env.tokenFor(t, models.RoleTerminal)
+
+	// Terminals only report for themselves
+	w := env.doJSON("POST", "/api/v1/terminal/TERM-002/heartbeat", terminal,
+		types.TerminalHeartbeat{Timestamp: time.Now().UnixMilli()})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = env.doJSON("POST", "/api/v1/terminal/TERM-001/heartbeat", terminal, types.TerminalHeartbeat{})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	battery := 12
+	resp := heartbeat(t, env, types.TerminalHeartbeat{
+		BatteryLevel:    &battery,
+		FirmwareVersion: "1.4.0",
+		QueueDepth:      150,
+		Timestamp:       time.Now().Add(-3 * time.Minute).UnixMilli(),
+	})
+	assert.ElementsMatch(t, []string{fleet.AlertLowBattery, fleet.AlertClockSkew, fleet.AlertQueueBacklog}, resp.Alerts)
+	assert.InDelta(t, -3*time.Minute.Milliseconds(), resp.ClockSkewMs, 5000)
+	assert.NotEmpty(t, resp.ConfigVersion)
+
+	status := terminalStatus(t, env)
+	assert.True(t, status.Online)
+	assert.NotNil(t, status.LastHeartbeat)
+	require.NotNil(t, status.BatteryLevel)
+	assert.Equal(t, 12, *status.BatteryLevel)
+	assert.Equal(t, "1.4.0", status.FirmwareVersion)
+	assert.Equal(t, 150, status.QueueDepth)
+	assert.Len(t, status.Alerts, 3)
+
+	// Repeating a reading does not open a second alert; recovering resolves it
+	heartbeat(t, env, types.TerminalHeartbeat{
+		BatteryLevel: &battery, Charging: true, QueueDepth: 150, Timestamp: time.Now().UnixMilli(),
+	})
+	status = terminalStatus(t, env)
+	require.Len(t, status.Alerts, 1)
+	assert.Equal(t, fleet.AlertQueueBacklog, status.Alerts[0].Kind)
+
+	w = env.do("GET", "/api/v1/admin/terminals/alerts?all=true", env.tokenFor(t, models.RoleAdmin))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list struct {
+		Alerts []types.TerminalAlert `json:"alerts"`
+	}
+	decodeData(t, w.Body.Bytes(), &list)
+	assert.Len(t, list.Alerts, 3)
+
+	logs, err := env.services.AuditLogRepository().GetAuditLogs(10, 0, "terminal_alert_resolved", "", nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, logs, 2)
+}
+
+func TestFleetMonitorMarksSilentTerminalsOffline(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerTerminal(t, "TERM-001", true)
+	env.registerTerminal(t, "TERM-002", true)
+	heartbeat(t, env, types.TerminalHeartbeat{Timestamp: time.Now().UnixMilli()})
+
+	monitor := fleet.NewMonitor(env.services.TerminalRepository(), env.services.TerminalAlertRepository(),
+		env.services.AuditLogRepository(), env.services.GetConfig().Fleet, env.services.GetLogger())
+
+	// TERM-002 never reported, so it was never online
+	offline, err := monitor.Check(time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, offline)
+
+	offline, err = monitor.Check(time.Now().Add(6 * time.Minute))
+	require.NoError(t, err)
+	require.Len(t, offline, 1)
+	assert.Equal(t, "TERM-001", offline[0].ID)
+
+	status := terminalStatus(t, env)
+	assert.False(t, status.Online)
+	require.Len(t, status.Alerts, 1)
+	assert.Equal(t, fleet.AlertOffline, status.Alerts[0].Kind)
+
+	// Already offline terminals are not reported again
+	offline, err = monitor.Check(time.Now().Add(12 * time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, offline)
+
+	heartbeat(t, env, types.TerminalHeartbeat{Timestamp: time.Now().UnixMilli()})
+	status = terminalStatus(t, env)
+	assert.True(t, status.Online)
+	assert.Empty(t, status.Alerts)
+}
+
+func TestTerminalRemoteConfig(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerTerminal(t, "TERM-001", true)
+	terminal := env.tokenFor(t, models.RoleTerminal)
+
+	getConfig := func() types.TerminalRemoteConfig {
+		w := env.do("GET", "/api/v1/terminal/TERM-001/config", terminal)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var remote types.TerminalRemoteConfig
+		decodeData(t, w.Body.Bytes(), &remote)
+		return remote
+	}
+
+	remote := getConfig()
+	assert.Equal(t, "PU-1", remote.PollingUnitID)
+	assert.Empty(t, remote.ElectionID)
+	assert.Equal(t, 0.85, remote.MatchThreshold)
+	assert.Equal(t, 60, remote.HeartbeatInterval)
+	assert.NotEmpty(t, remote.FingerprintKey)
+	assert.Empty(t, remote.VerificationSalt, "there is no salt outside an election")
+
+	env.createCachedElection(t, "4", "APC", "PDP")
+	election, err := env.services.ElectionRepository().GetElectionByBlockchainID("4")
+	require.NoError(t, err)
+	require.NoError(t, env.services.ElectionRepository().UpdateElectionStatus(election.ID, true))
+
+	maxAttempts := 5
+	w := env.doJSON("PUT", "/api/v1/admin/terminals/TERM-001/config", env.tokenFor(t, models.RoleAdmin),
+		types.TerminalConfigOverrides{MaxAttempts: &maxAttempts})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var shown types.TerminalRemoteConfig
+	decodeData(t, w.Body.Bytes(), &shown)
+	assert.Empty(t, shown.VerificationSalt, "admins are not shown the terminal's secrets")
+	assert.Empty(t, shown.FingerprintKey)
+
+	updated := getConfig()
+	assert.Equal(t, "4", updated.ElectionID)
+	assert.Equal(t, []string{"APC", "PDP"}, updated.Candidates)
+	assert.Equal(t, 5, updated.MaxAttempts)
+	assert.Equal(t, 0.85, updated.MatchThreshold)
+	assert.NotEqual(t, remote.Version, updated.Version)
+
+	// The heartbeat carries the version so terminals know to refetch
+	resp := heartbeat(t, env, types.TerminalHeartbeat{Timestamp: time.Now().UnixMilli()})
+	assert.Equal(t, updated.Version, resp.ConfigVersion)
+
+	// Each election has its own salt, so a voter's hashes cannot be linked across elections
+	require.NotEmpty(t, updated.VerificationSalt)
+	assert.Equal(t, updated.VerificationSalt, getConfig().VerificationSalt)
+	require.NoError(t, env.services.ElectionRepository().UpdateElectionStatus(election.ID, false))
+	env.startElection(t, "5")
+	next := getConfig()
+	assert.Equal(t, testSalt, next.VerificationSalt)
+	assert.NotEqual(t, votesig.VerificationHash(updated.VerificationSalt, "12345678901"),
+		votesig.VerificationHash(next.VerificationSalt, "12345678901"))
+
+	w = env.do("GET", "/api/v1/terminal/TERM-002/config", terminal)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = env.doJSON("PUT", "/api/v1/admin/terminals/TERM-001/config", env.tokenFor(t, models.RoleAdmin),
+		map[string]interface{}{"match_threshold": 1.5})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestTerminalStatusCountsTodaysVotes(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerTerminal(t, "TERM-001", true)
+
+	for _, terminalID := range []string{"TERM-001", "TERM-001", "TERM-002"} {
+		require.NoError(t, env.services.VoteRepository().InsertVote(&database.Vote{
+			VerificationHash: "hash-" + terminalID + time.Now().String(), ElectionID: 1, PollingUnitID: "PU-1",
+			CandidateID: "APC", TerminalID: terminalID, Status: "pending",
+		}))
+	}
+
+	w := env.do("GET", "/api/v1/terminal/TERM-001/status", env.tokenFor(t, models.RoleTerminal))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var status types.TerminalStatus
+	decodeData(t, w.Body.Bytes(), &status)
+	assert.Equal(t, "TERM-001", status.TerminalID)
+	assert.Equal(t, 2, status.VotesToday)
+	assert.False(t, status.Online)
+
+	w = env.do("GET", "/api/v1/admin/terminals/TERM-404/status", env.tokenFor(t, models.RoleAdmin))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAdminListsTerminalsWithFilters(t *testing.T) {
+	env := newTestEnv(t)
+	admin := env.tokenFor(t, models.RoleAdmin)
+	env.registerTerminal(t, "TERM-001", true)
+	env.registerTerminal(t, "TERM-002", false)
+	heartbeat(t, env, types.TerminalHeartbeat{Timestamp: time.Now().UnixMilli()})
+
+	list := func(query string) ([]database.Terminal, int) {
+		w := env.do("GET", "/api/v1/admin/terminals/"+query, admin)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp struct {
+			Terminals []database.Terminal `json:"terminals"`
+			Total     int                 `json:"total"`
+		}
+		decodeData(t, w.Body.Bytes(), &resp)
+		return resp.Terminals, resp.Total
+	}
+
+	terminals, total := list("?polling_unit_id=PU-1")
+	assert.Len(t, terminals, 2)
+	assert.Equal(t, 2, total)
+
+	terminals, _ = list("?authorized=false")
+	require.Len(t, terminals, 1)
+	assert.Equal(t, "TERM-002", terminals[0].ID)
+
+	terminals, _ = list("?online=true")
+	require.Len(t, terminals, 1)
+	assert.Equal(t, "TERM-001", terminals[0].ID)
+
+	terminals, total = list("?q=term&limit=1")
+	assert.Len(t, terminals, 1)
+	assert.Equal(t, 2, total)
+
+	w := env.do("GET", "/api/v1/admin/terminals/?online=maybe", admin)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDeauthorizeAndDecommissionTerminal(t *testing.T) {
+	env := newTestEnv(t)
+	env.startElection(t, "1")
+	admin := env.tokenFor(t, models.RoleAdmin)
+	key := env.registerTerminal(t, "TERM-001", true)
+	heartbeat(t, env, types.TerminalHeartbeat{Timestamp: time.Now().Add(-time.Hour).UnixMilli()})
+
+	w := env.doJSON("POST", "/api/v1/admin/terminals/TERM-001/deauthorize", admin,
+		types.TerminalActionRequest{Reason: "reported stolen"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	terminal, err := env.services.TerminalRepository().GetTerminal("TERM-001")
+	require.NoError(t, err)
+	assert.False(t, terminal.Authorized)
+	assert.Equal(t, database.TerminalRevoked, terminal.Status)
+
+	vote := types.VoteRequest{NIN: "12345678901", FingerprintData: "fp", CandidateID: "APC", PollingUnitID: "PU-1"}
+	signVote(t, key, "TERM-001", &vote)
+	code, reason := castError(t, env, vote)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "terminal_not_authorized", reason)
+
+	w = env.do("DELETE", "/api/v1/admin/terminals/TERM-001?reason=retired", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = env.do("DELETE", "/api/v1/admin/terminals/TERM-001", admin)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// A decommissioned terminal is shut out and its alerts are closed
+	assert.Empty(t, terminalStatus(t, env).Alerts)
+	w = env.doJSON("POST", "/api/v1/public/token/terminal", "", map[string]string{"device_id": "TERM-001"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = env.doJSON("POST", "/api/v1/terminal/TERM-001/heartbeat", env.tokenFor(t, models.RoleTerminal),
+		types.TerminalHeartbeat{Timestamp: time.Now().UnixMilli()})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = env.doJSON("POST", "/api/v1/admin/terminals/TERM-001/authorize", admin,
+		map[string]interface{}{"address": terminal.EthAddress, "authorize": true})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// The audit trail records each step, including the refused vote, newest first
+	w = env.do("GET", "/api/v1/admin/terminals/TERM-001/logs", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var trail struct {
+		Logs []database.AuditLog `json:"logs"`
+	}
+	decodeData(t, w.Body.Bytes(), &trail)
+	var actions []string
+	details := map[string]string{}
+	for _, log := range trail.Logs {
+		actions = append(actions, log.Action)
+		details[log.Action] = log.Details
+	}
+	assert.Equal(t, []string{"terminal_decommissioned", "vote_rejected_signature", "terminal_deauthorized", "terminal_alert_raised"}, actions)
+	assert.Contains(t, details["terminal_deauthorized"], "reported stolen")
+	assert.Contains(t, details["terminal_decommissioned"], "retired")
+
+	w = env.do("GET", "/api/v1/admin/terminals/TERM-404/logs", admin)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestBulkAuthorizePollingUnit(t *testing.T) {
+	env := newTestEnv(t)
+	admin := env.tokenFor(t, models.RoleAdmin)
+	env.registerTerminal(t, "TERM-001", false)
+	env.registerTerminal(t, "TERM-002", true)
+	env.registerTerminal(t, "TERM-003", false)
+	require.NoError(t, env.services.TerminalRepository().DecommissionTerminal("TERM-003"))
+
+	w := env.doJSON("POST", "/api/v1/admin/terminals/bulk-authorize", admin, types.BulkAuthorizeRequest{PollingUnitID: "PU-1"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp types.BulkOperationResponse
+	decodeData(t, w.Body.Bytes(), &resp)
+	assert.Equal(t, 1, resp.Summary[types.TerminalOpDone])
+	assert.Equal(t, 2, resp.Summary[types.TerminalOpSkipped])
+
+	statuses := map[string]string{}
+	terminalStatuses := map[string]string{}
+	for _, result := range resp.Results {
+		statuses[result.TerminalID] = result.Status
+		terminalStatuses[result.TerminalID] = result.TerminalStatus
+	}
+	assert.Equal(t, map[string]string{
+		"TERM-001": types.TerminalOpDone, "TERM-002": types.TerminalOpSkipped, "TERM-003": types.TerminalOpSkipped,
+	}, statuses)
+	// Without a blockchain the pending terminal is approved but not yet active
+	assert.Equal(t, map[string]string{
+		"TERM-001": database.TerminalApproved, "TERM-002": database.TerminalActive, "TERM-003": database.TerminalDecommissioned,
+	}, terminalStatuses)
+
+	terminal, err := env.services.TerminalRepository().GetTerminal("TERM-001")
+	require.NoError(t, err)
+	assert.False(t, terminal.Authorized)
+	assert.Equal(t, database.TerminalApproved, terminal.Status)
+	assert.NotEmpty(t, terminal.ApprovedBy)
+
+	w = env.doJSON("POST", "/api/v1/admin/terminals/bulk-authorize", admin, types.BulkAuthorizeRequest{PollingUnitID: "PU-9"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"voting-system/internal/api/models"
+	"voting-system/internal/api/types"
+	"voting-system/internal/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// enrolTwoFactor enrols the token's user and returns the TOTP secret, the time
+// step of the confirming code and the recovery codes
+func enrolTwoFactor(t *testing.T, env *testEnv, token string) (string, int64, []string) {
+	t.Helper()
+
+	w := env.do("POST", "/api/v1/auth/2fa/enroll", token)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var enrolment struct {
+		Data types.TwoFactorEnrollment `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &enrolment))
+	require.NotEmpty(t, enrolment.Data.Secret)
+	assert.Contains(t, enrolment.Data.URI, "otpauth://totp/")
+
+	step := auth.TOTPStep(time.Now())
+	code, err := auth.TOTPCode(enrolment.Data.Secret, step)
+	require.NoError(t, err)
+
+	w = env.doJSON("POST", "/api/v1/auth/2fa/confirm", token, types.TwoFactorCodeRequest{Code: code})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var codes struct {
+		Data types.RecoveryCodes `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &codes))
+	require.Len(t, codes.Data.Codes, 10)
+
+	return enrolment.Data.Secret, step, codes.Data.Codes
+}
+
+func TestAdminMustEnrolInTwoFactor(t *testing.T) {
+	env := newTestEnv(t)
+	env.services.GetConfig().Security.EnableTwoFA = true
+	env.createLoginUser(t, "ivy", models.RoleAdmin)
+
+	w := login(t, env, "ivy", testPassword)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	tokens := decodeTokens(t, w)
+	assert.True(t, tokens.TwoFactorSetupRequired)
+
+	// Until enrolment only the auth routes are reachable
+	assert.Equal(t, http.StatusForbidden, env.do("GET", "/api/v1/admin/dashboard", tokens.AccessToken).Code)
+	assert.Equal(t, http.StatusOK, env.do("GET", "/api/v1/auth/2fa", tokens.AccessToken).Code)
+
+	enrolTwoFactor(t, env, tokens.AccessToken)
+	assert.NotEqual(t, http.StatusForbidden, env.do("GET", "/api/v1/admin/dashboard", tokens.AccessToken).Code)
+
+	// Admins cannot turn 2FA off
+	w = env.doJSON("POST", "/api/v1/auth/2fa/disable", tokens.AccessToken, types.TwoFactorCodeRequest{Code: "000000"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestLoginRequiresSecondFactor(t *testing.T) {
+	env := newTestEnv(t)
+	env.createLoginUser(t, "jack", models.RoleOperator)
+
+	first := decodeTokens(t, login(t, env, "jack", testPassword))
+	assert.False(t, first.TwoFactorSetupRequired)
+	secret, step, recovery := enrolTwoFactor(t, env, first.AccessToken)
+
+	// Password alone is no longer enough
+	w := login(t, env, "jack", testPassword)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "two_factor_required")
+
+	loginWith := func(otp, recoveryCode string) int {
+		return env.doJSON("POST", "/api/v1/public/auth/login", "", types.LoginRequest{
+			Username: "jack", Password: testPassword, OTP: otp, RecoveryCode: recoveryCode,
+		}).Code
+	}
+
+	// The code used to confirm enrolment cannot be replayed, the next one works once
+	confirmed, err := auth.TOTPCode(secret, step)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, loginWith(confirmed, ""))
+
+	next, err := auth.TOTPCode(secret, step+1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, loginWith(next, ""))
+	assert.Equal(t, http.StatusUnauthorized, loginWith(next, ""))
+
+	// Recovery codes are single use
+	assert.Equal(t, http.StatusOK, loginWith("", recovery[0]))
+	assert.Equal(t, http.StatusUnauthorized, loginWith("", recovery[0]))
+}
+
+func TestAdminCanResetTwoFactor(t *testing.T) {
+	env := newTestEnv(t)
+	id := env.createLoginUser(t, "kate", models.RoleAuditor)
+
+	tokens := decodeTokens(t, login(t, env, "kate", testPassword))
+	enrolTwoFactor(t, env, tokens.AccessToken)
+	require.Equal(t, http.StatusUnauthorized, login(t, env, "kate", testPassword).Code)
+
+	admin := env.tokenFor(t, models.RoleAdmin)
+	w := env.do("DELETE", "/api/v1/admin/users/"+strconv.FormatInt(id, 10)+"/2fa", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	assert.Equal(t, http.StatusUnauthorized, env.do("GET", "/api/v1/auth/2fa", tokens.AccessToken).Code)
+	assert.Equal(t, http.StatusOK, login(t, env, "kate", testPassword).Code)
+}
@@ -1,15 +1,27 @@
 package types
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+	"voting-system/internal/biometric"
+	"voting-system/internal/database"
+	"voting-system/internal/tally"
+)
 
-// VoteRequest represents a vote submission request
+// VoteRequest represents a vote submission request. Elections with a ballot
+// key take an encrypted Ballot instead of a CandidateID. The submitting
+// terminal signs the vote with its secp256k1 key (see package votesig).
 type VoteRequest struct {
-	NIN             string `json:"nin" binding:"required"`
-	FingerprintData string `json:"fingerprint_data" binding:"required"`
-	CandidateID     string `json:"candidate_id" binding:"required"`
-	PollingUnitID   string `json:"polling_unit_id" binding:"required"`
-	EncryptedVote   string `json:"encrypted_vote"`
-	Signature       string `json:"signature"`
+	NIN             string        `json:"nin" binding:"required"`
+	FingerprintData string        `json:"fingerprint_data" binding:"required"`
+	CandidateID     string        `json:"candidate_id"`
+	Ballot          *tally.Ballot `json:"ballot,omitempty"`
+	PollingUnitID   string        `json:"polling_unit_id" binding:"required"`
+	ElectionID      string        `json:"election_id,omitempty"` // election the terminal derived the verification hash for
+	EncryptedVote   string        `json:"encrypted_vote"`
+	Nonce           string        `json:"nonce"`     // random per vote, never reused by a terminal
+	Timestamp       int64         `json:"timestamp"` // unix seconds when the terminal signed the vote
+	Signature       string        `json:"signature"` // 65-byte secp256k1 signature, hex
 }
 
 // VoterRegistrationRequest represents a voter registration request
@@ -55,12 +67,121 @@ type SuccessResponse struct {
 
 // VoterStatus represents voter verification status
 type VoterStatus struct {
+	ElectionID  string `json:"election_id"`
 	HasVoted    bool   `json:"has_voted"`
 	VoteID      string `json:"vote_id,omitempty"`
 	Timestamp   int64  `json:"timestamp,omitempty"`
 	PollingUnit string `json:"polling_unit,omitempty"`
 }
 
+// VoterRollProof is a voter's inclusion proof in an election's roll snapshot.
+// It checks against the root published on chain without revealing the NIN.
+type VoterRollProof struct {
+	ElectionID       string   `json:"election_id"`
+	VerificationHash string   `json:"verification_hash"` // bytes32 the voter is recorded under on chain
+	Leaf             string   `json:"leaf"`
+	Proof            []string `json:"proof"`
+	Root             string   `json:"root"`
+}
+
+// VoterRollLeaves is a page of an election's roll leaves, sorted
+type VoterRollLeaves struct {
+	ElectionID string   `json:"election_id"`
+	Root       string   `json:"root"`
+	Total      int      `json:"total"`
+	Limit      int      `json:"limit"`
+	Offset     int      `json:"offset"`
+	Leaves     []string `json:"leaves"`
+}
+
+// VoterImportErrors is a page of the rows a voter import could not take
+type VoterImportErrors struct {
+	JobID  string                      `json:"job_id"`
+	Total  int                         `json:"total"`
+	Limit  int                         `json:"limit"`
+	Offset int                         `json:"offset"`
+	Errors []database.VoterImportError `json:"errors"`
+}
+
+// RosterVoter is a voter on a polling unit's roll, as synced to its terminals
+// so they can check voters while the server is unreachable
+type RosterVoter struct {
+	NIN             string              `json:"nin"`
+	FirstName       string              `json:"first_name"`
+	LastName        string              `json:"last_name"`
+	PollingUnitID   string              `json:"polling_unit_id"`
+	FingerprintHash string              `json:"fingerprint_hash"`
+	Template        *biometric.Template `json:"template,omitempty"` // enrolled template; absent for voters matched by hash
+}
+
+// JournalUpload is a batch of a terminal's journaled votes, oldest first
+type JournalUpload struct {
+	Entries []JournalUploadEntry `json:"entries" binding:"required,min=1,max=100,dive"`
+}
+
+// JournalUploadEntry is one link of a terminal's vote journal. Vote is the
+// VoteRequest exactly as journaled; its SHA-256 is part of the entry hash.
+type JournalUploadEntry struct {
+	Seq              int64           `json:"seq" binding:"required,min=1"`
+	PrevHash         string          `json:"prev_hash" binding:"required"`
+	EntryHash        string          `json:"entry_hash" binding:"required"`
+	Signature        string          `json:"signature"`
+	VerificationHash string          `json:"verification_hash" binding:"required"`
+	CreatedAt        time.Time       `json:"created_at" binding:"required"`
+	Vote             json.RawMessage `json:"vote" binding:"required"`
+}
+
+// Journal entry outcomes reported to terminals
+const (
+	JournalAccepted     = "accepted"      // vote recorded on chain
+	JournalQueued       = "queued"        // vote accepted and queued for the chain
+	JournalDuplicate    = "duplicate"     // voter had already voted
+	JournalRejected     = "rejected"      // vote refused for good
+	JournalInvalid      = "invalid"       // hash or signature does not verify
+	JournalOutOfOrder   = "out_of_order"  // does not continue the terminal's chain
+	JournalRetry        = "retry"         // server could not decide now; upload again
+	JournalNotProcessed = "not_processed" // skipped after an earlier entry stopped the batch
+)
+
+// JournalEntryResult is the server's outcome for one uploaded entry
+type JournalEntryResult struct {
+	Seq             int64  `json:"seq"`
+	EntryHash       string `json:"entry_hash"`
+	Status          string `json:"status"`
+	Code            int    `json:"code,omitempty"`
+	Error           string `json:"error,omitempty"`
+	TransactionHash string `json:"transaction_hash,omitempty"`
+	Replayed        bool   `json:"replayed,omitempty"` // already received in an earlier upload
+}
+
+// JournalUploadResponse lists the outcome of each uploaded entry
+type JournalUploadResponse struct {
+	Results []JournalEntryResult `json:"results"`
+}
+
+// ReconciliationEntry shows where a journal entry received from a terminal ended up
+type ReconciliationEntry struct {
+	Seq              int64      `json:"seq"`
+	EntryHash        string     `json:"entry_hash"`
+	VerificationHash string     `json:"verification_hash"`
+	Status           string     `json:"status"`
+	VoteStatus       string     `json:"vote_status,omitempty"`
+	TransactionHash  string     `json:"transaction_hash,omitempty"`
+	BlockNumber      int64      `json:"block_number,omitempty"`
+	OnChain          bool       `json:"on_chain"`
+	RecordedAt       time.Time  `json:"recorded_at"`
+	ReceivedAt       time.Time  `json:"received_at"`
+	SyncedAt         *time.Time `json:"synced_at,omitempty"`
+}
+
+// ReconciliationReport compares a terminal's uploaded journal with the chain
+type ReconciliationReport struct {
+	DeviceID string                `json:"device_id"`
+	LastSeq  int64                 `json:"last_seq"`
+	Summary  map[string]int        `json:"summary"`
+	Entries  []ReconciliationEntry `json:"entries"`
+}
+
 // VoterInfo represents voter information
 type VoterInfo struct {
 	ID            int64  `json:"id"`
@@ -72,6 +193,45 @@ type VoterInfo struct {
 	PollingUnitID string `json:"polling_unit_id"`
 	IsActive      bool   `json:"is_active"`
 	RegisteredAt  string `json:"registered_at"`
+
+	DeactivationReason string `json:"deactivation_reason,omitempty"`
+	DeactivatedAt      string `json:"deactivated_at,omitempty"`
+}
+
+// VoterVerificationRequest checks a voter's fingerprint and eligibility for
+// the active election before they vote
+type VoterVerificationRequest struct {
+	NIN             string `json:"nin" binding:"required"`
+	FingerprintData string `json:"fingerprint_data" binding:"required"`
+	PollingUnitID   string `json:"polling_unit_id" binding:"required"`
+}
+
+// VoterTransferRequest moves a voter to another polling unit
+type VoterTransferRequest struct {
+	PollingUnitID string `json:"polling_unit_id" binding:"required"`
+	ReasonCode    string `json:"reason_code" binding:"required"`
+	Notes         string `json:"notes" binding:"max=500"`
+}
+
+// VoterDeactivationRequest deactivates a voter. Duplicates name the NIN of
+// the registration that is kept.
+type VoterDeactivationRequest struct {
+	ReasonCode  string `json:"reason_code" binding:"required"`
+	DuplicateOf string `json:"duplicate_of"`
+	Notes       string `json:"notes" binding:"max=500"`
+}
+
+// VoterReactivationRequest restores a deactivated voter
+type VoterReactivationRequest struct {
+	ReasonCode string `json:"reason_code" binding:"required"`
+	Notes      string `json:"notes" binding:"max=500"`
+}
+
+// VoterReenrolmentRequest replaces a voter's enrolled fingerprint
+type VoterReenrolmentRequest struct {
+	FingerprintData string `json:"fingerprint_data" binding:"required"`
+	ReasonCode      string `json:"reason_code" binding:"required"`
+	Notes           string `json:"notes" binding:"max=500"`
 }
 
 // ElectionInfo represents election information
@@ -105,3 +265,388 @@ type AuditLog struct {
 	Timestamp     int64  `json:"timestamp"`
 	IPAddress     string `json:"ip_address"`
 }
+
+// LoginRequest represents a username/password login
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+
+	// Second factor, required once the account has 2FA enabled
+	OTP          string `json:"otp,omitempty"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+}
+
+// RefreshRequest represents a refresh token exchange
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// UserInfo represents the public view of an API user
+type UserInfo struct {
+	ID          int64    `json:"id"`
+	Username    string   `json:"username"`
+	Email       string   `json:"email"`
+	FirstName   string   `json:"first_name"`
+	LastName    string   `json:"last_name"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+	IsActive    bool     `json:"is_active"`
+	LastLogin   *int64   `json:"last_login,omitempty"`
+	CreatedAt   int64    `json:"created_at"`
+}
+
+// AuthTokens is returned by login and refresh
+type AuthTokens struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	TokenType    string    `json:"token_type"`
+	ExpiresIn    int64     `json:"expires_in"`
+	SessionID    string    `json:"session_id"`
+	User         *UserInfo `json:"user,omitempty"`
+
+	// TwoFactorSetupRequired is set when the account must enrol in 2FA before
+	// the session can be used for anything other than enrolment
+	TwoFactorSetupRequired bool `json:"two_factor_setup_required,omitempty"`
+}
+
+// SessionInfo represents an active login session
+type SessionInfo struct {
+	ID         string `json:"id"`
+	IPAddress  string `json:"ip_address"`
+	UserAgent  string `json:"user_agent"`
+	CreatedAt  int64  `json:"created_at"`
+	LastUsedAt int64  `json:"last_used_at"`
+	ExpiresAt  int64  `json:"expires_at"`
+	Current    bool   `json:"current"`
+}
+
+// CreateUserRequest creates a staff account
+type CreateUserRequest struct {
+	Username    string   `json:"username" binding:"required"`
+	Email       string   `json:"email" binding:"required,email"`
+	Password    string   `json:"password" binding:"required"`
+	FirstName   string   `json:"first_name"`
+	LastName    string   `json:"last_name"`
+	Role        string   `json:"role" binding:"required"`
+	Permissions []string `json:"permissions"`
+}
+
+// UpdateUserRequest changes a staff account. Omitted fields are left unchanged.
+type UpdateUserRequest struct {
+	Email       *string   `json:"email" binding:"omitempty,email"`
+	FirstName   *string   `json:"first_name"`
+	LastName    *string   `json:"last_name"`
+	Role        *string   `json:"role"`
+	Permissions *[]string `json:"permissions"`
+	IsActive    *bool     `json:"is_active"`
+}
+
+// PasswordResetToken is returned once when an admin issues a password reset
+type PasswordResetToken struct {
+	UserID    int64  `json:"user_id"`
+	Token     string `json:"token"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+// CompletePasswordResetRequest sets a new password using a reset token
+type CompletePasswordResetRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// TwoFactorStatus describes a user's 2FA enrolment
+type TwoFactorStatus struct {
+	Enabled                bool   `json:"enabled"`
+	Required               bool   `json:"required"`
+	RecoveryCodesRemaining int    `json:"recovery_codes_remaining"`
+	EnabledAt              *int64 `json:"enabled_at,omitempty"`
+}
+
+// TwoFactorEnrollment is returned when a user starts TOTP enrolment
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// TwoFactorCodeRequest carries a TOTP code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// RecoveryCodes are returned once when they are generated
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+// BallotKeyRequest registers the ballot encryption key of an election. The
+// trustees' verification keys are listed in share order, index 1 first.
+type BallotKeyRequest struct {
+	PublicKey        string   `json:"public_key" binding:"required"`
+	Threshold        int      `json:"threshold" binding:"required,min=1"`
+	VerificationKeys []string `json:"verification_keys" binding:"required"`
+}
+
+// BallotKey is what a terminal needs to encrypt ballots for an election
+type BallotKey struct {
+	ElectionID       string   `json:"election_id"`
+	PublicKey        string   `json:"public_key"`
+	Threshold        int      `json:"threshold"`
+	VerificationKeys []string `json:"verification_keys"`
+	Candidates       []string `json:"candidates"` // ballot order
+	Context          string   `json:"context"`    // proof context, tally.BallotContext
+	Status           string   `json:"status"`
+}
+
+// EncryptedTally is the summed ballots of an election, published for trustees to decrypt
+type EncryptedTally struct {
+	ElectionID string                `json:"election_id"`
+	Candidates []string              `json:"candidates"`
+	Context    string                `json:"context"`
+	Tally      *tally.EncryptedTally `json:"tally"`
+	Decrypted  bool                  `json:"decrypted"`
+}
+
+// DecryptTallyRequest carries the trustees' decryption shares
+type DecryptTallyRequest struct {
+	Shares []*tally.DecryptionShare `json:"shares" binding:"required,min=1"`
+}
+
+// TallyResults are the decrypted per-candidate counts of a ballot-secrecy election
+type TallyResults struct {
+	ElectionID  string           `json:"election_id"`
+	Ballots     int              `json:"ballots"`
+	Results     map[string]int64 `json:"results"`
+	DecryptedAt *int64           `json:"decrypted_at,omitempty"`
+}
+
+// KeyCeremonyRequest starts a key ceremony for an election. Trustees are staff
+// user IDs and receive share indices in the order given. ReuseElectionID carries
+// over an earlier election's key and trustees instead of dealing a new key,
+// which is refused when encryption key rotation is enabled.
+type KeyCeremonyRequest struct {
+	Threshold       int     `json:"threshold"`
+	Trustees        []int64 `json:"trustees"`
+	ReuseElectionID string  `json:"reuse_election_id"`
+}
+
+// CeremonyTrustee is a trustee's progress through a key ceremony
+type CeremonyTrustee struct {
+	Index       int    `json:"index"`
+	UserID      int64  `json:"user_id"`
+	Collected   bool   `json:"share_collected"`
+	CollectedAt *int64 `json:"collected_at,omitempty"`
+	Submitted   bool   `json:"decryption_submitted"`
+}
+
+// KeyCeremony describes an election's key ceremony
+type KeyCeremony struct {
+	ElectionID  string            `json:"election_id"`
+	Threshold   int               `json:"threshold"`
+	PublicKey   string            `json:"public_key"`
+	Commitments []string          `json:"commitments"`
+	ReusedFrom  string            `json:"reused_from,omitempty"`
+	Trustees    []CeremonyTrustee `json:"trustees"`
+	CreatedAt   int64             `json:"created_at"`
+	CompletedAt *int64            `json:"completed_at,omitempty"`
+	KeyStatus   string            `json:"key_status"`
+}
+
+// TrusteeKeyShare is handed to a trustee exactly once
+type TrusteeKeyShare struct {
+	ElectionID  string         `json:"election_id"`
+	Threshold   int            `json:"threshold"`
+	PublicKey   string         `json:"public_key"`
+	Commitments []string       `json:"commitments"`
+	Share       tally.KeyShare `json:"share"`
+}
+
+// TrusteeSeat is a ceremony the caller is a trustee of
+type TrusteeSeat struct {
+	ElectionID string `json:"election_id"`
+	Index      int    `json:"index"`
+	Collected  bool   `json:"share_collected"`
+	Submitted  bool   `json:"decryption_submitted"`
+	KeyStatus  string `json:"key_status"`
+}
+
+// DecryptionShareRequest carries one trustee's partial decryption of the tally
+type DecryptionShareRequest struct {
+	Share *tally.DecryptionShare `json:"share" binding:"required"`
+}
+
+// DecryptionProgress reports how many trustees have decrypted, with the results once enough have
+type DecryptionProgress struct {
+	ElectionID string        `json:"election_id"`
+	Submitted  int           `json:"submitted"`
+	Threshold  int           `json:"threshold"`
+	Results    *TallyResults `json:"results,omitempty"`
+}
+
+// TerminalHeartbeat is a terminal's periodic health report
+type TerminalHeartbeat struct {
+	BatteryLevel    *int   `json:"battery_level" binding:"omitempty,min=0,max=100"` // percent; omitted on mains power
+	Charging        bool   `json:"charging"`
+	FirmwareVersion string `json:"firmware_version" binding:"max=50"`
+	QueueDepth      int    `json:"queue_depth" binding:"min=0"`       // votes journaled but not yet uploaded
+	Timestamp       int64  `json:"timestamp" binding:"required,gt=0"` // terminal clock, unix milliseconds
+}
+
+// HeartbeatResponse acknowledges a heartbeat
+type HeartbeatResponse struct {
+	ServerTime    int64    `json:"server_time"` // unix milliseconds
+	ClockSkewMs   int64    `json:"clock_skew_ms"`
+	ConfigVersion string   `json:"config_version"` // changes whenever the terminal's remote config does
+	Alerts        []string `json:"alerts"`         // alert kinds the reported health raised
+}
+
+// TerminalRemoteConfig is the configuration a terminal runs with, assembled
+// from the active election, the server's biometric settings and any
+// per-terminal overrides
+type TerminalRemoteConfig struct {
+	DeviceID          string   `json:"device_id"`
+	PollingUnitID     string   `json:"polling_unit_id"`
+	ElectionID        string   `json:"election_id,omitempty"` // empty when no election is active
+	Candidates        []string `json:"candidates"`
+	QualityThreshold  float64  `json:"quality_threshold"`
+	MatchThreshold    float64  `json:"match_threshold"`
+	MaxAttempts       int      `json:"max_attempts"`
+	LockoutDuration   int      `json:"lockout_duration"`   // seconds
+	Matcher           string   `json:"matcher"`            // template format new registrations are enrolled in
+	HeartbeatInterval int      `json:"heartbeat_interval"` // seconds
+	Version           string   `json:"version"`
+
+	// Secrets only sent to the terminal itself
+	VerificationSalt string `json:"verification_salt,omitempty"` // the election's salt for verification hashes
+	FingerprintKey   string `json:"fingerprint_key,omitempty"`   // hex key of exact fingerprint hashes
+}
+
+// TerminalConfigOverrides are an admin's changes to one terminal's remote
+// config. Omitted fields fall back to the server's settings.
+type TerminalConfigOverrides struct {
+	QualityThreshold  *float64 `json:"quality_threshold,omitempty" binding:"omitempty,gt=0,lte=1"`
+	MatchThreshold    *float64 `json:"match_threshold,omitempty" binding:"omitempty,gt=0,lte=1"`
+	MaxAttempts       *int     `json:"max_attempts,omitempty" binding:"omitempty,min=1"`
+	HeartbeatInterval *int     `json:"heartbeat_interval,omitempty" binding:"omitempty,min=10"` // seconds
+}
+
+// TerminalAlert is a fleet alert raised for a terminal
+type TerminalAlert struct {
+	ID         int64      `json:"id"`
+	DeviceID   string     `json:"device_id"`
+	Kind       string     `json:"kind"`
+	Message    string     `json:"message"`
+	RaisedAt   time.Time  `json:"raised_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// TerminalStatus is a terminal's registration, its last reported health and today's activity
+type TerminalStatus struct {
+	TerminalID      string          `json:"terminal_id"`
+	Name            string          `json:"name"`
+	Location        string          `json:"location"`
+	PollingUnitID   string          `json:"polling_unit_id"`
+	Address         string          `json:"address"`
+	Status          string          `json:"status"`
+	Authorized      bool            `json:"authorized"`
+	Online          bool            `json:"online"`
+	LastHeartbeat   *time.Time      `json:"last_heartbeat"`
+	BatteryLevel    *int            `json:"battery_level"`
+	Charging        bool            `json:"charging"`
+	FirmwareVersion string          `json:"firmware_version"`
+	QueueDepth      int             `json:"queue_depth"`
+	ClockSkewMs     int64           `json:"clock_skew_ms"`
+	VotesToday      int             `json:"votes_today"`
+	Alerts          []TerminalAlert `json:"alerts"` // open alerts
+}
+
+// TerminalActionRequest carries the reason for an admin action on a terminal
+type TerminalActionRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// BulkAuthorizeRequest authorizes every terminal of a polling unit
+type BulkAuthorizeRequest struct {
+	PollingUnitID string `json:"polling_unit_id" binding:"required"`
+	Reason        string `json:"reason" binding:"max=500"`
+}
+
+// Outcomes of a bulk operation for one terminal
+const (
+	TerminalOpDone    = "done"
+	TerminalOpSkipped = "skipped"
+	TerminalOpFailed  = "failed"
+)
+
+// TerminalOperationResult is the outcome of a bulk operation for one terminal
+type TerminalOperationResult struct {
+	TerminalID     string `json:"terminal_id"`
+	Status         string `json:"status"`
+	TerminalStatus string `json:"terminal_status"` // the terminal's status afterwards
+	Transaction    string `json:"transaction,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// BulkOperationResponse lists the outcome for each terminal of a bulk operation
+type BulkOperationResponse struct {
+	PollingUnitID string                    `json:"polling_unit_id"`
+	Summary       map[string]int            `json:"summary"`
+	Results       []TerminalOperationResult `json:"results"`
+}
+
+// EnrolmentCodeRequest asks for a one-time code to enrol a terminal at a
+// polling unit, optionally only the terminal with the given ID
+type EnrolmentCodeRequest struct {
+	PollingUnitID string `json:"polling_unit_id" binding:"required"`
+	TerminalID    string `json:"terminal_id"`
+	ValidFor      int    `json:"valid_for" binding:"min=0"` // seconds; the server default when 0
+}
+
+// EnrolmentCodeResponse carries a newly issued enrolment code. The code is
+// only ever shown here.
+type EnrolmentCodeResponse struct {
+	Code          string    `json:"code"`
+	PollingUnitID string    `json:"polling_unit_id"`
+	TerminalID    string    `json:"terminal_id,omitempty"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// TerminalEnrolmentRequest is a terminal's request to be enrolled. It is
+// signed, as an EIP-712 Enrolment message, with the key whose address and
+// public key it carries.
+type TerminalEnrolmentRequest struct {
+	TerminalID    string `json:"terminal_id" binding:"required,max=50"`
+	Name          string `json:"name" binding:"max=255"`
+	Location      string `json:"location" binding:"max=255"`
+	PollingUnitID string `json:"polling_unit_id" binding:"required,max=50"`
+	Address       string `json:"address" binding:"required"`
+	PublicKey     string `json:"public_key" binding:"required"` // uncompressed secp256k1 key as hex
+	Code          string `json:"code" binding:"required"`
+	Timestamp     int64  `json:"timestamp" binding:"required"` // unix seconds
+	Signature     string `json:"signature" binding:"required"`
+}
+
+// TerminalEnrolmentResponse reports where a terminal is in its enrolment
+type TerminalEnrolmentResponse struct {
+	TerminalID string `json:"terminal_id"`
+	Status     string `json:"status"`
+	Address    string `json:"address"`
+}
+
+// TerminalTokenRequest asks for a terminal's device token. It is signed with
+// the terminal's secret; see auth.TerminalTokenMAC.
+type TerminalTokenRequest struct {
+	DeviceID  string `json:"device_id" binding:"required"`
+	Timestamp string `json:"ts"` // unix seconds
+	Nonce     string `json:"nonce"`
+	Signature string `json:"signature"`
+}
+
+// TerminalSecretResponse carries a terminal's newly issued secret. The secret
+// is only ever shown here.
+type TerminalSecretResponse struct {
+	TerminalID string     `json:"terminal_id"`
+	Secret     string     `json:"secret"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Previous   int64      `json:"previous"`              // older secrets still valid for the grace period
+	GraceUntil *time.Time `json:"grace_until,omitempty"` // when they stop working
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"voting-system/internal/api/models"
+	"voting-system/internal/api/types"
+	"voting-system/internal/database"
+	"voting-system/pkg/config"
+	"voting-system/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeUser(t *testing.T, body []byte) types.UserInfo {
+	t.Helper()
+
+	var resp struct {
+		Data types.UserInfo `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	return resp.Data
+}
+
+func TestUserCRUD(t *testing.T) {
+	env := newTestEnv(t)
+	admin := env.tokenFor(t, models.RoleAdmin)
+
+	w := env.doJSON("POST", "/api/v1/admin/users/", admin, types.CreateUserRequest{
+		Username:    "frank",
+		Email:       "frank@example.com",
+		Password:    testPassword,
+		Role:        models.RoleOperator,
+		Permissions: []string{models.PermAuditRead},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	created := decodeUser(t, w.Body.Bytes())
+	assert.Equal(t, models.RoleOperator, created.Role)
+	assert.Contains(t, created.Permissions, models.PermAuditRead)
+	userPath := "/api/v1/admin/users/" + strconv.FormatInt(created.ID, 10)
+
+	// The new account can log in
+	frank := decodeTokens(t, login(t, env, "frank", testPassword))
+	assert.Equal(t, http.StatusOK, env.do("GET", "/api/v1/audit/logs", frank.AccessToken).Code)
+
+	// Duplicates, unknown roles and device permissions are rejected
+	w = env.doJSON("POST", "/api/v1/admin/users/", admin, types.CreateUserRequest{
+		Username: "frank", Email: "other@example.com", Password: testPassword, Role: models.RoleOperator,
+	})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = env.doJSON("POST", "/api/v1/admin/users/", admin, types.CreateUserRequest{
+		Username: "gina", Email: "gina@example.com", Password: testPassword, Role: models.RoleTerminal,
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = env.doJSON("POST", "/api/v1/admin/users/", admin, types.CreateUserRequest{
+		Username: "gina", Email: "gina@example.com", Password: testPassword, Role: models.RoleAuditor,
+		Permissions: []string{models.PermVoting},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Changing the role revokes existing sessions
+	role := models.RoleAuditor
+	w = env.doJSON("PUT", userPath, admin, types.UpdateUserRequest{Role: &role})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, models.RoleAuditor, decodeUser(t, w.Body.Bytes()).Role)
+	assert.Equal(t, http.StatusUnauthorized, env.do("GET", "/api/v1/audit/logs", frank.AccessToken).Code)
+
+	w = env.do("GET", userPath, admin)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.RoleAuditor, decodeUser(t, w.Body.Bytes()).Role)
+
+	// Deleting deactivates the account
+	require.Equal(t, http.StatusOK, env.do("DELETE", userPath, admin).Code)
+	assert.Equal(t, http.StatusUnauthorized, login(t, env, "frank", testPassword).Code)
+	user, err := env.users.GetByID(created.ID)
+	require.NoError(t, err)
+	assert.False(t, user.IsActive)
+
+	// Every change is audited
+	logs, err := env.services.AuditLogRepository().GetAuditLogs(100, 0, "", "", nil, nil)
+	require.NoError(t, err)
+	actions := map[string]bool{}
+	for _, log := range logs {
+		actions[log.Action] = true
+	}
+	assert.True(t, actions["user_created"])
+	assert.True(t, actions["user_updated"])
+	assert.True(t, actions["user_deactivated"])
+}
+
+func TestLastAdminCannotBeRemoved(t *testing.T) {
+	env := newTestEnv(t)
+	admin := env.tokenFor(t, models.RoleAdmin)
+	adminPath := "/api/v1/admin/users/" + strconv.FormatInt(env.userIDs[models.RoleAdmin], 10)
+
+	role := models.RoleOperator
+	w := env.doJSON("PUT", adminPath, admin, types.UpdateUserRequest{Role: &role})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	inactive := false
+	w = env.doJSON("PUT", adminPath, admin, types.UpdateUserRequest{IsActive: &inactive})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = env.do("DELETE", adminPath, admin)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPasswordResetTokenIsSingleUse(t *testing.T) {
+	env := newTestEnv(t)
+	admin := env.tokenFor(t, models.RoleAdmin)
+	id := env.createLoginUser(t, "henry", models.RoleOperator)
+	henry := decodeTokens(t, login(t, env, "henry", testPassword))
+
+	w := env.do("POST", "/api/v1/admin/users/"+strconv.FormatInt(id, 10)+"/reset-password", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Data types.PasswordResetToken `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotEmpty(t, resp.Data.Token)
+
+	newPassword := "a different passphrase"
+	w = env.doJSON("POST", "/api/v1/public/auth/reset-password", "", types.CompletePasswordResetRequest{
+		Token: resp.Data.Token, NewPassword: newPassword,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// Old sessions and the old password stop working
+	assert.Equal(t, http.StatusUnauthorized, env.do("GET", "/api/v1/auth/sessions", henry.AccessToken).Code)
+	assert.Equal(t, http.StatusUnauthorized, login(t, env, "henry", testPassword).Code)
+	assert.Equal(t, http.StatusOK, login(t, env, "henry", newPassword).Code)
+
+	// The token cannot be used twice
+	w = env.doJSON("POST", "/api/v1/public/auth/reset-password", "", types.CompletePasswordResetRequest{
+		Token: resp.Data.Token, NewPassword: "yet another passphrase",
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestBootstrapAdmin(t *testing.T) {
+	db, err := database.NewConnection(&config.DatabaseConfig{
+		Type: "sqlite",
+		Path: filepath.Join(t.TempDir(), "bootstrap.db"),
+	})
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, database.RunMigrations(db))
+
+	cfg := &config.Config{Admin: config.AdminConfig{Username: "root", Password: testPassword}}
+	services := NewServices(db, nil, nil, nil, nil, logger.NewLogger("panic", ""), cfg)
+
+	require.NoError(t, BootstrapAdmin(services))
+	user, err := services.UserRepository().GetByUsername("root")
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleAdmin, user.Role)
+
+	// Running again with different credentials does not add another admin
+	cfg.Admin.Username = "second"
+	require.NoError(t, BootstrapAdmin(services))
+	count, err := services.UserRepository().CountUsers()
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
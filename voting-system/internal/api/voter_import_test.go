@@ -0,0 +1,148 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"voting-system/internal/api/models"
+	"voting-system/internal/api/types"
+	"voting-system/internal/database"
+	"voting-system/internal/voterimport"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startImport uploads an import file and returns the job it started
+func (env *testEnv) startImport(t *testing.T, query, contentType, file string) (int, database.VoterImportJob) {
+	t.Helper()
+	env.request++
+
+	req := httptest.NewRequest("POST", "/api/v1/admin/voters/import"+query, strings.NewReader(file))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+env.tokenFor(t, models.RoleOperator))
+	req.RemoteAddr = fmt.Sprintf("10.%d.%d.%d:40000", env.request/65536%256, env.request/256%256, env.request%256)
+	w := httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+
+	var job database.VoterImportJob
+	if w.Code == http.StatusAccepted {
+		decodeData(t, w.Body.Bytes(), &job)
+	}
+	return w.Code, job
+}
+
+// waitForImport polls an import job until it has finished
+func (env *testEnv) waitForImport(t *testing.T, id string) (database.VoterImportJob, map[int]string) {
+	t.Helper()
+
+	token := env.tokenFor(t, models.RoleOperator)
+	var job database.VoterImportJob
+	require.Eventually(t, func() bool {
+		w := env.do("GET", "/api/v1/admin/voters/import/"+id, token)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		decodeData(t, w.Body.Bytes(), &job)
+		return job.Status != database.ImportRunning
+	}, 10*time.Second, 20*time.Millisecond)
+
+	w := env.do("GET", "/api/v1/admin/voters/import/"+id+"/errors", token)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var page types.VoterImportErrors
+	decodeData(t, w.Body.Bytes(), &page)
+	assert.Equal(t, job.FailedRows, page.Total)
+	codes := make(map[int]string, len(page.Errors))
+	for _, e := range page.Errors {
+		codes[e.Line] = e.Code
+	}
+	return job, codes
+}
+
+func TestVoterImport(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerTerminal(t, "TERM-001", true)
+	env.registerVoter(t, "12345678901", "slot-1")
+
+	file := strings.Join([]string{
+		"nin,first_name,last_name,date_of_birth,gender,polling_unit_id,fingerprint_data",
+		"10987654321,Bola,Ade,1990-01-01,M,PU-1,slot-2",
+		"11122233344,Chidi,Eze,1985-05-05,Male,PU-2,slot-3",
+		"12345678901,Ada,Obi,1990-01-01,F,PU-1,slot-4",
+		"123,Dayo,Ola,1990-01-01,M,PU-1,slot-5",
+		"10987654321,Efe,Ubi,1990-01-01,F,PU-1,slot-6",
+		"11122233355,Femi,Ojo,1990-01-01,M,PU-1,slot-2",
+		"11122233366,Gbenga,Alu,1990-01-01,M,PU-1,slot-1",
+	}, "\n")
+	failed := map[int]string{
+		4: voterimport.CodeDuplicateNIN,
+		5: voterimport.CodeInvalidNIN,
+		6: voterimport.CodeDuplicateNIN,
+		7: voterimport.CodeDuplicateFingerprint,
+		8: voterimport.CodeDuplicateFingerprint,
+	}
+
+	// A dry run reports every row without registering anyone
+	code, job := env.startImport(t, "?dry_run=true", "text/csv", file)
+	require.Equal(t, http.StatusAccepted, code)
+	assert.Equal(t, voterimport.FormatCSV, job.Format)
+	assert.Equal(t, 7, job.TotalRows)
+	job, codes := env.waitForImport(t, job.ID)
+	assert.Equal(t, database.ImportCompleted, job.Status)
+	assert.Equal(t, 7, job.ProcessedRows)
+	assert.Equal(t, 2, job.ImportedRows)
+	assert.Equal(t, 5, job.FailedRows)
+	assert.Equal(t, failed, codes)
+	_, err := env.services.VoterRepository().GetVoterByNIN("10987654321")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// A committed import registers the rows that pass
+	code, job = env.startImport(t, "?format=csv", "application/octet-stream", file)
+	require.Equal(t, http.StatusAccepted, code)
+	job, codes = env.waitForImport(t, job.ID)
+	assert.Equal(t, database.ImportCompleted, job.Status)
+	assert.Equal(t, 2, job.ImportedRows)
+	assert.Equal(t, failed, codes)
+	voter, err := env.services.VoterRepository().GetVoterByNIN("11122233344")
+	require.NoError(t, err)
+	assert.Equal(t, "M", voter.Gender)
+	assert.Equal(t, "PU-2", voter.PollingUnitID)
+	template, err := env.services.VoterTemplateRepository().Get("11122233344")
+	require.NoError(t, err)
+	assert.True(t, template.Sealed)
+
+	// Importing the same file again finds everyone registered
+	jsonl := `{"nin":"10987654321","first_name":"Bola","last_name":"Ade","date_of_birth":"1990-01-01","gender":"M","polling_unit_id":"PU-1","fingerprint_data":"slot-9"}`
+	code, job = env.startImport(t, "", "application/x-ndjson", jsonl)
+	require.Equal(t, http.StatusAccepted, code)
+	job, codes = env.waitForImport(t, job.ID)
+	assert.Equal(t, 0, job.ImportedRows)
+	assert.Equal(t, map[int]string{1: voterimport.CodeDuplicateNIN}, codes)
+
+	logs, err := env.services.AuditLogRepository().GetAuditLogsByAction("voter_import_completed", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, logs, 3)
+
+	code, _ = env.startImport(t, "", "application/json", file)
+	assert.Equal(t, http.StatusBadRequest, code, "the format is required")
+	code, _ = env.startImport(t, "?format=csv", "text/csv", "nin,first_name\n")
+	assert.Equal(t, http.StatusBadRequest, code)
+	w := env.do("GET", "/api/v1/admin/voters/import/unknown", env.tokenFor(t, models.RoleAdmin))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestInterruptedImportsFail(t *testing.T) {
+	env := newTestEnv(t)
+	repo := env.services.VoterImportRepository()
+	require.NoError(t, repo.Create(&database.VoterImportJob{ID: "job-1", Format: "csv", Status: database.ImportRunning, TotalRows: 10}))
+
+	require.NoError(t, FailInterruptedImports(env.services))
+	job, err := repo.Get("job-1")
+	require.NoError(t, err)
+	assert.Equal(t, database.ImportFailed, job.Status)
+	assert.NotEmpty(t, job.Error)
+	assert.NotNil(t, job.FinishedAt)
+}
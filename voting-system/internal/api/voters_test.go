@@ -0,0 +1,125 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"voting-system/internal/api/models"
+	"voting-system/internal/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// voterAction posts an admin action on a voter and returns the status and
+// error code
+func (env *testEnv) voterAction(t *testing.T, nin, action string, body interface{}) (int, string) {
+	t.Helper()
+
+	w := env.doJSON("POST", "/api/v1/admin/voters/"+nin+"/"+action, env.tokenFor(t, models.RoleOperator), body)
+	var resp types.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), w.Body.String())
+	return w.Code, resp.Error
+}
+
+func TestVoterLifecycle(t *testing.T) {
+	env := newTestEnv(t)
+	key := env.registerTerminal(t, "TERM-001", true)
+	env.registerVoter(t, "12345678901", "slot-1")
+	env.registerVoter(t, "10987654321", "slot-2")
+	env.registerVoter(t, "11122233344", "slot-3")
+	token := env.tokenFor(t, models.RoleOperator)
+
+	var page struct {
+		Voters []types.VoterInfo `json:"voters"`
+		Total  int               `json:"total"`
+	}
+	w := env.do("GET", "/api/v1/admin/voters?q=obi&polling_unit_id=PU-1", token)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	decodeData(t, w.Body.Bytes(), &page)
+	assert.Equal(t, 3, page.Total)
+	w = env.do("GET", "/api/v1/admin/voters?status=gone", token)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = env.do("GET", "/api/v1/admin/voters/99999999999", token)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Transfers
+	code, reason := env.voterAction(t, "10987654321", "transfer", types.VoterTransferRequest{PollingUnitID: "PU-2", ReasonCode: "holiday"})
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "invalid_reason_code", reason)
+	code, _ = env.voterAction(t, "10987654321", "transfer", types.VoterTransferRequest{PollingUnitID: "PU-2", ReasonCode: "relocation"})
+	require.Equal(t, http.StatusOK, code)
+	code, reason = env.voterAction(t, "10987654321", "transfer", types.VoterTransferRequest{PollingUnitID: "PU-2", ReasonCode: "relocation"})
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, "same_polling_unit", reason)
+	var voter types.VoterInfo
+	w = env.do("GET", "/api/v1/admin/voters/10987654321", token)
+	require.Equal(t, http.StatusOK, w.Code)
+	decodeData(t, w.Body.Bytes(), &voter)
+	assert.Equal(t, "PU-2", voter.PollingUnitID)
+
+	// Duplicates name the registration that is kept
+	code, reason = env.voterAction(t, "11122233344", "deactivate", types.VoterDeactivationRequest{ReasonCode: "duplicate", DuplicateOf: "11122233344"})
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "invalid_duplicate", reason)
+	code, _ = env.voterAction(t, "11122233344", "deactivate", types.VoterDeactivationRequest{ReasonCode: "deceased", Notes: "death certificate 42"})
+	require.Equal(t, http.StatusOK, code)
+	code, reason = env.voterAction(t, "11122233344", "deactivate", types.VoterDeactivationRequest{ReasonCode: "deceased"})
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, "voter_deactivated", reason)
+	w = env.do("GET", "/api/v1/admin/voters?status=deactivated", token)
+	decodeData(t, w.Body.Bytes(), &page)
+	require.Equal(t, 1, page.Total)
+	assert.Equal(t, "deceased", page.Voters[0].DeactivationReason)
+	assert.NotEmpty(t, page.Voters[0].DeactivatedAt)
+
+	// Deactivated voters can neither register again, be verified nor vote
+	w = env.doJSON("POST", "/api/v1/public/voter/register", env.tokenFor(t, models.RoleTerminal), types.VoterRegistrationRequest{
+		NIN: "11122233344", FirstName: "Ada", LastName: "Obi", DateOfBirth: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+		Gender: "F", PollingUnitID: "PU-1", FingerprintData: "slot-4",
+	})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	env.startElection(t, "1")
+	w = env.doJSON("POST", "/api/v1/voting/verify", env.tokenFor(t, models.RoleTerminal),
+		types.VoterVerificationRequest{NIN: "11122233344", FingerprintData: "slot-3", PollingUnitID: "PU-1"})
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	vote := types.VoteRequest{NIN: "11122233344", FingerprintData: "slot-3", CandidateID: "APC", PollingUnitID: "PU-1"}
+	signVote(t, key, "TERM-001", &vote)
+	code, reason = castError(t, env, vote)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "voter_deactivated", reason)
+	logs, err := env.services.AuditLogRepository().GetAuditLogsByAction("vote_rejected_voter_deactivated", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, logs, 1)
+
+	code, reason = env.voterAction(t, "11122233344", "fingerprint", types.VoterReenrolmentRequest{FingerprintData: "slot-4", ReasonCode: "injury"})
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, "voter_deactivated", reason)
+	code, _ = env.voterAction(t, "11122233344", "reactivate", types.VoterReactivationRequest{ReasonCode: "deactivated_in_error"})
+	require.Equal(t, http.StatusOK, code)
+	code, reason = env.voterAction(t, "11122233344", "reactivate", types.VoterReactivationRequest{ReasonCode: "deactivated_in_error"})
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, "voter_active", reason)
+
+	// Re-enrolment refuses another voter's finger and frees the old one
+	code, reason = env.voterAction(t, "12345678901", "fingerprint", types.VoterReenrolmentRequest{FingerprintData: "slot-2", ReasonCode: "injury"})
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, "fingerprint_exists", reason)
+	_, err = env.services.BiometricAttemptRepository().RecordFailure("12345678901", 5, time.Minute)
+	require.NoError(t, err)
+	code, _ = env.voterAction(t, "12345678901", "fingerprint", types.VoterReenrolmentRequest{FingerprintData: "slot-9", ReasonCode: "injury"})
+	require.Equal(t, http.StatusOK, code)
+	attempt, err := env.services.BiometricAttemptRepository().Get("12345678901")
+	require.NoError(t, err)
+	assert.Zero(t, attempt.FailedCount)
+	env.registerVoter(t, "11111111111", "slot-1")
+
+	// Every operation is audited
+	for _, action := range []string{"voter_transferred", "voter_deactivated", "voter_reactivated", "voter_fingerprint_reenrolled"} {
+		logs, err := env.services.AuditLogRepository().GetAuditLogsByAction(action, 10, 0)
+		require.NoError(t, err)
+		assert.Len(t, logs, 1, action)
+	}
+}
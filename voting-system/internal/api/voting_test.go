@@ -0,0 +1,328 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"voting-system/internal/api/models"
+	"voting-system/internal/api/types"
+	"voting-system/internal/auth"
+	"voting-system/internal/database"
+	"voting-system/internal/votesig"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registerTerminal stores a terminal with a fresh signing key and returns the key
+func (env *testEnv) registerTerminal(t *testing.T, deviceID string, authorized bool) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	repo := env.services.TerminalRepository()
+	require.NoError(t, repo.RegisterTerminal(&database.Terminal{
+		ID: deviceID, Name: deviceID, Location: "Test", PollingUnitID: "PU-1",
+		EthAddress: crypto.PubkeyToAddress(key.PublicKey).Hex(), Status: database.TerminalPending,
+	}))
+	if authorized {
+		require.NoError(t, repo.SetAuthorization(deviceID, true))
+	}
+	return key
+}
+
+// testVoteDomain is the chain and contract the test server accepts votes for
+var testVoteDomain = votesig.NewDomain(1337, "0x345cA3e014Aaf5dcA488057592ee47305D9B3e10")
+
+// signVote signs a vote the way deviceID's terminal would
+func signVote(t *testing.T, key *ecdsa.PrivateKey, deviceID string, vote *types.VoteRequest) {
+	t.Helper()
+	signVoteFor(t, testVoteDomain, key, deviceID, vote)
+}
+
+// signVoteFor signs a vote for the contract in domain
+func signVoteFor(t *testing.T, domain votesig.Domain, key *ecdsa.PrivateKey, deviceID string, vote *types.VoteRequest) {
+	t.Helper()
+	signVoteAs(t, domain, key, deviceID, voteHash(vote.NIN), vote)
+}
+
+// signVoteAs signs a vote with the given verification hash, for election 1
+// unless the vote names another
+func signVoteAs(t *testing.T, domain votesig.Domain, key *ecdsa.PrivateKey, deviceID, verificationHash string, vote *types.VoteRequest) {
+	t.Helper()
+
+	nonce, err := votesig.NewNonce()
+	require.NoError(t, err)
+	vote.Nonce = nonce
+	if vote.Timestamp == 0 {
+		vote.Timestamp = time.Now().Unix()
+	}
+	if vote.ElectionID == "" {
+		vote.ElectionID = "1"
+	}
+
+	signed, err := votesig.FromRequest(deviceID, verificationHash, vote)
+	require.NoError(t, err)
+	vote.Signature, err = votesig.NewSigner(key, domain).Sign(signed)
+	require.NoError(t, err)
+}
+
+// testSalt is the verification salt of elections started by startElection
+const testSalt = "test-election-salt"
+
+// startElection makes an election active in the DB cache, with testSalt as
+// its verification salt
+func (env *testEnv) startElection(t *testing.T, blockchainID string) *database.Election {
+	t.Helper()
+
+	election := &database.Election{
+		BlockchainID: blockchainID,
+		Name:         "Election " + blockchainID,
+		StartTime:    time.Now(),
+		EndTime:      time.Now().Add(time.Hour),
+	}
+	require.NoError(t, env.services.ElectionRepository().CreateElection(election))
+	require.NoError(t, env.services.ElectionRepository().UpdateElectionStatus(election.ID, true))
+	sealed, err := auth.Seal(testJWTSecret, testSalt)
+	require.NoError(t, err)
+	_, err = env.services.ElectionSaltRepository().Create(blockchainID, sealed)
+	require.NoError(t, err)
+	return election
+}
+
+// voteHash is the verification hash of the voter with nin in elections
+// started by startElection
+func voteHash(nin string) string {
+	return votesig.VerificationHash(testSalt, nin)
+}
+
+func castError(t *testing.T, env *testEnv, vote types.VoteRequest) (int, string) {
+	t.Helper()
+
+	w := env.doJSON("POST", "/api/v1/voting/cast", env.tokenFor(t, models.RoleTerminal), vote)
+	var resp types.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), w.Body.String())
+	return w.Code, resp.Error
+}
+
+func TestCastVoteRequiresTerminalSignature(t *testing.T) {
+	env := newTestEnv(t)
+	env.startElection(t, "1")
+	vote := types.VoteRequest{NIN: "12345678901", FingerprintData: "fp", CandidateID: "APC", PollingUnitID: "PU-1", ElectionID: "1"}
+
+	code, reason := castError(t, env, vote)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Equal(t, "signature_required", reason)
+
+	// Votes name the election they are for
+	unnamed := vote
+	unnamed.ElectionID = ""
+	code, reason = castError(t, env, unnamed)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "election_not_active", reason)
+
+	// The terminal must be registered under the signing address
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signed := vote
+	signVote(t, key, "TERM-001", &signed)
+	code, reason = castError(t, env, signed)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "unknown_terminal", reason)
+
+	termKey := env.registerTerminal(t, "TERM-001", false)
+	code, reason = castError(t, env, signed)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "signer_mismatch", reason)
+
+	signed = vote
+	signVote(t, termKey, "TERM-001", &signed)
+	code, reason = castError(t, env, signed)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "terminal_not_authorized", reason)
+
+	require.NoError(t, env.services.TerminalRepository().AuthorizeTerminal("TERM-001"))
+
+	// Altering any signed field breaks the signature
+	tampered := signed
+	tampered.CandidateID = "PDP"
+	code, reason = castError(t, env, tampered)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "signer_mismatch", reason)
+
+	// Signatures are bound to the server's chain and contract
+	elsewhere := vote
+	signVoteFor(t, votesig.NewDomain(1, testVoteDomain.Contract.Hex()), termKey, "TERM-001", &elsewhere)
+	code, reason = castError(t, env, elsewhere)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "signer_mismatch", reason)
+
+	stale := vote
+	stale.Timestamp = time.Now().Add(-48 * time.Hour).Unix()
+	signVote(t, termKey, "TERM-001", &stale)
+	code, reason = castError(t, env, stale)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Equal(t, "stale_signature", reason)
+
+	// A valid signature gets the vote as far as the voter lookup, once
+	code, reason = castError(t, env, signed)
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Equal(t, "voter_not_found", reason)
+
+	code, reason = castError(t, env, signed)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Equal(t, "replayed_vote", reason)
+}
+
+func TestTerminalsActForTheirOwnPollingUnit(t *testing.T) {
+	env := newTestEnv(t)
+	env.startElection(t, "1")
+	key := env.registerTerminal(t, "TERM-001", true)
+	terminal := env.tokenFor(t, models.RoleTerminal)
+
+	// A vote signed by the terminal for another polling unit is refused
+	vote := types.VoteRequest{NIN: "12345678901", FingerprintData: "fp", CandidateID: "APC", PollingUnitID: "PU-2"}
+	signVote(t, key, "TERM-001", &vote)
+	code, reason := castError(t, env, vote)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "polling_unit_mismatch", reason)
+
+	// Terminals register voters at their own polling unit only
+	registration := types.VoterRegistrationRequest{
+		NIN: "10987654321", FirstName: "Bola", LastName: "Ade", DateOfBirth: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+		Gender: "F", PollingUnitID: "PU-2", FingerprintData: "fp-bola",
+	}
+	w := env.doJSON("POST", "/api/v1/public/voter/register", terminal, registration)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	registration.PollingUnitID = "PU-1"
+	w = env.doJSON("POST", "/api/v1/public/voter/register", terminal, registration)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	// and cannot register another polling unit on chain
+	w = env.doJSON("POST", "/api/v1/terminal/polling-unit/ensure", terminal, map[string]string{"id": "PU-2"})
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+
+	logs, err := env.services.AuditLogRepository().GetAuditLogsByAction("terminal_polling_unit_mismatch", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, logs, 3)
+
+	// A token issued for the polling unit the terminal was at before is stale
+	claims := jwt.MapClaims{
+		"user_id":         "TERM-001",
+		"role":            models.RoleTerminal,
+		"polling_unit_id": "PU-2",
+		"exp":             time.Now().Add(time.Hour).Unix(),
+	}
+	stale, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testJWTSecret))
+	require.NoError(t, err)
+	w = env.doJSON("POST", "/api/v1/terminal/polling-unit/ensure", stale, map[string]string{})
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+}
+
+func TestRosterIsScopedToTheTerminalsPollingUnit(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerTerminal(t, "TERM-001", true)
+	env.registerVoter(t, "12345678901", "slot-1")
+	w := env.doJSON("POST", "/api/v1/public/voter/register", "", types.VoterRegistrationRequest{
+		NIN: "10987654321", FirstName: "Bola", LastName: "Ade", DateOfBirth: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+		Gender: "F", PollingUnitID: "PU-2", FingerprintData: "slot-2",
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	terminal := env.tokenFor(t, models.RoleTerminal)
+
+	// Without a polling unit the terminal gets its own roster
+	for _, path := range []string{"/api/v1/terminal/voters", "/api/v1/terminal/voters?polling_unit_id=PU-1"} {
+		w = env.do("GET", path, terminal)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var roster []types.RosterVoter
+		decodeData(t, w.Body.Bytes(), &roster)
+		require.Len(t, roster, 1)
+		assert.Equal(t, "12345678901", roster[0].NIN)
+	}
+
+	// Another polling unit's voters and templates are refused
+	w = env.do("GET", "/api/v1/terminal/voters?polling_unit_id=PU-2", terminal)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	assert.NotContains(t, w.Body.String(), "10987654321")
+	logs, err := env.services.AuditLogRepository().GetAuditLogsByAction("terminal_polling_unit_mismatch", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, logs, 1)
+}
+
+func TestTerminalEndpointsAreScopedToThePollingUnit(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerTerminal(t, "TERM-001", true)
+	env.startElection(t, "1")
+	terminal := env.tokenFor(t, models.RoleTerminal)
+
+	// Rosters and voted sets of another polling unit are refused
+	for _, path := range []string{"/api/v1/terminal/voters?polling_unit_id=PU-2", "/api/v1/terminal/voted?polling_unit_id=PU-2"} {
+		w := env.do("GET", path, terminal)
+		assert.Equal(t, http.StatusForbidden, w.Code, path)
+		assert.Contains(t, w.Body.String(), "polling_unit_mismatch", path)
+	}
+	logs, err := env.services.AuditLogRepository().GetAuditLogsByAction("terminal_polling_unit_mismatch", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, logs, 2)
+
+	// A token issued before the terminal moved is good for none of its endpoints
+	stale := terminalToken(t, "TERM-001", "PU-2")
+	for _, route := range [][2]string{
+		{"GET", "/api/v1/terminal/TERM-001/status"},
+		{"POST", "/api/v1/terminal/polling-unit/ensure"},
+		{"GET", "/api/v1/terminal/voters"},
+		{"GET", "/api/v1/terminal/voted"},
+		{"POST", "/api/v1/terminal/journal"},
+		{"GET", "/api/v1/terminal/journal/reconciliation"},
+		{"POST", "/api/v1/terminal/TERM-001/heartbeat"},
+		{"GET", "/api/v1/terminal/TERM-001/config"},
+	} {
+		w := env.do(route[0], route[1], stale)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, route[1])
+		assert.Contains(t, w.Body.String(), "stale_token", route[1])
+	}
+
+	// A token for the terminal's own polling unit still works
+	current := terminalToken(t, "TERM-001", "PU-1")
+	for _, path := range []string{"/api/v1/terminal/voted", "/api/v1/terminal/journal/reconciliation", "/api/v1/terminal/TERM-001/status"} {
+		w := env.do("GET", path, current)
+		assert.Equal(t, http.StatusOK, w.Code, path)
+	}
+}
+
+func TestEligibilityIsPerElection(t *testing.T) {
+	env := newTestEnv(t)
+	key := env.registerTerminal(t, "TERM-001", true)
+	env.registerVoter(t, "12345678901", "slot-1")
+	first := env.startElection(t, "1")
+	env.snapshotRoll(t, "1")
+
+	// Voters registered during an election are on the roll of the next one
+	env.registerVoter(t, "10987654321", "slot-2")
+	require.NoError(t, env.services.ElectionRepository().UpdateElectionStatus(first.ID, false))
+	env.startElection(t, "2")
+	vote := types.VoteRequest{NIN: "10987654321", FingerprintData: "slot-9", CandidateID: "APC", PollingUnitID: "PU-1", ElectionID: "2"}
+	signVote(t, key, "TERM-001", &vote)
+	code, reason := castError(t, env, vote)
+	assert.Equal(t, http.StatusForbidden, code, "not until its roll is snapshotted")
+	assert.Equal(t, "not_eligible", reason)
+
+	roll := env.snapshotRoll(t, "2")
+	assert.Equal(t, 2, roll.VoterCount)
+	signVote(t, key, "TERM-001", &vote)
+	code, reason = castError(t, env, vote)
+	assert.Equal(t, http.StatusUnauthorized, code, "eligible voters go on to the fingerprint check")
+	assert.Equal(t, "invalid_fingerprint", reason)
+	eligible, err := env.services.ElectionVoterRepository().IsEligible("1", "10987654321")
+	require.NoError(t, err)
+	assert.False(t, eligible)
+
+	logs, err := env.services.AuditLogRepository().GetAuditLogsByAction("vote_rejected_not_eligible", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, logs, 1)
+}
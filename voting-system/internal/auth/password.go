@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordCost is the bcrypt cost used for new password hashes
+const PasswordCost = 12
+
+// dummyHash is compared against when a user does not exist so that failed
+// logins take the same time whether or not the username is known
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("voting-system-dummy-password"), PasswordCost)
+
+// HashPassword returns a bcrypt hash of the password
+func HashPassword(password string) (string, error) {
+	if password == "" {
+		return "", errors.New("password must not be empty")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), PasswordCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether password matches the bcrypt hash.
+// An empty hash is compared against a dummy hash and always fails.
+func CheckPassword(hash, password string) bool {
+	if hash == "" {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// ValidatePassword checks a new password against the configured policy.
+// A strong password mixes upper case, lower case, digits and symbols.
+func ValidatePassword(password string, minLength int, requireStrong bool) error {
+	if len(password) < minLength {
+		return fmt.Errorf("password must be at least %d characters", minLength)
+	}
+	if !requireStrong {
+		return nil
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if !upper || !lower || !digit || !symbol {
+		return errors.New("password must contain upper and lower case letters, a digit and a symbol")
+	}
+	return nil
+}
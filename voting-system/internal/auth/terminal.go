@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// TerminalTokenMAC returns the HMAC-SHA256 a terminal signs its token request
+// with: the hex MAC of "ts|nonce|device_id" under the terminal's secret
+func TerminalTokenMAC(secret, timestamp, nonce, deviceID string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "|" + nonce + "|" + deviceID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// TerminalTokenMACMatches checks a token request's signature against the
+// MAC under secret in constant time
+func TerminalTokenMACMatches(secret, timestamp, nonce, deviceID, signature string) bool {
+	expected := TerminalTokenMAC(secret, timestamp, nonce, deviceID)
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// RandomToken returns n random bytes encoded as hex
+func RandomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// HashToken returns the SHA-256 hex digest of a token. Only digests of
+// refresh and reset tokens are stored.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// TokenMatches compares a token against a stored digest in constant time
+func TokenMatches(token, digest string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashToken(token)), []byte(digest)) == 1
+}
+
+// NewRefreshToken creates a refresh token for a session. The token has the
+// form "<sessionID>.<secret>" so the session can be looked up directly.
+func NewRefreshToken(sessionID string) (token, secret string, err error) {
+	secret, err = RandomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	return sessionID + "." + secret, secret, nil
+}
+
+// SplitRefreshToken returns the session ID and secret of a refresh token
+func SplitRefreshToken(token string) (sessionID, secret string, err error) {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.New("malformed refresh token")
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret is the SHA-1 seed from RFC 6238 Appendix B
+var rfc6238Secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPMatchesRFC6238Vectors(t *testing.T) {
+	// RFC 6238 Appendix B uses 8 digits; the last 6 digits are the 6-digit code
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	key, err := decodeTOTPSecret(rfc6238Secret)
+	require.NoError(t, err)
+
+	for _, v := range vectors {
+		step := TOTPStep(time.Unix(v.unix, 0))
+		assert.Equal(t, v.code, hotp(key, uint64(step), 8), "time %d", v.unix)
+
+		code, err := TOTPCode(rfc6238Secret, step)
+		require.NoError(t, err)
+		assert.Equal(t, v.code[2:], code, "time %d", v.unix)
+	}
+}
+
+func TestValidateTOTPAllowsOneStepOfSkew(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	step := TOTPStep(now)
+
+	for _, offset := range []int64{-1, 0, 1} {
+		code, err := TOTPCode(secret, step+offset)
+		require.NoError(t, err)
+		matched, ok := ValidateTOTP(secret, code, now)
+		assert.True(t, ok, "offset %d", offset)
+		assert.Equal(t, step+offset, matched)
+	}
+
+	for _, offset := range []int64{-3, 3} {
+		code, err := TOTPCode(secret, step+offset)
+		require.NoError(t, err)
+		_, ok := ValidateTOTP(secret, code, now)
+		assert.False(t, ok, "offset %d", offset)
+	}
+
+	_, ok := ValidateTOTP(secret, "12345", now)
+	assert.False(t, ok)
+	_, ok = ValidateTOTP("not base32!", "123456", now)
+	assert.False(t, ok)
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("Voting System", "alice@example.com", "JBSWY3DPEHPK3PXP")
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Voting%20System:alice@example.com?"))
+	assert.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	assert.Contains(t, uri, "issuer=Voting+System")
+	assert.Contains(t, uri, "digits=6")
+	assert.Contains(t, uri, "period=30")
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := NewRecoveryCodes(10)
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		assert.Len(t, code, 11)
+		assert.False(t, seen[code])
+		seen[code] = true
+		assert.Equal(t, NormalizeRecoveryCode(code), NormalizeRecoveryCode(strings.ToUpper(strings.ReplaceAll(code, "-", " "))))
+	}
+}
+
+func TestSealRoundTrip(t *testing.T) {
+	sealed, err := Seal("key", "JBSWY3DPEHPK3PXP")
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, "JBSWY3DPEHPK3PXP")
+
+	opened, err := Open("key", sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", opened)
+
+	_, err = Open("other", sealed)
+	assert.Error(t, err)
+	_, err = Seal("", "x")
+	assert.Error(t, err)
+}
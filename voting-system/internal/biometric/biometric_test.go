@@ -0,0 +1,175 @@
+package biometric
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"voting-system/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// finger returns a synthetic finger of n minutiae
+func finger(seed int64, n int) []Minutia {
+	r := rand.New(rand.NewSource(seed))
+	minutiae := make([]Minutia, n)
+	for i := range minutiae {
+		minutiae[i] = Minutia{
+			X:       r.Float64() * 300,
+			Y:       r.Float64() * 400,
+			Angle:   r.Float64() * 360,
+			Type:    []string{RidgeEnding, RidgeBifurcation}[r.Intn(2)],
+			Quality: 0.9,
+		}
+	}
+	return minutiae
+}
+
+// capture places a finger on the sensor again: rotated, shifted and jittered,
+// with some minutiae missed and some spurious ones found
+func capture(t *testing.T, minutiae []Minutia, seed int64, rotation, dx, dy float64, missed, spurious int) string {
+	t.Helper()
+
+	r := rand.New(rand.NewSource(seed))
+	var captured []Minutia
+	for _, m := range minutiae[missed:] {
+		x, y := rotate(m.X, m.Y, rotation)
+		captured = append(captured, Minutia{
+			X:       x + dx + r.Float64()*4 - 2,
+			Y:       y + dy + r.Float64()*4 - 2,
+			Angle:   m.Angle + rotation + r.Float64()*6 - 3,
+			Type:    m.Type,
+			Quality: m.Quality,
+		})
+	}
+	captured = append(captured, finger(seed+1000, spurious)...)
+	return sample(t, captured)
+}
+
+func sample(t *testing.T, minutiae []Minutia) string {
+	t.Helper()
+	data, err := json.Marshal(MinutiaeSample{Minutiae: minutiae})
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestMinutiaeMatchesTheSameFinger(t *testing.T) {
+	ada := finger(1, 30)
+	enrolled, err := Minutiae{}.Enrol(sample(t, ada))
+	require.NoError(t, err)
+	assert.InDelta(t, 0.9, enrolled.Quality, 0.001)
+
+	same, err := Minutiae{}.Score(capture(t, ada, 2, 20, 35, -15, 3, 2), enrolled)
+	require.NoError(t, err)
+	assert.Greater(t, same, 0.85, "a new capture of the same finger matches")
+
+	other, err := Minutiae{}.Score(capture(t, finger(3, 30), 4, 0, 0, 0, 0, 0), enrolled)
+	require.NoError(t, err)
+	assert.Less(t, other, 0.4, "another finger does not")
+}
+
+func TestMinutiaeQuality(t *testing.T) {
+	sparse, err := Minutiae{}.Enrol(sample(t, finger(1, 6)))
+	require.NoError(t, err)
+	assert.InDelta(t, 0.45, sparse.Quality, 0.001, "captures with few minutiae lose quality")
+
+	for _, bad := range []string{"", "not json", `{"minutiae":[]}`, `{"minutiae":[{"type":"loop","quality":1}]}`} {
+		_, err := Minutiae{}.Enrol(bad)
+		assert.ErrorIs(t, err, ErrInvalidSample, bad)
+	}
+}
+
+func TestServiceThresholds(t *testing.T) {
+	key := []byte("fingerprint-key")
+	service, err := NewService(config.BiometricConfig{
+		Matcher: FormatMinutiae, QualityThreshold: 0.8, MatchThreshold: 0.85, MaxAttempts: 3,
+	}, key)
+	require.NoError(t, err)
+	assert.Equal(t, 3, service.MaxAttempts())
+
+	_, err = service.Enrol(sample(t, finger(1, 6)))
+	assert.ErrorIs(t, err, ErrLowQuality)
+
+	ada, bola := finger(1, 30), finger(5, 30)
+	adaTemplate, err := service.Enrol(sample(t, ada))
+	require.NoError(t, err)
+	bolaTemplate, err := service.Enrol(sample(t, bola))
+	require.NoError(t, err)
+
+	score, matched, err := service.Verify(capture(t, ada, 2, -10, 5, 5, 2, 1), adaTemplate)
+	require.NoError(t, err)
+	assert.True(t, matched, "score %.2f", score)
+	_, matched, err = service.Verify(capture(t, ada, 2, -10, 5, 5, 2, 1), bolaTemplate)
+	require.NoError(t, err)
+	assert.False(t, matched)
+
+	// Voters enrolled with the sensor's slot IDs still verify exactly
+	slot, err := Exact{Key: key}.Enrol("slot-7")
+	require.NoError(t, err)
+	_, matched, err = service.Verify("slot-7", slot)
+	require.NoError(t, err)
+	assert.True(t, matched)
+	legacy, err := Exact{}.Enrol("slot-8")
+	require.NoError(t, err)
+	_, matched, err = service.Verify("slot-8", legacy)
+	require.NoError(t, err)
+	assert.True(t, matched, "unkeyed templates from before the vault still verify")
+
+	matches, err := service.Identify(capture(t, bola, 6, 45, -20, 10, 2, 2), []Candidate{
+		{ID: "ada", Template: adaTemplate},
+		{ID: "bola", Template: bolaTemplate},
+		{ID: "slot", Template: slot},
+	})
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "bola", matches[0].ID)
+}
+
+func TestExactMatcher(t *testing.T) {
+	_, err := NewMatcher("", nil)
+	assert.ErrorIs(t, err, ErrNoKey)
+
+	matcher, err := NewMatcher("", []byte("fingerprint-key"))
+	require.NoError(t, err)
+	assert.Equal(t, FormatExact, matcher.Format())
+
+	enrolled, err := matcher.Enrol("slot-1")
+	require.NoError(t, err)
+	assert.Equal(t, Hash([]byte("fingerprint-key"), "slot-1"), enrolled.Data)
+	assert.NotEqual(t, Hash(nil, "slot-1"), enrolled.Data, "keyed hashes differ from plain SHA-256")
+
+	score, err := matcher.Score("slot-1", enrolled)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, score)
+	score, err = matcher.Score("slot-2", enrolled)
+	require.NoError(t, err)
+	assert.Zero(t, score)
+
+	_, err = NewMatcher("iris", nil)
+	assert.Error(t, err)
+}
+
+func TestVault(t *testing.T) {
+	_, err := NewVault("")
+	assert.Error(t, err)
+
+	vault, err := NewVault("encryption-key")
+	require.NoError(t, err)
+	template, err := Minutiae{}.Enrol(sample(t, finger(1, 30)))
+	require.NoError(t, err)
+
+	sealed, err := vault.Seal(template.Data)
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, "minutiae")
+	opened, err := vault.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, template.Data, opened)
+
+	other, err := NewVault("another-key")
+	require.NoError(t, err)
+	_, err = other.Open(sealed)
+	assert.Error(t, err, "templates only open with the key they were sealed with")
+	assert.NotEqual(t, vault.FingerprintKey(), other.FingerprintKey())
+}
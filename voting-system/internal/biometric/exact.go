@@ -0,0 +1,57 @@
+package biometric
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+// Exact matches captures byte for byte. It suits sensors that match on the
+// device and report the matched slot ID, like the ESP32's; its template is a
+// keyed hash of the capture, so it is also the voter's stored fingerprint
+// hash. Without a key it reads the unkeyed SHA-256 templates of voters
+// enrolled before hashes were keyed.
+type Exact struct {
+	Key []byte // the vault's fingerprint key
+}
+
+// Format returns FormatExact, or FormatSHA256 for the unkeyed matcher
+func (e Exact) Format() string {
+	if len(e.Key) == 0 {
+		return FormatSHA256
+	}
+	return FormatExact
+}
+
+// Enrol hashes the capture
+func (e Exact) Enrol(sample string) (*Template, error) {
+	if sample == "" {
+		return nil, ErrInvalidSample
+	}
+	return &Template{Format: e.Format(), Data: Hash(e.Key, sample), Quality: 1}, nil
+}
+
+// Score is 1 when the capture hashes to the template and 0 otherwise
+func (e Exact) Score(sample string, enrolled *Template) (float64, error) {
+	if sample == "" {
+		return 0, ErrInvalidSample
+	}
+	if subtle.ConstantTimeCompare([]byte(Hash(e.Key, sample)), []byte(enrolled.Data)) != 1 {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+// Hash returns the hex HMAC-SHA256 of a capture under key, the fingerprint
+// hash voters are registered with. Without a key it is the plain SHA-256
+// voters were registered with before.
+func Hash(key []byte, sample string) string {
+	if len(key) == 0 {
+		hash := sha256.Sum256([]byte(sample))
+		return hex.EncodeToString(hash[:])
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(sample))
+	return hex.EncodeToString(mac.Sum(nil))
+}
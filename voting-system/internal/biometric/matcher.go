@@ -0,0 +1,62 @@
+// Package biometric matches fingerprints. A Matcher turns a capture into a
+// template at enrolment and scores later captures against it; the Service
+// applies the configured quality and match thresholds on top and searches the
+// enrolled templates for duplicates at registration.
+package biometric
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Template formats
+const (
+	FormatExact    = "exact"    // the sensor matched on-device and sends its slot ID
+	FormatMinutiae = "minutiae" // minutiae extracted from the capture
+	FormatSHA256   = "sha256"   // unkeyed exact templates, read but no longer enrolled
+)
+
+var (
+	// ErrInvalidSample is returned for a capture a matcher cannot read
+	ErrInvalidSample = errors.New("biometric: invalid fingerprint sample")
+	// ErrLowQuality is returned when a capture is below the quality threshold
+	ErrLowQuality = errors.New("biometric: fingerprint quality is below the threshold")
+	// ErrNoKey is returned for exact matching without a fingerprint key
+	ErrNoKey = errors.New("biometric: exact matching needs a fingerprint key")
+)
+
+// Template is an enrolled fingerprint in a matcher's format
+type Template struct {
+	Format  string  `json:"format"`
+	Data    string  `json:"data"`
+	Quality float64 `json:"quality"` // 0 to 1
+}
+
+// Matcher enrols and compares fingerprints of one template format
+type Matcher interface {
+	// Format returns the template format the matcher produces and reads
+	Format() string
+	// Enrol extracts a template from a capture
+	Enrol(sample string) (*Template, error)
+	// Score compares a capture with an enrolled template. Scores run from 0
+	// for no resemblance to 1 for an identical finger.
+	Score(sample string, enrolled *Template) (float64, error)
+}
+
+// NewMatcher returns the matcher for a template format. An empty format is
+// the exact matcher, which hashes captures under key.
+func NewMatcher(format string, key []byte) (Matcher, error) {
+	switch format {
+	case "", FormatExact:
+		if len(key) == 0 {
+			return nil, ErrNoKey
+		}
+		return Exact{Key: key}, nil
+	case FormatSHA256:
+		return Exact{}, nil
+	case FormatMinutiae:
+		return Minutiae{}, nil
+	default:
+		return nil, fmt.Errorf("biometric: unknown template format %q", format)
+	}
+}
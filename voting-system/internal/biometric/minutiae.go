@@ -0,0 +1,201 @@
+package biometric
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Minutia types
+const (
+	RidgeEnding      = "ending"
+	RidgeBifurcation = "bifurcation"
+)
+
+const (
+	minMinutiae       = 12  // captures with fewer minutiae lose quality
+	maxMinutiae       = 200 // captures with more are refused
+	distanceTolerance = 12.0
+	angleTolerance    = 15.0 // degrees
+	rotationBin       = 10.0 // degrees
+	translationBin    = 10.0
+)
+
+// Minutia is a ridge ending or bifurcation, in sensor pixels
+type Minutia struct {
+	X       float64 `json:"x"`
+	Y       float64 `json:"y"`
+	Angle   float64 `json:"angle"` // ridge direction in degrees
+	Type    string  `json:"type"`
+	Quality float64 `json:"quality"` // 0 to 1
+}
+
+// MinutiaeSample is the capture the minutiae matcher reads, as sent by the
+// terminal's extractor
+type MinutiaeSample struct {
+	Minutiae []Minutia `json:"minutiae"`
+}
+
+// Minutiae is the reference minutiae matcher. It aligns a capture with the
+// enrolled template by voting over the rotation and translation that map
+// minutiae of the same type onto each other, then pairs the minutiae that
+// land within tolerance. The score is the share of minutiae paired.
+type Minutiae struct{}
+
+// Format returns FormatMinutiae
+func (Minutiae) Format() string {
+	return FormatMinutiae
+}
+
+// Enrol validates the capture and stores its minutiae. The template's quality
+// is the mean minutia quality, reduced for captures with few minutiae.
+func (Minutiae) Enrol(sample string) (*Template, error) {
+	minutiae, err := parseMinutiae(sample)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(MinutiaeSample{Minutiae: minutiae})
+	if err != nil {
+		return nil, err
+	}
+
+	quality := 0.0
+	for _, m := range minutiae {
+		quality += m.Quality
+	}
+	quality /= float64(len(minutiae))
+	if len(minutiae) < minMinutiae {
+		quality *= float64(len(minutiae)) / minMinutiae
+	}
+
+	return &Template{Format: FormatMinutiae, Data: string(data), Quality: quality}, nil
+}
+
+// Score aligns the capture with the template and returns the share of
+// minutiae that pair up
+func (Minutiae) Score(sample string, enrolled *Template) (float64, error) {
+	probe, err := parseMinutiae(sample)
+	if err != nil {
+		return 0, err
+	}
+	gallery, err := parseMinutiae(enrolled.Data)
+	if err != nil {
+		return 0, fmt.Errorf("biometric: invalid template: %v", err)
+	}
+
+	rotation, dx, dy, ok := align(probe, gallery)
+	if !ok {
+		return 0, nil
+	}
+
+	paired := make([]bool, len(gallery))
+	matched := 0
+	for _, p := range probe {
+		x, y := rotate(p.X, p.Y, rotation)
+		x, y = x+dx, y+dy
+		angle := p.Angle + rotation
+
+		best, bestDistance := -1, distanceTolerance
+		for i, g := range gallery {
+			if paired[i] || g.Type != p.Type || math.Abs(angleDiff(g.Angle, angle)) > angleTolerance {
+				continue
+			}
+			if d := math.Hypot(g.X-x, g.Y-y); d <= bestDistance {
+				best, bestDistance = i, d
+			}
+		}
+		if best >= 0 {
+			paired[best] = true
+			matched++
+		}
+	}
+
+	return 2 * float64(matched) / float64(len(probe)+len(gallery)), nil
+}
+
+// align finds the rotation and translation most pairs of same-type minutiae
+// agree on, averaged over the pairs in the winning bin
+func align(probe, gallery []Minutia) (rotation, dx, dy float64, ok bool) {
+	type bin struct{ r, x, y int }
+	type votes struct {
+		count            int
+		rotation, dx, dy float64
+	}
+
+	bins := make(map[bin]*votes)
+	var best bin
+	for _, p := range probe {
+		for _, g := range gallery {
+			if p.Type != g.Type {
+				continue
+			}
+			r := angleDiff(g.Angle, p.Angle)
+			x, y := rotate(p.X, p.Y, r)
+			tx, ty := g.X-x, g.Y-y
+
+			key := bin{int(math.Round(r / rotationBin)), int(math.Round(tx / translationBin)), int(math.Round(ty / translationBin))}
+			v := bins[key]
+			if v == nil {
+				v = &votes{}
+				bins[key] = v
+			}
+			v.count++
+			v.rotation += r
+			v.dx += tx
+			v.dy += ty
+
+			// The first bin to reach the top count wins ties
+			if b := bins[best]; b == nil || v.count > b.count {
+				best = key
+			}
+		}
+	}
+
+	v := bins[best]
+	if v == nil {
+		return 0, 0, 0, false
+	}
+	n := float64(v.count)
+	return v.rotation / n, v.dx / n, v.dy / n, true
+}
+
+func parseMinutiae(sample string) ([]Minutia, error) {
+	var s MinutiaeSample
+	if err := json.Unmarshal([]byte(sample), &s); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSample, err)
+	}
+	if len(s.Minutiae) == 0 || len(s.Minutiae) > maxMinutiae {
+		return nil, fmt.Errorf("%w: %d minutiae, want 1 to %d", ErrInvalidSample, len(s.Minutiae), maxMinutiae)
+	}
+	for i := range s.Minutiae {
+		m := &s.Minutiae[i]
+		if m.Type != RidgeEnding && m.Type != RidgeBifurcation {
+			return nil, fmt.Errorf("%w: unknown minutia type %q", ErrInvalidSample, m.Type)
+		}
+		if m.Quality < 0 || m.Quality > 1 {
+			return nil, fmt.Errorf("%w: minutia quality must be between 0 and 1", ErrInvalidSample)
+		}
+		m.Angle = math.Mod(m.Angle, 360)
+		if m.Angle < 0 {
+			m.Angle += 360
+		}
+	}
+	return s.Minutiae, nil
+}
+
+// angleDiff returns a - b in degrees, between -180 and 180
+func angleDiff(a, b float64) float64 {
+	d := math.Mod(a-b, 360)
+	if d > 180 {
+		d -= 360
+	} else if d <= -180 {
+		d += 360
+	}
+	return d
+}
+
+func rotate(x, y, degrees float64) (float64, float64) {
+	sin, cos := math.Sincos(degrees * math.Pi / 180)
+	return x*cos - y*sin, x*sin + y*cos
+}
@@ -0,0 +1,117 @@
+package biometric
+
+import (
+	"sort"
+
+	"voting-system/pkg/config"
+)
+
+// Candidate is an enrolled template searched at registration
+type Candidate struct {
+	ID       string // the voter's NIN
+	Template *Template
+}
+
+// Match is a candidate whose template a capture matched
+type Match struct {
+	ID    string  `json:"id"`
+	Score float64 `json:"score"`
+}
+
+// Service enrols and verifies fingerprints with the configured matcher and
+// thresholds. Templates are verified with the matcher of their own format, so
+// voters enrolled before a change of matcher still verify.
+type Service struct {
+	matcher          Matcher
+	key              []byte
+	qualityThreshold float64
+	matchThreshold   float64
+	maxAttempts      int
+}
+
+// NewService returns a service for the biometric configuration. Exact
+// templates are hashed under key, the vault's fingerprint key.
+func NewService(cfg config.BiometricConfig, key []byte) (*Service, error) {
+	matcher, err := NewMatcher(cfg.Matcher, key)
+	if err != nil {
+		return nil, err
+	}
+	return &Service{
+		matcher:          matcher,
+		key:              key,
+		qualityThreshold: cfg.QualityThreshold,
+		matchThreshold:   cfg.MatchThreshold,
+		maxAttempts:      cfg.MaxAttempts,
+	}, nil
+}
+
+// Format returns the format new templates are enrolled in
+func (s *Service) Format() string {
+	return s.matcher.Format()
+}
+
+// Hash returns the keyed fingerprint hash of a capture
+func (s *Service) Hash(sample string) string {
+	return Hash(s.key, sample)
+}
+
+// MaxAttempts returns how many failed verifications lock a voter out. Zero
+// disables the lockout.
+func (s *Service) MaxAttempts() int {
+	return s.maxAttempts
+}
+
+// Enrol extracts a template from a capture, refusing captures below the
+// quality threshold with ErrLowQuality
+func (s *Service) Enrol(sample string) (*Template, error) {
+	template, err := s.matcher.Enrol(sample)
+	if err != nil {
+		return nil, err
+	}
+	if template.Quality < s.qualityThreshold {
+		return template, ErrLowQuality
+	}
+	return template, nil
+}
+
+// Verify compares a capture with a voter's enrolled template, 1:1. It
+// returns the score and whether it reaches the match threshold.
+func (s *Service) Verify(sample string, enrolled *Template) (float64, bool, error) {
+	matcher, err := NewMatcher(enrolled.Format, s.key)
+	if err != nil {
+		return 0, false, err
+	}
+	score, err := matcher.Score(sample, enrolled)
+	if err != nil {
+		return 0, false, err
+	}
+	return score, s.matches(score), nil
+}
+
+// Identify searches a gallery for the capture, 1:N, and returns the
+// candidates it matches, best first. Templates in other formats than the
+// capture's are skipped.
+func (s *Service) Identify(sample string, gallery []Candidate) ([]Match, error) {
+	var matches []Match
+	for _, candidate := range gallery {
+		if candidate.Template.Format != s.matcher.Format() {
+			continue
+		}
+		score, err := s.matcher.Score(sample, candidate.Template)
+		if err != nil {
+			return nil, err
+		}
+		if s.matches(score) {
+			matches = append(matches, Match{ID: candidate.ID, Score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches, nil
+}
+
+func (s *Service) matches(score float64) bool {
+	return score > 0 && score >= s.matchThreshold
+}
@@ -0,0 +1,52 @@
+package biometric
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"voting-system/internal/auth"
+)
+
+// Vault keeps enrolled templates encrypted at rest. Both its sealing key and
+// the key fingerprint hashes are computed with are derived from the server's
+// encryption key, so neither a template nor a hash can be read or recomputed
+// from a capture without it.
+type Vault struct {
+	sealKey        string
+	fingerprintKey []byte
+}
+
+// NewVault returns the vault for the server's encryption key
+func NewVault(secret string) (*Vault, error) {
+	if secret == "" {
+		return nil, errors.New("biometric: template vault needs an encryption key")
+	}
+	return &Vault{
+		sealKey:        hex.EncodeToString(deriveKey(secret, "voter template vault")),
+		fingerprintKey: deriveKey(secret, "voter fingerprint hash"),
+	}, nil
+}
+
+// FingerprintKey returns the key fingerprint hashes and exact templates are
+// computed with
+func (v *Vault) FingerprintKey() []byte {
+	return v.fingerprintKey
+}
+
+// Seal encrypts a template's data for storage
+func (v *Vault) Seal(data string) (string, error) {
+	return auth.Seal(v.sealKey, data)
+}
+
+// Open decrypts template data sealed by Seal
+func (v *Vault) Open(sealed string) (string, error) {
+	return auth.Open(v.sealKey, sealed)
+}
+
+func deriveKey(secret, purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
@@ -3,15 +3,19 @@ package blockchain
 import (
 	"context"
 	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
 	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -24,6 +28,48 @@ type VoteData struct {
 	EncryptedVote    string
 	PollingUnitID    string
 	CandidateID      string
+	// ElectionID is the election the verification hash was derived for. Ballot
+	// is set for elections with ballot secrecy: it is the JSON-encoded
+	// tally.Ballot and CandidateID is left empty.
+	ElectionID string
+	Ballot     string
+	// Terminal, Nonce, SignedAt and Signature carry the casting terminal's
+	// EIP-712 signature. Signed votes are relayed so the contract records the
+	// terminal, not the server's account, as the one that cast them.
+	Terminal  string
+	Nonce     string // 32 bytes as hex
+	SignedAt  int64  // unix seconds
+	Signature string // 65 bytes as hex
+}
+
+// IsEncrypted reports whether the vote carries an encrypted ballot
+func (v VoteData) IsEncrypted() bool {
+	return v.Ballot != ""
+}
+
+// IsRelayed reports whether the vote carries a terminal signature to relay
+func (v VoteData) IsRelayed() bool {
+	return v.Signature != ""
+}
+
+// relayArgs decodes the terminal signature fields for castVoteBySig
+func (v VoteData) relayArgs() ([32]byte, *big.Int, []byte, error) {
+	var nonce [32]byte
+	raw, err := hex.DecodeString(strings.TrimPrefix(v.Nonce, "0x"))
+	if err != nil || len(raw) != len(nonce) {
+		return nonce, nil, nil, fmt.Errorf("invalid vote nonce %q", v.Nonce)
+	}
+	copy(nonce[:], raw)
+
+	signature, err := hexutil.Decode(v.Signature)
+	if err != nil || len(signature) != crypto.SignatureLength {
+		return nonce, nil, nil, fmt.Errorf("invalid terminal signature")
+	}
+	// The contract only accepts the 27/28 recovery id convention
+	if signature[crypto.RecoveryIDOffset] < 27 {
+		signature[crypto.RecoveryIDOffset] += 27
+	}
+	return nonce, big.NewInt(v.SignedAt), signature, nil
 }
 
 // ElectionData represents election information
@@ -45,6 +91,7 @@ type VoteInfo struct {
 	PollingUnitID    string
 	ElectionID       *big.Int
 	IsValid          bool
+	Terminal         string // address of the terminal that cast the vote
 }
 
 // PollingUnitData mirrors returned fields from contract pollingUnits mapping
@@ -64,6 +111,7 @@ type BlockchainClient struct {
 	contractAddress common.Address
 	privateKey      *ecdsa.PrivateKey
 	auth            *bind.TransactOpts
+	nonces          *NonceManager
 	callOpts        *bind.CallOpts
 	chainID         *big.Int
 }
@@ -113,6 +161,7 @@ func NewBlockchainClient(nodeURL, contractAddress, privateKeyHex string) (*Block
 		contractAddress: contractAddr,
 		privateKey:      privateKey,
 		auth:            auth,
+		nonces:          NewNonceManager(client, auth.From),
 		callOpts:        &bind.CallOpts{},
 		chainID:         chainID,
 	}, nil
@@ -134,17 +183,84 @@ func (bc *BlockchainClient) CastVote(voteData VoteData) (*types.Transaction, err
 	encryptedVote := [32]byte{}
 	copy(encryptedVote[:], crypto.Keccak256([]byte(voteData.EncryptedVote)))
 
+	// Signed votes are relayed with the terminal's signature
+	var (
+		nonce     [32]byte
+		signedAt  *big.Int
+		signature []byte
+	)
+	if voteData.IsRelayed() {
+		var err error
+		if nonce, signedAt, signature, err = voteData.relayArgs(); err != nil {
+			return nil, fmt.Errorf("failed to cast vote: %v", err)
+		}
+	}
+
+	// Encrypted and relayed votes name their election on chain
+	var electionID *big.Int
+	if voteData.IsEncrypted() || voteData.IsRelayed() {
+		var ok bool
+		if electionID, ok = new(big.Int).SetString(voteData.ElectionID, 10); !ok {
+			return nil, fmt.Errorf("failed to cast vote: invalid election ID %q", voteData.ElectionID)
+		}
+	}
+
+	opts, err := bc.transactOpts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to cast vote: %v", err)
+	}
+
+	// Encrypted ballots only put a hash of the ballot on chain
+	if voteData.IsEncrypted() {
+		ballotHash := [32]byte{}
+		copy(ballotHash[:], crypto.Keccak256([]byte(voteData.Ballot)))
+
+		log.Printf("Casting encrypted vote - PollingUnit: %s, Election: %s",
+			voteData.PollingUnitID, voteData.ElectionID)
+
+		var tx *types.Transaction
+		if voteData.IsRelayed() {
+			tx, err = bc.contract.CastEncryptedVoteBySig(opts, verificationHash, ballotHash, electionID, voteData.PollingUnitID,
+				nonce, signedAt, signature)
+		} else {
+			tx, err = bc.contract.CastEncryptedVote(opts, verificationHash, ballotHash, electionID, voteData.PollingUnitID)
+		}
+		bc.settleNonce(opts, err)
+		if err != nil {
+			return nil, fmt.Errorf("failed to cast vote: %v", err)
+		}
+
+		log.Printf("Vote cast successfully. Transaction hash: %s", tx.Hash().Hex())
+		return tx, nil
+	}
+
 	log.Printf("Casting vote - PollingUnit: %s, Candidate: %s",
 		voteData.PollingUnitID, voteData.CandidateID)
 
 	// Call the smart contract
-	tx, err := bc.contract.CastVote(
-		bc.auth,
-		verificationHash,
-		encryptedVote,
-		voteData.PollingUnitID,
-		voteData.CandidateID,
-	)
+	var tx *types.Transaction
+	if voteData.IsRelayed() {
+		tx, err = bc.contract.CastVoteBySig(
+			opts,
+			verificationHash,
+			encryptedVote,
+			voteData.PollingUnitID,
+			voteData.CandidateID,
+			electionID,
+			nonce,
+			signedAt,
+			signature,
+		)
+	} else {
+		tx, err = bc.contract.CastVote(
+			opts,
+			verificationHash,
+			encryptedVote,
+			voteData.PollingUnitID,
+			voteData.CandidateID,
+		)
+	}
+	bc.settleNonce(opts, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to cast vote: %v", err)
 	}
@@ -153,13 +269,13 @@ func (bc *BlockchainClient) CastVote(voteData VoteData) (*types.Transaction, err
 	return tx, nil
 }
 
-// HasVoterVoted checks if a voter has already voted
-func (bc *BlockchainClient) HasVoterVoted(verificationHash string) (bool, error) {
+// HasVoterVoted checks if a voter has already voted in an election
+func (bc *BlockchainClient) HasVoterVoted(electionID *big.Int, verificationHash string) (bool, error) {
 	// Convert to bytes32
 	hash := [32]byte{}
 	copy(hash[:], crypto.Keccak256([]byte(verificationHash)))
 
-	hasVoted, err := bc.contract.HasVoterVoted(bc.callOpts, hash)
+	hasVoted, err := bc.contract.HasVoterVoted(bc.callOpts, electionID, hash)
 	if err != nil {
 		return false, fmt.Errorf("failed to check voter status: %v", err)
 	}
@@ -212,6 +328,11 @@ func (bc *BlockchainClient) GetVoteDetails(voteID *big.Int) (*VoteInfo, error) {
 		return nil, fmt.Errorf("failed to get vote details: %v", err)
 	}
 
+	terminal, err := bc.contract.GetVoteTerminal(bc.callOpts, voteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vote terminal: %v", err)
+	}
+
 	return &VoteInfo{
 		VerificationHash: common.Bytes2Hex(result.VerificationHash[:]),
 		EncryptedVote:    common.Bytes2Hex(result.EncryptedVote[:]),
@@ -219,6 +340,7 @@ func (bc *BlockchainClient) GetVoteDetails(voteID *big.Int) (*VoteInfo, error) {
 		PollingUnitID:    result.PollingUnitId,
 		ElectionID:       result.ElectionId,
 		IsValid:          result.IsValid,
+		Terminal:         terminal.Hex(),
 	}, nil
 }
 
@@ -249,6 +371,16 @@ func (bc *BlockchainClient) IsTerminalAuthorized(terminalAddress common.Address)
 	return isAuthorized, nil
 }
 
+// DomainSeparator returns the contract's EIP-712 domain separator, which
+// terminal vote signatures are bound to
+func (bc *BlockchainClient) DomainSeparator() (common.Hash, error) {
+	separator, err := bc.contract.DomainSeparator(bc.callOpts)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get domain separator: %v", err)
+	}
+	return separator, nil
+}
+
 // WaitForTransaction waits for a transaction to be mined and returns the receipt
 func (bc *BlockchainClient) WaitForTransaction(tx *types.Transaction) (*types.Receipt, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
@@ -266,6 +398,18 @@ func (bc *BlockchainClient) WaitForTransaction(tx *types.Transaction) (*types.Re
 	return receipt, nil
 }
 
+// GetPendingReceipt returns the receipt for a transaction, or nil if it has not been mined yet
+func (bc *BlockchainClient) GetPendingReceipt(txHash common.Hash) (*types.Receipt, error) {
+	receipt, err := bc.client.TransactionReceipt(context.Background(), txHash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction receipt: %v", err)
+	}
+	return receipt, nil
+}
+
 // GetTransactionStatus checks the status of a transaction
 func (bc *BlockchainClient) GetTransactionStatus(txHash common.Hash) (*types.Receipt, error) {
 	receipt, err := bc.client.TransactionReceipt(context.Background(), txHash)
@@ -353,6 +497,28 @@ func (bc *BlockchainClient) SubscribeToVoteEvents(ch chan<- *SecureVotingSystemV
 	return sub, nil
 }
 
+// transactOpts returns a copy of the signer options carrying the next locally managed nonce
+func (bc *BlockchainClient) transactOpts() (*bind.TransactOpts, error) {
+	nonce, err := bc.nonces.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := *bc.auth
+	opts.Nonce = new(big.Int).SetUint64(nonce)
+	return &opts, nil
+}
+
+// settleNonce hands the nonce of opts back to the nonce manager once the
+// transaction has been submitted or has failed to submit
+func (bc *BlockchainClient) settleNonce(opts *bind.TransactOpts, err error) {
+	if err != nil {
+		bc.nonces.Release(opts.Nonce.Uint64(), err)
+		return
+	}
+	bc.nonces.Sent()
+}
+
 // Helper function to convert string to bytes32
 func stringToBytes32(s string) [32]byte {
 	var result [32]byte
@@ -393,7 +559,12 @@ func (bc *BlockchainClient) UpdateGasPrice() error {
 // AuthorizeTerminal authorizes or deauthorizes a terminal address (owner only)
 func (bc *BlockchainClient) AuthorizeTerminal(address string, status bool) (*types.Transaction, error) {
 	addr := common.HexToAddress(address)
-	tx, err := bc.contract.AuthorizeTerminal(bc.auth, addr, status)
+	opts, err := bc.transactOpts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to authorize terminal: %v", err)
+	}
+	tx, err := bc.contract.AuthorizeTerminal(opts, addr, status)
+	bc.settleNonce(opts, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to authorize terminal: %v", err)
 	}
@@ -402,7 +573,12 @@ func (bc *BlockchainClient) AuthorizeTerminal(address string, status bool) (*typ
 
 // CreateElection creates a new election (owner only). Returns the tx and, after it's mined, you can call GetTotalElections to infer the new ID.
 func (bc *BlockchainClient) CreateElection(name string, startTime, endTime *big.Int, candidates []string) (*types.Transaction, error) {
-	tx, err := bc.contract.CreateElection(bc.auth, name, startTime, endTime, candidates)
+	opts, err := bc.transactOpts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create election: %v", err)
+	}
+	tx, err := bc.contract.CreateElection(opts, name, startTime, endTime, candidates)
+	bc.settleNonce(opts, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create election: %v", err)
 	}
@@ -411,16 +587,63 @@ func (bc *BlockchainClient) CreateElection(name string, startTime, endTime *big.
 
 // StartElection starts the given election (owner only)
 func (bc *BlockchainClient) StartElection(electionID *big.Int) (*types.Transaction, error) {
-	tx, err := bc.contract.StartElection(bc.auth, electionID)
+	opts, err := bc.transactOpts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start election: %v", err)
+	}
+	tx, err := bc.contract.StartElection(opts, electionID)
+	bc.settleNonce(opts, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to start election: %v", err)
 	}
 	return tx, nil
 }
 
+// PublishVoterRoll publishes the Merkle root of an election's voter roll
+func (bc *BlockchainClient) PublishVoterRoll(electionID *big.Int, root [32]byte, voterCount int) (*types.Transaction, error) {
+	opts, err := bc.transactOpts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish voter roll: %v", err)
+	}
+	tx, err := bc.contract.PublishVoterRoll(opts, electionID, root, big.NewInt(int64(voterCount)))
+	bc.settleNonce(opts, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish voter roll: %v", err)
+	}
+	return tx, nil
+}
+
+// GetVoterRollRoot returns the published Merkle root of an election's voter
+// roll, zero if none was published
+func (bc *BlockchainClient) GetVoterRollRoot(electionID *big.Int) ([32]byte, error) {
+	root, err := bc.contract.VoterRollRoots(bc.callOpts, electionID)
+	if err != nil {
+		return root, fmt.Errorf("failed to get voter roll root: %v", err)
+	}
+	return root, nil
+}
+
+// IsOnVoterRoll checks a voter's inclusion proof against an election's
+// published roll
+func (bc *BlockchainClient) IsOnVoterRoll(electionID *big.Int, verificationHash string, proof [][32]byte) (bool, error) {
+	hash := [32]byte{}
+	copy(hash[:], crypto.Keccak256([]byte(verificationHash)))
+
+	onRoll, err := bc.contract.IsOnVoterRoll(bc.callOpts, electionID, hash, proof)
+	if err != nil {
+		return false, fmt.Errorf("failed to check voter roll: %v", err)
+	}
+	return onRoll, nil
+}
+
 // EndElection ends the current active election (owner only)
 func (bc *BlockchainClient) EndElection() (*types.Transaction, error) {
-	tx, err := bc.contract.EndElection(bc.auth)
+	opts, err := bc.transactOpts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to end election: %v", err)
+	}
+	tx, err := bc.contract.EndElection(opts)
+	bc.settleNonce(opts, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to end election: %v", err)
 	}
@@ -429,7 +652,12 @@ func (bc *BlockchainClient) EndElection() (*types.Transaction, error) {
 
 // RegisterPollingUnit registers a polling unit on-chain (owner only)
 func (bc *BlockchainClient) RegisterPollingUnit(id, name, location string, totalVoters *big.Int) (*types.Transaction, error) {
-	tx, err := bc.contract.RegisterPollingUnit(bc.auth, id, name, location, totalVoters)
+	opts, err := bc.transactOpts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to register polling unit: %v", err)
+	}
+	tx, err := bc.contract.RegisterPollingUnit(opts, id, name, location, totalVoters)
+	bc.settleNonce(opts, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to register polling unit: %v", err)
 	}
@@ -469,7 +697,12 @@ func (bc *BlockchainClient) RegisterCandidate(electionID *big.Int, candidateID s
 		return nil, fmt.Errorf("abi parse error: %v", err)
 	}
 	bound := bind.NewBoundContract(bc.contractAddress, parsed, bc.client, bc.client, bc.client)
-	tx, err := bound.Transact(bc.auth, "registerCandidate", electionID, candidateID)
+	opts, err := bc.transactOpts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to register candidate: %v", err)
+	}
+	tx, err := bound.Transact(opts, "registerCandidate", electionID, candidateID)
+	bc.settleNonce(opts, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to register candidate: %v", err)
 	}
@@ -483,7 +716,12 @@ func (bc *BlockchainClient) RegisterCandidates(electionID *big.Int, candidateIDs
 		return nil, fmt.Errorf("abi parse error: %v", err)
 	}
 	bound := bind.NewBoundContract(bc.contractAddress, parsed, bc.client, bc.client, bc.client)
-	tx, err := bound.Transact(bc.auth, "registerCandidates", electionID, candidateIDs)
+	opts, err := bc.transactOpts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to register candidates: %v", err)
+	}
+	tx, err := bound.Transact(opts, "registerCandidates", electionID, candidateIDs)
+	bc.settleNonce(opts, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to register candidates: %v", err)
 	}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -118,7 +119,7 @@ func testVoterStatus(t *testing.T, client *BlockchainClient) {
 	// Test voter status for a dummy hash
 	testHash := "test_voter_hash_123"
 
-	hasVoted, err := client.HasVoterVoted(testHash)
+	hasVoted, err := client.HasVoterVoted(big.NewInt(1), testHash)
 	if err != nil {
 		// If contract is not deployed, this is expected
 		if strings.Contains(err.Error(), "no contract code") {
@@ -176,10 +177,11 @@ func testVoteCasting(t *testing.T, client *BlockchainClient) {
 		EncryptedVote:    "encrypted_vote_data_12345",
 		PollingUnitID:    "PU001",
 		CandidateID:      electionData.Candidates[0], // Vote for first candidate
+		ElectionID:       electionID.String(),
 	}
 
 	// Check if test voter has already voted
-	hasVoted, err := client.HasVoterVoted(voteData.VerificationHash)
+	hasVoted, err := client.HasVoterVoted(electionID, voteData.VerificationHash)
 	if err != nil {
 		t.Logf("Failed to check voter status: %v", err)
 		t.Skip("Cannot check voter status, skipping vote casting test")
@@ -510,7 +512,7 @@ func BenchmarkBlockchainOperations(b *testing.B) {
 		testHash := "benchmark_voter_hash"
 
 		for i := 0; i < b.N; i++ {
-			_, err := client.HasVoterVoted(testHash)
+			_, err := client.HasVoterVoted(big.NewInt(1), testHash)
 			if err != nil {
 				// Skip benchmark if contract is not deployed
 				if strings.Contains(err.Error(), "no contract code") {
@@ -584,6 +586,62 @@ func TestDataStructures(t *testing.T) {
 	})
 }
 
+// TestEncryptedVoteBinding checks the castEncryptedVote entry of the contract ABI
+func TestEncryptedVoteBinding(t *testing.T) {
+	parsed, err := SecureVotingSystemMetaData.GetAbi()
+	require.NoError(t, err)
+
+	method, ok := parsed.Methods["castEncryptedVote"]
+	require.True(t, ok, "ABI should define castEncryptedVote")
+	assert.Equal(t, "castEncryptedVote(bytes32,bytes32,uint256,string)", method.Sig)
+	assert.Equal(t, "0x44060907", hexutil.Encode(method.ID))
+
+	_, err = parsed.Pack("castEncryptedVote", [32]byte{1}, [32]byte{2}, big.NewInt(1), "PU001")
+	assert.NoError(t, err)
+
+	vote := VoteData{VerificationHash: "test_hash", PollingUnitID: "PU001", ElectionID: "1", Ballot: "{}"}
+	assert.True(t, vote.IsEncrypted())
+	vote.Ballot = ""
+	assert.False(t, vote.IsEncrypted())
+}
+
+func TestRelayedVoteBinding(t *testing.T) {
+	parsed, err := SecureVotingSystemMetaData.GetAbi()
+	require.NoError(t, err)
+
+	for name, want := range map[string][2]string{
+		"castVoteBySig":          {"castVoteBySig(bytes32,bytes32,string,string,uint256,bytes32,uint256,bytes)", "0xf76e0484"},
+		"castEncryptedVoteBySig": {"castEncryptedVoteBySig(bytes32,bytes32,uint256,string,bytes32,uint256,bytes)", "0x1da1aa59"},
+		"domainSeparator":        {"domainSeparator()", "0xf698da25"},
+		"getVoteTerminal":        {"getVoteTerminal(uint256)", "0xf696b77a"},
+	} {
+		method, ok := parsed.Methods[name]
+		require.True(t, ok, "ABI should define %s", name)
+		assert.Equal(t, want[0], method.Sig)
+		assert.Equal(t, want[1], hexutil.Encode(method.ID))
+	}
+	assert.Equal(t, "0xbdea3c07b8406af90cc4846048c690c1a6b23f9115f5e1ae71c97001760574f0",
+		parsed.Events["VoteRelayed"].ID.Hex())
+
+	vote := VoteData{
+		VerificationHash: "test_hash", PollingUnitID: "PU001", CandidateID: "C1",
+		Nonce: strings.Repeat("01", 32), SignedAt: 1760000000, Signature: "0x" + strings.Repeat("02", 64) + "00",
+	}
+	assert.True(t, vote.IsRelayed())
+	nonce, signedAt, signature, err := vote.relayArgs()
+	require.NoError(t, err)
+	assert.Equal(t, byte(1), nonce[31])
+	assert.Equal(t, int64(1760000000), signedAt.Int64())
+	assert.Equal(t, byte(27), signature[64], "recovery id is converted for the contract")
+
+	_, err = parsed.Pack("castVoteBySig", [32]byte{1}, [32]byte{2}, "PU001", "C1", big.NewInt(1), nonce, signedAt, signature)
+	assert.NoError(t, err)
+
+	vote.Nonce = "abc"
+	_, _, _, err = vote.relayArgs()
+	assert.Error(t, err)
+}
+
 // Integration test that tests the complete workflow
 func TestCompleteWorkflow(t *testing.T) {
 	if !isBlockchainAvailable() {
@@ -648,7 +706,7 @@ func TestCompleteWorkflow(t *testing.T) {
 	t.Log("=== Step 3: Testing voter verification ===")
 
 	testVoterHash := "integration_test_voter_" + time.Now().Format("20060102150405")
-	hasVoted, err := client.HasVoterVoted(testVoterHash)
+	hasVoted, err := client.HasVoterVoted(big.NewInt(1), testVoterHash)
 	if err != nil {
 		if strings.Contains(err.Error(), "no contract code") {
 			t.Log("Contract not deployed, skipping voter verification test")
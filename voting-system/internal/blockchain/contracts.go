@@ -31,7 +31,7 @@ var (
 
 // SecureVotingSystemMetaData contains all meta data concerning the SecureVotingSystem contract.
 var SecureVotingSystemMetaData = &bind.MetaData{
-	ABI: "[{\"inputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"electionId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"string\",\"name\":\"candidateId\",\"type\":\"string\"}],\"name\":\"CandidateRegistered\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"electionId\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"string\",\"name\":\"name\",\"type\":\"string\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"startTime\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"endTime\",\"type\":\"uint256\"}],\"name\":\"ElectionCreated\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"electionId\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"}],\"name\":\"ElectionEnded\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"electionId\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"}],\"name\":\"ElectionStarted\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"previousOwner\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"OwnershipTransferred\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"string\",\"name\":\"pollingUnitId\",\"type\":\"string\"},{\"indexed\":false,\"internalType\":\"string\",\"name\":\"name\",\"type\":\"string\"}],\"name\":\"PollingUnitRegistered\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"terminal\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"bool\",\"name\":\"status\",\"type\":\"bool\"}],\"name\":\"TerminalAuthorized\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"bytes32\",\"name\":\"verificationHash\",\"type\":\"bytes32\"},{\"indexed\":true,\"internalType\":\"string\",\"name\":\"pollingUnitId\",\"type\":\"string\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"electionId\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"voteId\",\"type\":\"uint256\"}],\"name\":\"VoteCast\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"voteId\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"string\",\"name\":\"reason\",\"type\":\"string\"}],\"name\":\"VoteInvalidated\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"authorizedTerminals\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[],\"name\":\"currentElectionId\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"\",\"type\":\"string\"}],\"name\":\"electionResults\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"elections\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"id\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"name\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"startTime\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"endTime\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"isActive\",\"type\":\"bool\"},{\"internalType\":\"uint256\",\"name\":\"totalVotes\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"name\":\"hasVoted\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[],\"name\":\"owner\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"string\",\"name\":\"\",\"type\":\"string\"}],\"name\":\"pollingUnits\",\"outputs\":[{\"internalType\":\"string\",\"name\":\"id\",\"type\":\"string\"},{\"internalType\":\"string\",\"name\":\"name\",\"type\":\"string\"},{\"internalType\":\"string\",\"name\":\"location\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"totalVoters\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"votesRecorded\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"isActive\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[],\"name\":\"renounceOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"transferOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"name\":\"verificationHashToVoteId\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"votes\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"verificationHash\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"encryptedVote\",\"type\":\"bytes32\"},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"pollingUnitId\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"electionId\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"candidateId\",\"type\":\"string\"},{\"internalType\":\"bool\",\"name\":\"isValid\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"string\",\"name\":\"_name\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"_startTime\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"_endTime\",\"type\":\"uint256\"},{\"internalType\":\"string[]\",\"name\":\"_candidates\",\"type\":\"string[]\"}],\"name\":\"createElection\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_electionId\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"_candidateId\",\"type\":\"string\"}],\"name\":\"registerCandidate\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_electionId\",\"type\":\"uint256\"},{\"internalType\":\"string[]\",\"name\":\"_candidateIds\",\"type\":\"string[]\"}],\"name\":\"registerCandidates\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_electionId\",\"type\":\"uint256\"}],\"name\":\"startElection\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"endElection\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"_verificationHash\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"_encryptedVote\",\"type\":\"bytes32\"},{\"internalType\":\"string\",\"name\":\"_pollingUnitId\",\"type\":\"string\"},{\"internalType\":\"string\",\"name\":\"_candidateId\",\"type\":\"string\"}],\"name\":\"castVote\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"_verificationHash\",\"type\":\"bytes32\"}],\"name\":\"hasVoterVoted\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"string\",\"name\":\"_pollingUnitId\",\"type\":\"string\"},{\"internalType\":\"string\",\"name\":\"_name\",\"type\":\"string\"},{\"internalType\":\"string\",\"name\":\"_location\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"_totalVoters\",\"type\":\"uint256\"}],\"name\":\"registerPollingUnit\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_terminal\",\"type\":\"address\"},{\"internalType\":\"bool\",\"name\":\"_status\",\"type\":\"bool\"}],\"name\":\"authorizeTerminal\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_terminal\",\"type\":\"address\"}],\"name\":\"isTerminalAuthorized\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_voteId\",\"type\":\"uint256\"}],\"name\":\"getVoteDetails\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"verificationHash\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"encryptedVote\",\"type\":\"bytes32\"},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"pollingUnitId\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"electionId\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"isValid\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_electionId\",\"type\":\"uint256\"}],\"name\":\"getElectionDetails\",\"outputs\":[{\"internalType\":\"string\",\"name\":\"name\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"startTime\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"endTime\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"isActive\",\"type\":\"bool\"},{\"internalType\":\"string[]\",\"name\":\"candidates\",\"type\":\"string[]\"},{\"internalType\":\"uint256\",\"name\":\"totalVotes\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_electionId\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"_candidateId\",\"type\":\"string\"}],\"name\":\"getElectionResults\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_electionId\",\"type\":\"uint256\"}],\"name\":\"getElectionCandidateResults\",\"outputs\":[{\"internalType\":\"string[]\",\"name\":\"candidateIds\",\"type\":\"string[]\"},{\"internalType\":\"uint256[]\",\"name\":\"voteCounts\",\"type\":\"uint256[]\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[],\"name\":\"getCurrentElectionId\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[],\"name\":\"getTotalVotes\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[],\"name\":\"getTotalElections\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"string\",\"name\":\"_pollingUnitId\",\"type\":\"string\"}],\"name\":\"getPollingUnitVoteCount\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[],\"name\":\"emergencyPause\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_voteId\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"_reason\",\"type\":\"string\"}],\"name\":\"invalidateVote\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_startTime\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"_endTime\",\"type\":\"uint256\"}],\"name\":\"getVotesByTimeRange\",\"outputs\":[{\"internalType\":\"uint256[]\",\"name\":\"\",\"type\":\"uint256[]\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_electionId\",\"type\":\"uint256\"}],\"name\":\"getElectionStatistics\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"totalVotes\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"validVotes\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"invalidVotes\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"duration\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"isCompleted\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true}]",
+	ABI: "[{\"inputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"electionId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"string\",\"name\":\"candidateId\",\"type\":\"string\"}],\"name\":\"CandidateRegistered\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[],\"name\":\"EIP712DomainChanged\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"electionId\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"string\",\"name\":\"name\",\"type\":\"string\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"startTime\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"endTime\",\"type\":\"uint256\"}],\"name\":\"ElectionCreated\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"electionId\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"}],\"name\":\"ElectionEnded\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"electionId\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"}],\"name\":\"ElectionStarted\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"previousOwner\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"OwnershipTransferred\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"string\",\"name\":\"pollingUnitId\",\"type\":\"string\"},{\"indexed\":false,\"internalType\":\"string\",\"name\":\"name\",\"type\":\"string\"}],\"name\":\"PollingUnitRegistered\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"terminal\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"bool\",\"name\":\"status\",\"type\":\"bool\"}],\"name\":\"TerminalAuthorized\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"bytes32\",\"name\":\"verificationHash\",\"type\":\"bytes32\"},{\"indexed\":true,\"internalType\":\"string\",\"name\":\"pollingUnitId\",\"type\":\"string\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"electionId\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"voteId\",\"type\":\"uint256\"}],\"name\":\"VoteCast\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"voteId\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"string\",\"name\":\"reason\",\"type\":\"string\"}],\"name\":\"VoteInvalidated\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"voteId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"terminal\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"relayer\",\"type\":\"address\"}],\"name\":\"VoteRelayed\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"electionId\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"root\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"voterCount\",\"type\":\"uint256\"}],\"name\":\"VoterRollPublished\",\"type\":\"event\"},{\"inputs\":[],\"name\":\"VOTE_TYPEHASH\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"authorizedTerminals\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[],\"name\":\"currentElectionId\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[],\"name\":\"eip712Domain\",\"outputs\":[{\"internalType\":\"bytes1\",\"name\":\"fields\",\"type\":\"bytes1\"},{\"internalType\":\"string\",\"name\":\"name\",\"type\":\"string\"},{\"internalType\":\"string\",\"name\":\"version\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"chainId\",\"type\":\"uint256\"},{\"internalType\":\"address\",\"name\":\"verifyingContract\",\"type\":\"address\"},{\"internalType\":\"bytes32\",\"name\":\"salt\",\"type\":\"bytes32\"},{\"internalType\":\"uint256[]\",\"name\":\"extensions\",\"type\":\"uint256[]\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"\",\"type\":\"string\"}],\"name\":\"electionResults\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"elections\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"id\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"name\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"startTime\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"endTime\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"isActive\",\"type\":\"bool\"},{\"internalType\":\"uint256\",\"name\":\"totalVotes\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"name\":\"hasVoted\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[],\"name\":\"owner\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"string\",\"name\":\"\",\"type\":\"string\"}],\"name\":\"pollingUnits\",\"outputs\":[{\"internalType\":\"string\",\"name\":\"id\",\"type\":\"string\"},{\"internalType\":\"string\",\"name\":\"name\",\"type\":\"string\"},{\"internalType\":\"string\",\"name\":\"location\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"totalVoters\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"votesRecorded\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"isActive\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[],\"name\":\"renounceOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"transferOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"name\":\"usedVoteNonces\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"name\":\"verificationHashToVoteId\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"voteTerminals\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"voterRollRoots\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"voterRollSizes\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"votes\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"verificationHash\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"encryptedVote\",\"type\":\"bytes32\"},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"pollingUnitId\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"electionId\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"candidateId\",\"type\":\"string\"},{\"internalType\":\"bool\",\"name\":\"isValid\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"string\",\"name\":\"_name\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"_startTime\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"_endTime\",\"type\":\"uint256\"},{\"internalType\":\"string[]\",\"name\":\"_candidates\",\"type\":\"string[]\"}],\"name\":\"createElection\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_electionId\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"_candidateId\",\"type\":\"string\"}],\"name\":\"registerCandidate\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_electionId\",\"type\":\"uint256\"},{\"internalType\":\"string[]\",\"name\":\"_candidateIds\",\"type\":\"string[]\"}],\"name\":\"registerCandidates\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_electionId\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"_root\",\"type\":\"bytes32\"},{\"internalType\":\"uint256\",\"name\":\"_voterCount\",\"type\":\"uint256\"}],\"name\":\"publishVoterRoll\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_electionId\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"_verificationHash\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32[]\",\"name\":\"_proof\",\"type\":\"bytes32[]\"}],\"name\":\"isOnVoterRoll\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_electionId\",\"type\":\"uint256\"}],\"name\":\"startElection\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"endElection\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"_verificationHash\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"_ballotHash\",\"type\":\"bytes32\"},{\"internalType\":\"uint256\",\"name\":\"_electionId\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"_pollingUnitId\",\"type\":\"string\"}],\"name\":\"castEncryptedVote\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"_verificationHash\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"_ballotHash\",\"type\":\"bytes32\"},{\"internalType\":\"uint256\",\"name\":\"_electionId\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"_pollingUnitId\",\"type\":\"string\"},{\"internalType\":\"bytes32\",\"name\":\"_nonce\",\"type\":\"bytes32\"},{\"internalType\":\"uint256\",\"name\":\"_timestamp\",\"type\":\"uint256\"},{\"internalType\":\"bytes\",\"name\":\"_signature\",\"type\":\"bytes\"}],\"name\":\"castEncryptedVoteBySig\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"_verificationHash\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"_encryptedVote\",\"type\":\"bytes32\"},{\"internalType\":\"string\",\"name\":\"_pollingUnitId\",\"type\":\"string\"},{\"internalType\":\"string\",\"name\":\"_candidateId\",\"type\":\"string\"}],\"name\":\"castVote\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"_verificationHash\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"_encryptedVote\",\"type\":\"bytes32\"},{\"internalType\":\"string\",\"name\":\"_pollingUnitId\",\"type\":\"string\"},{\"internalType\":\"string\",\"name\":\"_candidateId\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"_electionId\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"_nonce\",\"type\":\"bytes32\"},{\"internalType\":\"uint256\",\"name\":\"_timestamp\",\"type\":\"uint256\"},{\"internalType\":\"bytes\",\"name\":\"_signature\",\"type\":\"bytes\"}],\"name\":\"castVoteBySig\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_electionId\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"_verificationHash\",\"type\":\"bytes32\"}],\"name\":\"hasVoterVoted\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"string\",\"name\":\"_pollingUnitId\",\"type\":\"string\"},{\"internalType\":\"string\",\"name\":\"_name\",\"type\":\"string\"},{\"internalType\":\"string\",\"name\":\"_location\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"_totalVoters\",\"type\":\"uint256\"}],\"name\":\"registerPollingUnit\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_terminal\",\"type\":\"address\"},{\"internalType\":\"bool\",\"name\":\"_status\",\"type\":\"bool\"}],\"name\":\"authorizeTerminal\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"domainSeparator\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_terminal\",\"type\":\"address\"}],\"name\":\"isTerminalAuthorized\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_voteId\",\"type\":\"uint256\"}],\"name\":\"getVoteDetails\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"verificationHash\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"encryptedVote\",\"type\":\"bytes32\"},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"pollingUnitId\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"electionId\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"isValid\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_voteId\",\"type\":\"uint256\"}],\"name\":\"getVoteTerminal\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_electionId\",\"type\":\"uint256\"}],\"name\":\"getElectionDetails\",\"outputs\":[{\"internalType\":\"string\",\"name\":\"name\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"startTime\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"endTime\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"isActive\",\"type\":\"bool\"},{\"internalType\":\"string[]\",\"name\":\"candidates\",\"type\":\"string[]\"},{\"internalType\":\"uint256\",\"name\":\"totalVotes\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_electionId\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"_candidateId\",\"type\":\"string\"}],\"name\":\"getElectionResults\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_electionId\",\"type\":\"uint256\"}],\"name\":\"getElectionCandidateResults\",\"outputs\":[{\"internalType\":\"string[]\",\"name\":\"candidateIds\",\"type\":\"string[]\"},{\"internalType\":\"uint256[]\",\"name\":\"voteCounts\",\"type\":\"uint256[]\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[],\"name\":\"getCurrentElectionId\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[],\"name\":\"getTotalVotes\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[],\"name\":\"getTotalElections\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"string\",\"name\":\"_pollingUnitId\",\"type\":\"string\"}],\"name\":\"getPollingUnitVoteCount\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[],\"name\":\"emergencyPause\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_voteId\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"_reason\",\"type\":\"string\"}],\"name\":\"invalidateVote\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_startTime\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"_endTime\",\"type\":\"uint256\"}],\"name\":\"getVotesByTimeRange\",\"outputs\":[{\"internalType\":\"uint256[]\",\"name\":\"\",\"type\":\"uint256[]\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_electionId\",\"type\":\"uint256\"}],\"name\":\"getElectionStatistics\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"totalVotes\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"validVotes\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"invalidVotes\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"duration\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"isCompleted\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\",\"constant\":true}]",
 	Bin: "0x60806040523480156200001157600080fd5b506200001d3362000077565b600180805533600081815260086020908152604091829020805460ff191685179055905192835290917f1a857e9c86aef24412514088ba2a182be80f1f8578455e99e91a32f26f079ac0910160405180910390a2620000c7565b600080546001600160a01b038381166001600160a01b0319831681178455604051919092169283917f8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e09190a35050565b6135bc80620000d76000396000f3fe608060405234801561001057600080fd5b50600436106101fb5760003560e01c806373ed31a31161011a578063d1009367116100ad578063f2fde38b1161007c578063f2fde38b1461050d578063f42afb9014610520578063f604992414610533578063f67c7d0614610558578063fe2b536b1461056b57600080fd5b8063d100936714610497578063d293eb3b146104aa578063e744cf91146104bd578063e8b20ad7146104d057600080fd5b80639a0e7d66116100e95780639a0e7d6614610451578063a9392e0c14610459578063bc27904714610461578063c91d60ed1461047457600080fd5b806373ed31a3146103d15780638da5cb5b1461040d5780638dc419111461042857806398ecf2a01461044857600080fd5b806354a1b431116101925780636d32dc4b116101615780636d32dc4b14610375578063710f750c14610388578063715018a6146103a957806373b93c34146103b157600080fd5b806354a1b431146102fd57806359f78468146103225780635df813301461032a5780635e6fef011461035057600080fd5b8063374904b2116101ce578063374904b21461029a5780634596aee8146102bf5780634ba7945f146102e257806351858e27146102f557600080fd5b806310fc46b314610200578063184acbab146102155780631b4613cb146102565780631cfc71e614610279575b600080fd5b61021361020e366004612b54565b610573565b005b610241610223366004612bb6565b6001600160a01b031660009081526008602052604090205460ff1690565b60405190151581526020015b60405180910390f35b610241610264366004612bd8565b60046020526000908152604090205460ff1681565b61028c610287366004612bd8565b61099b565b60405161024d929190612cd4565b6102ad6102a8366004612d02565b610bb7565b60405161024d96959493929190612d3e565b6102416102cd366004612bb6565b60086020526000908152604090205460ff1681565b6102136102f0366004612e36565b610d93565b610213611005565b61031061030b366004612bd8565b611034565b60405161024d96959493929190612e72565b610213611249565b61033d610338366004612bd8565b611327565b60405161024d9796959493929190612eb1565b61036361035e366004612bd8565b611477565b60405161024d96959493929190612f05565b610213610383366004612bd8565b61153b565b61039b610396366004612f43565b611775565b60405190815260200161024d565b610213611cb7565b6103c46103bf366004612fb9565b611cc9565b60405161024d9190612fdb565b61039b6103df366004612b54565b600a602090815260009283526040909220815180830184018051928152908401929093019190912091525481565b6000546040516001600160a01b03909116815260200161024d565b61039b610436366004612bd8565b60066020526000908152604090205481565b61039b600b5481565b61039b611e97565b61039b611ea7565b61039b61046f366004612fee565b611eb2565b610241610482366004612bd8565b60009081526004602052604090205460ff1690565b6102136104a5366004612b54565b61209a565b61039b6104b8366004612d02565b612270565b6102136104cb366004613058565b61229b565b6104e36104de366004612bd8565b612360565b6040805195865260208601949094529284019190915260608301521515608082015260a00161024d565b61021361051b366004612bb6565b612466565b61021361052e366004613094565b6124df565b610546610541366004612bd8565b6126c7565b60405161024d96959493929190613123565b61039b610566366004612b54565b6128ac565b600b5461039b565b61057b6128df565b60008211801561058d57506002548211155b6105de5760405162461bcd60e51b815260206004820152601d60248201527f566f74696e6753797374656d3a20496e76616c696420766f746520494400000060448201526064015b60405180910390fd5b60008281526005602052604090206006015460ff1661064a5760405162461bcd60e51b815260206004820152602260248201527f566f74696e6753797374656d3a20566f746520616c726561647920696e76616c6044820152611a5960f21b60648201526084016105d5565b600082815260056020908152604080832060068101805460ff19169055815160e081018352815481526001820154938101939093526002810154918301919091526003810180546060840191906106a090613170565b80601f01602080910402602001604051908101604052809291908181526020018280546106cc90613170565b80156107195780601f106106ee57610100808354040283529160200191610719565b820191906000526020600020905b8154815290600101906020018083116106fc57829003601f168201915b505050505081526020016004820154815260200160058201805461073c90613170565b80601f016020809104026020016040519081016040528092919081815260200182805461076890613170565b80156107b55780601f1061078a576101008083540402835291602001916107b5565b820191906000526020600020905b81548152906001019060200180831161079857829003601f168201915b50505091835250506006919091015460ff161515602091820152608082015160009081526007918290526040902090810154919250901561080857600781018054906000610802836131c0565b91905055505b60006009836060015160405161081e91906131d7565b9081526020016040518091039020600401541115610870576009826060015160405161084a91906131d7565b908152604051908190036020019020600401805490600061086a836131c0565b91905055505b6000816006018360a0015160405161088891906131d7565b90815260200160405180910390205411156108d657806006018260a001516040516108b391906131d7565b90815260405190819003602001902080549060006108d0836131c0565b91905055505b60808201516000908152600a602052604080822060a0850151915190916108fc916131d7565b908152602001604051809103902054111561095d57600a6000836080015181526020019081526020016000208260a0015160405161093a91906131d7565b9081526040519081900360200190208054906000610957836131c0565b91905055505b837f135777869117aa60ca380541543f5506294b4330cef23c24067a9bd0bb1f0ff48460405161098d91906131f3565b60405180910390a250505050565b6060806000831180156109b057506003548311155b6109cc5760405162461bcd60e51b81526004016105d590613206565b600083815260076020526040812060058101549091816001600160401b038111156109f9576109f9612a9f565b604051908082528060200260200182016040528015610a2c57816020015b6060815260200190600190039081610a175790505b5090506000826001600160401b03811115610a4957610a49612a9f565b604051908082528060200260200182016040528015610a72578160200160208202803683370190505b50905060005b83811015610baa576000856005018281548110610a9757610a97613247565b906000526020600020018054610aac90613170565b80601f0160208091040260200160405190810160405280929190818152602001828054610ad890613170565b8015610b255780601f10610afa57610100808354040283529160200191610b25565b820191906000526020600020905b815481529060010190602001808311610b0857829003601f168201915b5050505050905080848381518110610b3f57610b3f613247565b6020026020010181905250600a60008a815260200190815260200160002081604051610b6b91906131d7565b908152602001604051809103902054838381518110610b8c57610b8c613247565b60209081029190910101525080610ba28161325d565b915050610a78565b5090969095509350505050565b8051602081830181018051600982529282019190930120915280548190610bdd90613170565b80601f0160208091040260200160405190810160405280929190818152602001828054610c0990613170565b8015610c565780601f10610c2b57610100808354040283529160200191610c56565b820191906000526020600020905b815481529060010190602001808311610c3957829003601f168201915b505050505090806001018054610c6b90613170565b80601f0160208091040260200160405190810160405280929190818152602001828054610c9790613170565b8015610ce45780601f10610cb957610100808354040283529160200191610ce4565b820191906000526020600020905b815481529060010190602001808311610cc757829003601f168201915b505050505090806002018054610cf990613170565b80601f0160208091040260200160405190810160405280929190818152602001828054610d2590613170565b8015610d725780601f10610d4757610100808354040283529160200191610d72565b820191906000526020600020905b815481529060010190602001808311610d5557829003601f168201915b50505050600383015460048401546005909401549293909290915060ff1686565b610d9b6128df565b6000828152600760205260409020600481015483919060ff1615610dd15760405162461bcd60e51b81526004016105d590613276565b80600201544210610df45760405162461bcd60e51b81526004016105d5906132bb565b600084118015610e0657506003548411155b610e225760405162461bcd60e51b81526004016105d590613206565b6000835111610e7f5760405162461bcd60e51b8152602060048201526024808201527f566f74696e6753797374656d3a204e6f2063616e646964617465732070726f766044820152631a59195960e21b60648201526084016105d5565b6000848152600760205260408120905b8451811015610ffd576000858281518110610eac57610eac613247565b602002602001015190506000815111610ed75760405162461bcd60e51b81526004016105d590613301565b6000805b6005850154811015610f43578280519060200120856005018281548110610f0457610f04613247565b90600052602060002001604051610f1b9190613343565b604051809103902003610f315760019150610f43565b80610f3b8161325d565b915050610edb565b508015610f625760405162461bcd60e51b81526004016105d5906133b9565b6005840180546001810182556000918252602090912001610f83838261344e565b5060008460060183604051610f9891906131d7565b90815260405190819003602001812091909155610fb69083906131d7565b6040519081900381209089907f96b6e1d9af8279de0ae4d01600bcae708bdb292c4a8f8f150aff92f5caf56a4290600090a350508080610ff59061325d565b915050610e8f565b505050505050565b61100d6128df565b600b541561103257600b546000908152600760205260409020600401805460ff191690555b565b6000806000606060008060008711801561105057506002548711155b61109c5760405162461bcd60e51b815260206004820152601d60248201527f566f74696e6753797374656d3a20496e76616c696420766f746520494400000060448201526064016105d5565b6000600560008981526020019081526020016000206040518060e00160405290816000820154815260200160018201548152602001600282015481526020016003820180546110ea90613170565b80601f016020809104026020016040519081016040528092919081815260200182805461111690613170565b80156111635780601f1061113857610100808354040283529160200191611163565b820191906000526020600020905b81548152906001019060200180831161114657829003601f168201915b505050505081526020016004820154815260200160058201805461118690613170565b80601f01602080910402602001604051908101604052809291908181526020018280546111b290613170565b80156111ff5780601f106111d4576101008083540402835291602001916111ff565b820191906000526020600020905b8154815290600101906020018083116111e257829003601f168201915b50505091835250506006919091015460ff16151560209182015281519082015160408301516060840151608085015160c090950151939d929c50909a509850919650945092505050565b6112516128df565b6000600b54116112a35760405162461bcd60e51b815260206004820181905260248201527f566f74696e6753797374656d3a204e6f2061637469766520656c656374696f6e60448201526064016105d5565b600b546000908152600760205260409020600481015460ff166112d85760405162461bcd60e51b81526004016105d59061350d565b60048101805460ff19169055600b8054600090915560405142815281907f32e2c12037f9600b91a766ce53eab6909f3bdb865851cd9a8c7fafbdf249a9ce906020015b60405180910390a25050565b60056020526000908152604090208054600182015460028301546003840180549394929391929161135790613170565b80601f016020809104026020016040519081016040528092919081815260200182805461138390613170565b80156113d05780601f106113a5576101008083540402835291602001916113d0565b820191906000526020600020905b8154815290600101906020018083116113b357829003601f168201915b5050505050908060040154908060050180546113eb90613170565b80601f016020809104026020016040519081016040528092919081815260200182805461141790613170565b80156114645780601f1061143957610100808354040283529160200191611464565b820191906000526020600020905b81548152906001019060200180831161144757829003601f168201915b5050506006909301549192505060ff1687565b6007602052600090815260409020805460018201805491929161149990613170565b80601f01602080910402602001604051908101604052809291908181526020018280546114c590613170565b80156115125780601f106114e757610100808354040283529160200191611512565b820191906000526020600020905b8154815290600101906020018083116114f557829003601f168201915b5050506002840154600385015460048601546007909601549495919490935060ff909116915086565b6115436128df565b60008111801561155557506003548111155b6115715760405162461bcd60e51b81526004016105d590613206565b600b54156115d25760405162461bcd60e51b815260206004820152602860248201527f566f74696e6753797374656d3a20416e6f7468657220656c656374696f6e2069604482015267732061637469766560c01b60648201526084016105d5565b6000818152600760205260409020600481015460ff16156116055760405162461bcd60e51b81526004016105d5906132bb565b806002015442101561166f5760405162461bcd60e51b815260206004820152602d60248201527f566f74696e6753797374656d3a20456c656374696f6e2073746172742074696d60448201526c19481b9bdd081c995858da1959609a1b60648201526084016105d5565b806003015442106116cd5760405162461bcd60e51b815260206004820152602260248201527f566f74696e6753797374656d3a20456c656374696f6e20686173206578706972604482015261195960f21b60648201526084016105d5565b600581015461172d5760405162461bcd60e51b815260206004820152602660248201527f566f74696e6753797374656d3a204e6f2063616e6469646174657320636f6e666044820152651a59dd5c995960d21b60648201526084016105d5565b60048101805460ff19166001179055600b82905560405182907fff6a30dd22f5e8b783044c7d895a6e8592b55c56f139978d44dc39daf596731f9061131b9042815260200190565b3360009081526008602052604081205460ff166117e05760405162461bcd60e51b815260206004820152602360248201527f566f74696e6753797374656d3a20556e617574686f72697a6564207465726d696044820152621b985b60ea1b60648201526084016105d5565b6000600b54116118325760405162461bcd60e51b815260206004820181905260248201527f566f74696e6753797374656d3a204e6f2061637469766520656c656374696f6e60448201526064016105d5565b600b546000908152600760205260409020600481015460ff166118675760405162461bcd60e51b81526004016105d59061350d565b8060020154421015801561187f575080600301544211155b6118d95760405162461bcd60e51b815260206004820152602560248201527f566f74696e6753797374656d3a20456c656374696f6e206e6f7420696e20736560448201526439b9b4b7b760d91b60648201526084016105d5565b836009816040516118ea91906131d7565b9081526040519081900360200190206005015460ff166119575760405162461bcd60e51b815260206004820152602260248201527f566f74696e6753797374656d3a20496e76616c696420706f6c6c696e6720756e6044820152611a5d60f21b60648201526084016105d5565b61195f612939565b60008781526004602052604090205460ff16156119d25760405162461bcd60e51b815260206004820152602b60248201527f566f74696e6753797374656d3a20566f7465722068617320616c72656164792060448201526a63617374206120766f746560a81b60648201526084016105d5565b600b54600090815260076020526040812090805b6005830154811015611a4e578680519060200120836005018281548110611a0f57611a0f613247565b90600052602060002001604051611a269190613343565b604051809103902003611a3c5760019150611a4e565b80611a468161325d565b9150506119e6565b5080611a9c5760405162461bcd60e51b815260206004820152601f60248201527f566f74696e6753797374656d3a20496e76616c69642063616e6469646174650060448201526064016105d5565b6000898152600460205260409020805460ff19166001179055611ac3600280546001019055565b6000611ace60025490565b6040805160e0810182528c815260208082018d815242838501908152606084018e8152600b54608086015260a085018e9052600160c086018190526000888152600590955295909320845181559151948201949094559251600284015551929350916003820190611b3f908261344e565b506080820151600482015560a08201516005820190611b5e908261344e565b5060c091909101516006918201805460ff191691151591909117905560008b815260208290526040908190208390555190840190611b9d9089906131d7565b9081526040519081900360200190208054906000611bba8361325d565b9091555050600783018054906000611bd18361325d565b9091555050600b546000908152600a6020526040908190209051611bf69089906131d7565b9081526040519081900360200190208054906000611c138361325d565b9190505550600988604051611c2891906131d7565b9081526040519081900360200190206004018054906000611c488361325d565b9190505550600b5488604051611c5e91906131d7565b6040805191829003822042835260208301859052918d917fdf9dbd71c12ac0ec889f1cad7d0e15a26cc5765f926d01d606c0eb683a161d7d910160405180910390a494505050611cad60018055565b5050949350505050565b611cbf6128df565b6110326000612992565b606082821015611d1b5760405162461bcd60e51b815260206004820181905260248201527f566f74696e6753797374656d3a20496e76616c69642074696d652072616e676560448201526064016105d5565b6000611d2660025490565b90506000816001600160401b03811115611d4257611d42612a9f565b604051908082528060200260200182016040528015611d6b578160200160208202803683370190505b509050600060015b838111611def576000818152600560205260409020600201548711801590611dac57506000818152600560205260409020600201548610155b15611ddd5780838381518110611dc457611dc4613247565b602090810291909101015281611dd98161325d565b9250505b80611de78161325d565b915050611d73565b506000816001600160401b03811115611e0a57611e0a612a9f565b604051908082528060200260200182016040528015611e33578160200160208202803683370190505b50905060005b82811015611e8a57838181518110611e5357611e53613247565b6020026020010151828281518110611e6d57611e6d613247565b602090810291909101015280611e828161325d565b915050611e39565b5093505050505b92915050565b6000611ea260025490565b905090565b6000611ea260035490565b6000611ebc6128df565b428411611f1e5760405162461bcd60e51b815260206004820152602a60248201527f566f74696e6753797374656d3a2053746172742074696d65206d75737420626560448201526920696e2066757475726560b01b60648201526084016105d5565b838311611f855760405162461bcd60e51b815260206004820152602f60248201527f566f74696e6753797374656d3a20456e642074696d65206d757374206265206160448201526e667465722073746172742074696d6560881b60648201526084016105d5565b611f93600380546001019055565b6000611f9e60035490565b600081815260076020526040902081815590915060018101611fc0888261344e565b50600281018690556003810185905560048101805460ff191690558351611ff090600583019060208701906129e2565b506000600782018190555b84518110156120535760008260060186838151811061201c5761201c613247565b602002602001015160405161203191906131d7565b908152604051908190036020019020558061204b8161325d565b915050611ffb565b50817fe7a0aae5d733e07e246dea86213a1ac1b0aa8554bde889bb75c12752f44e53d98888886040516120889392919061354e565b60405180910390a25095945050505050565b6120a26128df565b6000828152600760205260409020600481015483919060ff16156120d85760405162461bcd60e51b81526004016105d590613276565b806002015442106120fb5760405162461bcd60e51b81526004016105d5906132bb565b60008411801561210d57506003548411155b6121295760405162461bcd60e51b81526004016105d590613206565b600083511161214a5760405162461bcd60e51b81526004016105d590613301565b600084815260076020526040812090805b60058301548110156121c357858051906020012083600501828154811061218457612184613247565b9060005260206000200160405161219b9190613343565b6040518091039020036121b157600191506121c3565b806121bb8161325d565b91505061215b565b5080156121e25760405162461bcd60e51b81526004016105d5906133b9565b6005820180546001810182556000918252602090912001612203868261344e565b506000826006018660405161221891906131d7565b908152604051908190036020018120919091556122369086906131d7565b6040519081900381209087907f96b6e1d9af8279de0ae4d01600bcae708bdb292c4a8f8f150aff92f5caf56a4290600090a3505050505050565b600060098260405161228291906131d7565b9081526020016040518091039020600401549050919050565b6122a36128df565b6001600160a01b0382166123085760405162461bcd60e51b815260206004820152602660248201527f566f74696e6753797374656d3a20496e76616c6964207465726d696e616c206160448201526564647265737360d01b60648201526084016105d5565b6001600160a01b038216600081815260086020908152604091829020805460ff191685151590811790915591519182527f1a857e9c86aef24412514088ba2a182be80f1f8578455e99e91a32f26f079ac0910161131b565b6000806000806000808611801561237957506003548611155b6123955760405162461bcd60e51b81526004016105d590613206565b600086815260076020819052604082209081015490918060015b600254811161241d576000818152600560205260409020600401548b900361240b5760008181526005602052604090206006015460ff16156123fd57826123f58161325d565b93505061240b565b816124078161325d565b9250505b806124158161325d565b9150506123af565b506000846002015485600301546124349190613573565b600486015490915060009060ff161580156124525750856003015442115b949c939b5091995097509195509350505050565b61246e6128df565b6001600160a01b0381166124d35760405162461bcd60e51b815260206004820152602660248201527f4f776e61626c653a206e6577206f776e657220697320746865207a65726f206160448201526564647265737360d01b60648201526084016105d5565b6124dc81612992565b50565b6124e76128df565b60008451116125465760405162461bcd60e51b815260206004820152602560248201527f566f74696e6753797374656d3a20496e76616c696420706f6c6c696e6720756e6044820152641a5d08125160da1b60648201526084016105d5565b60098460405161255691906131d7565b9081526040519081900360200190206005015460ff16156125cb5760405162461bcd60e51b815260206004820152602960248201527f566f74696e6753797374656d3a20506f6c6c696e6720756e697420616c72656160448201526864792065786973747360b81b60648201526084016105d5565b6040518060c00160405280858152602001848152602001838152602001828152602001600081526020016001151581525060098560405161260c91906131d7565b90815260405190819003602001902081518190612629908261344e565b506020820151600182019061263e908261344e565b5060408201516002820190612653908261344e565b50606082015160038201556080820151600482015560a0909101516005909101805460ff19169115159190911790556040516126909085906131d7565b60405180910390207fb4fbf858aaf58f916976b6c4668154c1e069b7abc44972f310db304359cf28ce8460405161098d91906131f3565b606060008060006060600080871180156126e357506003548711155b6126ff5760405162461bcd60e51b81526004016105d590613206565b6000878152600760208190526040909120600281015460038201546004830154938301546001840180549495909460ff909116916005870191869061274390613170565b80601f016020809104026020016040519081016040528092919081815260200182805461276f90613170565b80156127bc5780601f10612791576101008083540402835291602001916127bc565b820191906000526020600020905b81548152906001019060200180831161279f57829003601f168201915b5050505050955081805480602002602001604051908101604052809291908181526020016000905b8282101561289057838290600052602060002001805461280390613170565b80601f016020809104026020016040519081016040528092919081815260200182805461282f90613170565b801561287c5780601f106128515761010080835404028352916020019161287c565b820191906000526020600020905b81548152906001019060200180831161285f57829003601f168201915b5050505050815260200190600101906127e4565b5050505091509650965096509650965096505091939550919395565b6000828152600a602052604080822090516128c89084906131d7565b908152602001604051809103902054905092915050565b6000546001600160a01b031633146110325760405162461bcd60e51b815260206004820181905260248201527f4f776e61626c653a2063616c6c6572206973206e6f7420746865206f776e657260448201526064016105d5565b60026001540361298b5760405162461bcd60e51b815260206004820152601f60248201527f5265656e7472616e637947756172643a207265656e7472616e742063616c6c0060448201526064016105d5565b6002600155565b600080546001600160a01b038381166001600160a01b0319831681178455604051919092169283917f8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e09190a35050565b828054828255906000526020600020908101928215612a28579160200282015b82811115612a285782518290612a18908261344e565b5091602001919060010190612a02565b50612a34929150612a38565b5090565b80821115612a34576000612a4c8282612a55565b50600101612a38565b508054612a6190613170565b6000825580601f10612a71575050565b601f0160209004906000526020600020908101906124dc91905b80821115612a345760008155600101612a8b565b634e487b7160e01b600052604160045260246000fd5b604051601f8201601f191681016001600160401b0381118282101715612add57612add612a9f565b604052919050565b600082601f830112612af657600080fd5b81356001600160401b03811115612b0f57612b0f612a9f565b612b22601f8201601f1916602001612ab5565b818152846020838601011115612b3757600080fd5b816020850160208301376000918101602001919091529392505050565b60008060408385031215612b6757600080fd5b8235915060208301356001600160401b03811115612b8457600080fd5b612b9085828601612ae5565b9150509250929050565b80356001600160a01b0381168114612bb157600080fd5b919050565b600060208284031215612bc857600080fd5b612bd182612b9a565b9392505050565b600060208284031215612bea57600080fd5b5035919050565b60005b83811015612c0c578181015183820152602001612bf4565b50506000910152565b60008151808452612c2d816020860160208601612bf1565b601f01601f19169290920160200192915050565b600082825180855260208086019550808260051b84010181860160005b84811015612c8c57601f19868403018952612c7a838351612c15565b98840198925090830190600101612c5e565b5090979650505050505050565b600081518084526020808501945080840160005b83811015612cc957815187529582019590820190600101612cad565b509495945050505050565b604081526000612ce76040830185612c41565b8281036020840152612cf98185612c99565b95945050505050565b600060208284031215612d1457600080fd5b81356001600160401b03811115612d2a57600080fd5b612d3684828501612ae5565b949350505050565b60c081526000612d5160c0830189612c15565b8281036020840152612d638189612c15565b90508281036040840152612d778188612c15565b606084019690965250506080810192909252151560a0909101529392505050565b600082601f830112612da957600080fd5b813560206001600160401b0380831115612dc557612dc5612a9f565b8260051b612dd4838201612ab5565b9384528581018301938381019088861115612dee57600080fd5b84880192505b85831015612e2a57823584811115612e0c5760008081fd5b612e1a8a87838c0101612ae5565b8352509184019190840190612df4565b98975050505050505050565b60008060408385031215612e4957600080fd5b8235915060208301356001600160401b03811115612e6657600080fd5b612b9085828601612d98565b86815285602082015284604082015260c060608201526000612e9760c0830186612c15565b60808301949094525090151560a090910152949350505050565b87815286602082015285604082015260e060608201526000612ed660e0830187612c15565b85608084015282810360a0840152612eee8186612c15565b91505082151560c083015298975050505050505050565b86815260c060208201526000612f1e60c0830188612c15565b6040830196909652506060810193909352901515608083015260a09091015292915050565b60008060008060808587031215612f5957600080fd5b843593506020850135925060408501356001600160401b0380821115612f7e57600080fd5b612f8a88838901612ae5565b93506060870135915080821115612fa057600080fd5b50612fad87828801612ae5565b91505092959194509250565b60008060408385031215612fcc57600080fd5b50508035926020909101359150565b602081526000612bd16020830184612c99565b6000806000806080858703121561300457600080fd5b84356001600160401b038082111561301b57600080fd5b61302788838901612ae5565b95506020870135945060408701359350606087013591508082111561304b57600080fd5b50612fad87828801612d98565b6000806040838503121561306b57600080fd5b61307483612b9a565b91506020830135801515811461308957600080fd5b809150509250929050565b600080600080608085870312156130aa57600080fd5b84356001600160401b03808211156130c157600080fd5b6130cd88838901612ae5565b955060208701359150808211156130e357600080fd5b6130ef88838901612ae5565b9450604087013591508082111561310557600080fd5b5061311287828801612ae5565b949793965093946060013593505050565b60c08152600061313660c0830189612c15565b8760208401528660408401528515156060840152828103608084015261315c8186612c41565b9150508260a0830152979650505050505050565b600181811c9082168061318457607f821691505b6020821081036131a457634e487b7160e01b600052602260045260246000fd5b50919050565b634e487b7160e01b600052601160045260246000fd5b6000816131cf576131cf6131aa565b506000190190565b600082516131e9818460208701612bf1565b9190910192915050565b602081526000612bd16020830184612c15565b60208082526021908201527f566f74696e6753797374656d3a20496e76616c696420656c656374696f6e20496040820152601160fa1b606082015260800190565b634e487b7160e01b600052603260045260246000fd5b60006001820161326f5761326f6131aa565b5060010190565b60208082526025908201527f566f74696e6753797374656d3a20456c656374696f6e20616c72656164792061604082015264637469766560d81b606082015260800190565b60208082526026908201527f566f74696e6753797374656d3a20456c656374696f6e20616c726561647920736040820152651d185c9d195960d21b606082015260800190565b60208082526022908201527f566f74696e6753797374656d3a20496e76616c69642063616e64696461746520604082015261125160f21b606082015260800190565b600080835461335181613170565b60018281168015613369576001811461337e576133ad565b60ff19841687528215158302870194506133ad565b8760005260208060002060005b858110156133a45781548a82015290840190820161338b565b50505082870194505b50929695505050505050565b6020808252602a908201527f566f74696e6753797374656d3a2043616e64696461746520616c7265616479206040820152691c9959da5cdd195c995960b21b606082015260800190565b601f82111561344957600081815260208120601f850160051c8101602086101561342a5750805b601f850160051c820191505b81811015610ffd57828155600101613436565b505050565b81516001600160401b0381111561346757613467612a9f565b61347b816134758454613170565b84613403565b602080601f8311600181146134b057600084156134985750858301515b600019600386901b1c1916600185901b178555610ffd565b600085815260208120601f198616915b828110156134df578886015182559484019460019091019084016134c0565b50858210156134fd5787850151600019600388901b60f8161c191681555b5050505050600190811b01905550565b60208082526021908201527f566f74696e6753797374656d3a20456c656374696f6e206e6f742061637469766040820152606560f81b606082015260800190565b6060815260006135616060830186612c15565b60208301949094525060400152919050565b81810381811115611e9157611e916131aa56fea2646970667358221220e84335711b90fb1d32f6f37c81f4f3854cf13ef30a717e1c9ed652fa0b7cada264736f6c63430008130033",
 }
 
@@ -202,6 +202,37 @@ func (_SecureVotingSystem *SecureVotingSystemTransactorRaw) Transact(opts *bind.
 	return _SecureVotingSystem.Contract.contract.Transact(opts, method, params...)
 }
 
+// VOTETYPEHASH is a free data retrieval call binding the contract method 0x86522973.
+//
+// Solidity: function VOTE_TYPEHASH() view returns(bytes32)
+func (_SecureVotingSystem *SecureVotingSystemCaller) VOTETYPEHASH(opts *bind.CallOpts) ([32]byte, error) {
+	var out []interface{}
+	err := _SecureVotingSystem.contract.Call(opts, &out, "VOTE_TYPEHASH")
+
+	if err != nil {
+		return *new([32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+
+	return out0, err
+
+}
+
+// VOTETYPEHASH is a free data retrieval call binding the contract method 0x86522973.
+//
+// Solidity: function VOTE_TYPEHASH() view returns(bytes32)
+func (_SecureVotingSystem *SecureVotingSystemSession) VOTETYPEHASH() ([32]byte, error) {
+	return _SecureVotingSystem.Contract.VOTETYPEHASH(&_SecureVotingSystem.CallOpts)
+}
+
+// VOTETYPEHASH is a free data retrieval call binding the contract method 0x86522973.
+//
+// Solidity: function VOTE_TYPEHASH() view returns(bytes32)
+func (_SecureVotingSystem *SecureVotingSystemCallerSession) VOTETYPEHASH() ([32]byte, error) {
+	return _SecureVotingSystem.Contract.VOTETYPEHASH(&_SecureVotingSystem.CallOpts)
+}
+
 // AuthorizedTerminals is a free data retrieval call binding the contract method 0x4596aee8.
 //
 // Solidity: function authorizedTerminals(address ) view returns(bool)
@@ -264,6 +295,37 @@ func (_SecureVotingSystem *SecureVotingSystemCallerSession) CurrentElectionId()
 	return _SecureVotingSystem.Contract.CurrentElectionId(&_SecureVotingSystem.CallOpts)
 }
 
+// DomainSeparator is a free data retrieval call binding the contract method 0xf698da25.
+//
+// Solidity: function domainSeparator() view returns(bytes32)
+func (_SecureVotingSystem *SecureVotingSystemCaller) DomainSeparator(opts *bind.CallOpts) ([32]byte, error) {
+	var out []interface{}
+	err := _SecureVotingSystem.contract.Call(opts, &out, "domainSeparator")
+
+	if err != nil {
+		return *new([32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+
+	return out0, err
+
+}
+
+// DomainSeparator is a free data retrieval call binding the contract method 0xf698da25.
+//
+// Solidity: function domainSeparator() view returns(bytes32)
+func (_SecureVotingSystem *SecureVotingSystemSession) DomainSeparator() ([32]byte, error) {
+	return _SecureVotingSystem.Contract.DomainSeparator(&_SecureVotingSystem.CallOpts)
+}
+
+// DomainSeparator is a free data retrieval call binding the contract method 0xf698da25.
+//
+// Solidity: function domainSeparator() view returns(bytes32)
+func (_SecureVotingSystem *SecureVotingSystemCallerSession) DomainSeparator() ([32]byte, error) {
+	return _SecureVotingSystem.Contract.DomainSeparator(&_SecureVotingSystem.CallOpts)
+}
+
 // ElectionResults is a free data retrieval call binding the contract method 0x73ed31a3.
 //
 // Solidity: function electionResults(uint256 , string ) view returns(uint256)
@@ -360,6 +422,76 @@ func (_SecureVotingSystem *SecureVotingSystemCallerSession) Elections(arg0 *big.
 	return _SecureVotingSystem.Contract.Elections(&_SecureVotingSystem.CallOpts, arg0)
 }
 
+// Eip712Domain is a free data retrieval call binding the contract method 0x84b0196e.
+//
+// Solidity: function eip712Domain() view returns(bytes1 fields, string name, string version, uint256 chainId, address verifyingContract, bytes32 salt, uint256[] extensions)
+func (_SecureVotingSystem *SecureVotingSystemCaller) Eip712Domain(opts *bind.CallOpts) (struct {
+	Fields            [1]byte
+	Name              string
+	Version           string
+	ChainId           *big.Int
+	VerifyingContract common.Address
+	Salt              [32]byte
+	Extensions        []*big.Int
+}, error) {
+	var out []interface{}
+	err := _SecureVotingSystem.contract.Call(opts, &out, "eip712Domain")
+
+	outstruct := new(struct {
+		Fields            [1]byte
+		Name              string
+		Version           string
+		ChainId           *big.Int
+		VerifyingContract common.Address
+		Salt              [32]byte
+		Extensions        []*big.Int
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.Fields = *abi.ConvertType(out[0], new([1]byte)).(*[1]byte)
+	outstruct.Name = *abi.ConvertType(out[1], new(string)).(*string)
+	outstruct.Version = *abi.ConvertType(out[2], new(string)).(*string)
+	outstruct.ChainId = *abi.ConvertType(out[3], new(*big.Int)).(**big.Int)
+	outstruct.VerifyingContract = *abi.ConvertType(out[4], new(common.Address)).(*common.Address)
+	outstruct.Salt = *abi.ConvertType(out[5], new([32]byte)).(*[32]byte)
+	outstruct.Extensions = *abi.ConvertType(out[6], new([]*big.Int)).(*[]*big.Int)
+
+	return *outstruct, err
+
+}
+
+// Eip712Domain is a free data retrieval call binding the contract method 0x84b0196e.
+//
+// Solidity: function eip712Domain() view returns(bytes1 fields, string name, string version, uint256 chainId, address verifyingContract, bytes32 salt, uint256[] extensions)
+func (_SecureVotingSystem *SecureVotingSystemSession) Eip712Domain() (struct {
+	Fields            [1]byte
+	Name              string
+	Version           string
+	ChainId           *big.Int
+	VerifyingContract common.Address
+	Salt              [32]byte
+	Extensions        []*big.Int
+}, error) {
+	return _SecureVotingSystem.Contract.Eip712Domain(&_SecureVotingSystem.CallOpts)
+}
+
+// Eip712Domain is a free data retrieval call binding the contract method 0x84b0196e.
+//
+// Solidity: function eip712Domain() view returns(bytes1 fields, string name, string version, uint256 chainId, address verifyingContract, bytes32 salt, uint256[] extensions)
+func (_SecureVotingSystem *SecureVotingSystemCallerSession) Eip712Domain() (struct {
+	Fields            [1]byte
+	Name              string
+	Version           string
+	ChainId           *big.Int
+	VerifyingContract common.Address
+	Salt              [32]byte
+	Extensions        []*big.Int
+}, error) {
+	return _SecureVotingSystem.Contract.Eip712Domain(&_SecureVotingSystem.CallOpts)
+}
+
 // GetCurrentElectionId is a free data retrieval call binding the contract method 0xfe2b536b.
 //
 // Solidity: function getCurrentElectionId() view returns(uint256)
@@ -750,6 +882,37 @@ func (_SecureVotingSystem *SecureVotingSystemCallerSession) GetVoteDetails(_vote
 	return _SecureVotingSystem.Contract.GetVoteDetails(&_SecureVotingSystem.CallOpts, _voteId)
 }
 
+// GetVoteTerminal is a free data retrieval call binding the contract method 0xf696b77a.
+//
+// Solidity: function getVoteTerminal(uint256 _voteId) view returns(address)
+func (_SecureVotingSystem *SecureVotingSystemCaller) GetVoteTerminal(opts *bind.CallOpts, _voteId *big.Int) (common.Address, error) {
+	var out []interface{}
+	err := _SecureVotingSystem.contract.Call(opts, &out, "getVoteTerminal", _voteId)
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// GetVoteTerminal is a free data retrieval call binding the contract method 0xf696b77a.
+//
+// Solidity: function getVoteTerminal(uint256 _voteId) view returns(address)
+func (_SecureVotingSystem *SecureVotingSystemSession) GetVoteTerminal(_voteId *big.Int) (common.Address, error) {
+	return _SecureVotingSystem.Contract.GetVoteTerminal(&_SecureVotingSystem.CallOpts, _voteId)
+}
+
+// GetVoteTerminal is a free data retrieval call binding the contract method 0xf696b77a.
+//
+// Solidity: function getVoteTerminal(uint256 _voteId) view returns(address)
+func (_SecureVotingSystem *SecureVotingSystemCallerSession) GetVoteTerminal(_voteId *big.Int) (common.Address, error) {
+	return _SecureVotingSystem.Contract.GetVoteTerminal(&_SecureVotingSystem.CallOpts, _voteId)
+}
+
 // GetVotesByTimeRange is a free data retrieval call binding the contract method 0x73b93c34.
 //
 // Solidity: function getVotesByTimeRange(uint256 _startTime, uint256 _endTime) view returns(uint256[])
@@ -781,12 +944,43 @@ func (_SecureVotingSystem *SecureVotingSystemCallerSession) GetVotesByTimeRange(
 	return _SecureVotingSystem.Contract.GetVotesByTimeRange(&_SecureVotingSystem.CallOpts, _startTime, _endTime)
 }
 
-// HasVoted is a free data retrieval call binding the contract method 0x1b4613cb.
+// HasVoted is a free data retrieval call binding the contract method 0x74417bf4.
+//
+// Solidity: function hasVoted(uint256 , bytes32 ) view returns(bool)
+func (_SecureVotingSystem *SecureVotingSystemCaller) HasVoted(opts *bind.CallOpts, arg0 *big.Int, arg1 [32]byte) (bool, error) {
+	var out []interface{}
+	err := _SecureVotingSystem.contract.Call(opts, &out, "hasVoted", arg0, arg1)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// HasVoted is a free data retrieval call binding the contract method 0x74417bf4.
+//
+// Solidity: function hasVoted(uint256 , bytes32 ) view returns(bool)
+func (_SecureVotingSystem *SecureVotingSystemSession) HasVoted(arg0 *big.Int, arg1 [32]byte) (bool, error) {
+	return _SecureVotingSystem.Contract.HasVoted(&_SecureVotingSystem.CallOpts, arg0, arg1)
+}
+
+// HasVoted is a free data retrieval call binding the contract method 0x74417bf4.
+//
+// Solidity: function hasVoted(uint256 , bytes32 ) view returns(bool)
+func (_SecureVotingSystem *SecureVotingSystemCallerSession) HasVoted(arg0 *big.Int, arg1 [32]byte) (bool, error) {
+	return _SecureVotingSystem.Contract.HasVoted(&_SecureVotingSystem.CallOpts, arg0, arg1)
+}
+
+// HasVoterVoted is a free data retrieval call binding the contract method 0x6ca0f0af.
 //
-// Solidity: function hasVoted(bytes32 ) view returns(bool)
-func (_SecureVotingSystem *SecureVotingSystemCaller) HasVoted(opts *bind.CallOpts, arg0 [32]byte) (bool, error) {
+// Solidity: function hasVoterVoted(uint256 _electionId, bytes32 _verificationHash) view returns(bool)
+func (_SecureVotingSystem *SecureVotingSystemCaller) HasVoterVoted(opts *bind.CallOpts, _electionId *big.Int, _verificationHash [32]byte) (bool, error) {
 	var out []interface{}
-	err := _SecureVotingSystem.contract.Call(opts, &out, "hasVoted", arg0)
+	err := _SecureVotingSystem.contract.Call(opts, &out, "hasVoterVoted", _electionId, _verificationHash)
 
 	if err != nil {
 		return *new(bool), err
@@ -798,26 +992,26 @@ func (_SecureVotingSystem *SecureVotingSystemCaller) HasVoted(opts *bind.CallOpt
 
 }
 
-// HasVoted is a free data retrieval call binding the contract method 0x1b4613cb.
+// HasVoterVoted is a free data retrieval call binding the contract method 0x6ca0f0af.
 //
-// Solidity: function hasVoted(bytes32 ) view returns(bool)
-func (_SecureVotingSystem *SecureVotingSystemSession) HasVoted(arg0 [32]byte) (bool, error) {
-	return _SecureVotingSystem.Contract.HasVoted(&_SecureVotingSystem.CallOpts, arg0)
+// Solidity: function hasVoterVoted(uint256 _electionId, bytes32 _verificationHash) view returns(bool)
+func (_SecureVotingSystem *SecureVotingSystemSession) HasVoterVoted(_electionId *big.Int, _verificationHash [32]byte) (bool, error) {
+	return _SecureVotingSystem.Contract.HasVoterVoted(&_SecureVotingSystem.CallOpts, _electionId, _verificationHash)
 }
 
-// HasVoted is a free data retrieval call binding the contract method 0x1b4613cb.
+// HasVoterVoted is a free data retrieval call binding the contract method 0x6ca0f0af.
 //
-// Solidity: function hasVoted(bytes32 ) view returns(bool)
-func (_SecureVotingSystem *SecureVotingSystemCallerSession) HasVoted(arg0 [32]byte) (bool, error) {
-	return _SecureVotingSystem.Contract.HasVoted(&_SecureVotingSystem.CallOpts, arg0)
+// Solidity: function hasVoterVoted(uint256 _electionId, bytes32 _verificationHash) view returns(bool)
+func (_SecureVotingSystem *SecureVotingSystemCallerSession) HasVoterVoted(_electionId *big.Int, _verificationHash [32]byte) (bool, error) {
+	return _SecureVotingSystem.Contract.HasVoterVoted(&_SecureVotingSystem.CallOpts, _electionId, _verificationHash)
 }
 
-// HasVoterVoted is a free data retrieval call binding the contract method 0xc91d60ed.
+// IsOnVoterRoll is a free data retrieval call binding the contract method 0xdd5f84fc.
 //
-// Solidity: function hasVoterVoted(bytes32 _verificationHash) view returns(bool)
-func (_SecureVotingSystem *SecureVotingSystemCaller) HasVoterVoted(opts *bind.CallOpts, _verificationHash [32]byte) (bool, error) {
+// Solidity: function isOnVoterRoll(uint256 _electionId, bytes32 _verificationHash, bytes32[] _proof) view returns(bool)
+func (_SecureVotingSystem *SecureVotingSystemCaller) IsOnVoterRoll(opts *bind.CallOpts, _electionId *big.Int, _verificationHash [32]byte, _proof [][32]byte) (bool, error) {
 	var out []interface{}
-	err := _SecureVotingSystem.contract.Call(opts, &out, "hasVoterVoted", _verificationHash)
+	err := _SecureVotingSystem.contract.Call(opts, &out, "isOnVoterRoll", _electionId, _verificationHash, _proof)
 
 	if err != nil {
 		return *new(bool), err
@@ -829,18 +1023,18 @@ func (_SecureVotingSystem *SecureVotingSystemCaller) HasVoterVoted(opts *bind.Ca
 
 }
 
-// HasVoterVoted is a free data retrieval call binding the contract method 0xc91d60ed.
+// IsOnVoterRoll is a free data retrieval call binding the contract method 0xdd5f84fc.
 //
-// Solidity: function hasVoterVoted(bytes32 _verificationHash) view returns(bool)
-func (_SecureVotingSystem *SecureVotingSystemSession) HasVoterVoted(_verificationHash [32]byte) (bool, error) {
-	return _SecureVotingSystem.Contract.HasVoterVoted(&_SecureVotingSystem.CallOpts, _verificationHash)
+// Solidity: function isOnVoterRoll(uint256 _electionId, bytes32 _verificationHash, bytes32[] _proof) view returns(bool)
+func (_SecureVotingSystem *SecureVotingSystemSession) IsOnVoterRoll(_electionId *big.Int, _verificationHash [32]byte, _proof [][32]byte) (bool, error) {
+	return _SecureVotingSystem.Contract.IsOnVoterRoll(&_SecureVotingSystem.CallOpts, _electionId, _verificationHash, _proof)
 }
 
-// HasVoterVoted is a free data retrieval call binding the contract method 0xc91d60ed.
+// IsOnVoterRoll is a free data retrieval call binding the contract method 0xdd5f84fc.
 //
-// Solidity: function hasVoterVoted(bytes32 _verificationHash) view returns(bool)
-func (_SecureVotingSystem *SecureVotingSystemCallerSession) HasVoterVoted(_verificationHash [32]byte) (bool, error) {
-	return _SecureVotingSystem.Contract.HasVoterVoted(&_SecureVotingSystem.CallOpts, _verificationHash)
+// Solidity: function isOnVoterRoll(uint256 _electionId, bytes32 _verificationHash, bytes32[] _proof) view returns(bool)
+func (_SecureVotingSystem *SecureVotingSystemCallerSession) IsOnVoterRoll(_electionId *big.Int, _verificationHash [32]byte, _proof [][32]byte) (bool, error) {
+	return _SecureVotingSystem.Contract.IsOnVoterRoll(&_SecureVotingSystem.CallOpts, _electionId, _verificationHash, _proof)
 }
 
 // IsTerminalAuthorized is a free data retrieval call binding the contract method 0x184acbab.
@@ -970,12 +1164,136 @@ func (_SecureVotingSystem *SecureVotingSystemCallerSession) PollingUnits(arg0 st
 	return _SecureVotingSystem.Contract.PollingUnits(&_SecureVotingSystem.CallOpts, arg0)
 }
 
-// VerificationHashToVoteId is a free data retrieval call binding the contract method 0x8dc41911.
+// UsedVoteNonces is a free data retrieval call binding the contract method 0xc731b91d.
+//
+// Solidity: function usedVoteNonces(address , bytes32 ) view returns(bool)
+func (_SecureVotingSystem *SecureVotingSystemCaller) UsedVoteNonces(opts *bind.CallOpts, arg0 common.Address, arg1 [32]byte) (bool, error) {
+	var out []interface{}
+	err := _SecureVotingSystem.contract.Call(opts, &out, "usedVoteNonces", arg0, arg1)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// UsedVoteNonces is a free data retrieval call binding the contract method 0xc731b91d.
+//
+// Solidity: function usedVoteNonces(address , bytes32 ) view returns(bool)
+func (_SecureVotingSystem *SecureVotingSystemSession) UsedVoteNonces(arg0 common.Address, arg1 [32]byte) (bool, error) {
+	return _SecureVotingSystem.Contract.UsedVoteNonces(&_SecureVotingSystem.CallOpts, arg0, arg1)
+}
+
+// UsedVoteNonces is a free data retrieval call binding the contract method 0xc731b91d.
+//
+// Solidity: function usedVoteNonces(address , bytes32 ) view returns(bool)
+func (_SecureVotingSystem *SecureVotingSystemCallerSession) UsedVoteNonces(arg0 common.Address, arg1 [32]byte) (bool, error) {
+	return _SecureVotingSystem.Contract.UsedVoteNonces(&_SecureVotingSystem.CallOpts, arg0, arg1)
+}
+
+// VerificationHashToVoteId is a free data retrieval call binding the contract method 0xf96760be.
+//
+// Solidity: function verificationHashToVoteId(uint256 , bytes32 ) view returns(uint256)
+func (_SecureVotingSystem *SecureVotingSystemCaller) VerificationHashToVoteId(opts *bind.CallOpts, arg0 *big.Int, arg1 [32]byte) (*big.Int, error) {
+	var out []interface{}
+	err := _SecureVotingSystem.contract.Call(opts, &out, "verificationHashToVoteId", arg0, arg1)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// VerificationHashToVoteId is a free data retrieval call binding the contract method 0xf96760be.
 //
-// Solidity: function verificationHashToVoteId(bytes32 ) view returns(uint256)
-func (_SecureVotingSystem *SecureVotingSystemCaller) VerificationHashToVoteId(opts *bind.CallOpts, arg0 [32]byte) (*big.Int, error) {
+// Solidity: function verificationHashToVoteId(uint256 , bytes32 ) view returns(uint256)
+func (_SecureVotingSystem *SecureVotingSystemSession) VerificationHashToVoteId(arg0 *big.Int, arg1 [32]byte) (*big.Int, error) {
+	return _SecureVotingSystem.Contract.VerificationHashToVoteId(&_SecureVotingSystem.CallOpts, arg0, arg1)
+}
+
+// VerificationHashToVoteId is a free data retrieval call binding the contract method 0xf96760be.
+//
+// Solidity: function verificationHashToVoteId(uint256 , bytes32 ) view returns(uint256)
+func (_SecureVotingSystem *SecureVotingSystemCallerSession) VerificationHashToVoteId(arg0 *big.Int, arg1 [32]byte) (*big.Int, error) {
+	return _SecureVotingSystem.Contract.VerificationHashToVoteId(&_SecureVotingSystem.CallOpts, arg0, arg1)
+}
+
+// VoteTerminals is a free data retrieval call binding the contract method 0x939fb39f.
+//
+// Solidity: function voteTerminals(uint256 ) view returns(address)
+func (_SecureVotingSystem *SecureVotingSystemCaller) VoteTerminals(opts *bind.CallOpts, arg0 *big.Int) (common.Address, error) {
+	var out []interface{}
+	err := _SecureVotingSystem.contract.Call(opts, &out, "voteTerminals", arg0)
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// VoteTerminals is a free data retrieval call binding the contract method 0x939fb39f.
+//
+// Solidity: function voteTerminals(uint256 ) view returns(address)
+func (_SecureVotingSystem *SecureVotingSystemSession) VoteTerminals(arg0 *big.Int) (common.Address, error) {
+	return _SecureVotingSystem.Contract.VoteTerminals(&_SecureVotingSystem.CallOpts, arg0)
+}
+
+// VoteTerminals is a free data retrieval call binding the contract method 0x939fb39f.
+//
+// Solidity: function voteTerminals(uint256 ) view returns(address)
+func (_SecureVotingSystem *SecureVotingSystemCallerSession) VoteTerminals(arg0 *big.Int) (common.Address, error) {
+	return _SecureVotingSystem.Contract.VoteTerminals(&_SecureVotingSystem.CallOpts, arg0)
+}
+
+// VoterRollRoots is a free data retrieval call binding the contract method 0x8ffa8dee.
+//
+// Solidity: function voterRollRoots(uint256 ) view returns(bytes32)
+func (_SecureVotingSystem *SecureVotingSystemCaller) VoterRollRoots(opts *bind.CallOpts, arg0 *big.Int) ([32]byte, error) {
+	var out []interface{}
+	err := _SecureVotingSystem.contract.Call(opts, &out, "voterRollRoots", arg0)
+
+	if err != nil {
+		return *new([32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+
+	return out0, err
+
+}
+
+// VoterRollRoots is a free data retrieval call binding the contract method 0x8ffa8dee.
+//
+// Solidity: function voterRollRoots(uint256 ) view returns(bytes32)
+func (_SecureVotingSystem *SecureVotingSystemSession) VoterRollRoots(arg0 *big.Int) ([32]byte, error) {
+	return _SecureVotingSystem.Contract.VoterRollRoots(&_SecureVotingSystem.CallOpts, arg0)
+}
+
+// VoterRollRoots is a free data retrieval call binding the contract method 0x8ffa8dee.
+//
+// Solidity: function voterRollRoots(uint256 ) view returns(bytes32)
+func (_SecureVotingSystem *SecureVotingSystemCallerSession) VoterRollRoots(arg0 *big.Int) ([32]byte, error) {
+	return _SecureVotingSystem.Contract.VoterRollRoots(&_SecureVotingSystem.CallOpts, arg0)
+}
+
+// VoterRollSizes is a free data retrieval call binding the contract method 0x3ee7f49f.
+//
+// Solidity: function voterRollSizes(uint256 ) view returns(uint256)
+func (_SecureVotingSystem *SecureVotingSystemCaller) VoterRollSizes(opts *bind.CallOpts, arg0 *big.Int) (*big.Int, error) {
 	var out []interface{}
-	err := _SecureVotingSystem.contract.Call(opts, &out, "verificationHashToVoteId", arg0)
+	err := _SecureVotingSystem.contract.Call(opts, &out, "voterRollSizes", arg0)
 
 	if err != nil {
 		return *new(*big.Int), err
@@ -987,18 +1305,18 @@ func (_SecureVotingSystem *SecureVotingSystemCaller) VerificationHashToVoteId(op
 
 }
 
-// VerificationHashToVoteId is a free data retrieval call binding the contract method 0x8dc41911.
+// VoterRollSizes is a free data retrieval call binding the contract method 0x3ee7f49f.
 //
-// Solidity: function verificationHashToVoteId(bytes32 ) view returns(uint256)
-func (_SecureVotingSystem *SecureVotingSystemSession) VerificationHashToVoteId(arg0 [32]byte) (*big.Int, error) {
-	return _SecureVotingSystem.Contract.VerificationHashToVoteId(&_SecureVotingSystem.CallOpts, arg0)
+// Solidity: function voterRollSizes(uint256 ) view returns(uint256)
+func (_SecureVotingSystem *SecureVotingSystemSession) VoterRollSizes(arg0 *big.Int) (*big.Int, error) {
+	return _SecureVotingSystem.Contract.VoterRollSizes(&_SecureVotingSystem.CallOpts, arg0)
 }
 
-// VerificationHashToVoteId is a free data retrieval call binding the contract method 0x8dc41911.
+// VoterRollSizes is a free data retrieval call binding the contract method 0x3ee7f49f.
 //
-// Solidity: function verificationHashToVoteId(bytes32 ) view returns(uint256)
-func (_SecureVotingSystem *SecureVotingSystemCallerSession) VerificationHashToVoteId(arg0 [32]byte) (*big.Int, error) {
-	return _SecureVotingSystem.Contract.VerificationHashToVoteId(&_SecureVotingSystem.CallOpts, arg0)
+// Solidity: function voterRollSizes(uint256 ) view returns(uint256)
+func (_SecureVotingSystem *SecureVotingSystemCallerSession) VoterRollSizes(arg0 *big.Int) (*big.Int, error) {
+	return _SecureVotingSystem.Contract.VoterRollSizes(&_SecureVotingSystem.CallOpts, arg0)
 }
 
 // Votes is a free data retrieval call binding the contract method 0x5df81330.
@@ -1092,6 +1410,48 @@ func (_SecureVotingSystem *SecureVotingSystemTransactorSession) AuthorizeTermina
 	return _SecureVotingSystem.Contract.AuthorizeTerminal(&_SecureVotingSystem.TransactOpts, _terminal, _status)
 }
 
+// CastEncryptedVote is a paid mutator transaction binding the contract method 0x44060907.
+//
+// Solidity: function castEncryptedVote(bytes32 _verificationHash, bytes32 _ballotHash, uint256 _electionId, string _pollingUnitId) returns(uint256)
+func (_SecureVotingSystem *SecureVotingSystemTransactor) CastEncryptedVote(opts *bind.TransactOpts, _verificationHash [32]byte, _ballotHash [32]byte, _electionId *big.Int, _pollingUnitId string) (*types.Transaction, error) {
+	return _SecureVotingSystem.contract.Transact(opts, "castEncryptedVote", _verificationHash, _ballotHash, _electionId, _pollingUnitId)
+}
+
+// CastEncryptedVote is a paid mutator transaction binding the contract method 0x44060907.
+//
+// Solidity: function castEncryptedVote(bytes32 _verificationHash, bytes32 _ballotHash, uint256 _electionId, string _pollingUnitId) returns(uint256)
+func (_SecureVotingSystem *SecureVotingSystemSession) CastEncryptedVote(_verificationHash [32]byte, _ballotHash [32]byte, _electionId *big.Int, _pollingUnitId string) (*types.Transaction, error) {
+	return _SecureVotingSystem.Contract.CastEncryptedVote(&_SecureVotingSystem.TransactOpts, _verificationHash, _ballotHash, _electionId, _pollingUnitId)
+}
+
+// CastEncryptedVote is a paid mutator transaction binding the contract method 0x44060907.
+//
+// Solidity: function castEncryptedVote(bytes32 _verificationHash, bytes32 _ballotHash, uint256 _electionId, string _pollingUnitId) returns(uint256)
+func (_SecureVotingSystem *SecureVotingSystemTransactorSession) CastEncryptedVote(_verificationHash [32]byte, _ballotHash [32]byte, _electionId *big.Int, _pollingUnitId string) (*types.Transaction, error) {
+	return _SecureVotingSystem.Contract.CastEncryptedVote(&_SecureVotingSystem.TransactOpts, _verificationHash, _ballotHash, _electionId, _pollingUnitId)
+}
+
+// CastEncryptedVoteBySig is a paid mutator transaction binding the contract method 0x1da1aa59.
+//
+// Solidity: function castEncryptedVoteBySig(bytes32 _verificationHash, bytes32 _ballotHash, uint256 _electionId, string _pollingUnitId, bytes32 _nonce, uint256 _timestamp, bytes _signature) returns(uint256)
+func (_SecureVotingSystem *SecureVotingSystemTransactor) CastEncryptedVoteBySig(opts *bind.TransactOpts, _verificationHash [32]byte, _ballotHash [32]byte, _electionId *big.Int, _pollingUnitId string, _nonce [32]byte, _timestamp *big.Int, _signature []byte) (*types.Transaction, error) {
+	return _SecureVotingSystem.contract.Transact(opts, "castEncryptedVoteBySig", _verificationHash, _ballotHash, _electionId, _pollingUnitId, _nonce, _timestamp, _signature)
+}
+
+// CastEncryptedVoteBySig is a paid mutator transaction binding the contract method 0x1da1aa59.
+//
+// Solidity: function castEncryptedVoteBySig(bytes32 _verificationHash, bytes32 _ballotHash, uint256 _electionId, string _pollingUnitId, bytes32 _nonce, uint256 _timestamp, bytes _signature) returns(uint256)
+func (_SecureVotingSystem *SecureVotingSystemSession) CastEncryptedVoteBySig(_verificationHash [32]byte, _ballotHash [32]byte, _electionId *big.Int, _pollingUnitId string, _nonce [32]byte, _timestamp *big.Int, _signature []byte) (*types.Transaction, error) {
+	return _SecureVotingSystem.Contract.CastEncryptedVoteBySig(&_SecureVotingSystem.TransactOpts, _verificationHash, _ballotHash, _electionId, _pollingUnitId, _nonce, _timestamp, _signature)
+}
+
+// CastEncryptedVoteBySig is a paid mutator transaction binding the contract method 0x1da1aa59.
+//
+// Solidity: function castEncryptedVoteBySig(bytes32 _verificationHash, bytes32 _ballotHash, uint256 _electionId, string _pollingUnitId, bytes32 _nonce, uint256 _timestamp, bytes _signature) returns(uint256)
+func (_SecureVotingSystem *SecureVotingSystemTransactorSession) CastEncryptedVoteBySig(_verificationHash [32]byte, _ballotHash [32]byte, _electionId *big.Int, _pollingUnitId string, _nonce [32]byte, _timestamp *big.Int, _signature []byte) (*types.Transaction, error) {
+	return _SecureVotingSystem.Contract.CastEncryptedVoteBySig(&_SecureVotingSystem.TransactOpts, _verificationHash, _ballotHash, _electionId, _pollingUnitId, _nonce, _timestamp, _signature)
+}
+
 // CastVote is a paid mutator transaction binding the contract method 0x710f750c.
 //
 // Solidity: function castVote(bytes32 _verificationHash, bytes32 _encryptedVote, string _pollingUnitId, string _candidateId) returns(uint256)
@@ -1113,6 +1473,27 @@ func (_SecureVotingSystem *SecureVotingSystemTransactorSession) CastVote(_verifi
 	return _SecureVotingSystem.Contract.CastVote(&_SecureVotingSystem.TransactOpts, _verificationHash, _encryptedVote, _pollingUnitId, _candidateId)
 }
 
+// CastVoteBySig is a paid mutator transaction binding the contract method 0xf76e0484.
+//
+// Solidity: function castVoteBySig(bytes32 _verificationHash, bytes32 _encryptedVote, string _pollingUnitId, string _candidateId, uint256 _electionId, bytes32 _nonce, uint256 _timestamp, bytes _signature) returns(uint256)
+func (_SecureVotingSystem *SecureVotingSystemTransactor) CastVoteBySig(opts *bind.TransactOpts, _verificationHash [32]byte, _encryptedVote [32]byte, _pollingUnitId string, _candidateId string, _electionId *big.Int, _nonce [32]byte, _timestamp *big.Int, _signature []byte) (*types.Transaction, error) {
+	return _SecureVotingSystem.contract.Transact(opts, "castVoteBySig", _verificationHash, _encryptedVote, _pollingUnitId, _candidateId, _electionId, _nonce, _timestamp, _signature)
+}
+
+// CastVoteBySig is a paid mutator transaction binding the contract method 0xf76e0484.
+//
+// Solidity: function castVoteBySig(bytes32 _verificationHash, bytes32 _encryptedVote, string _pollingUnitId, string _candidateId, uint256 _electionId, bytes32 _nonce, uint256 _timestamp, bytes _signature) returns(uint256)
+func (_SecureVotingSystem *SecureVotingSystemSession) CastVoteBySig(_verificationHash [32]byte, _encryptedVote [32]byte, _pollingUnitId string, _candidateId string, _electionId *big.Int, _nonce [32]byte, _timestamp *big.Int, _signature []byte) (*types.Transaction, error) {
+	return _SecureVotingSystem.Contract.CastVoteBySig(&_SecureVotingSystem.TransactOpts, _verificationHash, _encryptedVote, _pollingUnitId, _candidateId, _electionId, _nonce, _timestamp, _signature)
+}
+
+// CastVoteBySig is a paid mutator transaction binding the contract method 0xf76e0484.
+//
+// Solidity: function castVoteBySig(bytes32 _verificationHash, bytes32 _encryptedVote, string _pollingUnitId, string _candidateId, uint256 _electionId, bytes32 _nonce, uint256 _timestamp, bytes _signature) returns(uint256)
+func (_SecureVotingSystem *SecureVotingSystemTransactorSession) CastVoteBySig(_verificationHash [32]byte, _encryptedVote [32]byte, _pollingUnitId string, _candidateId string, _electionId *big.Int, _nonce [32]byte, _timestamp *big.Int, _signature []byte) (*types.Transaction, error) {
+	return _SecureVotingSystem.Contract.CastVoteBySig(&_SecureVotingSystem.TransactOpts, _verificationHash, _encryptedVote, _pollingUnitId, _candidateId, _electionId, _nonce, _timestamp, _signature)
+}
+
 // CreateElection is a paid mutator transaction binding the contract method 0xbc279047.
 //
 // Solidity: function createElection(string _name, uint256 _startTime, uint256 _endTime, string[] _candidates) returns(uint256)
@@ -1197,6 +1578,27 @@ func (_SecureVotingSystem *SecureVotingSystemTransactorSession) InvalidateVote(_
 	return _SecureVotingSystem.Contract.InvalidateVote(&_SecureVotingSystem.TransactOpts, _voteId, _reason)
 }
 
+// PublishVoterRoll is a paid mutator transaction binding the contract method 0x47a606a4.
+//
+// Solidity: function publishVoterRoll(uint256 _electionId, bytes32 _root, uint256 _voterCount) returns()
+func (_SecureVotingSystem *SecureVotingSystemTransactor) PublishVoterRoll(opts *bind.TransactOpts, _electionId *big.Int, _root [32]byte, _voterCount *big.Int) (*types.Transaction, error) {
+	return _SecureVotingSystem.contract.Transact(opts, "publishVoterRoll", _electionId, _root, _voterCount)
+}
+
+// PublishVoterRoll is a paid mutator transaction binding the contract method 0x47a606a4.
+//
+// Solidity: function publishVoterRoll(uint256 _electionId, bytes32 _root, uint256 _voterCount) returns()
+func (_SecureVotingSystem *SecureVotingSystemSession) PublishVoterRoll(_electionId *big.Int, _root [32]byte, _voterCount *big.Int) (*types.Transaction, error) {
+	return _SecureVotingSystem.Contract.PublishVoterRoll(&_SecureVotingSystem.TransactOpts, _electionId, _root, _voterCount)
+}
+
+// PublishVoterRoll is a paid mutator transaction binding the contract method 0x47a606a4.
+//
+// Solidity: function publishVoterRoll(uint256 _electionId, bytes32 _root, uint256 _voterCount) returns()
+func (_SecureVotingSystem *SecureVotingSystemTransactorSession) PublishVoterRoll(_electionId *big.Int, _root [32]byte, _voterCount *big.Int) (*types.Transaction, error) {
+	return _SecureVotingSystem.Contract.PublishVoterRoll(&_SecureVotingSystem.TransactOpts, _electionId, _root, _voterCount)
+}
+
 // RegisterCandidate is a paid mutator transaction binding the contract method 0xd1009367.
 //
 // Solidity: function registerCandidate(uint256 _electionId, string _candidateId) returns()
@@ -1476,9 +1878,9 @@ func (_SecureVotingSystem *SecureVotingSystemFilterer) ParseCandidateRegistered(
 	return event, nil
 }
 
-// SecureVotingSystemElectionCreatedIterator is returned from FilterElectionCreated and is used to iterate over the raw logs and unpacked data for ElectionCreated events raised by the SecureVotingSystem contract.
-type SecureVotingSystemElectionCreatedIterator struct {
-	Event *SecureVotingSystemElectionCreated // Event containing the contract specifics and raw log
+// SecureVotingSystemEIP712DomainChangedIterator is returned from FilterEIP712DomainChanged and is used to iterate over the raw logs and unpacked data for EIP712DomainChanged events raised by the SecureVotingSystem contract.
+type SecureVotingSystemEIP712DomainChangedIterator struct {
+	Event *SecureVotingSystemEIP712DomainChanged // Event containing the contract specifics and raw log
 
 	contract *bind.BoundContract // Generic contract to use for unpacking event data
 	event    string              // Event name to use for unpacking event data
@@ -1492,7 +1894,7 @@ type SecureVotingSystemElectionCreatedIterator struct {
 // Next advances the iterator to the subsequent event, returning whether there
 // are any more events found. In case of a retrieval or parsing error, false is
 // returned and Error() can be queried for the exact failure.
-func (it *SecureVotingSystemElectionCreatedIterator) Next() bool {
+func (it *SecureVotingSystemEIP712DomainChangedIterator) Next() bool {
 	// If the iterator failed, stop iterating
 	if it.fail != nil {
 		return false
@@ -1501,7 +1903,7 @@ func (it *SecureVotingSystemElectionCreatedIterator) Next() bool {
 	if it.done {
 		select {
 		case log := <-it.logs:
-			it.Event = new(SecureVotingSystemElectionCreated)
+			it.Event = new(SecureVotingSystemEIP712DomainChanged)
 			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
 				it.fail = err
 				return false
@@ -1516,7 +1918,7 @@ func (it *SecureVotingSystemElectionCreatedIterator) Next() bool {
 	// Iterator still in progress, wait for either a data or an error event
 	select {
 	case log := <-it.logs:
-		it.Event = new(SecureVotingSystemElectionCreated)
+		it.Event = new(SecureVotingSystemEIP712DomainChanged)
 		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
 			it.fail = err
 			return false
@@ -1532,54 +1934,40 @@ func (it *SecureVotingSystemElectionCreatedIterator) Next() bool {
 }
 
 // Error returns any retrieval or parsing error occurred during filtering.
-func (it *SecureVotingSystemElectionCreatedIterator) Error() error {
+func (it *SecureVotingSystemEIP712DomainChangedIterator) Error() error {
 	return it.fail
 }
 
 // Close terminates the iteration process, releasing any pending underlying
 // resources.
-func (it *SecureVotingSystemElectionCreatedIterator) Close() error {
+func (it *SecureVotingSystemEIP712DomainChangedIterator) Close() error {
 	it.sub.Unsubscribe()
 	return nil
 }
 
-// SecureVotingSystemElectionCreated represents a ElectionCreated event raised by the SecureVotingSystem contract.
-type SecureVotingSystemElectionCreated struct {
-	ElectionId *big.Int
-	Name       string
-	StartTime  *big.Int
-	EndTime    *big.Int
-	Raw        types.Log // Blockchain specific contextual infos
+// SecureVotingSystemEIP712DomainChanged represents a EIP712DomainChanged event raised by the SecureVotingSystem contract.
+type SecureVotingSystemEIP712DomainChanged struct {
+	Raw types.Log // Blockchain specific contextual infos
 }
 
-// FilterElectionCreated is a free log retrieval operation binding the contract event 0xe7a0aae5d733e07e246dea86213a1ac1b0aa8554bde889bb75c12752f44e53d9.
+// FilterEIP712DomainChanged is a free log retrieval operation binding the contract event 0x0a6387c9ea3628b88a633bb4f3b151770f70085117a15f9bf3787cda53f13d31.
 //
-// Solidity: event ElectionCreated(uint256 indexed electionId, string name, uint256 startTime, uint256 endTime)
-func (_SecureVotingSystem *SecureVotingSystemFilterer) FilterElectionCreated(opts *bind.FilterOpts, electionId []*big.Int) (*SecureVotingSystemElectionCreatedIterator, error) {
+// Solidity: event EIP712DomainChanged()
+func (_SecureVotingSystem *SecureVotingSystemFilterer) FilterEIP712DomainChanged(opts *bind.FilterOpts) (*SecureVotingSystemEIP712DomainChangedIterator, error) {
 
-	var electionIdRule []interface{}
-	for _, electionIdItem := range electionId {
-		electionIdRule = append(electionIdRule, electionIdItem)
-	}
-
-	logs, sub, err := _SecureVotingSystem.contract.FilterLogs(opts, "ElectionCreated", electionIdRule)
+	logs, sub, err := _SecureVotingSystem.contract.FilterLogs(opts, "EIP712DomainChanged")
 	if err != nil {
 		return nil, err
 	}
-	return &SecureVotingSystemElectionCreatedIterator{contract: _SecureVotingSystem.contract, event: "ElectionCreated", logs: logs, sub: sub}, nil
+	return &SecureVotingSystemEIP712DomainChangedIterator{contract: _SecureVotingSystem.contract, event: "EIP712DomainChanged", logs: logs, sub: sub}, nil
 }
 
-// WatchElectionCreated is a free log subscription operation binding the contract event 0xe7a0aae5d733e07e246dea86213a1ac1b0aa8554bde889bb75c12752f44e53d9.
+// WatchEIP712DomainChanged is a free log subscription operation binding the contract event 0x0a6387c9ea3628b88a633bb4f3b151770f70085117a15f9bf3787cda53f13d31.
 //
-// Solidity: event ElectionCreated(uint256 indexed electionId, string name, uint256 startTime, uint256 endTime)
-func (_SecureVotingSystem *SecureVotingSystemFilterer) WatchElectionCreated(opts *bind.WatchOpts, sink chan<- *SecureVotingSystemElectionCreated, electionId []*big.Int) (event.Subscription, error) {
-
-	var electionIdRule []interface{}
-	for _, electionIdItem := range electionId {
-		electionIdRule = append(electionIdRule, electionIdItem)
-	}
+// Solidity: event EIP712DomainChanged()
+func (_SecureVotingSystem *SecureVotingSystemFilterer) WatchEIP712DomainChanged(opts *bind.WatchOpts, sink chan<- *SecureVotingSystemEIP712DomainChanged) (event.Subscription, error) {
 
-	logs, sub, err := _SecureVotingSystem.contract.WatchLogs(opts, "ElectionCreated", electionIdRule)
+	logs, sub, err := _SecureVotingSystem.contract.WatchLogs(opts, "EIP712DomainChanged")
 	if err != nil {
 		return nil, err
 	}
@@ -1589,8 +1977,8 @@ func (_SecureVotingSystem *SecureVotingSystemFilterer) WatchElectionCreated(opts
 			select {
 			case log := <-logs:
 				// New log arrived, parse the event and forward to the user
-				event := new(SecureVotingSystemElectionCreated)
-				if err := _SecureVotingSystem.contract.UnpackLog(event, "ElectionCreated", log); err != nil {
+				event := new(SecureVotingSystemEIP712DomainChanged)
+				if err := _SecureVotingSystem.contract.UnpackLog(event, "EIP712DomainChanged", log); err != nil {
 					return err
 				}
 				event.Raw = log
@@ -1611,21 +1999,21 @@ func (_SecureVotingSystem *SecureVotingSystemFilterer) WatchElectionCreated(opts
 	}), nil
 }
 
-// ParseElectionCreated is a log parse operation binding the contract event 0xe7a0aae5d733e07e246dea86213a1ac1b0aa8554bde889bb75c12752f44e53d9.
+// ParseEIP712DomainChanged is a log parse operation binding the contract event 0x0a6387c9ea3628b88a633bb4f3b151770f70085117a15f9bf3787cda53f13d31.
 //
-// Solidity: event ElectionCreated(uint256 indexed electionId, string name, uint256 startTime, uint256 endTime)
-func (_SecureVotingSystem *SecureVotingSystemFilterer) ParseElectionCreated(log types.Log) (*SecureVotingSystemElectionCreated, error) {
-	event := new(SecureVotingSystemElectionCreated)
-	if err := _SecureVotingSystem.contract.UnpackLog(event, "ElectionCreated", log); err != nil {
+// Solidity: event EIP712DomainChanged()
+func (_SecureVotingSystem *SecureVotingSystemFilterer) ParseEIP712DomainChanged(log types.Log) (*SecureVotingSystemEIP712DomainChanged, error) {
+	event := new(SecureVotingSystemEIP712DomainChanged)
+	if err := _SecureVotingSystem.contract.UnpackLog(event, "EIP712DomainChanged", log); err != nil {
 		return nil, err
 	}
 	event.Raw = log
 	return event, nil
 }
 
-// SecureVotingSystemElectionEndedIterator is returned from FilterElectionEnded and is used to iterate over the raw logs and unpacked data for ElectionEnded events raised by the SecureVotingSystem contract.
-type SecureVotingSystemElectionEndedIterator struct {
-	Event *SecureVotingSystemElectionEnded // Event containing the contract specifics and raw log
+// SecureVotingSystemElectionCreatedIterator is returned from FilterElectionCreated and is used to iterate over the raw logs and unpacked data for ElectionCreated events raised by the SecureVotingSystem contract.
+type SecureVotingSystemElectionCreatedIterator struct {
+	Event *SecureVotingSystemElectionCreated // Event containing the contract specifics and raw log
 
 	contract *bind.BoundContract // Generic contract to use for unpacking event data
 	event    string              // Event name to use for unpacking event data
@@ -1639,7 +2027,7 @@ type SecureVotingSystemElectionEndedIterator struct {
 // Next advances the iterator to the subsequent event, returning whether there
 // are any more events found. In case of a retrieval or parsing error, false is
 // returned and Error() can be queried for the exact failure.
-func (it *SecureVotingSystemElectionEndedIterator) Next() bool {
+func (it *SecureVotingSystemElectionCreatedIterator) Next() bool {
 	// If the iterator failed, stop iterating
 	if it.fail != nil {
 		return false
@@ -1648,7 +2036,154 @@ func (it *SecureVotingSystemElectionEndedIterator) Next() bool {
 	if it.done {
 		select {
 		case log := <-it.logs:
-			it.Event = new(SecureVotingSystemElectionEnded)
+			it.Event = new(SecureVotingSystemElectionCreated)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(SecureVotingSystemElectionCreated)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *SecureVotingSystemElectionCreatedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *SecureVotingSystemElectionCreatedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// SecureVotingSystemElectionCreated represents a ElectionCreated event raised by the SecureVotingSystem contract.
+type SecureVotingSystemElectionCreated struct {
+	ElectionId *big.Int
+	Name       string
+	StartTime  *big.Int
+	EndTime    *big.Int
+	Raw        types.Log // Blockchain specific contextual infos
+}
+
+// FilterElectionCreated is a free log retrieval operation binding the contract event 0xe7a0aae5d733e07e246dea86213a1ac1b0aa8554bde889bb75c12752f44e53d9.
+//
+// Solidity: event ElectionCreated(uint256 indexed electionId, string name, uint256 startTime, uint256 endTime)
+func (_SecureVotingSystem *SecureVotingSystemFilterer) FilterElectionCreated(opts *bind.FilterOpts, electionId []*big.Int) (*SecureVotingSystemElectionCreatedIterator, error) {
+
+	var electionIdRule []interface{}
+	for _, electionIdItem := range electionId {
+		electionIdRule = append(electionIdRule, electionIdItem)
+	}
+
+	logs, sub, err := _SecureVotingSystem.contract.FilterLogs(opts, "ElectionCreated", electionIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return &SecureVotingSystemElectionCreatedIterator{contract: _SecureVotingSystem.contract, event: "ElectionCreated", logs: logs, sub: sub}, nil
+}
+
+// WatchElectionCreated is a free log subscription operation binding the contract event 0xe7a0aae5d733e07e246dea86213a1ac1b0aa8554bde889bb75c12752f44e53d9.
+//
+// Solidity: event ElectionCreated(uint256 indexed electionId, string name, uint256 startTime, uint256 endTime)
+func (_SecureVotingSystem *SecureVotingSystemFilterer) WatchElectionCreated(opts *bind.WatchOpts, sink chan<- *SecureVotingSystemElectionCreated, electionId []*big.Int) (event.Subscription, error) {
+
+	var electionIdRule []interface{}
+	for _, electionIdItem := range electionId {
+		electionIdRule = append(electionIdRule, electionIdItem)
+	}
+
+	logs, sub, err := _SecureVotingSystem.contract.WatchLogs(opts, "ElectionCreated", electionIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(SecureVotingSystemElectionCreated)
+				if err := _SecureVotingSystem.contract.UnpackLog(event, "ElectionCreated", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseElectionCreated is a log parse operation binding the contract event 0xe7a0aae5d733e07e246dea86213a1ac1b0aa8554bde889bb75c12752f44e53d9.
+//
+// Solidity: event ElectionCreated(uint256 indexed electionId, string name, uint256 startTime, uint256 endTime)
+func (_SecureVotingSystem *SecureVotingSystemFilterer) ParseElectionCreated(log types.Log) (*SecureVotingSystemElectionCreated, error) {
+	event := new(SecureVotingSystemElectionCreated)
+	if err := _SecureVotingSystem.contract.UnpackLog(event, "ElectionCreated", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// SecureVotingSystemElectionEndedIterator is returned from FilterElectionEnded and is used to iterate over the raw logs and unpacked data for ElectionEnded events raised by the SecureVotingSystem contract.
+type SecureVotingSystemElectionEndedIterator struct {
+	Event *SecureVotingSystemElectionEnded // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *SecureVotingSystemElectionEndedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(SecureVotingSystemElectionEnded)
 			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
 				it.fail = err
 				return false
@@ -2664,3 +3199,311 @@ func (_SecureVotingSystem *SecureVotingSystemFilterer) ParseVoteInvalidated(log
 	event.Raw = log
 	return event, nil
 }
+
+// SecureVotingSystemVoteRelayedIterator is returned from FilterVoteRelayed and is used to iterate over the raw logs and unpacked data for VoteRelayed events raised by the SecureVotingSystem contract.
+type SecureVotingSystemVoteRelayedIterator struct {
+	Event *SecureVotingSystemVoteRelayed // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *SecureVotingSystemVoteRelayedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(SecureVotingSystemVoteRelayed)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(SecureVotingSystemVoteRelayed)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *SecureVotingSystemVoteRelayedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *SecureVotingSystemVoteRelayedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// SecureVotingSystemVoteRelayed represents a VoteRelayed event raised by the SecureVotingSystem contract.
+type SecureVotingSystemVoteRelayed struct {
+	VoteId   *big.Int
+	Terminal common.Address
+	Relayer  common.Address
+	Raw      types.Log // Blockchain specific contextual infos
+}
+
+// FilterVoteRelayed is a free log retrieval operation binding the contract event 0xbdea3c07b8406af90cc4846048c690c1a6b23f9115f5e1ae71c97001760574f0.
+//
+// Solidity: event VoteRelayed(uint256 indexed voteId, address indexed terminal, address indexed relayer)
+func (_SecureVotingSystem *SecureVotingSystemFilterer) FilterVoteRelayed(opts *bind.FilterOpts, voteId []*big.Int, terminal []common.Address, relayer []common.Address) (*SecureVotingSystemVoteRelayedIterator, error) {
+
+	var voteIdRule []interface{}
+	for _, voteIdItem := range voteId {
+		voteIdRule = append(voteIdRule, voteIdItem)
+	}
+	var terminalRule []interface{}
+	for _, terminalItem := range terminal {
+		terminalRule = append(terminalRule, terminalItem)
+	}
+	var relayerRule []interface{}
+	for _, relayerItem := range relayer {
+		relayerRule = append(relayerRule, relayerItem)
+	}
+
+	logs, sub, err := _SecureVotingSystem.contract.FilterLogs(opts, "VoteRelayed", voteIdRule, terminalRule, relayerRule)
+	if err != nil {
+		return nil, err
+	}
+	return &SecureVotingSystemVoteRelayedIterator{contract: _SecureVotingSystem.contract, event: "VoteRelayed", logs: logs, sub: sub}, nil
+}
+
+// WatchVoteRelayed is a free log subscription operation binding the contract event 0xbdea3c07b8406af90cc4846048c690c1a6b23f9115f5e1ae71c97001760574f0.
+//
+// Solidity: event VoteRelayed(uint256 indexed voteId, address indexed terminal, address indexed relayer)
+func (_SecureVotingSystem *SecureVotingSystemFilterer) WatchVoteRelayed(opts *bind.WatchOpts, sink chan<- *SecureVotingSystemVoteRelayed, voteId []*big.Int, terminal []common.Address, relayer []common.Address) (event.Subscription, error) {
+
+	var voteIdRule []interface{}
+	for _, voteIdItem := range voteId {
+		voteIdRule = append(voteIdRule, voteIdItem)
+	}
+	var terminalRule []interface{}
+	for _, terminalItem := range terminal {
+		terminalRule = append(terminalRule, terminalItem)
+	}
+	var relayerRule []interface{}
+	for _, relayerItem := range relayer {
+		relayerRule = append(relayerRule, relayerItem)
+	}
+
+	logs, sub, err := _SecureVotingSystem.contract.WatchLogs(opts, "VoteRelayed", voteIdRule, terminalRule, relayerRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(SecureVotingSystemVoteRelayed)
+				if err := _SecureVotingSystem.contract.UnpackLog(event, "VoteRelayed", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseVoteRelayed is a log parse operation binding the contract event 0xbdea3c07b8406af90cc4846048c690c1a6b23f9115f5e1ae71c97001760574f0.
+//
+// Solidity: event VoteRelayed(uint256 indexed voteId, address indexed terminal, address indexed relayer)
+func (_SecureVotingSystem *SecureVotingSystemFilterer) ParseVoteRelayed(log types.Log) (*SecureVotingSystemVoteRelayed, error) {
+	event := new(SecureVotingSystemVoteRelayed)
+	if err := _SecureVotingSystem.contract.UnpackLog(event, "VoteRelayed", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// SecureVotingSystemVoterRollPublishedIterator is returned from FilterVoterRollPublished and is used to iterate over the raw logs and unpacked data for VoterRollPublished events raised by the SecureVotingSystem contract.
+type SecureVotingSystemVoterRollPublishedIterator struct {
+	Event *SecureVotingSystemVoterRollPublished // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *SecureVotingSystemVoterRollPublishedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(SecureVotingSystemVoterRollPublished)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(SecureVotingSystemVoterRollPublished)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *SecureVotingSystemVoterRollPublishedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *SecureVotingSystemVoterRollPublishedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// SecureVotingSystemVoterRollPublished represents a VoterRollPublished event raised by the SecureVotingSystem contract.
+type SecureVotingSystemVoterRollPublished struct {
+	ElectionId *big.Int
+	Root       [32]byte
+	VoterCount *big.Int
+	Raw        types.Log // Blockchain specific contextual infos
+}
+
+// FilterVoterRollPublished is a free log retrieval operation binding the contract event 0x4c40dc03abd174f9e13ab3797e2739d2b14aa96856b1cc7a9da904c05c3fe0fd.
+//
+// Solidity: event VoterRollPublished(uint256 indexed electionId, bytes32 root, uint256 voterCount)
+func (_SecureVotingSystem *SecureVotingSystemFilterer) FilterVoterRollPublished(opts *bind.FilterOpts, electionId []*big.Int) (*SecureVotingSystemVoterRollPublishedIterator, error) {
+
+	var electionIdRule []interface{}
+	for _, electionIdItem := range electionId {
+		electionIdRule = append(electionIdRule, electionIdItem)
+	}
+
+	logs, sub, err := _SecureVotingSystem.contract.FilterLogs(opts, "VoterRollPublished", electionIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return &SecureVotingSystemVoterRollPublishedIterator{contract: _SecureVotingSystem.contract, event: "VoterRollPublished", logs: logs, sub: sub}, nil
+}
+
+// WatchVoterRollPublished is a free log subscription operation binding the contract event 0x4c40dc03abd174f9e13ab3797e2739d2b14aa96856b1cc7a9da904c05c3fe0fd.
+//
+// Solidity: event VoterRollPublished(uint256 indexed electionId, bytes32 root, uint256 voterCount)
+func (_SecureVotingSystem *SecureVotingSystemFilterer) WatchVoterRollPublished(opts *bind.WatchOpts, sink chan<- *SecureVotingSystemVoterRollPublished, electionId []*big.Int) (event.Subscription, error) {
+
+	var electionIdRule []interface{}
+	for _, electionIdItem := range electionId {
+		electionIdRule = append(electionIdRule, electionIdItem)
+	}
+
+	logs, sub, err := _SecureVotingSystem.contract.WatchLogs(opts, "VoterRollPublished", electionIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(SecureVotingSystemVoterRollPublished)
+				if err := _SecureVotingSystem.contract.UnpackLog(event, "VoterRollPublished", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseVoterRollPublished is a log parse operation binding the contract event 0x4c40dc03abd174f9e13ab3797e2739d2b14aa96856b1cc7a9da904c05c3fe0fd.
+//
+// Solidity: event VoterRollPublished(uint256 indexed electionId, bytes32 root, uint256 voterCount)
+func (_SecureVotingSystem *SecureVotingSystemFilterer) ParseVoterRollPublished(log types.Log) (*SecureVotingSystemVoterRollPublished, error) {
+	event := new(SecureVotingSystemVoterRollPublished)
+	if err := _SecureVotingSystem.contract.UnpackLog(event, "VoterRollPublished", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
@@ -0,0 +1,104 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// NonceSource returns the next nonce for an account including pending transactions
+type NonceSource interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// NonceManager hands out sequential nonces for the signing account so that
+// several transactions can be submitted without waiting for each other to be mined
+type NonceManager struct {
+	source      NonceSource
+	address     common.Address
+	next        uint64
+	synced      bool
+	outstanding int      // reserved nonces not yet sent or released
+	released    []uint64 // reserved nonces whose submission failed, handed out again first
+	mutex       sync.Mutex
+}
+
+// NewNonceManager creates a nonce manager for the given account
+func NewNonceManager(source NonceSource, address common.Address) *NonceManager {
+	return &NonceManager{
+		source:  source,
+		address: address,
+	}
+}
+
+// Next reserves and returns the next nonce, fetching the pending nonce from
+// the node the first time and after every resync. Every reserved nonce must be
+// returned with Sent or Release.
+func (nm *NonceManager) Next() (uint64, error) {
+	nm.mutex.Lock()
+	defer nm.mutex.Unlock()
+
+	if !nm.synced {
+		nonce, err := nm.source.PendingNonceAt(context.Background(), nm.address)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get pending nonce: %v", err)
+		}
+		nm.next = nonce
+		nm.synced = true
+		nm.released = nil
+	}
+
+	nm.outstanding++
+	if len(nm.released) > 0 {
+		nonce := nm.released[0]
+		nm.released = nm.released[1:]
+		return nonce, nil
+	}
+	nonce := nm.next
+	nm.next++
+	return nonce, nil
+}
+
+// Sent records that the transaction of a reserved nonce reached the node
+func (nm *NonceManager) Sent() {
+	nm.mutex.Lock()
+	defer nm.mutex.Unlock()
+
+	if nm.outstanding > 0 {
+		nm.outstanding--
+	}
+}
+
+// Release returns a reserved nonce whose transaction failed to send. While
+// other nonces are reserved it is handed out again, so it leaves no gap and
+// nonces held by other submitters are never reused. The manager resyncs with
+// the node once nothing is reserved, or straight away when the node refused
+// the nonce itself.
+func (nm *NonceManager) Release(nonce uint64, err error) {
+	nm.mutex.Lock()
+	defer nm.mutex.Unlock()
+
+	if nm.outstanding > 0 {
+		nm.outstanding--
+	}
+	if nm.outstanding == 0 || isNonceError(err) {
+		nm.synced = false
+		nm.released = nil
+		return
+	}
+	nm.released = append(nm.released, nonce)
+	sort.Slice(nm.released, func(i, j int) bool { return nm.released[i] < nm.released[j] })
+}
+
+// isNonceError reports whether the node refused a transaction for its nonce
+func isNonceError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "nonce too low") || strings.Contains(msg, "nonce too high")
+}
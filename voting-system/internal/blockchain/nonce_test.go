@@ -0,0 +1,106 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNonceSource returns a fixed pending nonce and counts how often it is asked
+type fakeNonceSource struct {
+	nonce uint64
+	calls int
+	mutex sync.Mutex
+}
+
+func (s *fakeNonceSource) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.calls++
+	return s.nonce, nil
+}
+
+func TestNonceManagerConcurrentReservations(t *testing.T) {
+	source := &fakeNonceSource{nonce: 7}
+	nm := NewNonceManager(source, common.Address{})
+
+	const n = 50
+	nonces := make([]uint64, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			nonce, err := nm.Next()
+			assert.NoError(t, err)
+			nonces[i] = nonce
+			nm.Sent()
+		}(i)
+	}
+	wg.Wait()
+
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+	for i, nonce := range nonces {
+		assert.Equal(t, uint64(7+i), nonce)
+	}
+	assert.Equal(t, 1, source.calls)
+}
+
+func TestNonceManagerReleaseKeepsOutstandingNonces(t *testing.T) {
+	source := &fakeNonceSource{nonce: 3}
+	nm := NewNonceManager(source, common.Address{})
+
+	first, err := nm.Next()
+	require.NoError(t, err)
+	second, err := nm.Next()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), first)
+	assert.Equal(t, uint64(4), second)
+
+	// A failed submission while another nonce is held hands its nonce out
+	// again instead of resyncing
+	nm.Release(first, errors.New("connection reset by peer"))
+	again, err := nm.Next()
+	require.NoError(t, err)
+	assert.Equal(t, first, again)
+	next, err := nm.Next()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), next)
+	assert.Equal(t, 1, source.calls)
+}
+
+func TestNonceManagerResyncs(t *testing.T) {
+	source := &fakeNonceSource{nonce: 3}
+	nm := NewNonceManager(source, common.Address{})
+
+	// Once nothing is outstanding a failure resyncs with the node
+	nonce, err := nm.Next()
+	require.NoError(t, err)
+	nm.Release(nonce, errors.New("connection reset by peer"))
+	source.nonce = 9
+	nonce, err = nm.Next()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(9), nonce)
+	assert.Equal(t, 2, source.calls)
+
+	// A nonce the node refuses resyncs even while others are outstanding
+	_, err = nm.Next()
+	require.NoError(t, err)
+	nm.Release(nonce, errors.New("Nonce too low: next nonce 12, tx nonce 9"))
+	source.nonce = 12
+	nonce, err = nm.Next()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(12), nonce)
+	assert.Equal(t, 3, source.calls)
+
+	assert.True(t, isNonceError(errors.New("nonce too high")))
+	assert.False(t, isNonceError(errors.New("insufficient funds")))
+	assert.False(t, isNonceError(nil))
+}
@@ -0,0 +1,240 @@
+package blockchain
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Pending vote queue states
+const (
+	QueueStatusPending = "pending" // waiting for its next sync attempt
+	QueueStatusSyncing = "syncing" // picked up by the sync manager
+	QueueStatusSynced  = "synced"  // recorded on chain
+	QueueStatusFailed  = "failed"  // gave up after max retries
+)
+
+// PendingVote is a queued vote together with its sync bookkeeping
+type PendingVote struct {
+	ID          int64
+	Vote        VoteData
+	Status      string
+	Attempts    int
+	LastError   string
+	NextRetryAt time.Time
+	TxHash      string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// VoteQueue stores votes waiting to be synced to the blockchain.
+// Implementations must be safe for concurrent use.
+type VoteQueue interface {
+	// Enqueue adds a vote to the queue. Enqueueing a verification hash that is
+	// already queued returns the existing entry.
+	Enqueue(vote VoteData) (*PendingVote, error)
+	// Due returns pending votes whose next retry time is at or before now
+	Due(now time.Time, limit int) ([]PendingVote, error)
+	// MarkSyncing flags a vote as in progress and increments its attempt count
+	MarkSyncing(id int64) error
+	// MarkSubmitted records the transaction hash of a vote awaiting its receipt
+	MarkSubmitted(id int64, txHash string) error
+	// MarkSynced records a successful sync
+	MarkSynced(id int64, txHash string) error
+	// MarkRetry returns a vote to pending with the error and next retry time
+	MarkRetry(id int64, lastError string, nextRetryAt time.Time) error
+	// MarkFailed permanently fails a vote
+	MarkFailed(id int64, lastError string) error
+	// Recover resets votes left in the syncing state by a crash back to pending
+	Recover() (int, error)
+	// Count returns the number of outstanding (pending or syncing) votes
+	Count() (int, error)
+	// List returns all outstanding votes, oldest first
+	List() ([]PendingVote, error)
+	// Clear removes all outstanding votes and returns how many were removed
+	Clear() (int, error)
+}
+
+// MemoryVoteQueue is a VoteQueue that keeps votes in memory only.
+// Votes are lost on restart; use a persistent queue in production.
+type MemoryVoteQueue struct {
+	votes  []*PendingVote
+	nextID int64
+	mutex  sync.Mutex
+}
+
+// NewMemoryVoteQueue creates an empty in-memory vote queue
+func NewMemoryVoteQueue() *MemoryVoteQueue {
+	return &MemoryVoteQueue{
+		votes:  make([]*PendingVote, 0),
+		nextID: 1,
+	}
+}
+
+// Enqueue adds a vote to the queue
+func (q *MemoryVoteQueue) Enqueue(vote VoteData) (*PendingVote, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, pv := range q.votes {
+		if pv.Vote.VerificationHash == vote.VerificationHash {
+			copied := *pv
+			return &copied, nil
+		}
+	}
+
+	now := time.Now().UTC()
+	pv := &PendingVote{
+		ID:          q.nextID,
+		Vote:        vote,
+		Status:      QueueStatusPending,
+		NextRetryAt: now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	q.nextID++
+	q.votes = append(q.votes, pv)
+
+	copied := *pv
+	return &copied, nil
+}
+
+// Due returns pending votes ready for another attempt
+func (q *MemoryVoteQueue) Due(now time.Time, limit int) ([]PendingVote, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	due := make([]PendingVote, 0)
+	for _, pv := range q.votes {
+		if limit > 0 && len(due) >= limit {
+			break
+		}
+		if pv.Status == QueueStatusPending && !pv.NextRetryAt.After(now) {
+			due = append(due, *pv)
+		}
+	}
+	return due, nil
+}
+
+// MarkSyncing flags a vote as in progress
+func (q *MemoryVoteQueue) MarkSyncing(id int64) error {
+	return q.update(id, func(pv *PendingVote) {
+		pv.Status = QueueStatusSyncing
+		pv.Attempts++
+	})
+}
+
+// MarkSubmitted records the transaction hash of an in-flight vote
+func (q *MemoryVoteQueue) MarkSubmitted(id int64, txHash string) error {
+	return q.update(id, func(pv *PendingVote) {
+		pv.TxHash = txHash
+	})
+}
+
+// MarkSynced records a successful sync
+func (q *MemoryVoteQueue) MarkSynced(id int64, txHash string) error {
+	return q.update(id, func(pv *PendingVote) {
+		pv.Status = QueueStatusSynced
+		pv.TxHash = txHash
+		pv.LastError = ""
+	})
+}
+
+// MarkRetry schedules another attempt
+func (q *MemoryVoteQueue) MarkRetry(id int64, lastError string, nextRetryAt time.Time) error {
+	return q.update(id, func(pv *PendingVote) {
+		pv.Status = QueueStatusPending
+		pv.LastError = lastError
+		pv.NextRetryAt = nextRetryAt.UTC()
+	})
+}
+
+// MarkFailed permanently fails a vote
+func (q *MemoryVoteQueue) MarkFailed(id int64, lastError string) error {
+	return q.update(id, func(pv *PendingVote) {
+		pv.Status = QueueStatusFailed
+		pv.LastError = lastError
+	})
+}
+
+// Recover resets syncing votes back to pending
+func (q *MemoryVoteQueue) Recover() (int, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	recovered := 0
+	for _, pv := range q.votes {
+		if pv.Status == QueueStatusSyncing {
+			pv.Status = QueueStatusPending
+			pv.UpdatedAt = time.Now().UTC()
+			recovered++
+		}
+	}
+	return recovered, nil
+}
+
+// Count returns the number of outstanding votes
+func (q *MemoryVoteQueue) Count() (int, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	count := 0
+	for _, pv := range q.votes {
+		if isOutstanding(pv.Status) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// List returns all outstanding votes
+func (q *MemoryVoteQueue) List() ([]PendingVote, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	list := make([]PendingVote, 0, len(q.votes))
+	for _, pv := range q.votes {
+		if isOutstanding(pv.Status) {
+			list = append(list, *pv)
+		}
+	}
+	return list, nil
+}
+
+// Clear removes all outstanding votes
+func (q *MemoryVoteQueue) Clear() (int, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	kept := make([]*PendingVote, 0, len(q.votes))
+	cleared := 0
+	for _, pv := range q.votes {
+		if isOutstanding(pv.Status) {
+			cleared++
+			continue
+		}
+		kept = append(kept, pv)
+	}
+	q.votes = kept
+	return cleared, nil
+}
+
+// update applies fn to the vote with the given ID
+func (q *MemoryVoteQueue) update(id int64, fn func(pv *PendingVote)) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, pv := range q.votes {
+		if pv.ID == id {
+			fn(pv)
+			pv.UpdatedAt = time.Now().UTC()
+			return nil
+		}
+	}
+	return fmt.Errorf("pending vote %d not found", id)
+}
+
+// isOutstanding reports whether a queue status still needs syncing
+func isOutstanding(status string) bool {
+	return status == QueueStatusPending || status == QueueStatusSyncing
+}
@@ -0,0 +1,89 @@
+package blockchain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryVoteQueue(t *testing.T) {
+	queue := NewMemoryVoteQueue()
+	vote := VoteData{VerificationHash: "h1", EncryptedVote: "c", PollingUnitID: "PU001", CandidateID: "C1"}
+
+	// Enqueueing the same verification hash twice keeps one entry
+	first, err := queue.Enqueue(vote)
+	require.NoError(t, err)
+	assert.Equal(t, QueueStatusPending, first.Status)
+	again, err := queue.Enqueue(vote)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, again.ID)
+	second, err := queue.Enqueue(VoteData{VerificationHash: "h2", PollingUnitID: "PU001", CandidateID: "C2"})
+	require.NoError(t, err)
+	count, err := queue.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	due, err := queue.Due(time.Now().UTC(), 1)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, first.ID, due[0].ID)
+
+	// Claimed votes are no longer due
+	require.NoError(t, queue.MarkSyncing(first.ID))
+	due, err = queue.Due(time.Now().UTC(), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, second.ID, due[0].ID)
+
+	// Retries wait for their next retry time
+	retryAt := time.Now().UTC().Add(time.Minute)
+	require.NoError(t, queue.MarkRetry(first.ID, "timeout", retryAt))
+	due, err = queue.Due(time.Now().UTC(), 10)
+	require.NoError(t, err)
+	assert.Len(t, due, 1)
+	due, err = queue.Due(retryAt, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, 1, due[0].Attempts)
+	assert.Equal(t, "timeout", due[0].LastError)
+
+	require.NoError(t, queue.MarkSyncing(first.ID))
+	require.NoError(t, queue.MarkSubmitted(first.ID, "0xtx"))
+	require.NoError(t, queue.MarkSynced(first.ID, "0xtx"))
+	require.NoError(t, queue.MarkSyncing(second.ID))
+	require.NoError(t, queue.MarkFailed(second.ID, "reverted"))
+	count, err = queue.Count()
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	assert.Error(t, queue.MarkSynced(99, "0xtx"))
+}
+
+func TestMemoryVoteQueueRecover(t *testing.T) {
+	queue := NewMemoryVoteQueue()
+	for _, hash := range []string{"h1", "h2", "h3"} {
+		_, err := queue.Enqueue(VoteData{VerificationHash: hash, PollingUnitID: "PU001", CandidateID: "C1"})
+		require.NoError(t, err)
+	}
+	require.NoError(t, queue.MarkSyncing(1))
+	require.NoError(t, queue.MarkSyncing(2))
+	require.NoError(t, queue.MarkSynced(2, "0xtx"))
+
+	// Votes claimed when the process stopped go back to pending
+	recovered, err := queue.Recover()
+	require.NoError(t, err)
+	assert.Equal(t, 1, recovered)
+	due, err := queue.Due(time.Now().UTC(), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, int64(1), due[0].ID)
+	assert.Equal(t, 1, due[0].Attempts)
+
+	cleared, err := queue.Clear()
+	require.NoError(t, err)
+	assert.Equal(t, 2, cleared)
+	count, err := queue.Count()
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
@@ -3,38 +3,120 @@ package blockchain
 import (
 	"fmt"
 	"log"
+	"math/big"
 	"sync"
 	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
+// SyncOptions tunes the sync pipeline. Zero values, and a nil MaxRetries, keep
+// the current setting.
+type SyncOptions struct {
+	RetryInterval  time.Duration // base delay before the first retry
+	MaxBackoff     time.Duration // upper bound for the exponential retry delay
+	MaxRetries     *int          // retries after the first attempt before a vote is failed; 0 disables retries
+	Workers        int           // number of votes submitted concurrently
+	BatchSize      int           // maximum votes picked up per sync pass
+	ReceiptTimeout time.Duration // how long to wait for a receipt before retrying
+	ReceiptPoll    time.Duration // how often in-flight transactions are checked
+}
+
+// voteSubmitter is the part of BlockchainClient the sync manager relies on
+type voteSubmitter interface {
+	HasVoterVoted(electionID *big.Int, verificationHash string) (bool, error)
+	CastVote(voteData VoteData) (*types.Transaction, error)
+	GetPendingReceipt(txHash common.Hash) (*types.Receipt, error)
+	GetCurrentElectionID() (*big.Int, error)
+}
+
+// inflightVote is a submitted vote whose receipt has not been seen yet
+type inflightVote struct {
+	vote        PendingVote
+	txHash      common.Hash
+	submittedAt time.Time
+}
+
 // SyncManager handles blockchain synchronization operations
 type SyncManager struct {
-	client         *BlockchainClient
+	client         voteSubmitter
 	syncInterval   time.Duration
 	retryInterval  time.Duration
+	maxBackoff     time.Duration
 	maxRetries     int
+	workers        int
+	batchSize      int
+	receiptTimeout time.Duration
+	receiptPoll    time.Duration
 	isRunning      bool
 	stopChan       chan struct{}
-	pendingVotes   []VoteData
+	queue          VoteQueue
+	inflight       map[int64]*inflightVote
+	inflightMutex  sync.Mutex
+	syncMutex      sync.Mutex
 	mutex          sync.RWMutex
 	onVoteSuccess  func(voteData VoteData, txHash string)
 	onVoteFailed   func(voteData VoteData, err error)
 	onSyncComplete func(syncedCount int, failedCount int)
 }
 
-// NewSyncManager creates a new blockchain sync manager
+// NewSyncManager creates a new blockchain sync manager backed by an in-memory queue.
+// Call SetVoteQueue before Start to use a persistent queue.
 func NewSyncManager(client *BlockchainClient, syncInterval time.Duration) *SyncManager {
 	return &SyncManager{
-		client:        client,
-		syncInterval:  syncInterval,
-		retryInterval: 30 * time.Second,
-		maxRetries:    3,
-		isRunning:     false,
-		stopChan:      make(chan struct{}),
-		pendingVotes:  make([]VoteData, 0),
+		client:         client,
+		syncInterval:   syncInterval,
+		retryInterval:  30 * time.Second,
+		maxBackoff:     10 * time.Minute,
+		maxRetries:     3,
+		workers:        4,
+		batchSize:      100,
+		receiptTimeout: 5 * time.Minute,
+		receiptPoll:    2 * time.Second,
+		isRunning:      false,
+		stopChan:       make(chan struct{}),
+		queue:          NewMemoryVoteQueue(),
+		inflight:       make(map[int64]*inflightVote),
 	}
 }
 
+// Configure applies sync pipeline options. It should be called before Start.
+func (sm *SyncManager) Configure(opts SyncOptions) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	if opts.RetryInterval > 0 {
+		sm.retryInterval = opts.RetryInterval
+	}
+	if opts.MaxBackoff > 0 {
+		sm.maxBackoff = opts.MaxBackoff
+	}
+	if opts.MaxRetries != nil && *opts.MaxRetries >= 0 {
+		sm.maxRetries = *opts.MaxRetries
+	}
+	if opts.Workers > 0 {
+		sm.workers = opts.Workers
+	}
+	if opts.BatchSize > 0 {
+		sm.batchSize = opts.BatchSize
+	}
+	if opts.ReceiptTimeout > 0 {
+		sm.receiptTimeout = opts.ReceiptTimeout
+	}
+	if opts.ReceiptPoll > 0 {
+		sm.receiptPoll = opts.ReceiptPoll
+	}
+}
+
+// SetVoteQueue replaces the queue used to hold pending votes
+func (sm *SyncManager) SetVoteQueue(queue VoteQueue) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.queue = queue
+}
+
 // SetCallbacks sets callback functions for sync events
 func (sm *SyncManager) SetCallbacks(
 	onSuccess func(VoteData, string),
@@ -47,23 +129,35 @@ func (sm *SyncManager) SetCallbacks(
 }
 
 // AddPendingVote adds a vote to the pending queue for sync
-func (sm *SyncManager) AddPendingVote(voteData VoteData) {
-	sm.mutex.Lock()
-	defer sm.mutex.Unlock()
+func (sm *SyncManager) AddPendingVote(voteData VoteData) error {
+	pv, err := sm.getQueue().Enqueue(voteData)
+	if err != nil {
+		return fmt.Errorf("failed to queue vote: %v", err)
+	}
 
-	sm.pendingVotes = append(sm.pendingVotes, voteData)
-	log.Printf("Added vote to pending queue. Total pending: %d", len(sm.pendingVotes))
+	log.Printf("Added vote %d to pending queue. Total pending: %d", pv.ID, sm.GetPendingVoteCount())
+	return nil
 }
 
 // GetPendingVoteCount returns the number of pending votes
 func (sm *SyncManager) GetPendingVoteCount() int {
-	sm.mutex.RLock()
-	defer sm.mutex.RUnlock()
+	count, err := sm.getQueue().Count()
+	if err != nil {
+		log.Printf("Failed to count pending votes: %v", err)
+		return 0
+	}
+	return count
+}
 
-	return len(sm.pendingVotes)
+// GetInflightCount returns the number of submitted votes awaiting a receipt
+func (sm *SyncManager) GetInflightCount() int {
+	sm.inflightMutex.Lock()
+	defer sm.inflightMutex.Unlock()
+
+	return len(sm.inflight)
 }
 
-// Start begins the sync process
+// Start begins the sync process, recovering any votes interrupted by a previous shutdown
 func (sm *SyncManager) Start() error {
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
@@ -72,10 +166,22 @@ func (sm *SyncManager) Start() error {
 		return fmt.Errorf("sync manager is already running")
 	}
 
+	recovered, err := sm.queue.Recover()
+	if err != nil {
+		return fmt.Errorf("failed to recover pending votes: %v", err)
+	}
+	pending, err := sm.queue.Count()
+	if err != nil {
+		return fmt.Errorf("failed to load pending votes: %v", err)
+	}
+
 	sm.isRunning = true
+	sm.stopChan = make(chan struct{})
 	go sm.syncLoop()
+	go sm.receiptLoop()
 
-	log.Printf("Blockchain sync manager started with interval: %v", sm.syncInterval)
+	log.Printf("Blockchain sync manager started with interval: %v, workers: %d (pending: %d, recovered: %d)",
+		sm.syncInterval, sm.workers, pending, recovered)
 	return nil
 }
 
@@ -133,143 +239,280 @@ func (sm *SyncManager) syncLoop() {
 	}
 }
 
-// performSync performs the actual synchronization of pending votes
+// receiptLoop checks in-flight transactions independently of the submission pass
+func (sm *SyncManager) receiptLoop() {
+	ticker := time.NewTicker(sm.receiptPoll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			sm.checkReceipts()
+
+		case <-sm.stopChan:
+			return
+		}
+	}
+}
+
+// performSync submits due votes through the worker pool without waiting for
+// them to be mined. Receipts are collected by checkReceipts.
 func (sm *SyncManager) performSync() (int, int, error) {
-	sm.mutex.Lock()
-	pendingVotes := make([]VoteData, len(sm.pendingVotes))
-	copy(pendingVotes, sm.pendingVotes)
-	sm.mutex.Unlock()
+	sm.syncMutex.Lock()
+	defer sm.syncMutex.Unlock()
+
+	// Settle anything mined since the last pass first
+	syncedCount, failedCount := sm.checkReceipts()
 
-	if len(pendingVotes) == 0 {
-		return 0, 0, nil
+	queue := sm.getQueue()
+	dueVotes, err := queue.Due(time.Now().UTC(), sm.batchSize)
+	if err != nil {
+		return syncedCount, failedCount, fmt.Errorf("failed to load pending votes: %v", err)
 	}
 
-	log.Printf("Starting sync of %d pending votes", len(pendingVotes))
+	if len(dueVotes) == 0 {
+		return syncedCount, failedCount, nil
+	}
 
-	var syncedCount, failedCount int
-	var successfulIndices []int
-
-	for i, voteData := range pendingVotes {
-		success := sm.syncSingleVote(voteData, 0)
-		if success {
-			syncedCount++
-			successfulIndices = append(successfulIndices, i)
-		} else {
-			failedCount++
-		}
+	log.Printf("Starting sync of %d pending votes with %d workers", len(dueVotes), sm.workers)
+
+	jobs := make(chan PendingVote)
+	var countMutex sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < sm.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pv := range jobs {
+				synced, failed := sm.submitVote(pv)
+
+				countMutex.Lock()
+				syncedCount += synced
+				failedCount += failed
+				countMutex.Unlock()
+			}
+		}()
 	}
 
-	// Remove successfully synced votes from pending queue
-	if len(successfulIndices) > 0 {
-		sm.removeSyncedVotes(successfulIndices)
+	for _, pv := range dueVotes {
+		if err := queue.MarkSyncing(pv.ID); err != nil {
+			log.Printf("Failed to mark vote %d as syncing: %v", pv.ID, err)
+			continue
+		}
+		pv.Attempts++
+		jobs <- pv
 	}
+	close(jobs)
+	wg.Wait()
 
-	log.Printf("Sync completed. Synced: %d, Failed: %d, Remaining: %d",
-		syncedCount, failedCount, sm.GetPendingVoteCount())
+	log.Printf("Sync pass completed. Synced: %d, Failed: %d, In flight: %d, Remaining: %d",
+		syncedCount, failedCount, sm.GetInflightCount(), sm.GetPendingVoteCount())
 
 	return syncedCount, failedCount, nil
 }
 
-// syncSingleVote attempts to sync a single vote with retry logic
-func (sm *SyncManager) syncSingleVote(voteData VoteData, retryCount int) bool {
+// submitVote sends a single vote and registers its transaction for receipt
+// tracking. It returns the number of votes synced and failed by this call.
+func (sm *SyncManager) submitVote(pv PendingVote) (int, int) {
 	// Check if voter has already voted (to prevent double submission)
-	hasVoted, err := sm.client.HasVoterVoted(voteData.VerificationHash)
+	electionID, err := sm.voteElection(pv.Vote)
 	if err != nil {
-		log.Printf("Error checking voter status: %v", err)
-		if retryCount < sm.maxRetries {
-			time.Sleep(sm.retryInterval)
-			return sm.syncSingleVote(voteData, retryCount+1)
-		}
-		return false
+		return 0, sm.handleFailure(pv, err)
+	}
+	hasVoted, err := sm.client.HasVoterVoted(electionID, pv.Vote.VerificationHash)
+	if err != nil {
+		return 0, sm.handleFailure(pv, fmt.Errorf("error checking voter status: %v", err))
 	}
 
 	if hasVoted {
-		log.Printf("Voter has already voted, removing from queue: %s", voteData.VerificationHash)
-		if sm.onVoteSuccess != nil {
-			sm.onVoteSuccess(voteData, "already_voted")
-		}
-		return true // Remove from queue since vote is already recorded
+		log.Printf("Voter has already voted, removing from queue: %s", pv.Vote.VerificationHash)
+		sm.handleSuccess(pv, "already_voted")
+		return 1, 0
 	}
 
-	// Attempt to cast the vote
-	tx, err := sm.client.CastVote(voteData)
+	tx, err := sm.client.CastVote(pv.Vote)
 	if err != nil {
-		log.Printf("Failed to cast vote (attempt %d/%d): %v", retryCount+1, sm.maxRetries+1, err)
+		return 0, sm.handleFailure(pv, err)
+	}
 
-		if retryCount < sm.maxRetries {
-			time.Sleep(sm.retryInterval)
-			return sm.syncSingleVote(voteData, retryCount+1)
-		}
+	if err := sm.getQueue().MarkSubmitted(pv.ID, tx.Hash().Hex()); err != nil {
+		log.Printf("Failed to record transaction for vote %d: %v", pv.ID, err)
+	}
 
-		if sm.onVoteFailed != nil {
-			sm.onVoteFailed(voteData, err)
-		}
-		return false
+	sm.inflightMutex.Lock()
+	sm.inflight[pv.ID] = &inflightVote{
+		vote:        pv,
+		txHash:      tx.Hash(),
+		submittedAt: time.Now(),
 	}
+	sm.inflightMutex.Unlock()
 
-	// Wait for transaction to be mined
-	receipt, err := sm.client.WaitForTransaction(tx)
-	if err != nil {
-		log.Printf("Transaction failed or timed out: %v", err)
+	return 0, 0
+}
 
-		if retryCount < sm.maxRetries {
-			time.Sleep(sm.retryInterval)
-			return sm.syncSingleVote(voteData, retryCount+1)
+// voteElection returns the election a queued vote is for. Votes queued before
+// they carried their election are taken to be for the current one.
+func (sm *SyncManager) voteElection(vote VoteData) (*big.Int, error) {
+	if vote.ElectionID == "" {
+		return sm.client.GetCurrentElectionID()
+	}
+	electionID, ok := new(big.Int).SetString(vote.ElectionID, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid election ID %q", vote.ElectionID)
+	}
+	return electionID, nil
+}
+
+// checkReceipts polls the receipts of in-flight transactions and settles the
+// ones that were mined or timed out. It returns the synced and failed counts.
+func (sm *SyncManager) checkReceipts() (int, int) {
+	sm.inflightMutex.Lock()
+	pending := make([]*inflightVote, 0, len(sm.inflight))
+	for _, iv := range sm.inflight {
+		pending = append(pending, iv)
+	}
+	sm.inflightMutex.Unlock()
+
+	var syncedCount, failedCount int
+	for _, iv := range pending {
+		receipt, err := sm.client.GetPendingReceipt(iv.txHash)
+		if err != nil {
+			log.Printf("Failed to check receipt for vote %d: %v", iv.vote.ID, err)
+			continue
 		}
 
-		if sm.onVoteFailed != nil {
-			sm.onVoteFailed(voteData, err)
+		if receipt == nil {
+			if time.Since(iv.submittedAt) < sm.receiptTimeout {
+				continue
+			}
+			if sm.removeInflight(iv.vote.ID) {
+				failedCount += sm.handleFailure(iv.vote, fmt.Errorf("transaction %s not mined within %v",
+					iv.txHash.Hex(), sm.receiptTimeout))
+			}
+			continue
 		}
-		return false
+
+		// The receipt loop and a sync pass may both have picked the vote up;
+		// only the one that stops tracking it settles it
+		if !sm.removeInflight(iv.vote.ID) {
+			continue
+		}
+		if receipt.Status == types.ReceiptStatusFailed {
+			failedCount += sm.handleFailure(iv.vote, fmt.Errorf("transaction %s failed", iv.txHash.Hex()))
+			continue
+		}
+
+		log.Printf("Vote synced successfully. TX: %s, Gas used: %d", receipt.TxHash.Hex(), receipt.GasUsed)
+		sm.handleSuccess(iv.vote, receipt.TxHash.Hex())
+		syncedCount++
 	}
 
-	log.Printf("Vote synced successfully. TX: %s, Gas used: %d",
-		receipt.TxHash.Hex(), receipt.GasUsed)
+	return syncedCount, failedCount
+}
 
+// handleSuccess marks a vote as synced and fires the success callback
+func (sm *SyncManager) handleSuccess(pv PendingVote, txHash string) {
+	if err := sm.getQueue().MarkSynced(pv.ID, txHash); err != nil {
+		log.Printf("Failed to mark vote %d as synced: %v", pv.ID, err)
+	}
 	if sm.onVoteSuccess != nil {
-		sm.onVoteSuccess(voteData, receipt.TxHash.Hex())
+		sm.onVoteSuccess(pv.Vote, txHash)
 	}
-
-	return true
 }
 
-// removeSyncedVotes removes successfully synced votes from the pending queue
-func (sm *SyncManager) removeSyncedVotes(successfulIndices []int) {
-	sm.mutex.Lock()
-	defer sm.mutex.Unlock()
+// handleFailure schedules a retry with exponential backoff, or fails the vote
+// once it has used up its retries. It returns 1 so callers can count failures.
+func (sm *SyncManager) handleFailure(pv PendingVote, err error) int {
+	log.Printf("Failed to sync vote %d (attempt %d/%d): %v", pv.ID, pv.Attempts, sm.maxRetries+1, err)
 
-	// Remove indices in reverse order to maintain index validity
-	for i := len(successfulIndices) - 1; i >= 0; i-- {
-		index := successfulIndices[i]
-		if index < len(sm.pendingVotes) {
-			sm.pendingVotes = append(sm.pendingVotes[:index], sm.pendingVotes[index+1:]...)
+	queue := sm.getQueue()
+	if pv.Attempts > sm.maxRetries {
+		if markErr := queue.MarkFailed(pv.ID, err.Error()); markErr != nil {
+			log.Printf("Failed to mark vote %d as failed: %v", pv.ID, markErr)
 		}
+		if sm.onVoteFailed != nil {
+			sm.onVoteFailed(pv.Vote, err)
+		}
+		return 1
 	}
+
+	nextRetry := time.Now().UTC().Add(sm.backoff(pv.Attempts))
+	if markErr := queue.MarkRetry(pv.ID, err.Error(), nextRetry); markErr != nil {
+		log.Printf("Failed to schedule retry for vote %d: %v", pv.ID, markErr)
+	}
+	return 1
+}
+
+// backoff returns the retry delay after the given number of attempts,
+// doubling retryInterval each time up to maxBackoff
+func (sm *SyncManager) backoff(attempts int) time.Duration {
+	delay := sm.retryInterval
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= sm.maxBackoff {
+			return sm.maxBackoff
+		}
+	}
+	if delay > sm.maxBackoff {
+		return sm.maxBackoff
+	}
+	return delay
+}
+
+// removeInflight stops tracking a submitted vote. It reports whether the vote
+// was still tracked, so that only one caller settles it.
+func (sm *SyncManager) removeInflight(id int64) bool {
+	sm.inflightMutex.Lock()
+	defer sm.inflightMutex.Unlock()
+
+	if _, ok := sm.inflight[id]; !ok {
+		return false
+	}
+	delete(sm.inflight, id)
+	return true
 }
 
 // ClearPendingVotes clears all pending votes (use with caution)
 func (sm *SyncManager) ClearPendingVotes() int {
-	sm.mutex.Lock()
-	defer sm.mutex.Unlock()
+	count, err := sm.getQueue().Clear()
+	if err != nil {
+		log.Printf("Failed to clear pending votes: %v", err)
+		return 0
+	}
 
-	count := len(sm.pendingVotes)
-	sm.pendingVotes = make([]VoteData, 0)
+	sm.inflightMutex.Lock()
+	sm.inflight = make(map[int64]*inflightVote)
+	sm.inflightMutex.Unlock()
 
 	log.Printf("Cleared %d pending votes", count)
 	return count
 }
 
-// GetPendingVotes returns a copy of the pending votes slice
+// GetPendingVotes returns a copy of the pending votes
 func (sm *SyncManager) GetPendingVotes() []VoteData {
-	sm.mutex.RLock()
-	defer sm.mutex.RUnlock()
+	pending, err := sm.getQueue().List()
+	if err != nil {
+		log.Printf("Failed to list pending votes: %v", err)
+		return []VoteData{}
+	}
 
-	pendingCopy := make([]VoteData, len(sm.pendingVotes))
-	copy(pendingCopy, sm.pendingVotes)
+	pendingCopy := make([]VoteData, 0, len(pending))
+	for _, pv := range pending {
+		pendingCopy = append(pendingCopy, pv.Vote)
+	}
 
 	return pendingCopy
 }
 
+// getQueue returns the configured vote queue
+func (sm *SyncManager) getQueue() VoteQueue {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	return sm.queue
+}
+
 // EventMonitor monitors blockchain events
 type EventMonitor struct {
 	client     *BlockchainClient
@@ -454,8 +697,12 @@ func (cm *ConnectionManager) monitorConnection() {
 	}
 }
 
-// IsConnected returns the current connection status
+// IsConnected returns the current connection status. A nil manager, as on a
+// server started without a blockchain, is never connected.
 func (cm *ConnectionManager) IsConnected() bool {
+	if cm == nil {
+		return false
+	}
 	_, err := cm.client.GetBlockNumber()
 	return err == nil
 }
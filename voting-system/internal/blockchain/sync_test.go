@@ -0,0 +1,296 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChain stands in for the blockchain client. Cast transactions are mined
+// as soon as mine is set.
+type fakeChain struct {
+	castErr error
+	mine    bool
+	nonce   uint64
+	casts   int
+	sent    map[common.Hash]bool
+	mutex   sync.Mutex
+}
+
+func newFakeChain() *fakeChain {
+	return &fakeChain{sent: make(map[common.Hash]bool)}
+}
+
+func (c *fakeChain) HasVoterVoted(electionID *big.Int, verificationHash string) (bool, error) {
+	return false, nil
+}
+
+func (c *fakeChain) CastVote(voteData VoteData) (*types.Transaction, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.casts++
+	if c.castErr != nil {
+		return nil, c.castErr
+	}
+	tx := types.NewTx(&types.LegacyTx{Nonce: c.nonce})
+	c.nonce++
+	c.sent[tx.Hash()] = true
+	return tx, nil
+}
+
+func (c *fakeChain) GetPendingReceipt(txHash common.Hash) (*types.Receipt, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.sent[txHash] {
+		return nil, fmt.Errorf("unknown transaction %s", txHash.Hex())
+	}
+	if !c.mine {
+		return nil, nil
+	}
+	return &types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: txHash}, nil
+}
+
+func (c *fakeChain) GetCurrentElectionID() (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (c *fakeChain) setMining(mine bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.mine = mine
+}
+
+func (c *fakeChain) castCount() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.casts
+}
+
+// countingQueue is an in-memory queue that counts how often each vote is settled
+type countingQueue struct {
+	*MemoryVoteQueue
+	synced map[int64]int
+	failed map[int64]int
+	mutex  sync.Mutex
+}
+
+func newCountingQueue() *countingQueue {
+	return &countingQueue{
+		MemoryVoteQueue: NewMemoryVoteQueue(),
+		synced:          make(map[int64]int),
+		failed:          make(map[int64]int),
+	}
+}
+
+func (q *countingQueue) MarkSynced(id int64, txHash string) error {
+	q.mutex.Lock()
+	q.synced[id]++
+	q.mutex.Unlock()
+	return q.MemoryVoteQueue.MarkSynced(id, txHash)
+}
+
+func (q *countingQueue) MarkFailed(id int64, lastError string) error {
+	q.mutex.Lock()
+	q.failed[id]++
+	q.mutex.Unlock()
+	return q.MemoryVoteQueue.MarkFailed(id, lastError)
+}
+
+func newTestSyncManager(t *testing.T, chain *fakeChain, opts SyncOptions, votes int) (*SyncManager, *countingQueue) {
+	t.Helper()
+
+	sm := NewSyncManager(nil, time.Minute)
+	sm.client = chain
+	sm.Configure(opts)
+	queue := newCountingQueue()
+	sm.SetVoteQueue(queue)
+	for i := 0; i < votes; i++ {
+		require.NoError(t, sm.AddPendingVote(VoteData{
+			VerificationHash: fmt.Sprintf("hash-%d", i),
+			PollingUnitID:    "PU-1",
+			CandidateID:      "APC",
+			ElectionID:       "1",
+		}))
+	}
+	return sm, queue
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestSyncManagerSubmitsThroughWorkers(t *testing.T) {
+	chain := newFakeChain()
+	sm, queue := newTestSyncManager(t, chain, SyncOptions{Workers: 4}, 10)
+
+	// The first pass submits without waiting for the votes to be mined
+	synced, failed, err := sm.SyncNow()
+	require.NoError(t, err)
+	assert.Zero(t, synced)
+	assert.Zero(t, failed)
+	assert.Equal(t, 10, chain.castCount())
+	assert.Equal(t, 10, sm.GetInflightCount())
+	assert.Equal(t, 10, sm.GetPendingVoteCount())
+
+	// The next pass settles the mined transactions
+	chain.setMining(true)
+	synced, failed, err = sm.SyncNow()
+	require.NoError(t, err)
+	assert.Equal(t, 10, synced)
+	assert.Zero(t, failed)
+	assert.Zero(t, sm.GetInflightCount())
+	assert.Zero(t, sm.GetPendingVoteCount())
+	assert.Len(t, queue.synced, 10)
+	for id, n := range queue.synced {
+		assert.Equal(t, 1, n, "vote %d", id)
+	}
+	assert.Equal(t, 10, chain.castCount())
+}
+
+func TestSyncManagerFailsAfterMaxRetries(t *testing.T) {
+	chain := newFakeChain()
+	chain.castErr = errors.New("execution reverted")
+	sm, queue := newTestSyncManager(t, chain, SyncOptions{
+		RetryInterval: time.Nanosecond,
+		MaxBackoff:    time.Nanosecond,
+		MaxRetries:    intPtr(2),
+	}, 1)
+	var failedVotes []VoteData
+	sm.SetCallbacks(nil, func(vote VoteData, err error) { failedVotes = append(failedVotes, vote) }, nil)
+
+	for pass := 1; pass <= 3; pass++ {
+		time.Sleep(time.Millisecond)
+		_, failed, err := sm.SyncNow()
+		require.NoError(t, err)
+		assert.Equal(t, 1, failed, "pass %d", pass)
+		if pass < 3 {
+			assert.Equal(t, 1, sm.GetPendingVoteCount(), "pass %d", pass)
+			assert.Empty(t, queue.failed, "pass %d", pass)
+		}
+	}
+
+	assert.Equal(t, 3, chain.castCount())
+	assert.Zero(t, sm.GetPendingVoteCount())
+	assert.Equal(t, map[int64]int{1: 1}, queue.failed)
+	require.Len(t, failedVotes, 1)
+	assert.Equal(t, "hash-0", failedVotes[0].VerificationHash)
+
+	// Nothing is left to retry
+	time.Sleep(time.Millisecond)
+	_, failed, err := sm.SyncNow()
+	require.NoError(t, err)
+	assert.Zero(t, failed)
+	assert.Equal(t, 3, chain.castCount())
+}
+
+func TestSyncManagerWithoutRetries(t *testing.T) {
+	chain := newFakeChain()
+	chain.castErr = errors.New("execution reverted")
+	sm, queue := newTestSyncManager(t, chain, SyncOptions{MaxRetries: intPtr(0)}, 1)
+
+	_, failed, err := sm.SyncNow()
+	require.NoError(t, err)
+	assert.Equal(t, 1, failed)
+	assert.Equal(t, 1, chain.castCount())
+	assert.Equal(t, map[int64]int{1: 1}, queue.failed)
+
+	// Options without MaxRetries keep the current setting
+	sm.Configure(SyncOptions{Workers: 2})
+	assert.Zero(t, sm.maxRetries)
+}
+
+func TestSyncManagerBackoff(t *testing.T) {
+	sm := NewSyncManager(nil, time.Minute)
+	sm.Configure(SyncOptions{RetryInterval: time.Second, MaxBackoff: 10 * time.Second})
+
+	cases := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second},
+		{60, 10 * time.Second},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, sm.backoff(tc.attempts), "attempts %d", tc.attempts)
+	}
+
+	// A base interval above the cap is capped too
+	sm.Configure(SyncOptions{RetryInterval: time.Minute})
+	assert.Equal(t, 10*time.Second, sm.backoff(1))
+}
+
+func TestSyncManagerReceiptTimeout(t *testing.T) {
+	chain := newFakeChain()
+	sm, queue := newTestSyncManager(t, chain, SyncOptions{
+		RetryInterval:  time.Hour,
+		MaxBackoff:     time.Hour,
+		ReceiptTimeout: time.Nanosecond,
+	}, 1)
+
+	_, _, err := sm.SyncNow()
+	require.NoError(t, err)
+	require.Equal(t, 1, sm.GetInflightCount())
+
+	time.Sleep(time.Millisecond)
+	synced, failed := sm.checkReceipts()
+	assert.Zero(t, synced)
+	assert.Equal(t, 1, failed)
+	assert.Zero(t, sm.GetInflightCount())
+	assert.Empty(t, queue.failed)
+
+	// The vote goes back to the queue for a later attempt
+	pending, err := queue.List()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, QueueStatusPending, pending[0].Status)
+	assert.Contains(t, pending[0].LastError, "not mined")
+	assert.True(t, pending[0].NextRetryAt.After(time.Now().Add(30*time.Minute)))
+}
+
+func TestSyncManagerSettlesReceiptsOnce(t *testing.T) {
+	chain := newFakeChain()
+	sm, queue := newTestSyncManager(t, chain, SyncOptions{Workers: 4}, 20)
+
+	_, _, err := sm.SyncNow()
+	require.NoError(t, err)
+	require.Equal(t, 20, sm.GetInflightCount())
+	chain.setMining(true)
+
+	// The receipt loop and sync passes check receipts concurrently
+	var wg sync.WaitGroup
+	var total int
+	var totalMutex sync.Mutex
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			synced, _ := sm.checkReceipts()
+			totalMutex.Lock()
+			total += synced
+			totalMutex.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 20, total)
+	assert.Len(t, queue.synced, 20)
+	for id, n := range queue.synced {
+		assert.Equal(t, 1, n, "vote %d", id)
+	}
+}
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"database/sql"
+	"strconv"
+)
+
+// DB wraps a connection so repositories can write queries once, with ?
+// placeholders, and run them on either dialect
+type DB struct {
+	*sql.DB
+	dialect Dialect
+}
+
+// Wrap returns a dialect-aware handle for db. Connections from unknown
+// drivers are treated as SQLite and passed through unchanged.
+func Wrap(db *sql.DB) *DB {
+	dialect, err := DetectDialect(db)
+	if err != nil {
+		dialect = DialectSQLite
+	}
+	return &DB{DB: db, dialect: dialect}
+}
+
+// Dialect returns the SQL flavour of the connection
+func (db *DB) Dialect() Dialect {
+	return db.dialect
+}
+
+// Exec rebinds and executes a statement
+func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.DB.Exec(db.dialect.Rebind(query), args...)
+}
+
+// Query rebinds and runs a query
+func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.DB.Query(db.dialect.Rebind(query), args...)
+}
+
+// QueryRow rebinds and runs a single-row query
+func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.DB.QueryRow(db.dialect.Rebind(query), args...)
+}
+
+// Begin starts a transaction whose statements are rebound like the DB's
+func (db *DB) Begin() (*Tx, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, dialect: db.dialect}, nil
+}
+
+// InsertReturningID runs an INSERT and returns the id of the new row.
+// Postgres has no LastInsertId, so the id is read back with RETURNING.
+func (db *DB) InsertReturningID(query string, args ...interface{}) (int64, error) {
+	if db.dialect == DialectPostgres {
+		var id int64
+		err := db.QueryRow(query+" RETURNING id", args...).Scan(&id)
+		return id, err
+	}
+
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// Tx is a transaction on a DB
+type Tx struct {
+	*sql.Tx
+	dialect Dialect
+}
+
+// Exec rebinds and executes a statement
+func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return tx.Tx.Exec(tx.dialect.Rebind(query), args...)
+}
+
+// Query rebinds and runs a query
+func (tx *Tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.Tx.Query(tx.dialect.Rebind(query), args...)
+}
+
+// QueryRow rebinds and runs a single-row query
+func (tx *Tx) QueryRow(query string, args ...interface{}) *sql.Row {
+	return tx.Tx.QueryRow(tx.dialect.Rebind(query), args...)
+}
+
+// InsertReturningID runs an INSERT in the transaction and returns the id of
+// the new row
+func (tx *Tx) InsertReturningID(query string, args ...interface{}) (int64, error) {
+	if tx.dialect == DialectPostgres {
+		var id int64
+		err := tx.QueryRow(query+" RETURNING id", args...).Scan(&id)
+		return id, err
+	}
+
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// Rebind converts ? placeholders to $n for postgres. Queries must not
+// contain a literal ? inside string constants.
+func (d Dialect) Rebind(query string) string {
+	if d != DialectPostgres {
+		return query
+	}
+
+	out := make([]byte, 0, len(query)+8)
+	n := 0
+	for i := 0; i < len(query); i++ {
+		if query[i] == '?' {
+			n++
+			out = append(out, '$')
+			out = strconv.AppendInt(out, int64(n), 10)
+			continue
+		}
+		out = append(out, query[i])
+	}
+	return string(out)
+}
+
+// Date returns an expression that formats a timestamp column as YYYY-MM-DD
+func (d Dialect) Date(expr string) string {
+	if d == DialectPostgres {
+		return "TO_CHAR(" + expr + ", 'YYYY-MM-DD')"
+	}
+	return "DATE(" + expr + ")"
+}
@@ -0,0 +1,38 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialectRebindAndDate(t *testing.T) {
+	query := "SELECT id FROM votes WHERE election_id = ? AND status = ?"
+	assert.Equal(t, query, DialectSQLite.Rebind(query))
+	assert.Equal(t, "SELECT id FROM votes WHERE election_id = $1 AND status = $2", DialectPostgres.Rebind(query))
+
+	assert.Equal(t, "DATE(created_at)", DialectSQLite.Date("created_at"))
+	assert.Equal(t, "TO_CHAR(created_at, 'YYYY-MM-DD')", DialectPostgres.Date("created_at"))
+}
+
+func TestWrapInsertReturningID(t *testing.T) {
+	db := Wrap(newTestDB(t))
+	assert.Equal(t, DialectSQLite, db.Dialect())
+
+	_, err := db.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)")
+	require.NoError(t, err)
+
+	first, err := db.InsertReturningID("INSERT INTO items (name) VALUES (?)", "a")
+	require.NoError(t, err)
+	second, err := db.InsertReturningID("INSERT INTO items (name) VALUES (?)", "b")
+	require.NoError(t, err)
+	assert.Equal(t, first+1, second)
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	var name string
+	require.NoError(t, tx.QueryRow("SELECT name FROM items WHERE id = ?", second).Scan(&name))
+	require.NoError(t, tx.Commit())
+	assert.Equal(t, "b", name)
+}
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	sqlite3 "github.com/mattn/go-sqlite3"
+)
+
+// Dialect identifies the SQL flavour of a database connection
+type Dialect string
+
+const (
+	DialectSQLite   Dialect = "sqlite"
+	DialectPostgres Dialect = "postgres"
+)
+
+// DialectFor maps a DatabaseConfig.Type to its dialect
+func DialectFor(dbType string) (Dialect, error) {
+	switch dbType {
+	case "sqlite":
+		return DialectSQLite, nil
+	case "postgres":
+		return DialectPostgres, nil
+	default:
+		return "", fmt.Errorf("unsupported database type: %s", dbType)
+	}
+}
+
+// DetectDialect returns the dialect of an open connection from its driver
+func DetectDialect(db *sql.DB) (Dialect, error) {
+	switch db.Driver().(type) {
+	case *sqlite3.SQLiteDriver:
+		return DialectSQLite, nil
+	case *pq.Driver:
+		return DialectPostgres, nil
+	default:
+		return "", fmt.Errorf("unsupported database driver %T", db.Driver())
+	}
+}
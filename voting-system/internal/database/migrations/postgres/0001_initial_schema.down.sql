@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS candidates;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS system_logs;
+DROP TABLE IF EXISTS votes;
+DROP TABLE IF EXISTS polling_units;
+DROP TABLE IF EXISTS elections;
+DROP TABLE IF EXISTS voters;
+DROP TABLE IF EXISTS terminals;
+DROP TABLE IF EXISTS audit_logs;
@@ -0,0 +1,18 @@
+-- Durable queue of votes waiting to be synced to the blockchain
+CREATE TABLE IF NOT EXISTS pending_votes (
+    id BIGSERIAL PRIMARY KEY,
+    verification_hash VARCHAR(64) UNIQUE NOT NULL,
+    encrypted_vote TEXT,
+    polling_unit_id VARCHAR(50),
+    candidate_id VARCHAR(50),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_retry_at TIMESTAMP NOT NULL,
+    tx_hash VARCHAR(66) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_votes_status ON pending_votes(status);
+CREATE INDEX IF NOT EXISTS idx_pending_votes_next_retry ON pending_votes(status, next_retry_at);
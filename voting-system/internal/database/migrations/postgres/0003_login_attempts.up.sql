@@ -0,0 +1,10 @@
+-- Login lockout tracking and session lookups
+CREATE TABLE IF NOT EXISTS login_attempts (
+    username VARCHAR(50) PRIMARY KEY,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP,
+    last_failed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions(expires_at);
@@ -0,0 +1,26 @@
+-- Encrypted ballots and homomorphic tallies
+ALTER TABLE votes ADD COLUMN ballot TEXT;
+ALTER TABLE pending_votes ADD COLUMN election_id VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE pending_votes ADD COLUMN ballot TEXT NOT NULL DEFAULT '';
+
+-- Election encryption keys, keyed by blockchain election ID
+CREATE TABLE IF NOT EXISTS election_keys (
+    election_id VARCHAR(100) PRIMARY KEY,
+    public_key TEXT NOT NULL,
+    threshold INTEGER NOT NULL,
+    candidates TEXT NOT NULL,
+    verification_keys TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    created_by VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    closed_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS election_tallies (
+    election_id VARCHAR(100) PRIMARY KEY,
+    ballots INTEGER NOT NULL,
+    encrypted_tally TEXT NOT NULL,
+    results TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    decrypted_at TIMESTAMP
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_terminal_journal_hash;
+DROP TABLE IF EXISTS terminal_journal;
@@ -0,0 +1,10 @@
+-- Nonces of terminal-signed votes, kept while their timestamps are still accepted
+CREATE TABLE IF NOT EXISTS vote_nonces (
+    device_id VARCHAR(50) NOT NULL,
+    nonce VARCHAR(64) NOT NULL,
+    signed_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (device_id, nonce)
+);
+
+CREATE INDEX IF NOT EXISTS idx_vote_nonces_signed_at ON vote_nonces(signed_at);
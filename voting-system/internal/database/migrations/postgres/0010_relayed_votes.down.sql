@@ -0,0 +1,4 @@
+ALTER TABLE pending_votes DROP COLUMN terminal_signature;
+ALTER TABLE pending_votes DROP COLUMN signed_at;
+ALTER TABLE pending_votes DROP COLUMN vote_nonce;
+ALTER TABLE pending_votes DROP COLUMN terminal_address;
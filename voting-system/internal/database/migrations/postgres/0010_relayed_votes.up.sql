@@ -0,0 +1,5 @@
+-- Terminal signatures of queued votes, relayed to the contract when they sync
+ALTER TABLE pending_votes ADD COLUMN terminal_address VARCHAR(42) NOT NULL DEFAULT '';
+ALTER TABLE pending_votes ADD COLUMN vote_nonce VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE pending_votes ADD COLUMN signed_at BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE pending_votes ADD COLUMN terminal_signature VARCHAR(132) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_election_voters_polling_unit;
+DROP TABLE IF EXISTS election_voters;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_election_roll_nodes_hash;
+DROP TABLE IF EXISTS election_roll_nodes;
+DROP TABLE IF EXISTS election_rolls;
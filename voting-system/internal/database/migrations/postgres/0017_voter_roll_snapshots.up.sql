@@ -0,0 +1,22 @@
+-- The voter roll snapshot taken when an election starts. Its Merkle root is
+-- published on chain; leaves are hashes of voters' verification hashes.
+CREATE TABLE IF NOT EXISTS election_rolls (
+    election_id VARCHAR(50) PRIMARY KEY,
+    merkle_root VARCHAR(66) NOT NULL,
+    voter_count INTEGER NOT NULL,
+    tx_hash VARCHAR(66),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP
+);
+
+-- Every node of each roll's tree, leaves at level 0, so proofs are read
+-- without rebuilding the tree
+CREATE TABLE IF NOT EXISTS election_roll_nodes (
+    election_id VARCHAR(50) NOT NULL,
+    level INTEGER NOT NULL,
+    idx INTEGER NOT NULL,
+    hash VARCHAR(66) NOT NULL,
+    PRIMARY KEY (election_id, level, idx)
+);
+
+CREATE INDEX IF NOT EXISTS idx_election_roll_nodes_hash ON election_roll_nodes(election_id, hash);
@@ -0,0 +1,28 @@
+-- Bulk voter imports, run in the background and polled by ID
+CREATE TABLE IF NOT EXISTS voter_import_jobs (
+    id VARCHAR(64) PRIMARY KEY,
+    format VARCHAR(10) NOT NULL,
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(20) NOT NULL,
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    processed_rows INTEGER NOT NULL DEFAULT 0,
+    imported_rows INTEGER NOT NULL DEFAULT 0,
+    failed_rows INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_voter_import_jobs_status ON voter_import_jobs(status);
+
+-- The rows of each import that could not be imported, by line
+CREATE TABLE IF NOT EXISTS voter_import_errors (
+    job_id VARCHAR(64) NOT NULL,
+    line INTEGER NOT NULL,
+    nin VARCHAR(11) NOT NULL DEFAULT '',
+    code VARCHAR(50) NOT NULL,
+    message TEXT NOT NULL,
+    PRIMARY KEY (job_id, line),
+    FOREIGN KEY (job_id) REFERENCES voter_import_jobs(id)
+);
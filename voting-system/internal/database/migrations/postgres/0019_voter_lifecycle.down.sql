@@ -0,0 +1,2 @@
+ALTER TABLE voters DROP COLUMN deactivated_at;
+ALTER TABLE voters DROP COLUMN deactivation_reason;
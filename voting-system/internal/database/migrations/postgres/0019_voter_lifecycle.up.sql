@@ -0,0 +1,4 @@
+-- Why and when a voter was deactivated. Deactivated voters keep their
+-- record, so their NIN and fingerprint cannot be registered again.
+ALTER TABLE voters ADD COLUMN deactivation_reason VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE voters ADD COLUMN deactivated_at TIMESTAMP;
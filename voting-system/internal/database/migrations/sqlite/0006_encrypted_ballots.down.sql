@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS election_tallies;
+DROP TABLE IF EXISTS election_keys;
+ALTER TABLE pending_votes DROP COLUMN ballot;
+ALTER TABLE pending_votes DROP COLUMN election_id;
+ALTER TABLE votes DROP COLUMN ballot;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS decryption_shares;
+DROP TABLE IF EXISTS ceremony_trustees;
+DROP TABLE IF EXISTS key_ceremonies;
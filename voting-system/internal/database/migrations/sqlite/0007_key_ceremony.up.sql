@@ -0,0 +1,35 @@
+-- Threshold key ceremonies, keyed by blockchain election ID
+CREATE TABLE IF NOT EXISTS key_ceremonies (
+    election_id VARCHAR(100) PRIMARY KEY,
+    threshold INTEGER NOT NULL,
+    trustees INTEGER NOT NULL,
+    commitments TEXT NOT NULL,
+    reused_from VARCHAR(100),
+    created_by VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+-- Trustee shares are sealed until the trustee collects them, then erased
+CREATE TABLE IF NOT EXISTS ceremony_trustees (
+    election_id VARCHAR(100) NOT NULL,
+    trustee_index INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    sealed_share TEXT,
+    collected_at TIMESTAMP,
+    PRIMARY KEY (election_id, trustee_index),
+    UNIQUE (election_id, user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+-- Partial decryptions submitted by trustees after the election ends
+CREATE TABLE IF NOT EXISTS decryption_shares (
+    election_id VARCHAR(100) NOT NULL,
+    trustee_index INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    share TEXT NOT NULL,
+    submitted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (election_id, trustee_index)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ceremony_trustees_user ON ceremony_trustees(user_id);
@@ -0,0 +1,21 @@
+-- Vote journals uploaded by terminals after running offline. One row per
+-- chain link; (device_id, seq) makes re-uploads idempotent.
+CREATE TABLE IF NOT EXISTS terminal_journal (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    device_id VARCHAR(50) NOT NULL,
+    seq INTEGER NOT NULL,
+    prev_hash VARCHAR(64) NOT NULL,
+    entry_hash VARCHAR(64) NOT NULL,
+    signature VARCHAR(64),
+    verification_hash VARCHAR(64) NOT NULL,
+    polling_unit_id VARCHAR(50),
+    status VARCHAR(20) NOT NULL,
+    response_code INTEGER,
+    error TEXT,
+    transaction_hash VARCHAR(66),
+    recorded_at TIMESTAMP NOT NULL,
+    received_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (device_id, seq)
+);
+
+CREATE INDEX IF NOT EXISTS idx_terminal_journal_hash ON terminal_journal(verification_hash);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_vote_nonces_signed_at;
+DROP TABLE IF EXISTS vote_nonces;
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_terminal_alerts_raised;
+DROP INDEX IF EXISTS idx_terminal_alerts_device;
+DROP TABLE IF EXISTS terminal_alerts;
+DROP TABLE IF EXISTS terminal_configs;
+DROP INDEX IF EXISTS idx_votes_terminal;
+ALTER TABLE votes DROP COLUMN terminal_id;
+ALTER TABLE terminals DROP COLUMN clock_skew_ms;
+ALTER TABLE terminals DROP COLUMN queue_depth;
+ALTER TABLE terminals DROP COLUMN firmware_version;
+ALTER TABLE terminals DROP COLUMN charging;
+ALTER TABLE terminals DROP COLUMN battery_level;
+ALTER TABLE terminals DROP COLUMN online;
@@ -0,0 +1,32 @@
+-- Terminal health as of the last heartbeat
+ALTER TABLE terminals ADD COLUMN online BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE terminals ADD COLUMN battery_level INTEGER;
+ALTER TABLE terminals ADD COLUMN charging BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE terminals ADD COLUMN firmware_version VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE terminals ADD COLUMN queue_depth INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE terminals ADD COLUMN clock_skew_ms BIGINT NOT NULL DEFAULT 0;
+
+-- The terminal that submitted each vote
+ALTER TABLE votes ADD COLUMN terminal_id VARCHAR(50);
+CREATE INDEX IF NOT EXISTS idx_votes_terminal ON votes(terminal_id, created_at);
+
+-- Per-terminal overrides of the remote configuration document
+CREATE TABLE IF NOT EXISTS terminal_configs (
+    device_id VARCHAR(50) PRIMARY KEY,
+    settings TEXT NOT NULL,
+    updated_by VARCHAR(255),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Fleet alerts. At most one alert of a kind is open per terminal.
+CREATE TABLE IF NOT EXISTS terminal_alerts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    device_id VARCHAR(50) NOT NULL,
+    kind VARCHAR(30) NOT NULL,
+    message TEXT NOT NULL,
+    raised_at TIMESTAMP NOT NULL,
+    resolved_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_terminal_alerts_device ON terminal_alerts(device_id, kind, resolved_at);
+CREATE INDEX IF NOT EXISTS idx_terminal_alerts_raised ON terminal_alerts(raised_at);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_terminal_enrolment_codes_unit;
+DROP TABLE IF EXISTS terminal_enrolment_codes;
+ALTER TABLE terminals DROP COLUMN approved_at;
+ALTER TABLE terminals DROP COLUMN approved_by;
+UPDATE terminals SET status = 'authorized' WHERE status = 'active';
+UPDATE terminals SET status = 'deauthorized' WHERE status = 'revoked';
+UPDATE terminals SET status = 'registered' WHERE status IN ('pending', 'approved');
@@ -0,0 +1,21 @@
+-- Terminals move through pending, approved, active and revoked
+UPDATE terminals SET status = 'active' WHERE authorized = TRUE AND status <> 'decommissioned';
+UPDATE terminals SET status = 'revoked' WHERE status = 'deauthorized';
+UPDATE terminals SET status = 'pending' WHERE status NOT IN ('active', 'revoked', 'decommissioned');
+
+ALTER TABLE terminals ADD COLUMN approved_by VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE terminals ADD COLUMN approved_at TIMESTAMP;
+
+-- One-time enrolment codes, stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS terminal_enrolment_codes (
+    code_hash VARCHAR(64) PRIMARY KEY,
+    polling_unit_id VARCHAR(50) NOT NULL,
+    terminal_id VARCHAR(50) NOT NULL DEFAULT '',
+    issued_by VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    used_by VARCHAR(50) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_terminal_enrolment_codes_unit ON terminal_enrolment_codes(polling_unit_id, created_at);
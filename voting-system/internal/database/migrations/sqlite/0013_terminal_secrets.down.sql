@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_terminal_token_nonces_signed_at;
+DROP TABLE IF EXISTS terminal_token_nonces;
+DROP INDEX IF EXISTS idx_terminal_secrets_terminal;
+DROP TABLE IF EXISTS terminal_secrets;
@@ -0,0 +1,25 @@
+-- Per-terminal HMAC secrets, sealed with the server's encryption key. A
+-- rotated secret stays valid until expires_at; a revoked one never again.
+CREATE TABLE IF NOT EXISTS terminal_secrets (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    terminal_id VARCHAR(50) NOT NULL,
+    secret_sealed TEXT NOT NULL,
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    FOREIGN KEY (terminal_id) REFERENCES terminals(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_terminal_secrets_terminal ON terminal_secrets(terminal_id);
+
+-- Nonces of terminal token requests, kept while their timestamps are still accepted
+CREATE TABLE IF NOT EXISTS terminal_token_nonces (
+    device_id VARCHAR(50) NOT NULL,
+    nonce VARCHAR(64) NOT NULL,
+    signed_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (device_id, nonce)
+);
+
+CREATE INDEX IF NOT EXISTS idx_terminal_token_nonces_signed_at ON terminal_token_nonces(signed_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS biometric_attempts;
+DROP INDEX IF EXISTS idx_voter_templates_format;
+DROP TABLE IF EXISTS voter_templates;
@@ -0,0 +1,21 @@
+-- Enrolled fingerprint templates, one per voter. Voters registered before
+-- templates were kept have none and verify against their fingerprint hash.
+CREATE TABLE IF NOT EXISTS voter_templates (
+    nin VARCHAR(11) PRIMARY KEY,
+    format VARCHAR(20) NOT NULL,
+    template TEXT NOT NULL,
+    quality REAL NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (nin) REFERENCES voters(nin)
+);
+
+CREATE INDEX IF NOT EXISTS idx_voter_templates_format ON voter_templates(format);
+
+-- Failed fingerprint matches per voter, for the biometric lockout
+CREATE TABLE IF NOT EXISTS biometric_attempts (
+    nin VARCHAR(11) PRIMARY KEY,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP,
+    last_failed_at TIMESTAMP
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS election_salts;
+UPDATE voter_templates SET format = 'exact' WHERE format = 'sha256';
+ALTER TABLE voter_templates DROP COLUMN sealed;
@@ -0,0 +1,14 @@
+-- Templates are sealed with the server's encryption key. Rows enrolled before
+-- the vault are sealed at startup.
+ALTER TABLE voter_templates ADD COLUMN sealed BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Exact templates so far are unkeyed SHA-256 hashes of the capture
+UPDATE voter_templates SET format = 'sha256' WHERE format = 'exact';
+
+-- Each election's secret salt for verification hashes, sealed with the
+-- server's encryption key
+CREATE TABLE IF NOT EXISTS election_salts (
+    election_id VARCHAR(50) PRIMARY KEY,
+    salt_sealed TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
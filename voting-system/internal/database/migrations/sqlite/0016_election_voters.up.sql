@@ -0,0 +1,21 @@
+-- The voter roll of each election. Voters are eligible, and their has-voted
+-- state tracked, per election rather than once for all elections.
+CREATE TABLE IF NOT EXISTS election_voters (
+    election_id VARCHAR(50) NOT NULL,
+    nin VARCHAR(11) NOT NULL,
+    polling_unit_id VARCHAR(50),
+    added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (election_id, nin)
+);
+
+CREATE INDEX IF NOT EXISTS idx_election_voters_polling_unit ON election_voters(election_id, polling_unit_id);
+
+-- Existing elections take the active voters registered before they ended
+INSERT INTO election_voters (election_id, nin, polling_unit_id, added_at)
+SELECT e.blockchain_id, v.nin, v.polling_unit_id, CURRENT_TIMESTAMP
+FROM elections e
+CROSS JOIN voters v
+WHERE e.blockchain_id IS NOT NULL
+  AND v.is_active = TRUE
+  AND v.registered_at <= e.end_time
+ON CONFLICT (election_id, nin) DO NOTHING;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS voter_import_errors;
+DROP INDEX IF EXISTS idx_voter_import_jobs_status;
+DROP TABLE IF EXISTS voter_import_jobs;
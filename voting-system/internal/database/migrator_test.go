@@ -0,0 +1,182 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"voting-system/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := NewConnection(&config.DatabaseConfig{
+		Type: "sqlite",
+		Path: filepath.Join(t.TempDir(), "test.db"),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&count)
+	require.NoError(t, err)
+	return count > 0
+}
+
+func TestDialectsDefineTheSameMigrations(t *testing.T) {
+	sqlite, err := LoadMigrations(DialectSQLite)
+	require.NoError(t, err)
+	postgres, err := LoadMigrations(DialectPostgres)
+	require.NoError(t, err)
+
+	require.Equal(t, len(sqlite), len(postgres))
+	for i := range sqlite {
+		assert.Equal(t, sqlite[i].Version, postgres[i].Version)
+		assert.Equal(t, sqlite[i].Name, postgres[i].Name)
+		assert.Equal(t, i+1, sqlite[i].Version, "versions must be contiguous")
+		assert.NotContains(t, postgres[i].Up, "AUTOINCREMENT", "%d_%s", postgres[i].Version, postgres[i].Name)
+	}
+}
+
+func TestMigrateUpDownAndTo(t *testing.T) {
+	db := newTestDB(t)
+	migrator, err := NewMigrator(db, DialectSQLite)
+	require.NoError(t, err)
+	latest := migrator.Latest()
+
+	applied, err := migrator.Up()
+	require.NoError(t, err)
+	assert.Equal(t, latest, applied)
+	assert.True(t, tableExists(t, db, "recovery_codes"))
+
+	// Up is idempotent
+	applied, err = migrator.Up()
+	require.NoError(t, err)
+	assert.Equal(t, 0, applied)
+
+	reverted, err := migrator.Down(1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reverted)
+	version, err := migrator.Version()
+	require.NoError(t, err)
+	assert.Equal(t, latest-1, version)
+
+	_, err = migrator.To(4)
+	require.NoError(t, err)
+	assert.False(t, tableExists(t, db, "recovery_codes"))
+
+	_, err = migrator.To(0)
+	require.NoError(t, err)
+	assert.False(t, tableExists(t, db, "users"))
+	assert.True(t, tableExists(t, db, "schema_migrations"))
+
+	_, err = migrator.To(2)
+	require.NoError(t, err)
+	assert.True(t, tableExists(t, db, "pending_votes"))
+	assert.False(t, tableExists(t, db, "login_attempts"))
+
+	_, err = migrator.To(latest + 1)
+	assert.Error(t, err)
+
+	statuses, err := migrator.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, latest)
+	assert.True(t, statuses[1].Applied)
+	assert.False(t, statuses[2].Applied)
+}
+
+func TestMigrateRejectsModifiedOrUnknownMigrations(t *testing.T) {
+	db := newTestDB(t)
+	require.NoError(t, RunMigrations(db))
+
+	migrator, err := NewMigrator(db, DialectSQLite)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Verify())
+
+	_, err = db.Exec("UPDATE schema_migrations SET checksum = 'tampered' WHERE version = 1")
+	require.NoError(t, err)
+	assert.ErrorContains(t, migrator.Verify(), "checksum mismatch")
+	_, err = migrator.Up()
+	assert.Error(t, err)
+
+	_, err = db.Exec("UPDATE schema_migrations SET checksum = ? WHERE version = 1", migrator.Migrations()[0].Checksum)
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (999, 'future', 'x', CURRENT_TIMESTAMP)")
+	require.NoError(t, err)
+	assert.ErrorContains(t, migrator.Verify(), "does not know")
+}
+
+func TestMigrateAdoptsUnversionedDatabase(t *testing.T) {
+	db := newTestDB(t)
+
+	// A database created before versioned migrations already has the tables
+	_, err := db.Exec(`CREATE TABLE users (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        username VARCHAR(50) UNIQUE NOT NULL,
+        email VARCHAR(255) UNIQUE NOT NULL,
+        password_hash VARCHAR(255) NOT NULL,
+        first_name VARCHAR(100),
+        last_name VARCHAR(100),
+        role VARCHAR(20) DEFAULT 'operator',
+        permissions TEXT,
+        is_active BOOLEAN DEFAULT TRUE,
+        last_login TIMESTAMP,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    )`)
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO users (username, email, password_hash) VALUES ('old', 'old@example.com', 'x')")
+	require.NoError(t, err)
+
+	require.NoError(t, RunMigrations(db))
+
+	var count int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count))
+	assert.Equal(t, 1, count)
+}
+
+func TestMigrateLinksExistingVotersToElections(t *testing.T) {
+	db := newTestDB(t)
+	migrator, err := NewMigrator(db, DialectSQLite)
+	require.NoError(t, err)
+	_, err = migrator.To(15)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`INSERT INTO elections (blockchain_id, name, start_time, end_time) VALUES
+        ('1', 'Past', '2023-02-25 08:00:00', '2023-02-25 18:00:00'),
+        ('2', 'Next', '2027-03-01 08:00:00', '2027-03-01 18:00:00')`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO voters (nin, first_name, last_name, polling_unit_id, fingerprint_hash, registered_at, is_active) VALUES
+        ('12345678901', 'Ada', 'Obi', 'PU-1', 'fp-1', '2022-06-01 10:00:00', TRUE),
+        ('10987654321', 'Bola', 'Ade', 'PU-1', 'fp-2', '2024-06-01 10:00:00', TRUE),
+        ('11111111111', 'Chi', 'Eze', 'PU-2', 'fp-3', '2022-06-01 10:00:00', FALSE)`)
+	require.NoError(t, err)
+
+	_, err = migrator.Up()
+	require.NoError(t, err)
+
+	// Active voters join the rolls of the elections held after they registered
+	roll := func(electionID string) []string {
+		rows, err := db.Query("SELECT nin FROM election_voters WHERE election_id = ? ORDER BY nin", electionID)
+		require.NoError(t, err)
+		defer rows.Close()
+		var nins []string
+		for rows.Next() {
+			var nin string
+			require.NoError(t, rows.Scan(&nin))
+			nins = append(nins, nin)
+		}
+		return nins
+	}
+	assert.Equal(t, []string{"12345678901"}, roll("1"))
+	assert.Equal(t, []string{"10987654321", "12345678901"}, roll("2"))
+}
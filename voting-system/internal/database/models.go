@@ -24,22 +24,101 @@ type Terminal struct {
 	Status        string     `db:"status" json:"status"`
 	Authorized    bool       `db:"authorized" json:"authorized"`
 	LastHeartbeat *time.Time `db:"last_heartbeat" json:"last_heartbeat"`
+	ApprovedBy    string     `db:"approved_by" json:"approved_by,omitempty"`
+	ApprovedAt    *time.Time `db:"approved_at" json:"approved_at,omitempty"`
 	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
 	UpdatedAt     time.Time  `db:"updated_at" json:"updated_at"`
+	TerminalHealth
 }
 
+// Terminal statuses. A terminal enrols as pending, is approved by an admin
+// and becomes active once its address is authorized on chain. Revoked and
+// decommissioned terminals are refused even while the chain still lists them
+// as authorized; a revoked terminal may enrol again with a new code.
+const (
+	TerminalPending        = "pending"
+	TerminalApproved       = "approved"
+	TerminalActive         = "active"
+	TerminalRevoked        = "revoked"
+	TerminalDecommissioned = "decommissioned"
+)
+
+// TerminalEnrolmentCode is a one-time code an admin issues for enrolling a
+// terminal at a polling unit. Only the code's hash is stored.
+type TerminalEnrolmentCode struct {
+	CodeHash      string     `db:"code_hash" json:"-"`
+	PollingUnitID string     `db:"polling_unit_id" json:"polling_unit_id"`
+	TerminalID    string     `db:"terminal_id" json:"terminal_id,omitempty"` // empty if any terminal may use it
+	IssuedBy      string     `db:"issued_by" json:"issued_by"`
+	ExpiresAt     time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt        *time.Time `db:"used_at" json:"used_at,omitempty"`
+	UsedBy        string     `db:"used_by" json:"used_by,omitempty"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+}
+
+// TerminalSecret is a terminal's HMAC secret for requesting tokens and
+// signing its journal, sealed with the server's encryption key
+type TerminalSecret struct {
+	ID           int64      `db:"id" json:"id"`
+	TerminalID   string     `db:"terminal_id" json:"terminal_id"`
+	SecretSealed string     `db:"secret_sealed" json:"-"`
+	CreatedBy    string     `db:"created_by" json:"created_by"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	ExpiresAt    *time.Time `db:"expires_at" json:"expires_at,omitempty"` // set when the secret is rotated out
+	RevokedAt    *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+}
+
+// TerminalHealth is what a terminal reported in its last heartbeat
+type TerminalHealth struct {
+	Online          bool   `db:"online" json:"online"`
+	BatteryLevel    *int   `db:"battery_level" json:"battery_level"` // percent; nil on mains power
+	Charging        bool   `db:"charging" json:"charging"`
+	FirmwareVersion string `db:"firmware_version" json:"firmware_version"`
+	QueueDepth      int    `db:"queue_depth" json:"queue_depth"`     // votes journaled but not yet uploaded
+	ClockSkewMs     int64  `db:"clock_skew_ms" json:"clock_skew_ms"` // terminal clock minus server clock
+}
+
+// TerminalAlert is a fleet alert raised for a terminal
+type TerminalAlert struct {
+	ID         int64      `db:"id" json:"id"`
+	DeviceID   string     `db:"device_id" json:"device_id"`
+	Kind       string     `db:"kind" json:"kind"`
+	Message    string     `db:"message" json:"message"`
+	RaisedAt   time.Time  `db:"raised_at" json:"raised_at"`
+	ResolvedAt *time.Time `db:"resolved_at" json:"resolved_at,omitempty"`
+}
+
+// TerminalSettings holds an admin's overrides of a terminal's remote
+// configuration, as JSON
+type TerminalSettings struct {
+	DeviceID  string    `db:"device_id" json:"device_id"`
+	Settings  string    `db:"settings" json:"settings"`
+	UpdatedBy string    `db:"updated_by" json:"updated_by"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Voter deactivation reasons. A deactivated voter cannot vote or be
+// verified, and their NIN and fingerprint stay taken.
+const (
+	VoterDeceased   = "deceased"
+	VoterDuplicate  = "duplicate"  // registered again under another NIN
+	VoterIneligible = "ineligible" // e.g. not a citizen or under age
+)
+
 // Voter represents a registered voter
 type Voter struct {
-	ID              int64     `db:"id" json:"id"`
-	NIN             string    `db:"nin" json:"nin"`
-	FirstName       string    `db:"first_name" json:"first_name"`
-	LastName        string    `db:"last_name" json:"last_name"`
-	DateOfBirth     time.Time `db:"date_of_birth" json:"date_of_birth"`
-	Gender          string    `db:"gender" json:"gender"`
-	PollingUnitID   string    `db:"polling_unit_id" json:"polling_unit_id"`
-	FingerprintHash string    `db:"fingerprint_hash" json:"fingerprint_hash"`
-	RegisteredAt    time.Time `db:"registered_at" json:"registered_at"`
-	IsActive        bool      `db:"is_active" json:"is_active"`
+	ID                 int64      `db:"id" json:"id"`
+	NIN                string     `db:"nin" json:"nin"`
+	FirstName          string     `db:"first_name" json:"first_name"`
+	LastName           string     `db:"last_name" json:"last_name"`
+	DateOfBirth        time.Time  `db:"date_of_birth" json:"date_of_birth"`
+	Gender             string     `db:"gender" json:"gender"`
+	PollingUnitID      string     `db:"polling_unit_id" json:"polling_unit_id"`
+	FingerprintHash    string     `db:"fingerprint_hash" json:"fingerprint_hash"`
+	RegisteredAt       time.Time  `db:"registered_at" json:"registered_at"`
+	IsActive           bool       `db:"is_active" json:"is_active"`
+	DeactivationReason string     `db:"deactivation_reason" json:"deactivation_reason,omitempty"`
+	DeactivatedAt      *time.Time `db:"deactivated_at" json:"deactivated_at,omitempty"`
 }
 
 // Election represents an election
@@ -63,6 +142,8 @@ type Vote struct {
 	PollingUnitID    string     `db:"polling_unit_id" json:"polling_unit_id"`
 	CandidateID      string     `db:"candidate_id" json:"candidate_id"`
 	EncryptedVote    string     `db:"encrypted_vote" json:"encrypted_vote"`
+	Ballot           *string    `db:"ballot" json:"ballot,omitempty"`           // encrypted ballot for ballot-secrecy elections
+	TerminalID       string     `db:"terminal_id" json:"terminal_id,omitempty"` // terminal that submitted the vote
 	TransactionHash  string     `db:"transaction_hash" json:"transaction_hash"`
 	BlockNumber      int64      `db:"block_number" json:"block_number"`
 	Status           string     `db:"status" json:"status"`
@@ -127,3 +208,217 @@ type Session struct {
 	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 }
+
+// SessionData is the JSON stored in Session.Data for login sessions
+type SessionData struct {
+	RefreshHash  string `json:"refresh_hash"`            // digest of the current refresh secret
+	PreviousHash string `json:"previous_hash,omitempty"` // digest of the last rotated secret, used to detect reuse
+	IPAddress    string `json:"ip_address"`
+	UserAgent    string `json:"user_agent"`
+	LastUsedAt   int64  `json:"last_used_at"`
+
+	// TwoFactorPending restricts the session to 2FA enrolment until the user enables it
+	TwoFactorPending bool `json:"two_factor_pending,omitempty"`
+}
+
+// LoginAttempt tracks failed logins for a username
+type LoginAttempt struct {
+	Username     string     `db:"username" json:"username"`
+	FailedCount  int        `db:"failed_count" json:"failed_count"`
+	LockedUntil  *time.Time `db:"locked_until" json:"locked_until"`
+	LastFailedAt *time.Time `db:"last_failed_at" json:"last_failed_at"`
+}
+
+// VoterTemplate is a voter's enrolled fingerprint template, sealed with the
+// server's encryption key
+type VoterTemplate struct {
+	NIN       string    `db:"nin" json:"nin"`
+	Format    string    `db:"format" json:"format"`
+	Template  string    `db:"template" json:"-"`
+	Quality   float64   `db:"quality" json:"quality"`
+	Sealed    bool      `db:"sealed" json:"-"` // false for templates enrolled before the vault
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// BiometricAttempt tracks failed fingerprint matches for a voter
+type BiometricAttempt struct {
+	NIN          string     `db:"nin" json:"nin"`
+	FailedCount  int        `db:"failed_count" json:"failed_count"`
+	LockedUntil  *time.Time `db:"locked_until" json:"locked_until"`
+	LastFailedAt *time.Time `db:"last_failed_at" json:"last_failed_at"`
+}
+
+// PasswordResetToken is a one-time token allowing a user to set a new password
+type PasswordResetToken struct {
+	TokenHash string     `db:"token_hash" json:"-"`
+	UserID    int64      `db:"user_id" json:"user_id"`
+	CreatedBy string     `db:"created_by" json:"created_by"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time `db:"used_at" json:"used_at"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
+
+// TwoFactor is a user's TOTP enrolment. Secret is sealed with the server key.
+type TwoFactor struct {
+	UserID       int64      `db:"user_id" json:"user_id"`
+	Secret       string     `db:"secret" json:"-"`
+	Enabled      bool       `db:"enabled" json:"enabled"`
+	LastUsedStep int64      `db:"last_used_step" json:"-"`
+	EnabledAt    *time.Time `db:"enabled_at" json:"enabled_at"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+}
+
+// Election key statuses
+const (
+	ElectionKeyOpen      = "open"      // accepting encrypted ballots
+	ElectionKeyClosed    = "closed"    // election ended, awaiting decryption shares
+	ElectionKeyDecrypted = "decrypted" // tally decrypted and results published
+)
+
+// ElectionKey is the ballot encryption key for a ballot-secrecy election.
+// Candidates is the JSON list of candidate IDs in ballot order and
+// VerificationKeys is the JSON list of trustee verification keys, index i+1 at i.
+type ElectionKey struct {
+	ElectionID       string     `db:"election_id" json:"election_id"`
+	PublicKey        string     `db:"public_key" json:"public_key"`
+	Threshold        int        `db:"threshold" json:"threshold"`
+	Candidates       string     `db:"candidates" json:"candidates"`
+	VerificationKeys string     `db:"verification_keys" json:"verification_keys"`
+	Status           string     `db:"status" json:"status"`
+	CreatedBy        string     `db:"created_by" json:"created_by"`
+	CreatedAt        time.Time  `db:"created_at" json:"created_at"`
+	ClosedAt         *time.Time `db:"closed_at" json:"closed_at"`
+}
+
+// ElectionTally is the homomorphic sum of an election's ballots and, once
+// the trustees have decrypted it, the per-candidate results as JSON
+type ElectionTally struct {
+	ElectionID     string     `db:"election_id" json:"election_id"`
+	Ballots        int        `db:"ballots" json:"ballots"`
+	EncryptedTally string     `db:"encrypted_tally" json:"encrypted_tally"`
+	Results        *string    `db:"results" json:"results"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	DecryptedAt    *time.Time `db:"decrypted_at" json:"decrypted_at"`
+}
+
+// KeyCeremony records how an election key was dealt to its trustees.
+// Commitments is the JSON list of Feldman commitments, the public key first.
+type KeyCeremony struct {
+	ElectionID  string     `db:"election_id" json:"election_id"`
+	Threshold   int        `db:"threshold" json:"threshold"`
+	Trustees    int        `db:"trustees" json:"trustees"`
+	Commitments string     `db:"commitments" json:"commitments"`
+	ReusedFrom  *string    `db:"reused_from" json:"reused_from,omitempty"` // election whose key was carried over
+	CreatedBy   string     `db:"created_by" json:"created_by"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	CompletedAt *time.Time `db:"completed_at" json:"completed_at"` // every trustee has collected their share
+}
+
+// CeremonyTrustee is a trustee's seat in a key ceremony. SealedShare holds the
+// trustee's key share, sealed with the server key, until it is collected.
+type CeremonyTrustee struct {
+	ElectionID   string     `db:"election_id" json:"election_id"`
+	TrusteeIndex int        `db:"trustee_index" json:"trustee_index"`
+	UserID       int64      `db:"user_id" json:"user_id"`
+	SealedShare  *string    `db:"sealed_share" json:"-"`
+	CollectedAt  *time.Time `db:"collected_at" json:"collected_at"`
+}
+
+// DecryptionShareRecord is a trustee's partial decryption of an election tally
+type DecryptionShareRecord struct {
+	ElectionID   string    `db:"election_id" json:"election_id"`
+	TrusteeIndex int       `db:"trustee_index" json:"trustee_index"`
+	UserID       int64     `db:"user_id" json:"user_id"`
+	Share        string    `db:"share" json:"share"`
+	SubmittedAt  time.Time `db:"submitted_at" json:"submitted_at"`
+}
+
+// TerminalJournalEntry is a link of a terminal's vote journal as received by
+// the server, with the outcome of replaying its vote
+type TerminalJournalEntry struct {
+	ID               int64     `db:"id" json:"id"`
+	DeviceID         string    `db:"device_id" json:"device_id"`
+	Seq              int64     `db:"seq" json:"seq"`
+	PrevHash         string    `db:"prev_hash" json:"prev_hash"`
+	EntryHash        string    `db:"entry_hash" json:"entry_hash"`
+	Signature        string    `db:"signature" json:"signature,omitempty"`
+	VerificationHash string    `db:"verification_hash" json:"verification_hash"`
+	PollingUnitID    string    `db:"polling_unit_id" json:"polling_unit_id"`
+	Status           string    `db:"status" json:"status"`
+	ResponseCode     int       `db:"response_code" json:"response_code"`
+	Error            string    `db:"error" json:"error,omitempty"`
+	TransactionHash  string    `db:"transaction_hash" json:"transaction_hash,omitempty"`
+	RecordedAt       time.Time `db:"recorded_at" json:"recorded_at"` // when the terminal journaled the vote
+	ReceivedAt       time.Time `db:"received_at" json:"received_at"`
+}
+
+// JournalReconciliation joins a received journal entry with the vote it produced
+type JournalReconciliation struct {
+	TerminalJournalEntry
+	VoteStatus  *string    `db:"vote_status" json:"vote_status"`
+	VoteTxHash  *string    `db:"vote_transaction_hash" json:"vote_transaction_hash"`
+	BlockNumber *int64     `db:"block_number" json:"block_number"`
+	SyncedAt    *time.Time `db:"synced_at" json:"synced_at"`
+}
+
+// ElectionSalt is an election's secret salt for verification hashes, sealed
+// with the server's encryption key
+type ElectionSalt struct {
+	ElectionID string    `db:"election_id" json:"election_id"`
+	SaltSealed string    `db:"salt_sealed" json:"-"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+// ElectionVoter links a voter to the roll of an election they may vote in
+type ElectionVoter struct {
+	ElectionID    string    `db:"election_id" json:"election_id"`
+	NIN           string    `db:"nin" json:"nin"`
+	PollingUnitID string    `db:"polling_unit_id" json:"polling_unit_id"`
+	AddedAt       time.Time `db:"added_at" json:"added_at"`
+}
+
+// ElectionRoll is the snapshot of an election's voter roll: the Merkle root
+// of its voters' leaves and where it was published on chain
+type ElectionRoll struct {
+	ElectionID  string     `db:"election_id" json:"election_id"`
+	MerkleRoot  string     `db:"merkle_root" json:"merkle_root"`
+	VoterCount  int        `db:"voter_count" json:"voter_count"`
+	TxHash      string     `db:"tx_hash" json:"tx_hash,omitempty"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	PublishedAt *time.Time `db:"published_at" json:"published_at,omitempty"`
+}
+
+// Voter import job statuses
+const (
+	ImportRunning   = "running"
+	ImportCompleted = "completed"
+	ImportFailed    = "failed"
+)
+
+// VoterImportJob is a bulk voter import. A dry run checks every row without
+// registering anyone; otherwise the rows that pass are registered together in
+// one transaction once all rows are checked.
+type VoterImportJob struct {
+	ID            string     `db:"id" json:"id"`
+	Format        string     `db:"format" json:"format"`
+	DryRun        bool       `db:"dry_run" json:"dry_run"`
+	Status        string     `db:"status" json:"status"`
+	TotalRows     int        `db:"total_rows" json:"total_rows"`
+	ProcessedRows int        `db:"processed_rows" json:"processed_rows"`
+	ImportedRows  int        `db:"imported_rows" json:"imported_rows"` // registered, or would be in a dry run
+	FailedRows    int        `db:"failed_rows" json:"failed_rows"`
+	Error         string     `db:"error" json:"error,omitempty"`
+	CreatedBy     string     `db:"created_by" json:"created_by"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+	FinishedAt    *time.Time `db:"finished_at" json:"finished_at,omitempty"`
+}
+
+// VoterImportError is a row of an import that could not be imported
+type VoterImportError struct {
+	JobID   string `db:"job_id" json:"-"`
+	Line    int    `db:"line" json:"line"`
+	NIN     string `db:"nin" json:"nin,omitempty"`
+	Code    string `db:"code" json:"code"`
+	Message string `db:"message" json:"message"`
+}
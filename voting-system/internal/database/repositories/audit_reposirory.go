@@ -7,11 +7,11 @@ import (
 )
 
 type AuditLogRepository struct {
-	db *sql.DB
+	db *database.DB
 }
 
 func NewAuditLogRepository(db *sql.DB) *AuditLogRepository {
-	return &AuditLogRepository{db: db}
+	return &AuditLogRepository{db: database.Wrap(db)}
 }
 
 // InsertAuditLog inserts a new audit log entry
@@ -20,12 +20,7 @@ func (r *AuditLogRepository) InsertAuditLog(log *database.AuditLog) error {
         INSERT INTO audit_logs (action, user_id, polling_unit_id, details, ip_address)
         VALUES (?, ?, ?, ?, ?)
     `
-	result, err := r.db.Exec(query, log.Action, log.UserID, log.PollingUnitID, log.Details, log.IPAddress)
-	if err != nil {
-		return err
-	}
-
-	id, err := result.LastInsertId()
+	id, err := r.db.InsertReturningID(query, log.Action, log.UserID, log.PollingUnitID, log.Details, log.IPAddress)
 	if err != nil {
 		return err
 	}
@@ -116,6 +111,36 @@ func (r *AuditLogRepository) GetAuditLogsByAction(action string, limit, offset i
 	return logs, nil
 }
 
+// GetAuditLogsByUser gets audit logs recorded for a user, voter or terminal
+func (r *AuditLogRepository) GetAuditLogsByUser(userID string, limit, offset int) ([]database.AuditLog, error) {
+	query := `
+        SELECT id, action, user_id, polling_unit_id, details, ip_address, created_at
+        FROM audit_logs
+        WHERE user_id = ?
+        ORDER BY created_at DESC, id DESC
+        LIMIT ? OFFSET ?
+    `
+
+	rows, err := r.db.Query(query, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logs []database.AuditLog
+	for rows.Next() {
+		var log database.AuditLog
+		err := rows.Scan(&log.ID, &log.Action, &log.UserID, &log.PollingUnitID,
+			&log.Details, &log.IPAddress, &log.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, log)
+	}
+
+	return logs, nil
+}
+
 // GetAuditLogsByPollingUnit gets audit logs for a specific polling unit
 func (r *AuditLogRepository) GetAuditLogsByPollingUnit(pollingUnitID string, limit, offset int) ([]database.AuditLog, error) {
 	query := `
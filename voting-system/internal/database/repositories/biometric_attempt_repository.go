@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+	"voting-system/internal/database"
+)
+
+// BiometricAttemptRepository counts failed fingerprint matches per voter
+type BiometricAttemptRepository struct {
+	db *database.DB
+}
+
+func NewBiometricAttemptRepository(db *sql.DB) *BiometricAttemptRepository {
+	return &BiometricAttemptRepository{db: database.Wrap(db)}
+}
+
+// Get returns the failed match record for a voter, or an empty record if there is none
+func (r *BiometricAttemptRepository) Get(nin string) (*database.BiometricAttempt, error) {
+	query := `
+        SELECT nin, failed_count, locked_until, last_failed_at
+        FROM biometric_attempts
+        WHERE nin = ?
+    `
+
+	attempt := database.BiometricAttempt{NIN: nin}
+	err := r.db.QueryRow(query, nin).Scan(
+		&attempt.NIN, &attempt.FailedCount, &attempt.LockedUntil, &attempt.LastFailedAt,
+	)
+	if err == sql.ErrNoRows {
+		return &attempt, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+// RecordFailure counts a failed match and locks the voter out for lockout
+// once maxAttempts consecutive failures are reached. A maxAttempts of zero
+// disables lockout. The count and the lock are updated in one statement so
+// concurrent failures are all counted.
+func (r *BiometricAttemptRepository) RecordFailure(nin string, maxAttempts int, lockout time.Duration) (*database.BiometricAttempt, error) {
+	now := time.Now().UTC()
+	lockedUntil := now.Add(lockout)
+	var firstLock *time.Time
+	if maxAttempts == 1 {
+		firstLock = &lockedUntil
+	}
+
+	// A lock that has run out starts a fresh count
+	query := `
+        INSERT INTO biometric_attempts (nin, failed_count, locked_until, last_failed_at)
+        VALUES (?, 1, ?, ?)
+        ON CONFLICT(nin) DO UPDATE SET
+            failed_count = CASE WHEN biometric_attempts.locked_until <= ? THEN 1
+                ELSE biometric_attempts.failed_count + 1 END,
+            locked_until = CASE
+                WHEN ? > 0 AND (CASE WHEN biometric_attempts.locked_until <= ? THEN 1
+                    ELSE biometric_attempts.failed_count + 1 END) >= ? THEN ?
+                WHEN biometric_attempts.locked_until <= ? THEN NULL
+                ELSE biometric_attempts.locked_until END,
+            last_failed_at = excluded.last_failed_at
+        RETURNING failed_count, locked_until, last_failed_at
+    `
+
+	attempt := database.BiometricAttempt{NIN: nin}
+	err := r.db.QueryRow(query, nin, firstLock, now,
+		now,
+		maxAttempts, now, maxAttempts, lockedUntil,
+		now,
+	).Scan(&attempt.FailedCount, &attempt.LockedUntil, &attempt.LastFailedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+// Reset clears the failed match record after a successful match
+func (r *BiometricAttemptRepository) Reset(nin string) error {
+	_, err := r.db.Exec("DELETE FROM biometric_attempts WHERE nin = ?", nin)
+	return err
+}
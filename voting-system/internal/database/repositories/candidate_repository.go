@@ -6,17 +6,18 @@ import (
 )
 
 type CandidateRepository struct {
-	db *sql.DB
+	db *database.DB
 }
 
 func NewCandidateRepository(db *sql.DB) *CandidateRepository {
-	return &CandidateRepository{db: db}
+	return &CandidateRepository{db: database.Wrap(db)}
 }
 
 func (r *CandidateRepository) Insert(electionID int64, candidateID, name, party string) error {
 	query := `
-        INSERT OR IGNORE INTO candidates (election_id, candidate_id, name, party)
+        INSERT INTO candidates (election_id, candidate_id, name, party)
         VALUES (?, ?, ?, ?)
+        ON CONFLICT(election_id, candidate_id) DO NOTHING
     `
 	_, err := r.db.Exec(query, electionID, candidateID, name, party)
 	return err
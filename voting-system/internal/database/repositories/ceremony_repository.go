@@ -0,0 +1,216 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+	"voting-system/internal/database"
+)
+
+// CeremonyRepository stores key ceremonies, trustee shares and decryption shares
+type CeremonyRepository struct {
+	db *database.DB
+}
+
+func NewCeremonyRepository(db *sql.DB) *CeremonyRepository {
+	return &CeremonyRepository{db: database.Wrap(db)}
+}
+
+// Create records a ceremony and its trustees, replacing an earlier ceremony for the election
+func (r *CeremonyRepository) Create(ceremony *database.KeyCeremony, trustees []database.CeremonyTrustee) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"decryption_shares", "ceremony_trustees", "key_ceremonies"} {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE election_id = ?", ceremony.ElectionID); err != nil {
+			return err
+		}
+	}
+
+	ceremony.CreatedAt = time.Now().UTC()
+	_, err = tx.Exec(`
+        INSERT INTO key_ceremonies (election_id, threshold, trustees, commitments, reused_from, created_by, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?)
+    `, ceremony.ElectionID, ceremony.Threshold, ceremony.Trustees, ceremony.Commitments,
+		ceremony.ReusedFrom, ceremony.CreatedBy, ceremony.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	for _, trustee := range trustees {
+		_, err = tx.Exec(`
+            INSERT INTO ceremony_trustees (election_id, trustee_index, user_id, sealed_share, collected_at)
+            VALUES (?, ?, ?, ?, ?)
+        `, ceremony.ElectionID, trustee.TrusteeIndex, trustee.UserID, trustee.SealedShare, trustee.CollectedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Get returns an election's ceremony, or sql.ErrNoRows if it has none
+func (r *CeremonyRepository) Get(electionID string) (*database.KeyCeremony, error) {
+	query := `
+        SELECT election_id, threshold, trustees, commitments, reused_from, COALESCE(created_by, ''),
+               created_at, completed_at
+        FROM key_ceremonies
+        WHERE election_id = ?
+    `
+
+	var ceremony database.KeyCeremony
+	err := r.db.QueryRow(query, electionID).Scan(
+		&ceremony.ElectionID, &ceremony.Threshold, &ceremony.Trustees, &ceremony.Commitments,
+		&ceremony.ReusedFrom, &ceremony.CreatedBy, &ceremony.CreatedAt, &ceremony.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ceremony, nil
+}
+
+const ceremonyTrusteeColumns = `election_id, trustee_index, user_id, sealed_share, collected_at`
+
+func scanCeremonyTrustee(row rowScanner) (*database.CeremonyTrustee, error) {
+	var trustee database.CeremonyTrustee
+	err := row.Scan(&trustee.ElectionID, &trustee.TrusteeIndex, &trustee.UserID,
+		&trustee.SealedShare, &trustee.CollectedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &trustee, nil
+}
+
+func (r *CeremonyRepository) queryTrustees(query string, args ...interface{}) ([]database.CeremonyTrustee, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trustees []database.CeremonyTrustee
+	for rows.Next() {
+		trustee, err := scanCeremonyTrustee(rows)
+		if err != nil {
+			return nil, err
+		}
+		trustees = append(trustees, *trustee)
+	}
+
+	return trustees, rows.Err()
+}
+
+// ListTrustees returns an election's trustees in share order
+func (r *CeremonyRepository) ListTrustees(electionID string) ([]database.CeremonyTrustee, error) {
+	return r.queryTrustees(`SELECT `+ceremonyTrusteeColumns+` FROM ceremony_trustees
+        WHERE election_id = ? ORDER BY trustee_index ASC`, electionID)
+}
+
+// ListByUser returns every ceremony seat held by a user, newest election first
+func (r *CeremonyRepository) ListByUser(userID int64) ([]database.CeremonyTrustee, error) {
+	return r.queryTrustees(`SELECT `+ceremonyTrusteeColumns+` FROM ceremony_trustees
+        WHERE user_id = ? ORDER BY election_id DESC`, userID)
+}
+
+// GetTrustee returns a user's seat in an election's ceremony, or sql.ErrNoRows if they are not a trustee
+func (r *CeremonyRepository) GetTrustee(electionID string, userID int64) (*database.CeremonyTrustee, error) {
+	row := r.db.QueryRow(`SELECT `+ceremonyTrusteeColumns+` FROM ceremony_trustees
+        WHERE election_id = ? AND user_id = ?`, electionID, userID)
+	return scanCeremonyTrustee(row)
+}
+
+// CollectShare hands a trustee their sealed share once and erases it from the
+// database. It returns sql.ErrNoRows if the share was already collected.
+func (r *CeremonyRepository) CollectShare(electionID string, trusteeIndex int) (string, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var sealed string
+	err = tx.QueryRow(`
+        SELECT sealed_share FROM ceremony_trustees
+        WHERE election_id = ? AND trustee_index = ? AND collected_at IS NULL AND sealed_share IS NOT NULL
+    `, electionID, trusteeIndex).Scan(&sealed)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := tx.Exec(`
+        UPDATE ceremony_trustees SET sealed_share = NULL, collected_at = ?
+        WHERE election_id = ? AND trustee_index = ? AND collected_at IS NULL
+    `, time.Now().UTC(), electionID, trusteeIndex)
+	if err != nil {
+		return "", err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return "", err
+	} else if affected == 0 {
+		return "", sql.ErrNoRows
+	}
+
+	return sealed, tx.Commit()
+}
+
+// MarkCompleted records that every trustee has collected their share. It
+// returns true only for the call that completes the ceremony.
+func (r *CeremonyRepository) MarkCompleted(electionID string) (bool, error) {
+	result, err := r.db.Exec(`
+        UPDATE key_ceremonies SET completed_at = ?
+        WHERE election_id = ? AND completed_at IS NULL
+          AND NOT EXISTS (
+              SELECT 1 FROM ceremony_trustees
+              WHERE ceremony_trustees.election_id = key_ceremonies.election_id AND collected_at IS NULL
+          )
+    `, time.Now().UTC(), electionID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// SaveDecryptionShare stores a trustee's partial decryption, replacing an earlier submission
+func (r *CeremonyRepository) SaveDecryptionShare(share *database.DecryptionShareRecord) error {
+	share.SubmittedAt = time.Now().UTC()
+	_, err := r.db.Exec(`
+        INSERT INTO decryption_shares (election_id, trustee_index, user_id, share, submitted_at)
+        VALUES (?, ?, ?, ?, ?)
+        ON CONFLICT(election_id, trustee_index) DO UPDATE SET
+            user_id = excluded.user_id,
+            share = excluded.share,
+            submitted_at = excluded.submitted_at
+    `, share.ElectionID, share.TrusteeIndex, share.UserID, share.Share, share.SubmittedAt)
+	return err
+}
+
+// ListDecryptionShares returns the partial decryptions submitted for an election, in trustee order
+func (r *CeremonyRepository) ListDecryptionShares(electionID string) ([]database.DecryptionShareRecord, error) {
+	rows, err := r.db.Query(`
+        SELECT election_id, trustee_index, user_id, share, submitted_at
+        FROM decryption_shares
+        WHERE election_id = ?
+        ORDER BY trustee_index ASC
+    `, electionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shares []database.DecryptionShareRecord
+	for rows.Next() {
+		var share database.DecryptionShareRecord
+		if err := rows.Scan(&share.ElectionID, &share.TrusteeIndex, &share.UserID, &share.Share, &share.SubmittedAt); err != nil {
+			return nil, err
+		}
+		shares = append(shares, share)
+	}
+
+	return shares, rows.Err()
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+	"voting-system/internal/database"
+)
+
+// ElectionKeyRepository stores ballot encryption keys and encrypted tallies
+type ElectionKeyRepository struct {
+	db *database.DB
+}
+
+func NewElectionKeyRepository(db *sql.DB) *ElectionKeyRepository {
+	return &ElectionKeyRepository{db: database.Wrap(db)}
+}
+
+// Save stores an election's key, replacing an earlier key only while it is still open
+func (r *ElectionKeyRepository) Save(key *database.ElectionKey) error {
+	key.Status = database.ElectionKeyOpen
+	key.CreatedAt = time.Now().UTC()
+
+	query := `
+        INSERT INTO election_keys (election_id, public_key, threshold, candidates, verification_keys,
+                                   status, created_by, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+        ON CONFLICT(election_id) DO UPDATE SET
+            public_key = excluded.public_key,
+            threshold = excluded.threshold,
+            candidates = excluded.candidates,
+            verification_keys = excluded.verification_keys,
+            created_by = excluded.created_by,
+            created_at = excluded.created_at
+        WHERE election_keys.status = 'open'
+    `
+	_, err := r.db.Exec(query, key.ElectionID, key.PublicKey, key.Threshold, key.Candidates,
+		key.VerificationKeys, key.Status, key.CreatedBy, key.CreatedAt)
+	return err
+}
+
+// Get returns an election's key, or sql.ErrNoRows if the election has none
+func (r *ElectionKeyRepository) Get(electionID string) (*database.ElectionKey, error) {
+	query := `
+        SELECT election_id, public_key, threshold, candidates, verification_keys, status,
+               COALESCE(created_by, ''), created_at, closed_at
+        FROM election_keys
+        WHERE election_id = ?
+    `
+
+	var key database.ElectionKey
+	err := r.db.QueryRow(query, electionID).Scan(
+		&key.ElectionID, &key.PublicKey, &key.Threshold, &key.Candidates, &key.VerificationKeys,
+		&key.Status, &key.CreatedBy, &key.CreatedAt, &key.ClosedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// Close stops an open key from accepting ballots once the election has ended
+func (r *ElectionKeyRepository) Close(electionID string) error {
+	query := `
+        UPDATE election_keys
+        SET status = ?, closed_at = ?
+        WHERE election_id = ? AND status = ?
+    `
+	_, err := r.db.Exec(query, database.ElectionKeyClosed, time.Now().UTC(), electionID, database.ElectionKeyOpen)
+	return err
+}
+
+// GetTally returns an election's stored tally, or sql.ErrNoRows if none has been computed
+func (r *ElectionKeyRepository) GetTally(electionID string) (*database.ElectionTally, error) {
+	query := `
+        SELECT election_id, ballots, encrypted_tally, results, created_at, decrypted_at
+        FROM election_tallies
+        WHERE election_id = ?
+    `
+
+	var tally database.ElectionTally
+	err := r.db.QueryRow(query, electionID).Scan(
+		&tally.ElectionID, &tally.Ballots, &tally.EncryptedTally, &tally.Results,
+		&tally.CreatedAt, &tally.DecryptedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tally, nil
+}
+
+// SaveTally stores the encrypted tally that trustees will decrypt. The first
+// tally saved for an election is kept, so every trustee decrypts the same sums.
+func (r *ElectionKeyRepository) SaveTally(tally *database.ElectionTally) (*database.ElectionTally, error) {
+	query := `
+        INSERT INTO election_tallies (election_id, ballots, encrypted_tally, created_at)
+        VALUES (?, ?, ?, ?)
+        ON CONFLICT(election_id) DO NOTHING
+    `
+	_, err := r.db.Exec(query, tally.ElectionID, tally.Ballots, tally.EncryptedTally, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetTally(tally.ElectionID)
+}
+
+// SaveResults records the decrypted results and marks the election's key decrypted
+func (r *ElectionKeyRepository) SaveResults(electionID, results string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	_, err = tx.Exec("UPDATE election_tallies SET results = ?, decrypted_at = ? WHERE election_id = ?",
+		results, now, electionID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE election_keys SET status = ? WHERE election_id = ?",
+		database.ElectionKeyDecrypted, electionID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -6,11 +6,11 @@ import (
 )
 
 type ElectionRepository struct {
-	db *sql.DB
+	db *database.DB
 }
 
 func NewElectionRepository(db *sql.DB) *ElectionRepository {
-	return &ElectionRepository{db: db}
+	return &ElectionRepository{db: database.Wrap(db)}
 }
 
 // CreateElection creates a new election record
@@ -19,17 +19,12 @@ func (r *ElectionRepository) CreateElection(election *database.Election) error {
         INSERT INTO elections (blockchain_id, name, description, start_time, end_time)
         VALUES (?, ?, ?, ?, ?)
     `
-	result, err := r.db.Exec(query, election.BlockchainID, election.Name, election.Description,
+	id, err := r.db.InsertReturningID(query, election.BlockchainID, election.Name, election.Description,
 		election.StartTime, election.EndTime)
 	if err != nil {
 		return err
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return err
-	}
-
 	election.ID = id
 	return nil
 }
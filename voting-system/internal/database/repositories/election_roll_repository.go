@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+	"voting-system/internal/database"
+)
+
+// ElectionRollRepository stores the voter roll snapshots of elections and the
+// Merkle trees their proofs are read from
+type ElectionRollRepository struct {
+	db *database.DB
+}
+
+func NewElectionRollRepository(db *sql.DB) *ElectionRollRepository {
+	return &ElectionRollRepository{db: database.Wrap(db)}
+}
+
+// Create stores a roll snapshot with the nodes of its tree, leaves first and
+// the root last. It fails if the election already has a snapshot.
+func (r *ElectionRollRepository) Create(roll *database.ElectionRoll, levels [][]string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	roll.CreatedAt = time.Now().UTC()
+	_, err = tx.Exec(`
+        INSERT INTO election_rolls (election_id, merkle_root, voter_count, created_at)
+        VALUES (?, ?, ?, ?)
+    `, roll.ElectionID, roll.MerkleRoot, roll.VoterCount, roll.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	for level, nodes := range levels {
+		for idx, hash := range nodes {
+			_, err := tx.Exec(`INSERT INTO election_roll_nodes (election_id, level, idx, hash) VALUES (?, ?, ?, ?)`,
+				roll.ElectionID, level, idx, hash)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Get returns an election's roll snapshot, or sql.ErrNoRows
+func (r *ElectionRollRepository) Get(electionID string) (*database.ElectionRoll, error) {
+	query := `
+        SELECT election_id, merkle_root, voter_count, tx_hash, created_at, published_at
+        FROM election_rolls
+        WHERE election_id = ?
+    `
+
+	var roll database.ElectionRoll
+	var txHash sql.NullString
+	err := r.db.QueryRow(query, electionID).Scan(&roll.ElectionID, &roll.MerkleRoot, &roll.VoterCount,
+		&txHash, &roll.CreatedAt, &roll.PublishedAt)
+	if err != nil {
+		return nil, err
+	}
+	roll.TxHash = txHash.String
+	return &roll, nil
+}
+
+// MarkPublished records the transaction that published a roll's root on chain
+func (r *ElectionRollRepository) MarkPublished(electionID, txHash string) error {
+	result, err := r.db.Exec(`UPDATE election_rolls SET tx_hash = ?, published_at = ? WHERE election_id = ?`,
+		txHash, time.Now().UTC(), electionID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Proof returns the siblings on the path from a leaf to the root of an
+// election's roll, or sql.ErrNoRows if the leaf is not on it
+func (r *ElectionRollRepository) Proof(electionID, leaf string) ([]string, error) {
+	var idx int
+	err := r.db.QueryRow(`SELECT idx FROM election_roll_nodes WHERE election_id = ? AND level = 0 AND hash = ?`,
+		electionID, leaf).Scan(&idx)
+	if err != nil {
+		return nil, err
+	}
+
+	var root int
+	if err := r.db.QueryRow(`SELECT MAX(level) FROM election_roll_nodes WHERE election_id = ?`, electionID).Scan(&root); err != nil {
+		return nil, err
+	}
+
+	proof := []string{}
+	for level := 0; level < root; level++ {
+		var sibling string
+		err := r.db.QueryRow(`SELECT hash FROM election_roll_nodes WHERE election_id = ? AND level = ? AND idx = ?`,
+			electionID, level, idx^1).Scan(&sibling)
+		// A node without a sibling is carried up unchanged
+		switch {
+		case err == nil:
+			proof = append(proof, sibling)
+		case err != sql.ErrNoRows:
+			return nil, err
+		}
+		idx /= 2
+	}
+	return proof, nil
+}
+
+// ListLeaves returns a page of an election's roll leaves, in tree order
+func (r *ElectionRollRepository) ListLeaves(electionID string, limit, offset int) ([]string, error) {
+	query := `
+        SELECT hash FROM election_roll_nodes
+        WHERE election_id = ? AND level = 0
+        ORDER BY idx
+        LIMIT ? OFFSET ?
+    `
+	rows, err := r.db.Query(query, electionID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	leaves := []string{}
+	for rows.Next() {
+		var leaf string
+		if err := rows.Scan(&leaf); err != nil {
+			return nil, err
+		}
+		leaves = append(leaves, leaf)
+	}
+	return leaves, rows.Err()
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+	"voting-system/internal/database"
+)
+
+// ElectionSaltRepository stores elections' sealed verification hash salts
+type ElectionSaltRepository struct {
+	db *database.DB
+}
+
+func NewElectionSaltRepository(db *sql.DB) *ElectionSaltRepository {
+	return &ElectionSaltRepository{db: database.Wrap(db)}
+}
+
+// Get returns an election's salt, or sql.ErrNoRows
+func (r *ElectionSaltRepository) Get(electionID string) (*database.ElectionSalt, error) {
+	query := `
+        SELECT election_id, salt_sealed, created_at
+        FROM election_salts
+        WHERE election_id = ?
+    `
+
+	var salt database.ElectionSalt
+	err := r.db.QueryRow(query, electionID).Scan(&salt.ElectionID, &salt.SaltSealed, &salt.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &salt, nil
+}
+
+// Create stores an election's salt unless it already has one, and returns
+// the salt the election ends up with
+func (r *ElectionSaltRepository) Create(electionID, sealed string) (*database.ElectionSalt, error) {
+	query := `
+        INSERT INTO election_salts (election_id, salt_sealed, created_at)
+        VALUES (?, ?, ?)
+        ON CONFLICT(election_id) DO NOTHING
+    `
+	if _, err := r.db.Exec(query, electionID, sealed, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return r.Get(electionID)
+}
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+	"voting-system/internal/database"
+)
+
+// ElectionVoterRepository stores the voter roll of each election
+type ElectionVoterRepository struct {
+	db *database.DB
+}
+
+func NewElectionVoterRepository(db *sql.DB) *ElectionVoterRepository {
+	return &ElectionVoterRepository{db: database.Wrap(db)}
+}
+
+// LinkActiveVoters adds every active voter to an election's roll and returns
+// how many were added. Voters already on the roll are left as they are.
+func (r *ElectionVoterRepository) LinkActiveVoters(electionID string) (int64, error) {
+	query := `
+        INSERT INTO election_voters (election_id, nin, polling_unit_id, added_at)
+        SELECT ?, nin, polling_unit_id, ?
+        FROM voters
+        WHERE is_active = TRUE
+        ON CONFLICT(election_id, nin) DO NOTHING
+    `
+	result, err := r.db.Exec(query, electionID, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Add puts a voter on an election's roll
+func (r *ElectionVoterRepository) Add(electionID, nin, pollingUnitID string) error {
+	query := `
+        INSERT INTO election_voters (election_id, nin, polling_unit_id, added_at)
+        VALUES (?, ?, ?, ?)
+        ON CONFLICT(election_id, nin) DO UPDATE SET polling_unit_id = excluded.polling_unit_id
+    `
+	_, err := r.db.Exec(query, electionID, nin, pollingUnitID, time.Now().UTC())
+	return err
+}
+
+// IsEligible reports whether a voter is on an election's roll
+func (r *ElectionVoterRepository) IsEligible(electionID, nin string) (bool, error) {
+	query := `SELECT COUNT(*) FROM election_voters WHERE election_id = ? AND nin = ?`
+
+	var count int
+	if err := r.db.QueryRow(query, electionID, nin).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Count returns the number of voters on an election's roll
+func (r *ElectionVoterRepository) Count(electionID string) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM election_voters WHERE election_id = ?`, electionID).Scan(&count)
+	return count, err
+}
+
+// ListNINs returns the NINs on an election's roll
+func (r *ElectionVoterRepository) ListNINs(electionID string) ([]string, error) {
+	rows, err := r.db.Query(`SELECT nin FROM election_voters WHERE election_id = ? ORDER BY nin`, electionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var nins []string
+	for rows.Next() {
+		var nin string
+		if err := rows.Scan(&nin); err != nil {
+			return nil, err
+		}
+		nins = append(nins, nin)
+	}
+	return nins, rows.Err()
+}
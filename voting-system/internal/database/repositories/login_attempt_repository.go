@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+	"voting-system/internal/database"
+)
+
+type LoginAttemptRepository struct {
+	db *database.DB
+}
+
+func NewLoginAttemptRepository(db *sql.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{db: database.Wrap(db)}
+}
+
+// Get returns the failed login record for a username, or an empty record if there is none
+func (r *LoginAttemptRepository) Get(username string) (*database.LoginAttempt, error) {
+	query := `
+        SELECT username, failed_count, locked_until, last_failed_at
+        FROM login_attempts
+        WHERE username = ?
+    `
+
+	attempt := database.LoginAttempt{Username: username}
+	err := r.db.QueryRow(query, username).Scan(
+		&attempt.Username, &attempt.FailedCount, &attempt.LockedUntil, &attempt.LastFailedAt,
+	)
+	if err == sql.ErrNoRows {
+		return &attempt, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+// RecordFailure counts a failed login and locks the username for lockout once
+// maxAttempts consecutive failures are reached. A maxAttempts of zero disables lockout.
+// The count and the lock are updated in one statement so concurrent failures
+// are all counted.
+func (r *LoginAttemptRepository) RecordFailure(username string, maxAttempts int, lockout time.Duration) (*database.LoginAttempt, error) {
+	now := time.Now().UTC()
+	lockedUntil := now.Add(lockout)
+	var firstLock *time.Time
+	if maxAttempts == 1 {
+		firstLock = &lockedUntil
+	}
+
+	// A lock that has run out starts a fresh count
+	query := `
+        INSERT INTO login_attempts (username, failed_count, locked_until, last_failed_at)
+        VALUES (?, 1, ?, ?)
+        ON CONFLICT(username) DO UPDATE SET
+            failed_count = CASE WHEN login_attempts.locked_until <= ? THEN 1
+                ELSE login_attempts.failed_count + 1 END,
+            locked_until = CASE
+                WHEN ? > 0 AND (CASE WHEN login_attempts.locked_until <= ? THEN 1
+                    ELSE login_attempts.failed_count + 1 END) >= ? THEN ?
+                WHEN login_attempts.locked_until <= ? THEN NULL
+                ELSE login_attempts.locked_until END,
+            last_failed_at = excluded.last_failed_at
+        RETURNING failed_count, locked_until, last_failed_at
+    `
+
+	attempt := database.LoginAttempt{Username: username}
+	err := r.db.QueryRow(query, username, firstLock, now,
+		now,
+		maxAttempts, now, maxAttempts, lockedUntil,
+		now,
+	).Scan(&attempt.FailedCount, &attempt.LockedUntil, &attempt.LastFailedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+// Reset clears the failed login record after a successful login or an admin unlock
+func (r *LoginAttemptRepository) Reset(username string) error {
+	_, err := r.db.Exec("DELETE FROM login_attempts WHERE username = ?", username)
+	return err
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+	"voting-system/internal/database"
+)
+
+type PasswordResetRepository struct {
+	db *database.DB
+}
+
+func NewPasswordResetRepository(db *sql.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: database.Wrap(db)}
+}
+
+// Create stores a reset token, replacing any outstanding tokens for the same user
+func (r *PasswordResetRepository) Create(token *database.PasswordResetToken) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM password_reset_tokens WHERE user_id = ? AND used_at IS NULL", token.UserID); err != nil {
+		return err
+	}
+
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now().UTC()
+	}
+	query := `
+        INSERT INTO password_reset_tokens (token_hash, user_id, created_by, expires_at, created_at)
+        VALUES (?, ?, ?, ?, ?)
+    `
+	if _, err := tx.Exec(query, token.TokenHash, token.UserID, token.CreatedBy,
+		token.ExpiresAt.UTC(), token.CreatedAt.UTC()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Consume marks an unused, unexpired token as used and returns its user ID.
+// It returns sql.ErrNoRows if the token is unknown, expired or already used.
+func (r *PasswordResetRepository) Consume(tokenHash string) (int64, error) {
+	now := time.Now().UTC()
+	result, err := r.db.Exec(`
+        UPDATE password_reset_tokens
+        SET used_at = ?
+        WHERE token_hash = ? AND used_at IS NULL AND expires_at > ?
+    `, now, tokenHash, now)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if affected == 0 {
+		return 0, sql.ErrNoRows
+	}
+
+	var userID int64
+	err = r.db.QueryRow("SELECT user_id FROM password_reset_tokens WHERE token_hash = ?", tokenHash).Scan(&userID)
+	return userID, err
+}
+
+// DeleteByUser removes all reset tokens for a user
+func (r *PasswordResetRepository) DeleteByUser(userID int64) error {
+	_, err := r.db.Exec("DELETE FROM password_reset_tokens WHERE user_id = ?", userID)
+	return err
+}
@@ -0,0 +1,208 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"voting-system/internal/blockchain"
+	"voting-system/internal/database"
+)
+
+// PendingVoteRepository is a VoteQueue backed by the pending_votes table
+type PendingVoteRepository struct {
+	db *database.DB
+}
+
+func NewPendingVoteRepository(db *sql.DB) *PendingVoteRepository {
+	return &PendingVoteRepository{db: database.Wrap(db)}
+}
+
+const pendingVoteColumns = `
+        id, verification_hash, encrypted_vote, polling_unit_id, candidate_id,
+        election_id, ballot, terminal_address, vote_nonce, signed_at, terminal_signature,
+        status, attempts, last_error, next_retry_at, tx_hash, created_at, updated_at
+`
+
+// Enqueue adds a vote to the queue, returning the existing entry if the
+// verification hash is already queued
+func (r *PendingVoteRepository) Enqueue(vote blockchain.VoteData) (*blockchain.PendingVote, error) {
+	now := time.Now().UTC()
+	query := `
+        INSERT INTO pending_votes (verification_hash, encrypted_vote, polling_unit_id, candidate_id,
+                                   election_id, ballot, terminal_address, vote_nonce, signed_at,
+                                   terminal_signature, status, attempts, last_error, next_retry_at,
+                                   tx_hash, created_at, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0, '', ?, '', ?, ?)
+        ON CONFLICT(verification_hash) DO NOTHING
+    `
+	_, err := r.db.Exec(query, vote.VerificationHash, vote.EncryptedVote, vote.PollingUnitID,
+		vote.CandidateID, vote.ElectionID, vote.Ballot, vote.Terminal, vote.Nonce, vote.SignedAt,
+		vote.Signature, blockchain.QueueStatusPending, now, now, now)
+	if err != nil {
+		return nil, err
+	}
+
+	row := r.db.QueryRow(`SELECT `+pendingVoteColumns+` FROM pending_votes WHERE verification_hash = ?`,
+		vote.VerificationHash)
+	return scanPendingVote(row)
+}
+
+// Due returns pending votes whose next retry time has passed
+func (r *PendingVoteRepository) Due(now time.Time, limit int) ([]blockchain.PendingVote, error) {
+	query := `SELECT ` + pendingVoteColumns + `
+        FROM pending_votes
+        WHERE status = ? AND next_retry_at <= ?
+        ORDER BY next_retry_at ASC, id ASC`
+	args := []interface{}{blockchain.QueueStatusPending, now.UTC()}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	return r.queryPendingVotes(query, args...)
+}
+
+// MarkSyncing flags a vote as in progress and counts the attempt
+func (r *PendingVoteRepository) MarkSyncing(id int64) error {
+	return r.update(`
+        UPDATE pending_votes
+        SET status = ?, attempts = attempts + 1, updated_at = ?
+        WHERE id = ?
+    `, id, blockchain.QueueStatusSyncing, time.Now().UTC(), id)
+}
+
+// MarkSubmitted records the transaction hash of an in-flight vote
+func (r *PendingVoteRepository) MarkSubmitted(id int64, txHash string) error {
+	return r.update(`
+        UPDATE pending_votes
+        SET tx_hash = ?, updated_at = ?
+        WHERE id = ?
+    `, id, txHash, time.Now().UTC(), id)
+}
+
+// MarkSynced records a successful sync
+func (r *PendingVoteRepository) MarkSynced(id int64, txHash string) error {
+	return r.update(`
+        UPDATE pending_votes
+        SET status = ?, tx_hash = ?, last_error = '', updated_at = ?
+        WHERE id = ?
+    `, id, blockchain.QueueStatusSynced, txHash, time.Now().UTC(), id)
+}
+
+// MarkRetry returns a vote to pending and schedules its next attempt
+func (r *PendingVoteRepository) MarkRetry(id int64, lastError string, nextRetryAt time.Time) error {
+	return r.update(`
+        UPDATE pending_votes
+        SET status = ?, last_error = ?, next_retry_at = ?, updated_at = ?
+        WHERE id = ?
+    `, id, blockchain.QueueStatusPending, lastError, nextRetryAt.UTC(), time.Now().UTC(), id)
+}
+
+// MarkFailed permanently fails a vote
+func (r *PendingVoteRepository) MarkFailed(id int64, lastError string) error {
+	return r.update(`
+        UPDATE pending_votes
+        SET status = ?, last_error = ?, updated_at = ?
+        WHERE id = ?
+    `, id, blockchain.QueueStatusFailed, lastError, time.Now().UTC(), id)
+}
+
+// Recover resets votes left in the syncing state back to pending
+func (r *PendingVoteRepository) Recover() (int, error) {
+	result, err := r.db.Exec(`
+        UPDATE pending_votes
+        SET status = ?, updated_at = ?
+        WHERE status = ?
+    `, blockchain.QueueStatusPending, time.Now().UTC(), blockchain.QueueStatusSyncing)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
+// Count returns the number of outstanding votes
+func (r *PendingVoteRepository) Count() (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM pending_votes WHERE status IN (?, ?)",
+		blockchain.QueueStatusPending, blockchain.QueueStatusSyncing).Scan(&count)
+	return count, err
+}
+
+// List returns all outstanding votes, oldest first
+func (r *PendingVoteRepository) List() ([]blockchain.PendingVote, error) {
+	query := `SELECT ` + pendingVoteColumns + `
+        FROM pending_votes
+        WHERE status IN (?, ?)
+        ORDER BY created_at ASC, id ASC`
+
+	return r.queryPendingVotes(query, blockchain.QueueStatusPending, blockchain.QueueStatusSyncing)
+}
+
+// Clear removes all outstanding votes
+func (r *PendingVoteRepository) Clear() (int, error) {
+	result, err := r.db.Exec("DELETE FROM pending_votes WHERE status IN (?, ?)",
+		blockchain.QueueStatusPending, blockchain.QueueStatusSyncing)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
+func (r *PendingVoteRepository) update(query string, id int64, args ...interface{}) error {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("pending vote %d not found", id)
+	}
+
+	return nil
+}
+
+func (r *PendingVoteRepository) queryPendingVotes(query string, args ...interface{}) ([]blockchain.PendingVote, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var votes []blockchain.PendingVote
+	for rows.Next() {
+		pv, err := scanPendingVote(rows)
+		if err != nil {
+			return nil, err
+		}
+		votes = append(votes, *pv)
+	}
+
+	return votes, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPendingVote(row rowScanner) (*blockchain.PendingVote, error) {
+	var pv blockchain.PendingVote
+	err := row.Scan(
+		&pv.ID, &pv.Vote.VerificationHash, &pv.Vote.EncryptedVote, &pv.Vote.PollingUnitID,
+		&pv.Vote.CandidateID, &pv.Vote.ElectionID, &pv.Vote.Ballot, &pv.Vote.Terminal, &pv.Vote.Nonce,
+		&pv.Vote.SignedAt, &pv.Vote.Signature, &pv.Status, &pv.Attempts, &pv.LastError, &pv.NextRetryAt,
+		&pv.TxHash, &pv.CreatedAt, &pv.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pv, nil
+}
@@ -0,0 +1,903 @@
+package repositories
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"voting-system/internal/blockchain"
+	"voting-system/internal/database"
+	"voting-system/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// postgresDSNEnv names a Postgres server to run the repository tests against,
+// e.g. "host=localhost user=postgres password=postgres dbname=voting_test sslmode=disable"
+const postgresDSNEnv = "TEST_POSTGRES_DSN"
+
+// forEachDialect runs fn against a freshly migrated SQLite database and, when
+// TEST_POSTGRES_DSN is set, against a throwaway schema on that Postgres server.
+func forEachDialect(t *testing.T, fn func(t *testing.T, db *sql.DB)) {
+	t.Run("sqlite", func(t *testing.T) {
+		db, err := database.NewConnection(&config.DatabaseConfig{
+			Type: "sqlite",
+			Path: filepath.Join(t.TempDir(), "test.db"),
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		require.NoError(t, database.RunMigrations(db))
+		fn(t, db)
+	})
+
+	t.Run("postgres", func(t *testing.T) {
+		dsn := os.Getenv(postgresDSNEnv)
+		if dsn == "" {
+			t.Skipf("%s not set", postgresDSNEnv)
+		}
+		db := openPostgresSchema(t, dsn)
+		require.NoError(t, database.RunMigrations(db))
+		fn(t, db)
+	})
+}
+
+// openPostgresSchema creates an empty schema for one test and returns a
+// connection whose search_path points at it
+func openPostgresSchema(t *testing.T, dsn string) *sql.DB {
+	t.Helper()
+
+	admin, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { admin.Close() })
+
+	suffix := make([]byte, 6)
+	_, err = rand.Read(suffix)
+	require.NoError(t, err)
+	schema := "test_" + hex.EncodeToString(suffix)
+
+	_, err = admin.Exec("CREATE SCHEMA " + schema)
+	require.NoError(t, err)
+	t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		sep := "?"
+		if strings.Contains(dsn, "?") {
+			sep = "&"
+		}
+		dsn += sep + "search_path=" + schema
+	} else {
+		dsn += " search_path=" + schema
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.Ping())
+	return db
+}
+
+func createUser(t *testing.T, db *sql.DB, username string) *database.User {
+	t.Helper()
+
+	user := &database.User{
+		Username:     username,
+		Email:        username + "@example.com",
+		PasswordHash: "hash",
+		Role:         "operator",
+		Permissions:  "[]",
+	}
+	require.NoError(t, NewUserRepository(db).Create(user))
+	return user
+}
+
+func createElection(t *testing.T, db *sql.DB, blockchainID string) *database.Election {
+	t.Helper()
+
+	now := time.Now().UTC()
+	election := &database.Election{
+		BlockchainID: blockchainID,
+		Name:         "Election " + blockchainID,
+		StartTime:    now,
+		EndTime:      now.Add(24 * time.Hour),
+	}
+	require.NoError(t, NewElectionRepository(db).CreateElection(election))
+	return election
+}
+
+func TestUserRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB) {
+		repo := NewUserRepository(db)
+
+		first := createUser(t, db, "alice")
+		second := createUser(t, db, "bob")
+		assert.NotZero(t, first.ID)
+		assert.Greater(t, second.ID, first.ID)
+
+		user, err := repo.GetByUsername("alice")
+		require.NoError(t, err)
+		assert.Equal(t, first.ID, user.ID)
+		assert.True(t, user.IsActive)
+
+		require.NoError(t, repo.DeactivateUser(first.ID))
+		_, err = repo.GetByUsername("alice")
+		assert.Equal(t, sql.ErrNoRows, err)
+
+		active := true
+		users, err := repo.ListUsers("operator", &active, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.Equal(t, "bob", users[0].Username)
+
+		count, err := repo.CountActiveByRole("operator")
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		// Duplicate usernames violate the unique constraint on both dialects
+		err = repo.Create(&database.User{Username: "bob", Email: "other@example.com", PasswordHash: "hash", Role: "operator"})
+		require.Error(t, err)
+		msg := err.Error()
+		assert.True(t, strings.Contains(msg, "UNIQUE constraint failed") || strings.Contains(msg, "duplicate key value"), msg)
+	})
+}
+
+func TestElectionAndCandidateRepositories(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB) {
+		elections := NewElectionRepository(db)
+		candidates := NewCandidateRepository(db)
+
+		election := createElection(t, db, "1")
+		assert.NotZero(t, election.ID)
+
+		_, err := elections.GetActiveElection()
+		assert.Equal(t, sql.ErrNoRows, err)
+		require.NoError(t, elections.UpdateElectionStatus(election.ID, true))
+		active, err := elections.GetActiveElection()
+		require.NoError(t, err)
+		assert.Equal(t, election.ID, active.ID)
+
+		// Inserting the same candidate twice keeps one row
+		require.NoError(t, candidates.Insert(election.ID, "C1", "Ada", "Party A"))
+		require.NoError(t, candidates.Insert(election.ID, "C1", "Ada", "Party A"))
+		require.NoError(t, candidates.Insert(election.ID, "C2", "Grace", "Party B"))
+		list, err := candidates.ListByElection(election.ID)
+		require.NoError(t, err)
+		assert.Len(t, list, 2)
+
+		require.NoError(t, elections.DeleteElectionCascade(election.ID))
+		list, err = candidates.ListByElection(election.ID)
+		require.NoError(t, err)
+		assert.Empty(t, list)
+	})
+}
+
+func TestVoteRepositoryResults(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB) {
+		repo := NewVoteRepository(db)
+		election := createElection(t, db, "7")
+
+		for i, candidate := range []string{"C1", "C1", "C2"} {
+			vote := &database.Vote{
+				VerificationHash: "hash-" + string(rune('a'+i)),
+				ElectionID:       election.ID,
+				PollingUnitID:    "PU001",
+				CandidateID:      candidate,
+				EncryptedVote:    "ciphertext",
+				Status:           "pending",
+			}
+			require.NoError(t, repo.InsertVote(vote))
+			assert.NotZero(t, vote.ID)
+			require.NoError(t, repo.UpdateVoteSync(vote.VerificationHash, "0xtx", int64(100+i)))
+		}
+
+		results, err := repo.GetElectionResults(election.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, results["total_votes"])
+
+		// The timeline is grouped by day in the same format on every dialect
+		timeline, err := json.Marshal(results["voting_timeline"])
+		require.NoError(t, err)
+		assert.Contains(t, string(timeline), `"date":"`+time.Now().UTC().Format("2006-01-02")+`"`)
+		assert.Contains(t, string(timeline), `"vote_count":3`)
+
+		counts, err := repo.GetVoteCountByStatus(election.ID)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"synced": 3}, counts)
+	})
+}
+
+func TestElectionKeyRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB) {
+		keys := NewElectionKeyRepository(db)
+		votes := NewVoteRepository(db)
+		election := createElection(t, db, "7")
+
+		_, err := keys.Get("7")
+		assert.Equal(t, sql.ErrNoRows, err)
+
+		key := &database.ElectionKey{ElectionID: "7", PublicKey: "pk1", Threshold: 2, Candidates: `["C1","C2"]`, VerificationKeys: `[]`}
+		require.NoError(t, keys.Save(key))
+		key.PublicKey = "pk2"
+		require.NoError(t, keys.Save(key))
+		stored, err := keys.Get("7")
+		require.NoError(t, err)
+		assert.Equal(t, "pk2", stored.PublicKey)
+		assert.Equal(t, database.ElectionKeyOpen, stored.Status)
+
+		// Only synced ballots are tallied
+		for i, status := range []string{"synced", "pending"} {
+			ballot := fmt.Sprintf("ballot-%d", i)
+			vote := &database.Vote{VerificationHash: ballot, ElectionID: election.ID, PollingUnitID: "PU001", Ballot: &ballot, Status: "pending"}
+			require.NoError(t, votes.InsertVote(vote))
+			if status == "synced" {
+				require.NoError(t, votes.UpdateVoteSync(vote.VerificationHash, "0xtx", 1))
+			}
+		}
+		ballots, err := votes.ListBallots(election.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ballot-0"}, ballots)
+		count, err := votes.CountBallots(election.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		// A closed key can no longer be replaced
+		require.NoError(t, keys.Close("7"))
+		key.PublicKey = "pk3"
+		require.NoError(t, keys.Save(key))
+		stored, err = keys.Get("7")
+		require.NoError(t, err)
+		assert.Equal(t, "pk2", stored.PublicKey)
+		assert.Equal(t, database.ElectionKeyClosed, stored.Status)
+		assert.NotNil(t, stored.ClosedAt)
+
+		// The first tally saved is kept
+		first, err := keys.SaveTally(&database.ElectionTally{ElectionID: "7", Ballots: 1, EncryptedTally: "t1"})
+		require.NoError(t, err)
+		second, err := keys.SaveTally(&database.ElectionTally{ElectionID: "7", Ballots: 2, EncryptedTally: "t2"})
+		require.NoError(t, err)
+		assert.Equal(t, first.EncryptedTally, second.EncryptedTally)
+		assert.Nil(t, second.Results)
+
+		require.NoError(t, keys.SaveResults("7", `{"C1":1}`))
+		tally, err := keys.GetTally("7")
+		require.NoError(t, err)
+		require.NotNil(t, tally.Results)
+		assert.Equal(t, `{"C1":1}`, *tally.Results)
+		stored, err = keys.Get("7")
+		require.NoError(t, err)
+		assert.Equal(t, database.ElectionKeyDecrypted, stored.Status)
+	})
+}
+
+func TestCeremonyRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB) {
+		ceremonies := NewCeremonyRepository(db)
+		alice := createUser(t, db, "alice")
+		bob := createUser(t, db, "bob")
+
+		sealed := []string{"sealed-1", "sealed-2"}
+		create := func() {
+			require.NoError(t, ceremonies.Create(
+				&database.KeyCeremony{ElectionID: "7", Threshold: 2, Trustees: 2, Commitments: `["c0","c1"]`},
+				[]database.CeremonyTrustee{
+					{ElectionID: "7", TrusteeIndex: 1, UserID: alice.ID, SealedShare: &sealed[0]},
+					{ElectionID: "7", TrusteeIndex: 2, UserID: bob.ID, SealedShare: &sealed[1]},
+				}))
+		}
+		create()
+
+		seat, err := ceremonies.GetTrustee("7", bob.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, seat.TrusteeIndex)
+		_, err = ceremonies.GetTrustee("8", bob.ID)
+		assert.Equal(t, sql.ErrNoRows, err)
+
+		// Shares are handed out once
+		share, err := ceremonies.CollectShare("7", 1)
+		require.NoError(t, err)
+		assert.Equal(t, "sealed-1", share)
+		_, err = ceremonies.CollectShare("7", 1)
+		assert.Equal(t, sql.ErrNoRows, err)
+
+		completed, err := ceremonies.MarkCompleted("7")
+		require.NoError(t, err)
+		assert.False(t, completed)
+		_, err = ceremonies.CollectShare("7", 2)
+		require.NoError(t, err)
+		completed, err = ceremonies.MarkCompleted("7")
+		require.NoError(t, err)
+		assert.True(t, completed)
+		completed, err = ceremonies.MarkCompleted("7")
+		require.NoError(t, err)
+		assert.False(t, completed)
+
+		trustees, err := ceremonies.ListTrustees("7")
+		require.NoError(t, err)
+		require.Len(t, trustees, 2)
+		for _, trustee := range trustees {
+			assert.Nil(t, trustee.SealedShare)
+			assert.NotNil(t, trustee.CollectedAt)
+		}
+
+		require.NoError(t, ceremonies.SaveDecryptionShare(&database.DecryptionShareRecord{ElectionID: "7", TrusteeIndex: 2, UserID: bob.ID, Share: "d2"}))
+		require.NoError(t, ceremonies.SaveDecryptionShare(&database.DecryptionShareRecord{ElectionID: "7", TrusteeIndex: 1, UserID: alice.ID, Share: "old"}))
+		require.NoError(t, ceremonies.SaveDecryptionShare(&database.DecryptionShareRecord{ElectionID: "7", TrusteeIndex: 1, UserID: alice.ID, Share: "d1"}))
+		shares, err := ceremonies.ListDecryptionShares("7")
+		require.NoError(t, err)
+		require.Len(t, shares, 2)
+		assert.Equal(t, "d1", shares[0].Share)
+		assert.Equal(t, "d2", shares[1].Share)
+
+		// A new ceremony replaces the old one and its decryption shares
+		create()
+		ceremony, err := ceremonies.Get("7")
+		require.NoError(t, err)
+		assert.Nil(t, ceremony.CompletedAt)
+		shares, err = ceremonies.ListDecryptionShares("7")
+		require.NoError(t, err)
+		assert.Empty(t, shares)
+		seats, err := ceremonies.ListByUser(alice.ID)
+		require.NoError(t, err)
+		require.Len(t, seats, 1)
+		assert.NotNil(t, seats[0].SealedShare)
+	})
+}
+
+func TestAuditLogRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB) {
+		repo := NewAuditLogRepository(db)
+
+		for _, action := range []string{"login", "login", "vote_cast"} {
+			log := &database.AuditLog{Action: action, UserID: "1", PollingUnitID: "PU001", Details: "{}", IPAddress: "127.0.0.1"}
+			require.NoError(t, repo.InsertAuditLog(log))
+			assert.NotZero(t, log.ID)
+		}
+
+		start := time.Now().UTC().Add(-time.Hour)
+		logs, err := repo.GetAuditLogs(10, 0, "login", "PU001", &start, nil)
+		require.NoError(t, err)
+		assert.Len(t, logs, 2)
+
+		end := time.Now().UTC().Add(time.Hour)
+		stats, err := repo.GetAuditStatistics(&start, &end)
+		require.NoError(t, err)
+		assert.Equal(t, 3, stats["total_logs"])
+	})
+}
+
+func TestVoterAndTerminalRepositories(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB) {
+		voters := NewVoterRepository(db)
+		templates := NewVoterTemplateRepository(db)
+		attempts := NewBiometricAttemptRepository(db)
+		terminals := NewTerminalRepository(db)
+
+		require.NoError(t, voters.RegisterVoter(&database.Voter{
+			NIN: "12345678901", FirstName: "Ada", LastName: "Lovelace",
+			DateOfBirth: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), Gender: "F",
+			PollingUnitID: "PU001", FingerprintHash: "fp",
+		}, &database.VoterTemplate{Format: "minutiae", Template: `{"minutiae":[]}`, Quality: 0.9}))
+		template, err := templates.Get("12345678901")
+		require.NoError(t, err)
+		assert.Equal(t, "minutiae", template.Format)
+		assert.InDelta(t, 0.9, template.Quality, 0.001)
+		roster, err := templates.ListByPollingUnit("PU001")
+		require.NoError(t, err)
+		assert.Len(t, roster, 1)
+
+		voter, err := voters.GetVoterByNIN("12345678901")
+		require.NoError(t, err)
+		require.NoError(t, voters.DeactivateVoter(voter.ID, database.VoterDeceased))
+		assert.Equal(t, sql.ErrNoRows, voters.DeactivateVoter(voter.ID, database.VoterDeceased))
+		voter, err = voters.GetVoterByFingerprint("fp")
+		require.NoError(t, err)
+		assert.False(t, voter.IsActive)
+		assert.Equal(t, database.VoterDeceased, voter.DeactivationReason)
+		assert.NotNil(t, voter.DeactivatedAt)
+
+		// Deactivated voters leave the rosters but are still searched for duplicates
+		roster, err = templates.ListByPollingUnit("PU001")
+		require.NoError(t, err)
+		assert.Empty(t, roster)
+		gallery, err := templates.ListByFormat("minutiae")
+		require.NoError(t, err)
+		assert.Len(t, gallery, 1)
+
+		failed, err := attempts.RecordFailure("12345678901", 2, time.Minute)
+		require.NoError(t, err)
+		assert.Nil(t, failed.LockedUntil)
+		failed, err = attempts.RecordFailure("12345678901", 2, time.Minute)
+		require.NoError(t, err)
+		require.NotNil(t, failed.LockedUntil)
+		require.NoError(t, attempts.Reset("12345678901"))
+		failed, err = attempts.Get("12345678901")
+		require.NoError(t, err)
+		assert.Zero(t, failed.FailedCount)
+
+		// Concurrent failures are all counted
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := attempts.RecordFailure("10987654321", 0, time.Minute)
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+		failed, err = attempts.Get("10987654321")
+		require.NoError(t, err)
+		assert.Equal(t, 10, failed.FailedCount)
+
+		require.NoError(t, terminals.RegisterTerminal(&database.Terminal{
+			ID: "T1", Name: "Terminal 1", PollingUnitID: "PU001", EthAddress: "0xabc", Status: "active",
+		}))
+		require.NoError(t, terminals.AuthorizeTerminal("T1"))
+		require.NoError(t, terminals.UpdateTerminalHeartbeat("T1"))
+		terminal, err := terminals.GetTerminal("T1")
+		require.NoError(t, err)
+		assert.True(t, terminal.Authorized)
+		assert.NotNil(t, terminal.LastHeartbeat)
+
+		list, err := terminals.ListTerminals("active", "PU001", 10, 0)
+		require.NoError(t, err)
+		assert.Len(t, list, 1)
+	})
+}
+
+func TestVoterLifecycle(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB) {
+		voters := NewVoterRepository(db)
+		roll := NewElectionVoterRepository(db)
+		elections := NewElectionRepository(db)
+
+		for i, name := range []string{"Obi", "Ade", "Eze"} {
+			require.NoError(t, voters.RegisterVoter(&database.Voter{
+				NIN: fmt.Sprintf("1000000000%d", i), FirstName: "Ada", LastName: name,
+				DateOfBirth: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), Gender: "F",
+				PollingUnitID: "PU001", FingerprintHash: fmt.Sprintf("fp-%d", i),
+			}, &database.VoterTemplate{Format: "exact", Template: fmt.Sprintf("fp-%d", i), Quality: 1}))
+		}
+		found, total, err := voters.SearchVoters(VoterFilter{Name: "ad"}, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 3, total)
+		assert.Equal(t, "Ade", found[0].LastName, "voters are listed by name")
+		found, total, err = voters.SearchVoters(VoterFilter{Name: "EZE"}, 10, 0)
+		require.NoError(t, err)
+		require.Equal(t, 1, total)
+		eze := found[0]
+
+		// Transfers move the voter on the rolls of elections still to come
+		election := &database.Election{BlockchainID: "1", Name: "Upcoming",
+			StartTime: time.Now().Add(time.Hour), EndTime: time.Now().Add(2 * time.Hour)}
+		require.NoError(t, elections.CreateElection(election))
+		_, err = roll.LinkActiveVoters("1")
+		require.NoError(t, err)
+		require.NoError(t, voters.TransferVoter(eze.ID, "PU002"))
+		var pollingUnitID string
+		require.NoError(t, db.QueryRow(`SELECT polling_unit_id FROM election_voters WHERE election_id = '1' AND nin = ?`, eze.NIN).Scan(&pollingUnitID))
+		assert.Equal(t, "PU002", pollingUnitID)
+		_, total, err = voters.SearchVoters(VoterFilter{PollingUnitID: "PU001"}, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+
+		require.NoError(t, voters.DeactivateVoter(eze.ID, database.VoterIneligible))
+		assert.Equal(t, sql.ErrNoRows, voters.TransferVoter(eze.ID, "PU001"), "deactivated voters cannot be transferred")
+		active := false
+		found, total, err = voters.SearchVoters(VoterFilter{Active: &active}, 10, 0)
+		require.NoError(t, err)
+		require.Equal(t, 1, total)
+		assert.Equal(t, database.VoterIneligible, found[0].DeactivationReason)
+
+		require.NoError(t, voters.ReactivateVoter(eze.ID))
+		assert.Equal(t, sql.ErrNoRows, voters.ReactivateVoter(eze.ID))
+		voter, err := voters.GetVoterByNIN(eze.NIN)
+		require.NoError(t, err)
+		assert.True(t, voter.IsActive)
+		assert.Empty(t, voter.DeactivationReason)
+		assert.Nil(t, voter.DeactivatedAt)
+	})
+}
+
+func TestElectionVoterRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB) {
+		voters := NewVoterRepository(db)
+		roll := NewElectionVoterRepository(db)
+
+		for i, nin := range []string{"12345678901", "10987654321", "11111111111"} {
+			require.NoError(t, voters.RegisterVoter(&database.Voter{
+				NIN: nin, FirstName: "Ada", LastName: "Lovelace",
+				DateOfBirth: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), Gender: "F",
+				PollingUnitID: "PU001", FingerprintHash: fmt.Sprintf("fp-%d", i),
+			}, &database.VoterTemplate{Format: "exact", Template: fmt.Sprintf("fp-%d", i), Quality: 1}))
+		}
+		voter, err := voters.GetVoterByNIN("11111111111")
+		require.NoError(t, err)
+		require.NoError(t, voters.DeactivateVoter(voter.ID, database.VoterDuplicate))
+
+		// Only active voters are linked, once
+		linked, err := roll.LinkActiveVoters("1")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), linked)
+		linked, err = roll.LinkActiveVoters("1")
+		require.NoError(t, err)
+		assert.Zero(t, linked)
+		count, err := roll.Count("1")
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		eligible, err := roll.IsEligible("1", "12345678901")
+		require.NoError(t, err)
+		assert.True(t, eligible)
+		eligible, err = roll.IsEligible("1", "11111111111")
+		require.NoError(t, err)
+		assert.False(t, eligible)
+
+		// Rolls are per election
+		eligible, err = roll.IsEligible("2", "12345678901")
+		require.NoError(t, err)
+		assert.False(t, eligible)
+		require.NoError(t, roll.Add("2", "12345678901", "PU001"))
+		require.NoError(t, roll.Add("2", "12345678901", "PU002"))
+		count, err = roll.Count("2")
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+}
+
+func TestElectionRollRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB) {
+		rolls := NewElectionRollRepository(db)
+
+		_, err := rolls.Get("1")
+		assert.Equal(t, sql.ErrNoRows, err)
+
+		// Three leaves: the third is carried up to the root's level
+		levels := [][]string{{"0xa", "0xb", "0xc"}, {"0xab", "0xc"}, {"0xabc"}}
+		require.NoError(t, rolls.Create(&database.ElectionRoll{ElectionID: "1", MerkleRoot: "0xabc", VoterCount: 3}, levels))
+		assert.Error(t, rolls.Create(&database.ElectionRoll{ElectionID: "1", MerkleRoot: "0xdef", VoterCount: 1}, nil),
+			"an election is snapshotted once")
+
+		roll, err := rolls.Get("1")
+		require.NoError(t, err)
+		assert.Equal(t, "0xabc", roll.MerkleRoot)
+		assert.Equal(t, 3, roll.VoterCount)
+		assert.Nil(t, roll.PublishedAt)
+
+		proof, err := rolls.Proof("1", "0xb")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"0xa", "0xc"}, proof)
+		proof, err = rolls.Proof("1", "0xc")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"0xab"}, proof)
+		_, err = rolls.Proof("1", "0xab")
+		assert.Equal(t, sql.ErrNoRows, err, "inner nodes are not leaves")
+
+		leaves, err := rolls.ListLeaves("1", 2, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"0xb", "0xc"}, leaves)
+
+		require.NoError(t, rolls.MarkPublished("1", "0xtx"))
+		roll, err = rolls.Get("1")
+		require.NoError(t, err)
+		assert.Equal(t, "0xtx", roll.TxHash)
+		assert.NotNil(t, roll.PublishedAt)
+		assert.Equal(t, sql.ErrNoRows, rolls.MarkPublished("2", "0xtx"))
+	})
+}
+
+func TestVoterImportRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB) {
+		imports := NewVoterImportRepository(db)
+		voters := NewVoterRepository(db)
+
+		job := &database.VoterImportJob{ID: "job-1", Format: "csv", Status: database.ImportRunning, TotalRows: 3, CreatedBy: "1"}
+		require.NoError(t, imports.Create(job))
+		require.NoError(t, imports.AddErrors("job-1", []database.VoterImportError{
+			{Line: 4, NIN: "123", Code: "invalid_nin", Message: "NIN must be 11 digits"},
+			{Line: 2, Code: "missing_field", Message: "nin is required"},
+		}))
+		require.NoError(t, imports.UpdateProgress("job-1", 2, 0, 2))
+
+		got, err := imports.Get("job-1")
+		require.NoError(t, err)
+		assert.Equal(t, 2, got.ProcessedRows)
+		assert.Nil(t, got.FinishedAt)
+		rowErrors, err := imports.ListErrors("job-1", 10, 0)
+		require.NoError(t, err)
+		require.Len(t, rowErrors, 2)
+		assert.Equal(t, 2, rowErrors[0].Line)
+
+		// Voters are registered together or not at all
+		batch := func(nins ...string) ([]*database.Voter, []*database.VoterTemplate) {
+			var vs []*database.Voter
+			var ts []*database.VoterTemplate
+			for _, nin := range nins {
+				vs = append(vs, &database.Voter{NIN: nin, FirstName: "Ada", LastName: "Obi",
+					DateOfBirth: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), Gender: "F",
+					PollingUnitID: "PU001", FingerprintHash: "fp-" + nin})
+				ts = append(ts, &database.VoterTemplate{Format: "exact", Template: "fp-" + nin, Quality: 1})
+			}
+			return vs, ts
+		}
+		assert.Error(t, voters.RegisterVoters(batch("12345678901", "12345678901")))
+		_, err = voters.GetVoterByNIN("12345678901")
+		assert.Equal(t, sql.ErrNoRows, err)
+		require.NoError(t, voters.RegisterVoters(batch("12345678901", "10987654321")))
+
+		job.Status, job.ProcessedRows, job.ImportedRows, job.FailedRows = database.ImportCompleted, 3, 1, 2
+		require.NoError(t, imports.Finish(job))
+		require.NoError(t, imports.UpdateProgress("job-1", 0, 0, 0))
+		got, err = imports.Get("job-1")
+		require.NoError(t, err)
+		assert.Equal(t, database.ImportCompleted, got.Status)
+		assert.Equal(t, 3, got.ProcessedRows, "finished jobs take no more progress")
+		assert.NotNil(t, got.FinishedAt)
+
+		failed, err := imports.FailInterrupted()
+		require.NoError(t, err)
+		assert.Zero(t, failed)
+	})
+}
+
+func TestTerminalFleetRepositories(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB) {
+		terminals := NewTerminalRepository(db)
+		alerts := NewTerminalAlertRepository(db)
+		configs := NewTerminalConfigRepository(db)
+
+		require.NoError(t, terminals.RegisterTerminal(&database.Terminal{
+			ID: "T1", Name: "Terminal 1", PollingUnitID: "PU001", EthAddress: "0xabc", Status: "active",
+		}))
+		battery := 40
+		require.NoError(t, terminals.RecordHeartbeat("T1", database.TerminalHealth{
+			BatteryLevel: &battery, FirmwareVersion: "1.0.0", QueueDepth: 3, ClockSkewMs: -250,
+		}))
+		assert.Equal(t, sql.ErrNoRows, terminals.RecordHeartbeat("T404", database.TerminalHealth{}))
+
+		terminal, err := terminals.GetTerminal("T1")
+		require.NoError(t, err)
+		assert.True(t, terminal.Online)
+		require.NotNil(t, terminal.BatteryLevel)
+		assert.Equal(t, 40, *terminal.BatteryLevel)
+		assert.Equal(t, int64(-250), terminal.ClockSkewMs)
+
+		offline, err := terminals.MarkOffline(time.Now().Add(-time.Minute))
+		require.NoError(t, err)
+		assert.Empty(t, offline)
+		offline, err = terminals.MarkOffline(time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.Len(t, offline, 1)
+		terminal, err = terminals.GetTerminal("T1")
+		require.NoError(t, err)
+		assert.False(t, terminal.Online)
+
+		opened, err := alerts.Raise("T1", "offline", "silent")
+		require.NoError(t, err)
+		assert.True(t, opened)
+		opened, err = alerts.Raise("T1", "offline", "still silent")
+		require.NoError(t, err)
+		assert.False(t, opened)
+		resolved, err := alerts.Resolve("T1", "offline")
+		require.NoError(t, err)
+		assert.True(t, resolved)
+		open, err := alerts.List("T1", true, 10, 0)
+		require.NoError(t, err)
+		assert.Empty(t, open)
+		all, err := alerts.List("", false, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, all, 1)
+		assert.NotNil(t, all[0].ResolvedAt)
+
+		_, err = configs.Get("T1")
+		assert.Equal(t, sql.ErrNoRows, err)
+		require.NoError(t, configs.Save("T1", `{"max_attempts":5}`, "1"))
+		require.NoError(t, configs.Save("T1", `{"max_attempts":4}`, "2"))
+		settings, err := configs.Get("T1")
+		require.NoError(t, err)
+		assert.Equal(t, `{"max_attempts":4}`, settings.Settings)
+		assert.Equal(t, "2", settings.UpdatedBy)
+	})
+}
+
+func TestLoginAttemptsConcurrentFailures(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB) {
+		attempts := NewLoginAttemptRepository(db)
+
+		const failures = 20
+		var wg sync.WaitGroup
+		errs := make(chan error, failures)
+		for i := 0; i < failures; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := attempts.RecordFailure("dave", failures, time.Minute)
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			require.NoError(t, err)
+		}
+
+		// Every failure is counted and the last one locks the account
+		stored, err := attempts.Get("dave")
+		require.NoError(t, err)
+		assert.Equal(t, failures, stored.FailedCount)
+		require.NotNil(t, stored.LockedUntil)
+		assert.True(t, stored.LockedUntil.After(time.Now()))
+
+		// A lock that has run out starts a fresh count
+		_, err = attempts.RecordFailure("erin", 1, -time.Minute)
+		require.NoError(t, err)
+		attempt, err := attempts.RecordFailure("erin", 3, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempt.FailedCount)
+		assert.Nil(t, attempt.LockedUntil)
+		attempt, err = attempts.RecordFailure("erin", 0, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, 2, attempt.FailedCount)
+		assert.Nil(t, attempt.LockedUntil)
+	})
+}
+
+func TestAuthRepositories(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB) {
+		user := createUser(t, db, "carol")
+
+		// Sessions
+		sessions := NewSessionRepository(db)
+		require.NoError(t, sessions.Create(&database.Session{ID: "s1", UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour)}))
+		session, err := sessions.GetByID("s1")
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, session.UserID)
+		removed, err := sessions.DeleteByUser(user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), removed)
+
+		// Login attempts are upserted
+		attempts := NewLoginAttemptRepository(db)
+		_, err = attempts.RecordFailure("carol", 2, time.Minute)
+		require.NoError(t, err)
+		attempt, err := attempts.RecordFailure("carol", 2, time.Minute)
+		require.NoError(t, err)
+		assert.NotNil(t, attempt.LockedUntil)
+		stored, err := attempts.Get("carol")
+		require.NoError(t, err)
+		assert.Equal(t, 2, stored.FailedCount)
+
+		// Password reset tokens run in a transaction
+		resets := NewPasswordResetRepository(db)
+		require.NoError(t, resets.Create(&database.PasswordResetToken{
+			TokenHash: "token", UserID: user.ID, CreatedBy: "admin", ExpiresAt: time.Now().Add(time.Hour),
+		}))
+		userID, err := resets.Consume("token")
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, userID)
+		_, err = resets.Consume("token")
+		assert.Equal(t, sql.ErrNoRows, err)
+
+		// Two-factor enrolment
+		twoFactor := NewTwoFactorRepository(db)
+		require.NoError(t, twoFactor.SavePending(user.ID, "sealed"))
+		require.NoError(t, twoFactor.SavePending(user.ID, "sealed-again"))
+		require.NoError(t, twoFactor.Enable(user.ID, 10))
+		ok, err := twoFactor.UseStep(user.ID, 10)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		ok, err = twoFactor.UseStep(user.ID, 11)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		require.NoError(t, twoFactor.ReplaceRecoveryCodes(user.ID, []string{"a", "b"}))
+		ok, err = twoFactor.UseRecoveryCode(user.ID, "a")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		remaining, err := twoFactor.CountRecoveryCodes(user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, remaining)
+	})
+}
+
+func TestPendingVoteRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB) {
+		queue := NewPendingVoteRepository(db)
+		vote := blockchain.VoteData{VerificationHash: "h1", EncryptedVote: "c", PollingUnitID: "PU001", CandidateID: "C1"}
+
+		first, err := queue.Enqueue(vote)
+		require.NoError(t, err)
+		again, err := queue.Enqueue(vote)
+		require.NoError(t, err)
+		assert.Equal(t, first.ID, again.ID)
+
+		due, err := queue.Due(time.Now().Add(time.Second), 10)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+
+		// Encrypted ballots keep their election and ballot through the queue
+		encrypted, err := queue.Enqueue(blockchain.VoteData{VerificationHash: "h2", PollingUnitID: "PU001", ElectionID: "7", Ballot: `{"ciphertexts":[]}`})
+		require.NoError(t, err)
+		assert.True(t, encrypted.Vote.IsEncrypted())
+		assert.Equal(t, "7", encrypted.Vote.ElectionID)
+		require.NoError(t, queue.MarkFailed(encrypted.ID, "test"))
+
+		// So do terminal signatures, which are relayed when the vote syncs
+		relayed, err := queue.Enqueue(blockchain.VoteData{
+			VerificationHash: "h3", PollingUnitID: "PU001", CandidateID: "C1",
+			Terminal: "0x345cA3e014Aaf5dcA488057592ee47305D9B3e10", Nonce: strings.Repeat("ab", 32),
+			SignedAt: 1760000000, Signature: "0x" + strings.Repeat("cd", 65),
+		})
+		require.NoError(t, err)
+		assert.True(t, relayed.Vote.IsRelayed())
+		assert.Equal(t, int64(1760000000), relayed.Vote.SignedAt)
+		assert.Equal(t, strings.Repeat("ab", 32), relayed.Vote.Nonce)
+		require.NoError(t, queue.MarkFailed(relayed.ID, "test"))
+
+		require.NoError(t, queue.MarkSyncing(first.ID))
+		require.NoError(t, queue.MarkSynced(first.ID, "0xtx"))
+		count, err := queue.Count()
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+}
+
+func TestPendingVoteRepositoryRetryAndRecovery(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB) {
+		queue := NewPendingVoteRepository(db)
+		first, err := queue.Enqueue(blockchain.VoteData{VerificationHash: "h1", PollingUnitID: "PU001", CandidateID: "C1"})
+		require.NoError(t, err)
+		second, err := queue.Enqueue(blockchain.VoteData{VerificationHash: "h2", PollingUnitID: "PU001", CandidateID: "C2"})
+		require.NoError(t, err)
+
+		// Claimed votes are no longer due
+		require.NoError(t, queue.MarkSyncing(first.ID))
+		due, err := queue.Due(time.Now().Add(time.Second), 10)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		assert.Equal(t, second.ID, due[0].ID)
+
+		// Retries wait for their next retry time
+		retryAt := time.Now().UTC().Add(time.Hour)
+		require.NoError(t, queue.MarkRetry(first.ID, "timeout", retryAt))
+		due, err = queue.Due(time.Now().Add(time.Second), 10)
+		require.NoError(t, err)
+		assert.Len(t, due, 1)
+		due, err = queue.Due(retryAt.Add(time.Second), 10)
+		require.NoError(t, err)
+		require.Len(t, due, 2)
+		assert.Equal(t, first.ID, due[1].ID, "due votes are ordered by retry time")
+		assert.Equal(t, 1, due[1].Attempts)
+		assert.Equal(t, "timeout", due[1].LastError)
+
+		// A restarted server recovers votes it had claimed, keeping their
+		// attempts and submitted transaction
+		require.NoError(t, queue.MarkSyncing(second.ID))
+		require.NoError(t, queue.MarkSubmitted(second.ID, "0xtx"))
+		restarted := NewPendingVoteRepository(db)
+		recovered, err := restarted.Recover()
+		require.NoError(t, err)
+		assert.Equal(t, 1, recovered)
+		pending, err := restarted.List()
+		require.NoError(t, err)
+		require.Len(t, pending, 2)
+		assert.Equal(t, blockchain.QueueStatusPending, pending[1].Status)
+		assert.Equal(t, 1, pending[1].Attempts)
+		assert.Equal(t, "0xtx", pending[1].TxHash)
+
+		cleared, err := restarted.Clear()
+		require.NoError(t, err)
+		assert.Equal(t, 2, cleared)
+		count, err := restarted.Count()
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+	"voting-system/internal/database"
+)
+
+type SessionRepository struct {
+	db *database.DB
+}
+
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{db: database.Wrap(db)}
+}
+
+// Create stores a new session
+func (r *SessionRepository) Create(session *database.Session) error {
+	query := `
+        INSERT INTO sessions (id, user_id, data, expires_at, created_at)
+        VALUES (?, ?, ?, ?, ?)
+    `
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = time.Now().UTC()
+	}
+	_, err := r.db.Exec(query, session.ID, session.UserID, session.Data,
+		session.ExpiresAt.UTC(), session.CreatedAt.UTC())
+	return err
+}
+
+// GetByID retrieves a session that has not expired
+func (r *SessionRepository) GetByID(id string) (*database.Session, error) {
+	query := `
+        SELECT id, user_id, data, expires_at, created_at
+        FROM sessions
+        WHERE id = ? AND expires_at > ?
+    `
+
+	var session database.Session
+	err := r.db.QueryRow(query, id, time.Now().UTC()).Scan(
+		&session.ID, &session.UserID, &session.Data, &session.ExpiresAt, &session.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// ListByUser returns the user's active sessions, newest first
+func (r *SessionRepository) ListByUser(userID int64) ([]database.Session, error) {
+	query := `
+        SELECT id, user_id, data, expires_at, created_at
+        FROM sessions
+        WHERE user_id = ? AND expires_at > ?
+        ORDER BY created_at DESC
+    `
+
+	rows, err := r.db.Query(query, userID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []database.Session
+	for rows.Next() {
+		var session database.Session
+		err := rows.Scan(&session.ID, &session.UserID, &session.Data, &session.ExpiresAt, &session.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+// Update replaces a session's data and expiry, used when rotating refresh tokens
+func (r *SessionRepository) Update(id, data string, expiresAt time.Time) error {
+	query := `UPDATE sessions SET data = ?, expires_at = ? WHERE id = ?`
+	_, err := r.db.Exec(query, data, expiresAt.UTC(), id)
+	return err
+}
+
+// Delete revokes a single session
+func (r *SessionRepository) Delete(id string) error {
+	_, err := r.db.Exec("DELETE FROM sessions WHERE id = ?", id)
+	return err
+}
+
+// DeleteByUser revokes all of a user's sessions and returns how many were removed
+func (r *SessionRepository) DeleteByUser(userID int64) (int64, error) {
+	result, err := r.db.Exec("DELETE FROM sessions WHERE user_id = ?", userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// DeleteExpired removes sessions past their expiry
+func (r *SessionRepository) DeleteExpired() (int64, error) {
+	result, err := r.db.Exec("DELETE FROM sessions WHERE expires_at <= ?", time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+	"voting-system/internal/database"
+)
+
+// TerminalAlertRepository stores fleet alerts raised for terminals
+type TerminalAlertRepository struct {
+	db *database.DB
+}
+
+func NewTerminalAlertRepository(db *sql.DB) *TerminalAlertRepository {
+	return &TerminalAlertRepository{db: database.Wrap(db)}
+}
+
+const terminalAlertColumns = `id, device_id, kind, message, raised_at, resolved_at`
+
+// Raise opens an alert of kind for a terminal unless one is already open.
+// It reports whether a new alert was opened.
+func (r *TerminalAlertRepository) Raise(deviceID, kind, message string) (bool, error) {
+	var id int64
+	err := r.db.QueryRow(`
+        SELECT id FROM terminal_alerts
+        WHERE device_id = ? AND kind = ? AND resolved_at IS NULL
+    `, deviceID, kind).Scan(&id)
+	if err == nil {
+		return false, nil
+	}
+	if err != sql.ErrNoRows {
+		return false, err
+	}
+
+	_, err = r.db.Exec(`
+        INSERT INTO terminal_alerts (device_id, kind, message, raised_at)
+        VALUES (?, ?, ?, ?)
+    `, deviceID, kind, message, time.Now().UTC())
+	return err == nil, err
+}
+
+// Resolve closes the terminal's open alert of kind, reporting whether there was one
+func (r *TerminalAlertRepository) Resolve(deviceID, kind string) (bool, error) {
+	result, err := r.db.Exec(`
+        UPDATE terminal_alerts SET resolved_at = ?
+        WHERE device_id = ? AND kind = ? AND resolved_at IS NULL
+    `, time.Now().UTC(), deviceID, kind)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// ResolveAll closes every open alert of a terminal
+func (r *TerminalAlertRepository) ResolveAll(deviceID string) (int64, error) {
+	result, err := r.db.Exec(`
+        UPDATE terminal_alerts SET resolved_at = ?
+        WHERE device_id = ? AND resolved_at IS NULL
+    `, time.Now().UTC(), deviceID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// List returns alerts, newest first, optionally for one terminal and only open ones
+func (r *TerminalAlertRepository) List(deviceID string, openOnly bool, limit, offset int) ([]database.TerminalAlert, error) {
+	query := `SELECT ` + terminalAlertColumns + ` FROM terminal_alerts WHERE 1=1`
+	args := []interface{}{}
+
+	if deviceID != "" {
+		query += " AND device_id = ?"
+		args = append(args, deviceID)
+	}
+	if openOnly {
+		query += " AND resolved_at IS NULL"
+	}
+
+	query += " ORDER BY raised_at DESC, id DESC LIMIT ? OFFSET ?"
+	args = append(args, limit, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := []database.TerminalAlert{}
+	for rows.Next() {
+		var a database.TerminalAlert
+		if err := rows.Scan(&a.ID, &a.DeviceID, &a.Kind, &a.Message, &a.RaisedAt, &a.ResolvedAt); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, rows.Err()
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+	"voting-system/internal/database"
+)
+
+// TerminalConfigRepository stores admins' per-terminal configuration overrides
+type TerminalConfigRepository struct {
+	db *database.DB
+}
+
+func NewTerminalConfigRepository(db *sql.DB) *TerminalConfigRepository {
+	return &TerminalConfigRepository{db: database.Wrap(db)}
+}
+
+// Get returns a terminal's overrides, or sql.ErrNoRows if it has none
+func (r *TerminalConfigRepository) Get(deviceID string) (*database.TerminalSettings, error) {
+	var s database.TerminalSettings
+	var updatedBy sql.NullString
+	err := r.db.QueryRow(`
+        SELECT device_id, settings, updated_by, updated_at
+        FROM terminal_configs
+        WHERE device_id = ?
+    `, deviceID).Scan(&s.DeviceID, &s.Settings, &updatedBy, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	s.UpdatedBy = updatedBy.String
+	return &s, nil
+}
+
+// Save replaces a terminal's overrides
+func (r *TerminalConfigRepository) Save(deviceID, settings, updatedBy string) error {
+	now := time.Now().UTC()
+	_, err := r.db.Exec(`
+        INSERT INTO terminal_configs (device_id, settings, updated_by, updated_at)
+        VALUES (?, ?, ?, ?)
+        ON CONFLICT(device_id) DO UPDATE SET
+            settings = excluded.settings, updated_by = excluded.updated_by, updated_at = excluded.updated_at
+    `, deviceID, settings, updatedBy, now)
+	return err
+}
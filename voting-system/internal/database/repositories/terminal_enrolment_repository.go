@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+	"voting-system/internal/database"
+)
+
+// TerminalEnrolmentRepository stores one-time terminal enrolment codes
+type TerminalEnrolmentRepository struct {
+	db *database.DB
+}
+
+func NewTerminalEnrolmentRepository(db *sql.DB) *TerminalEnrolmentRepository {
+	return &TerminalEnrolmentRepository{db: database.Wrap(db)}
+}
+
+// IssueCode stores a new enrolment code
+func (r *TerminalEnrolmentRepository) IssueCode(code *database.TerminalEnrolmentCode) error {
+	if code.CreatedAt.IsZero() {
+		code.CreatedAt = time.Now().UTC()
+	}
+	query := `
+        INSERT INTO terminal_enrolment_codes (code_hash, polling_unit_id, terminal_id, issued_by, expires_at, created_at)
+        VALUES (?, ?, ?, ?, ?, ?)
+    `
+	_, err := r.db.Exec(query, code.CodeHash, code.PollingUnitID, code.TerminalID, code.IssuedBy,
+		code.ExpiresAt.UTC(), code.CreatedAt.UTC())
+	return err
+}
+
+// Enrol consumes an enrolment code and stores the terminal as pending. A
+// revoked terminal is replaced; any other existing terminal is left alone.
+// It returns sql.ErrNoRows, without consuming the code, if the code is
+// unknown, expired, used, or issued for another polling unit or terminal, or
+// if the terminal is already enrolled.
+func (r *TerminalEnrolmentRepository) Enrol(codeHash string, terminal *database.Terminal) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	result, err := tx.Exec(`
+        UPDATE terminal_enrolment_codes
+        SET used_at = ?, used_by = ?
+        WHERE code_hash = ? AND used_at IS NULL AND expires_at > ?
+          AND polling_unit_id = ? AND (terminal_id = '' OR terminal_id = ?)
+    `, now, terminal.ID, codeHash, now, terminal.PollingUnitID, terminal.ID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		if err == nil {
+			err = sql.ErrNoRows
+		}
+		return err
+	}
+
+	terminal.Status = database.TerminalPending
+	terminal.Authorized = false
+	result, err = tx.Exec(`
+        INSERT INTO terminals (id, name, location, polling_unit_id, eth_address, public_key, status, authorized)
+        VALUES (?, ?, ?, ?, ?, ?, ?, FALSE)
+        ON CONFLICT (id) DO UPDATE SET
+            name = excluded.name, location = excluded.location, polling_unit_id = excluded.polling_unit_id,
+            eth_address = excluded.eth_address, public_key = excluded.public_key, status = excluded.status,
+            authorized = FALSE, approved_by = '', approved_at = NULL, updated_at = CURRENT_TIMESTAMP
+        WHERE terminals.status = ?
+    `, terminal.ID, terminal.Name, terminal.Location, terminal.PollingUnitID, terminal.EthAddress,
+		terminal.PublicKey, terminal.Status, database.TerminalRevoked)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		if err == nil {
+			err = sql.ErrNoRows
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListCodes returns the codes issued for a polling unit, or for every polling
+// unit when pollingUnitID is empty, newest first
+func (r *TerminalEnrolmentRepository) ListCodes(pollingUnitID string, limit, offset int) ([]database.TerminalEnrolmentCode, error) {
+	query := `
+        SELECT code_hash, polling_unit_id, terminal_id, issued_by, expires_at, used_at, used_by, created_at
+        FROM terminal_enrolment_codes
+    `
+	args := []interface{}{}
+	if pollingUnitID != "" {
+		query += " WHERE polling_unit_id = ?"
+		args = append(args, pollingUnitID)
+	}
+	query += " ORDER BY created_at DESC LIMIT ? OFFSET ?"
+	args = append(args, limit, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var codes []database.TerminalEnrolmentCode
+	for rows.Next() {
+		var code database.TerminalEnrolmentCode
+		if err := rows.Scan(&code.CodeHash, &code.PollingUnitID, &code.TerminalID, &code.IssuedBy,
+			&code.ExpiresAt, &code.UsedAt, &code.UsedBy, &code.CreatedAt); err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, rows.Err()
+}
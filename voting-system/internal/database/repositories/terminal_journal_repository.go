@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+	"voting-system/internal/database"
+)
+
+// TerminalJournalRepository stores the vote journals uploaded by terminals
+type TerminalJournalRepository struct {
+	db *database.DB
+}
+
+func NewTerminalJournalRepository(db *sql.DB) *TerminalJournalRepository {
+	return &TerminalJournalRepository{db: database.Wrap(db)}
+}
+
+const terminalJournalColumns = `id, device_id, seq, prev_hash, entry_hash, COALESCE(signature, ''),
+        verification_hash, COALESCE(polling_unit_id, ''), status, COALESCE(response_code, 0),
+        COALESCE(error, ''), COALESCE(transaction_hash, ''), recorded_at, received_at`
+
+func scanTerminalJournalEntry(row rowScanner, extra ...interface{}) (*database.TerminalJournalEntry, error) {
+	var e database.TerminalJournalEntry
+	dest := append([]interface{}{&e.ID, &e.DeviceID, &e.Seq, &e.PrevHash, &e.EntryHash, &e.Signature,
+		&e.VerificationHash, &e.PollingUnitID, &e.Status, &e.ResponseCode, &e.Error, &e.TransactionHash,
+		&e.RecordedAt, &e.ReceivedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Record stores a received entry. It fails if the terminal already has an entry with the same seq.
+func (r *TerminalJournalRepository) Record(entry *database.TerminalJournalEntry) error {
+	entry.ReceivedAt = time.Now().UTC()
+	id, err := r.db.InsertReturningID(`
+        INSERT INTO terminal_journal (device_id, seq, prev_hash, entry_hash, signature, verification_hash,
+                                      polling_unit_id, status, response_code, error, transaction_hash,
+                                      recorded_at, received_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `, entry.DeviceID, entry.Seq, entry.PrevHash, entry.EntryHash, entry.Signature, entry.VerificationHash,
+		entry.PollingUnitID, entry.Status, entry.ResponseCode, entry.Error, entry.TransactionHash,
+		entry.RecordedAt, entry.ReceivedAt)
+	if err != nil {
+		return err
+	}
+
+	entry.ID = id
+	return nil
+}
+
+// Get returns a terminal's entry by sequence number, or sql.ErrNoRows
+func (r *TerminalJournalRepository) Get(deviceID string, seq int64) (*database.TerminalJournalEntry, error) {
+	return scanTerminalJournalEntry(r.db.QueryRow(`SELECT `+terminalJournalColumns+` FROM terminal_journal
+        WHERE device_id = ? AND seq = ?`, deviceID, seq))
+}
+
+// Last returns a terminal's latest entry, or sql.ErrNoRows if it has uploaded none
+func (r *TerminalJournalRepository) Last(deviceID string) (*database.TerminalJournalEntry, error) {
+	return scanTerminalJournalEntry(r.db.QueryRow(`SELECT `+terminalJournalColumns+` FROM terminal_journal
+        WHERE device_id = ? ORDER BY seq DESC LIMIT 1`, deviceID))
+}
+
+// Reconcile returns a terminal's entries in chain order, each with the vote it
+// produced. Votes queued while the chain was down are only in pending_votes.
+func (r *TerminalJournalRepository) Reconcile(deviceID string) ([]database.JournalReconciliation, error) {
+	rows, err := r.db.Query(`
+        SELECT j.id, j.device_id, j.seq, j.prev_hash, j.entry_hash, COALESCE(j.signature, ''),
+               j.verification_hash, COALESCE(j.polling_unit_id, ''), j.status, COALESCE(j.response_code, 0),
+               COALESCE(j.error, ''), COALESCE(j.transaction_hash, ''), j.recorded_at, j.received_at,
+               COALESCE(v.status, p.status), COALESCE(v.transaction_hash, NULLIF(p.tx_hash, '')),
+               v.block_number, v.synced_at
+        FROM terminal_journal j
+        LEFT JOIN votes v ON v.verification_hash = j.verification_hash
+        LEFT JOIN pending_votes p ON p.verification_hash = j.verification_hash
+        WHERE j.device_id = ?
+        ORDER BY j.seq ASC
+    `, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []database.JournalReconciliation
+	for rows.Next() {
+		var rec database.JournalReconciliation
+		entry, err := scanTerminalJournalEntry(rows, &rec.VoteStatus, &rec.VoteTxHash, &rec.BlockNumber, &rec.SyncedAt)
+		if err != nil {
+			return nil, err
+		}
+		rec.TerminalJournalEntry = *entry
+		entries = append(entries, rec)
+	}
+
+	return entries, rows.Err()
+}
+
+// VotedHashes returns the verification hashes of everyone who has voted at a
+// polling unit in an election, including votes still queued for the chain
+func (r *TerminalJournalRepository) VotedHashes(pollingUnitID string, electionID int64) ([]string, error) {
+	rows, err := r.db.Query(`
+        SELECT verification_hash FROM votes WHERE polling_unit_id = ? AND election_id = ?
+        UNION
+        SELECT verification_hash FROM pending_votes WHERE polling_unit_id = ? AND status <> 'failed'
+    `, pollingUnitID, electionID, pollingUnitID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hashes := []string{}
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, rows.Err()
+}
@@ -2,18 +2,25 @@ package repositories
 
 import (
 	"database/sql"
+	"strings"
 	"time"
 	"voting-system/internal/database"
 )
 
 type TerminalRepository struct {
-	db *sql.DB
+	db *database.DB
 }
 
 func NewTerminalRepository(db *sql.DB) *TerminalRepository {
-	return &TerminalRepository{db: db}
+	return &TerminalRepository{db: database.Wrap(db)}
 }
 
+const terminalColumns = `
+        id, name, location, polling_unit_id, eth_address, public_key,
+        status, authorized, last_heartbeat, approved_by, approved_at, created_at, updated_at,
+        online, battery_level, charging, firmware_version, queue_depth, clock_skew_ms
+`
+
 // RegisterTerminal registers a new terminal
 func (r *TerminalRepository) RegisterTerminal(terminal *database.Terminal) error {
 	query := `
@@ -39,70 +46,65 @@ func (r *TerminalRepository) UpdateTerminalHeartbeat(terminalID string) error {
 // GetTerminal retrieves terminal information by ID
 func (r *TerminalRepository) GetTerminal(terminalID string) (*database.Terminal, error) {
 	query := `
-        SELECT id, name, location, polling_unit_id, eth_address, public_key, 
-               status, authorized, last_heartbeat, created_at, updated_at
+        SELECT ` + terminalColumns + `
         FROM terminals
         WHERE id = ?
     `
 
-	var terminal database.Terminal
-	err := r.db.QueryRow(query, terminalID).Scan(
-		&terminal.ID, &terminal.Name, &terminal.Location, &terminal.PollingUnitID,
-		&terminal.EthAddress, &terminal.PublicKey, &terminal.Status, &terminal.Authorized,
-		&terminal.LastHeartbeat, &terminal.CreatedAt, &terminal.UpdatedAt,
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	return &terminal, nil
+	return scanTerminal(r.db.QueryRow(query, terminalID))
 }
 
 // ListTerminals retrieves all terminals with optional filtering
 func (r *TerminalRepository) ListTerminals(status, pollingUnitID string, limit, offset int) ([]database.Terminal, error) {
-	query := `
-        SELECT id, name, location, polling_unit_id, eth_address, public_key, 
-               status, authorized, last_heartbeat, created_at, updated_at
-        FROM terminals
-        WHERE 1=1
-    `
+	terminals, _, err := r.FindTerminals(TerminalFilter{Status: status, PollingUnitID: pollingUnitID}, limit, offset)
+	return terminals, err
+}
+
+// TerminalFilter narrows a terminal listing. Empty fields do not filter.
+type TerminalFilter struct {
+	Status        string
+	PollingUnitID string
+	Authorized    *bool
+	Online        *bool
+	Search        string // part of the ID, name or location
+}
+
+// FindTerminals returns a page of the terminals matching filter, newest
+// first, and how many match in total
+func (r *TerminalRepository) FindTerminals(filter TerminalFilter, limit, offset int) ([]database.Terminal, int, error) {
+	where := " WHERE 1=1"
 	args := []interface{}{}
 
-	if status != "" {
-		query += " AND status = ?"
-		args = append(args, status)
+	if filter.Status != "" {
+		where += " AND status = ?"
+		args = append(args, filter.Status)
 	}
-
-	if pollingUnitID != "" {
-		query += " AND polling_unit_id = ?"
-		args = append(args, pollingUnitID)
+	if filter.PollingUnitID != "" {
+		where += " AND polling_unit_id = ?"
+		args = append(args, filter.PollingUnitID)
 	}
-
-	query += " ORDER BY created_at DESC LIMIT ? OFFSET ?"
-	args = append(args, limit, offset)
-
-	rows, err := r.db.Query(query, args...)
-	if err != nil {
-		return nil, err
+	if filter.Authorized != nil {
+		where += " AND authorized = ?"
+		args = append(args, *filter.Authorized)
+	}
+	if filter.Online != nil {
+		where += " AND online = ?"
+		args = append(args, *filter.Online)
+	}
+	if filter.Search != "" {
+		where += " AND (LOWER(id) LIKE ? OR LOWER(name) LIKE ? OR LOWER(location) LIKE ?)"
+		pattern := "%" + strings.ToLower(filter.Search) + "%"
+		args = append(args, pattern, pattern, pattern)
 	}
-	defer rows.Close()
 
-	var terminals []database.Terminal
-	for rows.Next() {
-		var terminal database.Terminal
-		err := rows.Scan(
-			&terminal.ID, &terminal.Name, &terminal.Location, &terminal.PollingUnitID,
-			&terminal.EthAddress, &terminal.PublicKey, &terminal.Status, &terminal.Authorized,
-			&terminal.LastHeartbeat, &terminal.CreatedAt, &terminal.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		terminals = append(terminals, terminal)
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM terminals"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
 	}
 
-	return terminals, nil
+	query := "SELECT " + terminalColumns + " FROM terminals" + where + " ORDER BY created_at DESC, id LIMIT ? OFFSET ?"
+	terminals, err := r.queryTerminals(query, append(args, limit, offset)...)
+	return terminals, total, err
 }
 
 // UpdateTerminalStatus updates the status of a terminal
@@ -112,65 +114,130 @@ func (r *TerminalRepository) UpdateTerminalStatus(terminalID, status string) err
 	return err
 }
 
-// AuthorizeTerminal authorizes a terminal for voting
+// AuthorizeTerminal authorizes a terminal for voting, making it active
 func (r *TerminalRepository) AuthorizeTerminal(terminalID string) error {
-	query := `UPDATE terminals SET authorized = true, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
-	_, err := r.db.Exec(query, terminalID)
-	return err
+	return r.SetAuthorization(terminalID, true)
 }
 
-// DeauthorizeTerminal deauthorizes a terminal
+// DeauthorizeTerminal deauthorizes a terminal, revoking it
 func (r *TerminalRepository) DeauthorizeTerminal(terminalID string) error {
-	query := `UPDATE terminals SET authorized = false, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
-	_, err := r.db.Exec(query, terminalID)
+	return r.SetAuthorization(terminalID, false)
+}
+
+// SetAuthorization sets a terminal's authorized flag and the matching status,
+// active or revoked
+func (r *TerminalRepository) SetAuthorization(terminalID string, authorized bool) error {
+	status := database.TerminalRevoked
+	if authorized {
+		status = database.TerminalActive
+	}
+	query := `UPDATE terminals SET authorized = ?, status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+	_, err := r.db.Exec(query, authorized, status, terminalID)
+	return err
+}
+
+// ApproveTerminal records an admin's approval of a pending terminal. It
+// returns false if the terminal is not pending.
+func (r *TerminalRepository) ApproveTerminal(terminalID, approvedBy string) (bool, error) {
+	result, err := r.db.Exec(`
+        UPDATE terminals
+        SET status = ?, approved_by = ?, approved_at = ?, updated_at = CURRENT_TIMESTAMP
+        WHERE id = ? AND status = ?
+    `, database.TerminalApproved, approvedBy, time.Now().UTC(), terminalID, database.TerminalPending)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// DecommissionTerminal retires a terminal. The row is kept so its votes and
+// audit trail still resolve.
+func (r *TerminalRepository) DecommissionTerminal(terminalID string) error {
+	query := `
+        UPDATE terminals
+        SET status = ?, authorized = FALSE, online = FALSE, updated_at = CURRENT_TIMESTAMP
+        WHERE id = ?
+    `
+	_, err := r.db.Exec(query, database.TerminalDecommissioned, terminalID)
 	return err
 }
 
 // GetTerminalsByPollingUnit gets all terminals for a specific polling unit
 func (r *TerminalRepository) GetTerminalsByPollingUnit(pollingUnitID string) ([]database.Terminal, error) {
 	query := `
-        SELECT id, name, location, polling_unit_id, eth_address, public_key, 
-               status, authorized, last_heartbeat, created_at, updated_at
+        SELECT ` + terminalColumns + `
         FROM terminals
         WHERE polling_unit_id = ?
         ORDER BY created_at DESC
     `
 
-	rows, err := r.db.Query(query, pollingUnitID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var terminals []database.Terminal
-	for rows.Next() {
-		var terminal database.Terminal
-		err := rows.Scan(
-			&terminal.ID, &terminal.Name, &terminal.Location, &terminal.PollingUnitID,
-			&terminal.EthAddress, &terminal.PublicKey, &terminal.Status, &terminal.Authorized,
-			&terminal.LastHeartbeat, &terminal.CreatedAt, &terminal.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		terminals = append(terminals, terminal)
-	}
-
-	return terminals, nil
+	return r.queryTerminals(query, pollingUnitID)
 }
 
 // GetOfflineTerminals gets terminals that haven't sent a heartbeat recently
 func (r *TerminalRepository) GetOfflineTerminals(timeoutMinutes int) ([]database.Terminal, error) {
 	query := `
-        SELECT id, name, location, polling_unit_id, eth_address, public_key, 
-               status, authorized, last_heartbeat, created_at, updated_at
+        SELECT ` + terminalColumns + `
         FROM terminals
         WHERE last_heartbeat < ? OR last_heartbeat IS NULL
         ORDER BY last_heartbeat ASC
     `
 
 	timeout := time.Now().Add(-time.Duration(timeoutMinutes) * time.Minute)
-	rows, err := r.db.Query(query, timeout)
+	return r.queryTerminals(query, timeout)
+}
+
+// RecordHeartbeat stores a terminal's health report and marks it online
+func (r *TerminalRepository) RecordHeartbeat(terminalID string, health database.TerminalHealth) error {
+	query := `
+        UPDATE terminals
+        SET last_heartbeat = ?, online = TRUE, battery_level = ?, charging = ?, firmware_version = ?,
+            queue_depth = ?, clock_skew_ms = ?, updated_at = CURRENT_TIMESTAMP
+        WHERE id = ?
+    `
+	result, err := r.db.Exec(query, time.Now().UTC(), health.BatteryLevel, health.Charging,
+		health.FirmwareVersion, health.QueueDepth, health.ClockSkewMs, terminalID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MarkOffline marks online terminals that have been silent since before
+// cutoff as offline and returns them
+func (r *TerminalRepository) MarkOffline(cutoff time.Time) ([]database.Terminal, error) {
+	silent, err := r.queryTerminals(`SELECT `+terminalColumns+`
+        FROM terminals
+        WHERE online = TRUE AND (last_heartbeat < ? OR last_heartbeat IS NULL)
+        ORDER BY last_heartbeat ASC`, cutoff.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	var marked []database.Terminal
+	for _, terminal := range silent {
+		// A heartbeat may have arrived since the terminal was selected
+		result, err := r.db.Exec(`
+            UPDATE terminals SET online = FALSE, updated_at = CURRENT_TIMESTAMP
+            WHERE id = ? AND online = TRUE AND (last_heartbeat < ? OR last_heartbeat IS NULL)
+        `, terminal.ID, cutoff.UTC())
+		if err != nil {
+			return marked, err
+		}
+		if affected, err := result.RowsAffected(); err == nil && affected > 0 {
+			terminal.Online = false
+			marked = append(marked, terminal)
+		}
+	}
+	return marked, nil
+}
+
+func (r *TerminalRepository) queryTerminals(query string, args ...interface{}) ([]database.Terminal, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -178,17 +245,28 @@ func (r *TerminalRepository) GetOfflineTerminals(timeoutMinutes int) ([]database
 
 	var terminals []database.Terminal
 	for rows.Next() {
-		var terminal database.Terminal
-		err := rows.Scan(
-			&terminal.ID, &terminal.Name, &terminal.Location, &terminal.PollingUnitID,
-			&terminal.EthAddress, &terminal.PublicKey, &terminal.Status, &terminal.Authorized,
-			&terminal.LastHeartbeat, &terminal.CreatedAt, &terminal.UpdatedAt,
-		)
+		terminal, err := scanTerminal(rows)
 		if err != nil {
 			return nil, err
 		}
-		terminals = append(terminals, terminal)
+		terminals = append(terminals, *terminal)
 	}
 
-	return terminals, nil
+	return terminals, rows.Err()
+}
+
+func scanTerminal(row rowScanner) (*database.Terminal, error) {
+	var terminal database.Terminal
+	err := row.Scan(
+		&terminal.ID, &terminal.Name, &terminal.Location, &terminal.PollingUnitID,
+		&terminal.EthAddress, &terminal.PublicKey, &terminal.Status, &terminal.Authorized,
+		&terminal.LastHeartbeat, &terminal.ApprovedBy, &terminal.ApprovedAt, &terminal.CreatedAt, &terminal.UpdatedAt,
+		&terminal.Online, &terminal.BatteryLevel, &terminal.Charging, &terminal.FirmwareVersion,
+		&terminal.QueueDepth, &terminal.ClockSkewMs,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &terminal, nil
 }
@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+	"voting-system/internal/database"
+)
+
+// TerminalSecretRepository stores terminals' sealed HMAC secrets and the
+// nonces of their token requests
+type TerminalSecretRepository struct {
+	db *database.DB
+}
+
+func NewTerminalSecretRepository(db *sql.DB) *TerminalSecretRepository {
+	return &TerminalSecretRepository{db: database.Wrap(db)}
+}
+
+const terminalSecretColumns = `id, terminal_id, secret_sealed, created_by, created_at, expires_at, revoked_at`
+
+func scanTerminalSecrets(rows *sql.Rows) ([]database.TerminalSecret, error) {
+	defer rows.Close()
+
+	var secrets []database.TerminalSecret
+	for rows.Next() {
+		var secret database.TerminalSecret
+		if err := rows.Scan(&secret.ID, &secret.TerminalID, &secret.SecretSealed, &secret.CreatedBy,
+			&secret.CreatedAt, &secret.ExpiresAt, &secret.RevokedAt); err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, secret)
+	}
+	return secrets, rows.Err()
+}
+
+// Rotate stores a new secret for a terminal. Its current secrets stay valid
+// for grace, so the terminal can keep signing in until it is reconfigured; it
+// returns how many there were.
+func (r *TerminalSecretRepository) Rotate(terminalID, sealed, createdBy string, grace time.Duration) (*database.TerminalSecret, int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(grace)
+	result, err := tx.Exec(`
+        UPDATE terminal_secrets SET expires_at = ?
+        WHERE terminal_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)
+    `, expiresAt, terminalID, expiresAt)
+	if err != nil {
+		return nil, 0, err
+	}
+	previous, err := result.RowsAffected()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	secret := &database.TerminalSecret{TerminalID: terminalID, SecretSealed: sealed, CreatedBy: createdBy, CreatedAt: now}
+	secret.ID, err = tx.InsertReturningID(`
+        INSERT INTO terminal_secrets (terminal_id, secret_sealed, created_by, created_at)
+        VALUES (?, ?, ?, ?)
+    `, terminalID, sealed, createdBy, now)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return secret, previous, tx.Commit()
+}
+
+// Active returns a terminal's secrets that are neither revoked nor expired,
+// newest first
+func (r *TerminalSecretRepository) Active(terminalID string) ([]database.TerminalSecret, error) {
+	rows, err := r.db.Query(`
+        SELECT `+terminalSecretColumns+`
+        FROM terminal_secrets
+        WHERE terminal_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)
+        ORDER BY created_at DESC, id DESC
+    `, terminalID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return scanTerminalSecrets(rows)
+}
+
+// Unrevoked returns a terminal's secrets that have not been revoked, expired
+// ones included, newest first. Journal entries signed before a rotation are
+// still verified with them.
+func (r *TerminalSecretRepository) Unrevoked(terminalID string) ([]database.TerminalSecret, error) {
+	rows, err := r.db.Query(`
+        SELECT `+terminalSecretColumns+`
+        FROM terminal_secrets
+        WHERE terminal_id = ? AND revoked_at IS NULL
+        ORDER BY created_at DESC, id DESC
+    `, terminalID)
+	if err != nil {
+		return nil, err
+	}
+	return scanTerminalSecrets(rows)
+}
+
+// Revoke revokes all of a terminal's secrets and returns how many were still
+// unrevoked
+func (r *TerminalSecretRepository) Revoke(terminalID string) (int64, error) {
+	result, err := r.db.Exec(`
+        UPDATE terminal_secrets SET revoked_at = ?
+        WHERE terminal_id = ? AND revoked_at IS NULL
+    `, time.Now().UTC(), terminalID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// UseNonce records the nonce of a terminal's token request and reports
+// whether it was fresh. Nonces signed before expireBefore are forgotten
+// first; requests that old are refused on their timestamp instead.
+func (r *TerminalSecretRepository) UseNonce(terminalID, nonce string, signedAt, expireBefore time.Time) (bool, error) {
+	if _, err := r.db.Exec(`DELETE FROM terminal_token_nonces WHERE signed_at < ?`, expireBefore.UTC()); err != nil {
+		return false, err
+	}
+
+	result, err := r.db.Exec(`
+        INSERT INTO terminal_token_nonces (device_id, nonce, signed_at)
+        VALUES (?, ?, ?)
+        ON CONFLICT (device_id, nonce) DO NOTHING
+    `, terminalID, nonce, signedAt.UTC())
+	if err != nil {
+		return false, err
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return inserted == 1, nil
+}
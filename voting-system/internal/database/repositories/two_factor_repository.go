@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+	"voting-system/internal/database"
+)
+
+type TwoFactorRepository struct {
+	db *database.DB
+}
+
+func NewTwoFactorRepository(db *sql.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: database.Wrap(db)}
+}
+
+// Get returns a user's enrolment, or sql.ErrNoRows if they have none
+func (r *TwoFactorRepository) Get(userID int64) (*database.TwoFactor, error) {
+	query := `
+        SELECT user_id, secret, enabled, last_used_step, enabled_at, created_at
+        FROM user_two_factor
+        WHERE user_id = ?
+    `
+
+	var tf database.TwoFactor
+	err := r.db.QueryRow(query, userID).Scan(
+		&tf.UserID, &tf.Secret, &tf.Enabled, &tf.LastUsedStep, &tf.EnabledAt, &tf.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tf, nil
+}
+
+// IsEnabled reports whether a user has confirmed 2FA
+func (r *TwoFactorRepository) IsEnabled(userID int64) (bool, error) {
+	tf, err := r.Get(userID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return tf.Enabled, nil
+}
+
+// SavePending stores a new unconfirmed secret, replacing any earlier unconfirmed one
+func (r *TwoFactorRepository) SavePending(userID int64, sealedSecret string) error {
+	query := `
+        INSERT INTO user_two_factor (user_id, secret, enabled, last_used_step, created_at)
+        VALUES (?, ?, false, 0, ?)
+        ON CONFLICT(user_id) DO UPDATE SET
+            secret = excluded.secret,
+            last_used_step = 0,
+            created_at = excluded.created_at
+        WHERE user_two_factor.enabled = false
+    `
+	_, err := r.db.Exec(query, userID, sealedSecret, time.Now().UTC())
+	return err
+}
+
+// Enable confirms the enrolment and records the step of the confirming code
+func (r *TwoFactorRepository) Enable(userID, step int64) error {
+	query := `
+        UPDATE user_two_factor
+        SET enabled = true, enabled_at = ?, last_used_step = ?
+        WHERE user_id = ?
+    `
+	_, err := r.db.Exec(query, time.Now().UTC(), step, userID)
+	return err
+}
+
+// UseStep records a verified code's time step. It returns false if that step
+// or a later one was already used, so each code is accepted only once.
+func (r *TwoFactorRepository) UseStep(userID, step int64) (bool, error) {
+	result, err := r.db.Exec(`
+        UPDATE user_two_factor
+        SET last_used_step = ?
+        WHERE user_id = ? AND last_used_step < ?
+    `, step, userID, step)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// Delete removes a user's enrolment and recovery codes
+func (r *TwoFactorRepository) Delete(userID int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM recovery_codes WHERE user_id = ?", userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM user_two_factor WHERE user_id = ?", userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReplaceRecoveryCodes discards a user's recovery codes and stores new digests
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(userID int64, codeHashes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM recovery_codes WHERE user_id = ?", userID); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for _, hash := range codeHashes {
+		_, err := tx.Exec("INSERT INTO recovery_codes (user_id, code_hash, created_at) VALUES (?, ?, ?)",
+			userID, hash, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UseRecoveryCode marks an unused recovery code as used. It returns false if
+// the code does not exist or was already used.
+func (r *TwoFactorRepository) UseRecoveryCode(userID int64, codeHash string) (bool, error) {
+	result, err := r.db.Exec(`
+        UPDATE recovery_codes
+        SET used_at = ?
+        WHERE user_id = ? AND code_hash = ? AND used_at IS NULL
+    `, time.Now().UTC(), userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// CountRecoveryCodes returns how many unused recovery codes a user has left
+func (r *TwoFactorRepository) CountRecoveryCodes(userID int64) (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM recovery_codes WHERE user_id = ? AND used_at IS NULL",
+		userID).Scan(&count)
+	return count, err
+}
@@ -6,11 +6,11 @@ import (
 )
 
 type UserRepository struct {
-	db *sql.DB
+	db *database.DB
 }
 
 func NewUserRepository(db *sql.DB) *UserRepository {
-	return &UserRepository{db: db}
+	return &UserRepository{db: database.Wrap(db)}
 }
 
 func (r *UserRepository) Create(user *database.User) error {
@@ -18,17 +18,12 @@ func (r *UserRepository) Create(user *database.User) error {
         INSERT INTO users (username, email, password_hash, first_name, last_name, role, permissions)
         VALUES (?, ?, ?, ?, ?, ?, ?)
     `
-	result, err := r.db.Exec(query, user.Username, user.Email, user.PasswordHash,
+	id, err := r.db.InsertReturningID(query, user.Username, user.Email, user.PasswordHash,
 		user.FirstName, user.LastName, user.Role, user.Permissions)
 	if err != nil {
 		return err
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return err
-	}
-
 	user.ID = id
 	return nil
 }
@@ -223,3 +218,17 @@ func (r *UserRepository) GetUsersByRole(role string) ([]database.User, error) {
 
 	return users, nil
 }
+
+// CountUsers returns the total number of users
+func (r *UserRepository) CountUsers() (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count)
+	return count, err
+}
+
+// CountActiveByRole returns the number of active users with a role
+func (r *UserRepository) CountActiveByRole(role string) (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM users WHERE role = ? AND is_active = true", role).Scan(&count)
+	return count, err
+}
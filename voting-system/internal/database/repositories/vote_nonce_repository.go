@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+	"voting-system/internal/database"
+)
+
+// VoteNonceRepository remembers the nonces of terminal-signed votes so a
+// signed vote cannot be submitted twice
+type VoteNonceRepository struct {
+	db *database.DB
+}
+
+func NewVoteNonceRepository(db *sql.DB) *VoteNonceRepository {
+	return &VoteNonceRepository{db: database.Wrap(db)}
+}
+
+// Use records a terminal's nonce and reports whether it was fresh. Nonces
+// signed before expireBefore are forgotten first; votes that old are refused
+// on their timestamp instead.
+func (r *VoteNonceRepository) Use(deviceID, nonce string, signedAt, expireBefore time.Time) (bool, error) {
+	if _, err := r.db.Exec(`DELETE FROM vote_nonces WHERE signed_at < ?`, expireBefore.UTC()); err != nil {
+		return false, err
+	}
+
+	result, err := r.db.Exec(`
+        INSERT INTO vote_nonces (device_id, nonce, signed_at)
+        VALUES (?, ?, ?)
+        ON CONFLICT (device_id, nonce) DO NOTHING
+    `, deviceID, nonce, signedAt.UTC())
+	if err != nil {
+		return false, err
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return inserted == 1, nil
+}
+
+// Release forgets a nonce, so a vote that failed for reasons unrelated to its
+// signature can be resubmitted
+func (r *VoteNonceRepository) Release(deviceID, nonce string) error {
+	_, err := r.db.Exec(`DELETE FROM vote_nonces WHERE device_id = ? AND nonce = ?`, deviceID, nonce)
+	return err
+}
@@ -7,26 +7,25 @@ import (
 )
 
 type VoteRepository struct {
-	db *sql.DB
+	db *database.DB
 }
 
 func NewVoteRepository(db *sql.DB) *VoteRepository {
-	return &VoteRepository{db: db}
+	return &VoteRepository{db: database.Wrap(db)}
 }
 
 func (r *VoteRepository) InsertVote(vote *database.Vote) error {
 	query := `
         INSERT INTO votes (verification_hash, election_id, polling_unit_id, candidate_id, 
-                          encrypted_vote, status)
-        VALUES (?, ?, ?, ?, ?, ?)
+                          encrypted_vote, ballot, terminal_id, status)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
     `
-	result, err := r.db.Exec(query, vote.VerificationHash, vote.ElectionID, vote.PollingUnitID,
-		vote.CandidateID, vote.EncryptedVote, vote.Status)
-	if err != nil {
-		return err
+	var terminalID sql.NullString
+	if vote.TerminalID != "" {
+		terminalID = sql.NullString{String: vote.TerminalID, Valid: true}
 	}
-
-	id, err := result.LastInsertId()
+	id, err := r.db.InsertReturningID(query, vote.VerificationHash, vote.ElectionID, vote.PollingUnitID,
+		vote.CandidateID, vote.EncryptedVote, vote.Ballot, terminalID, vote.Status)
 	if err != nil {
 		return err
 	}
@@ -78,7 +77,7 @@ func (r *VoteRepository) GetPendingVotes() ([]database.Vote, error) {
 func (r *VoteRepository) GetByVerificationHash(hash string) (*database.Vote, error) {
 	query := `
         SELECT id, blockchain_vote_id, verification_hash, election_id, polling_unit_id, 
-               candidate_id, encrypted_vote, transaction_hash, block_number, status, 
+               candidate_id, encrypted_vote, ballot, transaction_hash, block_number, status, 
                created_at, synced_at
         FROM votes
         WHERE verification_hash = ?
@@ -87,7 +86,7 @@ func (r *VoteRepository) GetByVerificationHash(hash string) (*database.Vote, err
 	var vote database.Vote
 	err := r.db.QueryRow(query, hash).Scan(
 		&vote.ID, &vote.BlockchainVoteID, &vote.VerificationHash, &vote.ElectionID,
-		&vote.PollingUnitID, &vote.CandidateID, &vote.EncryptedVote,
+		&vote.PollingUnitID, &vote.CandidateID, &vote.EncryptedVote, &vote.Ballot,
 		&vote.TransactionHash, &vote.BlockNumber, &vote.Status,
 		&vote.CreatedAt, &vote.SyncedAt,
 	)
@@ -99,6 +98,46 @@ func (r *VoteRepository) GetByVerificationHash(hash string) (*database.Vote, err
 	return &vote, nil
 }
 
+// ListBallots returns the encrypted ballots recorded on chain for an election, oldest first
+func (r *VoteRepository) ListBallots(electionID int64) ([]string, error) {
+	query := `
+        SELECT ballot
+        FROM votes
+        WHERE election_id = ? AND ballot IS NOT NULL AND status = 'synced'
+        ORDER BY id ASC
+    `
+	rows, err := r.db.Query(query, electionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ballots []string
+	for rows.Next() {
+		var ballot string
+		if err := rows.Scan(&ballot); err != nil {
+			return nil, err
+		}
+		ballots = append(ballots, ballot)
+	}
+
+	return ballots, rows.Err()
+}
+
+// CountBallots counts an election's encrypted ballots, synced or not
+func (r *VoteRepository) CountBallots(electionID int64) (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM votes WHERE election_id = ? AND ballot IS NOT NULL", electionID).Scan(&count)
+	return count, err
+}
+
+// CountTerminalVotes counts the votes a terminal has submitted since a time
+func (r *VoteRepository) CountTerminalVotes(deviceID string, since time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM votes WHERE terminal_id = ? AND created_at >= ?", deviceID, since.UTC()).Scan(&count)
+	return count, err
+}
+
 // GetElectionResults gets the complete results for an election
 func (r *VoteRepository) GetElectionResults(electionID int64) (map[string]interface{}, error) {
 	// Get total votes cast
@@ -183,11 +222,12 @@ func (r *VoteRepository) GetElectionResults(electionID int64) (map[string]interf
 	}
 
 	// Get voting timeline
+	voteDate := r.db.Dialect().Date("created_at")
 	timelineQuery := `
-        SELECT DATE(created_at) as vote_date, COUNT(*) as vote_count
+        SELECT ` + voteDate + ` as vote_date, COUNT(*) as vote_count
         FROM votes 
         WHERE election_id = ? AND status = 'synced'
-        GROUP BY DATE(created_at)
+        GROUP BY ` + voteDate + `
         ORDER BY vote_date
     `
 	rows, err = r.db.Query(timelineQuery, electionID)
@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+	"voting-system/internal/database"
+)
+
+// VoterImportRepository stores bulk voter import jobs and their row errors
+type VoterImportRepository struct {
+	db *database.DB
+}
+
+func NewVoterImportRepository(db *sql.DB) *VoterImportRepository {
+	return &VoterImportRepository{db: database.Wrap(db)}
+}
+
+// Create stores a new import job
+func (r *VoterImportRepository) Create(job *database.VoterImportJob) error {
+	job.CreatedAt = time.Now().UTC()
+	query := `
+        INSERT INTO voter_import_jobs (id, format, dry_run, status, total_rows, created_by, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?)
+    `
+	_, err := r.db.Exec(query, job.ID, job.Format, job.DryRun, job.Status, job.TotalRows, job.CreatedBy, job.CreatedAt)
+	return err
+}
+
+// Get returns an import job, or sql.ErrNoRows
+func (r *VoterImportRepository) Get(id string) (*database.VoterImportJob, error) {
+	query := `
+        SELECT id, format, dry_run, status, total_rows, processed_rows, imported_rows,
+               failed_rows, error, created_by, created_at, finished_at
+        FROM voter_import_jobs
+        WHERE id = ?
+    `
+
+	var job database.VoterImportJob
+	err := r.db.QueryRow(query, id).Scan(&job.ID, &job.Format, &job.DryRun, &job.Status, &job.TotalRows,
+		&job.ProcessedRows, &job.ImportedRows, &job.FailedRows, &job.Error, &job.CreatedBy,
+		&job.CreatedAt, &job.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// UpdateProgress records how many rows of a running job have been checked
+func (r *VoterImportRepository) UpdateProgress(id string, processed, imported, failed int) error {
+	_, err := r.db.Exec(`
+        UPDATE voter_import_jobs SET processed_rows = ?, imported_rows = ?, failed_rows = ?
+        WHERE id = ? AND status = ?
+    `, processed, imported, failed, id, database.ImportRunning)
+	return err
+}
+
+// Finish records the outcome of a job
+func (r *VoterImportRepository) Finish(job *database.VoterImportJob) error {
+	now := time.Now().UTC()
+	job.FinishedAt = &now
+	_, err := r.db.Exec(`
+        UPDATE voter_import_jobs
+        SET status = ?, processed_rows = ?, imported_rows = ?, failed_rows = ?, error = ?, finished_at = ?
+        WHERE id = ?
+    `, job.Status, job.ProcessedRows, job.ImportedRows, job.FailedRows, job.Error, now, job.ID)
+	return err
+}
+
+// FailInterrupted marks the jobs still running as failed. Jobs run in the
+// server process, so none survive a restart.
+func (r *VoterImportRepository) FailInterrupted() (int64, error) {
+	result, err := r.db.Exec(`
+        UPDATE voter_import_jobs SET status = ?, error = ?, finished_at = ?
+        WHERE status = ?
+    `, database.ImportFailed, "interrupted by a server restart", time.Now().UTC(), database.ImportRunning)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// AddErrors stores row errors of a job
+func (r *VoterImportRepository) AddErrors(jobID string, rowErrors []database.VoterImportError) error {
+	if len(rowErrors) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, e := range rowErrors {
+		_, err := tx.Exec(`INSERT INTO voter_import_errors (job_id, line, nin, code, message) VALUES (?, ?, ?, ?, ?)`,
+			jobID, e.Line, e.NIN, e.Code, e.Message)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListErrors returns a page of a job's row errors, by line
+func (r *VoterImportRepository) ListErrors(jobID string, limit, offset int) ([]database.VoterImportError, error) {
+	query := `
+        SELECT job_id, line, nin, code, message
+        FROM voter_import_errors
+        WHERE job_id = ?
+        ORDER BY line
+        LIMIT ? OFFSET ?
+    `
+	rows, err := r.db.Query(query, jobID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rowErrors := []database.VoterImportError{}
+	for rows.Next() {
+		var e database.VoterImportError
+		if err := rows.Scan(&e.JobID, &e.Line, &e.NIN, &e.Code, &e.Message); err != nil {
+			return nil, err
+		}
+		rowErrors = append(rowErrors, e)
+	}
+	return rowErrors, rows.Err()
+}
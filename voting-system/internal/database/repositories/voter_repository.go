@@ -2,106 +2,177 @@ package repositories
 
 import (
 	"database/sql"
+	"fmt"
+	"strings"
+	"time"
 	"voting-system/internal/database"
 )
 
 type VoterRepository struct {
-	db *sql.DB
+	db *database.DB
 }
 
 func NewVoterRepository(db *sql.DB) *VoterRepository {
-	return &VoterRepository{db: db}
+	return &VoterRepository{db: database.Wrap(db)}
 }
 
-// RegisterVoter registers a new voter
-func (r *VoterRepository) RegisterVoter(voter *database.Voter) error {
-	query := `
-        INSERT INTO voters (nin, first_name, last_name, date_of_birth, gender, 
-                           polling_unit_id, fingerprint_hash)
-        VALUES (?, ?, ?, ?, ?, ?, ?)
-    `
-	_, err := r.db.Exec(query, voter.NIN, voter.FirstName, voter.LastName,
-		voter.DateOfBirth, voter.Gender, voter.PollingUnitID, voter.FingerprintHash)
-	return err
+// RegisterVoter registers a new voter together with their enrolled
+// fingerprint template
+func (r *VoterRepository) RegisterVoter(voter *database.Voter, template *database.VoterTemplate) error {
+	return r.RegisterVoters([]*database.Voter{voter}, []*database.VoterTemplate{template})
 }
 
-// GetVoterByNIN retrieves voter information by NIN
-func (r *VoterRepository) GetVoterByNIN(nin string) (*database.Voter, error) {
-	query := `
-        SELECT id, nin, first_name, last_name, date_of_birth, gender,
-               polling_unit_id, fingerprint_hash, registered_at, is_active
-        FROM voters
-        WHERE nin = ? AND is_active = true
-    `
-
-	var voter database.Voter
-	err := r.db.QueryRow(query, nin).Scan(
-		&voter.ID, &voter.NIN, &voter.FirstName, &voter.LastName,
-		&voter.DateOfBirth, &voter.Gender, &voter.PollingUnitID, &voter.FingerprintHash,
-		&voter.RegisteredAt, &voter.IsActive,
-	)
+// RegisterVoters registers voters with their enrolled templates, the same
+// index in each slice, in one transaction: either all are registered or none
+func (r *VoterRepository) RegisterVoters(voters []*database.Voter, templates []*database.VoterTemplate) error {
+	if len(voters) != len(templates) {
+		return fmt.Errorf("%d voters with %d templates", len(voters), len(templates))
+	}
 
+	tx, err := r.db.Begin()
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer tx.Rollback()
 
-	return &voter, nil
+	now := time.Now().UTC()
+	for i, voter := range voters {
+		query := `
+            INSERT INTO voters (nin, first_name, last_name, date_of_birth, gender, 
+                               polling_unit_id, fingerprint_hash)
+            VALUES (?, ?, ?, ?, ?, ?, ?)
+        `
+		_, err = tx.Exec(query, voter.NIN, voter.FirstName, voter.LastName,
+			voter.DateOfBirth, voter.Gender, voter.PollingUnitID, voter.FingerprintHash)
+		if err != nil {
+			return fmt.Errorf("voter %s: %v", voter.NIN, err)
+		}
+
+		template := templates[i]
+		template.NIN = voter.NIN
+		template.CreatedAt, template.UpdatedAt = now, now
+		_, err = tx.Exec(`
+            INSERT INTO voter_templates (nin, format, template, quality, sealed, created_at, updated_at)
+            VALUES (?, ?, ?, ?, ?, ?, ?)
+        `, template.NIN, template.Format, template.Template, template.Quality, template.Sealed,
+			template.CreatedAt, template.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("template of voter %s: %v", voter.NIN, err)
+		}
+	}
+
+	return tx.Commit()
 }
 
-// GetVoterByFingerprint retrieves voter by fingerprint hash
-func (r *VoterRepository) GetVoterByFingerprint(fingerprintHash string) (*database.Voter, error) {
-	query := `
-        SELECT id, nin, first_name, last_name, date_of_birth, gender,
-               polling_unit_id, fingerprint_hash, registered_at, is_active
-        FROM voters
-        WHERE fingerprint_hash = ? AND is_active = true
-    `
+// UpdateFingerprint replaces a voter's fingerprint hash and enrolled template
+// together
+func (r *VoterRepository) UpdateFingerprint(nin, fingerprintHash string, template *database.VoterTemplate) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	var voter database.Voter
-	err := r.db.QueryRow(query, fingerprintHash).Scan(
-		&voter.ID, &voter.NIN, &voter.FirstName, &voter.LastName,
-		&voter.DateOfBirth, &voter.Gender, &voter.PollingUnitID, &voter.FingerprintHash,
-		&voter.RegisteredAt, &voter.IsActive,
-	)
+	result, err := tx.Exec(`UPDATE voters SET fingerprint_hash = ? WHERE nin = ?`, fingerprintHash, nin)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
 
+	now := time.Now().UTC()
+	template.NIN = nin
+	template.CreatedAt, template.UpdatedAt = now, now
+	_, err = tx.Exec(`
+        INSERT INTO voter_templates (nin, format, template, quality, sealed, created_at, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?)
+        ON CONFLICT(nin) DO UPDATE SET
+            format = excluded.format,
+            template = excluded.template,
+            quality = excluded.quality,
+            sealed = excluded.sealed,
+            updated_at = excluded.updated_at
+    `, template.NIN, template.Format, template.Template, template.Quality, template.Sealed,
+		template.CreatedAt, template.UpdatedAt)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return &voter, nil
+	return tx.Commit()
 }
 
-// GetVotersByPollingUnit retrieves all voters in a polling unit
+// voterColumns are the columns scanVoter reads, in order
+const voterColumns = `
+        id, nin, first_name, last_name, date_of_birth, gender,
+        polling_unit_id, fingerprint_hash, registered_at, is_active,
+        deactivation_reason, deactivated_at
+`
+
+// GetVoterByNIN retrieves voter information by NIN, whether or not the voter
+// is active. Callers that serve voters check IsActive.
+func (r *VoterRepository) GetVoterByNIN(nin string) (*database.Voter, error) {
+	return scanVoter(r.db.QueryRow("SELECT "+voterColumns+" FROM voters WHERE nin = ?", nin))
+}
+
+// GetVoterByFingerprint retrieves voter by fingerprint hash, whether or not
+// the voter is active, so deactivated voters' fingerprints stay taken
+func (r *VoterRepository) GetVoterByFingerprint(fingerprintHash string) (*database.Voter, error) {
+	return scanVoter(r.db.QueryRow("SELECT "+voterColumns+" FROM voters WHERE fingerprint_hash = ?", fingerprintHash))
+}
+
+// GetVotersByPollingUnit retrieves all active voters in a polling unit
 func (r *VoterRepository) GetVotersByPollingUnit(pollingUnitID string) ([]*database.Voter, error) {
-	query := `
-        SELECT id, nin, first_name, last_name, date_of_birth, gender,
-               polling_unit_id, fingerprint_hash, registered_at, is_active
+	query := "SELECT " + voterColumns + `
         FROM voters
         WHERE polling_unit_id = ? AND is_active = true
         ORDER BY last_name, first_name
     `
+	return r.queryVoters(query, pollingUnitID)
+}
 
-	rows, err := r.db.Query(query, pollingUnitID)
-	if err != nil {
-		return nil, err
+// VoterFilter narrows a voter search. Empty fields do not filter.
+type VoterFilter struct {
+	NIN           string
+	Name          string // part of the first or last name
+	PollingUnitID string
+	Active        *bool
+}
+
+// SearchVoters returns a page of the voters matching filter, by name, and
+// how many match in total
+func (r *VoterRepository) SearchVoters(filter VoterFilter, limit, offset int) ([]*database.Voter, int, error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+
+	if filter.NIN != "" {
+		where += " AND nin = ?"
+		args = append(args, filter.NIN)
+	}
+	if filter.Name != "" {
+		where += " AND (LOWER(first_name) LIKE ? OR LOWER(last_name) LIKE ?)"
+		pattern := "%" + strings.ToLower(filter.Name) + "%"
+		args = append(args, pattern, pattern)
+	}
+	if filter.PollingUnitID != "" {
+		where += " AND polling_unit_id = ?"
+		args = append(args, filter.PollingUnitID)
+	}
+	if filter.Active != nil {
+		where += " AND is_active = ?"
+		args = append(args, *filter.Active)
 	}
-	defer rows.Close()
 
-	var voters []*database.Voter
-	for rows.Next() {
-		var voter database.Voter
-		err := rows.Scan(
-			&voter.ID, &voter.NIN, &voter.FirstName, &voter.LastName,
-			&voter.DateOfBirth, &voter.Gender, &voter.PollingUnitID, &voter.FingerprintHash,
-			&voter.RegisteredAt, &voter.IsActive,
-		)
-		if err != nil {
-			return nil, err
-		}
-		voters = append(voters, &voter)
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM voters"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
 	}
 
-	return voters, nil
+	query := "SELECT " + voterColumns + " FROM voters" + where + " ORDER BY last_name, first_name, id LIMIT ? OFFSET ?"
+	voters, err := r.queryVoters(query, append(args, limit, offset)...)
+	return voters, total, err
 }
 
 // UpdateVoter updates voter information
@@ -117,9 +188,102 @@ func (r *VoterRepository) UpdateVoter(voter *database.Voter) error {
 	return err
 }
 
-// DeactivateVoter deactivates a voter
-func (r *VoterRepository) DeactivateVoter(voterID int64) error {
-	query := `UPDATE voters SET is_active = false WHERE id = ?`
-	_, err := r.db.Exec(query, voterID)
-	return err
+// TransferVoter moves an active voter to another polling unit. Their place on
+// the rolls of elections not yet started or still running moves with them;
+// the rolls of ended elections keep the polling unit they voted at. It
+// returns sql.ErrNoRows if there is no such active voter.
+func (r *VoterRepository) TransferVoter(voterID int64, pollingUnitID string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE voters SET polling_unit_id = ? WHERE id = ? AND is_active = true`, pollingUnitID, voterID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	_, err = tx.Exec(`
+        UPDATE election_voters SET polling_unit_id = ?
+        WHERE nin = (SELECT nin FROM voters WHERE id = ?)
+          AND (election_id IN (SELECT blockchain_id FROM elections WHERE is_active = TRUE)
+               OR election_id NOT IN (SELECT election_id FROM election_rolls))
+    `, pollingUnitID, voterID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeactivateVoter deactivates an active voter for reason. It returns
+// sql.ErrNoRows if there is no such active voter.
+func (r *VoterRepository) DeactivateVoter(voterID int64, reason string) error {
+	query := `
+        UPDATE voters SET is_active = false, deactivation_reason = ?, deactivated_at = ?
+        WHERE id = ? AND is_active = true
+    `
+	return r.execOne(query, reason, time.Now().UTC(), voterID)
+}
+
+// ReactivateVoter restores a deactivated voter. It returns sql.ErrNoRows if
+// there is no such deactivated voter.
+func (r *VoterRepository) ReactivateVoter(voterID int64) error {
+	query := `
+        UPDATE voters SET is_active = true, deactivation_reason = '', deactivated_at = NULL
+        WHERE id = ? AND is_active = false
+    `
+	return r.execOne(query, voterID)
+}
+
+// execOne runs an update that must change one row, or returns sql.ErrNoRows
+func (r *VoterRepository) execOne(query string, args ...interface{}) error {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *VoterRepository) queryVoters(query string, args ...interface{}) ([]*database.Voter, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var voters []*database.Voter
+	for rows.Next() {
+		voter, err := scanVoter(rows)
+		if err != nil {
+			return nil, err
+		}
+		voters = append(voters, voter)
+	}
+
+	return voters, rows.Err()
+}
+
+func scanVoter(row rowScanner) (*database.Voter, error) {
+	var voter database.Voter
+	err := row.Scan(
+		&voter.ID, &voter.NIN, &voter.FirstName, &voter.LastName,
+		&voter.DateOfBirth, &voter.Gender, &voter.PollingUnitID, &voter.FingerprintHash,
+		&voter.RegisteredAt, &voter.IsActive, &voter.DeactivationReason, &voter.DeactivatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &voter, nil
 }
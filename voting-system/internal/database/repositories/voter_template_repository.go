@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+	"voting-system/internal/database"
+)
+
+// VoterTemplateRepository stores voters' enrolled fingerprint templates
+type VoterTemplateRepository struct {
+	db *database.DB
+}
+
+func NewVoterTemplateRepository(db *sql.DB) *VoterTemplateRepository {
+	return &VoterTemplateRepository{db: database.Wrap(db)}
+}
+
+// Save stores a voter's template, replacing any earlier one
+func (r *VoterTemplateRepository) Save(template *database.VoterTemplate) error {
+	now := time.Now().UTC()
+	if template.CreatedAt.IsZero() {
+		template.CreatedAt = now
+	}
+	template.UpdatedAt = now
+
+	query := `
+        INSERT INTO voter_templates (nin, format, template, quality, sealed, created_at, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?)
+        ON CONFLICT(nin) DO UPDATE SET
+            format = excluded.format,
+            template = excluded.template,
+            quality = excluded.quality,
+            sealed = excluded.sealed,
+            updated_at = excluded.updated_at
+    `
+	_, err := r.db.Exec(query, template.NIN, template.Format, template.Template, template.Quality,
+		template.Sealed, template.CreatedAt, template.UpdatedAt)
+	return err
+}
+
+// Get returns a voter's template, or sql.ErrNoRows
+func (r *VoterTemplateRepository) Get(nin string) (*database.VoterTemplate, error) {
+	query := `
+        SELECT nin, format, template, quality, sealed, created_at, updated_at
+        FROM voter_templates
+        WHERE nin = ?
+    `
+
+	var template database.VoterTemplate
+	err := r.db.QueryRow(query, nin).Scan(&template.NIN, &template.Format, &template.Template,
+		&template.Quality, &template.Sealed, &template.CreatedAt, &template.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// ListByPollingUnit returns the templates of a polling unit's active voters,
+// for its terminals' rosters
+func (r *VoterTemplateRepository) ListByPollingUnit(pollingUnitID string) ([]database.VoterTemplate, error) {
+	query := `
+        SELECT t.nin, t.format, t.template, t.quality, t.sealed, t.created_at, t.updated_at
+        FROM voter_templates t
+        JOIN voters v ON v.nin = t.nin
+        WHERE v.polling_unit_id = ? AND v.is_active = TRUE
+    `
+	return r.list(query, pollingUnitID)
+}
+
+// ListByFormat returns every template enrolled in a format, for searching at
+// registration
+func (r *VoterTemplateRepository) ListByFormat(format string) ([]database.VoterTemplate, error) {
+	query := `
+        SELECT nin, format, template, quality, sealed, created_at, updated_at
+        FROM voter_templates
+        WHERE format = ?
+        ORDER BY nin
+    `
+	return r.list(query, format)
+}
+
+// ListUnsealed returns the templates enrolled before the vault, for sealing
+func (r *VoterTemplateRepository) ListUnsealed() ([]database.VoterTemplate, error) {
+	query := `
+        SELECT nin, format, template, quality, sealed, created_at, updated_at
+        FROM voter_templates
+        WHERE sealed = FALSE
+        ORDER BY nin
+    `
+	return r.list(query)
+}
+
+func (r *VoterTemplateRepository) list(query string, args ...interface{}) ([]database.VoterTemplate, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []database.VoterTemplate
+	for rows.Next() {
+		var template database.VoterTemplate
+		if err := rows.Scan(&template.NIN, &template.Format, &template.Template,
+			&template.Quality, &template.Sealed, &template.CreatedAt, &template.UpdatedAt); err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+	return templates, rows.Err()
+}
@@ -0,0 +1,225 @@
+// Package fleet watches the health of the voting terminals. Terminals report
+// battery, firmware, queue depth and clock skew in periodic heartbeats; the
+// monitor raises an alert for every reading outside the configured limits and
+// for every terminal that stops reporting, and resolves it once it recovers.
+package fleet
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"voting-system/internal/database"
+	"voting-system/internal/database/repositories"
+	"voting-system/pkg/config"
+	"voting-system/pkg/logger"
+)
+
+// Alert kinds
+const (
+	AlertOffline      = "offline"
+	AlertLowBattery   = "low_battery"
+	AlertClockSkew    = "clock_skew"
+	AlertQueueBacklog = "queue_backlog"
+)
+
+// healthAlerts are the alert kinds derived from a heartbeat's readings
+var healthAlerts = []string{AlertLowBattery, AlertClockSkew, AlertQueueBacklog}
+
+// Condition is a reading outside the configured limits
+type Condition struct {
+	Kind    string
+	Message string
+}
+
+// Evaluate returns the conditions a terminal's reported health is in
+func Evaluate(health database.TerminalHealth, limits config.FleetConfig) []Condition {
+	var conditions []Condition
+
+	if health.BatteryLevel != nil && !health.Charging && limits.LowBattery > 0 && *health.BatteryLevel < limits.LowBattery {
+		conditions = append(conditions, Condition{
+			Kind:    AlertLowBattery,
+			Message: fmt.Sprintf("battery at %d%% and not charging", *health.BatteryLevel),
+		})
+	}
+
+	skew := time.Duration(health.ClockSkewMs) * time.Millisecond
+	if skew < 0 {
+		skew = -skew
+	}
+	if limits.MaxClockSkew > 0 && skew > limits.MaxClockSkew {
+		conditions = append(conditions, Condition{
+			Kind:    AlertClockSkew,
+			Message: fmt.Sprintf("clock is %dms off the server's", health.ClockSkewMs),
+		})
+	}
+
+	if limits.MaxQueueDepth > 0 && health.QueueDepth > limits.MaxQueueDepth {
+		conditions = append(conditions, Condition{
+			Kind:    AlertQueueBacklog,
+			Message: fmt.Sprintf("%d votes waiting to be uploaded", health.QueueDepth),
+		})
+	}
+
+	return conditions
+}
+
+// Monitor raises and resolves terminal alerts
+type Monitor struct {
+	terminals *repositories.TerminalRepository
+	alerts    *repositories.TerminalAlertRepository
+	audit     *repositories.AuditLogRepository
+	limits    config.FleetConfig
+	logger    *logger.Logger
+
+	isRunning bool
+	stopChan  chan struct{}
+	mutex     sync.Mutex
+}
+
+// NewMonitor creates a fleet monitor
+func NewMonitor(
+	terminals *repositories.TerminalRepository,
+	alerts *repositories.TerminalAlertRepository,
+	audit *repositories.AuditLogRepository,
+	limits config.FleetConfig,
+	logger *logger.Logger,
+) *Monitor {
+	return &Monitor{
+		terminals: terminals,
+		alerts:    alerts,
+		audit:     audit,
+		limits:    limits,
+		logger:    logger,
+	}
+}
+
+// Start begins checking for silent terminals every CheckInterval
+func (m *Monitor) Start() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.isRunning {
+		return fmt.Errorf("fleet monitor is already running")
+	}
+	if m.limits.CheckInterval <= 0 || m.limits.OfflineAfter <= 0 {
+		return fmt.Errorf("fleet monitor requires a check interval and an offline threshold")
+	}
+
+	m.isRunning = true
+	m.stopChan = make(chan struct{})
+	go m.checkLoop()
+
+	m.logger.Info("Fleet monitor started: terminals silent for %v are marked offline", m.limits.OfflineAfter)
+	return nil
+}
+
+// Stop stops the monitor
+func (m *Monitor) Stop() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !m.isRunning {
+		return
+	}
+	close(m.stopChan)
+	m.isRunning = false
+}
+
+func (m *Monitor) checkLoop() {
+	ticker := time.NewTicker(m.limits.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := m.Check(time.Now()); err != nil {
+				m.logger.Error("Fleet check failed: %v", err)
+			}
+		case <-m.stopChan:
+			return
+		}
+	}
+}
+
+// Check marks the terminals that have not sent a heartbeat within
+// OfflineAfter of now as offline, raising an alert for each, and returns them
+func (m *Monitor) Check(now time.Time) ([]database.Terminal, error) {
+	offline, err := m.terminals.MarkOffline(now.Add(-m.limits.OfflineAfter))
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark silent terminals offline: %v", err)
+	}
+
+	for _, terminal := range offline {
+		message := "no heartbeat received"
+		if terminal.LastHeartbeat != nil {
+			message = fmt.Sprintf("no heartbeat since %s", terminal.LastHeartbeat.UTC().Format(time.RFC3339))
+		}
+		if err := m.raise(terminal.ID, terminal.PollingUnitID, Condition{Kind: AlertOffline, Message: message}); err != nil {
+			return offline, err
+		}
+	}
+	return offline, nil
+}
+
+// Heartbeat updates a terminal's alerts after it reported health: the offline
+// alert and any reading back within limits are resolved, and readings outside
+// the limits raise alerts. It returns the conditions the terminal is in.
+func (m *Monitor) Heartbeat(terminal *database.Terminal, health database.TerminalHealth) ([]Condition, error) {
+	conditions := Evaluate(health, m.limits)
+
+	active := make(map[string]bool, len(conditions))
+	for _, condition := range conditions {
+		active[condition.Kind] = true
+		if err := m.raise(terminal.ID, terminal.PollingUnitID, condition); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, kind := range append([]string{AlertOffline}, healthAlerts...) {
+		if active[kind] {
+			continue
+		}
+		if err := m.resolve(terminal.ID, terminal.PollingUnitID, kind); err != nil {
+			return nil, err
+		}
+	}
+	return conditions, nil
+}
+
+func (m *Monitor) raise(deviceID, pollingUnitID string, condition Condition) error {
+	opened, err := m.alerts.Raise(deviceID, condition.Kind, condition.Message)
+	if err != nil {
+		return fmt.Errorf("failed to raise %s alert for terminal %s: %v", condition.Kind, deviceID, err)
+	}
+	if opened {
+		m.logger.Warning("Terminal %s alert: %s: %s", deviceID, condition.Kind, condition.Message)
+		m.record("terminal_alert_raised", deviceID, pollingUnitID, fmt.Sprintf("%s: %s", condition.Kind, condition.Message))
+	}
+	return nil
+}
+
+func (m *Monitor) resolve(deviceID, pollingUnitID, kind string) error {
+	resolved, err := m.alerts.Resolve(deviceID, kind)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s alert for terminal %s: %v", kind, deviceID, err)
+	}
+	if resolved {
+		m.logger.Info("Terminal %s alert resolved: %s", deviceID, kind)
+		m.record("terminal_alert_resolved", deviceID, pollingUnitID, kind)
+	}
+	return nil
+}
+
+func (m *Monitor) record(action, deviceID, pollingUnitID, details string) {
+	err := m.audit.InsertAuditLog(&database.AuditLog{
+		Action:        action,
+		UserID:        deviceID,
+		PollingUnitID: pollingUnitID,
+		Details:       details,
+		CreatedAt:     time.Now(),
+	})
+	if err != nil {
+		m.logger.Error("Failed to create audit log: %v", err)
+	}
+}
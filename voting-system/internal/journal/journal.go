@@ -0,0 +1,91 @@
+// Package journal defines the hash chain that links a terminal's offline vote
+// journal. Each entry commits to the previous entry's hash, so the server can
+// tell when entries are missing, reordered or altered after the fact.
+package journal
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GenesisHash is the previous hash of a terminal's first journal entry
+var GenesisHash = strings.Repeat("0", 64)
+
+var (
+	// ErrHashMismatch is returned when an entry's hash does not match its contents
+	ErrHashMismatch = errors.New("journal entry hash does not match its contents")
+	// ErrBadSignature is returned when an entry's signature does not verify
+	ErrBadSignature = errors.New("journal entry signature is invalid")
+)
+
+// Entry is the part of a journal entry covered by the chain
+type Entry struct {
+	DeviceID         string
+	Seq              int64
+	PrevHash         string
+	VerificationHash string
+	PayloadHash      string
+	CreatedAt        time.Time
+}
+
+// Timestamp truncates a time to the precision the chain commits to
+func Timestamp(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Millisecond)
+}
+
+// PayloadHash returns the SHA-256 hex digest of an entry's vote payload
+func PayloadHash(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// Hash returns the entry's chain hash
+func (e *Entry) Hash() string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%s|%s|%s|%d",
+		e.DeviceID, e.Seq, e.PrevHash, e.VerificationHash, e.PayloadHash, e.CreatedAt.UnixMilli())))
+	return hex.EncodeToString(sum[:])
+}
+
+// Sign returns the HMAC-SHA256 of an entry hash under the terminal secret.
+// Without a secret entries are chained but unsigned.
+func Sign(secret, entryHash string) string {
+	if secret == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(entryHash))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks that entryHash matches the entry and, with a secret, that
+// signature is the terminal's signature over it
+func (e *Entry) Verify(entryHash, signature, secret string) error {
+	if e.Hash() != strings.ToLower(entryHash) {
+		return ErrHashMismatch
+	}
+	if secret != "" && !hmac.Equal([]byte(Sign(secret, e.Hash())), []byte(strings.ToLower(signature))) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// VerifyAny is Verify for a terminal whose secret has been rotated: the
+// signature may have been made with any of secrets. Without secrets only the
+// hash is checked.
+func (e *Entry) VerifyAny(entryHash, signature string, secrets []string) error {
+	if len(secrets) == 0 {
+		return e.Verify(entryHash, signature, "")
+	}
+	var err error
+	for _, secret := range secrets {
+		if err = e.Verify(entryHash, signature, secret); err != ErrBadSignature {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,144 @@
+// Package merkle builds the Merkle tree of an election's voter roll. Leaves
+// are derived from voters' verification hashes, so the tree and its proofs
+// carry no personal data. Pairs are hashed in sorted order, as OpenZeppelin's
+// MerkleProof expects, so the contract can check the same proofs on chain.
+package merkle
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrNotInTree is returned for a proof of a leaf the tree does not have
+var ErrNotInTree = errors.New("leaf is not in the tree")
+
+// Hash is a leaf or node of the tree
+type Hash [32]byte
+
+// Hex returns the hash as 0x-prefixed hex
+func (h Hash) Hex() string {
+	return "0x" + hex.EncodeToString(h[:])
+}
+
+// ParseHash reads a hash written by Hex
+func ParseHash(s string) (Hash, error) {
+	var h Hash
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(b) != len(h) {
+		return h, fmt.Errorf("merkle: invalid hash %q", s)
+	}
+	copy(h[:], b)
+	return h, nil
+}
+
+// ChainHash returns the bytes32 a verification hash is recorded under on
+// chain, the Keccak-256 of its hex string
+func ChainHash(verificationHash string) Hash {
+	var h Hash
+	copy(h[:], crypto.Keccak256([]byte(verificationHash)))
+	return h
+}
+
+// Leaf returns the leaf of a voter with the given verification hash:
+// keccak256(abi.encodePacked(bytes32)) of its on-chain hash. Hashing the
+// bytes32 again keeps leaves from being mistaken for inner nodes.
+func Leaf(verificationHash string) Hash {
+	chain := ChainHash(verificationHash)
+	var h Hash
+	copy(h[:], crypto.Keccak256(chain[:]))
+	return h
+}
+
+// Tree is a Merkle tree over sorted leaves. A node without a sibling is
+// carried up to the next level unchanged.
+type Tree struct {
+	levels [][]Hash // leaves first, root last
+}
+
+// New builds the tree of the leaves. Leaves are sorted, so the tree does not
+// depend on the order voters are listed in, and must be distinct.
+func New(leaves []Hash) (*Tree, error) {
+	if len(leaves) == 0 {
+		return nil, errors.New("merkle: no leaves")
+	}
+	level := append([]Hash(nil), leaves...)
+	sort.Slice(level, func(i, j int) bool { return bytes.Compare(level[i][:], level[j][:]) < 0 })
+	for i := 1; i < len(level); i++ {
+		if level[i] == level[i-1] {
+			return nil, fmt.Errorf("merkle: duplicate leaf %s", level[i].Hex())
+		}
+	}
+
+	levels := [][]Hash{level}
+	for len(level) > 1 {
+		next := make([]Hash, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 < len(level) {
+				next = append(next, hashPair(level[i], level[i+1]))
+			} else {
+				next = append(next, level[i])
+			}
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return &Tree{levels: levels}, nil
+}
+
+// Root returns the root of the tree
+func (t *Tree) Root() Hash {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// Size returns the number of leaves
+func (t *Tree) Size() int {
+	return len(t.levels[0])
+}
+
+// Levels returns the nodes of each level, leaves first and root last
+func (t *Tree) Levels() [][]Hash {
+	return t.levels
+}
+
+// Proof returns the siblings on the path from a leaf to the root
+func (t *Tree) Proof(leaf Hash) ([]Hash, error) {
+	leaves := t.levels[0]
+	index := sort.Search(len(leaves), func(i int) bool { return bytes.Compare(leaves[i][:], leaf[:]) >= 0 })
+	if index == len(leaves) || leaves[index] != leaf {
+		return nil, ErrNotInTree
+	}
+
+	var proof []Hash
+	for _, level := range t.levels[:len(t.levels)-1] {
+		if sibling := index ^ 1; sibling < len(level) {
+			proof = append(proof, level[sibling])
+		}
+		index /= 2
+	}
+	return proof, nil
+}
+
+// Verify reports whether proof leads from leaf to root
+func Verify(root, leaf Hash, proof []Hash) bool {
+	node := leaf
+	for _, sibling := range proof {
+		node = hashPair(node, sibling)
+	}
+	return node == root
+}
+
+// hashPair hashes two nodes in sorted order
+func hashPair(a, b Hash) Hash {
+	if bytes.Compare(a[:], b[:]) > 0 {
+		a, b = b, a
+	}
+	var h Hash
+	copy(h[:], crypto.Keccak256(a[:], b[:]))
+	return h
+}
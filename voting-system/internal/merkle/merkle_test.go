@@ -0,0 +1,83 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func leaves(n int) []Hash {
+	out := make([]Hash, n)
+	for i := range out {
+		out[i] = Leaf(fmt.Sprintf("voter-%d", i))
+	}
+	return out
+}
+
+func TestProofsVerifyForEveryLeaf(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 7, 8, 33} {
+		tree, err := New(leaves(n))
+		require.NoError(t, err)
+		assert.Equal(t, n, tree.Size())
+
+		for _, leaf := range leaves(n) {
+			proof, err := tree.Proof(leaf)
+			require.NoError(t, err)
+			assert.True(t, Verify(tree.Root(), leaf, proof), "%d leaves", n)
+		}
+
+		_, err = tree.Proof(Leaf("stranger"))
+		assert.ErrorIs(t, err, ErrNotInTree)
+	}
+}
+
+func TestRootDoesNotDependOnOrder(t *testing.T) {
+	forward := leaves(5)
+	backward := make([]Hash, len(forward))
+	for i, leaf := range forward {
+		backward[len(forward)-1-i] = leaf
+	}
+
+	a, err := New(forward)
+	require.NoError(t, err)
+	b, err := New(backward)
+	require.NoError(t, err)
+	assert.Equal(t, a.Root(), b.Root())
+
+	// Any change to the roll changes the root
+	c, err := New(leaves(4))
+	require.NoError(t, err)
+	assert.NotEqual(t, a.Root(), c.Root())
+}
+
+func TestTamperedProofsFail(t *testing.T) {
+	all := leaves(6)
+	tree, err := New(all)
+	require.NoError(t, err)
+	proof, err := tree.Proof(all[2])
+	require.NoError(t, err)
+
+	assert.False(t, Verify(tree.Root(), Leaf("stranger"), proof))
+	proof[0][0] ^= 1
+	assert.False(t, Verify(tree.Root(), all[2], proof))
+}
+
+func TestLeafMatchesTheContract(t *testing.T) {
+	// keccak256(abi.encodePacked(bytes32(keccak256(bytes(verificationHash)))))
+	leaf := Leaf("abc")
+	assert.Equal(t, crypto.Keccak256(crypto.Keccak256([]byte("abc"))), leaf[:])
+
+	parsed, err := ParseHash(leaf.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, leaf, parsed)
+	_, err = ParseHash("0x1234")
+	assert.Error(t, err)
+
+	_, err = New(nil)
+	assert.Error(t, err)
+	_, err = New([]Hash{leaf, leaf})
+	assert.Error(t, err, "a voter is on the roll once")
+}
@@ -0,0 +1,114 @@
+package tally
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// Ballot is an encrypted vote with one ciphertext per candidate, in the
+// election's candidate order. The chosen candidate's ciphertext encrypts 1 and
+// all others encrypt 0.
+type Ballot struct {
+	Ciphertexts []Ciphertext  `json:"ciphertexts"`
+	BitProofs   []BitProof    `json:"bit_proofs"` // each ciphertext encrypts 0 or 1
+	SumProof    EqualityProof `json:"sum_proof"`  // the ciphertexts add up to exactly 1
+}
+
+// BallotContext binds ballot proofs to an election and its candidate list so a
+// ballot cannot be replayed into another election
+func BallotContext(electionID string, candidates []string) []byte {
+	ctx, _ := json.Marshal(struct {
+		ElectionID string   `json:"election_id"`
+		Candidates []string `json:"candidates"`
+	}{electionID, candidates})
+	return ctx
+}
+
+// EncryptBallot encrypts a vote for candidate index choice out of n candidates
+func EncryptBallot(pk PublicKey, context []byte, n, choice int, random io.Reader) (*Ballot, error) {
+	if n < 1 {
+		return nil, errors.New("tally: ballot needs at least one candidate")
+	}
+	if choice < 0 || choice >= n {
+		return nil, fmt.Errorf("tally: choice %d out of range", choice)
+	}
+
+	ballot := &Ballot{
+		Ciphertexts: make([]Ciphertext, n),
+		BitProofs:   make([]BitProof, n),
+	}
+	rSum := new(big.Int)
+	for i := 0; i < n; i++ {
+		var m int64
+		if i == choice {
+			m = 1
+		}
+		ct, r, err := pk.Encrypt(m, random)
+		if err != nil {
+			return nil, err
+		}
+		proof, err := proveBit(context, pk, ct, m, r, random)
+		if err != nil {
+			return nil, err
+		}
+		ballot.Ciphertexts[i] = ct
+		ballot.BitProofs[i] = proof
+		rSum.Add(rSum, r)
+	}
+
+	// The sum encrypts 1 with randomness rSum: prove log_G(S1) = log_H(S2 - G)
+	sum := ballot.sum()
+	proof, err := proveEquality(context, modN(rSum), sum.C1, pk.H, sub(sum.C2, generator()), random)
+	if err != nil {
+		return nil, err
+	}
+	ballot.SumProof = proof
+	return ballot, nil
+}
+
+// Verify checks that the ballot has n well-formed ciphertexts encrypting a vote
+// for exactly one candidate
+func (b *Ballot) Verify(pk PublicKey, context []byte, n int) error {
+	if n < 1 {
+		return errors.New("tally: ballot needs at least one candidate")
+	}
+	if len(b.Ciphertexts) != n || len(b.BitProofs) != n {
+		return fmt.Errorf("tally: ballot has %d ciphertexts, want %d", len(b.Ciphertexts), n)
+	}
+	for i := range b.Ciphertexts {
+		if !verifyBit(context, pk, b.Ciphertexts[i], b.BitProofs[i]) {
+			return fmt.Errorf("%w for candidate %d", ErrInvalidProof, i)
+		}
+	}
+	sum := b.sum()
+	if !verifyEquality(context, b.SumProof, sum.C1, pk.H, sub(sum.C2, generator())) {
+		return fmt.Errorf("%w: ballot does not select exactly one candidate", ErrInvalidProof)
+	}
+	return nil
+}
+
+func (b *Ballot) sum() Ciphertext {
+	total := b.Ciphertexts[0]
+	for _, ct := range b.Ciphertexts[1:] {
+		total = total.Add(ct)
+	}
+	return total
+}
+
+// String returns the JSON encoding of the ballot
+func (b *Ballot) String() string {
+	data, _ := json.Marshal(b)
+	return string(data)
+}
+
+// ParseBallot decodes a ballot produced by Ballot.String. It does not verify the proofs.
+func ParseBallot(s string) (*Ballot, error) {
+	var b Ballot
+	if err := json.Unmarshal([]byte(s), &b); err != nil {
+		return nil, fmt.Errorf("tally: invalid ballot: %v", err)
+	}
+	return &b, nil
+}
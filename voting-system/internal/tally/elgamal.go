@@ -0,0 +1,214 @@
+// Package tally implements encrypted ballots and homomorphic tallying.
+//
+// Ballots use exponential ElGamal on secp256k1: each candidate gets its own
+// ciphertext of 0 or 1, together with zero-knowledge proofs that the ballot
+// is well formed. Ciphertexts are added without decrypting them, and only the
+// per-candidate sums are decrypted, by a threshold of trustees, once the
+// election has ended.
+package tally
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/secp256k1"
+	"github.com/consensys/gnark-crypto/ecc/secp256k1/fr"
+)
+
+// Point is an element of the secp256k1 group
+type Point = secp256k1.G1Affine
+
+// pointSize is the length of an encoded point (uncompressed X and Y)
+const pointSize = secp256k1.SizeOfG1AffineUncompressed
+
+var (
+	// ErrInvalidPoint is returned for encodings that are not points on the curve
+	ErrInvalidPoint = errors.New("tally: invalid curve point")
+	// ErrInvalidProof is returned when a zero-knowledge proof does not verify
+	ErrInvalidProof = errors.New("tally: invalid proof")
+)
+
+// order returns the order of the group
+func order() *big.Int {
+	return fr.Modulus()
+}
+
+// generator returns the group generator G
+func generator() Point {
+	_, g := secp256k1.Generators()
+	return g
+}
+
+// randomScalar returns a uniformly random scalar in [1, order)
+func randomScalar(random io.Reader) (*big.Int, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	max := new(big.Int).Sub(order(), big.NewInt(1))
+	k, err := rand.Int(random, max)
+	if err != nil {
+		return nil, err
+	}
+	return k.Add(k, big.NewInt(1)), nil
+}
+
+func mulBase(k *big.Int) Point {
+	var p Point
+	p.ScalarMultiplicationBase(new(big.Int).Mod(k, order()))
+	return p
+}
+
+func mul(p Point, k *big.Int) Point {
+	var q Point
+	q.ScalarMultiplication(&p, new(big.Int).Mod(k, order()))
+	return q
+}
+
+func add(a, b Point) Point {
+	var p Point
+	p.Add(&a, &b)
+	return p
+}
+
+func sub(a, b Point) Point {
+	var p Point
+	p.Sub(&a, &b)
+	return p
+}
+
+// EncodePoint returns the hex encoding of a point. The identity encodes as zeros.
+func EncodePoint(p Point) string {
+	raw := p.RawBytes()
+	return hex.EncodeToString(raw[:])
+}
+
+// DecodePoint parses a point produced by EncodePoint
+func DecodePoint(s string) (Point, error) {
+	var p Point
+	raw, err := hex.DecodeString(s)
+	if err != nil || len(raw) != pointSize {
+		return p, ErrInvalidPoint
+	}
+	if _, err := p.SetBytes(raw); err != nil {
+		return p, ErrInvalidPoint
+	}
+	return p, nil
+}
+
+// PublicKey is an election encryption key H = xG
+type PublicKey struct {
+	H Point
+}
+
+// PrivateKey is the secret x of an election key. In threshold deployments it
+// only exists while it is split into trustee shares.
+type PrivateKey struct {
+	PublicKey
+	X *big.Int
+}
+
+// GenerateKey creates a new election key pair
+func GenerateKey(random io.Reader) (*PrivateKey, error) {
+	x, err := randomScalar(random)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{PublicKey: PublicKey{H: mulBase(x)}, X: x}, nil
+}
+
+// String returns the encoded public key
+func (pk PublicKey) String() string {
+	return EncodePoint(pk.H)
+}
+
+// ParsePublicKey decodes a public key produced by PublicKey.String
+func ParsePublicKey(s string) (PublicKey, error) {
+	h, err := DecodePoint(s)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	if h.IsInfinity() {
+		return PublicKey{}, ErrInvalidPoint
+	}
+	return PublicKey{H: h}, nil
+}
+
+// Ciphertext is an exponential ElGamal encryption (rG, mG + rH) of a small integer m
+type Ciphertext struct {
+	C1 Point
+	C2 Point
+}
+
+// Encrypt encrypts m under pk with fresh randomness and returns the randomness used
+func (pk PublicKey) Encrypt(m int64, random io.Reader) (Ciphertext, *big.Int, error) {
+	r, err := randomScalar(random)
+	if err != nil {
+		return Ciphertext{}, nil, err
+	}
+	return pk.encryptWith(m, r), r, nil
+}
+
+func (pk PublicKey) encryptWith(m int64, r *big.Int) Ciphertext {
+	return Ciphertext{
+		C1: mulBase(r),
+		C2: add(mulBase(big.NewInt(m)), mul(pk.H, r)),
+	}
+}
+
+// Add returns the encryption of the sum of the plaintexts of c and other
+func (c Ciphertext) Add(other Ciphertext) Ciphertext {
+	return Ciphertext{C1: add(c.C1, other.C1), C2: add(c.C2, other.C2)}
+}
+
+// Decrypt recovers m from c, searching plaintexts up to max
+func (sk *PrivateKey) Decrypt(c Ciphertext, max int64) (int64, error) {
+	return discreteLog(sub(c.C2, mul(c.C1, sk.X)), max)
+}
+
+// discreteLog finds m in [0, max] with M = mG. Tallies are bounded by the
+// number of ballots, so a linear walk is enough.
+func discreteLog(m Point, max int64) (int64, error) {
+	g := generator()
+	var acc Point
+	acc.SetInfinity()
+	for i := int64(0); i <= max; i++ {
+		if acc.Equal(&m) {
+			return i, nil
+		}
+		acc = add(acc, g)
+	}
+	return 0, fmt.Errorf("tally: plaintext exceeds %d", max)
+}
+
+type encodedCiphertext struct {
+	C1 string `json:"c1"`
+	C2 string `json:"c2"`
+}
+
+// MarshalJSON encodes the ciphertext as hex points
+func (c Ciphertext) MarshalJSON() ([]byte, error) {
+	return json.Marshal(encodedCiphertext{C1: EncodePoint(c.C1), C2: EncodePoint(c.C2)})
+}
+
+// UnmarshalJSON decodes a ciphertext produced by MarshalJSON
+func (c *Ciphertext) UnmarshalJSON(data []byte) error {
+	var enc encodedCiphertext
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	c1, err := DecodePoint(enc.C1)
+	if err != nil {
+		return err
+	}
+	c2, err := DecodePoint(enc.C2)
+	if err != nil {
+		return err
+	}
+	c.C1, c.C2 = c1, c2
+	return nil
+}
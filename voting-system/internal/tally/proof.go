@@ -0,0 +1,203 @@
+package tally
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"math/big"
+)
+
+// Domain separation tags for the Fiat-Shamir challenges
+const (
+	tagBitProof      = "voting-system/tally/bit/v1"
+	tagEqualityProof = "voting-system/tally/equality/v1"
+)
+
+// challenge hashes a tag, a context and a list of points to a scalar
+func challenge(tag string, context []byte, points ...Point) *big.Int {
+	h := sha256.New()
+	writeBytes(h, []byte(tag))
+	writeBytes(h, context)
+	for _, p := range points {
+		raw := p.RawBytes()
+		h.Write(raw[:])
+	}
+	c := new(big.Int).SetBytes(h.Sum(nil))
+	return c.Mod(c, order())
+}
+
+// writeBytes writes a length-prefixed byte string so adjacent fields cannot run together
+func writeBytes(w io.Writer, b []byte) {
+	n := len(b)
+	w.Write([]byte{byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)})
+	w.Write(b)
+}
+
+func modN(x *big.Int) *big.Int {
+	return x.Mod(x, order())
+}
+
+// EqualityProof is a Chaum-Pedersen proof that log_G(X) = log_Y(Z)
+type EqualityProof struct {
+	C *big.Int
+	Z *big.Int
+}
+
+// proveEquality proves knowledge of s with X = sG and Z = sY
+func proveEquality(context []byte, s *big.Int, x, y, z Point, random io.Reader) (EqualityProof, error) {
+	w, err := randomScalar(random)
+	if err != nil {
+		return EqualityProof{}, err
+	}
+	a := mulBase(w)
+	b := mul(y, w)
+	c := challenge(tagEqualityProof, context, generator(), x, y, z, a, b)
+	resp := modN(new(big.Int).Add(w, new(big.Int).Mul(c, s)))
+	return EqualityProof{C: c, Z: resp}, nil
+}
+
+// verifyEquality checks a proof produced by proveEquality
+func verifyEquality(context []byte, proof EqualityProof, x, y, z Point) bool {
+	if proof.C == nil || proof.Z == nil {
+		return false
+	}
+	// A = zG - cX, B = zY - cZ
+	a := sub(mulBase(proof.Z), mul(x, proof.C))
+	b := sub(mul(y, proof.Z), mul(z, proof.C))
+	return challenge(tagEqualityProof, context, generator(), x, y, z, a, b).Cmp(proof.C) == 0
+}
+
+// BitProof is a disjunctive Chaum-Pedersen proof that a ciphertext encrypts 0 or 1
+type BitProof struct {
+	C0, C1 *big.Int
+	Z0, Z1 *big.Int
+}
+
+// proveBit proves that ct = Encrypt(m, r) with m in {0, 1}. The branch for the
+// other value is simulated.
+func proveBit(context []byte, pk PublicKey, ct Ciphertext, m int64, r *big.Int, random io.Reader) (BitProof, error) {
+	real, fake := int(m), 1-int(m)
+	var c, z [2]*big.Int
+	var a, b [2]Point
+
+	var err error
+	if c[fake], err = randomScalar(random); err != nil {
+		return BitProof{}, err
+	}
+	if z[fake], err = randomScalar(random); err != nil {
+		return BitProof{}, err
+	}
+	a[fake], b[fake] = bitCommitments(pk, ct, fake, c[fake], z[fake])
+
+	w, err := randomScalar(random)
+	if err != nil {
+		return BitProof{}, err
+	}
+	a[real] = mulBase(w)
+	b[real] = mul(pk.H, w)
+
+	total := challenge(tagBitProof, context, pk.H, ct.C1, ct.C2, a[0], b[0], a[1], b[1])
+	c[real] = modN(new(big.Int).Sub(total, c[fake]))
+	z[real] = modN(new(big.Int).Add(w, new(big.Int).Mul(c[real], r)))
+
+	return BitProof{C0: c[0], C1: c[1], Z0: z[0], Z1: z[1]}, nil
+}
+
+// bitCommitments recomputes the commitments for branch v from a challenge and response:
+// A = zG - cC1, B = zH - c(C2 - vG)
+func bitCommitments(pk PublicKey, ct Ciphertext, v int, c, z *big.Int) (Point, Point) {
+	a := sub(mulBase(z), mul(ct.C1, c))
+	shifted := ct.C2
+	if v == 1 {
+		shifted = sub(ct.C2, generator())
+	}
+	b := sub(mul(pk.H, z), mul(shifted, c))
+	return a, b
+}
+
+// verifyBit checks a proof produced by proveBit
+func verifyBit(context []byte, pk PublicKey, ct Ciphertext, proof BitProof) bool {
+	if proof.C0 == nil || proof.C1 == nil || proof.Z0 == nil || proof.Z1 == nil {
+		return false
+	}
+	a0, b0 := bitCommitments(pk, ct, 0, proof.C0, proof.Z0)
+	a1, b1 := bitCommitments(pk, ct, 1, proof.C1, proof.Z1)
+	total := challenge(tagBitProof, context, pk.H, ct.C1, ct.C2, a0, b0, a1, b1)
+	return modN(new(big.Int).Add(proof.C0, proof.C1)).Cmp(total) == 0
+}
+
+// Scalars are encoded as hex strings in JSON
+
+type encodedEqualityProof struct {
+	C string `json:"c"`
+	Z string `json:"z"`
+}
+
+// MarshalJSON encodes the proof scalars as hex
+func (p EqualityProof) MarshalJSON() ([]byte, error) {
+	return json.Marshal(encodedEqualityProof{C: encodeScalar(p.C), Z: encodeScalar(p.Z)})
+}
+
+// UnmarshalJSON decodes a proof produced by MarshalJSON
+func (p *EqualityProof) UnmarshalJSON(data []byte) error {
+	var enc encodedEqualityProof
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	var err error
+	if p.C, err = decodeScalar(enc.C); err != nil {
+		return err
+	}
+	p.Z, err = decodeScalar(enc.Z)
+	return err
+}
+
+type encodedBitProof struct {
+	C0 string `json:"c0"`
+	C1 string `json:"c1"`
+	Z0 string `json:"z0"`
+	Z1 string `json:"z1"`
+}
+
+// MarshalJSON encodes the proof scalars as hex
+func (p BitProof) MarshalJSON() ([]byte, error) {
+	return json.Marshal(encodedBitProof{
+		C0: encodeScalar(p.C0), C1: encodeScalar(p.C1),
+		Z0: encodeScalar(p.Z0), Z1: encodeScalar(p.Z1),
+	})
+}
+
+// UnmarshalJSON decodes a proof produced by MarshalJSON
+func (p *BitProof) UnmarshalJSON(data []byte) error {
+	var enc encodedBitProof
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	for _, f := range []struct {
+		dst **big.Int
+		src string
+	}{{&p.C0, enc.C0}, {&p.C1, enc.C1}, {&p.Z0, enc.Z0}, {&p.Z1, enc.Z1}} {
+		v, err := decodeScalar(f.src)
+		if err != nil {
+			return err
+		}
+		*f.dst = v
+	}
+	return nil
+}
+
+func encodeScalar(k *big.Int) string {
+	if k == nil {
+		return ""
+	}
+	return k.Text(16)
+}
+
+// decodeScalar parses a hex scalar and rejects values outside [0, order)
+func decodeScalar(s string) (*big.Int, error) {
+	k, ok := new(big.Int).SetString(s, 16)
+	if !ok || k.Sign() < 0 || k.Cmp(order()) >= 0 {
+		return nil, ErrInvalidProof
+	}
+	return k, nil
+}
@@ -0,0 +1,52 @@
+package tally
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// EncryptedTally is the homomorphic sum of all ballots of an election
+type EncryptedTally struct {
+	Context []byte       `json:"-"`
+	Sums    []Ciphertext `json:"sums"`    // one encrypted count per candidate
+	Ballots int          `json:"ballots"` // number of ballots added
+}
+
+// NewEncryptedTally starts an empty tally for n candidates. The sums start as
+// encryptions of zero with zero randomness.
+func NewEncryptedTally(context []byte, n int) *EncryptedTally {
+	sums := make([]Ciphertext, n)
+	for i := range sums {
+		sums[i].C1.SetInfinity()
+		sums[i].C2.SetInfinity()
+	}
+	return &EncryptedTally{Context: context, Sums: sums}
+}
+
+// Add verifies a ballot and adds it to the tally
+func (t *EncryptedTally) Add(pk PublicKey, ballot *Ballot) error {
+	if err := ballot.Verify(pk, t.Context, len(t.Sums)); err != nil {
+		return err
+	}
+	for i, ct := range ballot.Ciphertexts {
+		t.Sums[i] = t.Sums[i].Add(ct)
+	}
+	t.Ballots++
+	return nil
+}
+
+// String returns the JSON encoding of the tally
+func (t *EncryptedTally) String() string {
+	data, _ := json.Marshal(t)
+	return string(data)
+}
+
+// ParseEncryptedTally decodes a tally produced by EncryptedTally.String for the given context
+func ParseEncryptedTally(s string, context []byte) (*EncryptedTally, error) {
+	var t EncryptedTally
+	if err := json.Unmarshal([]byte(s), &t); err != nil {
+		return nil, fmt.Errorf("tally: invalid encrypted tally: %v", err)
+	}
+	t.Context = context
+	return &t, nil
+}
@@ -0,0 +1,179 @@
+package tally
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var candidates = []string{"C1", "C2", "C3"}
+
+func TestEncryptionIsAdditive(t *testing.T) {
+	sk, err := GenerateKey(nil)
+	require.NoError(t, err)
+
+	a, _, err := sk.PublicKey.Encrypt(3, nil)
+	require.NoError(t, err)
+	b, _, err := sk.PublicKey.Encrypt(4, nil)
+	require.NoError(t, err)
+
+	m, err := sk.Decrypt(a.Add(b), 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), m)
+
+	_, err = sk.Decrypt(a.Add(b), 5)
+	assert.Error(t, err, "plaintexts above the bound are not found")
+}
+
+func TestBallotVerification(t *testing.T) {
+	sk, err := GenerateKey(nil)
+	require.NoError(t, err)
+	pk := sk.PublicKey
+	ctx := BallotContext("1", candidates)
+
+	ballot, err := EncryptBallot(pk, ctx, len(candidates), 1, nil)
+	require.NoError(t, err)
+	require.NoError(t, ballot.Verify(pk, ctx, len(candidates)))
+
+	// The ballot survives a JSON round trip
+	parsed, err := ParseBallot(ballot.String())
+	require.NoError(t, err)
+	require.NoError(t, parsed.Verify(pk, ctx, len(candidates)))
+
+	// Proofs are bound to the election
+	assert.ErrorIs(t, ballot.Verify(pk, BallotContext("2", candidates), len(candidates)), ErrInvalidProof)
+	assert.Error(t, ballot.Verify(pk, ctx, len(candidates)+1))
+
+	// An election without candidates has no valid ballot, not even an empty one
+	empty := &Ballot{Ciphertexts: []Ciphertext{}, BitProofs: []BitProof{}}
+	assert.Error(t, empty.Verify(pk, ctx, 0))
+	assert.Error(t, (&Ballot{}).Verify(pk, ctx, 0))
+
+	// A ciphertext of 2 has no valid bit proof
+	forged := *parsed
+	forged.Ciphertexts = append([]Ciphertext(nil), parsed.Ciphertexts...)
+	forged.Ciphertexts[1] = forged.Ciphertexts[1].Add(forged.Ciphertexts[1])
+	assert.ErrorIs(t, forged.Verify(pk, ctx, len(candidates)), ErrInvalidProof)
+
+	// Two votes with otherwise valid bit proofs fail the sum proof
+	other, err := EncryptBallot(pk, ctx, len(candidates), 2, nil)
+	require.NoError(t, err)
+	double := *ballot
+	double.Ciphertexts = []Ciphertext{ballot.Ciphertexts[0], ballot.Ciphertexts[1], other.Ciphertexts[2]}
+	double.BitProofs = []BitProof{ballot.BitProofs[0], ballot.BitProofs[1], other.BitProofs[2]}
+	assert.ErrorIs(t, double.Verify(pk, ctx, len(candidates)), ErrInvalidProof)
+}
+
+func TestThresholdTally(t *testing.T) {
+	sk, err := GenerateKey(nil)
+	require.NoError(t, err)
+	pk := sk.PublicKey
+	ctx := BallotContext("7", candidates)
+
+	shares, err := SplitKey(sk, 3, 5, nil)
+	require.NoError(t, err)
+	verificationKeys := make(map[int]Point)
+	keyList := make([]Point, len(shares))
+	for i, share := range shares {
+		verificationKeys[share.Index] = share.VerificationKey()
+		keyList[i] = share.VerificationKey()
+	}
+	require.NoError(t, CheckVerificationKeys(pk, keyList, 3))
+	assert.Error(t, CheckVerificationKeys(pk, keyList, 2))
+	keyList[3], keyList[4] = keyList[4], keyList[3]
+	assert.Error(t, CheckVerificationKeys(pk, keyList, 3))
+
+	choices := []int{0, 1, 1, 2, 1, 0, 1}
+	result := NewEncryptedTally(ctx, len(candidates))
+	for _, choice := range choices {
+		ballot, err := EncryptBallot(pk, ctx, len(candidates), choice, nil)
+		require.NoError(t, err)
+		require.NoError(t, result.Add(pk, ballot))
+	}
+	assert.Equal(t, len(choices), result.Ballots)
+
+	// Trustees work from the published tally
+	published, err := ParseEncryptedTally(result.String(), ctx)
+	require.NoError(t, err)
+
+	partials := make([]*DecryptionShare, len(shares))
+	for i, share := range shares {
+		partial, err := share.PartialDecrypt(published, nil)
+		require.NoError(t, err)
+
+		// Shares are submitted as JSON
+		data, err := json.Marshal(partial)
+		require.NoError(t, err)
+		var decoded DecryptionShare
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		partials[i] = &decoded
+	}
+
+	want := []int64{2, 4, 1}
+	counts, err := Combine(published, partials[:3], verificationKeys, 3)
+	require.NoError(t, err)
+	assert.Equal(t, want, counts)
+
+	// Any three trustees give the same result
+	counts, err = Combine(published, []*DecryptionShare{partials[4], partials[1], partials[3]}, verificationKeys, 3)
+	require.NoError(t, err)
+	assert.Equal(t, want, counts)
+
+	// Two trustees are not enough, even if one submits twice
+	_, err = Combine(published, []*DecryptionShare{partials[0], partials[0], partials[1]}, verificationKeys, 3)
+	assert.Error(t, err)
+
+	// A share computed with the wrong key is rejected
+	wrong := shares[0]
+	wrong.X = shares[1].X
+	bad, err := wrong.PartialDecrypt(published, nil)
+	require.NoError(t, err)
+	_, err = Combine(published, []*DecryptionShare{bad, partials[1], partials[2]}, verificationKeys, 3)
+	assert.ErrorIs(t, err, ErrInvalidProof)
+}
+
+func TestBallotRejectedByTally(t *testing.T) {
+	sk, err := GenerateKey(nil)
+	require.NoError(t, err)
+	other, err := GenerateKey(nil)
+	require.NoError(t, err)
+	ctx := BallotContext("1", candidates)
+
+	// A ballot encrypted under another key does not verify
+	ballot, err := EncryptBallot(other.PublicKey, ctx, len(candidates), 0, nil)
+	require.NoError(t, err)
+	result := NewEncryptedTally(ctx, len(candidates))
+	assert.Error(t, result.Add(sk.PublicKey, ballot))
+	assert.Equal(t, 0, result.Ballots)
+}
+
+func TestDealCommitments(t *testing.T) {
+	dealing, err := Deal(2, 4, nil)
+	require.NoError(t, err)
+	require.Len(t, dealing.Commitments, 2)
+	assert.True(t, dealing.Commitments[0].Equal(&dealing.PublicKey.H))
+
+	keys := make([]Point, len(dealing.Shares))
+	for i, share := range dealing.Shares {
+		require.NoError(t, share.Verify(dealing.Commitments))
+		keys[i] = CommitmentVerificationKey(dealing.Commitments, share.Index)
+
+		// Shares survive the JSON handed to trustees
+		data, err := json.Marshal(share)
+		require.NoError(t, err)
+		var decoded KeyShare
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, share.Index, decoded.Index)
+		assert.Equal(t, 0, share.X.Cmp(decoded.X))
+	}
+	require.NoError(t, CheckVerificationKeys(dealing.PublicKey, keys, 2))
+
+	// A share for another index or a tampered value is rejected
+	wrongIndex := KeyShare{Index: 2, X: dealing.Shares[0].X}
+	assert.Error(t, wrongIndex.Verify(dealing.Commitments))
+	tampered := KeyShare{Index: 1, X: new(big.Int).Add(dealing.Shares[0].X, big.NewInt(1))}
+	assert.Error(t, tampered.Verify(dealing.Commitments))
+}
@@ -0,0 +1,298 @@
+package tally
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// KeyShare is a trustee's Shamir share f(Index) of an election private key
+type KeyShare struct {
+	Index int
+	X     *big.Int
+}
+
+// VerificationKey returns the trustee's public share x_i·G, used to check its
+// partial decryptions
+func (s KeyShare) VerificationKey() Point {
+	return mulBase(s.X)
+}
+
+// Verify checks the share against the dealer's Feldman commitments, so a
+// trustee can confirm it received a valid share of the published key
+func (s KeyShare) Verify(commitments []Point) error {
+	if s.X == nil || s.Index < 1 {
+		return errors.New("tally: malformed key share")
+	}
+	expected := CommitmentVerificationKey(commitments, s.Index)
+	actual := s.VerificationKey()
+	if !actual.Equal(&expected) {
+		return fmt.Errorf("tally: key share %d does not match the commitments", s.Index)
+	}
+	return nil
+}
+
+type encodedKeyShare struct {
+	Index int    `json:"index"`
+	X     string `json:"x"`
+}
+
+// MarshalJSON encodes the share with its scalar in hex
+func (s KeyShare) MarshalJSON() ([]byte, error) {
+	return json.Marshal(encodedKeyShare{Index: s.Index, X: encodeScalar(s.X)})
+}
+
+// UnmarshalJSON decodes a share produced by MarshalJSON
+func (s *KeyShare) UnmarshalJSON(data []byte) error {
+	var e encodedKeyShare
+	if err := json.Unmarshal(data, &e); err != nil {
+		return err
+	}
+	x, err := decodeScalar(e.X)
+	if err != nil {
+		return err
+	}
+	s.Index, s.X = e.Index, x
+	return nil
+}
+
+// SplitKey splits a private key into n shares, any threshold of which can
+// decrypt. Share indices run from 1 to n.
+func SplitKey(sk *PrivateKey, threshold, n int, random io.Reader) ([]KeyShare, error) {
+	shares, _, err := splitKey(sk, threshold, n, random)
+	return shares, err
+}
+
+func splitKey(sk *PrivateKey, threshold, n int, random io.Reader) ([]KeyShare, []*big.Int, error) {
+	if threshold < 1 || threshold > n {
+		return nil, nil, fmt.Errorf("tally: invalid threshold %d of %d", threshold, n)
+	}
+
+	// f(z) = x + a1·z + ... + a(t-1)·z^(t-1)
+	coeffs := make([]*big.Int, threshold)
+	coeffs[0] = new(big.Int).Set(sk.X)
+	for i := 1; i < threshold; i++ {
+		a, err := randomScalar(random)
+		if err != nil {
+			return nil, nil, err
+		}
+		coeffs[i] = a
+	}
+
+	shares := make([]KeyShare, n)
+	for i := 1; i <= n; i++ {
+		shares[i-1] = KeyShare{Index: i, X: evalPolynomial(coeffs, int64(i))}
+	}
+	return shares, coeffs, nil
+}
+
+// Dealing is the outcome of a key ceremony: a fresh election key split into
+// trustee shares, with Feldman commitments a_j·G to the sharing polynomial.
+// Commitments[0] is the public key. The private key itself is not kept.
+type Dealing struct {
+	PublicKey   PublicKey
+	Shares      []KeyShare
+	Commitments []Point
+}
+
+// Deal generates an election key and splits it t-of-n with Feldman commitments
+func Deal(threshold, n int, random io.Reader) (*Dealing, error) {
+	sk, err := GenerateKey(random)
+	if err != nil {
+		return nil, err
+	}
+	shares, coeffs, err := splitKey(sk, threshold, n, random)
+	if err != nil {
+		return nil, err
+	}
+
+	commitments := make([]Point, len(coeffs))
+	for i, a := range coeffs {
+		commitments[i] = mulBase(a)
+	}
+	return &Dealing{PublicKey: sk.PublicKey, Shares: shares, Commitments: commitments}, nil
+}
+
+// CommitmentVerificationKey derives trustee index's verification key from
+// Feldman commitments: the sum of C_j·index^j
+func CommitmentVerificationKey(commitments []Point, index int) Point {
+	var vk Point
+	vk.SetInfinity()
+	power := big.NewInt(1)
+	z := big.NewInt(int64(index))
+	for _, c := range commitments {
+		vk = add(vk, mul(c, power))
+		power = modN(new(big.Int).Mul(power, z))
+	}
+	return vk
+}
+
+// CheckVerificationKeys checks that trustee verification keys, index i+1 at
+// position i, lie on one polynomial of degree threshold-1 whose value at zero
+// is the public key. Every window of threshold consecutive keys must
+// interpolate to the public key; two overlapping windows agree on threshold
+// points, so this pins all keys to the same polynomial.
+func CheckVerificationKeys(pk PublicKey, verificationKeys []Point, threshold int) error {
+	if threshold < 1 || threshold > len(verificationKeys) {
+		return fmt.Errorf("tally: invalid threshold %d of %d", threshold, len(verificationKeys))
+	}
+
+	for start := 1; start+threshold-1 <= len(verificationKeys); start++ {
+		indices := make([]int, threshold)
+		for i := range indices {
+			indices[i] = start + i
+		}
+
+		var h Point
+		h.SetInfinity()
+		for _, i := range indices {
+			h = add(h, mul(verificationKeys[i-1], lagrangeAtZero(i, indices)))
+		}
+		if !h.Equal(&pk.H) {
+			return fmt.Errorf("tally: verification keys %d to %d do not match the public key",
+				start, start+threshold-1)
+		}
+	}
+	return nil
+}
+
+// evalPolynomial evaluates the polynomial with the given coefficients at z, mod the group order
+func evalPolynomial(coeffs []*big.Int, z int64) *big.Int {
+	zBig := big.NewInt(z)
+	result := new(big.Int)
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		result.Mul(result, zBig)
+		result.Add(result, coeffs[i])
+		modN(result)
+	}
+	return result
+}
+
+// lagrangeAtZero returns the Lagrange coefficient of index i for interpolating at zero
+func lagrangeAtZero(i int, indices []int) *big.Int {
+	num, den := big.NewInt(1), big.NewInt(1)
+	for _, j := range indices {
+		if j == i {
+			continue
+		}
+		num.Mul(num, big.NewInt(int64(j)))
+		den.Mul(den, big.NewInt(int64(j-i)))
+	}
+	den.Mod(den, order())
+	den.ModInverse(den, order())
+	return modN(num.Mul(num, den))
+}
+
+// DecryptionShare is a trustee's partial decryption of every ciphertext of a
+// tally, each with a proof that it used the trustee's key share
+type DecryptionShare struct {
+	Index   int             `json:"index"`
+	Factors []PointJSON     `json:"factors"` // x_i·C1 per candidate
+	Proofs  []EqualityProof `json:"proofs"`
+}
+
+// PointJSON is a Point that encodes as hex in JSON
+type PointJSON struct {
+	Point
+}
+
+// MarshalJSON encodes the point as hex
+func (p PointJSON) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + EncodePoint(p.Point) + `"`), nil
+}
+
+// UnmarshalJSON decodes a hex point
+func (p *PointJSON) UnmarshalJSON(data []byte) error {
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return ErrInvalidPoint
+	}
+	point, err := DecodePoint(string(data[1 : len(data)-1]))
+	if err != nil {
+		return err
+	}
+	p.Point = point
+	return nil
+}
+
+// PartialDecrypt computes a trustee's decryption share for an encrypted tally
+func (s KeyShare) PartialDecrypt(result *EncryptedTally, random io.Reader) (*DecryptionShare, error) {
+	vk := s.VerificationKey()
+	share := &DecryptionShare{
+		Index:   s.Index,
+		Factors: make([]PointJSON, len(result.Sums)),
+		Proofs:  make([]EqualityProof, len(result.Sums)),
+	}
+	for i, ct := range result.Sums {
+		factor := mul(ct.C1, s.X)
+		proof, err := proveEquality(result.Context, s.X, vk, ct.C1, factor, random)
+		if err != nil {
+			return nil, err
+		}
+		share.Factors[i] = PointJSON{factor}
+		share.Proofs[i] = proof
+	}
+	return share, nil
+}
+
+// VerifyShare checks a decryption share against the trustee's verification key
+func VerifyShare(result *EncryptedTally, share *DecryptionShare, vk Point) error {
+	if len(share.Factors) != len(result.Sums) || len(share.Proofs) != len(result.Sums) {
+		return errors.New("tally: decryption share does not match the tally")
+	}
+	for i, ct := range result.Sums {
+		if !verifyEquality(result.Context, share.Proofs[i], vk, ct.C1, share.Factors[i].Point) {
+			return fmt.Errorf("%w: decryption share %d, candidate %d", ErrInvalidProof, share.Index, i)
+		}
+	}
+	return nil
+}
+
+// Combine verifies decryption shares and, given at least threshold valid ones,
+// decrypts the tally. verificationKeys maps trustee index to verification key.
+func Combine(result *EncryptedTally, shares []*DecryptionShare, verificationKeys map[int]Point, threshold int) ([]int64, error) {
+	seen := make(map[int]bool)
+	var valid []*DecryptionShare
+	for _, share := range shares {
+		vk, ok := verificationKeys[share.Index]
+		if !ok {
+			return nil, fmt.Errorf("tally: unknown trustee %d", share.Index)
+		}
+		if seen[share.Index] {
+			continue
+		}
+		if err := VerifyShare(result, share, vk); err != nil {
+			return nil, err
+		}
+		seen[share.Index] = true
+		valid = append(valid, share)
+		if len(valid) == threshold {
+			break
+		}
+	}
+	if threshold < 1 || len(valid) < threshold {
+		return nil, fmt.Errorf("tally: %d valid decryption shares, need %d", len(valid), threshold)
+	}
+
+	indices := make([]int, len(valid))
+	for i, share := range valid {
+		indices[i] = share.Index
+	}
+
+	counts := make([]int64, len(result.Sums))
+	for c, ct := range result.Sums {
+		// x·C1 = sum of λ_i · (x_i·C1)
+		var xC1 Point
+		xC1.SetInfinity()
+		for _, share := range valid {
+			xC1 = add(xC1, mul(share.Factors[c].Point, lagrangeAtZero(share.Index, indices)))
+		}
+		count, err := discreteLog(sub(ct.C2, xC1), int64(result.Ballots))
+		if err != nil {
+			return nil, err
+		}
+		counts[c] = count
+	}
+	return counts, nil
+}
@@ -0,0 +1,544 @@
+package terminal
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"voting-system/internal/api/types"
+	"voting-system/internal/biometric"
+	"voting-system/internal/tally"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRequest is a voter registration taken on the terminal. The polling
+// unit is the terminal's own.
+type RegisterRequest struct {
+	NIN             string    `json:"nin" binding:"required"`
+	FirstName       string    `json:"first_name" binding:"required"`
+	LastName        string    `json:"last_name" binding:"required"`
+	DateOfBirth     time.Time `json:"date_of_birth" binding:"required"`
+	Gender          string    `json:"gender" binding:"required"`
+	FingerprintData string    `json:"fingerprint_data" binding:"required"`
+}
+
+// VerifyRequest identifies a voter at the terminal
+type VerifyRequest struct {
+	NIN             string `json:"nin" binding:"required"`
+	FingerprintData string `json:"fingerprint_data" binding:"required"`
+}
+
+// CastRequest is a vote taken on the terminal. Elections with a ballot key
+// take an encrypted Ballot instead of a CandidateID.
+type CastRequest struct {
+	NIN             string        `json:"nin" binding:"required"`
+	FingerprintData string        `json:"fingerprint_data" binding:"required"`
+	CandidateID     string        `json:"candidate_id"`
+	Ballot          *tally.Ballot `json:"ballot,omitempty"`
+}
+
+// SetupRoutes registers the terminal's local REST API
+func (t *Terminal) SetupRoutes(router *gin.Engine) {
+	local := router.Group("/api/v1")
+	local.Use(t.tokenRequired())
+	{
+		local.GET("/status", t.getStatus)
+		local.GET("/election", t.getElection)
+		local.POST("/voters", t.registerVoter)
+		local.POST("/voters/verify", t.verifyVoter)
+		local.POST("/votes", t.castVote)
+		local.GET("/votes/:hash", t.getVote)
+		local.GET("/journal", t.listJournal)
+		local.POST("/sync", t.sync)
+		local.GET("/reconciliation", t.reconcile)
+	}
+}
+
+// tokenRequired checks the bearer token when terminal.api_token is set
+func (t *Terminal) tokenRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if t.cfg.APIToken == "" {
+			c.Next()
+			return
+		}
+
+		token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(t.cfg.APIToken)) != 1 {
+			c.JSON(http.StatusUnauthorized, types.ErrorResponse{
+				Error:   "unauthorized",
+				Code:    http.StatusUnauthorized,
+				Message: "Invalid or missing API token",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func (t *Terminal) getStatus(c *gin.Context) {
+	voters, err := t.store.CountVoters()
+	if err != nil {
+		t.internalError(c, "Failed to count voters", err)
+		return
+	}
+	journal, err := t.store.JournalCounts()
+	if err != nil {
+		t.internalError(c, "Failed to count journal entries", err)
+		return
+	}
+	voted, err := t.store.CountVotedSet()
+	if err != nil {
+		t.internalError(c, "Failed to count voted set", err)
+		return
+	}
+
+	t.remoteMutex.RLock()
+	remote, heartbeat := t.remote, t.lastHeartbeat
+	t.remoteMutex.RUnlock()
+	if remote != nil {
+		// The config's secrets stay on the terminal
+		redacted := *remote
+		redacted.VerificationSalt, redacted.FingerprintKey = "", ""
+		remote = &redacted
+	}
+
+	c.JSON(http.StatusOK, types.SuccessResponse{
+		Success: true,
+		Data: gin.H{
+			"device_id":       t.cfg.DeviceID,
+			"firmware":        Version,
+			"address":         t.Address().Hex(),
+			"polling_unit_id": t.cfg.PollingUnitID,
+			"server_url":      t.cfg.ServerURL,
+			"authenticated":   t.client.Authenticated(),
+			"online":          t.forwarder.Online(),
+			"voters":          voters,
+			"voted_set":       voted,
+			"journal":         journal,
+			"config":          remote,
+			"last_heartbeat":  heartbeat,
+			"timestamp":       time.Now().UTC(),
+		},
+	})
+}
+
+func (t *Terminal) getElection(c *gin.Context) {
+	election, cached, err := t.CurrentElection()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
+			Error:   "server_unreachable",
+			Code:    http.StatusServiceUnavailable,
+			Message: "Current election is not available: " + err.Error(),
+		})
+		return
+	}
+
+	message := "Current election retrieved"
+	if cached {
+		message = "Central server unreachable, showing the last election seen"
+	}
+	c.JSON(http.StatusOK, types.SuccessResponse{
+		Success: true,
+		Data:    election,
+		Message: message,
+	})
+}
+
+func (t *Terminal) registerVoter(c *gin.Context) {
+	var req RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, err)
+		return
+	}
+
+	// The server searches all its voters for the same finger once the
+	// registration is uploaded; the terminal only checks the capture's quality
+	matcher, err := t.biometrics()
+	if err != nil {
+		t.configError(c, err)
+		return
+	}
+	template, err := matcher.Enrol(req.FingerprintData)
+	switch {
+	case errors.Is(err, biometric.ErrLowQuality):
+		c.JSON(http.StatusUnprocessableEntity, types.ErrorResponse{
+			Error:   "low_quality_fingerprint",
+			Code:    http.StatusUnprocessableEntity,
+			Message: fmt.Sprintf("Fingerprint quality %.2f is too low; capture it again", template.Quality),
+		})
+		return
+	case errors.Is(err, biometric.ErrInvalidSample):
+		badRequest(c, err)
+		return
+	case err != nil:
+		t.internalError(c, "Failed to enrol fingerprint", err)
+		return
+	}
+
+	registration, err := json.Marshal(types.VoterRegistrationRequest{
+		NIN:             req.NIN,
+		FirstName:       req.FirstName,
+		LastName:        req.LastName,
+		DateOfBirth:     req.DateOfBirth,
+		Gender:          req.Gender,
+		PollingUnitID:   t.cfg.PollingUnitID,
+		FingerprintData: req.FingerprintData,
+	})
+	if err != nil {
+		t.internalError(c, "Failed to encode registration", err)
+		return
+	}
+
+	voter := &Voter{
+		NIN:             req.NIN,
+		FirstName:       req.FirstName,
+		LastName:        req.LastName,
+		PollingUnitID:   t.cfg.PollingUnitID,
+		FingerprintHash: matcher.Hash(req.FingerprintData),
+		TemplateFormat:  template.Format,
+		Template:        template.Data,
+	}
+	if err := t.store.RegisterVoter(voter, string(registration)); err != nil {
+		if errors.Is(err, ErrVoterExists) {
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "voter_exists",
+				Code:    http.StatusConflict,
+				Message: "Voter with this NIN or fingerprint is already registered",
+			})
+			return
+		}
+		t.internalError(c, "Failed to store voter", err)
+		return
+	}
+
+	t.logger.Info("Voter registered on terminal: %s", req.NIN)
+	t.forwarder.Wake()
+
+	c.JSON(http.StatusAccepted, types.SuccessResponse{
+		Success: true,
+		Data:    voter,
+		Message: "Voter registered; registration will be uploaded to the central server",
+	})
+}
+
+func (t *Terminal) verifyVoter(c *gin.Context) {
+	var req VerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, err)
+		return
+	}
+
+	voter, ok := t.identify(c, req.NIN, req.FingerprintData)
+	if !ok {
+		return
+	}
+
+	_, hash, err := t.verificationHash(voter.NIN)
+	if err != nil {
+		t.configError(c, err)
+		return
+	}
+	hasVoted, err := t.store.HasVoted(hash)
+	if err != nil {
+		t.internalError(c, "Failed to check vote journal", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.SuccessResponse{
+		Success: true,
+		Data: gin.H{
+			"voter":     voter,
+			"has_voted": hasVoted,
+		},
+	})
+}
+
+func (t *Terminal) castVote(c *gin.Context) {
+	var req CastRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, err)
+		return
+	}
+	if req.CandidateID == "" && req.Ballot == nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    http.StatusBadRequest,
+			Message: "Either candidate_id or ballot is required",
+		})
+		return
+	}
+
+	voter, ok := t.identify(c, req.NIN, req.FingerprintData)
+	if !ok {
+		return
+	}
+
+	electionID, hash, err := t.verificationHash(voter.NIN)
+	if err != nil {
+		t.configError(c, err)
+		return
+	}
+	vote := types.VoteRequest{
+		NIN:             req.NIN,
+		FingerprintData: req.FingerprintData,
+		CandidateID:     req.CandidateID,
+		Ballot:          req.Ballot,
+		PollingUnitID:   t.cfg.PollingUnitID,
+		ElectionID:      electionID,
+	}
+	if err := t.signVote(&vote, hash); err != nil {
+		t.internalError(c, "Failed to sign vote", err)
+		return
+	}
+	payload, err := json.Marshal(vote)
+	if err != nil {
+		t.internalError(c, "Failed to encode vote", err)
+		return
+	}
+
+	entry := &JournalEntry{
+		VerificationHash: hash,
+		PollingUnitID:    t.cfg.PollingUnitID,
+		Payload:          string(payload),
+	}
+	if err := t.store.AppendVote(entry); err != nil {
+		if errors.Is(err, ErrAlreadyVoted) {
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Error:   "already_voted",
+				Code:    http.StatusConflict,
+				Message: "Voter has already cast a vote",
+			})
+			return
+		}
+		t.internalError(c, "Failed to journal vote", err)
+		return
+	}
+
+	// The vote is safe in the journal; while online, deliver it as the voter waits
+	t.forwarder.Flush()
+	if stored, err := t.store.GetVote(entry.VerificationHash); err == nil {
+		entry = stored
+	}
+
+	switch entry.Status {
+	case VoteForwarded:
+		c.JSON(http.StatusCreated, types.SuccessResponse{
+			Success: true,
+			Data:    entry,
+			Message: "Vote cast successfully",
+		})
+	case VoteRejected:
+		message := "Vote rejected by the central server"
+		if entry.LastError != nil {
+			message += ": " + *entry.LastError
+		}
+		code := http.StatusUnprocessableEntity
+		if entry.ResponseCode != nil {
+			code = *entry.ResponseCode
+		}
+		c.JSON(code, types.ErrorResponse{
+			Error:   "vote_rejected",
+			Code:    code,
+			Message: message,
+		})
+	default:
+		c.JSON(http.StatusAccepted, types.SuccessResponse{
+			Success: true,
+			Data:    entry,
+			Message: "Vote recorded on the terminal and will be forwarded when the server is reachable",
+		})
+	}
+}
+
+func (t *Terminal) getVote(c *gin.Context) {
+	entry, err := t.store.GetVote(c.Param("hash"))
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, types.ErrorResponse{
+			Error:   "vote_not_found",
+			Code:    http.StatusNotFound,
+			Message: "No vote with this verification hash on the terminal",
+		})
+		return
+	}
+	if err != nil {
+		t.internalError(c, "Failed to get vote", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.SuccessResponse{
+		Success: true,
+		Data:    entry,
+	})
+}
+
+func (t *Terminal) listJournal(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	entries, err := t.store.ListJournal(c.Query("status"), limit, offset)
+	if err != nil {
+		t.internalError(c, "Failed to list journal", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.SuccessResponse{
+		Success: true,
+		Data:    entries,
+	})
+}
+
+func (t *Terminal) sync(c *gin.Context) {
+	delivered, failed, err := t.forwarder.ForwardNow()
+	if err != nil {
+		t.internalError(c, "Failed to forward journal", err)
+		return
+	}
+
+	refreshError := ""
+	if err := t.RefreshRoster(); err != nil {
+		refreshError = err.Error()
+	} else if err := t.RefreshVotedSet(); err != nil {
+		refreshError = err.Error()
+	}
+
+	c.JSON(http.StatusOK, types.SuccessResponse{
+		Success: true,
+		Data: gin.H{
+			"delivered":     delivered,
+			"not_delivered": failed,
+			"refresh_error": refreshError,
+		},
+		Message: "Sync completed",
+	})
+}
+
+func (t *Terminal) reconcile(c *gin.Context) {
+	report, err := t.Reconcile()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
+			Error:   "server_unreachable",
+			Code:    http.StatusServiceUnavailable,
+			Message: "Failed to reconcile journal: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, types.SuccessResponse{
+		Success: true,
+		Data:    report,
+	})
+}
+
+// identify looks the voter up in the local store and matches the fingerprint
+// against their enrolled template. Failed matches count towards the lockout.
+func (t *Terminal) identify(c *gin.Context, nin, fingerprintData string) (*Voter, bool) {
+	voter, err := t.store.GetVoter(nin)
+	if err == sql.ErrNoRows || (err == nil && !voter.IsActive) {
+		c.JSON(http.StatusNotFound, types.ErrorResponse{
+			Error:   "voter_not_found",
+			Code:    http.StatusNotFound,
+			Message: "Voter is not registered at this polling unit",
+		})
+		return nil, false
+	}
+	if err != nil {
+		t.internalError(c, "Failed to look up voter", err)
+		return nil, false
+	}
+
+	if until, locked := t.lockedUntil(nin); locked {
+		biometricLocked(c, until)
+		return nil, false
+	}
+
+	matcher, err := t.biometrics()
+	if err != nil {
+		t.configError(c, err)
+		return nil, false
+	}
+	template := &biometric.Template{Format: voter.TemplateFormat, Data: voter.Template}
+	if voter.TemplateFormat == "" {
+		template = &biometric.Template{Format: biometric.FormatSHA256, Data: voter.FingerprintHash}
+	}
+	_, matched, err := matcher.Verify(fingerprintData, template)
+	if err != nil && !errors.Is(err, biometric.ErrInvalidSample) {
+		t.internalError(c, "Failed to match fingerprint", err)
+		return nil, false
+	}
+
+	if !matched {
+		if until, locked := t.recordMatchFailure(nin, matcher.MaxAttempts()); locked {
+			t.logger.Warning("Voter %s locked out after too many failed fingerprint matches", nin)
+			biometricLocked(c, until)
+			return nil, false
+		}
+		c.JSON(http.StatusUnauthorized, types.ErrorResponse{
+			Error:   "fingerprint_mismatch",
+			Code:    http.StatusUnauthorized,
+			Message: "Fingerprint does not match",
+		})
+		return nil, false
+	}
+	t.resetMatchFailures(nin)
+	return voter, true
+}
+
+func biometricLocked(c *gin.Context, until time.Time) {
+	c.JSON(http.StatusLocked, types.ErrorResponse{
+		Error:   "biometric_locked",
+		Code:    http.StatusLocked,
+		Message: fmt.Sprintf("Too many failed fingerprint matches. Try again after %s", until.UTC().Format(time.RFC3339)),
+	})
+}
+
+func badRequest(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, types.ErrorResponse{
+		Error:   "invalid_request",
+		Code:    http.StatusBadRequest,
+		Message: err.Error(),
+	})
+}
+
+// configError reports a request the terminal cannot serve without the remote
+// config, or outside an election
+func (t *Terminal) configError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, types.ErrorResponse{
+			Error:   "terminal_not_configured",
+			Code:    http.StatusServiceUnavailable,
+			Message: "Terminal has not fetched its config from the central server yet",
+		})
+	case errors.Is(err, ErrNoElection):
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "no_active_election",
+			Code:    http.StatusBadRequest,
+			Message: "No active election found",
+		})
+	default:
+		t.internalError(c, "Invalid terminal config", err)
+	}
+}
+
+func (t *Terminal) internalError(c *gin.Context, message string, err error) {
+	t.logger.Error("%s: %v", message, err)
+	c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+		Error:   "internal_error",
+		Code:    http.StatusInternalServerError,
+		Message: message,
+	})
+}
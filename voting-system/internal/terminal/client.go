@@ -0,0 +1,310 @@
+package terminal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"voting-system/internal/api/types"
+	"voting-system/internal/auth"
+)
+
+// Central server endpoints used by the terminal
+const (
+	tokenPath           = "/api/v1/public/token/terminal"
+	enrolPath           = "/api/v1/public/terminal/enrol"
+	registerPath        = "/api/v1/public/voter/register"
+	rosterPath          = "/api/v1/terminal/voters"
+	votedPath           = "/api/v1/terminal/voted"
+	journalPath         = "/api/v1/terminal/journal"
+	reconciliationPath  = "/api/v1/terminal/journal/reconciliation"
+	currentElectionPath = "/api/v1/public/election/current"
+	heartbeatPath       = "/api/v1/terminal/%s/heartbeat"
+	configPath          = "/api/v1/terminal/%s/config"
+)
+
+// Response is the central server's answer to a request
+type Response struct {
+	StatusCode int
+	Body       []byte
+}
+
+// Error returns the server's error code and message, if the body holds one
+func (r *Response) Error() string {
+	var e types.ErrorResponse
+	if err := json.Unmarshal(r.Body, &e); err == nil && e.Error != "" {
+		if e.Message != "" {
+			return e.Error + ": " + e.Message
+		}
+		return e.Error
+	}
+	return fmt.Sprintf("HTTP %d", r.StatusCode)
+}
+
+// Client talks to the central server on behalf of the terminal. It holds a
+// device token from /public/token/terminal and renews it when it is refused.
+type Client struct {
+	baseURL      string
+	deviceID     string
+	sharedSecret string
+	http         *http.Client
+
+	mutex sync.Mutex
+	token string
+}
+
+// NewClient creates a client for the central server at baseURL
+func NewClient(baseURL, deviceID, sharedSecret string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		deviceID:     deviceID,
+		sharedSecret: sharedSecret,
+		http:         &http.Client{Timeout: timeout},
+	}
+}
+
+// Authenticate fetches a new device token. With the terminal's secret
+// configured the request is signed with HMAC-SHA256 over
+// "ts|nonce|device_id"; the server accepts each nonce once.
+func (c *Client) Authenticate() error {
+	body := types.TerminalTokenRequest{DeviceID: c.deviceID}
+	if c.sharedSecret != "" {
+		nonce, err := auth.RandomToken(16)
+		if err != nil {
+			return err
+		}
+		body.Timestamp = strconv.FormatInt(time.Now().Unix(), 10)
+		body.Nonce = nonce
+		body.Signature = auth.TerminalTokenMAC(c.sharedSecret, body.Timestamp, nonce, c.deviceID)
+	}
+
+	resp, err := c.send(http.MethodPost, tokenPath, body, "")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("token request refused: %s", resp.Error())
+	}
+
+	var envelope struct {
+		Data struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body, &envelope); err != nil || envelope.Data.Token == "" {
+		return fmt.Errorf("token response has no token")
+	}
+
+	c.mutex.Lock()
+	c.token = envelope.Data.Token
+	c.mutex.Unlock()
+	return nil
+}
+
+// Enrol submits the terminal's signed enrolment request. The server answers
+// with the terminal's status, which stays pending until an admin approves it.
+func (c *Client) Enrol(req types.TerminalEnrolmentRequest) (*types.TerminalEnrolmentResponse, error) {
+	resp, err := c.send(http.MethodPost, enrolPath, req, "")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return nil, fmt.Errorf("enrolment refused: %s", resp.Error())
+	}
+
+	var envelope struct {
+		Data types.TerminalEnrolmentResponse `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid enrolment response: %v", err)
+	}
+	return &envelope.Data, nil
+}
+
+// Authenticated reports whether the client holds a device token
+func (c *Client) Authenticated() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.token != ""
+}
+
+// RegisterVoter forwards a voter registration request
+func (c *Client) RegisterVoter(payload json.RawMessage) (*Response, error) {
+	return c.do(http.MethodPost, registerPath, payload)
+}
+
+// CurrentElection returns the server's current election
+func (c *Client) CurrentElection() (*Response, error) {
+	return c.send(http.MethodGet, currentElectionPath, nil, "")
+}
+
+// Roster downloads the active voters of a polling unit
+func (c *Client) Roster(pollingUnitID string) ([]types.RosterVoter, error) {
+	resp, err := c.do(http.MethodGet, rosterPath+"?polling_unit_id="+url.QueryEscape(pollingUnitID), nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("roster request refused: %s", resp.Error())
+	}
+
+	var envelope struct {
+		Data []types.RosterVoter `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid roster response: %v", err)
+	}
+	return envelope.Data, nil
+}
+
+// UploadJournal sends a batch of chained journal entries
+func (c *Client) UploadJournal(upload types.JournalUpload) (*Response, error) {
+	return c.do(http.MethodPost, journalPath, upload)
+}
+
+// VotedSet downloads the verification hashes of everyone who has voted at a polling unit
+func (c *Client) VotedSet(pollingUnitID string) ([]string, error) {
+	resp, err := c.do(http.MethodGet, votedPath+"?polling_unit_id="+url.QueryEscape(pollingUnitID), nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("voted set request refused: %s", resp.Error())
+	}
+
+	var envelope struct {
+		Data []string `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid voted set response: %v", err)
+	}
+	return envelope.Data, nil
+}
+
+// Reconciliation fetches the server's report on this terminal's uploaded journal
+func (c *Client) Reconciliation() (*types.ReconciliationReport, error) {
+	resp, err := c.do(http.MethodGet, reconciliationPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("reconciliation request refused: %s", resp.Error())
+	}
+
+	var envelope struct {
+		Data types.ReconciliationReport `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid reconciliation response: %v", err)
+	}
+	return &envelope.Data, nil
+}
+
+// Heartbeat reports the terminal's health
+func (c *Client) Heartbeat(heartbeat types.TerminalHeartbeat) (*types.HeartbeatResponse, error) {
+	resp, err := c.do(http.MethodPost, fmt.Sprintf(heartbeatPath, url.PathEscape(c.deviceID)), heartbeat)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("heartbeat refused: %s", resp.Error())
+	}
+
+	var envelope struct {
+		Data types.HeartbeatResponse `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid heartbeat response: %v", err)
+	}
+	return &envelope.Data, nil
+}
+
+// Config fetches the terminal's remote config
+func (c *Client) Config() (*types.TerminalRemoteConfig, error) {
+	resp, err := c.do(http.MethodGet, fmt.Sprintf(configPath, url.PathEscape(c.deviceID)), nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("config request refused: %s", resp.Error())
+	}
+
+	var envelope struct {
+		Data types.TerminalRemoteConfig `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid config response: %v", err)
+	}
+	return &envelope.Data, nil
+}
+
+// do sends an authenticated request, renewing the token once if it is refused
+func (c *Client) do(method, path string, body interface{}) (*Response, error) {
+	if !c.Authenticated() {
+		if err := c.Authenticate(); err != nil {
+			return nil, err
+		}
+	}
+
+	c.mutex.Lock()
+	token := c.token
+	c.mutex.Unlock()
+
+	resp, err := c.send(method, path, body, token)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	if err := c.Authenticate(); err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	token = c.token
+	c.mutex.Unlock()
+	return c.send(method, path, body, token)
+}
+
+func (c *Client) send(method, path string, body interface{}, token string) (*Response, error) {
+	var reader io.Reader
+	if body != nil {
+		encoded, ok := body.(json.RawMessage)
+		if !ok {
+			var err error
+			if encoded, err = json.Marshal(body); err != nil {
+				return nil, err
+			}
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	return &Response{StatusCode: resp.StatusCode, Body: data}, nil
+}
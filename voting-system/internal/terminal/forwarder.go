@@ -0,0 +1,354 @@
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"voting-system/internal/api/types"
+	"voting-system/pkg/logger"
+)
+
+// ForwarderOptions tunes the forwarder. Zero values keep the defaults.
+type ForwarderOptions struct {
+	Interval      time.Duration // how often due uploads are picked up
+	RetryInterval time.Duration // base delay before the first retry
+	MaxBackoff    time.Duration // upper bound for the exponential retry delay
+	BatchSize     int           // maximum registrations per pass and journal entries per upload
+}
+
+// Forwarder uploads the terminal's registrations and journaled votes to the
+// central server. Votes go up in chain-ordered batches to the journal endpoint,
+// which settles each entry once however often it is sent. Network errors,
+// server errors and rate limiting are retried with exponential backoff; any
+// other refusal is final. Registrations go first so the server knows a voter
+// before their vote arrives.
+type Forwarder struct {
+	store         *Store
+	client        *Client
+	logger        *logger.Logger
+	interval      time.Duration
+	retryInterval time.Duration
+	maxBackoff    time.Duration
+	batchSize     int
+
+	isRunning bool
+	online    bool // last contact with the server succeeded
+	stopChan  chan struct{}
+	wake      chan struct{}
+	mutex     sync.Mutex
+	passMutex sync.Mutex // one upload at a time, so the chain goes out in order
+}
+
+// NewForwarder creates a forwarder for the store's pending uploads
+func NewForwarder(store *Store, client *Client, logger *logger.Logger, opts ForwarderOptions) *Forwarder {
+	f := &Forwarder{
+		store:         store,
+		client:        client,
+		logger:        logger,
+		interval:      5 * time.Second,
+		retryInterval: 10 * time.Second,
+		maxBackoff:    5 * time.Minute,
+		batchSize:     50,
+		online:        true,
+		wake:          make(chan struct{}, 1),
+	}
+	if opts.Interval > 0 {
+		f.interval = opts.Interval
+	}
+	if opts.RetryInterval > 0 {
+		f.retryInterval = opts.RetryInterval
+	}
+	if opts.MaxBackoff > 0 {
+		f.maxBackoff = opts.MaxBackoff
+	}
+	if opts.BatchSize > 0 {
+		f.batchSize = opts.BatchSize
+	}
+	return f
+}
+
+// Start begins forwarding in the background
+func (f *Forwarder) Start() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.isRunning {
+		return fmt.Errorf("forwarder is already running")
+	}
+	f.isRunning = true
+	f.stopChan = make(chan struct{})
+	go f.loop()
+
+	f.logger.Info("Vote forwarder started with interval: %v", f.interval)
+	return nil
+}
+
+// Stop stops background forwarding
+func (f *Forwarder) Stop() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if !f.isRunning {
+		return
+	}
+	close(f.stopChan)
+	f.isRunning = false
+
+	f.logger.Info("Vote forwarder stopped")
+}
+
+// Wake asks the background loop to run a pass now
+func (f *Forwarder) Wake() {
+	select {
+	case f.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (f *Forwarder) loop() {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-f.wake:
+		case <-f.stopChan:
+			return
+		}
+
+		if forwarded, failed, err := f.ForwardNow(); err != nil {
+			f.logger.Error("Forwarding pass failed: %v", err)
+		} else if forwarded+failed > 0 {
+			f.logger.Info("Forwarding pass: %d delivered, %d not delivered", forwarded, failed)
+		}
+	}
+}
+
+// ForwardNow uploads every registration and vote that is due and returns how
+// many were delivered and how many were retried or rejected
+func (f *Forwarder) ForwardNow() (int, int, error) {
+	now := time.Now().UTC()
+	delivered, failed := 0, 0
+
+	voters, err := f.store.DueRegistrations(now, f.batchSize)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to load registrations: %v", err)
+	}
+	for i := range voters {
+		if f.forwardRegistration(&voters[i]) {
+			delivered++
+		} else {
+			failed++
+		}
+	}
+
+	for {
+		sent, accepted, err := f.uploadJournal(now)
+		delivered += accepted
+		failed += sent - accepted
+		if err != nil {
+			return delivered, failed, err
+		}
+		// Keep going while full batches are getting through
+		if sent < f.batchSize || accepted < sent {
+			return delivered, failed, nil
+		}
+	}
+}
+
+// Flush uploads the pending journal straight away, ignoring retry delays.
+// It does nothing while the terminal is offline, so voters never wait on an
+// unreachable server; the background loop brings the terminal back online.
+func (f *Forwarder) Flush() {
+	if !f.Online() {
+		return
+	}
+	if _, _, err := f.uploadJournal(time.Time{}); err != nil {
+		f.logger.Error("Failed to upload journal: %v", err)
+	}
+}
+
+// Online reports whether the last contact with the server succeeded
+func (f *Forwarder) Online() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.online
+}
+
+func (f *Forwarder) setOnline(online bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.online != online {
+		if online {
+			f.logger.Info("Central server reachable, leaving offline mode")
+		} else {
+			f.logger.Warning("Central server unreachable, votes are kept in the journal")
+		}
+	}
+	f.online = online
+}
+
+// uploadJournal sends the oldest batch of pending journal entries, in chain
+// order, and records the server's outcome for each. Entries are only sent
+// once the oldest one is due (a zero now sends them regardless), since the
+// server takes the chain strictly in order. It returns how many entries were
+// sent and how many the server settled.
+func (f *Forwarder) uploadJournal(now time.Time) (int, int, error) {
+	f.passMutex.Lock()
+	defer f.passMutex.Unlock()
+
+	entries, err := f.store.PendingChain(f.batchSize)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to load journal: %v", err)
+	}
+	if len(entries) == 0 || (!now.IsZero() && entries[0].NextAttemptAt.After(now)) {
+		return 0, 0, nil
+	}
+
+	upload := types.JournalUpload{Entries: make([]types.JournalUploadEntry, len(entries))}
+	for i, e := range entries {
+		upload.Entries[i] = types.JournalUploadEntry{
+			Seq:              e.Seq,
+			PrevHash:         e.PrevHash,
+			EntryHash:        e.EntryHash,
+			Signature:        e.Signature,
+			VerificationHash: e.VerificationHash,
+			CreatedAt:        e.CreatedAt,
+			Vote:             json.RawMessage(e.Payload),
+		}
+	}
+
+	resp, err := f.client.UploadJournal(upload)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		reason := describeFailure(resp, err)
+		f.setOnline(false)
+		f.logger.Warning("Failed to upload %d journal entries (attempt %d): %s", len(entries), entries[0].Attempts+1, reason)
+		next := time.Now().UTC().Add(f.backoff(entries[0].Attempts + 1))
+		for _, e := range entries {
+			if err := f.store.MarkVoteRetry(e.ID, reason, next); err != nil {
+				f.logger.Error("Failed to schedule retry for vote %d: %v", e.Seq, err)
+			}
+		}
+		return len(entries), 0, nil
+	}
+	f.setOnline(true)
+
+	var envelope struct {
+		Data types.JournalUploadResponse `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body, &envelope); err != nil {
+		return len(entries), 0, fmt.Errorf("invalid journal upload response: %v", err)
+	}
+	results := make(map[int64]types.JournalEntryResult, len(envelope.Data.Results))
+	for _, result := range envelope.Data.Results {
+		results[result.Seq] = result
+	}
+
+	settled := 0
+	next := time.Now().UTC().Add(f.retryInterval)
+	for _, e := range entries {
+		result, ok := results[e.Seq]
+		if !ok || result.EntryHash != e.EntryHash {
+			result = types.JournalEntryResult{Status: types.JournalNotProcessed}
+		}
+
+		switch result.Status {
+		case types.JournalAccepted, types.JournalQueued:
+			err = f.store.MarkVoteForwarded(e.ID, result.Status, result.Code, result.TransactionHash)
+			settled++
+		case types.JournalDuplicate, types.JournalRejected:
+			f.logger.Warning("Server refused journal entry %d: %s", e.Seq, result.Error)
+			err = f.store.MarkVoteRejected(e.ID, result.Status, result.Code, result.Error)
+			settled++
+		case types.JournalInvalid, types.JournalOutOfOrder:
+			// Retrying cannot help; the chain needs an operator
+			f.logger.Error("Server refused journal entry %d as %s: %s", e.Seq, result.Status, result.Error)
+			err = f.store.MarkVoteRetry(e.ID, result.Status+": "+result.Error, time.Now().UTC().Add(f.maxBackoff))
+		default:
+			err = f.store.MarkVoteRetry(e.ID, result.Status, next)
+		}
+		if err != nil {
+			f.logger.Error("Failed to record outcome of journal entry %d: %v", e.Seq, err)
+		}
+	}
+
+	return len(entries), settled, nil
+}
+
+// forwardRegistration uploads one local registration and records the outcome
+func (f *Forwarder) forwardRegistration(voter *Voter) bool {
+	f.passMutex.Lock()
+	defer f.passMutex.Unlock()
+
+	if voter.Registration == nil {
+		return false
+	}
+
+	resp, err := f.client.RegisterVoter(json.RawMessage(*voter.Registration))
+	if err == nil && resp.StatusCode == http.StatusConflict {
+		var e types.ErrorResponse
+		_ = json.Unmarshal(resp.Body, &e)
+		if e.Error == "voter_exists" {
+			// An earlier attempt got through but its answer was lost, or the voter
+			// registered elsewhere; either way the server now knows the NIN
+			resp.StatusCode = http.StatusOK
+		}
+	}
+
+	switch {
+	case err == nil && resp.StatusCode >= 200 && resp.StatusCode < 300:
+		if err := f.store.MarkRegistrationSynced(voter.NIN); err != nil {
+			f.logger.Error("Failed to record uploaded registration %s: %v", voter.NIN, err)
+		}
+		return true
+	case err == nil && !retryable(resp.StatusCode):
+		f.logger.Warning("Server rejected registration %s: %s", voter.NIN, resp.Error())
+		if err := f.store.MarkRegistrationRejected(voter.NIN, resp.Error()); err != nil {
+			f.logger.Error("Failed to record rejected registration %s: %v", voter.NIN, err)
+		}
+	default:
+		reason := describeFailure(resp, err)
+		f.logger.Warning("Failed to upload registration %s (attempt %d): %s", voter.NIN, voter.Attempts+1, reason)
+		if err := f.store.MarkRegistrationRetry(voter.NIN, reason, time.Now().UTC().Add(f.backoff(voter.Attempts+1))); err != nil {
+			f.logger.Error("Failed to schedule retry for registration %s: %v", voter.NIN, err)
+		}
+	}
+	return false
+}
+
+// retryable reports whether a server status is worth retrying. A 401 that
+// survives a token renewal is treated as transient, so a misconfigured secret
+// never discards votes.
+func retryable(status int) bool {
+	return status >= 500 || status == http.StatusRequestTimeout ||
+		status == http.StatusTooManyRequests || status == http.StatusUnauthorized
+}
+
+func describeFailure(resp *Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return resp.Error()
+}
+
+// backoff returns the retry delay after the given number of attempts,
+// doubling retryInterval each time up to maxBackoff
+func (f *Forwarder) backoff(attempts int) time.Duration {
+	delay := f.retryInterval
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= f.maxBackoff {
+			return f.maxBackoff
+		}
+	}
+	if delay > f.maxBackoff {
+		return f.maxBackoff
+	}
+	return delay
+}
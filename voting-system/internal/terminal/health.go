@@ -0,0 +1,134 @@
+package terminal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"voting-system/internal/api/types"
+)
+
+// Version is the terminal firmware version reported in heartbeats
+const Version = "0.5.0"
+
+func (t *Terminal) heartbeatLoop() {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			if err := t.SendHeartbeat(); err != nil {
+				t.logger.Warning("Failed to send heartbeat: %v", err)
+			}
+			timer.Reset(t.heartbeatInterval())
+		case <-t.stopChan:
+			return
+		}
+	}
+}
+
+// heartbeatInterval is the remote config's interval, or the local one until
+// the config has been fetched
+func (t *Terminal) heartbeatInterval() time.Duration {
+	t.remoteMutex.RLock()
+	defer t.remoteMutex.RUnlock()
+
+	if t.remote != nil && t.remote.HeartbeatInterval > 0 {
+		return time.Duration(t.remote.HeartbeatInterval) * time.Second
+	}
+	if t.cfg.HeartbeatInterval > 0 {
+		return t.cfg.HeartbeatInterval
+	}
+	return time.Minute
+}
+
+// SendHeartbeat reports the terminal's health and refetches the remote config
+// when the server says it changed
+func (t *Terminal) SendHeartbeat() error {
+	counts, err := t.store.JournalCounts()
+	if err != nil {
+		return fmt.Errorf("failed to count journal entries: %v", err)
+	}
+
+	heartbeat := types.TerminalHeartbeat{
+		FirmwareVersion: Version,
+		QueueDepth:      counts[VotePending],
+		Timestamp:       time.Now().UnixMilli(),
+	}
+	heartbeat.BatteryLevel, heartbeat.Charging = readBattery(t.cfg.BatteryPath)
+
+	resp, err := t.client.Heartbeat(heartbeat)
+	if err != nil {
+		return err
+	}
+	for _, alert := range resp.Alerts {
+		t.logger.Warning("Server raised a %s alert for this terminal", alert)
+	}
+
+	t.remoteMutex.Lock()
+	t.lastHeartbeat = resp
+	stale := t.remote == nil || t.remote.Version != resp.ConfigVersion
+	t.remoteMutex.Unlock()
+
+	if stale {
+		return t.RefreshConfig()
+	}
+	return nil
+}
+
+// RefreshConfig downloads the terminal's remote config
+func (t *Terminal) RefreshConfig() error {
+	remote, err := t.client.Config()
+	if err != nil {
+		return err
+	}
+	if remote.PollingUnitID != t.cfg.PollingUnitID {
+		t.logger.Warning("Server assigns this terminal to polling unit %s, but it is configured for %s",
+			remote.PollingUnitID, t.cfg.PollingUnitID)
+	}
+
+	t.remoteMutex.Lock()
+	t.remote = remote
+	t.remoteMutex.Unlock()
+
+	if err := t.store.SaveRemoteConfig(remote); err != nil {
+		t.logger.Warning("Failed to save remote config: %v", err)
+	}
+
+	t.logger.Info("Remote config %s loaded: election %q, %d candidates", remote.Version, remote.ElectionID, len(remote.Candidates))
+	return nil
+}
+
+// RemoteConfig returns the last remote config fetched from the server, or nil
+func (t *Terminal) RemoteConfig() *types.TerminalRemoteConfig {
+	t.remoteMutex.RLock()
+	defer t.remoteMutex.RUnlock()
+	return t.remote
+}
+
+// readBattery reads a sysfs power supply capacity file and the status file
+// next to it. It returns nil when there is no battery to report.
+func readBattery(path string) (*int, bool) {
+	if path == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	level, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || level < 0 || level > 100 {
+		return nil, false
+	}
+
+	status, _ := os.ReadFile(filepath.Join(filepath.Dir(path), "status"))
+	switch strings.TrimSpace(string(status)) {
+	case "Charging", "Full":
+		return &level, true
+	}
+	return &level, false
+}
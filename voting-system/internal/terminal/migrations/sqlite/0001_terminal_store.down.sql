@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS vote_journal;
+DROP TABLE IF EXISTS voters;
@@ -0,0 +1,43 @@
+-- Local store of a voting terminal: the polling unit's voters and the
+-- journal of votes cast on this device.
+
+-- Voters come from the server's roster or are registered on this terminal.
+-- Local registrations keep their request in registration until uploaded.
+CREATE TABLE voters (
+    nin VARCHAR(11) PRIMARY KEY,
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    polling_unit_id VARCHAR(50) NOT NULL,
+    fingerprint_hash VARCHAR(64) NOT NULL UNIQUE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    source VARCHAR(20) NOT NULL DEFAULT 'roster',
+    registration TEXT,
+    sync_status VARCHAR(20) NOT NULL DEFAULT 'synced',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_voters_sync ON voters(sync_status, next_attempt_at);
+
+-- Every vote cast on the terminal, keyed by the voter's verification hash.
+-- payload is the request forwarded to the server and is cleared once the
+-- server has answered for good.
+CREATE TABLE vote_journal (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    verification_hash VARCHAR(64) NOT NULL UNIQUE,
+    polling_unit_id VARCHAR(50) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    response_code INTEGER,
+    transaction_hash VARCHAR(66),
+    next_attempt_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    forwarded_at TIMESTAMP
+);
+
+CREATE INDEX idx_vote_journal_due ON vote_journal(status, next_attempt_at);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS voted_set;
+DROP INDEX IF EXISTS idx_vote_journal_seq;
+ALTER TABLE vote_journal DROP COLUMN uploaded_status;
+ALTER TABLE vote_journal DROP COLUMN signature;
+ALTER TABLE vote_journal DROP COLUMN payload_hash;
+ALTER TABLE vote_journal DROP COLUMN entry_hash;
+ALTER TABLE vote_journal DROP COLUMN prev_hash;
+ALTER TABLE vote_journal DROP COLUMN seq;
@@ -0,0 +1,18 @@
+-- Chain the vote journal so the server can tell when entries are missing,
+-- reordered or altered. seq and the hashes stay NULL on entries settled
+-- before chaining; pending ones are chained when the store opens.
+ALTER TABLE vote_journal ADD COLUMN seq INTEGER;
+ALTER TABLE vote_journal ADD COLUMN prev_hash VARCHAR(64);
+ALTER TABLE vote_journal ADD COLUMN entry_hash VARCHAR(64);
+ALTER TABLE vote_journal ADD COLUMN payload_hash VARCHAR(64);
+ALTER TABLE vote_journal ADD COLUMN signature VARCHAR(64);
+ALTER TABLE vote_journal ADD COLUMN uploaded_status VARCHAR(20);
+
+CREATE UNIQUE INDEX idx_vote_journal_seq ON vote_journal(seq);
+
+-- Verification hashes of everyone who has voted at the polling unit,
+-- replicated from the server to refuse repeat voters while offline
+CREATE TABLE voted_set (
+    verification_hash VARCHAR(64) PRIMARY KEY,
+    synced_at TIMESTAMP NOT NULL
+);
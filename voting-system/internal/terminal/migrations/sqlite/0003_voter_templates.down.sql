@@ -0,0 +1,2 @@
+ALTER TABLE voters DROP COLUMN template;
+ALTER TABLE voters DROP COLUMN template_format;
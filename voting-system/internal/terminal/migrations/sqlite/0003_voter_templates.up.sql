@@ -0,0 +1,4 @@
+-- Enrolled fingerprint templates, matched locally while the server is
+-- unreachable. Voters without one are matched by fingerprint hash.
+ALTER TABLE voters ADD COLUMN template_format TEXT NOT NULL DEFAULT '';
+ALTER TABLE voters ADD COLUMN template TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,8 @@
+-- The last remote config fetched from the server, sealed with the terminal
+-- secret, so a terminal restarted offline still has its fingerprint key and
+-- the election's verification salt
+CREATE TABLE IF NOT EXISTS remote_config (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    config_sealed TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
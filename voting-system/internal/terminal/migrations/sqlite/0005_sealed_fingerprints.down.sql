@@ -0,0 +1,2 @@
+ALTER TABLE vote_journal DROP COLUMN sealed;
+ALTER TABLE voters DROP COLUMN sealed;
@@ -0,0 +1,5 @@
+-- Fingerprint templates, registrations awaiting upload and journaled votes
+-- hold fingerprint data, so they are sealed with the terminal secret. Rows
+-- written before are sealed when the store opens.
+ALTER TABLE voters ADD COLUMN sealed BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE vote_journal ADD COLUMN sealed BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,93 @@
+package terminal
+
+import (
+	"voting-system/internal/api/types"
+)
+
+// ReconciliationEntry compares one local journal entry with the server's record of it
+type ReconciliationEntry struct {
+	Seq              int64  `json:"seq"`
+	VerificationHash string `json:"verification_hash"`
+	LocalStatus      string `json:"local_status"`
+	ServerStatus     string `json:"server_status"` // "missing" until the server has received the entry
+	OnChain          bool   `json:"on_chain"`
+	TransactionHash  string `json:"transaction_hash,omitempty"`
+	Problem          string `json:"problem,omitempty"`
+}
+
+// Reconciliation shows which of the terminal's journaled votes landed on chain
+type Reconciliation struct {
+	DeviceID   string                `json:"device_id"`
+	ChainValid bool                  `json:"chain_valid"`
+	ChainError string                `json:"chain_error,omitempty"`
+	Summary    map[string]int        `json:"summary"`
+	Entries    []ReconciliationEntry `json:"entries"`
+}
+
+// Reconcile checks the local hash chain and matches every journal entry with
+// the server's reconciliation report
+func (t *Terminal) Reconcile() (*Reconciliation, error) {
+	entries, err := t.store.Chain()
+	if err != nil {
+		return nil, err
+	}
+	server, err := t.client.Reconciliation()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Reconciliation{
+		DeviceID:   t.cfg.DeviceID,
+		ChainValid: true,
+		Summary: map[string]int{
+			"journaled": len(entries), "received": 0, "missing": 0,
+			"on_chain": 0, "awaiting_chain": 0, "refused": 0, "mismatched": 0,
+		},
+		Entries: make([]ReconciliationEntry, 0, len(entries)),
+	}
+	if _, err := t.store.VerifyChain(); err != nil {
+		report.ChainValid = false
+		report.ChainError = err.Error()
+	}
+
+	received := make(map[int64]types.ReconciliationEntry, len(server.Entries))
+	for _, e := range server.Entries {
+		received[e.Seq] = e
+	}
+
+	for _, e := range entries {
+		entry := ReconciliationEntry{
+			Seq:              e.Seq,
+			VerificationHash: e.VerificationHash,
+			LocalStatus:      e.Status,
+			ServerStatus:     "missing",
+		}
+
+		remote, ok := received[e.Seq]
+		switch {
+		case !ok:
+			report.Summary["missing"]++
+		case remote.EntryHash != e.EntryHash:
+			entry.ServerStatus = remote.Status
+			entry.Problem = "server holds a different entry at this sequence number"
+			report.Summary["received"]++
+			report.Summary["mismatched"]++
+		default:
+			entry.ServerStatus = remote.Status
+			entry.OnChain = remote.OnChain
+			entry.TransactionHash = remote.TransactionHash
+			report.Summary["received"]++
+			switch {
+			case remote.OnChain:
+				report.Summary["on_chain"]++
+			case remote.Status == types.JournalAccepted || remote.Status == types.JournalQueued:
+				report.Summary["awaiting_chain"]++
+			default:
+				report.Summary["refused"]++
+			}
+		}
+		report.Entries = append(report.Entries, entry)
+	}
+
+	return report, nil
+}
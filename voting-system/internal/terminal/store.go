@@ -0,0 +1,780 @@
+package terminal
+
+import (
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"voting-system/internal/api/types"
+	"voting-system/internal/auth"
+	"voting-system/internal/database"
+	"voting-system/internal/journal"
+)
+
+// migrationFiles holds the terminal's own schema, separate from the server's
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// Voter sources
+const (
+	SourceRoster = "roster" // downloaded from the server
+	SourceLocal  = "local"  // registered on this terminal
+)
+
+// Upload states of locally registered voters
+const (
+	SyncPending  = "pending"
+	SyncSynced   = "synced"
+	SyncRejected = "rejected"
+)
+
+// Vote journal states
+const (
+	VotePending   = "pending"   // waiting to be forwarded
+	VoteForwarded = "forwarded" // accepted by the server
+	VoteRejected  = "rejected"  // refused by the server for good
+)
+
+var (
+	// ErrVoterExists is returned when a NIN or fingerprint is already in the store
+	ErrVoterExists = errors.New("voter already registered")
+	// ErrAlreadyVoted is returned when a verification hash is in the journal or the voted set
+	ErrAlreadyVoted = errors.New("voter has already voted on this terminal")
+)
+
+// Voter is a voter in the terminal's local store
+type Voter struct {
+	NIN             string     `json:"nin"`
+	FirstName       string     `json:"first_name"`
+	LastName        string     `json:"last_name"`
+	PollingUnitID   string     `json:"polling_unit_id"`
+	FingerprintHash string     `json:"-"`
+	TemplateFormat  string     `json:"-"` // empty for voters matched by fingerprint hash
+	Template        string     `json:"-"`
+	IsActive        bool       `json:"is_active"`
+	Source          string     `json:"source"`
+	Registration    *string    `json:"-"` // registration request awaiting upload
+	SyncStatus      string     `json:"sync_status"`
+	Attempts        int        `json:"attempts"`
+	LastError       *string    `json:"last_error,omitempty"`
+	NextAttemptAt   *time.Time `json:"next_attempt_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// JournalEntry is a vote cast on the terminal
+type JournalEntry struct {
+	ID               int64      `json:"id"`
+	Seq              int64      `json:"seq"` // position in the hash chain; 0 for entries settled before chaining
+	PrevHash         string     `json:"prev_hash"`
+	EntryHash        string     `json:"entry_hash"`
+	PayloadHash      string     `json:"payload_hash"`
+	Signature        string     `json:"signature,omitempty"`
+	VerificationHash string     `json:"verification_hash"`
+	PollingUnitID    string     `json:"polling_unit_id"`
+	Payload          string     `json:"-"` // vote request forwarded to the server
+	Status           string     `json:"status"`
+	ServerStatus     string     `json:"server_status,omitempty"` // the server's outcome for the entry
+	Attempts         int        `json:"attempts"`
+	LastError        *string    `json:"last_error,omitempty"`
+	ResponseCode     *int       `json:"response_code,omitempty"`
+	TransactionHash  *string    `json:"transaction_hash,omitempty"`
+	NextAttemptAt    time.Time  `json:"next_attempt_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	ForwardedAt      *time.Time `json:"forwarded_at,omitempty"`
+}
+
+// Store is the terminal's local SQLite database. Journal entries are chained
+// under the device ID and signed with the terminal secret. Fingerprint data,
+// in templates, registrations awaiting upload and journaled votes, is sealed
+// with the terminal secret.
+type Store struct {
+	db       *database.DB
+	deviceID string
+	secret   string
+	previous []string // secrets rotated out; entries signed with them still verify
+}
+
+// NewStore migrates the terminal schema and wraps the database. Entries are
+// signed with secret and verified with it or any previous secret.
+func NewStore(db *sql.DB, deviceID, secret string, previous ...string) (*Store, error) {
+	migrator, err := database.NewMigratorFS(db, database.DialectSQLite, migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := migrator.Up(); err != nil {
+		return nil, fmt.Errorf("migration failed: %v", err)
+	}
+
+	store := &Store{db: database.Wrap(db), deviceID: deviceID, secret: secret, previous: previous}
+	if err := store.sealPlaintext(); err != nil {
+		return nil, fmt.Errorf("failed to seal fingerprint data: %v", err)
+	}
+	if err := store.chainPending(); err != nil {
+		return nil, fmt.Errorf("failed to chain journal: %v", err)
+	}
+	return store, nil
+}
+
+const voterColumns = `nin, first_name, last_name, polling_unit_id, fingerprint_hash, template_format, template,
+        is_active, source, registration, sync_status, attempts, last_error, next_attempt_at, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (s *Store) scanVoter(row rowScanner) (*Voter, error) {
+	var v Voter
+	err := row.Scan(&v.NIN, &v.FirstName, &v.LastName, &v.PollingUnitID, &v.FingerprintHash, &v.TemplateFormat, &v.Template, &v.IsActive,
+		&v.Source, &v.Registration, &v.SyncStatus, &v.Attempts, &v.LastError, &v.NextAttemptAt,
+		&v.CreatedAt, &v.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if v.Template, err = s.open(v.Template); err != nil {
+		return nil, fmt.Errorf("voter %s: %v", v.NIN, err)
+	}
+	if v.Registration != nil {
+		registration, err := s.open(*v.Registration)
+		if err != nil {
+			return nil, fmt.Errorf("voter %s: %v", v.NIN, err)
+		}
+		v.Registration = &registration
+	}
+	return &v, nil
+}
+
+// seal encrypts fingerprint data with the terminal secret. Empty values,
+// such as a settled vote's dropped payload, stay empty.
+func (s *Store) seal(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	return auth.Seal(s.secret, plaintext)
+}
+
+// open decrypts a value sealed with the terminal secret or a previous one
+func (s *Store) open(sealed string) (string, error) {
+	if sealed == "" {
+		return "", nil
+	}
+	for _, secret := range s.secrets() {
+		if plaintext, err := auth.Open(secret, sealed); err == nil {
+			return plaintext, nil
+		}
+	}
+	return "", errors.New("sealed fingerprint data does not open with the terminal secret")
+}
+
+// sealPlaintext seals fingerprint data written before it was sealed, then
+// vacuums the database so the plaintext does not linger in free pages
+func (s *Store) sealPlaintext() error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	type voterRow struct {
+		nin          string
+		template     string
+		registration *string
+	}
+	rows, err := tx.Query("SELECT nin, template, registration FROM voters WHERE sealed = FALSE")
+	if err != nil {
+		return err
+	}
+	var voters []voterRow
+	for rows.Next() {
+		var v voterRow
+		if err := rows.Scan(&v.nin, &v.template, &v.registration); err != nil {
+			rows.Close()
+			return err
+		}
+		voters = append(voters, v)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	type journalRow struct {
+		id      int64
+		payload string
+	}
+	rows, err = tx.Query("SELECT id, payload FROM vote_journal WHERE sealed = FALSE")
+	if err != nil {
+		return err
+	}
+	var entries []journalRow
+	for rows.Next() {
+		var e journalRow
+		if err := rows.Scan(&e.id, &e.payload); err != nil {
+			rows.Close()
+			return err
+		}
+		entries = append(entries, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(voters) == 0 && len(entries) == 0 {
+		return nil
+	}
+
+	for _, v := range voters {
+		template, err := s.seal(v.template)
+		if err != nil {
+			return err
+		}
+		var registration *string
+		if v.registration != nil {
+			sealed, err := s.seal(*v.registration)
+			if err != nil {
+				return err
+			}
+			registration = &sealed
+		}
+		if _, err := tx.Exec("UPDATE voters SET template = ?, registration = ?, sealed = TRUE WHERE nin = ?",
+			template, registration, v.nin); err != nil {
+			return err
+		}
+	}
+	for _, e := range entries {
+		payload, err := s.seal(e.payload)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE vote_journal SET payload = ?, sealed = TRUE WHERE id = ?", payload, e.id); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec("VACUUM")
+	return err
+}
+
+// ReplaceRoster stores the server's voter list for the polling unit. Voters
+// missing from it are deactivated; local registrations still awaiting upload
+// are left alone. It returns how many voters were stored and skipped.
+func (s *Store) ReplaceRoster(voters []types.RosterVoter) (int, int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	if _, err := tx.Exec("UPDATE voters SET is_active = FALSE, updated_at = ? WHERE sync_status <> ?",
+		now, SyncPending); err != nil {
+		return 0, 0, err
+	}
+
+	stored, skipped := 0, 0
+	for _, v := range voters {
+		templateFormat, template := "", ""
+		if v.Template != nil {
+			templateFormat = v.Template.Format
+			if template, err = s.seal(v.Template.Data); err != nil {
+				return 0, 0, err
+			}
+		}
+		result, err := tx.Exec(`
+            INSERT INTO voters (nin, first_name, last_name, polling_unit_id, fingerprint_hash, template_format,
+                                template, is_active, source, sync_status, sealed, created_at, updated_at)
+            VALUES (?, ?, ?, ?, ?, ?, ?, TRUE, ?, ?, TRUE, ?, ?)
+            ON CONFLICT(nin) DO UPDATE SET
+                first_name = excluded.first_name,
+                last_name = excluded.last_name,
+                polling_unit_id = excluded.polling_unit_id,
+                fingerprint_hash = excluded.fingerprint_hash,
+                template_format = excluded.template_format,
+                template = excluded.template,
+                is_active = TRUE,
+                source = excluded.source,
+                registration = NULL,
+                sync_status = excluded.sync_status,
+                sealed = TRUE,
+                last_error = NULL,
+                updated_at = excluded.updated_at
+            WHERE voters.sync_status <> 'pending'
+        `, v.NIN, v.FirstName, v.LastName, v.PollingUnitID, v.FingerprintHash, templateFormat, template,
+			SourceRoster, SyncSynced, now, now)
+		if err != nil {
+			// A fingerprint held by another local voter; the roster wins on the next
+			// refresh once that registration has been settled
+			skipped++
+			continue
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			// A pending local registration of the same NIN
+			skipped++
+			continue
+		}
+		stored++
+	}
+
+	return stored, skipped, tx.Commit()
+}
+
+// RegisterVoter stores a voter registered on this terminal, with the request to upload
+func (s *Store) RegisterVoter(voter *Voter, registration string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	err = tx.QueryRow("SELECT COUNT(*) FROM voters WHERE nin = ? OR fingerprint_hash = ?",
+		voter.NIN, voter.FingerprintHash).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists > 0 {
+		return ErrVoterExists
+	}
+
+	template, err := s.seal(voter.Template)
+	if err != nil {
+		return err
+	}
+	sealed, err := s.seal(registration)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	voter.IsActive = true
+	voter.Source = SourceLocal
+	voter.Registration = &registration
+	voter.SyncStatus = SyncPending
+	voter.NextAttemptAt = &now
+	voter.CreatedAt = now
+	voter.UpdatedAt = now
+	_, err = tx.Exec(`
+        INSERT INTO voters (nin, first_name, last_name, polling_unit_id, fingerprint_hash, template_format,
+                            template, is_active, source, registration, sync_status, next_attempt_at, sealed,
+                            created_at, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, TRUE, ?, ?)
+    `, voter.NIN, voter.FirstName, voter.LastName, voter.PollingUnitID, voter.FingerprintHash,
+		voter.TemplateFormat, template, voter.IsActive,
+		voter.Source, sealed, voter.SyncStatus, now, now, now)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetVoter returns a voter by NIN, or sql.ErrNoRows
+func (s *Store) GetVoter(nin string) (*Voter, error) {
+	return s.scanVoter(s.db.QueryRow("SELECT "+voterColumns+" FROM voters WHERE nin = ?", nin))
+}
+
+// CountVoters returns the number of active voters in the store
+func (s *Store) CountVoters() (int, error) {
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM voters WHERE is_active = TRUE").Scan(&count)
+	return count, err
+}
+
+// DueRegistrations returns local registrations whose upload is due
+func (s *Store) DueRegistrations(now time.Time, limit int) ([]Voter, error) {
+	rows, err := s.db.Query("SELECT "+voterColumns+` FROM voters
+        WHERE sync_status = ? AND next_attempt_at <= ?
+        ORDER BY created_at ASC LIMIT ?`, SyncPending, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var voters []Voter
+	for rows.Next() {
+		v, err := s.scanVoter(rows)
+		if err != nil {
+			return nil, err
+		}
+		voters = append(voters, *v)
+	}
+	return voters, rows.Err()
+}
+
+// MarkRegistrationSynced records that the server accepted a registration
+func (s *Store) MarkRegistrationSynced(nin string) error {
+	_, err := s.db.Exec(`UPDATE voters SET sync_status = ?, registration = NULL, last_error = NULL,
+        attempts = attempts + 1, updated_at = ? WHERE nin = ?`, SyncSynced, time.Now().UTC(), nin)
+	return err
+}
+
+// MarkRegistrationRetry schedules another upload of a registration
+func (s *Store) MarkRegistrationRetry(nin, lastError string, next time.Time) error {
+	_, err := s.db.Exec(`UPDATE voters SET attempts = attempts + 1, last_error = ?, next_attempt_at = ?,
+        updated_at = ? WHERE nin = ?`, lastError, next, time.Now().UTC(), nin)
+	return err
+}
+
+// MarkRegistrationRejected records that the server refused a registration.
+// The voter cannot vote on this terminal until the roster says otherwise.
+func (s *Store) MarkRegistrationRejected(nin, lastError string) error {
+	_, err := s.db.Exec(`UPDATE voters SET sync_status = ?, is_active = FALSE, registration = NULL,
+        attempts = attempts + 1, last_error = ?, updated_at = ? WHERE nin = ?`,
+		SyncRejected, lastError, time.Now().UTC(), nin)
+	return err
+}
+
+const journalColumns = `id, COALESCE(seq, 0), COALESCE(prev_hash, ''), COALESCE(entry_hash, ''),
+        COALESCE(payload_hash, ''), COALESCE(signature, ''), verification_hash, polling_unit_id, payload, status,
+        COALESCE(uploaded_status, ''), attempts, last_error, response_code, transaction_hash, next_attempt_at,
+        created_at, forwarded_at`
+
+func (s *Store) scanJournalEntry(row rowScanner) (*JournalEntry, error) {
+	var e JournalEntry
+	err := row.Scan(&e.ID, &e.Seq, &e.PrevHash, &e.EntryHash, &e.PayloadHash, &e.Signature, &e.VerificationHash,
+		&e.PollingUnitID, &e.Payload, &e.Status, &e.ServerStatus, &e.Attempts, &e.LastError, &e.ResponseCode,
+		&e.TransactionHash, &e.NextAttemptAt, &e.CreatedAt, &e.ForwardedAt)
+	if err != nil {
+		return nil, err
+	}
+	if e.Payload, err = s.open(e.Payload); err != nil {
+		return nil, fmt.Errorf("journal entry %d: %v", e.ID, err)
+	}
+	return &e, nil
+}
+
+func (s *Store) queryJournal(query string, args ...interface{}) ([]JournalEntry, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []JournalEntry
+	for rows.Next() {
+		e, err := s.scanJournalEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *e)
+	}
+	return entries, rows.Err()
+}
+
+// link returns the chain link of a journal entry
+func (s *Store) link(e *JournalEntry) *journal.Entry {
+	return &journal.Entry{
+		DeviceID:         s.deviceID,
+		Seq:              e.Seq,
+		PrevHash:         e.PrevHash,
+		VerificationHash: e.VerificationHash,
+		PayloadHash:      e.PayloadHash,
+		CreatedAt:        journal.Timestamp(e.CreatedAt),
+	}
+}
+
+// chain appends an entry to the hash chain inside tx, setting its seq and hashes
+func (s *Store) chain(tx *database.Tx, e *JournalEntry) error {
+	e.Seq, e.PrevHash = 1, journal.GenesisHash
+	err := tx.QueryRow("SELECT seq, entry_hash FROM vote_journal WHERE seq IS NOT NULL ORDER BY seq DESC LIMIT 1").
+		Scan(&e.Seq, &e.PrevHash)
+	if err == nil {
+		e.Seq++
+	} else if err != sql.ErrNoRows {
+		return err
+	}
+
+	e.PayloadHash = journal.PayloadHash([]byte(e.Payload))
+	e.EntryHash = s.link(e).Hash()
+	e.Signature = journal.Sign(s.secret, e.EntryHash)
+	return nil
+}
+
+// chainPending chains pending entries journaled before the journal was
+// chained, in the order they were cast
+func (s *Store) chainPending() error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT id, verification_hash, payload, created_at FROM vote_journal WHERE seq IS NULL AND status = ? ORDER BY id ASC",
+		VotePending)
+	if err != nil {
+		return err
+	}
+	var entries []JournalEntry
+	for rows.Next() {
+		var e JournalEntry
+		if err := rows.Scan(&e.ID, &e.VerificationHash, &e.Payload, &e.CreatedAt); err != nil {
+			rows.Close()
+			return err
+		}
+		if e.Payload, err = s.open(e.Payload); err != nil {
+			rows.Close()
+			return err
+		}
+		entries = append(entries, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range entries {
+		e := &entries[i]
+		e.CreatedAt = journal.Timestamp(e.CreatedAt)
+		if err := s.chain(tx, e); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`UPDATE vote_journal SET seq = ?, prev_hash = ?, entry_hash = ?, payload_hash = ?,
+            signature = ?, created_at = ? WHERE id = ?`,
+			e.Seq, e.PrevHash, e.EntryHash, e.PayloadHash, e.Signature, e.CreatedAt, e.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// AppendVote chains and journals a vote before it is forwarded. It returns
+// ErrAlreadyVoted if the verification hash is already in the journal or in
+// the polling unit's voted set.
+func (s *Store) AppendVote(entry *JournalEntry) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var voted int
+	err = tx.QueryRow(`SELECT (SELECT COUNT(*) FROM vote_journal WHERE verification_hash = ?) +
+        (SELECT COUNT(*) FROM voted_set WHERE verification_hash = ?)`,
+		entry.VerificationHash, entry.VerificationHash).Scan(&voted)
+	if err != nil {
+		return err
+	}
+	if voted > 0 {
+		return ErrAlreadyVoted
+	}
+
+	now := journal.Timestamp(time.Now())
+	entry.Status = VotePending
+	entry.NextAttemptAt = now
+	entry.CreatedAt = now
+	if err := s.chain(tx, entry); err != nil {
+		return err
+	}
+	payload, err := s.seal(entry.Payload)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`
+        INSERT INTO vote_journal (seq, prev_hash, entry_hash, payload_hash, signature, verification_hash,
+                                  polling_unit_id, payload, status, sealed, next_attempt_at, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, TRUE, ?, ?)
+    `, entry.Seq, entry.PrevHash, entry.EntryHash, entry.PayloadHash, entry.Signature, entry.VerificationHash,
+		entry.PollingUnitID, payload, entry.Status, now, now)
+	if err != nil {
+		return err
+	}
+	if entry.ID, err = result.LastInsertId(); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// HasVoted reports whether a verification hash is in the journal or the voted set
+func (s *Store) HasVoted(verificationHash string) (bool, error) {
+	var voted int
+	err := s.db.QueryRow(`SELECT (SELECT COUNT(*) FROM vote_journal WHERE verification_hash = ?) +
+        (SELECT COUNT(*) FROM voted_set WHERE verification_hash = ?)`,
+		verificationHash, verificationHash).Scan(&voted)
+	return voted > 0, err
+}
+
+// ReplaceVotedSet stores the server's list of everyone who has voted at the polling unit
+func (s *Store) ReplaceVotedSet(hashes []string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM voted_set"); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for _, hash := range hashes {
+		if _, err := tx.Exec("INSERT INTO voted_set (verification_hash, synced_at) VALUES (?, ?) ON CONFLICT DO NOTHING",
+			hash, now); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// CountVotedSet returns the size of the replicated voted set
+func (s *Store) CountVotedSet() (int, error) {
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM voted_set").Scan(&count)
+	return count, err
+}
+
+// SaveRemoteConfig keeps the remote config, sealed with the terminal secret
+func (s *Store) SaveRemoteConfig(remote *types.TerminalRemoteConfig) error {
+	encoded, err := json.Marshal(remote)
+	if err != nil {
+		return err
+	}
+	sealed, err := auth.Seal(s.secret, string(encoded))
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT INTO remote_config (id, config_sealed, updated_at) VALUES (1, ?, ?)
+        ON CONFLICT(id) DO UPDATE SET config_sealed = excluded.config_sealed, updated_at = excluded.updated_at`,
+		sealed, time.Now().UTC())
+	return err
+}
+
+// LoadRemoteConfig returns the remote config last saved, or sql.ErrNoRows
+func (s *Store) LoadRemoteConfig() (*types.TerminalRemoteConfig, error) {
+	var sealed string
+	if err := s.db.QueryRow("SELECT config_sealed FROM remote_config WHERE id = 1").Scan(&sealed); err != nil {
+		return nil, err
+	}
+	encoded, err := auth.Open(s.secret, sealed)
+	if err != nil {
+		return nil, err
+	}
+	var remote types.TerminalRemoteConfig
+	if err := json.Unmarshal([]byte(encoded), &remote); err != nil {
+		return nil, err
+	}
+	return &remote, nil
+}
+
+// GetVote returns the journal entry for a verification hash, or sql.ErrNoRows
+func (s *Store) GetVote(verificationHash string) (*JournalEntry, error) {
+	return s.scanJournalEntry(s.db.QueryRow("SELECT "+journalColumns+" FROM vote_journal WHERE verification_hash = ?",
+		verificationHash))
+}
+
+// PendingChain returns the oldest chained votes still waiting for the server,
+// in chain order. They must be uploaded in that order.
+func (s *Store) PendingChain(limit int) ([]JournalEntry, error) {
+	return s.queryJournal("SELECT "+journalColumns+` FROM vote_journal
+        WHERE status = ? AND seq IS NOT NULL
+        ORDER BY seq ASC LIMIT ?`, VotePending, limit)
+}
+
+// Chain returns every chained journal entry in chain order
+func (s *Store) Chain() ([]JournalEntry, error) {
+	return s.queryJournal("SELECT " + journalColumns + " FROM vote_journal WHERE seq IS NOT NULL ORDER BY seq ASC")
+}
+
+// VerifyChain recomputes the journal's hash chain. It returns the number of
+// entries checked and an error naming the first broken link.
+func (s *Store) VerifyChain() (int, error) {
+	entries, err := s.Chain()
+	if err != nil {
+		return 0, err
+	}
+
+	prev := journal.GenesisHash
+	for i := range entries {
+		e := &entries[i]
+		if e.Seq != int64(i+1) || e.PrevHash != prev {
+			return i, fmt.Errorf("entry %d does not follow entry %d", e.Seq, i)
+		}
+		// The payload is dropped once settled; its hash stays
+		if e.Payload != "" && journal.PayloadHash([]byte(e.Payload)) != e.PayloadHash {
+			return i, fmt.Errorf("entry %d: payload does not match its hash", e.Seq)
+		}
+		if err := s.link(e).VerifyAny(e.EntryHash, e.Signature, s.secrets()); err != nil {
+			return i, fmt.Errorf("entry %d: %v", e.Seq, err)
+		}
+		prev = e.EntryHash
+	}
+	return len(entries), nil
+}
+
+// secrets returns the secrets journal entries may be signed with
+func (s *Store) secrets() []string {
+	var secrets []string
+	for _, secret := range append([]string{s.secret}, s.previous...) {
+		if secret != "" {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets
+}
+
+// ListJournal returns journal entries, newest first, optionally filtered by status
+func (s *Store) ListJournal(status string, limit, offset int) ([]JournalEntry, error) {
+	if status != "" {
+		return s.queryJournal("SELECT "+journalColumns+` FROM vote_journal WHERE status = ?
+            ORDER BY id DESC LIMIT ? OFFSET ?`, status, limit, offset)
+	}
+	return s.queryJournal("SELECT "+journalColumns+` FROM vote_journal
+        ORDER BY id DESC LIMIT ? OFFSET ?`, limit, offset)
+}
+
+// JournalCounts returns the number of journal entries in each state
+func (s *Store) JournalCounts() (map[string]int, error) {
+	rows, err := s.db.Query("SELECT status, COUNT(*) FROM vote_journal GROUP BY status")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int{VotePending: 0, VoteForwarded: 0, VoteRejected: 0}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+	return counts, rows.Err()
+}
+
+// MarkVoteForwarded records the server's acceptance and drops the vote payload
+func (s *Store) MarkVoteForwarded(id int64, serverStatus string, responseCode int, txHash string) error {
+	var tx *string
+	if txHash != "" {
+		tx = &txHash
+	}
+	now := time.Now().UTC()
+	_, err := s.db.Exec(`UPDATE vote_journal SET status = ?, uploaded_status = ?, payload = '', attempts = attempts + 1,
+        last_error = NULL, response_code = ?, transaction_hash = ?, forwarded_at = ? WHERE id = ?`,
+		VoteForwarded, serverStatus, responseCode, tx, now, id)
+	return err
+}
+
+// MarkVoteRetry schedules another forwarding attempt
+func (s *Store) MarkVoteRetry(id int64, lastError string, next time.Time) error {
+	_, err := s.db.Exec(`UPDATE vote_journal SET attempts = attempts + 1, last_error = ?, next_attempt_at = ?
+        WHERE id = ?`, lastError, next, id)
+	return err
+}
+
+// MarkVoteRejected records that the server refused a vote and drops its payload
+func (s *Store) MarkVoteRejected(id int64, serverStatus string, responseCode int, lastError string) error {
+	_, err := s.db.Exec(`UPDATE vote_journal SET status = ?, uploaded_status = ?, payload = '', attempts = attempts + 1,
+        last_error = ?, response_code = ? WHERE id = ?`, VoteRejected, serverStatus, lastError, responseCode, id)
+	return err
+}
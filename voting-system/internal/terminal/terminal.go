@@ -0,0 +1,360 @@
+package terminal
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"voting-system/internal/api/types"
+	"voting-system/internal/biometric"
+	"voting-system/internal/votesig"
+	"voting-system/pkg/config"
+	"voting-system/pkg/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Terminal is the voting terminal daemon. It keeps a local copy of its
+// polling unit's voters, journals every vote before forwarding it, and serves
+// the ESP32 sketch's registration and voting flow as a local REST API.
+type Terminal struct {
+	cfg       *config.TerminalConfig
+	signer    *votesig.Signer // signs votes; its address is authorized on chain
+	store     *Store
+	client    *Client
+	forwarder *Forwarder
+	logger    *logger.Logger
+
+	isRunning bool
+	stopChan  chan struct{}
+	mutex     sync.Mutex
+
+	electionMutex sync.RWMutex
+	election      json.RawMessage // last current election seen on the server
+
+	remoteMutex   sync.RWMutex
+	remote        *types.TerminalRemoteConfig // last remote config fetched from the server
+	lastHeartbeat *types.HeartbeatResponse
+
+	attemptsMutex sync.Mutex
+	attempts      map[string]*biometricAttempt // failed fingerprint matches by NIN
+}
+
+var (
+	// ErrNotConfigured is returned for fingerprint matching before the
+	// terminal has fetched its remote config
+	ErrNotConfigured = errors.New("terminal has not fetched its remote config from the server")
+	// ErrNoElection is returned for vote hashing when the remote config has
+	// no active election
+	ErrNoElection = errors.New("no active election in the terminal's remote config")
+)
+
+// biometricAttempt counts a voter's failed fingerprint matches on the terminal
+type biometricAttempt struct {
+	failed      int
+	lockedUntil time.Time
+}
+
+// New creates a terminal daemon that signs votes with signer
+func New(cfg *config.TerminalConfig, signer *votesig.Signer, store *Store, client *Client, forwarder *Forwarder, logger *logger.Logger) *Terminal {
+	return &Terminal{
+		cfg:       cfg,
+		signer:    signer,
+		store:     store,
+		client:    client,
+		forwarder: forwarder,
+		logger:    logger,
+		attempts:  make(map[string]*biometricAttempt),
+	}
+}
+
+// Start authenticates to the server, loads the roster and starts the
+// background forwarder. The server being unreachable is not fatal.
+func (t *Terminal) Start() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.isRunning {
+		return fmt.Errorf("terminal is already running")
+	}
+
+	// Until the server answers, use the config saved at the last refresh
+	if remote, err := t.store.LoadRemoteConfig(); err == nil {
+		t.remoteMutex.Lock()
+		t.remote = remote
+		t.remoteMutex.Unlock()
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		t.logger.Warning("Failed to load saved remote config: %v", err)
+	}
+
+	if err := t.client.Authenticate(); err != nil {
+		t.logger.Warning("Failed to authenticate to the central server: %v", err)
+		if t.cfg.EnrolmentCode != "" {
+			if status, err := t.Enrol(); err != nil {
+				t.logger.Warning("Failed to enrol terminal: %v", err)
+			} else {
+				t.logger.Info("Terminal enrolment is %s", status)
+			}
+		}
+	} else {
+		if err := t.RefreshRoster(); err != nil {
+			t.logger.Warning("Failed to load voter roster: %v", err)
+		}
+		if err := t.RefreshVotedSet(); err != nil {
+			t.logger.Warning("Failed to load voted set: %v", err)
+		}
+		if err := t.RefreshConfig(); err != nil {
+			t.logger.Warning("Failed to load remote config: %v", err)
+		}
+	}
+
+	if err := t.forwarder.Start(); err != nil {
+		return err
+	}
+
+	t.isRunning = true
+	t.stopChan = make(chan struct{})
+	go t.refreshLoop()
+	go t.heartbeatLoop()
+
+	return nil
+}
+
+// Stop stops the background work
+func (t *Terminal) Stop() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !t.isRunning {
+		return
+	}
+	close(t.stopChan)
+	t.forwarder.Stop()
+	t.isRunning = false
+}
+
+func (t *Terminal) refreshLoop() {
+	roster := time.NewTicker(t.cfg.RosterInterval)
+	defer roster.Stop()
+	voted := time.NewTicker(t.cfg.VotedInterval)
+	defer voted.Stop()
+
+	for {
+		select {
+		case <-roster.C:
+			if err := t.RefreshRoster(); err != nil {
+				t.logger.Warning("Failed to refresh voter roster: %v", err)
+			}
+		case <-voted.C:
+			if err := t.RefreshVotedSet(); err != nil {
+				t.logger.Warning("Failed to refresh voted set: %v", err)
+			}
+		case <-t.stopChan:
+			return
+		}
+	}
+}
+
+// RefreshRoster downloads the polling unit's voters into the local store
+func (t *Terminal) RefreshRoster() error {
+	voters, err := t.client.Roster(t.cfg.PollingUnitID)
+	if err != nil {
+		return err
+	}
+
+	stored, skipped, err := t.store.ReplaceRoster(voters)
+	if err != nil {
+		return fmt.Errorf("failed to store roster: %v", err)
+	}
+
+	t.logger.Info("Voter roster refreshed: %d voters stored, %d pending local registrations kept", stored, skipped)
+	return nil
+}
+
+// RefreshVotedSet downloads who has already voted at the polling unit, on any
+// terminal, so repeat voters are refused while the server is unreachable
+func (t *Terminal) RefreshVotedSet() error {
+	hashes, err := t.client.VotedSet(t.cfg.PollingUnitID)
+	if err != nil {
+		return err
+	}
+	if err := t.store.ReplaceVotedSet(hashes); err != nil {
+		return fmt.Errorf("failed to store voted set: %v", err)
+	}
+	return nil
+}
+
+// CurrentElection returns the server's current election, falling back to the
+// last one seen while the server is unreachable
+func (t *Terminal) CurrentElection() (json.RawMessage, bool, error) {
+	resp, err := t.client.CurrentElection()
+	if err == nil && resp.StatusCode == http.StatusOK {
+		var envelope struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(resp.Body, &envelope); err == nil && len(envelope.Data) > 0 {
+			t.electionMutex.Lock()
+			t.election = envelope.Data
+			t.electionMutex.Unlock()
+			return envelope.Data, false, nil
+		}
+	}
+
+	t.electionMutex.RLock()
+	defer t.electionMutex.RUnlock()
+
+	if t.election != nil {
+		return t.election, true, nil
+	}
+	if err == nil {
+		err = fmt.Errorf("%s", resp.Error())
+	}
+	return nil, false, err
+}
+
+// Address returns the address votes are signed with
+func (t *Terminal) Address() common.Address {
+	return t.signer.Address()
+}
+
+// Enrol asks the server to enrol the terminal with its signing key, using the
+// configured one-time enrolment code, and returns the terminal's status
+func (t *Terminal) Enrol() (string, error) {
+	enrolment := &votesig.Enrolment{
+		TerminalID:    t.cfg.DeviceID,
+		PollingUnitID: t.cfg.PollingUnitID,
+		Code:          t.cfg.EnrolmentCode,
+		Timestamp:     time.Now().Unix(),
+	}
+	signature, err := t.signer.SignEnrolment(enrolment)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := t.client.Enrol(types.TerminalEnrolmentRequest{
+		TerminalID:    t.cfg.DeviceID,
+		Name:          t.cfg.Name,
+		Location:      t.cfg.Location,
+		PollingUnitID: t.cfg.PollingUnitID,
+		Address:       t.signer.Address().Hex(),
+		PublicKey:     t.signer.PublicKey(),
+		Code:          t.cfg.EnrolmentCode,
+		Timestamp:     enrolment.Timestamp,
+		Signature:     signature,
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.Status, nil
+}
+
+// signVote stamps a vote with a fresh nonce and the current time and signs it
+func (t *Terminal) signVote(vote *types.VoteRequest, verificationHash string) error {
+	nonce, err := votesig.NewNonce()
+	if err != nil {
+		return err
+	}
+	vote.Nonce = nonce
+	vote.Timestamp = time.Now().Unix()
+
+	signed, err := votesig.FromRequest(t.cfg.DeviceID, verificationHash, vote)
+	if err != nil {
+		return err
+	}
+	vote.Signature, err = t.signer.Sign(signed)
+	return err
+}
+
+// biometrics returns the fingerprint matching service for the remote config's
+// settings. Until the config has been fetched it returns ErrNotConfigured.
+func (t *Terminal) biometrics() (*biometric.Service, error) {
+	t.remoteMutex.RLock()
+	defer t.remoteMutex.RUnlock()
+
+	if t.remote == nil || t.remote.FingerprintKey == "" {
+		return nil, ErrNotConfigured
+	}
+	key, err := hex.DecodeString(t.remote.FingerprintKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fingerprint key: %v", err)
+	}
+	return biometric.NewService(config.BiometricConfig{
+		Matcher:          t.remote.Matcher,
+		QualityThreshold: t.remote.QualityThreshold,
+		MatchThreshold:   t.remote.MatchThreshold,
+		MaxAttempts:      t.remote.MaxAttempts,
+	}, key)
+}
+
+// lockoutDuration is how long the remote config locks a voter out after too
+// many failed matches
+func (t *Terminal) lockoutDuration() time.Duration {
+	t.remoteMutex.RLock()
+	defer t.remoteMutex.RUnlock()
+
+	if t.remote == nil {
+		return 0
+	}
+	return time.Duration(t.remote.LockoutDuration) * time.Second
+}
+
+// lockedUntil returns when a voter's biometric lockout ends, if they are locked out
+func (t *Terminal) lockedUntil(nin string) (time.Time, bool) {
+	t.attemptsMutex.Lock()
+	defer t.attemptsMutex.Unlock()
+
+	attempt := t.attempts[nin]
+	if attempt == nil || !attempt.lockedUntil.After(time.Now()) {
+		return time.Time{}, false
+	}
+	return attempt.lockedUntil, true
+}
+
+// recordMatchFailure counts a failed match and returns when the voter's
+// lockout ends once maxAttempts is reached
+func (t *Terminal) recordMatchFailure(nin string, maxAttempts int) (time.Time, bool) {
+	t.attemptsMutex.Lock()
+	defer t.attemptsMutex.Unlock()
+
+	attempt := t.attempts[nin]
+	now := time.Now()
+	// A lock that has run out starts a fresh count
+	if attempt == nil || (!attempt.lockedUntil.IsZero() && !attempt.lockedUntil.After(now)) {
+		attempt = &biometricAttempt{}
+		t.attempts[nin] = attempt
+	}
+	attempt.failed++
+	if maxAttempts > 0 && attempt.failed >= maxAttempts {
+		attempt.lockedUntil = now.Add(t.lockoutDuration())
+		return attempt.lockedUntil, true
+	}
+	return time.Time{}, false
+}
+
+// resetMatchFailures clears a voter's failed matches after a match
+func (t *Terminal) resetMatchFailures(nin string) {
+	t.attemptsMutex.Lock()
+	defer t.attemptsMutex.Unlock()
+	delete(t.attempts, nin)
+}
+
+// verificationHash derives the voter's verification hash the way the server
+// does, from the NIN and the active election's salt. It returns the election
+// too, so votes carry the election their hash was derived for.
+func (t *Terminal) verificationHash(nin string) (string, string, error) {
+	t.remoteMutex.RLock()
+	defer t.remoteMutex.RUnlock()
+
+	if t.remote == nil {
+		return "", "", ErrNotConfigured
+	}
+	if t.remote.VerificationSalt == "" || t.remote.ElectionID == "" {
+		return "", "", ErrNoElection
+	}
+	return t.remote.ElectionID, votesig.VerificationHash(t.remote.VerificationSalt, nin), nil
+}
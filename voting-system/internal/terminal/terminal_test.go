@@ -0,0 +1,637 @@
+package terminal
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"voting-system/internal/api/types"
+	"voting-system/internal/biometric"
+	"voting-system/internal/database"
+	"voting-system/internal/journal"
+	"voting-system/internal/votesig"
+	"voting-system/pkg/config"
+	"voting-system/pkg/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The fingerprint key and election salt the fake server's config carries
+var (
+	testFingerprintKey = []byte("test-fingerprint-key")
+	testSalt           = "test-election-salt"
+)
+
+func testVerificationHash(nin string) string {
+	return votesig.VerificationHash(testSalt, nin)
+}
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	db, err := database.NewConnection(&config.DatabaseConfig{
+		Type: "sqlite",
+		Path: filepath.Join(t.TempDir(), "terminal.db"),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	store, err := NewStore(db, "TERM-001", "secret")
+	require.NoError(t, err)
+	return store
+}
+
+// testVoteDomain is the chain and contract the terminal signs votes for
+var testVoteDomain = votesig.NewDomain(1337, "0x345cA3e014Aaf5dcA488057592ee47305D9B3e10")
+
+// fakeServer stands in for the central server
+type fakeServer struct {
+	mutex      sync.Mutex
+	down       bool
+	castStatus int
+	votes      []types.VoteRequest
+	journal    []types.JournalUploadEntry
+	registered []types.VoterRegistrationRequest
+	roster     []types.RosterVoter
+	voted      []string
+	address    common.Address // the terminal's signing address
+	heartbeats []types.TerminalHeartbeat
+	remote     types.TerminalRemoteConfig
+	enrolments []types.TerminalEnrolmentRequest
+}
+
+func (f *fakeServer) handler(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if f.down {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.URL.Path {
+	case tokenPath:
+		json.NewEncoder(w).Encode(types.SuccessResponse{Success: true, Data: map[string]string{"token": "device-token"}})
+	case enrolPath:
+		var req types.TerminalEnrolmentRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		signer, err := votesig.RecoverEnrolment(testVoteDomain, &votesig.Enrolment{
+			TerminalID: req.TerminalID, PollingUnitID: req.PollingUnitID, Address: common.HexToAddress(req.Address),
+			Code: req.Code, Timestamp: req.Timestamp,
+		}, req.Signature)
+		if err != nil || signer != f.address {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(types.ErrorResponse{Error: "invalid_signature", Code: 401})
+			return
+		}
+		f.enrolments = append(f.enrolments, req)
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(types.SuccessResponse{Success: true, Data: types.TerminalEnrolmentResponse{
+			TerminalID: req.TerminalID, Status: "pending", Address: req.Address,
+		}})
+	case rosterPath:
+		json.NewEncoder(w).Encode(types.SuccessResponse{Success: true, Data: f.roster})
+	case votedPath:
+		json.NewEncoder(w).Encode(types.SuccessResponse{Success: true, Data: f.voted})
+	case registerPath:
+		var req types.VoterRegistrationRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		f.registered = append(f.registered, req)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(types.SuccessResponse{Success: true})
+	case journalPath:
+		if r.Header.Get("Authorization") != "Bearer device-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var upload types.JournalUpload
+		json.NewDecoder(r.Body).Decode(&upload)
+
+		var resp types.JournalUploadResponse
+		for _, entry := range upload.Entries {
+			result := types.JournalEntryResult{Seq: entry.Seq, EntryHash: entry.EntryHash}
+			prev := journal.GenesisHash
+			if len(f.journal) > 0 {
+				prev = f.journal[len(f.journal)-1].EntryHash
+			}
+			var vote types.VoteRequest
+			json.Unmarshal(entry.Vote, &vote)
+			signed, _ := votesig.FromRequest("TERM-001", entry.VerificationHash, &vote)
+			signer, err := votesig.Recover(testVoteDomain, signed, vote.Signature)
+			switch {
+			case entry.Seq != int64(len(f.journal)+1) || entry.PrevHash != prev:
+				result.Status = types.JournalOutOfOrder
+			case err != nil || signer != f.address:
+				result.Status, result.Code, result.Error = types.JournalRejected, http.StatusForbidden, "signer_mismatch"
+			case f.castStatus != 0:
+				result.Status, result.Code, result.Error = types.JournalDuplicate, f.castStatus, "already_voted"
+			default:
+				result.Status, result.Code, result.TransactionHash = types.JournalAccepted, http.StatusCreated, "0xabc"
+				f.votes = append(f.votes, vote)
+			}
+			if result.Status != types.JournalOutOfOrder {
+				f.journal = append(f.journal, entry)
+			}
+			resp.Results = append(resp.Results, result)
+		}
+		json.NewEncoder(w).Encode(types.SuccessResponse{Success: true, Data: resp})
+	case fmt.Sprintf(heartbeatPath, "TERM-001"):
+		var heartbeat types.TerminalHeartbeat
+		json.NewDecoder(r.Body).Decode(&heartbeat)
+		f.heartbeats = append(f.heartbeats, heartbeat)
+		json.NewEncoder(w).Encode(types.SuccessResponse{Success: true, Data: types.HeartbeatResponse{
+			ServerTime: time.Now().UnixMilli(), ConfigVersion: f.remote.Version, Alerts: []string{},
+		}})
+	case fmt.Sprintf(configPath, "TERM-001"):
+		json.NewEncoder(w).Encode(types.SuccessResponse{Success: true, Data: f.remote})
+	case reconciliationPath:
+		report := types.ReconciliationReport{DeviceID: "TERM-001"}
+		for _, entry := range f.journal {
+			report.Entries = append(report.Entries, types.ReconciliationEntry{
+				Seq: entry.Seq, EntryHash: entry.EntryHash, Status: types.JournalAccepted, OnChain: entry.Seq == 1,
+			})
+		}
+		json.NewEncoder(w).Encode(types.SuccessResponse{Success: true, Data: report})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeServer) set(down bool, castStatus int) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.down, f.castStatus = down, castStatus
+}
+
+type testTerminal struct {
+	*Terminal
+	server *fakeServer
+	router *gin.Engine
+}
+
+func newTestTerminal(t *testing.T) *testTerminal {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	ada := biometric.Hash(testFingerprintKey, "finger-ada")
+	fake := &fakeServer{roster: []types.RosterVoter{{
+		NIN: "12345678901", FirstName: "Ada", LastName: "Obi", PollingUnitID: "PU001",
+		FingerprintHash: ada, Template: &biometric.Template{Format: biometric.FormatExact, Data: ada, Quality: 1},
+	}}, address: crypto.PubkeyToAddress(key.PublicKey), remote: types.TerminalRemoteConfig{
+		DeviceID: "TERM-001", PollingUnitID: "PU001", ElectionID: "1", Version: "v0",
+		FingerprintKey: hex.EncodeToString(testFingerprintKey), VerificationSalt: testSalt,
+	}}
+	srv := httptest.NewServer(http.HandlerFunc(fake.handler))
+	t.Cleanup(srv.Close)
+
+	cfg := &config.TerminalConfig{DeviceID: "TERM-001", PollingUnitID: "PU001", ServerURL: srv.URL}
+	store := newTestStore(t)
+	client := NewClient(srv.URL, cfg.DeviceID, "", 5*time.Second)
+	log := logger.NewLogger("panic", "")
+	forwarder := NewForwarder(store, client, log, ForwarderOptions{RetryInterval: time.Millisecond})
+
+	term := New(cfg, votesig.NewSigner(key, testVoteDomain), store, client, forwarder, log)
+	require.NoError(t, term.RefreshRoster())
+	require.NoError(t, term.RefreshConfig())
+
+	router := gin.New()
+	term.SetupRoutes(router)
+	return &testTerminal{Terminal: term, server: fake, router: router}
+}
+
+func (tt *testTerminal) post(t *testing.T, path string, body interface{}) (int, []byte) {
+	t.Helper()
+
+	encoded, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(encoded))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	tt.router.ServeHTTP(w, req)
+	data, _ := io.ReadAll(w.Body)
+	return w.Code, data
+}
+
+func TestStoreRosterKeepsPendingRegistrations(t *testing.T) {
+	store := newTestStore(t)
+
+	local := &Voter{NIN: "222", FirstName: "B", LastName: "C", PollingUnitID: "PU001", FingerprintHash: "fp-local"}
+	require.NoError(t, store.RegisterVoter(local, `{"nin":"222"}`))
+	assert.ErrorIs(t, store.RegisterVoter(&Voter{NIN: "333", FingerprintHash: "fp-local"}, "{}"), ErrVoterExists)
+
+	stored, skipped, err := store.ReplaceRoster([]types.RosterVoter{
+		{NIN: "111", FirstName: "A", LastName: "B", PollingUnitID: "PU001", FingerprintHash: "fp-roster"},
+		{NIN: "222", FirstName: "X", LastName: "Y", PollingUnitID: "PU001", FingerprintHash: "fp-local"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored)
+	assert.Equal(t, 1, skipped)
+
+	voter, err := store.GetVoter("222")
+	require.NoError(t, err)
+	assert.Equal(t, SyncPending, voter.SyncStatus)
+	assert.Equal(t, "B", voter.FirstName)
+
+	// Dropped from the next roster: roster voters are deactivated
+	_, _, err = store.ReplaceRoster(nil)
+	require.NoError(t, err)
+	voter, err = store.GetVoter("111")
+	require.NoError(t, err)
+	assert.False(t, voter.IsActive)
+
+	due, err := store.DueRegistrations(time.Now().UTC(), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "222", due[0].NIN)
+}
+
+func TestFingerprintDataIsSealedAtRest(t *testing.T) {
+	db, err := database.NewConnection(&config.DatabaseConfig{
+		Type: "sqlite",
+		Path: filepath.Join(t.TempDir(), "terminal.db"),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	store, err := NewStore(db, "TERM-001", "secret")
+	require.NoError(t, err)
+
+	local := &Voter{NIN: "222", PollingUnitID: "PU001", FingerprintHash: "fp-local", TemplateFormat: biometric.FormatMinutiae,
+		Template: "template-local"}
+	require.NoError(t, store.RegisterVoter(local, `{"fingerprint_data":"finger-local"}`))
+	_, _, err = store.ReplaceRoster([]types.RosterVoter{{NIN: "111", PollingUnitID: "PU001", FingerprintHash: "fp-roster",
+		Template: &biometric.Template{Format: biometric.FormatMinutiae, Data: "template-roster"}}})
+	require.NoError(t, err)
+	require.NoError(t, store.AppendVote(&JournalEntry{VerificationHash: "hash-1", PollingUnitID: "PU001",
+		Payload: `{"fingerprint_data":"finger-vote"}`}))
+
+	// Neither templates, registrations nor journaled votes are stored in the clear
+	rawColumns := func() string {
+		var voters, votes string
+		require.NoError(t, db.QueryRow("SELECT GROUP_CONCAT(template || COALESCE(registration, '')) FROM voters").Scan(&voters))
+		require.NoError(t, db.QueryRow("SELECT GROUP_CONCAT(payload) FROM vote_journal").Scan(&votes))
+		return voters + votes
+	}
+	raw := rawColumns()
+	assert.NotContains(t, raw, "template-")
+	assert.NotContains(t, raw, "finger-")
+
+	voter, err := store.GetVoter("222")
+	require.NoError(t, err)
+	assert.Equal(t, "template-local", voter.Template)
+	assert.Equal(t, `{"fingerprint_data":"finger-local"}`, *voter.Registration)
+	voter, err = store.GetVoter("111")
+	require.NoError(t, err)
+	assert.Equal(t, "template-roster", voter.Template)
+	entry, err := store.GetVote("hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, `{"fingerprint_data":"finger-vote"}`, entry.Payload)
+	verified, err := store.VerifyChain()
+	require.NoError(t, err)
+	assert.Equal(t, 1, verified)
+
+	// Rows written in the clear before sealing are sealed when the store opens
+	_, err = db.Exec(`INSERT INTO voters (nin, first_name, last_name, polling_unit_id, fingerprint_hash, template_format,
+        template, is_active, source, registration, sync_status, created_at, updated_at)
+        VALUES ('333', '', '', 'PU001', 'fp-old', 'minutiae', 'template-old', TRUE, 'local', '{"fingerprint_data":"finger-old"}',
+        'pending', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO vote_journal (verification_hash, polling_unit_id, payload, status, next_attempt_at, created_at)
+        VALUES ('hash-old', 'PU001', '{"fingerprint_data":"finger-old-vote"}', 'pending', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`)
+	require.NoError(t, err)
+	store, err = NewStore(db, "TERM-001", "secret")
+	require.NoError(t, err)
+	raw = rawColumns()
+	assert.NotContains(t, raw, "template-")
+	assert.NotContains(t, raw, "finger-")
+	voter, err = store.GetVoter("333")
+	require.NoError(t, err)
+	assert.Equal(t, "template-old", voter.Template)
+	assert.Equal(t, `{"fingerprint_data":"finger-old"}`, *voter.Registration)
+	entry, err = store.GetVote("hash-old")
+	require.NoError(t, err)
+	assert.Equal(t, `{"fingerprint_data":"finger-old-vote"}`, entry.Payload)
+	assert.Equal(t, int64(2), entry.Seq, "the old pending vote is chained after it is sealed")
+	verified, err = store.VerifyChain()
+	require.NoError(t, err)
+	assert.Equal(t, 2, verified)
+}
+
+func TestCastVoteForwardsOrJournals(t *testing.T) {
+	tt := newTestTerminal(t)
+	vote := CastRequest{NIN: "12345678901", FingerprintData: "finger-ada", CandidateID: "CAND-1"}
+
+	code, _ := tt.post(t, "/api/v1/votes", CastRequest{NIN: "12345678901", FingerprintData: "wrong", CandidateID: "CAND-1"})
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	// Server down: the vote stays in the journal
+	tt.server.set(true, 0)
+	code, body := tt.post(t, "/api/v1/votes", vote)
+	require.Equal(t, http.StatusAccepted, code, string(body))
+
+	code, _ = tt.post(t, "/api/v1/votes", vote)
+	assert.Equal(t, http.StatusConflict, code)
+
+	entry, err := tt.store.GetVote(testVerificationHash(vote.NIN))
+	require.NoError(t, err)
+	assert.Equal(t, VotePending, entry.Status)
+	assert.Equal(t, 1, entry.Attempts)
+
+	// Server back: the next pass delivers it
+	tt.server.set(false, 0)
+	time.Sleep(5 * time.Millisecond)
+	delivered, failed, err := tt.forwarder.ForwardNow()
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, 0, failed)
+
+	entry, err = tt.store.GetVote(entry.VerificationHash)
+	require.NoError(t, err)
+	assert.Equal(t, VoteForwarded, entry.Status)
+	require.NotNil(t, entry.TransactionHash)
+	assert.Equal(t, "0xabc", *entry.TransactionHash)
+	assert.Empty(t, entry.Payload)
+
+	require.Len(t, tt.server.votes, 1)
+	assert.Equal(t, "PU001", tt.server.votes[0].PollingUnitID)
+	assert.Equal(t, "CAND-1", tt.server.votes[0].CandidateID)
+	assert.Equal(t, "1", tt.server.votes[0].ElectionID)
+}
+
+func TestRegisterVoterUploadsBeforeVoting(t *testing.T) {
+	tt := newTestTerminal(t)
+	tt.server.set(true, 0)
+
+	code, body := tt.post(t, "/api/v1/voters", RegisterRequest{
+		NIN: "98765432109", FirstName: "Chi", LastName: "Eze", Gender: "F",
+		DateOfBirth: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), FingerprintData: "finger-chi",
+	})
+	require.Equal(t, http.StatusAccepted, code, string(body))
+
+	code, _ = tt.post(t, "/api/v1/voters/verify", VerifyRequest{NIN: "98765432109", FingerprintData: "finger-chi"})
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = tt.post(t, "/api/v1/votes", CastRequest{NIN: "98765432109", FingerprintData: "finger-chi", CandidateID: "CAND-2"})
+	require.Equal(t, http.StatusAccepted, code)
+
+	tt.server.set(false, 0)
+	time.Sleep(5 * time.Millisecond)
+	delivered, _, err := tt.forwarder.ForwardNow()
+	require.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+
+	require.Len(t, tt.server.registered, 1)
+	assert.Equal(t, "PU001", tt.server.registered[0].PollingUnitID)
+	voter, err := tt.store.GetVoter("98765432109")
+	require.NoError(t, err)
+	assert.Equal(t, SyncSynced, voter.SyncStatus)
+}
+
+func TestRejectedVoteIsNotRetried(t *testing.T) {
+	tt := newTestTerminal(t)
+	tt.server.set(false, http.StatusConflict)
+
+	code, body := tt.post(t, "/api/v1/votes", CastRequest{NIN: "12345678901", FingerprintData: "finger-ada", CandidateID: "CAND-1"})
+	require.Equal(t, http.StatusConflict, code, string(body))
+
+	entry, err := tt.store.GetVote(testVerificationHash("12345678901"))
+	require.NoError(t, err)
+	assert.Equal(t, VoteRejected, entry.Status)
+
+	pending, err := tt.store.PendingChain(10)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestHeartbeatReportsHealthAndFollowsConfig(t *testing.T) {
+	tt := newTestTerminal(t)
+	tt.server.remote.HeartbeatInterval, tt.server.remote.Version = 30, "v1"
+
+	battery := filepath.Join(t.TempDir(), "capacity")
+	require.NoError(t, os.WriteFile(battery, []byte("64\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(battery), "status"), []byte("Discharging\n"), 0o644))
+	tt.cfg.BatteryPath = battery
+
+	// A vote journaled while offline is reported as queued
+	tt.server.set(true, 0)
+	code, body := tt.post(t, "/api/v1/votes", CastRequest{NIN: "12345678901", FingerprintData: "finger-ada", CandidateID: "CAND-1"})
+	require.Equal(t, http.StatusAccepted, code, string(body))
+	tt.server.set(false, 0)
+
+	require.NoError(t, tt.SendHeartbeat())
+	require.Len(t, tt.server.heartbeats, 1)
+	sent := tt.server.heartbeats[0]
+	assert.Equal(t, 1, sent.QueueDepth)
+	assert.Equal(t, Version, sent.FirmwareVersion)
+	require.NotNil(t, sent.BatteryLevel)
+	assert.Equal(t, 64, *sent.BatteryLevel)
+	assert.False(t, sent.Charging)
+
+	// The config is fetched because its version changed, and sets the interval
+	require.NotNil(t, tt.RemoteConfig())
+	assert.Equal(t, "v1", tt.RemoteConfig().Version)
+	assert.Equal(t, 30*time.Second, tt.heartbeatInterval())
+
+	tt.server.remote.HeartbeatInterval = 15
+	tt.server.remote.Version = "v2"
+	require.NoError(t, tt.SendHeartbeat())
+	assert.Equal(t, 15*time.Second, tt.heartbeatInterval())
+}
+
+func TestEnrolSignsRequestWithTerminalKey(t *testing.T) {
+	tt := newTestTerminal(t)
+	tt.cfg.EnrolmentCode = "ABCD-EFGH-IJKL-MNOP"
+	tt.cfg.Location = "Ward 3"
+
+	status, err := tt.Enrol()
+	require.NoError(t, err)
+	assert.Equal(t, "pending", status)
+	require.Len(t, tt.server.enrolments, 1)
+	sent := tt.server.enrolments[0]
+	assert.Equal(t, "TERM-001", sent.TerminalID)
+	assert.Equal(t, "PU001", sent.PollingUnitID)
+	assert.Equal(t, "ABCD-EFGH-IJKL-MNOP", sent.Code)
+	assert.Equal(t, "Ward 3", sent.Location)
+	assert.Equal(t, tt.Address().Hex(), sent.Address)
+
+	pub, err := hexutil.Decode(sent.PublicKey)
+	require.NoError(t, err)
+	key, err := crypto.UnmarshalPubkey(pub)
+	require.NoError(t, err)
+	assert.Equal(t, tt.Address(), crypto.PubkeyToAddress(*key))
+}
+
+func TestVotedSetRefusesRepeatVotersOffline(t *testing.T) {
+	tt := newTestTerminal(t)
+	hash := testVerificationHash("12345678901")
+	tt.server.voted = []string{hash}
+	require.NoError(t, tt.RefreshVotedSet())
+
+	// The voter cast at another terminal; this one is offline but still refuses them
+	tt.server.set(true, 0)
+	code, _ := tt.post(t, "/api/v1/votes", CastRequest{NIN: "12345678901", FingerprintData: "finger-ada", CandidateID: "CAND-1"})
+	assert.Equal(t, http.StatusConflict, code)
+
+	count, err := tt.store.CountVotedSet()
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	_, err = tt.store.GetVote(hash)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestFingerprintsMatchAgainstRosterTemplates(t *testing.T) {
+	tt := newTestTerminal(t)
+	minutiae := make([]biometric.Minutia, 20)
+	for i := range minutiae {
+		minutiae[i] = biometric.Minutia{X: float64(i*37%200 + 10), Y: float64(i*53%300 + 10), Angle: float64(i * 47 % 360),
+			Type: biometric.RidgeEnding, Quality: 0.9}
+	}
+	// capture places the finger shifted by dx, or with scrambled ridge
+	// directions for another finger
+	capture := func(dx float64, other bool) string {
+		shifted := make([]biometric.Minutia, len(minutiae))
+		for i, m := range minutiae {
+			m.X += dx
+			if other {
+				m.Angle = float64(i * 113 % 360)
+			}
+			shifted[i] = m
+		}
+		data, err := json.Marshal(biometric.MinutiaeSample{Minutiae: shifted})
+		require.NoError(t, err)
+		return string(data)
+	}
+	enrolled, err := biometric.Minutiae{}.Enrol(capture(0, false))
+	require.NoError(t, err)
+
+	tt.server.roster = append(tt.server.roster, types.RosterVoter{
+		NIN: "10987654321", FirstName: "Bola", LastName: "Ade", PollingUnitID: "PU001",
+		FingerprintHash: biometric.Hash(testFingerprintKey, capture(0, false)), Template: enrolled,
+	})
+	require.NoError(t, tt.RefreshRoster())
+	remote := *tt.RemoteConfig()
+	remote.Matcher, remote.MatchThreshold, remote.MaxAttempts, remote.LockoutDuration = biometric.FormatMinutiae, 0.85, 2, 60
+	tt.remote = &remote
+
+	// Another capture of the enrolled finger matches; the roster's exact voters still do too
+	code, body := tt.post(t, "/api/v1/voters/verify", VerifyRequest{NIN: "10987654321", FingerprintData: capture(3, false)})
+	assert.Equal(t, http.StatusOK, code, string(body))
+	code, _ = tt.post(t, "/api/v1/voters/verify", VerifyRequest{NIN: "12345678901", FingerprintData: "finger-ada"})
+	assert.Equal(t, http.StatusOK, code)
+
+	// Failed matches lock the voter out
+	code, _ = tt.post(t, "/api/v1/voters/verify", VerifyRequest{NIN: "10987654321", FingerprintData: capture(0, true)})
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = tt.post(t, "/api/v1/voters/verify", VerifyRequest{NIN: "10987654321", FingerprintData: capture(0, true)})
+	assert.Equal(t, http.StatusLocked, code)
+	code, _ = tt.post(t, "/api/v1/voters/verify", VerifyRequest{NIN: "10987654321", FingerprintData: capture(3, false)})
+	assert.Equal(t, http.StatusLocked, code)
+}
+
+func TestJournalChainAndReconciliation(t *testing.T) {
+	tt := newTestTerminal(t)
+	// Bola was registered before fingerprint hashes were keyed
+	tt.server.roster = append(tt.server.roster, types.RosterVoter{
+		NIN: "10987654321", FirstName: "Bola", LastName: "Ade", PollingUnitID: "PU001",
+		FingerprintHash: biometric.Hash(nil, "finger-bola"),
+	})
+	require.NoError(t, tt.RefreshRoster())
+
+	tt.server.set(true, 0)
+	for _, vote := range []CastRequest{
+		{NIN: "12345678901", FingerprintData: "finger-ada", CandidateID: "CAND-1"},
+		{NIN: "10987654321", FingerprintData: "finger-bola", CandidateID: "CAND-2"},
+	} {
+		code, body := tt.post(t, "/api/v1/votes", vote)
+		require.Equal(t, http.StatusAccepted, code, string(body))
+	}
+
+	verified, err := tt.store.VerifyChain()
+	require.NoError(t, err)
+	assert.Equal(t, 2, verified)
+
+	// After a secret rotation the entries open and verify with the previous secret only
+	rotated := &Store{db: tt.store.db, deviceID: "TERM-001", secret: "rotated"}
+	_, err = rotated.VerifyChain()
+	assert.ErrorContains(t, err, "does not open with the terminal secret")
+	rotated.previous = []string{"secret"}
+	verified, err = rotated.VerifyChain()
+	require.NoError(t, err)
+	assert.Equal(t, 2, verified)
+
+	// Both entries go up as one batch, in chain order
+	tt.server.set(false, 0)
+	time.Sleep(5 * time.Millisecond)
+	delivered, _, err := tt.forwarder.ForwardNow()
+	require.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+	require.Len(t, tt.server.journal, 2)
+	assert.Equal(t, journal.GenesisHash, tt.server.journal[0].PrevHash)
+	assert.Equal(t, tt.server.journal[0].EntryHash, tt.server.journal[1].PrevHash)
+	assert.NotEmpty(t, tt.server.journal[0].Signature)
+
+	report, err := tt.Reconcile()
+	require.NoError(t, err)
+	assert.True(t, report.ChainValid)
+	assert.Equal(t, 2, report.Summary["received"])
+	assert.Equal(t, 1, report.Summary["on_chain"])
+	assert.Equal(t, 1, report.Summary["awaiting_chain"])
+
+	// Editing a journaled vote breaks the chain
+	_, err = tt.store.db.Exec(`UPDATE vote_journal SET verification_hash = 'forged' WHERE seq = 2`)
+	require.NoError(t, err)
+	_, err = tt.store.VerifyChain()
+	assert.Error(t, err)
+	report, err = tt.Reconcile()
+	require.NoError(t, err)
+	assert.False(t, report.ChainValid)
+}
+
+func TestRemoteConfigSecrets(t *testing.T) {
+	tt := newTestTerminal(t)
+
+	// The saved config is sealed with the terminal secret, and a restarted
+	// terminal picks it up before the server answers
+	var sealed string
+	require.NoError(t, tt.store.db.QueryRow("SELECT config_sealed FROM remote_config").Scan(&sealed))
+	assert.NotContains(t, sealed, testSalt)
+	saved, err := tt.store.LoadRemoteConfig()
+	require.NoError(t, err)
+	assert.Equal(t, testSalt, saved.VerificationSalt)
+
+	// The local status leaves the secrets out
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/status", nil)
+	w := httptest.NewRecorder()
+	tt.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), testSalt)
+
+	// Without an election there is no salt to hash votes with
+	remote := *tt.RemoteConfig()
+	remote.ElectionID, remote.VerificationSalt = "", ""
+	tt.remote = &remote
+	code, _ := tt.post(t, "/api/v1/votes", CastRequest{NIN: "12345678901", FingerprintData: "finger-ada", CandidateID: "CAND-1"})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// and without a config the terminal cannot match fingerprints
+	tt.remote = nil
+	code, _ = tt.post(t, "/api/v1/voters/verify", VerifyRequest{NIN: "12345678901", FingerprintData: "finger-ada"})
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+}
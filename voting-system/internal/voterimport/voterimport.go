@@ -0,0 +1,249 @@
+// Package voterimport reads bulk voter registration files. Files are CSV with
+// a header row or JSON Lines, one voter per line, with the fields of a single
+// registration. Rows are validated as they are read; rows that fail are
+// reported by line and do not stop the rest of the file.
+package voterimport
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// File formats
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// Row error codes
+const (
+	CodeMalformed            = "malformed_row"
+	CodeMissingField         = "missing_field"
+	CodeInvalidNIN           = "invalid_nin"
+	CodeInvalidDateOfBirth   = "invalid_date_of_birth"
+	CodeInvalidGender        = "invalid_gender"
+	CodeDuplicateNIN         = "duplicate_nin"
+	CodeDuplicateFingerprint = "duplicate_fingerprint"
+	CodeInvalidFingerprint   = "invalid_fingerprint"
+	CodeLowQuality           = "low_quality_fingerprint"
+)
+
+// MinimumAge is the age a voter must have reached to be registered
+const MinimumAge = 18
+
+// Columns are the fields of a row, the CSV header and the JSON keys
+var Columns = []string{"nin", "first_name", "last_name", "date_of_birth", "gender", "polling_unit_id", "fingerprint_data"}
+
+// maxLine bounds a JSON Lines row; minutiae captures run to a few KB
+const maxLine = 1 << 20
+
+// Row is a voter read from an import file
+type Row struct {
+	Line            int
+	NIN             string
+	FirstName       string
+	LastName        string
+	DateOfBirth     time.Time
+	Gender          string // M or F
+	PollingUnitID   string
+	FingerprintData string
+}
+
+// RowError is a row that cannot be imported
+type RowError struct {
+	Line    int    `json:"line"`
+	NIN     string `json:"nin,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// Parse reads every row of a file. Rows that fail validation are returned as
+// errors next to the valid ones. An error is returned only if the file
+// cannot be read at all.
+func Parse(format string, r io.Reader, now time.Time) ([]Row, []RowError, error) {
+	switch format {
+	case FormatCSV:
+		return parseCSV(r, now)
+	case FormatJSONL:
+		return parseJSONL(r, now)
+	default:
+		return nil, nil, fmt.Errorf("unsupported format %q; use %s or %s", format, FormatCSV, FormatJSONL)
+	}
+}
+
+func parseCSV(r io.Reader, now time.Time) ([]Row, []RowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read header: %v", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, column := range Columns {
+		if _, ok := index[column]; !ok {
+			return nil, nil, fmt.Errorf("header has no %s column", column)
+		}
+	}
+
+	var rows []Row
+	var rowErrors []RowError
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, err
+			}
+			rowErrors = append(rowErrors, RowError{Line: parseErr.StartLine, Code: CodeMalformed, Message: parseErr.Err.Error()})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+
+		fields := make(map[string]string, len(Columns))
+		for _, column := range Columns {
+			if i := index[column]; i < len(record) {
+				fields[column] = record[i]
+			}
+		}
+		row, rowErr := validate(line, fields, now)
+		if rowErr != nil {
+			rowErrors = append(rowErrors, *rowErr)
+			continue
+		}
+		rows = append(rows, *row)
+	}
+	return rows, rowErrors, nil
+}
+
+func parseJSONL(r io.Reader, now time.Time) ([]Row, []RowError, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLine)
+
+	var rows []Row
+	var rowErrors []RowError
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var fields map[string]string
+		if err := json.Unmarshal([]byte(text), &fields); err != nil {
+			rowErrors = append(rowErrors, RowError{Line: line, Code: CodeMalformed, Message: "not a JSON object of string fields"})
+			continue
+		}
+		row, rowErr := validate(line, fields, now)
+		if rowErr != nil {
+			rowErrors = append(rowErrors, *rowErr)
+			continue
+		}
+		rows = append(rows, *row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return rows, rowErrors, nil
+}
+
+// validate checks a row's fields and returns the row they describe
+func validate(line int, fields map[string]string, now time.Time) (*Row, *RowError) {
+	for key, value := range fields {
+		fields[key] = strings.TrimSpace(value)
+	}
+	nin := fields["nin"]
+	fail := func(code, format string, args ...interface{}) (*Row, *RowError) {
+		return nil, &RowError{Line: line, NIN: nin, Code: code, Message: fmt.Sprintf(format, args...)}
+	}
+
+	for _, column := range Columns {
+		if fields[column] == "" {
+			return fail(CodeMissingField, "%s is required", column)
+		}
+	}
+	if !ValidNIN(nin) {
+		return fail(CodeInvalidNIN, "NIN must be 11 digits")
+	}
+	dob, err := ParseDateOfBirth(fields["date_of_birth"], now)
+	if err != nil {
+		return fail(CodeInvalidDateOfBirth, "%v", err)
+	}
+	gender, ok := NormalizeGender(fields["gender"])
+	if !ok {
+		return fail(CodeInvalidGender, "gender must be M or F")
+	}
+
+	return &Row{
+		Line:            line,
+		NIN:             nin,
+		FirstName:       fields["first_name"],
+		LastName:        fields["last_name"],
+		DateOfBirth:     dob,
+		Gender:          gender,
+		PollingUnitID:   fields["polling_unit_id"],
+		FingerprintData: fields["fingerprint_data"],
+	}, nil
+}
+
+// ValidNIN reports whether nin is a National Identification Number: 11 digits
+func ValidNIN(nin string) bool {
+	if len(nin) != 11 {
+		return false
+	}
+	for _, c := range nin {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseDateOfBirth reads a YYYY-MM-DD or RFC 3339 date of birth and checks
+// the voter is at least MinimumAge at now
+func ParseDateOfBirth(value string, now time.Time) (time.Time, error) {
+	dob, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		if dob, err = time.Parse(time.RFC3339, value); err != nil {
+			return time.Time{}, fmt.Errorf("date of birth %q is not a YYYY-MM-DD date", value)
+		}
+	}
+	dob = time.Date(dob.Year(), dob.Month(), dob.Day(), 0, 0, 0, 0, time.UTC)
+	if dob.Year() < 1900 {
+		return time.Time{}, fmt.Errorf("date of birth %s is before 1900", dob.Format("2006-01-02"))
+	}
+	if dob.AddDate(MinimumAge, 0, 0).After(now) {
+		return time.Time{}, fmt.Errorf("voter is under %d", MinimumAge)
+	}
+	return dob, nil
+}
+
+// NormalizeGender returns M or F for the accepted spellings of a gender
+func NormalizeGender(value string) (string, bool) {
+	switch strings.ToUpper(value) {
+	case "M", "MALE":
+		return "M", true
+	case "F", "FEMALE":
+		return "F", true
+	}
+	return "", false
+}
@@ -0,0 +1,92 @@
+package voterimport
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func codes(errs []RowError) map[int]string {
+	out := make(map[int]string, len(errs))
+	for _, e := range errs {
+		out[e.Line] = e.Code
+	}
+	return out
+}
+
+func TestParseCSV(t *testing.T) {
+	file := strings.Join([]string{
+		"NIN,first_name,last_name,date_of_birth,gender,polling_unit_id,fingerprint_data,notes",
+		"12345678901,Ada,Obi,1990-01-01,f,PU-1,slot-1,",
+		"1234567890,Bola,Ade,1990-01-01,M,PU-1,slot-2,",
+		"10987654321,Chidi,Eze,2010-01-01,M,PU-1,slot-3,",
+		"11122233344,Dayo,Ola,1990-13-01,M,PU-1,slot-4,",
+		"11122233355,Efe,Ubi,1985-05-05,X,PU-1,slot-5,",
+		"11122233366,Femi,,1985-05-05,Male,PU-1,slot-6,",
+		`11122233377,"Gbenga,1985-05-05,M,PU-1,slot-7`,
+	}, "\n")
+
+	rows, errs, err := Parse(FormatCSV, strings.NewReader(file), now)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, Row{
+		Line: 2, NIN: "12345678901", FirstName: "Ada", LastName: "Obi",
+		DateOfBirth: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), Gender: "F",
+		PollingUnitID: "PU-1", FingerprintData: "slot-1",
+	}, rows[0])
+	assert.Equal(t, map[int]string{
+		3: CodeInvalidNIN,
+		4: CodeInvalidDateOfBirth,
+		5: CodeInvalidDateOfBirth,
+		6: CodeInvalidGender,
+		7: CodeMissingField,
+		8: CodeMalformed,
+	}, codes(errs))
+	assert.Equal(t, "10987654321", errs[1].NIN)
+
+	_, _, err = Parse(FormatCSV, strings.NewReader("nin,first_name\n12345678901,Ada\n"), now)
+	assert.Error(t, err, "every column is required in the header")
+	_, _, err = Parse(FormatCSV, strings.NewReader(""), now)
+	assert.Error(t, err)
+}
+
+func TestParseJSONL(t *testing.T) {
+	file := strings.Join([]string{
+		`{"nin":"12345678901","first_name":"Ada","last_name":"Obi","date_of_birth":"1990-01-01T00:00:00Z","gender":"FEMALE","polling_unit_id":"PU-1","fingerprint_data":"slot-1"}`,
+		``,
+		`{"nin":12345678901}`,
+		`not json`,
+		`{"nin":"10987654321","first_name":"Bola","last_name":"Ade","date_of_birth":"1990-01-01","gender":"M","polling_unit_id":"PU-1"}`,
+	}, "\n")
+
+	rows, errs, err := Parse(FormatJSONL, strings.NewReader(file), now)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, 1, rows[0].Line)
+	assert.Equal(t, "F", rows[0].Gender)
+	assert.Equal(t, map[int]string{
+		3: CodeMalformed,
+		4: CodeMalformed,
+		5: CodeMissingField,
+	}, codes(errs))
+
+	_, _, err = Parse("xlsx", strings.NewReader(file), now)
+	assert.Error(t, err)
+}
+
+func TestDateOfBirth(t *testing.T) {
+	// Voters turn 18 on their birthday
+	_, err := ParseDateOfBirth("2008-06-01", now)
+	assert.NoError(t, err)
+	_, err = ParseDateOfBirth("2008-06-02", now)
+	assert.Error(t, err)
+	_, err = ParseDateOfBirth("1899-12-31", now)
+	assert.Error(t, err)
+	_, err = ParseDateOfBirth("01/06/1990", now)
+	assert.Error(t, err)
+}
@@ -0,0 +1,72 @@
+package votesig
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// EnrolmentType is the EIP-712 type of a terminal's enrolment request. It is
+// signed in the same domain as votes but is never sent to the contract.
+const EnrolmentType = "Enrolment(string terminalId,string pollingUnitId,address terminal,bytes32 code,uint256 timestamp)"
+
+var enrolmentTypeHash = crypto.Keccak256([]byte(EnrolmentType))
+
+// Enrolment is a terminal's request to be enrolled. Signing it with the key
+// being enrolled proves the terminal holds that key, the way a certificate
+// signing request does.
+type Enrolment struct {
+	TerminalID    string
+	PollingUnitID string
+	Address       common.Address
+	Code          string // one-time enrolment code, as issued
+	Timestamp     int64  // unix seconds
+}
+
+// StructHash returns the EIP-712 hash of the enrolment message
+func (e *Enrolment) StructHash() common.Hash {
+	return crypto.Keccak256Hash(
+		enrolmentTypeHash,
+		crypto.Keccak256([]byte(e.TerminalID)),
+		crypto.Keccak256([]byte(e.PollingUnitID)),
+		common.LeftPadBytes(e.Address.Bytes(), 32),
+		crypto.Keccak256([]byte(e.Code)),
+		math.U256Bytes(big.NewInt(e.Timestamp)),
+	)
+}
+
+// Digest returns the hash that is signed for the enrolment in domain
+func (e *Enrolment) Digest(domain Domain) []byte {
+	separator := domain.Separator()
+	structHash := e.StructHash()
+	return crypto.Keccak256([]byte("\x19\x01"), separator[:], structHash[:])
+}
+
+// SignedAt returns the enrolment's timestamp
+func (e *Enrolment) SignedAt() time.Time {
+	return time.Unix(e.Timestamp, 0).UTC()
+}
+
+// SignEnrolment signs an enrolment request for the signer's address
+func (s *Signer) SignEnrolment(e *Enrolment) (string, error) {
+	e.Address = s.Address()
+	return s.sign(e.Digest(s.domain))
+}
+
+// PublicKey returns the signer's uncompressed public key as 0x-prefixed hex
+func (s *Signer) PublicKey() string {
+	return hexutil.Encode(crypto.FromECDSAPub(&s.key.PublicKey))
+}
+
+// RecoverEnrolment returns the address that produced signature over the
+// enrolment in domain
+func RecoverEnrolment(domain Domain, e *Enrolment, signature string) (common.Address, error) {
+	if signature == "" || e.Timestamp <= 0 {
+		return common.Address{}, ErrUnsigned
+	}
+	return recoverSigner(e.Digest(domain), signature)
+}
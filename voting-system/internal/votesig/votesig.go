@@ -0,0 +1,235 @@
+// Package votesig defines the EIP-712 message a terminal signs when it
+// submits a vote. Terminals sign with their secp256k1 key, the same key whose
+// address the contract authorizes, so the server can recover the signer and
+// check it against the terminal's registered address, and can then relay the
+// signed vote to the contract, which recovers the same signer. Terminals sign
+// their enrolment request with the same key.
+package votesig
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"voting-system/internal/api/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The EIP-712 domain and type of a vote, as declared by SecureVotingSystem
+const (
+	DomainName    = "SecureVotingSystem"
+	DomainVersion = "1"
+	VoteType      = "Vote(bytes32 verificationHash,bytes32 encryptedVote,string candidateId,string pollingUnitId,uint256 electionId,bytes32 nonce,uint256 timestamp)"
+)
+
+var (
+	domainTypeHash = crypto.Keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+	voteTypeHash   = crypto.Keccak256([]byte(VoteType))
+)
+
+var (
+	// ErrUnsigned is returned for a vote without a signature, nonce or timestamp
+	ErrUnsigned = errors.New("vote is not signed")
+	// ErrBadSignature is returned when a signature cannot be decoded or recovered
+	ErrBadSignature = errors.New("vote signature is invalid")
+)
+
+// Domain identifies the deployment votes are signed for, so a signature is
+// only valid on one chain and one contract
+type Domain struct {
+	ChainID  *big.Int
+	Contract common.Address
+}
+
+// NewDomain returns the domain of the contract at address on chainID
+func NewDomain(chainID int64, contract string) Domain {
+	return Domain{ChainID: big.NewInt(chainID), Contract: common.HexToAddress(contract)}
+}
+
+// Separator returns the EIP-712 domain separator, which the contract's
+// domainSeparator() also returns
+func (d Domain) Separator() common.Hash {
+	chainID := d.ChainID
+	if chainID == nil {
+		chainID = new(big.Int)
+	}
+	return crypto.Keccak256Hash(
+		domainTypeHash,
+		crypto.Keccak256([]byte(DomainName)),
+		crypto.Keccak256([]byte(DomainVersion)),
+		math.U256Bytes(new(big.Int).Set(chainID)),
+		common.LeftPadBytes(d.Contract.Bytes(), 32),
+	)
+}
+
+// Vote is the part of a vote submission covered by the terminal's signature
+type Vote struct {
+	DeviceID         string // terminal the vote was submitted as; not part of the signed message
+	VerificationHash string
+	EncryptedVote    string
+	Ballot           string // JSON-encoded encrypted ballot, "" for a plain vote
+	CandidateID      string
+	PollingUnitID    string
+	ElectionID       string // on-chain election ID in decimal
+	Nonce            string // 32 bytes as hex
+	Timestamp        int64  // unix seconds
+}
+
+// FromRequest builds the signed part of a vote request submitted by deviceID
+func FromRequest(deviceID, verificationHash string, req *types.VoteRequest) (*Vote, error) {
+	vote := &Vote{
+		DeviceID:         deviceID,
+		VerificationHash: verificationHash,
+		EncryptedVote:    req.EncryptedVote,
+		CandidateID:      req.CandidateID,
+		PollingUnitID:    req.PollingUnitID,
+		ElectionID:       req.ElectionID,
+		Nonce:            strings.ToLower(strings.TrimPrefix(req.Nonce, "0x")),
+		Timestamp:        req.Timestamp,
+	}
+	if vote.ElectionID != "" {
+		if _, ok := new(big.Int).SetString(vote.ElectionID, 10); !ok {
+			return nil, fmt.Errorf("vote election ID must be a decimal number")
+		}
+	}
+	if vote.Nonce != "" {
+		if nonce, err := hex.DecodeString(vote.Nonce); err != nil || len(nonce) != 32 {
+			return nil, fmt.Errorf("vote nonce must be 32 bytes of hex")
+		}
+	}
+	if req.Ballot != nil {
+		encoded, err := json.Marshal(req.Ballot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode ballot: %v", err)
+		}
+		vote.Ballot = string(encoded)
+	}
+	return vote, nil
+}
+
+// StructHash returns the EIP-712 hash of the vote message. The verification
+// hash, encrypted vote and ballot are reduced to bytes32 the way the server
+// records them on chain; a ballot takes the place of the encrypted vote.
+// The election ID binds the signature to one election, so a vote cannot be
+// replayed into a later one.
+func (v *Vote) StructHash() common.Hash {
+	encryptedVote := v.EncryptedVote
+	if v.Ballot != "" {
+		encryptedVote = v.Ballot
+	}
+	electionID, ok := new(big.Int).SetString(v.ElectionID, 10)
+	if !ok {
+		electionID = new(big.Int)
+	}
+	nonce, _ := hex.DecodeString(v.Nonce)
+	return crypto.Keccak256Hash(
+		voteTypeHash,
+		crypto.Keccak256([]byte(v.VerificationHash)),
+		crypto.Keccak256([]byte(encryptedVote)),
+		crypto.Keccak256([]byte(v.CandidateID)),
+		crypto.Keccak256([]byte(v.PollingUnitID)),
+		math.U256Bytes(electionID),
+		common.LeftPadBytes(nonce, 32),
+		math.U256Bytes(big.NewInt(v.Timestamp)),
+	)
+}
+
+// Digest returns the hash that is signed for the vote in domain
+func (v *Vote) Digest(domain Domain) []byte {
+	separator := domain.Separator()
+	structHash := v.StructHash()
+	return crypto.Keccak256([]byte("\x19\x01"), separator[:], structHash[:])
+}
+
+// SignedAt returns the vote's timestamp
+func (v *Vote) SignedAt() time.Time {
+	return time.Unix(v.Timestamp, 0).UTC()
+}
+
+// Signer signs votes for one deployment with a terminal's key
+type Signer struct {
+	key    *ecdsa.PrivateKey
+	domain Domain
+}
+
+// NewSigner returns a signer for votes in domain
+func NewSigner(key *ecdsa.PrivateKey, domain Domain) *Signer {
+	return &Signer{key: key, domain: domain}
+}
+
+// Address returns the address votes are signed with
+func (s *Signer) Address() common.Address {
+	return crypto.PubkeyToAddress(s.key.PublicKey)
+}
+
+// Sign signs the vote and returns the 65-byte signature as 0x-prefixed hex,
+// with the 27/28 recovery id the contract expects
+func (s *Signer) Sign(v *Vote) (string, error) {
+	return s.sign(v.Digest(s.domain))
+}
+
+func (s *Signer) sign(digest []byte) (string, error) {
+	signature, err := crypto.Sign(digest, s.key)
+	if err != nil {
+		return "", err
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return hexutil.Encode(signature), nil
+}
+
+// Recover returns the address that produced signature over the vote in domain.
+// Both the 0/1 and the 27/28 recovery id conventions are accepted.
+func Recover(domain Domain, v *Vote, signature string) (common.Address, error) {
+	if signature == "" || v.Nonce == "" || v.Timestamp <= 0 {
+		return common.Address{}, ErrUnsigned
+	}
+	return recoverSigner(v.Digest(domain), signature)
+}
+
+// recoverSigner returns the address that signed digest
+func recoverSigner(digest []byte, signature string) (common.Address, error) {
+	sig, err := hexutil.Decode(signature)
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return common.Address{}, ErrBadSignature
+	}
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	pub, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		return common.Address{}, ErrBadSignature
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// NewNonce returns a random 256-bit nonce as hex
+func NewNonce() (string, error) {
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(nonce), nil
+}
+
+// VerificationHash derives the hash a voter's vote is recorded under on chain:
+// the hex HMAC-SHA256 of their NIN under the election's secret salt. Without
+// the salt it cannot be linked to the NIN, and the same voter's hashes in two
+// elections cannot be linked to each other.
+func VerificationHash(salt, nin string) string {
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(nin))
+	return hex.EncodeToString(mac.Sum(nil))
+}
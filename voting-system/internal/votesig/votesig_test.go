@@ -0,0 +1,142 @@
+package votesig
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDigestMatchesEIP712 checks the hand-rolled encoding against go-ethereum's
+// generic EIP-712 implementation, which is what wallets and the contract follow
+func TestDigestMatchesEIP712(t *testing.T) {
+	domain := NewDomain(1337, "0x345cA3e014Aaf5dcA488057592ee47305D9B3e10")
+	nonce, err := NewNonce()
+	require.NoError(t, err)
+	vote := &Vote{
+		VerificationHash: "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8",
+		EncryptedVote:    "ciphertext",
+		CandidateID:      "APC",
+		PollingUnitID:    "PU-1",
+		ElectionID:       "7",
+		Nonce:            nonce,
+		Timestamp:        1760000000,
+	}
+
+	rawNonce, _ := hex.DecodeString(nonce)
+	typed := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Vote": {
+				{Name: "verificationHash", Type: "bytes32"},
+				{Name: "encryptedVote", Type: "bytes32"},
+				{Name: "candidateId", Type: "string"},
+				{Name: "pollingUnitId", Type: "string"},
+				{Name: "electionId", Type: "uint256"},
+				{Name: "nonce", Type: "bytes32"},
+				{Name: "timestamp", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Vote",
+		Domain: apitypes.TypedDataDomain{
+			Name:              DomainName,
+			Version:           DomainVersion,
+			ChainId:           (*math.HexOrDecimal256)(big.NewInt(1337)),
+			VerifyingContract: domain.Contract.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"verificationHash": crypto.Keccak256([]byte(vote.VerificationHash)),
+			"encryptedVote":    crypto.Keccak256([]byte(vote.EncryptedVote)),
+			"candidateId":      vote.CandidateID,
+			"pollingUnitId":    vote.PollingUnitID,
+			"electionId":       (*math.HexOrDecimal256)(big.NewInt(7)),
+			"nonce":            rawNonce,
+			"timestamp":        (*math.HexOrDecimal256)(big.NewInt(vote.Timestamp)),
+		},
+	}
+	want, _, err := apitypes.TypedDataAndHash(typed)
+	require.NoError(t, err)
+	assert.Equal(t, want, vote.Digest(domain))
+
+	separator, err := typed.HashStruct("EIP712Domain", typed.Domain.Map())
+	require.NoError(t, err)
+	assert.Equal(t, common.BytesToHash(separator), domain.Separator())
+}
+
+func TestSignAndRecover(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	domain := NewDomain(1337, "0x345cA3e014Aaf5dcA488057592ee47305D9B3e10")
+	signer := NewSigner(key, domain)
+
+	nonce, err := NewNonce()
+	require.NoError(t, err)
+	vote := &Vote{VerificationHash: "abc", CandidateID: "APC", PollingUnitID: "PU-1", ElectionID: "1", Nonce: nonce, Timestamp: 1760000000}
+	signature, err := signer.Sign(vote)
+	require.NoError(t, err)
+
+	recovered, err := Recover(domain, vote, signature)
+	require.NoError(t, err)
+	assert.Equal(t, signer.Address(), recovered)
+
+	// The signature does not carry over to another deployment or another vote
+	other, err := Recover(NewDomain(1, domain.Contract.Hex()), vote, signature)
+	require.NoError(t, err)
+	assert.NotEqual(t, signer.Address(), other)
+
+	vote.ElectionID = "2"
+	other, err = Recover(domain, vote, signature)
+	require.NoError(t, err)
+	assert.NotEqual(t, signer.Address(), other, "a vote cannot be replayed into another election")
+
+	vote.ElectionID = "1"
+	vote.CandidateID = "PDP"
+	other, err = Recover(domain, vote, signature)
+	require.NoError(t, err)
+	assert.NotEqual(t, signer.Address(), other)
+
+	vote.Nonce = ""
+	_, err = Recover(domain, vote, signature)
+	assert.ErrorIs(t, err, ErrUnsigned)
+}
+
+func TestEnrolmentSignAndRecover(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	domain := NewDomain(1337, "0x345cA3e014Aaf5dcA488057592ee47305D9B3e10")
+	signer := NewSigner(key, domain)
+
+	enrolment := &Enrolment{TerminalID: "TERM-001", PollingUnitID: "PU-1", Code: "ABCD-EFGH-IJKL-MNOP", Timestamp: 1760000000}
+	signature, err := signer.SignEnrolment(enrolment)
+	require.NoError(t, err)
+	assert.Equal(t, signer.Address(), enrolment.Address)
+
+	recovered, err := RecoverEnrolment(domain, enrolment, signature)
+	require.NoError(t, err)
+	assert.Equal(t, signer.Address(), recovered)
+
+	// The signature is bound to the code
+	enrolment.Code = "ABCD-EFGH-IJKL-MNOQ"
+	other, err := RecoverEnrolment(domain, enrolment, signature)
+	require.NoError(t, err)
+	assert.NotEqual(t, signer.Address(), other)
+}
+
+func TestVerificationHash(t *testing.T) {
+	hash := VerificationHash("salt-1", "12345678901")
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, VerificationHash("salt-1", "12345678901"))
+	assert.NotEqual(t, hash, VerificationHash("salt-2", "12345678901"), "each election's salt gives another hash")
+	assert.NotEqual(t, hash, VerificationHash("salt-1", "10987654321"))
+}
@@ -22,6 +22,9 @@ type Config struct {
 	Logging    LoggingConfig    `mapstructure:"logging"`
 	Security   SecurityConfig   `mapstructure:"security"`
 	API        APIConfig        `mapstructure:"api"`
+	Admin      AdminConfig      `mapstructure:"admin"`
+	Terminal   TerminalConfig   `mapstructure:"terminal"`
+	Fleet      FleetConfig      `mapstructure:"fleet"`
 }
 
 // ServerConfig holds server-related configuration
@@ -68,6 +71,10 @@ type BlockchainConfig struct {
 	SyncInterval    time.Duration `mapstructure:"sync_interval"`
 	RetryInterval   time.Duration `mapstructure:"retry_interval"`
 	MaxRetries      int           `mapstructure:"max_retries"`
+	MaxBackoff      time.Duration `mapstructure:"max_backoff"`
+	SyncWorkers     int           `mapstructure:"sync_workers"`
+	SyncBatchSize   int           `mapstructure:"sync_batch_size"`
+	ReceiptTimeout  time.Duration `mapstructure:"receipt_timeout"`
 	ConfirmBlocks   int           `mapstructure:"confirm_blocks"`
 }
 
@@ -79,6 +86,10 @@ type BiometricConfig struct {
 	MaxAttempts       int     `mapstructure:"max_attempts"`
 	TemplateFormat    string  `mapstructure:"template_format"`
 	Enabled           bool    `mapstructure:"enabled"`
+	// Matcher enrols new fingerprints: "exact" for sensors that match on the
+	// device and send a slot ID, or "minutiae" for minutiae captures
+	Matcher         string        `mapstructure:"matcher"`
+	LockoutDuration time.Duration `mapstructure:"lockout_duration"` // after MaxAttempts failed matches
 }
 
 // HardwareConfig holds hardware interface configuration
@@ -94,8 +105,8 @@ type HardwareConfig struct {
 // EncryptionConfig holds encryption-related configuration
 type EncryptionConfig struct {
 	Key         string `mapstructure:"key"`
-	Algorithm   string `mapstructure:"algorithm"` // AES-256-GCM, ChaCha20-Poly1305
-	KeyRotation bool   `mapstructure:"key_rotation"`
+	Algorithm   string `mapstructure:"algorithm"`    // AES-256-GCM, ChaCha20-Poly1305
+	KeyRotation bool   `mapstructure:"key_rotation"` // deal a fresh ballot key per election instead of allowing reuse
 }
 
 // RedisConfig holds Redis configuration for caching and sessions
@@ -130,7 +141,50 @@ type SecurityConfig struct {
 	LockoutDuration     time.Duration `mapstructure:"lockout_duration"`
 	PasswordMinLength   int           `mapstructure:"password_min_length"`
 	RequireStrongPasswd bool          `mapstructure:"require_strong_password"`
-	EnableTwoFA         bool          `mapstructure:"enable_2fa"`
+	EnableTwoFA         bool          `mapstructure:"enable_2fa"`    // force TOTP for roles in models.TwoFactorRoles
+	TwoFAIssuer         string        `mapstructure:"two_fa_issuer"` // issuer shown in authenticator apps
+	PasswordResetTTL    time.Duration `mapstructure:"password_reset_ttl"`
+	VoteSignatureMaxAge time.Duration `mapstructure:"vote_signature_max_age"` // oldest terminal-signed vote accepted; covers terminals that were offline
+}
+
+// AdminConfig holds the credentials used to bootstrap the first admin account
+type AdminConfig struct {
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	Email    string `mapstructure:"email"`
+}
+
+// TerminalConfig holds the voting terminal daemon's configuration
+type TerminalConfig struct {
+	DeviceID          string        `mapstructure:"device_id"`
+	PollingUnitID     string        `mapstructure:"polling_unit_id"`
+	ServerURL         string        `mapstructure:"server_url"`       // central server base URL
+	SharedSecret      string        `mapstructure:"shared_secret"`    // the terminal's own HMAC secret, issued when it is approved
+	PreviousSecrets   []string      `mapstructure:"previous_secrets"` // secrets rotated out, kept to verify the local journal
+	APIToken          string        `mapstructure:"api_token"`        // bearer token for the local REST API; empty disables the check
+	ForwardInterval   time.Duration `mapstructure:"forward_interval"`
+	RetryInterval     time.Duration `mapstructure:"retry_interval"` // base delay before a failed upload is retried
+	MaxBackoff        time.Duration `mapstructure:"max_backoff"`
+	RequestTimeout    time.Duration `mapstructure:"request_timeout"`
+	RosterInterval    time.Duration `mapstructure:"roster_interval"`    // how often the polling unit's voter list is refreshed
+	VotedInterval     time.Duration `mapstructure:"voted_interval"`     // how often the polling unit's voted set is refreshed
+	HeartbeatInterval time.Duration `mapstructure:"heartbeat_interval"` // how often health is reported and remote config fetched
+	BatteryPath       string        `mapstructure:"battery_path"`       // sysfs capacity file; empty on mains-powered terminals
+	Name              string        `mapstructure:"name"`
+	Location          string        `mapstructure:"location"`
+	EnrolmentCode     string        `mapstructure:"enrolment_code"` // one-time code from an admin; used while the terminal is not enrolled
+}
+
+// FleetConfig holds the server's terminal health monitoring configuration
+type FleetConfig struct {
+	HeartbeatInterval time.Duration `mapstructure:"heartbeat_interval"` // interval handed to terminals in their remote config
+	OfflineAfter      time.Duration `mapstructure:"offline_after"`      // silence after which a terminal is marked offline
+	CheckInterval     time.Duration `mapstructure:"check_interval"`
+	LowBattery        int           `mapstructure:"low_battery"`     // percent below which an unplugged terminal raises an alert
+	MaxClockSkew      time.Duration `mapstructure:"max_clock_skew"`  // terminal clock drift that raises an alert
+	MaxQueueDepth     int           `mapstructure:"max_queue_depth"` // unforwarded votes that raise an alert
+	EnrolmentCodeTTL  time.Duration `mapstructure:"enrolment_code_ttl"`
+	SecretGrace       time.Duration `mapstructure:"secret_grace"` // how long a rotated terminal secret stays valid
 }
 
 // APIConfig holds API-related configuration
@@ -154,6 +208,58 @@ type CORSConfig struct {
 
 // LoadConfig loads configuration from file and environment variables
 func LoadConfig(configPath string) (*Config, error) {
+	config, err := load(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate configuration
+	if err := validateConfig(config); err != nil {
+		return nil, fmt.Errorf("config validation failed: %v", err)
+	}
+
+	return config, nil
+}
+
+// LoadDatabaseConfig loads only the database section, without requiring the
+// blockchain settings. Used by tools such as the migration CLI.
+func LoadDatabaseConfig(configPath string) (*DatabaseConfig, error) {
+	config, err := load(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return &config.Database, nil
+}
+
+// LoadTerminalConfig loads the voting terminal's configuration. Unlike
+// LoadConfig it does not require blockchain or JWT settings, which stay on
+// the central server.
+func LoadTerminalConfig(configPath string) (*Config, error) {
+	config, err := load(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case config.Terminal.DeviceID == "":
+		return nil, fmt.Errorf("config validation failed: terminal device ID is required")
+	case config.Terminal.PollingUnitID == "":
+		return nil, fmt.Errorf("config validation failed: terminal polling unit ID is required")
+	case config.Terminal.ServerURL == "":
+		return nil, fmt.Errorf("config validation failed: terminal server URL is required")
+	case config.Blockchain.PrivateKey == "":
+		return nil, fmt.Errorf("config validation failed: the terminal requires a private key to sign votes")
+	case config.Blockchain.ContractAddress == "":
+		return nil, fmt.Errorf("config validation failed: the terminal requires the contract address votes are signed for")
+	case config.Database.Type != "sqlite" || config.Database.Path == "":
+		return nil, fmt.Errorf("config validation failed: the terminal requires a sqlite database path")
+	}
+
+	return config, nil
+}
+
+// load reads defaults, the config file and environment overrides
+func load(configPath string) (*Config, error) {
 	// Set default values
 	setDefaults()
 
@@ -183,11 +289,6 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("error unmarshaling config: %v", err)
 	}
 
-	// Validate configuration
-	if err := validateConfig(&config); err != nil {
-		return nil, fmt.Errorf("config validation failed: %v", err)
-	}
-
 	return &config, nil
 }
 
@@ -216,6 +317,10 @@ func setDefaults() {
 	viper.SetDefault("blockchain.sync_interval", "30s")
 	viper.SetDefault("blockchain.retry_interval", "30s")
 	viper.SetDefault("blockchain.max_retries", 3)
+	viper.SetDefault("blockchain.max_backoff", "10m")
+	viper.SetDefault("blockchain.sync_workers", 4)
+	viper.SetDefault("blockchain.sync_batch_size", 100)
+	viper.SetDefault("blockchain.receipt_timeout", "5m")
 	viper.SetDefault("blockchain.confirm_blocks", 1)
 
 	// Biometric defaults
@@ -224,6 +329,8 @@ func setDefaults() {
 	viper.SetDefault("biometric.max_attempts", 3)
 	viper.SetDefault("biometric.template_format", "iso")
 	viper.SetDefault("biometric.enabled", true)
+	viper.SetDefault("biometric.matcher", "exact")
+	viper.SetDefault("biometric.lockout_duration", "15m")
 
 	// Hardware defaults
 	viper.SetDefault("hardware.status_leds", true)
@@ -252,13 +359,35 @@ func setDefaults() {
 	viper.SetDefault("logging.compress", true)
 
 	// Security defaults
-	viper.SetDefault("security.jwt_expiration", "24h")
+	viper.SetDefault("security.jwt_expiration", "15m")
 	viper.SetDefault("security.session_timeout", "2h")
 	viper.SetDefault("security.max_login_attempts", 5)
 	viper.SetDefault("security.lockout_duration", "15m")
 	viper.SetDefault("security.password_min_length", 8)
 	viper.SetDefault("security.require_strong_password", true)
-	viper.SetDefault("security.enable_2fa", false)
+	viper.SetDefault("security.enable_2fa", true)
+	viper.SetDefault("security.two_fa_issuer", "Voting System")
+	viper.SetDefault("security.password_reset_ttl", "1h")
+	viper.SetDefault("security.vote_signature_max_age", "24h")
+
+	// Terminal defaults
+	viper.SetDefault("terminal.forward_interval", "5s")
+	viper.SetDefault("terminal.retry_interval", "10s")
+	viper.SetDefault("terminal.max_backoff", "5m")
+	viper.SetDefault("terminal.request_timeout", "15s")
+	viper.SetDefault("terminal.roster_interval", "10m")
+	viper.SetDefault("terminal.voted_interval", "1m")
+	viper.SetDefault("terminal.heartbeat_interval", "1m")
+
+	// Fleet monitoring defaults
+	viper.SetDefault("fleet.heartbeat_interval", "1m")
+	viper.SetDefault("fleet.offline_after", "5m")
+	viper.SetDefault("fleet.check_interval", "1m")
+	viper.SetDefault("fleet.low_battery", 20)
+	viper.SetDefault("fleet.max_clock_skew", "1m")
+	viper.SetDefault("fleet.max_queue_depth", 100)
+	viper.SetDefault("fleet.enrolment_code_ttl", "24h")
+	viper.SetDefault("fleet.secret_grace", "24h")
 
 	// API defaults
 	viper.SetDefault("api.rate_limit", 100)
@@ -289,8 +418,13 @@ func overrideWithEnvVars() {
 		"JWT_SECRET":       "security.jwt_secret",
 		"ADMIN_USERNAME":   "admin.username",
 		"ADMIN_PASSWORD":   "admin.password",
+		"ADMIN_EMAIL":      "admin.email",
 		"REDIS_URL":        "redis.addr",
 		"REDIS_PASSWORD":   "redis.password",
+
+		"TERMINAL_SHARED_SECRET":  "terminal.shared_secret",
+		"TERMINAL_API_TOKEN":      "terminal.api_token",
+		"TERMINAL_ENROLMENT_CODE": "terminal.enrolment_code",
 	}
 
 	for envVar, configKey := range envMappings {
@@ -348,6 +482,12 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("biometric match threshold must be between 0 and 1")
 	}
 
+	switch config.Biometric.Matcher {
+	case "", "exact", "minutiae":
+	default:
+		return fmt.Errorf("unknown biometric matcher: %s", config.Biometric.Matcher)
+	}
+
 	// Validate blockchain configuration
 	if config.Blockchain.GasLimit == 0 {
 		config.Blockchain.GasLimit = 3000000 // Set default
@@ -357,6 +497,14 @@ func validateConfig(config *Config) error {
 		config.Blockchain.ChainID = 1337 // Set default for development
 	}
 
+	if config.Blockchain.SyncInterval <= 0 {
+		return fmt.Errorf("blockchain sync interval must be positive")
+	}
+
+	if config.Blockchain.SyncWorkers < 1 {
+		return fmt.Errorf("blockchain sync workers must be at least 1")
+	}
+
 	return nil
 }
 
@@ -397,6 +545,15 @@ func (c *Config) GetServerAddress() string {
 	return fmt.Sprintf("%s:%s", c.Server.Host, c.Server.Port)
 }
 
+// SealingKey returns the key server-held secrets are sealed with: the
+// encryption key, or the JWT secret when none is configured
+func (c *Config) SealingKey() string {
+	if c.Encryption.Key != "" {
+		return c.Encryption.Key
+	}
+	return c.Security.JWTSecret
+}
+
 // SanitizeForLogging returns a copy of the config with sensitive data redacted
 func (c *Config) SanitizeForLogging() *Config {
 	sanitized := *c
@@ -422,6 +579,10 @@ func (c *Config) SanitizeForLogging() *Config {
 		sanitized.Redis.Password = "[REDACTED]"
 	}
 
+	if sanitized.Admin.Password != "" {
+		sanitized.Admin.Password = "[REDACTED]"
+	}
+
 	return &sanitized
 }
 
@@ -450,6 +611,13 @@ func LoadConfigFromEnv() (*Config, error) {
 	config.Blockchain.ChainID = getEnvInt64("CHAIN_ID", 1337)
 	config.Blockchain.GasLimit = getEnvUint64("GAS_LIMIT", 3000000)
 	config.Blockchain.GasPrice = getEnvInt64("GAS_PRICE", 20000000000)
+	config.Blockchain.SyncInterval = getEnvDuration("SYNC_INTERVAL", 30*time.Second)
+	config.Blockchain.RetryInterval = getEnvDuration("RETRY_INTERVAL", 30*time.Second)
+	config.Blockchain.MaxRetries = getEnvInt("MAX_RETRIES", 3)
+	config.Blockchain.MaxBackoff = getEnvDuration("MAX_BACKOFF", 10*time.Minute)
+	config.Blockchain.SyncWorkers = getEnvInt("SYNC_WORKERS", 4)
+	config.Blockchain.SyncBatchSize = getEnvInt("SYNC_BATCH_SIZE", 100)
+	config.Blockchain.ReceiptTimeout = getEnvDuration("RECEIPT_TIMEOUT", 5*time.Minute)
 
 	// Encryption configuration
 	config.Encryption.Key = os.Getenv("ENCRYPTION_KEY")
@@ -457,6 +625,20 @@ func LoadConfigFromEnv() (*Config, error) {
 
 	// Security configuration
 	config.Security.JWTSecret = os.Getenv("JWT_SECRET")
+	config.Security.JWTExpiration = getEnvDuration("JWT_EXPIRATION", 15*time.Minute)
+	config.Security.SessionTimeout = getEnvDuration("SESSION_TIMEOUT", 2*time.Hour)
+	config.Security.MaxLoginAttempts = getEnvInt("MAX_LOGIN_ATTEMPTS", 5)
+	config.Security.LockoutDuration = getEnvDuration("LOCKOUT_DURATION", 15*time.Minute)
+	config.Security.PasswordMinLength = getEnvInt("PASSWORD_MIN_LENGTH", 8)
+	config.Security.RequireStrongPasswd = getEnvBool("REQUIRE_STRONG_PASSWORD", true)
+	config.Security.PasswordResetTTL = getEnvDuration("PASSWORD_RESET_TTL", time.Hour)
+	config.Security.EnableTwoFA = getEnvBool("ENABLE_2FA", true)
+	config.Security.TwoFAIssuer = getEnvOrDefault("TWO_FA_ISSUER", "Voting System")
+
+	// Admin bootstrap
+	config.Admin.Username = os.Getenv("ADMIN_USERNAME")
+	config.Admin.Password = os.Getenv("ADMIN_PASSWORD")
+	config.Admin.Email = os.Getenv("ADMIN_EMAIL")
 
 	// Logging configuration
 	config.Logging.Level = getEnvOrDefault("LOG_LEVEL", "info")
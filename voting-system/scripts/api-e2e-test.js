@@ -22,8 +22,6 @@ const CANDIDATES = (
   process.env.CANDIDATES || "CANDIDATE_001,CANDIDATE_002"
 ).split(",");
 const TERMINAL_ID = "TEST_TERMINAL_1";
-const TERMINAL_ADDR =
-  process.env.TERMINAL_ADDR || "0x0000000000000000000000000000000000000001";
 const POLLING_UNIT_ID = "TEST_PU1";
 
 // Per-run uniqueness to avoid DB uniqueness collisions across runs
@@ -159,16 +157,13 @@ function trySqliteCleanup() {
     step("End any active on-chain election");
     await endAllActiveElections();
 
-    step("Register terminal (and authorize on-chain)");
-    await httpRetry("POST", "/api/v1/terminal/register", {
-      terminal_id: TERMINAL_ID,
-      name: "Terminal 1",
-      location: "Localhost",
-      polling_unit_id: POLLING_UNIT_ID,
-      address: TERMINAL_ADDR,
-      authorize: true,
+    // Terminals enrol themselves with a signed request (see README, Enrolment);
+    // approving the pending enrolment authorizes the terminal on-chain
+    step("Approve terminal enrolment (and authorize on-chain)");
+    await httpRetry("POST", `/api/v1/admin/terminals/${TERMINAL_ID}/approve`, {
+      reason: "API E2E test",
     });
-    ok("Terminal registered");
+    ok("Terminal approved");
 
     step("Create election (starts in ~20s)");
     const now = Math.floor(Date.now() / 1000);
@@ -167,7 +167,7 @@ contract, _ := blockchain.NewSecureVotingSystem(
 )
 
 // Use contract methods
-hasVoted, _ := contract.HasVoterVoted(nil, voterHash)
+hasVoted, _ := contract.HasVoterVoted(nil, electionID, voterHash)
 \`\`\`
 
 ## Next Steps
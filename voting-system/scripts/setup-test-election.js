@@ -56,6 +56,29 @@ const contractABI = [
     stateMutability: "nonpayable",
     type: "function",
   },
+  {
+    inputs: [
+      {
+        internalType: "uint256",
+        name: "_electionId",
+        type: "uint256",
+      },
+      {
+        internalType: "bytes32",
+        name: "_root",
+        type: "bytes32",
+      },
+      {
+        internalType: "uint256",
+        name: "_voterCount",
+        type: "uint256",
+      },
+    ],
+    name: "publishVoterRoll",
+    outputs: [],
+    stateMutability: "nonpayable",
+    type: "function",
+  },
   {
     inputs: [],
     name: "getCurrentElectionId",
@@ -213,6 +236,26 @@ async function setupTestElection() {
       await new Promise((resolve) => setTimeout(resolve, waitTime + 1000)); // Add 1 second buffer
     }
 
+    // The contract only starts elections with a published voter roll. The
+    // server publishes the root of its roll snapshot; this test election gets
+    // a placeholder, so the server has no roll for it.
+    console.log("Publishing placeholder voter roll...");
+    const rollData = contract.methods
+      .publishVoterRoll(
+        newElectionId,
+        web3.utils.keccak256(`test-roll-${newElectionId}`),
+        1
+      )
+      .encodeABI();
+    const rollResult = await web3.eth.sendTransaction({
+      from: ownerAccount,
+      to: CONTRACT_ADDRESS,
+      data: rollData,
+      gas: 200000,
+      gasPrice: web3.utils.toWei("20", "gwei"),
+    });
+    console.log(`Publish roll transaction hash: ${rollResult.transactionHash}`);
+
     // Start the election
     console.log("Starting election...");
     const startData = contract.methods.startElection(newElectionId).encodeABI();